@@ -0,0 +1,74 @@
+/*
+Package artifacts provides a directory for each test where it can write files
+which help to debug a failure, like logs, dumps, and screenshots.
+
+When a test fails, the files in its artifacts directory are listed in the test
+output. If the RootEnvVar environment variable is set, the files are also
+copied to a directory for the test under that path, so that a CI system can
+upload them. The artifacts directory of a test which passes is removed.
+*/
+package artifacts // import "gotest.tools/v3/artifacts"
+
+import (
+	"os"
+	"path/filepath"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/internal/artifacts"
+)
+
+// RootEnvVar is the name of the environment variable which sets the directory
+// where the artifacts of failed tests are copied.
+const RootEnvVar = artifacts.RootEnvVar
+
+// TestingT is the subset of testing.T used by Dir.
+type TestingT interface {
+	assert.TestingT
+	Name() string
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Dir returns the artifacts directory of the test. The directory is created by
+// the first call to Dir, and later calls by the same test return the same
+// directory.
+//
+// When used with Go 1.14+ the files in the directory are reported when the
+// test ends, if the test failed. The directory is removed if the test passed,
+// unless the TEST_NOCLEANUP env var is set to true.
+func Dir(t TestingT) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	dir, err := artifacts.Dir(t)
+	assert.NilError(t, err)
+	return dir
+}
+
+// Path returns the path to a file called name in the artifacts directory of
+// the test. See Dir.
+func Path(t TestingT, name string) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return filepath.Join(Dir(t), name)
+}
+
+// Create creates a file called name in the artifacts directory of the test,
+// and returns it open for writing. The test fails if the file can not be
+// created. The file is closed when the test ends, if it was not closed already.
+func Create(t TestingT, name string) *os.File {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	path := Path(t, name)
+	assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	f, err := os.Create(path)
+	assert.NilError(t, err)
+	artifacts.RegisterCleanup(t, func() {
+		f.Close() //nolint: errcheck
+	})
+	return f
+}
@@ -0,0 +1,110 @@
+package artifacts
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+type fakeT struct {
+	name     string
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string { return t.name }
+
+func (t *fakeT) Fail() { t.failed = true }
+
+func (t *fakeT) FailNow() { t.failed = true }
+
+func (t *fakeT) Failed() bool { return t.failed }
+
+func (t *fakeT) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+
+func (t *fakeT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeT) runCleanups() {
+	for len(t.cleanups) > 0 {
+		f := t.cleanups[len(t.cleanups)-1]
+		t.cleanups = t.cleanups[:len(t.cleanups)-1]
+		f()
+	}
+}
+
+func writeArtifacts(t *testing.T, ft *fakeT) string {
+	dir := Dir(ft)
+	assert.Equal(t, Dir(ft), dir)
+	assert.NilError(t, ioutil.WriteFile(Path(ft, "server.log"), []byte("started"), 0644))
+	f := Create(ft, "dumps/state.json")
+	_, err := f.WriteString("{}")
+	assert.NilError(t, err)
+	return dir
+}
+
+func TestDirRemovedWhenTestPasses(t *testing.T) {
+	ft := &fakeT{name: "TestPasses"}
+	dir := writeArtifacts(t, ft)
+
+	ft.runCleanups()
+	_, err := os.Stat(dir)
+	assert.Assert(t, os.IsNotExist(err))
+	assert.Equal(t, len(ft.logs), 0)
+}
+
+func TestDirReportedWhenTestFails(t *testing.T) {
+	ft := &fakeT{name: "TestFails/sub test"}
+	dir := writeArtifacts(t, ft)
+	defer os.RemoveAll(dir)
+
+	ft.failed = true
+	ft.runCleanups()
+	expected := fmt.Sprintf(`artifacts in %s:
+  %s (2 bytes)
+  %s (7 bytes)`, dir, filepath.Join("dumps", "state.json"), "server.log")
+	assert.DeepEqual(t, ft.logs, []string{expected})
+	assert.Assert(t, fs.Equal(dir, fs.Expected(t,
+		fs.WithMode(0700),
+		fs.WithFile("server.log", "started"),
+		fs.WithDir("dumps", fs.WithFile("state.json", "{}")))))
+}
+
+func TestDirCopiedToRoot(t *testing.T) {
+	root := fs.NewDir(t, "artifacts-root")
+	defer root.Remove()
+	defer env.Patch(t, RootEnvVar, root.Path())()
+
+	ft := &fakeT{name: "TestFails/sub test"}
+	dir := writeArtifacts(t, ft)
+
+	ft.failed = true
+	ft.runCleanups()
+	target := root.Join("TestFails-sub_test")
+	expected := fmt.Sprintf(`artifacts in %s:
+  %s (2 bytes)
+  %s (7 bytes)`, target, filepath.Join("dumps", "state.json"), "server.log")
+	assert.DeepEqual(t, ft.logs, []string{expected})
+
+	_, err := os.Stat(dir)
+	assert.Assert(t, os.IsNotExist(err))
+	content, err := ioutil.ReadFile(filepath.Join(target, "dumps", "state.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "{}")
+}
+
+func TestDirEmptyNotReported(t *testing.T) {
+	ft := &fakeT{name: "TestEmpty", failed: true}
+	dir := Dir(ft)
+
+	ft.runCleanups()
+	assert.Equal(t, len(ft.logs), 0)
+	_, err := os.Stat(dir)
+	assert.Assert(t, os.IsNotExist(err))
+}
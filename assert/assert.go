@@ -46,6 +46,9 @@ message is omitted from these examples for brevity.
 	// assertion failed: expected error to contain "includes this", got "oops"
 	assert.ErrorIs(t, err, os.ErrNotExist)
 	// assertion failed: error is "oops", not "file does not exist" (os.ErrNotExist)
+	assert.ErrorAs(t, err, &pathErr)
+	// assertion failed: error chain of "oops" does not contain *fs.PathError:
+	//   *errors.errorString: oops
 
 	// complex types
 
@@ -57,6 +60,9 @@ message is omitted from these examples for brevity.
 	// assertion failed: len(sequence) is 0
 	assert.Assert(t, is.Contains(mapping, "key"))
 	// assertion failed: map[other:1] does not contain key
+	assert.Assert(t, is.ElementsMatch(names, expected)) // in any order
+	// assertion failed: elements of names and expected do not match
+	// in names but not expected: [extra]
 
 	// pointers and interface
 
@@ -83,6 +89,83 @@ Package http://pkg.go.dev/gotest.tools/v3/assert/cmp provides
 many common comparisons. Additional comparisons can be written to compare
 values in other ways. See the example Assert (CustomComparison).
 
+# Generic assertions
+
+EqualT and DeepEqualT are like Equal and DeepEqual, but their arguments must
+have the same type, so comparing values of different types is a compile
+error. They use type parameters, so they require Go 1.18 or later. The other
+assertions of this package support Go 1.13 or later.
+
+# Diff output
+
+Equal with multi-line strings, DeepEqual, and the golden package show a diff
+when the values are different. Environment variables change how the diff is
+rendered:
+
+  - GOTESTTOOLS_DIFF_ALGORITHM selects the algorithm used to match lines of
+    Equal and golden diffs: difflib (the default), patience, or histogram.
+  - GOTESTTOOLS_DIFF_CONTEXT is the number of unchanged lines shown around each
+    change in Equal and golden diffs. The default is 2.
+  - GOTESTTOOLS_DIFF_INLINE highlights the changes within a changed line: none
+    (the default), word, or char. Removed text is marked with [-text-] and
+    added text with {+text+}. When it is set Equal also shows a diff of single
+    line strings longer than 80 characters.
+
+The DeepEqual diff is created by go-cmp, so only GOTESTTOOLS_DIFF_INLINE
+changes it. A variable with an invalid value is reported after the diff, and
+the default is used instead.
+
+# Source snippets
+
+Set GOTESTTOOLS_SOURCE_SNIPPET to a number of lines to include the source of
+a failed assertion in the test log, with that many lines of context before and
+after it, and a caret under the assertion call. The source is taken from the
+first caller outside of gotest.tools, which helps to find the assertion which
+failed when it is called from a helper or a table-driven test.
+
+	$ GOTESTTOOLS_SOURCE_SNIPPET=1 go test ./...
+	    main_test.go:41: assertion failed: 1 (actual int) != 21 (expected int32)
+	    main_test.go:41: main_test.go:41:
+	          40 |	actual := compute()
+	        > 41 |	assert.Equal(t, actual, expected)
+	             |	^~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~
+	          42 |}
+
+# Collecting failures
+
+A Collector runs many checks, and reports all of the failures together when
+Report is called, or when the test ends. Failures are grouped by the line of
+the check, with a count of the failures.
+
+	c := assert.NewCollector(t)
+	for _, tc := range testcases {
+		c.Equal(tc.actual, tc.expected, tc.name)
+	}
+	c.Report()
+	// 2 assertions failed:
+	// main_test.go:41 (2 failures):
+	//     assertion failed: 1 (tc.actual int) != 2 (tc.expected int): first
+	//     assertion failed: 3 (tc.actual int) != 4 (tc.expected int): second
+
+# Failure format
+
+Failures are logged with t.Log as text by default. Set
+GOTESTTOOLS_FAILURE_FORMAT to color to highlight the lines of diffs with ANSI
+escape codes, or to json to log each failure as a JSON object with the message
+and the position of the assertion, for tools which annotate the failures in CI.
+
+Use SetReporter to log failures with a custom Reporter.
+
+# Large failure messages
+
+Failure messages larger than 64KiB are truncated in the test log. The full
+message is written to a file in the artifacts directory of the test (see
+http://pkg.go.dev/gotest.tools/v3/artifacts), and the path to the file is
+included in the log. The limit applies to the failures of this package, and to
+the golden, fs, and icmd packages which use it. Set
+GOTESTTOOLS_MAX_FAILURE_SIZE to change the limit, in bytes, or to 0 to disable
+it.
+
 # Automated migration from testify
 
 gty-migrate-from-testify is a command which translates Go source code from
@@ -93,6 +176,9 @@ See http://pkg.go.dev/gotest.tools/v3/assert/cmd/gty-migrate-from-testify.
 package assert // import "gotest.tools/v3/assert"
 
 import (
+	"context"
+	"time"
+
 	gocmp "github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/internal/assert"
@@ -188,10 +274,17 @@ func NilError(t TestingT, err error, msgAndArgs ...interface{}) {
 //	assert.Equal(t, actual, expected)
 //	// main_test.go:41: assertion failed: 1 (actual int) != 21 (expected int32)
 //
-// If either x or y are a multi-line string the failure message will include a
+// If either x or y are a multi-line string, or the strings only differ by
+// whitespace or invisible characters, the failure message will include a
 // unified diff of the two values. If the values only differ by whitespace
 // the unified diff will be augmented by replacing whitespace characters with
-// visible characters to identify the whitespace difference.
+// visible characters to identify the whitespace difference. Characters which
+// are easily mistaken for others are shown the same way: a non-breaking space
+// is shown as ⍽, zero width characters and byte order marks as <ZWSP>, <ZWJ>,
+// <ZWNJ>, <WJ>, or <BOM>, and other invisible characters as <U+XXXX>. Lines
+// indented with a mix of tabs and spaces also enable the visible characters.
+//
+// Use EqualT to make comparing values of different types a compile error.
 //
 // Equal uses t.FailNow to fail the test. Like t.FailNow, Equal must be
 // called from the goroutine running the test function, not from other
@@ -210,7 +303,8 @@ func Equal(t TestingT, x, y interface{}, msgAndArgs ...interface{}) {
 // to assert two values are equal and fails the test if they are not equal.
 //
 // Package http://pkg.go.dev/gotest.tools/v3/assert/opt provides some additional
-// commonly used Options.
+// commonly used Options. Use DeepEqualT to require x and y to have the same
+// type.
 //
 // DeepEqual uses t.FailNow to fail the test. Like t.FailNow, DeepEqual must be
 // called from the goroutine running the test function, not from other
@@ -310,3 +404,76 @@ func ErrorIs(t TestingT, err error, expected error, msgAndArgs ...interface{}) {
 		t.FailNow()
 	}
 }
+
+// ErrorAs fails the test if err is nil, or if no error in the chain of err
+// matches target when compared using errors.As. When it succeeds target is set
+// to the matching error, so that it can be used by the rest of the test.
+//
+//	var pathErr *fs.PathError
+//	assert.ErrorAs(t, err, &pathErr)
+//	assert.Equal(t, pathErr.Path, "config.yaml")
+//
+// target must be a non-nil pointer to a type which implements error, or to an
+// interface type. The failure message includes the chain of err, and the type
+// of target. See https://golang.org/pkg/errors/#As for details.
+//
+// ErrorAs uses t.FailNow to fail the test. Like t.FailNow, ErrorAs
+// must be called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.ErrorAs from other
+// goroutines.
+func ErrorAs(t TestingT, err error, target interface{}, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.ErrorAs(err, target), msgAndArgs...) {
+		t.FailNow()
+	}
+}
+
+// MaxAllocs fails the test if fn makes more than max heap allocations on
+// average, counted like testing.AllocsPerRun. See cmp.MaxAllocs.
+//
+// MaxAllocs uses t.FailNow to fail the test. Like t.FailNow, MaxAllocs
+// must be called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.MaxAllocs from other
+// goroutines.
+func MaxAllocs(t TestingT, max int, fn func(), msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.MaxAllocs(max, fn), msgAndArgs...) {
+		t.FailNow()
+	}
+}
+
+// ContextDone fails the test if ctx is not done. ContextDone does not wait for
+// ctx to be done. See cmp.ContextDone.
+//
+// ContextDone uses t.FailNow to fail the test. Like t.FailNow, ContextDone
+// must be called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.ContextDone from other
+// goroutines.
+func ContextDone(t TestingT, ctx context.Context, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.ContextDone(ctx), msgAndArgs...) {
+		t.FailNow()
+	}
+}
+
+// ContextNotDone fails the test if ctx is done, or if ctx becomes done before
+// the within duration has passed. See cmp.ContextNotDone.
+//
+// ContextNotDone uses t.FailNow to fail the test. Like t.FailNow, ContextNotDone
+// must be called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.ContextNotDone from
+// other goroutines.
+func ContextNotDone(t TestingT, ctx context.Context, within time.Duration, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.ContextNotDone(ctx, within), msgAndArgs...) {
+		t.FailNow()
+	}
+}
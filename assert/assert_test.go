@@ -1,9 +1,12 @@
 package assert
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	gocmp "github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert/cmp"
@@ -468,3 +471,113 @@ func TestErrorIs(t *testing.T) {
 		expectSuccess(t, fakeT)
 	})
 }
+
+func TestErrorAs(t *testing.T) {
+	t.Run("nil error", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+
+		var err error
+		var target *os.PathError
+		ErrorAs(fakeT, err, &target)
+		expectFailNowed(t, fakeT, "assertion failed: error is nil, not *fs.PathError")
+	})
+	t.Run("different error", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+
+		err := fmt.Errorf("the actual error")
+		var target *os.PathError
+		ErrorAs(fakeT, err, &target)
+		expected := `assertion failed: error chain of "the actual error" does not contain *fs.PathError:
+  *errors.errorString: the actual error`
+		expectFailNowed(t, fakeT, expected)
+	})
+	t.Run("matching error", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+
+		_, err := os.Open("/does-not-exist")
+		err = fmt.Errorf("some wrapping: %w", err)
+		var target *os.PathError
+		ErrorAs(fakeT, err, &target)
+		expectSuccess(t, fakeT)
+		Equal(t, target.Path, "/does-not-exist")
+	})
+}
+
+var allocSink []byte
+
+func TestMaxAllocs(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		MaxAllocs(fakeT, 0, func() {})
+		expectSuccess(t, fakeT)
+	})
+	t.Run("failure", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		MaxAllocs(fakeT, 0, func() {
+			allocSink = make([]byte, 1024)
+		})
+		expected := "assertion failed: expected at most 0 allocations per run, got 1"
+		expectFailNowed(t, fakeT, expected)
+	})
+}
+
+func TestContextDone(t *testing.T) {
+	t.Run("done", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ContextDone(fakeT, ctx)
+		expectSuccess(t, fakeT)
+	})
+	t.Run("not done", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		ContextDone(fakeT, context.Background())
+		expectFailNowed(t, fakeT, "assertion failed: context is not done")
+	})
+}
+
+func TestContextNotDone(t *testing.T) {
+	t.Run("not done", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		ContextNotDone(fakeT, context.Background(), time.Millisecond)
+		expectSuccess(t, fakeT)
+	})
+	t.Run("done", func(t *testing.T) {
+		fakeT := &fakeTestingT{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ContextNotDone(fakeT, ctx, time.Millisecond)
+		expectFailNowed(t, fakeT, "assertion failed: context is done: context canceled")
+	})
+}
+
+func TestFailureWithSourceSnippet(t *testing.T) {
+	orig, ok := os.LookupEnv("GOTESTTOOLS_SOURCE_SNIPPET")
+	os.Setenv("GOTESTTOOLS_SOURCE_SNIPPET", "1")
+	defer func() {
+		if ok {
+			os.Setenv("GOTESTTOOLS_SOURCE_SNIPPET", orig)
+			return
+		}
+		os.Unsetenv("GOTESTTOOLS_SOURCE_SNIPPET")
+	}()
+
+	fakeT := &fakeTestingT{}
+	actual, expected := 1, 3
+	Equal(fakeT, actual, expected)
+	expectFailNowed(t, fakeT, "assertion failed: 1 (actual int) != 3 (expected int)")
+
+	if len(fakeT.msgs) != 2 {
+		t.Fatalf("expected a snippet, got messages %q", fakeT.msgs)
+	}
+	snippet := fakeT.msgs[1]
+	for _, expected := range []string{
+		"\tactual, expected := 1, 3\n",
+		"\tEqual(fakeT, actual, expected)\n",
+		"| \t^~~~~~~~~~~~~~~~~~~~~~~~~~~~~~\n",
+	} {
+		if !strings.Contains(snippet, expected) {
+			t.Fatalf("expected snippet to contain %q, got:\n%s", expected, snippet)
+		}
+	}
+}
@@ -0,0 +1,444 @@
+/*
+Package assertlint provides an analyzer which reports common mistakes in the
+use of gotest.tools/v3/assert and gotest.tools/v3/assert/cmp.
+
+The analyzer reports:
+
+  - Equal and DeepEqual with a constant as the actual (first) value, and a
+    variable as the expected (second) value. The failure message uses the
+    position of the arguments to identify the actual and expected values.
+  - Equal with a value which can not be compared with ==, like a map, slice,
+    or func. These comparisons panic, DeepEqual should be used instead.
+  - A cmp.Comparison which is created but never passed to an assertion, and a
+    Check of an error which is ignored when the values returned with the error
+    are used after the Check.
+  - Messages in msgAndArgs which have a different number of formatting
+    directives than arguments, or which are not a format string.
+  - Comparisons of errors which hide the error from the failure message, like
+    Equal(t, err.Error(), "message"), Equal(t, err, nil), and
+    Assert(t, err == nil).
+
+The analyzer can be run with the gty-assertlint command, or with any driver
+that supports go/analysis, like go vet -vettool.
+*/
+package assertlint
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	pkgAssert = "gotest.tools/v3/assert"
+	pkgCmp    = "gotest.tools/v3/assert/cmp"
+)
+
+// Analyzer reports common mistakes in the use of gotest.tools/v3/assert.
+var Analyzer = &analysis.Analyzer{
+	Name:     "assertlint",
+	Doc:      "report common mistakes in the use of gotest.tools/v3/assert",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodeFilter := []ast.Node{
+		(*ast.CallExpr)(nil),
+		(*ast.BlockStmt)(nil),
+		(*ast.CaseClause)(nil),
+		(*ast.CommClause)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(node ast.Node) {
+		switch typed := node.(type) {
+		case *ast.CallExpr:
+			checkCall(pass, typed)
+		case *ast.BlockStmt:
+			checkStmts(pass, typed.List)
+		case *ast.CaseClause:
+			checkStmts(pass, typed.Body)
+		case *ast.CommClause:
+			checkStmts(pass, typed.Body)
+		}
+	})
+	return nil, nil
+}
+
+// assertCall is a call to a function in the assert or cmp packages.
+type assertCall struct {
+	expr *ast.CallExpr
+	fn   *types.Func
+	// args are the arguments after t, for functions in the assert package.
+	args []ast.Expr
+}
+
+func (c assertCall) pkg() string {
+	return c.fn.Pkg().Path()
+}
+
+func (c assertCall) name() string {
+	return c.fn.Pkg().Name() + "." + c.fn.Name()
+}
+
+func (c assertCall) is(pkg, name string) bool {
+	return c.pkg() == pkg && c.fn.Name() == name
+}
+
+// newAssertCall returns the assertCall for expr, or false if expr is not a
+// call to a function in the assert or cmp packages.
+func newAssertCall(pass *analysis.Pass, expr *ast.CallExpr) (assertCall, bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, expr).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return assertCall{}, false
+	}
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		return assertCall{}, false
+	}
+	c := assertCall{expr: expr, fn: fn, args: expr.Args}
+	switch c.pkg() {
+	case pkgAssert:
+		if len(c.args) == 0 {
+			return assertCall{}, false
+		}
+		c.args = c.args[1:]
+	case pkgCmp:
+	default:
+		return assertCall{}, false
+	}
+	return c, true
+}
+
+func checkCall(pass *analysis.Pass, expr *ast.CallExpr) {
+	c, ok := newAssertCall(pass, expr)
+	if !ok {
+		return
+	}
+	switch c.fn.Name() {
+	case "Equal":
+		if len(c.args) >= 2 {
+			checkSwapped(pass, c)
+			checkComparable(pass, c)
+			checkEqualErrors(pass, c)
+		}
+	case "DeepEqual":
+		if len(c.args) >= 2 {
+			checkSwapped(pass, c)
+			checkEqualErrors(pass, c)
+		}
+	case "Assert", "Check":
+		if c.pkg() == pkgAssert && len(c.args) >= 1 {
+			checkBoolErrors(pass, c)
+		}
+	}
+	if c.pkg() == pkgAssert {
+		checkMsgAndArgs(pass, c)
+	}
+}
+
+// checkSwapped reports an Equal or DeepEqual where the actual value is a
+// constant, and the expected value is not.
+func checkSwapped(pass *analysis.Pass, c assertCall) {
+	if isConstant(pass, c.args[0]) && !isConstant(pass, c.args[1]) {
+		pass.Reportf(c.args[0].Pos(),
+			"%s has a constant as the actual value, the expected value should be the second argument",
+			c.name())
+	}
+}
+
+func isConstant(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && (tv.Value != nil || tv.IsNil())
+}
+
+// checkComparable reports an Equal with a value which can not be compared
+// with ==.
+func checkComparable(pass *analysis.Pass, c assertCall) {
+	for _, arg := range c.args[:2] {
+		typ := pass.TypesInfo.TypeOf(arg)
+		if typ == nil || isNil(pass, arg) || types.Comparable(typ) {
+			continue
+		}
+		if _, ok := typ.Underlying().(*types.Signature); ok {
+			pass.Reportf(arg.Pos(), "%s with a func value panics, funcs can only be compared to nil",
+				c.name())
+			return
+		}
+		pass.Reportf(arg.Pos(), "%s with a value of type %s panics, use %s.DeepEqual",
+			c.name(), typ, c.fn.Pkg().Name())
+		return
+	}
+}
+
+// checkEqualErrors reports an Equal or DeepEqual which compares an error, or
+// the message of an error.
+func checkEqualErrors(pass *analysis.Pass, c assertCall) {
+	x, y := c.args[0], c.args[1]
+	for _, arg := range []ast.Expr{x, y} {
+		if isErrorMessage(pass, arg) {
+			pass.Reportf(arg.Pos(), "%s of an error message panics when the error is nil, use %s",
+				c.name(), suggest(c, "Error"))
+			return
+		}
+	}
+	if !isError(pass, x) && !isError(pass, y) {
+		return
+	}
+	if isNil(pass, x) || isNil(pass, y) {
+		pass.Reportf(c.expr.Pos(), "%s of an error with nil, use %s", c.name(), suggestNilError(c))
+		return
+	}
+	pass.Reportf(c.expr.Pos(), "%s of errors does not match wrapped errors, use %s",
+		c.name(), suggest(c, "ErrorIs"))
+}
+
+// checkBoolErrors reports an Assert or Check of a boolean expression which
+// compares an error, because the failure message does not include the error.
+func checkBoolErrors(pass *analysis.Pass, c assertCall) {
+	switch arg := astutil.Unparen(c.args[0]).(type) {
+	case *ast.BinaryExpr:
+		if arg.Op != token.EQL || !isError(pass, arg.X) && !isError(pass, arg.Y) {
+			return
+		}
+		if isNil(pass, arg.X) || isNil(pass, arg.Y) {
+			pass.Reportf(arg.Pos(), "%s of err == nil does not print the error, use %s",
+				c.name(), suggestNilError(c))
+			return
+		}
+		pass.Reportf(arg.Pos(), "%s of errors does not match wrapped errors, use %s",
+			c.name(), suggestCmp(c, "ErrorIs"))
+	case *ast.CallExpr:
+		fn, ok := typeutil.Callee(pass.TypesInfo, arg).(*types.Func)
+		if !ok || fn.Pkg() == nil {
+			return
+		}
+		switch {
+		case fn.Pkg().Path() == "errors" && fn.Name() == "Is":
+			pass.Reportf(arg.Pos(), "%s of errors.Is does not print the error, use %s",
+				c.name(), suggestCmp(c, "ErrorIs"))
+		case fn.Pkg().Path() == "strings" && fn.Name() == "Contains" &&
+			len(arg.Args) == 2 && isErrorMessage(pass, arg.Args[0]):
+			pass.Reportf(arg.Pos(), "%s of strings.Contains with an error message panics when the error is nil, use %s",
+				c.name(), suggestCmp(c, "ErrorContains"))
+		}
+	}
+}
+
+// suggest returns the name of the function in the package of c which should
+// be used instead of c.
+func suggest(c assertCall, name string) string {
+	return c.fn.Pkg().Name() + "." + name
+}
+
+// suggestCmp returns the assertion which should be used instead of c, which
+// is Assert or Check.
+func suggestCmp(c assertCall, name string) string {
+	if c.is(pkgAssert, "Check") {
+		return "assert.Check with cmp." + name
+	}
+	return "assert." + name
+}
+
+// suggestNilError returns the assertion which should be used to check that
+// an error is nil, in place of c.
+func suggestNilError(c assertCall) string {
+	switch {
+	case c.pkg() == pkgCmp:
+		return "cmp.Nil"
+	case c.is(pkgAssert, "Check"):
+		return "assert.Check(t, err)"
+	}
+	return "assert.NilError"
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func isError(pass *analysis.Pass, expr ast.Expr) bool {
+	typ := pass.TypesInfo.TypeOf(expr)
+	return typ != nil && types.Identical(typ, errorType)
+}
+
+func isNil(pass *analysis.Pass, expr ast.Expr) bool {
+	tv, ok := pass.TypesInfo.Types[expr]
+	return ok && tv.IsNil()
+}
+
+// isErrorMessage returns true if expr is a call to the Error method of an
+// error.
+func isErrorMessage(pass *analysis.Pass, expr ast.Expr) bool {
+	call, ok := astutil.Unparen(expr).(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && selector.Sel.Name == "Error" && isError(pass, selector.X)
+}
+
+// checkMsgAndArgs reports a msgAndArgs with a different number of formatting
+// directives than arguments.
+func checkMsgAndArgs(pass *analysis.Pass, c assertCall) {
+	sig := c.fn.Type().(*types.Signature)
+	params := sig.Params()
+	if !sig.Variadic() || params.At(params.Len()-1).Name() != "msgAndArgs" || c.expr.Ellipsis.IsValid() {
+		return
+	}
+	// the first param is t, which is not in c.args
+	first := params.Len() - 2
+	if len(c.args) <= first {
+		return
+	}
+	msgAndArgs := c.args[first:]
+	msg := msgAndArgs[0]
+
+	if len(msgAndArgs) > 1 && !isString(pass, msg) {
+		pass.Reportf(msg.Pos(), "%s message with arguments must be a format string", c.name())
+		return
+	}
+	tv, ok := pass.TypesInfo.Types[msg]
+	if !ok || tv.Value == nil || !isString(pass, msg) {
+		return
+	}
+	verbs, ok := countVerbs(constant.StringVal(tv.Value))
+	switch {
+	case !ok:
+	case len(msgAndArgs) == 1 && verbs > 0:
+		pass.Reportf(msg.Pos(), "%s message has formatting directives but no arguments", c.name())
+	case len(msgAndArgs) > 1 && verbs != len(msgAndArgs)-1:
+		pass.Reportf(msg.Pos(), "%s message has %d formatting directives but %d arguments",
+			c.name(), verbs, len(msgAndArgs)-1)
+	}
+}
+
+func isString(pass *analysis.Pass, expr ast.Expr) bool {
+	typ := pass.TypesInfo.TypeOf(expr)
+	if typ == nil {
+		return false
+	}
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// countVerbs returns the number of formatting directives in format which use
+// an argument. Returns false if the directives use explicit argument indexes
+// or a width or precision from an argument, which are not counted.
+func countVerbs(format string) (int, bool) {
+	count := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0123456789.", format[i]) >= 0 {
+			i++
+		}
+		if i == len(format) {
+			break
+		}
+		switch format[i] {
+		case '%':
+		case '[', '*':
+			return 0, false
+		default:
+			count++
+		}
+	}
+	return count, true
+}
+
+// checkStmts reports statements in a block which discard the result of a
+// comparison, or which continue to use values after a failed check of an
+// error.
+func checkStmts(pass *analysis.Pass, stmts []ast.Stmt) {
+	for i, stmt := range stmts {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		call, ok := astutil.Unparen(exprStmt.X).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		if isComparison(pass.TypesInfo.TypeOf(call)) {
+			pass.Reportf(call.Pos(), "result of comparison is not used, pass it to assert.Check or assert.Assert")
+			continue
+		}
+		c, ok := newAssertCall(pass, call)
+		if !ok || !c.is(pkgAssert, "Check") || len(c.args) == 0 || i == 0 {
+			continue
+		}
+		checkIgnoredCheck(pass, c, stmts[i-1], stmts[i+1:])
+	}
+}
+
+func isComparison(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgCmp && obj.Name() == "Comparison"
+}
+
+// checkIgnoredCheck reports a Check of an error, where the result of Check is
+// ignored and the other values assigned with the error are used after the
+// Check. Those values are usually not valid when the error is not nil.
+func checkIgnoredCheck(pass *analysis.Pass, c assertCall, prev ast.Stmt, next []ast.Stmt) {
+	errIdent, ok := astutil.Unparen(c.args[0]).(*ast.Ident)
+	if !ok || !isError(pass, errIdent) {
+		return
+	}
+	assign, ok := prev.(*ast.AssignStmt)
+	if !ok {
+		return
+	}
+	errObj := pass.TypesInfo.ObjectOf(errIdent)
+	var values []types.Object
+	assignsErr := false
+	for _, lhs := range assign.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			continue
+		}
+		obj := pass.TypesInfo.ObjectOf(ident)
+		if obj == errObj {
+			assignsErr = true
+			continue
+		}
+		values = append(values, obj)
+	}
+	if !assignsErr {
+		return
+	}
+	for _, obj := range values {
+		if usesObject(pass, next, obj) {
+			pass.Reportf(c.expr.Pos(),
+				"%s does not stop the test when %s is not nil, and %s is used after the check, use assert.NilError",
+				c.name(), errIdent.Name, obj.Name())
+			return
+		}
+	}
+}
+
+func usesObject(pass *analysis.Pass, stmts []ast.Stmt, obj types.Object) bool {
+	found := false
+	for _, stmt := range stmts {
+		ast.Inspect(stmt, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok && pass.TypesInfo.Uses[ident] == obj {
+				found = true
+			}
+			return !found
+		})
+		if found {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+module gotest.tools/v3/assert/assertlint/golangci
+
+go 1.25.0
+
+replace gotest.tools/v3 => ../../../
+
+require (
+	github.com/golangci/plugin-module-register v0.1.2
+	golang.org/x/tools v0.36.0
+	gotest.tools/v3 v3.0.0-00010101000000-000000000000
+)
+
+require github.com/google/go-cmp v0.6.0 // indirect
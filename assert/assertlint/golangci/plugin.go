@@ -0,0 +1,49 @@
+/*
+Package golangci registers the assertlint analyzer as a golangci-lint module
+plugin. It is a separate module, to keep the golangci-lint dependencies out of
+gotest.tools.
+
+To build golangci-lint with the plugin add it to .custom-gcl.yml:
+
+	version: v2.5.0
+	plugins:
+	  - module: gotest.tools/v3/assert/assertlint/golangci
+	    import: gotest.tools/v3/assert/assertlint/golangci
+	    version: latest
+
+and enable the linter in .golangci.yml:
+
+	linters:
+	  enable:
+	    - assertlint
+	  settings:
+	    custom:
+	      assertlint:
+	        type: module
+*/
+package golangci
+
+import (
+	"github.com/golangci/plugin-module-register/register"
+	"golang.org/x/tools/go/analysis"
+	"gotest.tools/v3/assert/assertlint"
+)
+
+func init() {
+	register.Plugin("assertlint", New)
+}
+
+// New returns the assertlint plugin. The plugin has no settings.
+func New(settings any) (register.LinterPlugin, error) {
+	return plugin{}, nil
+}
+
+type plugin struct{}
+
+func (plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	return []*analysis.Analyzer{assertlint.Analyzer}, nil
+}
+
+func (plugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
@@ -0,0 +1,23 @@
+package golangci
+
+import (
+	"testing"
+
+	"github.com/golangci/plugin-module-register/register"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/assertlint"
+)
+
+func TestPlugin(t *testing.T) {
+	newPlugin, err := register.GetPlugin("assertlint")
+	assert.NilError(t, err)
+
+	p, err := newPlugin(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, p.GetLoadMode(), register.LoadModeTypesInfo)
+
+	analyzers, err := p.BuildAnalyzers()
+	assert.NilError(t, err)
+	assert.Equal(t, len(analyzers), 1)
+	assert.Equal(t, analyzers[0], assertlint.Analyzer)
+}
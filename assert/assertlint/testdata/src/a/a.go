@@ -0,0 +1,76 @@
+package a
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestSwapped(t *testing.T) {
+	count := 2
+	assert.Equal(t, 2, count)                 // want `assert.Equal has a constant as the actual value`
+	assert.DeepEqual(t, "a", strconv.Itoa(1)) // want `assert.DeepEqual has a constant as the actual value`
+	assert.Check(t, is.Equal(2, count))       // want `cmp.Equal has a constant as the actual value`
+	assert.Equal(t, count, 2)
+	assert.Equal(t, 1, 1)
+}
+
+func TestComparable(t *testing.T) {
+	m := map[string]int{}
+	assert.Equal(t, m, map[string]int{})  // want `assert.Equal with a value of type map\[string\]int panics, use assert.DeepEqual`
+	assert.Check(t, is.Equal([]int{}, 3)) // want `cmp.Equal with a value of type \[\]int panics, use cmp.DeepEqual`
+	assert.Equal(t, TestComparable, nil)  // want `assert.Equal with a func value panics`
+	assert.DeepEqual(t, m, map[string]int{})
+}
+
+func TestIgnoredResult(t *testing.T) {
+	is.Equal(1, 2) // want `result of comparison is not used`
+
+	value, err := strconv.Atoi("1")
+	assert.Check(t, err) // want `assert.Check does not stop the test when err is not nil, and value is used after the check, use assert.NilError`
+	_ = value + 1
+
+	other, err := strconv.Atoi("2")
+	assert.NilError(t, err)
+	_ = other
+
+	if !assert.Check(t, err) {
+		return
+	}
+	_, err = strconv.Atoi("3")
+	assert.Check(t, err)
+}
+
+func TestMsgAndArgs(t *testing.T) {
+	value := 1
+	assert.Equal(t, value, 1, "value %d")            // want `assert.Equal message has formatting directives but no arguments`
+	assert.Equal(t, value, 1, "value %d %s", value)  // want `assert.Equal message has 2 formatting directives but 1 arguments`
+	assert.Assert(t, value == 1, value, "something") // want `assert.Assert message with arguments must be a format string`
+	assert.Equal(t, value, 1, "value %d", value)
+	assert.Equal(t, value, 1, "100%% done")
+	assert.Equal(t, value, 1, "value %[1]d %[1]d", value)
+	assert.Equal(t, value, 1, "value %*d", 3, value)
+	assert.Equal(t, value, 1, value)
+}
+
+func TestErrors(t *testing.T) {
+	err := errors.New("not found")
+	assert.Equal(t, err.Error(), "not found")                    // want `assert.Equal of an error message panics when the error is nil, use assert.Error`
+	assert.Equal(t, err, nil)                                    // want `assert.Equal of an error with nil, use assert.NilError`
+	assert.Check(t, is.Equal(err, errNotFound))                  // want `cmp.Equal of errors does not match wrapped errors, use cmp.ErrorIs`
+	assert.DeepEqual(t, err, errNotFound)                        // want `assert.DeepEqual of errors does not match wrapped errors, use assert.ErrorIs`
+	assert.Assert(t, err == nil)                                 // want `assert.Assert of err == nil does not print the error, use assert.NilError`
+	assert.Check(t, err == nil)                                  // want `assert.Check of err == nil does not print the error, use assert.Check\(t, err\)`
+	assert.Assert(t, err == errNotFound)                         // want `assert.Assert of errors does not match wrapped errors, use assert.ErrorIs`
+	assert.Check(t, errors.Is(err, errNotFound))                 // want `assert.Check of errors.Is does not print the error, use assert.Check with cmp.ErrorIs`
+	assert.Assert(t, strings.Contains(err.Error(), "not found")) // want `assert.Assert of strings.Contains with an error message panics when the error is nil, use assert.ErrorContains`
+	assert.Assert(t, err != nil)
+	assert.ErrorIs(t, err, errNotFound)
+	assert.Error(t, err, "not found")
+}
@@ -0,0 +1,29 @@
+// Package assert is a stub of gotest.tools/v3/assert for the tests of the
+// analyzer.
+package assert
+
+import "gotest.tools/v3/assert/cmp"
+
+type TestingT interface {
+	FailNow()
+	Fail()
+	Log(args ...interface{})
+}
+
+type BoolOrComparison interface{}
+
+func Assert(t TestingT, comparison BoolOrComparison, msgAndArgs ...interface{}) {}
+
+func Check(t TestingT, comparison BoolOrComparison, msgAndArgs ...interface{}) bool {
+	return true
+}
+
+func NilError(t TestingT, err error, msgAndArgs ...interface{}) {}
+
+func Equal(t TestingT, x, y interface{}, msgAndArgs ...interface{}) {}
+
+func DeepEqual(t TestingT, x, y interface{}, opts ...cmp.Option) {}
+
+func Error(t TestingT, err error, expected string, msgAndArgs ...interface{}) {}
+
+func ErrorIs(t TestingT, err error, expected error, msgAndArgs ...interface{}) {}
@@ -0,0 +1,25 @@
+/*
+Command gty-assertlint reports common mistakes in the use of
+gotest.tools/v3/assert. See gotest.tools/v3/assert/assertlint for the list of
+checks.
+
+	$ go install gotest.tools/v3/assert/cmd/gty-assertlint
+
+Usage:
+
+	gty-assertlint [OPTIONS] PACKAGE [PACKAGE...]
+
+The command can also be run by go vet:
+
+	go vet -vettool=$(which gty-assertlint) ./...
+*/
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+	"gotest.tools/v3/assert/assertlint"
+)
+
+func main() {
+	singlechecker.Main(assertlint.Analyzer)
+}
@@ -6,6 +6,8 @@ import (
 	"go/ast"
 	"go/format"
 	"go/token"
+
+	"gotest.tools/v3/assert/cmd/internal/migrate"
 )
 
 // call wraps a testify/assert ast.CallExpr and exposes properties of the
@@ -32,7 +34,7 @@ func (c call) StringWithFileInfo() string {
 		return fmt.Sprintf("%s at unknown file", c)
 	}
 	return fmt.Sprintf("%s at %s:%d", c,
-		relativePath(c.fileset.File(c.expr.Pos()).Name()),
+		migrate.RelativePath(c.fileset.File(c.expr.Pos()).Name()),
 		c.fileset.Position(c.expr.Pos()).Line)
 }
 
@@ -92,10 +94,10 @@ func newTestifyCallFromNode(callExpr *ast.CallExpr, migration migration) (call,
 		return tcall, false
 	}
 
-	testifyNewAssignStmt := testifyAssertionsAssignment(tcall, migration)
+	testifyNewCallExpr := testifyAssertionsDecl(tcall, migration)
 	switch {
-	case testifyNewAssignStmt != nil:
-		return updateCallForTestifyNew(tcall, testifyNewAssignStmt, migration)
+	case testifyNewCallExpr != nil:
+		return updateCallForTestifyNew(tcall, testifyNewCallExpr, migration)
 	case isTestifyPkgCall(tcall, migration):
 		tcall.assert = migration.importNames.funcNameFromTestifyName(tcall.xIdent.Name)
 		return tcall, true
@@ -113,34 +115,33 @@ func isTestifyPkgCall(tcall call, migration migration) bool {
 	return migration.importNames.matchesTestify(tcall.xIdent)
 }
 
-// testifyAssertionsAssignment returns an ast.AssignStmt if the call is a testify
-// call from an Assertions object returned from assert.New(t) (not a package level
-// assert). Otherwise returns nil.
-func testifyAssertionsAssignment(tcall call, migration migration) *ast.AssignStmt {
+// testifyAssertionsDecl returns the assert.New(t) call expression if the call
+// is a testify call from an Assertions object returned from assert.New(t) (not
+// a package level assert). The Assertions object may be declared with := or
+// var. Otherwise returns nil.
+func testifyAssertionsDecl(tcall call, migration migration) *ast.CallExpr {
 	if tcall.xIdent.Obj == nil {
 		return nil
 	}
 
-	assignStmt, ok := tcall.xIdent.Obj.Decl.(*ast.AssignStmt)
-	if !ok {
-		return nil
+	var callExpr *ast.CallExpr
+	switch decl := tcall.xIdent.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		callExpr = callExprFromAssignment(decl)
+	case *ast.ValueSpec:
+		callExpr = callExprFromValueSpec(decl)
 	}
-
-	if isAssignmentFromAssertNew(assignStmt, migration) {
-		return assignStmt
+	if callExpr != nil && isCallToAssertNew(callExpr, migration) {
+		return callExpr
 	}
 	return nil
 }
 
 func updateCallForTestifyNew(
 	tcall call,
-	testifyNewAssignStmt *ast.AssignStmt,
+	testifyNewCallExpr *ast.CallExpr,
 	migration migration,
 ) (call, bool) {
-	testifyNewCallExpr := callExprFromAssignment(testifyNewAssignStmt)
-	if testifyNewCallExpr == nil {
-		return tcall, false
-	}
 	testifyNewCall, ok := newCallFromCallExpr(testifyNewCallExpr, migration)
 	if !ok {
 		return tcall, false
@@ -171,9 +172,25 @@ func removePos(node ast.Expr) ast.Expr {
 // TODO: use pkgInfo and walkForType instead?
 func isAssignmentFromAssertNew(assign *ast.AssignStmt, migration migration) bool {
 	callExpr := callExprFromAssignment(assign)
-	if callExpr == nil {
+	return callExpr != nil && isCallToAssertNew(callExpr, migration)
+}
+
+// isDeclFromAssertNew returns true if the statement is a var declaration of a
+// single Assertions object, for example: var is = assert.New(t)
+func isDeclFromAssertNew(decl *ast.DeclStmt, migration migration) bool {
+	genDecl, ok := decl.Decl.(*ast.GenDecl)
+	if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+		return false
+	}
+	spec, ok := genDecl.Specs[0].(*ast.ValueSpec)
+	if !ok {
 		return false
 	}
+	callExpr := callExprFromValueSpec(spec)
+	return callExpr != nil && isCallToAssertNew(callExpr, migration)
+}
+
+func isCallToAssertNew(callExpr *ast.CallExpr, migration migration) bool {
 	tcall, ok := newCallFromCallExpr(callExpr, migration)
 	if !ok {
 		return false
@@ -199,3 +216,15 @@ func callExprFromAssignment(assign *ast.AssignStmt) *ast.CallExpr {
 	}
 	return callExpr
 }
+
+func callExprFromValueSpec(spec *ast.ValueSpec) *ast.CallExpr {
+	if len(spec.Names) != 1 || len(spec.Values) != 1 {
+		return nil
+	}
+
+	callExpr, ok := spec.Values[0].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+	return callExpr
+}
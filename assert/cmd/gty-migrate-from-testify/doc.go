@@ -2,6 +2,54 @@
 Command gty-migrate-from-testify migrates packages from
 testify/assert and testify/require to gotest.tools/v3/assert.
 
+Assertions from testify/require are migrated to assertions which stop the
+test, like assert.Assert, and assertions from testify/assert are migrated to
+assertions which continue the test, like assert.Check. Assertions on an
+object returned by assert.New(t) are migrated to package level assertions.
+
+Suites from testify/suite are migrated to gotest.tools/v3/suite. The
+suite.Suite field is removed from the suite struct, and a t *testing.T
+parameter is added to test methods, setup and teardown methods, and helper
+methods which use the suite. Assertion methods on the suite, like s.Equal and
+s.Require().NoError, are migrated like the package level assertions, s.T() is
+replaced by t, and s.Run is replaced by t.Run. BeforeTest, AfterTest, and the
+sub-test hooks are not supported, and are reported so they can be migrated by
+hand.
+
+Use --suites=subtests to remove the dependency on a suite package. Each
+suite.Run(t, s) is replaced by a call to t.Run for each test method of the
+suite, which calls the SetupTest and TearDownTest methods of the suite around
+the test method.
+
+References to testify which are not migrated, like assert.AnError, mocks from
+testify/mock, or assertions which are not supported, are left in place, and
+the testify import is kept. When the name of the testify package conflicts
+with gotest.tools/v3/assert, the import is renamed to testifyassert or
+testifyrequire.
+
+# Migrating from gocheck
+
+Use --from=gocheck to migrate packages from gopkg.in/check.v1, or
+--from=testify,gocheck to migrate from both libraries. Parameters of type
+*check.C are changed to *testing.T, and calls to c.Assert and c.Check with the
+Equals, DeepEquals, IsNil, NotNil, HasLen, ErrorMatches, and Matches checkers
+are migrated to assertions. Suites registered with check.Suite are run with
+gotest.tools/v3/suite, and SetUpSuite and SetUpTest are renamed to SetupSuite
+and SetupTest. Other checkers are reported so they can be migrated by hand.
+
+# Migrating from Gomega
+
+Use --from=gomega to migrate expectations from github.com/onsi/gomega. An
+expectation like g.Expect(x).To(Equal(y)) is migrated to an assertion which
+stops the test, using the t passed to gomega.NewWithT(t), or to
+gomega.RegisterTestingT(t) for the package level Expect. The Equal, BeNil,
+HaveOccurred, Succeed, BeTrue, BeFalse, HaveLen, BeEmpty, ContainSubstring,
+ContainElement, HaveKey, MatchRegexp, MatchError, and BeNumerically matchers
+are supported. Eventually(f, timeout, interval).Should(matcher) is migrated
+to poll.WaitOn with a check which compares the value returned by f. Other
+matchers, and expectations which could not be translated, are reported so
+they can be migrated by hand.
+
 	$ go get gotest.tools/v3/assert/cmd/gty-migrate-from-testify
 
 Usage:
@@ -10,6 +58,11 @@ Usage:
 
 See --help for full usage.
 
+Use --dry-run to review a migration before making it. The files are not
+changed; a unified diff of the changes is printed to stdout, and a summary of
+the calls in each file which could not be migrated is printed to stderr. The
+diff can be applied with git apply, or with patch -p1.
+
 To run on all packages (including external test packages) use:
 
 	go list \
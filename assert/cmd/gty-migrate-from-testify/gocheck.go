@@ -0,0 +1,415 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	pkgGocheck       = "gopkg.in/check.v1"
+	pkgGithubGocheck = "github.com/go-check/check"
+)
+
+func isGocheckPkg(pkgPath string) bool {
+	return pkgPath == pkgGocheck || pkgPath == pkgGithubGocheck
+}
+
+// gocheckHooks maps the names of gocheck fixture methods to the names of the
+// hooks of gotest.tools/v3/suite.
+var gocheckHooks = map[string]string{
+	"SetUpSuite":    "SetupSuite",
+	"TearDownSuite": "TearDownSuite",
+	"SetUpTest":     "SetupTest",
+	"TearDownTest":  "TearDownTest",
+}
+
+// gocheckRenamedMethods maps methods of check.C to the equivalent methods of
+// testing.T. Methods of check.C which have the same name as a method of
+// testing.T are not listed.
+var gocheckRenamedMethods = map[string]string{
+	"MkDir":    "TempDir",
+	"TestName": "Name",
+}
+
+var gocheckSameMethods = map[string]bool{
+	"Log":     true,
+	"Logf":    true,
+	"Error":   true,
+	"Errorf":  true,
+	"Fatal":   true,
+	"Fatalf":  true,
+	"Fail":    true,
+	"FailNow": true,
+	"Failed":  true,
+	"Skip":    true,
+}
+
+// migrateGocheck converts gocheck suites and assertions to gotest.tools.
+// Parameters of type *check.C are changed to *testing.T, c.Assert and c.Check
+// with the gocheck checkers are replaced with assertions, and suites
+// registered with check.Suite are run with gotest.tools/v3/suite.
+func migrateGocheck(migration migration) {
+	if migration.importNames.gocheck == "" {
+		return
+	}
+	m := gocheckMigration{migration: migration}
+	astutil.Apply(migration.file, nil, m.replace)
+
+	if m.migrateSuites() {
+		astutil.AddImport(migration.fileset, migration.file, pkgSuite)
+	}
+	for _, pkg := range []string{pkgGocheck, pkgGithubGocheck} {
+		astutil.DeleteImport(migration.fileset, migration.file, pkg)
+		astutil.DeleteNamedImport(migration.fileset, migration.file, migration.importNames.gocheck, pkg)
+	}
+	astutil.AddImport(migration.fileset, migration.file, "testing")
+}
+
+type gocheckMigration struct {
+	migration
+}
+
+// gocheckObj returns the name of the object in the gocheck package which is
+// referenced by expr, or an empty string if expr is not a reference to the
+// gocheck package.
+func (m gocheckMigration) gocheckObj(expr ast.Expr) string {
+	var ident *ast.Ident
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		ident = typed
+	case *ast.SelectorExpr:
+		if !isIdent(typed.X, m.importNames.gocheck) {
+			return ""
+		}
+		ident = typed.Sel
+	default:
+		return ""
+	}
+
+	if m.pkgInfo != nil {
+		obj := m.pkgInfo.Uses[ident]
+		if obj == nil || obj.Pkg() == nil || !isGocheckPkg(obj.Pkg().Path()) {
+			return ""
+		}
+		return obj.Name()
+	}
+	if _, ok := expr.(*ast.Ident); ok && m.importNames.gocheck != "." {
+		return ""
+	}
+	return ident.Name
+}
+
+// isGocheckC returns true if expr has the type *check.C.
+func (m gocheckMigration) isGocheckC(expr ast.Expr) bool {
+	if m.pkgInfo == nil {
+		return false
+	}
+	ptr, ok := m.pkgInfo.TypeOf(expr).(*types.Pointer)
+	if !ok {
+		return false
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return false
+	}
+	return named.Obj().Name() == "C" && isGocheckPkg(named.Obj().Pkg().Path())
+}
+
+func (m gocheckMigration) replace(cursor *astutil.Cursor) bool {
+	switch typed := cursor.Node().(type) {
+	case *ast.StarExpr:
+		if m.gocheckObj(typed.X) == "C" {
+			cursor.Replace(&ast.StarExpr{
+				Star: typed.Star,
+				X: &ast.SelectorExpr{
+					X:   &ast.Ident{Name: "testing"},
+					Sel: &ast.Ident{Name: "T"},
+				},
+			})
+		}
+	case *ast.CallExpr:
+		selector, ok := typed.Fun.(*ast.SelectorExpr)
+		if !ok || !m.isGocheckC(selector.X) {
+			return true
+		}
+		name := selector.Sel.Name
+		switch {
+		case name == "Assert" || name == "Check":
+			if newNode := m.convertChecker(typed, selector); newNode != nil {
+				cursor.Replace(newNode)
+			}
+		case gocheckRenamedMethods[name] != "":
+			selector.Sel = &ast.Ident{Name: gocheckRenamedMethods[name], NamePos: selector.Sel.NamePos}
+		case !gocheckSameMethods[name]:
+			m.skip(typed, "unsupported method %s", name)
+		}
+	}
+	return true
+}
+
+// convertChecker converts c.Assert(obtained, checker, args...) and
+// c.Check(obtained, checker, args...) to an assertion.
+func (m gocheckMigration) convertChecker(callExpr *ast.CallExpr, selector *ast.SelectorExpr) ast.Node {
+	if len(callExpr.Args) < 2 {
+		return nil
+	}
+	gc := gocheckCall{
+		assert:   selector.Sel.Name,
+		t:        selector.X,
+		pos:      selector.Pos(),
+		obtained: callExpr.Args[0],
+		args:     callExpr.Args[2:],
+		names:    m.importNames,
+	}
+	if n := len(gc.args); n > 0 {
+		if comment, ok := gc.args[n-1].(*ast.CallExpr); ok && m.gocheckObj(comment.Fun) == "Commentf" {
+			gc.args = gc.args[:n-1]
+			gc.msgAndArgs = comment.Args
+		}
+	}
+
+	checker := callExpr.Args[1]
+	negate := false
+	if not, ok := checker.(*ast.CallExpr); ok && m.gocheckObj(not.Fun) == "Not" && len(not.Args) == 1 {
+		checker = not.Args[0]
+		negate = true
+	}
+
+	name := m.gocheckObj(checker)
+	switch {
+	case name == "Equals" && negate && len(gc.args) == 1:
+		return gc.assertion(&ast.BinaryExpr{X: gc.obtained, Op: token.NEQ, Y: gc.args[0]})
+	case name == "IsNil" && negate, name == "NotNil" && !negate:
+		return gc.assertion(&ast.BinaryExpr{X: gc.obtained, Op: token.NEQ, Y: &ast.Ident{Name: "nil"}})
+	case negate:
+	case name == "Equals" && len(gc.args) == 1:
+		if gc.assert == funcNameAssert {
+			return gc.call("Equal", gc.obtained, gc.args[0])
+		}
+		return gc.comparison("Equal", gc.obtained, gc.args[0])
+	case name == "DeepEquals" && len(gc.args) == 1:
+		// assert.DeepEqual accepts gocmp.Option instead of msgAndArgs
+		if gc.assert == funcNameAssert && len(gc.msgAndArgs) == 0 {
+			return gc.call("DeepEqual", gc.obtained, gc.args[0])
+		}
+		return gc.comparison("DeepEqual", gc.obtained, gc.args[0])
+	case name == "IsNil":
+		if gotype := walkForType(m.pkgInfo, gc.obtained); gotype != nil && gotype.String() == "error" {
+			if gc.assert == funcNameAssert {
+				return gc.call("NilError", gc.obtained)
+			}
+			return gc.assertion(gc.obtained)
+		}
+		return gc.comparison("Nil", gc.obtained)
+	case name == "HasLen" && len(gc.args) == 1:
+		return gc.comparison("Len", gc.obtained, gc.args[0])
+	case name == "ErrorMatches" && len(gc.args) == 1:
+		if msg, ok := literalString(gc.args[0]); ok && regexp.QuoteMeta(msg) == msg {
+			if gc.assert == funcNameAssert {
+				return gc.call("Error", gc.obtained, gc.args[0])
+			}
+			return gc.comparison("Error", gc.obtained, gc.args[0])
+		}
+		// fmt.Sprint is used so that a nil error fails the assertion,
+		// instead of panicking.
+		errString := newCallExpr("fmt", "Sprint", []ast.Expr{gc.obtained})
+		return gc.comparison("Regexp", anchorPattern(gc.args[0]), errString)
+	case name == "Matches" && len(gc.args) == 1:
+		return gc.comparison("Regexp", anchorPattern(gc.args[0]), gc.obtained)
+	}
+
+	checkerName := name
+	if checkerName == "" {
+		checkerName = "custom checker"
+	}
+	if negate {
+		checkerName = "Not(" + checkerName + ")"
+	}
+	m.skip(callExpr, "unsupported checker %s", checkerName)
+	return nil
+}
+
+// gocheckCall is a call to c.Assert or c.Check.
+type gocheckCall struct {
+	// assert is Assert or Check
+	assert     string
+	t          ast.Expr
+	pos        token.Pos
+	obtained   ast.Expr
+	args       []ast.Expr
+	msgAndArgs []ast.Expr
+	names      importNames
+}
+
+// call returns a call to the assert function name, with the args and the
+// msgAndArgs of the gocheck call.
+func (c gocheckCall) call(name string, args ...ast.Expr) *ast.CallExpr {
+	args = append(append([]ast.Expr{c.t}, args...), c.msgAndArgs...)
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: c.names.assert, NamePos: c.pos},
+			Sel: &ast.Ident{Name: name},
+		},
+		Args: args,
+	}
+}
+
+// assertion returns a call to assert.Assert or assert.Check with the
+// comparison.
+func (c gocheckCall) assertion(comparison ast.Expr) *ast.CallExpr {
+	return c.call(c.assert, comparison)
+}
+
+// comparison returns a call to assert.Assert or assert.Check with the
+// cmp.<name> comparison.
+func (c gocheckCall) comparison(name string, args ...ast.Expr) *ast.CallExpr {
+	return c.assertion(newCallExpr(c.names.cmp, name, args))
+}
+
+func literalString(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// anchorPattern returns an expression for the regular expression pattern
+// which must match the whole string, like the gocheck Matches and
+// ErrorMatches checkers.
+func anchorPattern(pattern ast.Expr) ast.Expr {
+	const prefix, suffix = "^(?:", ")$"
+	if value, ok := literalString(pattern); ok {
+		anchored := prefix + value + suffix
+		if strings.HasPrefix(pattern.(*ast.BasicLit).Value, "`") && !strings.Contains(anchored, "`") {
+			return &ast.BasicLit{Kind: token.STRING, Value: "`" + anchored + "`"}
+		}
+		return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(anchored)}
+	}
+	return &ast.BinaryExpr{
+		X: &ast.BinaryExpr{
+			X:  &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(prefix)},
+			Op: token.ADD,
+			Y:  pattern,
+		},
+		Op: token.ADD,
+		Y:  &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(suffix)},
+	}
+}
+
+// migrateSuites replaces the registration of gocheck suites with a test
+// function which runs the suite with gotest.tools/v3/suite, and removes the
+// test function which runs gocheck. Returns true if any suites were found.
+func (m gocheckMigration) migrateSuites() bool {
+	suiteTypes := make(map[string]bool)
+	decls := m.file.Decls[:0]
+	for _, decl := range m.file.Decls {
+		switch typed := decl.(type) {
+		case *ast.GenDecl:
+			if suiteExpr := m.suiteRegistration(typed); suiteExpr != nil {
+				typeName := suiteTypeName(suiteExpr)
+				suiteTypes[typeName] = true
+				decls = append(decls, newSuiteTestFunc(typeName, suiteExpr, typed.Pos()))
+				continue
+			}
+		case *ast.FuncDecl:
+			if m.isGocheckBootstrap(typed) {
+				continue
+			}
+		}
+		decls = append(decls, decl)
+	}
+	m.file.Decls = decls
+
+	for _, decl := range m.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+			continue
+		}
+		if !suiteTypes[receiverTypeName(funcDecl.Recv.List[0].Type)] {
+			continue
+		}
+		name := funcDecl.Name.Name
+		switch {
+		case gocheckHooks[name] != "":
+			funcDecl.Name = &ast.Ident{Name: gocheckHooks[name], NamePos: funcDecl.Name.NamePos}
+		case strings.HasPrefix(name, "Benchmark"):
+			m.skip(funcDecl, "unsupported benchmark method %s", name)
+		}
+	}
+	return len(suiteTypes) > 0
+}
+
+// suiteRegistration returns the argument to check.Suite if decl is a
+// declaration like: var _ = check.Suite(&MySuite{})
+func (m gocheckMigration) suiteRegistration(decl *ast.GenDecl) ast.Expr {
+	if decl.Tok != token.VAR || len(decl.Specs) != 1 {
+		return nil
+	}
+	spec, ok := decl.Specs[0].(*ast.ValueSpec)
+	if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "_" {
+		return nil
+	}
+	callExpr := callExprFromValueSpec(spec)
+	if callExpr == nil || len(callExpr.Args) != 1 || m.gocheckObj(callExpr.Fun) != "Suite" {
+		return nil
+	}
+	return callExpr.Args[0]
+}
+
+// isGocheckBootstrap returns true if decl is a test function which only runs
+// the gocheck suites, like:
+//
+//	func Test(t *testing.T) { check.TestingT(t) }
+func (m gocheckMigration) isGocheckBootstrap(decl *ast.FuncDecl) bool {
+	if decl.Recv != nil || decl.Body == nil || len(decl.Body.List) != 1 {
+		return false
+	}
+	stmt, ok := decl.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	callExpr, ok := stmt.X.(*ast.CallExpr)
+	return ok && m.gocheckObj(callExpr.Fun) == "TestingT"
+}
+
+// suiteTypeName returns the name of the type of a suite value, like
+// &MySuite{}, new(MySuite), or MySuite{}.
+func suiteTypeName(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.UnaryExpr:
+		return suiteTypeName(typed.X)
+	case *ast.CompositeLit:
+		return receiverTypeName(typed.Type)
+	case *ast.CallExpr:
+		if isIdent(typed.Fun, "new") && len(typed.Args) == 1 {
+			return receiverTypeName(typed.Args[0])
+		}
+	case *ast.Ident:
+		return typed.Name
+	}
+	return "Suite"
+}
+
+func newSuiteTestFunc(typeName string, suiteExpr ast.Expr, pos token.Pos) *ast.FuncDecl {
+	name := "Test" + strings.TrimPrefix(typeName, "Test")
+	return &ast.FuncDecl{
+		Name: &ast.Ident{Name: name, NamePos: pos},
+		Type: &ast.FuncType{
+			Func:   pos,
+			Params: &ast.FieldList{List: []*ast.Field{newTestingTField()}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ExprStmt{X: newCallExpr(path.Base(pkgSuite), "Run",
+				[]ast.Expr{&ast.Ident{Name: "t"}, suiteExpr})},
+		}},
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestMigrateFileConvertsGocheck(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"errors"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type StoreSuite struct {
+	items []string
+}
+
+var _ = Suite(&StoreSuite{})
+
+func (s *StoreSuite) SetUpTest(c *C) {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TestItems(c *C) {
+	var err error
+	c.Assert(err, IsNil)
+	c.Check(len(s.items), Equals, 1, Commentf("items: %v", s.items))
+	c.Assert(s.items, DeepEquals, []string{"a"})
+	c.Check(s.items, HasLen, 1)
+	c.Assert(s.items[0], Not(Equals), "b")
+	c.Assert(s.items, NotNil)
+	c.Assert(s.items[0], Matches, "[a-z]")
+	dir := c.MkDir()
+	c.Log(dir)
+}
+
+func (s *StoreSuite) TestErrors(c *C) {
+	err := errors.New("item not found")
+	c.Assert(err, ErrorMatches, "item not found")
+	c.Check(err, ErrorMatches, "item .* found")
+	checkErr(c, err)
+}
+
+func checkErr(c *C, err error) {
+	c.Assert(err, Not(IsNil))
+}
+`
+	migration := newMigrationFromSourceWithOptions(t, source, options{from: []string{fromGocheck}})
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/suite"
+)
+
+type StoreSuite struct {
+	items []string
+}
+
+func TestStoreSuite(t *testing.T) { suite.Run(t, &StoreSuite{}) }
+
+func (s *StoreSuite) SetupTest(c *testing.T) {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TestItems(c *testing.T) {
+	var err error
+	assert.NilError(c, err)
+	assert.Check(c, cmp.Equal(len(s.items), 1), "items: %v", s.items)
+	assert.DeepEqual(c, s.items, []string{"a"})
+	assert.Check(c, cmp.Len(s.items, 1))
+	assert.Assert(c, s.items[0] != "b")
+	assert.Assert(c, s.items != nil)
+	assert.Assert(c, cmp.Regexp("^(?:[a-z])$", s.items[0]))
+	dir := c.TempDir()
+	c.Log(dir)
+}
+
+func (s *StoreSuite) TestErrors(c *testing.T) {
+	err := errors.New("item not found")
+	assert.Error(c, err, "item not found")
+	assert.Check(c, cmp.Regexp("^(?:item .* found)$", fmt.Sprint(err)))
+	checkErr(c, err)
+}
+
+func checkErr(c *testing.T, err error) {
+	assert.Assert(c, err != nil)
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
+
+func TestMigrateFileGocheckNotEnabled(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	check "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { check.TestingT(t) }
+`
+	migration := newMigrationFromSource(t, source)
+	assert.Assert(t, !migration.importNames.hasImportsToMigrate())
+}
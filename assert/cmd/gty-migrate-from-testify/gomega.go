@@ -0,0 +1,563 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	pkgGomega = "github.com/onsi/gomega"
+	pkgPoll   = "gotest.tools/v3/poll"
+)
+
+func isGomegaPkg(pkgPath string) bool {
+	return pkgPath == pkgGomega || strings.HasPrefix(pkgPath, pkgGomega+"/")
+}
+
+// migrateGomega converts Gomega expectations, like
+// g.Expect(x).To(Equal(y)), to assertions. The testing.T of an expectation is
+// found from the call to gomega.NewWithT(t) which created g, or from a call to
+// gomega.RegisterTestingT(t) in the same function for expectations which use
+// the package level Expect. Eventually is converted to poll.WaitOn.
+func migrateGomega(migration migration) {
+	if migration.importNames.gomega == "" {
+		return
+	}
+	m := &gomegaMigration{migration: migration}
+	for _, decl := range migration.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		var register ast.Stmt
+		register, m.registeredT = m.registerTestingT(funcDecl.Body)
+		unsupported := m.unsupportedCount
+		astutil.Apply(funcDecl.Body, nil, m.replace)
+		m.removeUnused(funcDecl.Body, register, m.unsupportedCount == unsupported)
+	}
+
+	if m.usesPoll {
+		astutil.AddImport(migration.fileset, migration.file, pkgPoll)
+	}
+	if m.usesTime {
+		astutil.AddImport(migration.fileset, migration.file, "time")
+	}
+	// keep the import for the expectations which must be migrated by hand
+	if m.unsupportedCount == 0 {
+		astutil.DeleteImport(migration.fileset, migration.file, pkgGomega)
+		astutil.DeleteNamedImport(migration.fileset, migration.file, migration.importNames.gomega, pkgGomega)
+	}
+}
+
+type gomegaMigration struct {
+	migration
+	// registeredT is the argument to RegisterTestingT in the function which
+	// is being migrated.
+	registeredT ast.Expr
+	usesPoll    bool
+	usesTime    bool
+	// unsupportedCount is the number of expectations which were not
+	// migrated.
+	unsupportedCount int
+}
+
+// gomegaObj returns the name of the function or method in the gomega
+// packages which is referenced by expr, or an empty string if expr is not a
+// reference to gomega.
+func (m *gomegaMigration) gomegaObj(expr ast.Expr) string {
+	var ident *ast.Ident
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		ident = typed
+	case *ast.SelectorExpr:
+		ident = typed.Sel
+	default:
+		return ""
+	}
+	if m.pkgInfo == nil {
+		return ""
+	}
+	obj := m.pkgInfo.Uses[ident]
+	if obj == nil || obj.Pkg() == nil || !isGomegaPkg(obj.Pkg().Path()) {
+		return ""
+	}
+	return obj.Name()
+}
+
+// registerTestingT returns the RegisterTestingT(t) statement from the top
+// level of body, and t.
+func (m *gomegaMigration) registerTestingT(body *ast.BlockStmt) (ast.Stmt, ast.Expr) {
+	for _, stmt := range body.List {
+		exprStmt, ok := stmt.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		callExpr, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok || len(callExpr.Args) != 1 || m.gomegaObj(callExpr.Fun) != "RegisterTestingT" {
+			continue
+		}
+		return stmt, removePos(callExpr.Args[0])
+	}
+	return nil, nil
+}
+
+// removeUnused removes the RegisterTestingT(t) statement from body if all the
+// expectations were migrated, and removes the g := NewWithT(t) statements
+// where g is no longer used.
+func (m *gomegaMigration) removeUnused(body *ast.BlockStmt, register ast.Stmt, allMigrated bool) {
+	astutil.Apply(body, nil, func(cursor *astutil.Cursor) bool {
+		switch typed := cursor.Node().(type) {
+		case *ast.ExprStmt:
+			if typed == register && allMigrated {
+				cursor.Delete()
+			}
+		case *ast.AssignStmt:
+			callExpr := callExprFromAssignment(typed)
+			if callExpr == nil || !m.isNewWithT(callExpr) || len(typed.Lhs) != 1 {
+				return true
+			}
+			if ident, ok := typed.Lhs[0].(*ast.Ident); ok && !isUsed(body, ident) {
+				cursor.Delete()
+			}
+		}
+		return true
+	})
+}
+
+// isUsed returns true if the variable declared by ident is referenced in body.
+func isUsed(body *ast.BlockStmt, ident *ast.Ident) bool {
+	if ident.Obj == nil {
+		return true
+	}
+	used := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if other, ok := node.(*ast.Ident); ok && other != ident && other.Obj == ident.Obj {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+// isNewWithT returns true if callExpr is a call to gomega.NewWithT(t).
+func (m *gomegaMigration) isNewWithT(callExpr *ast.CallExpr) bool {
+	switch m.gomegaObj(callExpr.Fun) {
+	case "NewWithT", "NewGomegaWithT":
+		return len(callExpr.Args) == 1
+	}
+	return false
+}
+
+func (m *gomegaMigration) replace(cursor *astutil.Cursor) bool {
+	if callExpr, ok := cursor.Node().(*ast.CallExpr); ok {
+		if newNode := m.convertAssertion(callExpr); newNode != nil {
+			cursor.Replace(newNode)
+		}
+	}
+	return true
+}
+
+// gomegaAssertion is an expectation like Expect(actual).To(matcher), or
+// Eventually(actual, args...).Should(matcher).
+type gomegaAssertion struct {
+	callExpr *ast.CallExpr
+	// method is the name of the function which created the assertion, like
+	// Expect or Eventually.
+	method  string
+	t       ast.Expr
+	pos     token.Pos
+	actual  ast.Expr
+	args    []ast.Expr
+	matcher ast.Expr
+	negate  bool
+	msgArgs []ast.Expr
+}
+
+func (m *gomegaMigration) convertAssertion(callExpr *ast.CallExpr) ast.Node {
+	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || len(callExpr.Args) == 0 {
+		return nil
+	}
+	var negate bool
+	switch m.gomegaObj(selector) {
+	case "To", "Should":
+	case "ToNot", "NotTo", "ShouldNot":
+		negate = true
+	default:
+		return nil
+	}
+	inner, ok := selector.X.(*ast.CallExpr)
+	if !ok || len(inner.Args) == 0 {
+		return nil
+	}
+
+	a := gomegaAssertion{
+		callExpr: callExpr,
+		method:   m.gomegaObj(inner.Fun),
+		pos:      inner.Pos(),
+		actual:   inner.Args[0],
+		args:     inner.Args[1:],
+		matcher:  callExpr.Args[0],
+		negate:   negate,
+		msgArgs:  callExpr.Args[1:],
+	}
+	a.t = m.testingT(inner)
+	if a.t == nil {
+		m.unsupported(callExpr, "expectation without a testing.T, use gomega.NewWithT(t)")
+		return nil
+	}
+
+	switch a.method {
+	case "Expect", "Ω":
+		if len(a.args) > 0 || isTuple(m.pkgInfo, a.actual) {
+			m.unsupported(callExpr, "expectation with extra values")
+			return nil
+		}
+		return m.convertExpect(a)
+	case "Eventually":
+		return m.convertEventually(a)
+	}
+	m.unsupported(callExpr, a.method)
+	return nil
+}
+
+// testingT returns the testing.T used by the expectation created by
+// callExpr.
+func (m *gomegaMigration) testingT(callExpr *ast.CallExpr) ast.Expr {
+	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || isIdent(selector.X, m.importNames.gomega) {
+		return m.registeredT
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	if !ok || ident.Obj == nil {
+		return nil
+	}
+	var newCall *ast.CallExpr
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		newCall = callExprFromAssignment(decl)
+	case *ast.ValueSpec:
+		newCall = callExprFromValueSpec(decl)
+	}
+	if newCall == nil || !m.isNewWithT(newCall) {
+		return nil
+	}
+	return removePos(newCall.Args[0])
+}
+
+func isTuple(pkgInfo *types.Info, expr ast.Expr) bool {
+	if pkgInfo == nil {
+		return false
+	}
+	_, ok := pkgInfo.TypeOf(expr).(*types.Tuple)
+	return ok
+}
+
+func (m *gomegaMigration) unsupported(node ast.Node, what string) {
+	m.unsupportedCount++
+	m.skip(node, "unsupported gomega %s", what)
+}
+
+// gomegaMatch is the translation of a gomega matcher applied to a value.
+type gomegaMatch struct {
+	// assert is the name of the assert function, and args are the arguments
+	// to the function after t.
+	assert string
+	args   []ast.Expr
+	// comparison is an equivalent cmp.Comparison, used by Eventually. It is
+	// nil if there is no equivalent comparison.
+	comparison ast.Expr
+}
+
+func (m *gomegaMigration) convertExpect(a gomegaAssertion) ast.Node {
+	match, ok := m.translateMatcher(a, a.actual)
+	if !ok {
+		return nil
+	}
+	// assert.DeepEqual accepts gocmp.Option instead of msgAndArgs
+	if match.assert == "DeepEqual" && len(a.msgArgs) > 0 {
+		match = gomegaMatch{assert: funcNameAssert, args: []ast.Expr{match.comparison}}
+	}
+	args := append(append([]ast.Expr{a.t}, match.args...), a.msgArgs...)
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: m.importNames.assert, NamePos: a.pos},
+			Sel: &ast.Ident{Name: match.assert},
+		},
+		Args: args,
+	}
+}
+
+// convertEventually converts Eventually(f, timeout, interval).Should(matcher)
+// to a poll.WaitOn which compares the value returned by f on every attempt.
+func (m *gomegaMigration) convertEventually(a gomegaAssertion) ast.Node {
+	sig, ok := typeOf(m.pkgInfo, a.actual).(*types.Signature)
+	if !ok || sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		m.unsupported(a.callExpr, "Eventually with a value which is not a func() T")
+		return nil
+	}
+	if len(a.msgArgs) > 0 {
+		m.unsupported(a.callExpr, "Eventually with a description")
+		return nil
+	}
+	var pollOps []ast.Expr
+	if len(a.args) > 2 {
+		m.unsupported(a.callExpr, "Eventually with more than two arguments")
+		return nil
+	}
+	for i, arg := range a.args {
+		duration, ok := m.durationExpr(arg)
+		if !ok {
+			m.unsupported(a.callExpr, "Eventually argument")
+			return nil
+		}
+		name := "WithTimeout"
+		if i == 1 {
+			name = "WithDelay"
+		}
+		pollOps = append(pollOps, newCallExpr("poll", name, []ast.Expr{duration}))
+	}
+
+	actual := &ast.CallExpr{Fun: a.actual}
+	if _, isFuncLit := a.actual.(*ast.FuncLit); isFuncLit {
+		actual = &ast.CallExpr{Fun: &ast.ParenExpr{X: a.actual}}
+	}
+	match, ok := m.translateMatcher(a, actual)
+	if !ok {
+		return nil
+	}
+	if match.comparison == nil {
+		m.unsupported(a.callExpr, "matcher in Eventually")
+		return nil
+	}
+	m.usesPoll = true
+
+	check := newCallExpr("poll", "CompareCheck", []ast.Expr{&ast.FuncLit{
+		Type: &ast.FuncType{
+			Params: &ast.FieldList{},
+			Results: &ast.FieldList{List: []*ast.Field{{
+				Type: &ast.SelectorExpr{X: &ast.Ident{Name: m.importNames.cmp}, Sel: &ast.Ident{Name: "Comparison"}},
+			}}},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{match.comparison}},
+		}},
+	}})
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: "poll", NamePos: a.pos},
+			Sel: &ast.Ident{Name: "WaitOn"},
+		},
+		Args: append([]ast.Expr{a.t, check}, pollOps...),
+	}
+}
+
+func typeOf(pkgInfo *types.Info, expr ast.Expr) types.Type {
+	if pkgInfo == nil {
+		return nil
+	}
+	typ := pkgInfo.TypeOf(expr)
+	if typ == nil {
+		return nil
+	}
+	return typ.Underlying()
+}
+
+// durationExpr returns an expression of type time.Duration for an Eventually
+// timeout or polling interval, which may be a time.Duration or a string
+// literal like "2s".
+func (m *gomegaMigration) durationExpr(expr ast.Expr) (ast.Expr, bool) {
+	if value, ok := literalString(expr); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, false
+		}
+		m.usesTime = true
+		return newDurationExpr(d), true
+	}
+	if m.pkgInfo == nil {
+		return nil, false
+	}
+	if named, ok := m.pkgInfo.TypeOf(expr).(*types.Named); ok && named.String() == "time.Duration" {
+		return expr, true
+	}
+	return nil, false
+}
+
+func newDurationExpr(d time.Duration) ast.Expr {
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "Hour"},
+		{time.Minute, "Minute"},
+		{time.Second, "Second"},
+		{time.Millisecond, "Millisecond"},
+		{time.Microsecond, "Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit != 0 {
+			continue
+		}
+		unit := &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: u.name}}
+		if d == u.unit {
+			return unit
+		}
+		count := &ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(int64(d/u.unit), 10)}
+		return &ast.BinaryExpr{X: count, Op: token.MUL, Y: unit}
+	}
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: "Duration"}},
+		Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: strconv.FormatInt(int64(d), 10)}},
+	}
+}
+
+// translateMatcher returns the assertion which is equivalent to applying the
+// matcher of the gomega assertion to actual.
+func (m *gomegaMigration) translateMatcher(a gomegaAssertion, actual ast.Expr) (gomegaMatch, bool) {
+	matcherCall, ok := a.matcher.(*ast.CallExpr)
+	if !ok {
+		m.unsupported(a.callExpr, "matcher")
+		return gomegaMatch{}, false
+	}
+	name := m.gomegaObj(matcherCall.Fun)
+	args := matcherCall.Args
+	cmpCall := func(name string, args ...ast.Expr) ast.Expr {
+		return newCallExpr(m.importNames.cmp, name, args)
+	}
+	assertCmp := func(comparison ast.Expr) gomegaMatch {
+		return gomegaMatch{assert: funcNameAssert, args: []ast.Expr{comparison}, comparison: comparison}
+	}
+	assertExpr := func(expr ast.Expr) gomegaMatch {
+		return gomegaMatch{assert: funcNameAssert, args: []ast.Expr{expr}}
+	}
+	nilExpr := &ast.Ident{Name: "nil"}
+	isError := isErrorType(m.pkgInfo, a.actual, a.method == "Eventually")
+
+	switch {
+	case name == "Equal" && len(args) == 1:
+		basic := isBasicType(m.pkgInfo, args[0]) || isBasicType(m.pkgInfo, a.actual)
+		switch {
+		case a.negate && basic:
+			return assertExpr(&ast.BinaryExpr{X: actual, Op: token.NEQ, Y: args[0]}), true
+		case a.negate:
+		case basic:
+			return gomegaMatch{assert: "Equal", args: []ast.Expr{actual, args[0]},
+				comparison: cmpCall("Equal", actual, args[0])}, true
+		default:
+			return gomegaMatch{assert: "DeepEqual", args: []ast.Expr{actual, args[0]},
+				comparison: cmpCall("DeepEqual", actual, args[0])}, true
+		}
+	case name == "BeNil" && len(args) == 0:
+		switch {
+		case a.negate:
+			return assertExpr(&ast.BinaryExpr{X: actual, Op: token.NEQ, Y: nilExpr}), true
+		case isError:
+			return gomegaMatch{assert: "NilError", args: []ast.Expr{actual},
+				comparison: cmpCall("Nil", actual)}, true
+		default:
+			return assertCmp(cmpCall("Nil", actual)), true
+		}
+	case (name == "HaveOccurred" && !a.negate) || (name == "Succeed" && a.negate):
+		if len(args) == 0 {
+			return gomegaMatch{assert: "ErrorContains",
+				args:       []ast.Expr{actual, &ast.BasicLit{Kind: token.STRING, Value: `""`}},
+				comparison: cmpCall("ErrorContains", actual, &ast.BasicLit{Kind: token.STRING, Value: `""`})}, true
+		}
+	case name == "HaveOccurred" || name == "Succeed":
+		if len(args) == 0 {
+			return gomegaMatch{assert: "NilError", args: []ast.Expr{actual},
+				comparison: cmpCall("Nil", actual)}, true
+		}
+	case name == "BeTrue" || name == "BeFalse":
+		if len(args) != 0 {
+			break
+		}
+		want := (name == "BeTrue") != a.negate
+		expr := actual
+		if !want {
+			expr = &ast.UnaryExpr{Op: token.NOT, X: actual}
+		}
+		match := assertExpr(expr)
+		match.comparison = cmpCall("Equal", actual, &ast.Ident{Name: strconv.FormatBool(want)})
+		return match, true
+	case name == "HaveLen" && len(args) == 1:
+		if a.negate {
+			lenExpr := &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{actual}}
+			return assertExpr(&ast.BinaryExpr{X: lenExpr, Op: token.NEQ, Y: args[0]}), true
+		}
+		return assertCmp(cmpCall("Len", actual, args[0])), true
+	case name == "BeEmpty" && len(args) == 0:
+		zero := &ast.BasicLit{Kind: token.INT, Value: "0"}
+		if a.negate {
+			lenExpr := &ast.CallExpr{Fun: &ast.Ident{Name: "len"}, Args: []ast.Expr{actual}}
+			return assertExpr(&ast.BinaryExpr{X: lenExpr, Op: token.NEQ, Y: zero}), true
+		}
+		return assertCmp(cmpCall("Len", actual, zero)), true
+	case a.negate:
+	case (name == "ContainSubstring" || name == "ContainElement" || name == "HaveKey") && len(args) == 1:
+		return assertCmp(cmpCall("Contains", actual, args[0])), true
+	case name == "MatchRegexp" && len(args) == 1:
+		return assertCmp(cmpCall("Regexp", args[0], actual)), true
+	case name == "MatchError" && len(args) == 1:
+		if _, ok := literalString(args[0]); ok {
+			return gomegaMatch{assert: "Error", args: []ast.Expr{actual, args[0]},
+				comparison: cmpCall("Error", actual, args[0])}, true
+		}
+		if isErrorType(m.pkgInfo, args[0], false) {
+			return gomegaMatch{assert: "ErrorIs", args: []ast.Expr{actual, args[0]},
+				comparison: cmpCall("ErrorIs", actual, args[0])}, true
+		}
+	case name == "BeNumerically" && len(args) == 2:
+		op, ok := literalString(args[0])
+		if !ok {
+			break
+		}
+		tokens := map[string]token.Token{
+			"==": token.EQL, ">": token.GTR, ">=": token.GEQ, "<": token.LSS, "<=": token.LEQ,
+		}
+		if tok, ok := tokens[op]; ok {
+			return assertExpr(&ast.BinaryExpr{X: actual, Op: tok, Y: args[1]}), true
+		}
+	}
+
+	if name == "" {
+		name = "custom matcher"
+	}
+	if a.negate {
+		name = "negated " + name
+	}
+	m.unsupported(a.callExpr, "matcher "+name)
+	return gomegaMatch{}, false
+}
+
+// isErrorType returns true if expr has the type error. If result is true
+// expr is a function, and the type of its result is checked.
+func isErrorType(pkgInfo *types.Info, expr ast.Expr, result bool) bool {
+	if pkgInfo == nil {
+		return false
+	}
+	typ := pkgInfo.TypeOf(expr)
+	if sig, ok := typ.(*types.Signature); ok && result && sig.Results().Len() == 1 {
+		typ = sig.Results().At(0).Type()
+	}
+	return typ != nil && typ.String() == "error"
+}
+
+func isBasicType(pkgInfo *types.Info, expr ast.Expr) bool {
+	if pkgInfo == nil {
+		return false
+	}
+	typ := pkgInfo.TypeOf(expr)
+	if typ == nil {
+		return false
+	}
+	_, ok := typ.Underlying().(*types.Basic)
+	return ok && !isUnknownType(typ)
+}
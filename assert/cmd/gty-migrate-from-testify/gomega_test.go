@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMigrateFileConvertsGomega(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestWithT(t *testing.T) {
+	g := NewWithT(t)
+	items := []string{"a"}
+	var err error
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(len(items)).To(Equal(1))
+	g.Expect(items).To(Equal([]string{"a"}))
+	g.Expect(items).To(HaveLen(1), "items: %v", items)
+	g.Expect(items).NotTo(BeEmpty())
+	g.Expect(items[0]).To(ContainSubstring("a"))
+	g.Expect(items[0]).To(MatchRegexp("^[a-z]$"))
+	g.Expect(len(items) > 0).To(BeTrue())
+	g.Expect(len(items)).To(BeNumerically(">=", 1))
+	g.Expect(items).ToNot(BeNil())
+	g.Expect(errNotFound).To(MatchError("not found"))
+	g.Expect(errNotFound).To(MatchError(errNotFound))
+	g.Expect(items).To(ConsistOf("a"))
+}
+
+func TestAllMigrated(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(1).To(Equal(1))
+}
+
+func TestRegistered(t *testing.T) {
+	RegisterTestingT(t)
+	ready := false
+	Expect(ready).To(BeFalse())
+	Eventually(func() bool { return ready }, "2s", 10*time.Millisecond).Should(BeTrue())
+}
+
+func TestNotRegistered(t *testing.T) {
+	Expect(true).To(BeTrue())
+}
+`
+	migration := newMigrationFromSourceWithOptions(t, source, options{from: []string{fromGomega}})
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestWithT(t *testing.T) {
+	g := NewWithT(t)
+	items := []string{"a"}
+	var err error
+	assert.NilError(t, err)
+	assert.Equal(t, len(items), 1)
+	assert.DeepEqual(t, items, []string{"a"})
+	assert.Assert(t, cmp.Len(items, 1), "items: %v", items)
+	assert.Assert(t, len(items) != 0)
+	assert.Assert(t, cmp.Contains(items[0], "a"))
+	assert.Assert(t, cmp.Regexp("^[a-z]$", items[0]))
+	assert.Assert(t, len(items) > 0)
+	assert.Assert(t, len(items) >= 1)
+	assert.Assert(t, items != nil)
+	assert.Error(t, errNotFound, "not found")
+	assert.ErrorIs(t, errNotFound, errNotFound)
+	g.Expect(items).To(ConsistOf("a"))
+}
+
+func TestAllMigrated(t *testing.T) {
+
+	assert.Equal(t, 1, 1)
+}
+
+func TestRegistered(t *testing.T) {
+
+	ready := false
+	assert.Assert(t, !ready)
+	poll.WaitOn(t, poll.CompareCheck(func() cmp.Comparison {
+		return cmp.Equal((func() bool { return ready })(), true)
+	}), poll.WithTimeout(2*time.Second), poll.WithDelay(10*time.Millisecond))
+}
+
+func TestNotRegistered(t *testing.T) {
+	Expect(true).To(BeTrue())
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Equal(t, string(actual), expected)
+}
+
+func TestMigrateFileGomegaNotEnabled(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestWithT(t *testing.T) {
+	g := gomega.NewWithT(t)
+	g.Expect(1).To(gomega.Equal(1))
+}
+`
+	migration := newMigrationFromSourceWithOptions(t, source, options{})
+	assert.Assert(t, !migration.importNames.hasImportsToMigrate())
+}
@@ -2,73 +2,73 @@ package main
 
 import (
 	"bytes"
-	"errors"
 	"flag"
 	"fmt"
 	"go/ast"
 	"go/format"
 	"go/token"
-	"io/ioutil"
-	"log"
+	"io"
 	"os"
 	"path"
-	"path/filepath"
 	"strings"
 
-	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/imports"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
 )
 
 type options struct {
-	pkgs             []string
-	dryRun           bool
-	debug            bool
-	cmpImportName    string
-	showLoaderErrors bool
-	buildFlags       []string
-	localImportPath  string
+	migrate.Options
+	cmpImportName string
+	from          []string
+	suites        string
+}
+
+// Values for the --from flag, which select the assertion libraries to migrate
+// from.
+const (
+	fromTestify = "testify"
+	fromGocheck = "gocheck"
+	fromGomega  = "gomega"
+)
+
+// migrates returns true if the assertions of the library should be migrated.
+// testify is migrated when --from is not set.
+func (o options) migrates(library string) bool {
+	if len(o.from) == 0 {
+		return library == fromTestify
+	}
+	for _, from := range o.from {
+		if from == library {
+			return true
+		}
+	}
+	return false
 }
 
 func main() {
 	name := os.Args[0]
 	flags, opts := setupFlags(name)
-	handleExitError(name, flags.Parse(os.Args[1:]))
-	setupLogging(opts)
-	opts.pkgs = flags.Args()
-	handleExitError(name, run(*opts))
-}
-
-func setupLogging(opts *options) {
-	log.SetFlags(0)
-	enableDebug = opts.debug
-}
-
-var enableDebug = false
-
-func debugf(msg string, args ...interface{}) {
-	if enableDebug {
-		log.Printf("DEBUG: "+msg, args...)
-	}
+	migrate.HandleExitError(name, flags.Parse(os.Args[1:]))
+	migrate.SetupLogging(opts.Options)
+	opts.Pkgs = flags.Args()
+	migrate.HandleExitError(name, run(*opts, os.Stdout))
 }
 
 func setupFlags(name string) (*flag.FlagSet, *options) {
 	opts := options{}
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
-	flags.BoolVar(&opts.dryRun, "dry-run", false,
-		"don't write changes to file")
-	flags.BoolVar(&opts.debug, "debug", false, "enable debug logging")
+	opts.AddFlags(flags)
 	flags.StringVar(&opts.cmpImportName, "cmp-pkg-import-alias", "is",
 		"import alias to use for the assert/cmp package")
-	flags.BoolVar(&opts.showLoaderErrors, "print-loader-errors", false,
-		"print errors from loading source")
-	flags.Var((*stringSliceValue)(&opts.buildFlags), "build-flags",
-		"build flags to pass to Go when loading source files")
-	flags.StringVar(&opts.localImportPath, "local-import-path", "",
-		"value to pass to 'goimports -local' flag for sorting local imports")
+	flags.Var((*migrate.StringSliceValue)(&opts.from), "from",
+		"comma separated list of libraries to migrate from: testify, gocheck, gomega (default testify)")
+	flags.StringVar(&opts.suites, "suites", suitesPackage,
+		"how testify suites are migrated: suite (run with gotest.tools/v3/suite), or subtests (call each test method with t.Run)")
 	flags.Usage = func() {
 		fmt.Fprintf(os.Stderr, `Usage: %s [OPTIONS] PACKAGE [PACKAGE...]
 
-Migrate calls from testify/{assert|require} to gotest.tools/v3/assert.
+Migrate calls from testify/{assert|require} to gotest.tools/v3/assert, and
+suites from testify/suite to gotest.tools/v3/suite.
 
 `, name)
 		flags.PrintDefaults()
@@ -76,58 +76,44 @@ Migrate calls from testify/{assert|require} to gotest.tools/v3/assert.
 	return flags, &opts
 }
 
-func handleExitError(name string, err error) {
-	switch {
-	case err == nil:
-		return
-	case errors.Is(err, flag.ErrHelp):
-		os.Exit(0)
+func run(opts options, out io.Writer) error {
+	imports.LocalPrefix = opts.LocalImportPath
+	switch opts.suites {
+	case "", suitesPackage, suitesSubtests:
 	default:
-		log.Println(name + ": Error: " + err.Error())
-		os.Exit(3)
+		return fmt.Errorf("invalid value for --suites: %q, expected %s or %s",
+			opts.suites, suitesPackage, suitesSubtests)
 	}
-}
-
-func run(opts options) error {
-	imports.LocalPrefix = opts.localImportPath
 
 	fset := token.NewFileSet()
-	pkgs, err := loadPackages(opts, fset)
+	pkgs, err := migrate.LoadPackages(opts.Options, fset)
 	if err != nil {
 		return fmt.Errorf("failed to load program: %w", err)
 	}
 
-	debugf("package count: %d", len(pkgs))
+	migrate.Debugf("package count: %d", len(pkgs))
 	for _, pkg := range pkgs {
-		debugf("file count for package %v: %d", pkg.PkgPath, len(pkg.Syntax))
+		migrate.Debugf("file count for package %v: %d", pkg.PkgPath, len(pkg.Syntax))
 		for _, astFile := range pkg.Syntax {
 			absFilename := fset.File(astFile.Pos()).Name()
-			filename := relativePath(absFilename)
+			filename := migrate.RelativePath(absFilename)
 			importNames := newImportNames(astFile.Imports, opts)
-			if !importNames.hasTestifyImports() {
-				debugf("skipping file %s, no imports", filename)
+			if !importNames.hasImportsToMigrate() {
+				migrate.Debugf("skipping file %s, no imports", filename)
 				continue
 			}
 
-			debugf("migrating %s with imports: %#v", filename, importNames)
+			migrate.Debugf("migrating %s with imports: %#v", filename, importNames)
 			m := migration{
-				file:        astFile,
-				fileset:     fset,
-				importNames: importNames,
-				pkgInfo:     pkg.TypesInfo,
-			}
-			migrateFile(m)
-			if opts.dryRun {
-				continue
-			}
-
-			raw, err := formatFile(m)
-			if err != nil {
-				return fmt.Errorf("failed to format %s: %w", filename, err)
+				file:          astFile,
+				fileset:       fset,
+				importNames:   importNames,
+				pkgInfo:       pkg.TypesInfo,
+				report:        &migrate.Report{},
+				subtestSuites: opts.suites == suitesSubtests,
 			}
-
-			if err := ioutil.WriteFile(absFilename, raw, 0); err != nil {
-				return fmt.Errorf("failed to write file %s: %w", filename, err)
+			if err := migrateAndWrite(m, absFilename, filename, opts, out); err != nil {
+				return err
 			}
 		}
 	}
@@ -135,56 +121,34 @@ func run(opts options) error {
 	return nil
 }
 
-var loadMode = packages.NeedName |
-	packages.NeedFiles |
-	packages.NeedCompiledGoFiles |
-	packages.NeedDeps |
-	packages.NeedImports |
-	packages.NeedTypes |
-	packages.NeedTypesInfo |
-	packages.NeedTypesSizes |
-	packages.NeedSyntax
-
-func loadPackages(opts options, fset *token.FileSet) ([]*packages.Package, error) {
-	conf := &packages.Config{
-		Mode:       loadMode,
-		Fset:       fset,
-		Tests:      true,
-		Logf:       debugf,
-		BuildFlags: opts.buildFlags,
-	}
-
-	pkgs, err := packages.Load(conf, opts.pkgs...)
-	if err != nil {
-		return nil, err
-	}
-	if opts.showLoaderErrors {
-		packages.PrintErrors(pkgs)
-	}
-	return pkgs, nil
-}
-
-func relativePath(p string) string {
-	cwd, err := os.Getwd()
-	if err != nil {
-		return p
-	}
-	rel, err := filepath.Rel(cwd, p)
+// migrateAndWrite migrates the file, and writes the result to the file, or
+// when opts.DryRun is set, writes a diff of the changes to out.
+func migrateAndWrite(m migration, absFilename, filename string, opts options, out io.Writer) error {
+	migrateFile(m)
+	raw, err := formatFile(m)
 	if err != nil {
-		return p
+		return fmt.Errorf("failed to format %s: %w", filename, err)
 	}
-	return rel
+	return migrate.WriteFile(opts.Options, m.report, absFilename, filename, raw, out)
 }
 
 type importNames struct {
 	testifyAssert  string
 	testifyRequire string
-	assert         string
-	cmp            string
+	testifySuite   string
+	// gocheck is the name of the gocheck package, which is "." for a dot
+	// import.
+	gocheck string
+	// gomega is the name of the gomega package, which is "." for a dot
+	// import.
+	gomega string
+	assert string
+	cmp    string
 }
 
-func (p importNames) hasTestifyImports() bool {
-	return p.testifyAssert != "" || p.testifyRequire != ""
+func (p importNames) hasImportsToMigrate() bool {
+	return p.testifyAssert != "" || p.testifyRequire != "" || p.testifySuite != "" ||
+		p.gocheck != "" || p.gomega != ""
 }
 
 func (p importNames) matchesTestify(ident *ast.Ident) bool {
@@ -209,14 +173,24 @@ func newImportNames(imports []*ast.ImportSpec, opt options) importNames {
 		cmp:    path.Base(pkgCmp),
 	}
 	for _, spec := range imports {
-		switch strings.Trim(spec.Path.Value, `"`) {
-		case pkgTestifyAssert, pkgGopkgTestifyAssert:
+		switch pkgPath := strings.Trim(spec.Path.Value, `"`); {
+		case !opt.migrates(fromTestify) && isTestifyPkg(pkgPath):
+			continue
+		case pkgPath == pkgTestifyAssert, pkgPath == pkgGopkgTestifyAssert:
 			importNames.testifyAssert = identOrDefault(spec.Name, "assert")
-		case pkgTestifyRequire, pkgGopkgTestifyRequire:
+		case pkgPath == pkgTestifyRequire, pkgPath == pkgGopkgTestifyRequire:
 			importNames.testifyRequire = identOrDefault(spec.Name, "require")
+		case pkgPath == pkgTestifySuite, pkgPath == pkgGopkgTestifySuite:
+			importNames.testifySuite = identOrDefault(spec.Name, "suite")
+		case isGocheckPkg(pkgPath):
+			if opt.migrates(fromGocheck) {
+				importNames.gocheck = identOrDefault(spec.Name, "check")
+			}
+		case pkgPath == pkgGomega:
+			if opt.migrates(fromGomega) {
+				importNames.gomega = identOrDefault(spec.Name, "gomega")
+			}
 		default:
-			pkgPath := strings.Trim(spec.Path.Value, `"`)
-
 			switch {
 			// v3/assert is already imported and has an alias
 			case pkgPath == pkgAssert:
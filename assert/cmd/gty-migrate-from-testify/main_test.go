@@ -1,28 +1,32 @@
 package main
 
 import (
+	"bytes"
 	"io/ioutil"
+	"log"
+	"os"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
 	"gotest.tools/v3/env"
 	"gotest.tools/v3/fs"
 	"gotest.tools/v3/golden"
 )
 
 func TestRun(t *testing.T) {
-	setupLogging(&options{})
+	migrate.SetupLogging(migrate.Options{})
 	dir := fs.NewDir(t, "test-run",
 		fs.WithDir("src/example.com/example", fs.FromDir("testdata/full")))
 	defer dir.Remove()
 
 	defer env.Patch(t, "GO111MODULE", "off")()
 	defer env.Patch(t, "GOPATH", dir.Path())()
-	err := run(options{
-		pkgs:             []string{"example.com/example"},
-		showLoaderErrors: true,
-	})
+	err := run(options{Options: migrate.Options{
+		Pkgs:             []string{"example.com/example"},
+		ShowLoaderErrors: true,
+	}}, ioutil.Discard)
 	assert.NilError(t, err)
 
 	raw, err := ioutil.ReadFile(dir.Join("src/example.com/example/some_test.go"))
@@ -30,6 +34,44 @@ func TestRun(t *testing.T) {
 	golden.Assert(t, string(raw), "full-expected/some_test.go")
 }
 
+func TestRunDryRun(t *testing.T) {
+	migrate.SetupLogging(migrate.Options{})
+	dir := fs.NewDir(t, "test-run",
+		fs.WithDir("src/example.com/example", fs.FromDir("testdata/full")))
+	defer dir.Remove()
+
+	defer env.Patch(t, "GO111MODULE", "off")()
+	defer env.Patch(t, "GOPATH", dir.Path())()
+	restoreWorkingDir := env.ChangeWorkingDir(t, dir.Join("src/example.com/example"))
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+
+	out := new(bytes.Buffer)
+	err := run(options{Options: migrate.Options{
+		Pkgs:             []string{"."},
+		ShowLoaderErrors: true,
+		DryRun:           true,
+	}}, out)
+	restoreWorkingDir()
+	assert.NilError(t, err)
+	golden.Assert(t, out.String(), "full-expected/dry-run.diff")
+	assert.Equal(t, logs.String(), `some_test.go: 1 call sites could not be migrated:
+    some_test.go:56: skipping unsupported assertion assert.NotContains(t, []bool{}, true)
+`)
+
+	raw, err := ioutil.ReadFile(dir.Join("src/example.com/example/some_test.go"))
+	assert.NilError(t, err)
+	original, err := ioutil.ReadFile("testdata/full/some_test.go")
+	assert.NilError(t, err)
+	assert.Equal(t, string(raw), string(original), "file should not be changed")
+}
+
+func TestRunInvalidSuites(t *testing.T) {
+	err := run(options{suites: "classes"}, ioutil.Discard)
+	assert.Error(t, err, `invalid value for --suites: "classes", expected suite or subtests`)
+}
+
 func TestSetupFlags(t *testing.T) {
 	flags, opts := setupFlags("testing")
 	assert.Assert(t, flags.Usage != nil)
@@ -39,13 +81,19 @@ func TestSetupFlags(t *testing.T) {
 		"--debug",
 		"--cmp-pkg-import-alias=foo",
 		"--print-loader-errors",
+		"--from=testify,gocheck",
+		"--suites=subtests",
 	})
 	assert.NilError(t, err)
 	expected := &options{
-		dryRun:           true,
-		debug:            true,
-		cmpImportName:    "foo",
-		showLoaderErrors: true,
+		Options: migrate.Options{
+			DryRun:           true,
+			Debug:            true,
+			ShowLoaderErrors: true,
+		},
+		cmpImportName: "foo",
+		from:          []string{"testify", "gocheck"},
+		suites:        "subtests",
 	}
 	assert.DeepEqual(t, opts, expected, cmpOptions)
 }
@@ -4,10 +4,10 @@ import (
 	"go/ast"
 	"go/token"
 	"go/types"
-	"log"
 	"path"
 
 	"golang.org/x/tools/go/ast/astutil"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
 )
 
 const (
@@ -31,22 +31,116 @@ var allTestifyPks = []string{
 	pkgGopkgTestifyRequire,
 }
 
+func isTestifyPkg(pkgPath string) bool {
+	for _, pkg := range allTestifyPks {
+		if pkgPath == pkg {
+			return true
+		}
+	}
+	return pkgPath == pkgTestifySuite || pkgPath == pkgGopkgTestifySuite
+}
+
 type migration struct {
 	file        *ast.File
 	fileset     *token.FileSet
 	importNames importNames
 	pkgInfo     *types.Info
+	// report records the call sites which could not be migrated. If it is
+	// nil they are logged.
+	report *migrate.Report
+	// testifyIdents are the package names of the calls to testify functions
+	// created by migrateSuites, mapped to the import path of the package.
+	testifyIdents map[*ast.Ident]string
+	// subtestSuites converts the suite.Run calls of testify suites to
+	// subtests, instead of running the suites with gotest.tools/v3/suite.
+	subtestSuites bool
 }
 
 func migrateFile(migration migration) {
+	migration = migrateSuites(migration)
+	migrateGocheck(migration)
+	migrateGomega(migration)
 	astutil.Apply(migration.file, nil, replaceCalls(migration))
-	updateImports(migration)
+	updateImports(migration, keepTestifyReferences(migration))
 }
 
-func updateImports(migration migration) {
+// keepTestifyReferences finds the references to the testify assert and
+// require packages which were not migrated, like assert.AnError passed to a
+// mock, or an unsupported assertion. It returns the import paths of the
+// packages which are still used, mapped to the name used for each package.
+// References which would conflict with the name of the gotest.tools/v3/assert
+// or cmp packages are renamed.
+func keepTestifyReferences(migration migration) map[string]string {
+	kept := make(map[string]string)
+	ast.Inspect(migration.file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		pkgPath := testifyPkgOfIdent(ident, migration)
+		if pkgPath == "" {
+			return true
+		}
+		name, ok := kept[pkgPath]
+		if !ok {
+			name = ident.Name
+			if name == migration.importNames.assert || name == migration.importNames.cmp {
+				name = syntheticTestifyPkgName(pkgPath)
+			}
+			kept[pkgPath] = name
+		}
+		ident.Name = name
+		return true
+	})
+	return kept
+}
+
+// testifyPkgOfIdent returns the import path of the testify assert or require
+// package referenced by ident, or an empty string if ident does not reference
+// one of those packages.
+func testifyPkgOfIdent(ident *ast.Ident, migration migration) string {
+	if pkgPath, ok := migration.testifyIdents[ident]; ok {
+		return pkgPath
+	}
+	if migration.pkgInfo == nil {
+		return ""
+	}
+	pkgName, ok := migration.pkgInfo.Uses[ident].(*types.PkgName)
+	if !ok {
+		return ""
+	}
+	for _, pkgPath := range allTestifyPks {
+		if pkgName.Imported().Path() == pkgPath {
+			return pkgPath
+		}
+	}
+	return ""
+}
+
+func syntheticTestifyPkgName(pkgPath string) string {
+	if pkgPath == pkgTestifyRequire || pkgPath == pkgGopkgTestifyRequire {
+		return syntheticTestifyRequire
+	}
+	return syntheticTestifyAssert
+}
+
+// updateImports removes the imports of the testify packages, except for the
+// packages in kept which are still used, and adds the gotest.tools imports.
+func updateImports(migration migration, kept map[string]string) {
 	for _, remove := range allTestifyPks {
 		astutil.DeleteImport(migration.fileset, migration.file, remove)
 	}
+	for pkgPath, name := range kept {
+		alias := ""
+		if name != path.Base(pkgPath) {
+			alias = name
+		}
+		astutil.AddNamedImport(migration.fileset, migration.file, alias, pkgPath)
+	}
 
 	var alias string
 	if migration.importNames.assert != path.Base(pkgAssert) {
@@ -58,6 +152,20 @@ func updateImports(migration migration) {
 		alias = migration.importNames.cmp
 	}
 	astutil.AddNamedImport(migration.fileset, migration.file, alias, pkgCmp)
+
+	if migration.importNames.testifySuite != "" {
+		for _, remove := range []string{pkgTestifySuite, pkgGopkgTestifySuite} {
+			astutil.DeleteImport(migration.fileset, migration.file, remove)
+		}
+		if !migration.subtestSuites || usesPkgName(migration.file, migration.importNames.testifySuite) {
+			alias = ""
+			if migration.importNames.testifySuite != path.Base(pkgSuite) {
+				alias = migration.importNames.testifySuite
+			}
+			astutil.AddNamedImport(migration.fileset, migration.file, alias, pkgSuite)
+		}
+		astutil.AddImport(migration.fileset, migration.file, "testing")
+	}
 }
 
 type emptyNode struct{}
@@ -82,6 +190,8 @@ func replaceCalls(migration migration) func(cursor *astutil.Cursor) bool {
 			newNode = getReplacementAssertion(typed, migration)
 		case *ast.AssignStmt:
 			newNode = getReplacementAssignment(typed, migration)
+		case *ast.DeclStmt:
+			newNode = getReplacementDecl(typed, migration)
 		}
 
 		switch newNode {
@@ -115,7 +225,7 @@ func getReplacementAssertion(callExpr *ast.CallExpr, migration migration) ast.No
 		return nil
 	}
 	if len(tcall.expr.Args) < 2 {
-		return convertTestifySingleArgCall(tcall)
+		return convertTestifySingleArgCall(tcall, migration)
 	}
 	return convertTestifyAssertion(tcall, migration)
 }
@@ -127,7 +237,14 @@ func getReplacementAssignment(assign *ast.AssignStmt, migration migration) ast.N
 	return nil
 }
 
-func convertTestifySingleArgCall(tcall call) ast.Node {
+func getReplacementDecl(decl *ast.DeclStmt, migration migration) ast.Node {
+	if isDeclFromAssertNew(decl, migration) {
+		return removeNode
+	}
+	return nil
+}
+
+func convertTestifySingleArgCall(tcall call, migration migration) ast.Node {
 	switch tcall.selExpr.Sel.Name {
 	case "TestingT":
 		// handled as SelectorExpr
@@ -136,7 +253,7 @@ func convertTestifySingleArgCall(tcall call) ast.Node {
 		// handled by getReplacementAssignment
 		return nil
 	default:
-		log.Printf("%s: skipping unknown selector", tcall.StringWithFileInfo())
+		migration.skip(tcall.expr, "unknown selector %s", tcall)
 		return nil
 	}
 }
@@ -165,15 +282,51 @@ func convertTestifyAssertion(tcall call, migration migration) ast.Node { //nolin
 		return convertError(tcall, imports)
 	case "ErrorContains", "ErrorContainsf":
 		return convertErrorContains(tcall, imports)
+	case "ErrorIs", "ErrorIsf":
+		return convertErrorIs(tcall, imports)
+	case "NotErrorIs", "NotErrorIsf":
+		return convertBoolCall(tcall, imports, "errors", "Is", true)
+	case "ErrorAs", "ErrorAsf":
+		return convertBoolCall(tcall, imports, "errors", "As", false)
+	case "Regexp", "Regexpf":
+		return convertTwoArgComparison(tcall, imports, "Regexp")
+	case "Greater", "Greaterf":
+		return convertBinaryComparison(tcall, imports, token.GTR)
+	case "GreaterOrEqual", "GreaterOrEqualf":
+		return convertBinaryComparison(tcall, imports, token.GEQ)
+	case "Less", "Lessf":
+		return convertBinaryComparison(tcall, imports, token.LSS)
+	case "LessOrEqual", "LessOrEqualf":
+		return convertBinaryComparison(tcall, imports, token.LEQ)
+	case "Same", "Samef":
+		return convertBinaryComparison(tcall, imports, token.EQL)
+	case "NotSame", "NotSamef":
+		return convertBinaryComparison(tcall, imports, token.NEQ)
+	case "Positive", "Positivef":
+		zero := &ast.BasicLit{Kind: token.INT, Value: "0"}
+		return convertComparisonWith(tcall, imports, token.GTR, zero)
+	case "Negative", "Negativef":
+		zero := &ast.BasicLit{Kind: token.INT, Value: "0"}
+		return convertComparisonWith(tcall, imports, token.LSS, zero)
+	case "Condition", "Conditionf":
+		return newCallExprWithPosition(tcall, imports,
+			newCallExprArgs(
+				tcall.testingT(),
+				&ast.CallExpr{Fun: tcall.arg(1)},
+				tcall.extraArgs(2)...))
 	case "Empty", "Emptyf":
 		return convertEmpty(tcall, imports)
 	case "Nil", "Nilf":
 		return convertNil(tcall, migration)
 	case "NotNil", "NotNilf":
 		return convertNegativeComparison(tcall, imports, &ast.Ident{Name: "nil"}, 2)
-	case "NotEqual", "NotEqualf":
+	case "NotEqual", "NotEqualf", "NotEqualValues", "NotEqualValuesf":
 		return convertNegativeComparison(tcall, imports, tcall.arg(2), 3)
 	case "Fail", "Failf":
+		// require.Fail stops the test, like FailNow
+		if tcall.assert == funcNameAssert {
+			return convertFail(tcall, "Fatal")
+		}
 		return convertFail(tcall, "Error")
 	case "FailNow", "FailNowf":
 		return convertFail(tcall, "Fatal")
@@ -183,7 +336,7 @@ func convertTestifyAssertion(tcall call, migration migration) ast.Node { //nolin
 		zero := &ast.BasicLit{Kind: token.INT, Value: "0"}
 		return convertNegativeComparison(tcall, imports, zero, 2)
 	}
-	log.Printf("%s: skipping unsupported assertion", tcall.StringWithFileInfo())
+	migration.skip(tcall.expr, "unsupported assertion %s", tcall)
 	return nil
 }
 
@@ -314,6 +467,9 @@ func convertError(tcall call, imports importNames) ast.Node {
 }
 
 func convertErrorContains(tcall call, imports importNames) ast.Node {
+	if tcall.assert == funcNameCheck {
+		return convertTwoArgComparison(tcall, imports, "ErrorContains")
+	}
 	return &ast.CallExpr{
 		Fun: &ast.SelectorExpr{
 			X: &ast.Ident{
@@ -326,6 +482,45 @@ func convertErrorContains(tcall call, imports importNames) ast.Node {
 	}
 }
 
+func convertErrorIs(tcall call, imports importNames) ast.Node {
+	if tcall.assert == funcNameAssert {
+		return newCallExprWithoutComparison(tcall, imports, "ErrorIs")
+	}
+	return convertTwoArgComparison(tcall, imports, "ErrorIs")
+}
+
+// convertBoolCall converts an assertion with two arguments to an assertion on
+// the result of calling pkg.name with those arguments, for example
+// errors.Is(err, target).
+func convertBoolCall(tcall call, imports importNames, pkg, name string, negate bool) ast.Node {
+	var expr ast.Expr = newCallExpr(pkg, name, tcall.args(1, 3))
+	if negate {
+		expr = &ast.UnaryExpr{Op: token.NOT, X: expr}
+	}
+	return newCallExprWithPosition(tcall, imports,
+		newCallExprArgs(tcall.testingT(), expr, tcall.extraArgs(3)...))
+}
+
+// convertBinaryComparison converts an assertion with two arguments, x and y,
+// to an assertion on the expression x <op> y.
+func convertBinaryComparison(tcall call, imports importNames, op token.Token) ast.Node {
+	return newCallExprWithPosition(tcall, imports,
+		newCallExprArgs(
+			tcall.testingT(),
+			&ast.BinaryExpr{X: tcall.arg(1), Op: op, Y: tcall.arg(2)},
+			tcall.extraArgs(3)...))
+}
+
+// convertComparisonWith converts an assertion with one argument, x, to an
+// assertion on the expression x <op> y.
+func convertComparisonWith(tcall call, imports importNames, op token.Token, y ast.Expr) ast.Node {
+	return newCallExprWithPosition(tcall, imports,
+		newCallExprArgs(
+			tcall.testingT(),
+			&ast.BinaryExpr{X: tcall.arg(1), Op: op, Y: y},
+			tcall.extraArgs(2)...))
+}
+
 func convertEmpty(tcall call, imports importNames) ast.Node {
 	cmpArgs := []ast.Expr{
 		tcall.arg(1),
@@ -6,6 +6,7 @@ import (
 
 	"golang.org/x/tools/go/packages"
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
 	"gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/env"
 	"gotest.tools/v3/fs"
@@ -60,10 +61,18 @@ func do(t assert.TestingT) {}
 }
 
 func newMigrationFromSource(t *testing.T, source string) migration {
+	t.Helper()
+	return newMigrationFromSourceWithOptions(t, source, options{})
+}
+
+func newMigrationFromSourceWithOptions(t *testing.T, source string, opts options) migration {
 	t.Helper()
 	goMod := `module example.com/foo
 
-require  github.com/stretchr/testify v1.7.1
+require (
+	github.com/onsi/gomega v1.42.1
+	github.com/stretchr/testify v1.7.1
+)
 `
 
 	dir := fs.NewDir(t, t.Name(),
@@ -74,8 +83,8 @@ require  github.com/stretchr/testify v1.7.1
 	env.ChangeWorkingDir(t, dir.Path())
 	icmd.RunCommand("go", "mod", "tidy").Assert(t, icmd.Success)
 
-	opts := options{pkgs: []string{"./..."}}
-	pkgs, err := loadPackages(opts, fileset)
+	opts.Pkgs = []string{"./..."}
+	pkgs, err := migrate.LoadPackages(opts.Options, fileset)
 	assert.NilError(t, err)
 	packages.PrintErrors(pkgs)
 
@@ -83,10 +92,11 @@ require  github.com/stretchr/testify v1.7.1
 	assert.Assert(t, !pkg.IllTyped)
 
 	return migration{
-		file:        pkg.Syntax[0],
-		fileset:     fileset,
-		importNames: newImportNames(pkg.Syntax[0].Imports, opts),
-		pkgInfo:     pkg.TypesInfo,
+		file:          pkg.Syntax[0],
+		fileset:       fileset,
+		importNames:   newImportNames(pkg.Syntax[0].Imports, opts),
+		pkgInfo:       pkg.TypesInfo,
+		subtestSuites: opts.suites == suitesSubtests,
 	}
 }
 
@@ -379,3 +389,193 @@ func TestSomething(t *testing.T) {
 	assert.NilError(t, err)
 	assert.Assert(t, cmp.Equal(expected, string(actual)))
 }
+
+func TestMigrateFileComparisonAssertions(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSomething(t *testing.T) {
+	err := errors.New("oops")
+	var target *os.PathError
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	require.ErrorIs(t, err, os.ErrNotExist, "must be")
+	assert.NotErrorIs(t, err, os.ErrExist)
+	require.ErrorAs(t, err, &target)
+	assert.ErrorContains(t, err, "oo")
+	assert.Regexp(t, "^oo", err.Error())
+	assert.Greater(t, 2, 1)
+	require.LessOrEqual(t, 1, 2, "because")
+	assert.Positive(t, 3)
+	require.Same(t, target, target)
+	assert.Condition(t, func() bool { return true })
+	require.Fail(t, "stop")
+}
+`
+	migration := newMigrationFromSource(t, source)
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestSomething(t *testing.T) {
+	err := errors.New("oops")
+	var target *os.PathError
+	assert.Check(t, cmp.ErrorIs(err, os.ErrNotExist))
+	assert.ErrorIs(t, err, os.ErrNotExist, "must be")
+	assert.Check(t, !errors.Is(err, os.ErrExist))
+	assert.Assert(t, errors.As(err, &target))
+	assert.Check(t, cmp.ErrorContains(err, "oo"))
+	assert.Check(t, cmp.Regexp("^oo", err.Error()))
+	assert.Check(t, 2 > 1)
+	assert.Assert(t, 1 <= 2, "because")
+	assert.Check(t, 3 > 0)
+	assert.Assert(t, target == target)
+	assert.Check(t, func() bool { return true }())
+	t.Fatal("stop")
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
+
+func TestMigrateFileConvertAssertNewVarDecl(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSomething(t *testing.T) {
+	var is = require.New(t)
+	is.Equal("one", "two")
+}
+`
+	migration := newMigrationFromSource(t, source)
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSomething(t *testing.T) {
+
+	assert.Equal(t, "one", "two")
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
+
+func TestMigrateFileKeepsUnmigratedTestifyReferences(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSomething(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", assert.AnError)
+	require.ErrorIs(t, err, assert.AnError)
+	assert.True(t, assert.ObjectsAreEqual(1, 1))
+}
+`
+	migration := newMigrationFromSource(t, source)
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"fmt"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"gotest.tools/v3/assert"
+)
+
+func TestSomething(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", testifyassert.AnError)
+	assert.ErrorIs(t, err, testifyassert.AnError)
+	assert.Check(t, testifyassert.ObjectsAreEqual(1, 1))
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
+
+func TestMigrateFileRemovesTestifyImportsWithoutReferences(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSomething(t *testing.T) {
+	err := doSomething()
+	require.NoError(t, err)
+	assert.Equal(t, "a", "b")
+}
+
+func doSomething() error {
+	return nil
+}
+`
+	migration := newMigrationFromSource(t, source)
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestSomething(t *testing.T) {
+	err := doSomething()
+	assert.NilError(t, err)
+	assert.Check(t, cmp.Equal("a", "b"))
+}
+
+func doSomething() error {
+	return nil
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
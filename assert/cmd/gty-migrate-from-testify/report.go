@@ -0,0 +1,11 @@
+package main
+
+import (
+	"go/ast"
+)
+
+// skip records that node could not be migrated. If the migration has no
+// report the message is logged immediately.
+func (m migration) skip(node ast.Node, msg string, args ...interface{}) {
+	m.report.Skip(m.fileset.Position(node.Pos()), msg, args...)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// Values for the --suites flag, which selects how testify suites are
+// migrated.
+const (
+	suitesPackage  = "suite"
+	suitesSubtests = "subtests"
+)
+
+// lowerSuiteRuns replaces each suite.Run(t, s) statement with a call to t.Run
+// for each test method of the suite, in the order of the method names. The
+// SetupSuite and SetupTest hooks are called before the tests, and the
+// TearDownSuite and TearDownTest hooks are registered with t.Cleanup, like
+// gotest.tools/v3/suite.Run.
+func lowerSuiteRuns(migration migration, methods []suiteMethod) {
+	for _, decl := range migration.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		varName := unusedName(funcDecl.Body, "s", "ts", "testSuite")
+		astutil.Apply(funcDecl.Body, func(cursor *astutil.Cursor) bool {
+			stmt, ok := cursor.Node().(*ast.ExprStmt)
+			if !ok {
+				return true
+			}
+			callExpr, ok := stmt.X.(*ast.CallExpr)
+			if !ok || !isSuiteRun(callExpr, migration.importNames) {
+				return true
+			}
+			stmts, ok := newSuiteSubtests(callExpr, methods, varName, migration)
+			if !ok || cursor.Index() < 0 {
+				migration.skip(callExpr, "unable to convert suite.Run to subtests")
+				return false
+			}
+			for _, stmt := range stmts {
+				cursor.InsertBefore(stmt)
+			}
+			cursor.Delete()
+			return false
+		}, nil)
+	}
+}
+
+func isSuiteRun(callExpr *ast.CallExpr, names importNames) bool {
+	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+	return ok && isIdent(selector.X, names.testifySuite) &&
+		selector.Sel.Name == "Run" && len(callExpr.Args) == 2
+}
+
+// newSuiteSubtests returns the statements which replace the suite.Run call.
+func newSuiteSubtests(
+	callExpr *ast.CallExpr,
+	methods []suiteMethod,
+	varName string,
+	migration migration,
+) ([]ast.Stmt, bool) {
+	t, ok := callExpr.Args[0].(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+	typeName := runSuiteTypeName(callExpr.Args[1], migration)
+	hooks := make(map[string]bool)
+	var tests []string
+	for _, method := range methods {
+		if receiverTypeName(method.decl.Recv.List[0].Type) != typeName {
+			continue
+		}
+		name := method.decl.Name.Name
+		switch {
+		case suiteHooks[name]:
+			hooks[name] = true
+		case isTestMethod(name) && isSubtestSignature(method.decl.Type):
+			tests = append(tests, name)
+		}
+	}
+	if typeName == "" || len(tests) == 0 {
+		return nil, false
+	}
+	sort.Strings(tests)
+
+	var stmts []ast.Stmt
+	suiteVar, ok := callExpr.Args[1].(*ast.Ident)
+	if !ok {
+		suiteVar = &ast.Ident{Name: varName}
+		stmts = append(stmts, &ast.AssignStmt{
+			Lhs: []ast.Expr{suiteVar},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{callExpr.Args[1]},
+		})
+	}
+	stmts = append(stmts, newHookStmts(t, suiteVar, hooks, "SetupSuite", "TearDownSuite")...)
+
+	subtestT := &ast.Ident{Name: "t"}
+	for _, name := range tests {
+		body := newHookStmts(subtestT, suiteVar, hooks, "SetupTest", "TearDownTest")
+		body = append(body, &ast.ExprStmt{X: newMethodCall(suiteVar, name, subtestT)})
+		funcLit := &ast.FuncLit{
+			Type: &ast.FuncType{},
+			Body: &ast.BlockStmt{List: body},
+		}
+		addTestingTParam(funcLit.Type)
+		stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: t, Sel: &ast.Ident{Name: "Run"}},
+			Args: []ast.Expr{
+				&ast.BasicLit{Kind: token.STRING, Value: `"` + name + `"`},
+				funcLit,
+			},
+		}})
+	}
+	return stmts, true
+}
+
+// newHookStmts returns a call to the setup hook, and a call to t.Cleanup
+// which runs the teardown hook, for the hooks implemented by the suite.
+func newHookStmts(t, suiteVar *ast.Ident, hooks map[string]bool, setup, teardown string) []ast.Stmt {
+	var stmts []ast.Stmt
+	if hooks[setup] {
+		stmts = append(stmts, &ast.ExprStmt{X: newMethodCall(suiteVar, setup, t)})
+	}
+	if hooks[teardown] {
+		stmts = append(stmts, &ast.ExprStmt{X: &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: t, Sel: &ast.Ident{Name: "Cleanup"}},
+			Args: []ast.Expr{&ast.FuncLit{
+				Type: &ast.FuncType{Params: &ast.FieldList{}},
+				Body: &ast.BlockStmt{List: []ast.Stmt{
+					&ast.ExprStmt{X: newMethodCall(suiteVar, teardown, t)},
+				}},
+			}},
+		}})
+	}
+	return stmts
+}
+
+func newMethodCall(recv *ast.Ident, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: recv, Sel: &ast.Ident{Name: name}},
+		Args: args,
+	}
+}
+
+// isSubtestSignature returns true if the migrated test method only accepts a
+// *testing.T, and has no results.
+func isSubtestSignature(funcType *ast.FuncType) bool {
+	return len(funcType.Params.List) == 1 && len(funcType.Params.List[0].Names) == 1 &&
+		(funcType.Results == nil || len(funcType.Results.List) == 0)
+}
+
+// runSuiteTypeName returns the name of the type of the suite passed to
+// suite.Run, or an empty string if the type is not known.
+func runSuiteTypeName(expr ast.Expr, migration migration) string {
+	if migration.pkgInfo != nil {
+		typ := migration.pkgInfo.TypeOf(expr)
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); ok {
+			return named.Obj().Name()
+		}
+	}
+	if _, ok := expr.(*ast.Ident); ok {
+		return ""
+	}
+	return suiteTypeName(expr)
+}
+
+// unusedName returns the first of names which is not used as an identifier
+// in node.
+func unusedName(node ast.Node, names ...string) string {
+	used := make(map[string]bool)
+	ast.Inspect(node, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			used[ident.Name] = true
+		}
+		return true
+	})
+	for _, name := range names {
+		if !used[name] {
+			return name
+		}
+	}
+	return names[len(names)-1]
+}
+
+// usesPkgName returns true if a selector in file has the package name pkg.
+func usesPkgName(file *ast.File, pkg string) bool {
+	found := false
+	ast.Inspect(file, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok && isIdent(selector.X, pkg) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
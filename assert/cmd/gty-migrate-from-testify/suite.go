@@ -0,0 +1,352 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+const (
+	pkgTestifySuite      = "github.com/stretchr/testify/suite"
+	pkgGopkgTestifySuite = "gopkg.in/stretchr/testify.v1/suite"
+	pkgSuite             = "gotest.tools/v3/suite"
+)
+
+// Names used for the testify packages in assertions created from suite
+// methods, when the file does not import the package.
+const (
+	syntheticTestifyAssert  = "testifyassert"
+	syntheticTestifyRequire = "testifyrequire"
+)
+
+// suiteHooks are the methods of a testify suite which are converted to the
+// hooks of gotest.tools/v3/suite, by adding a *testing.T parameter.
+var suiteHooks = map[string]bool{
+	"SetupSuite":    true,
+	"TearDownSuite": true,
+	"SetupTest":     true,
+	"TearDownTest":  true,
+}
+
+// unsupportedSuiteHooks are testify suite hooks which have no equivalent in
+// gotest.tools/v3/suite.
+var unsupportedSuiteHooks = map[string]bool{
+	"BeforeTest":      true,
+	"AfterTest":       true,
+	"SetupSubTest":    true,
+	"TearDownSubTest": true,
+	"HandleStats":     true,
+}
+
+// migrateSuites converts testify suites to gotest.tools/v3/suite. The
+// suite.Suite field is removed from the suite struct, and a t *testing.T
+// parameter is added to the test methods, hooks, and helper methods which use
+// the suite. Calls to s.T() are replaced by t, and assertion methods on the
+// suite, like s.Equal(...) and s.Require().NoError(...), are replaced with
+// calls to testify package functions, which are then migrated by
+// replaceCalls.
+func migrateSuites(migration migration) migration {
+	if migration.importNames.testifySuite == "" {
+		return migration
+	}
+	suiteTypes := removeSuiteFields(migration)
+	if len(suiteTypes) == 0 {
+		return migration
+	}
+
+	if migration.importNames.testifyAssert == "" {
+		migration.importNames.testifyAssert = syntheticTestifyAssert
+	}
+	if migration.importNames.testifyRequire == "" {
+		migration.importNames.testifyRequire = syntheticTestifyRequire
+	}
+	migration.testifyIdents = make(map[*ast.Ident]string)
+
+	methods := suiteMethods(migration.file, suiteTypes)
+	needsT := make(map[string]bool)
+	for _, method := range methods {
+		name := method.decl.Name.Name
+		switch {
+		case unsupportedSuiteHooks[name]:
+			migration.skip(method.decl, "unsupported suite method %s", name)
+		case isTestMethod(name), suiteHooks[name], usesSuite(method, migration):
+			needsT[name] = true
+		}
+	}
+
+	for _, method := range methods {
+		if !needsT[method.decl.Name.Name] {
+			continue
+		}
+		addTestingTParam(method.decl.Type)
+		if method.recv != "" {
+			replaceSuiteCalls(method, needsT, migration)
+		}
+	}
+	if migration.subtestSuites {
+		lowerSuiteRuns(migration, methods)
+	}
+	return migration
+}
+
+// removeSuiteFields removes the embedded suite.Suite field from struct types,
+// and returns the names of the types.
+func removeSuiteFields(migration migration) map[string]bool {
+	suiteTypes := make(map[string]bool)
+	for _, decl := range migration.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			fields := structType.Fields.List[:0]
+			for _, field := range structType.Fields.List {
+				if len(field.Names) == 0 && isTestifySuiteType(field.Type, migration.importNames) {
+					suiteTypes[typeSpec.Name.Name] = true
+					continue
+				}
+				fields = append(fields, field)
+			}
+			structType.Fields.List = fields
+		}
+	}
+	return suiteTypes
+}
+
+func isTestifySuiteType(expr ast.Expr, names importNames) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := selector.X.(*ast.Ident)
+	return ok && ident.Name == names.testifySuite && selector.Sel.Name == "Suite"
+}
+
+type suiteMethod struct {
+	decl *ast.FuncDecl
+	// recv is the name of the receiver, or an empty string if the receiver
+	// is not named.
+	recv string
+}
+
+func suiteMethods(file *ast.File, suiteTypes map[string]bool) []suiteMethod {
+	var methods []suiteMethod
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv == nil || len(funcDecl.Recv.List) != 1 {
+			continue
+		}
+		field := funcDecl.Recv.List[0]
+		if !suiteTypes[receiverTypeName(field.Type)] {
+			continue
+		}
+		method := suiteMethod{decl: funcDecl}
+		if len(field.Names) == 1 && field.Names[0].Name != "_" {
+			method.recv = field.Names[0].Name
+		}
+		methods = append(methods, method)
+	}
+	return methods
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func isTestMethod(name string) bool {
+	return strings.HasPrefix(name, "Test")
+}
+
+// usesSuite returns true if the method calls a method of the testify suite,
+// or an assertion method, on its receiver.
+func usesSuite(method suiteMethod, migration migration) bool {
+	if method.recv == "" || method.decl.Body == nil {
+		return false
+	}
+	found := false
+	ast.Inspect(method.decl.Body, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok || !isIdent(selector.X, method.recv) {
+			return !found
+		}
+		if testifyPkgOfSelection(selector, migration) != "" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// testifyPkgOfSelection returns the import path of the testify package which
+// declares the method selected by selector, or an empty string if the method
+// is not declared by testify.
+func testifyPkgOfSelection(selector *ast.SelectorExpr, migration migration) string {
+	if migration.pkgInfo == nil {
+		return ""
+	}
+	selection, ok := migration.pkgInfo.Selections[selector]
+	if !ok || selection.Kind() != types.MethodVal || selection.Obj().Pkg() == nil {
+		return ""
+	}
+	switch path := selection.Obj().Pkg().Path(); path {
+	case pkgTestifyAssert, pkgGopkgTestifyAssert, pkgTestifySuite, pkgGopkgTestifySuite:
+		return path
+	}
+	return ""
+}
+
+func isIdent(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func newTestingTField() *ast.Field {
+	return &ast.Field{
+		Names: []*ast.Ident{{Name: "t"}},
+		Type: &ast.StarExpr{X: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: "testing"},
+			Sel: &ast.Ident{Name: "T"},
+		}},
+	}
+}
+
+func addTestingTParam(funcType *ast.FuncType) {
+	if funcType.Params == nil {
+		funcType.Params = &ast.FieldList{}
+	}
+	funcType.Params.List = append([]*ast.Field{newTestingTField()}, funcType.Params.List...)
+}
+
+// replaceSuiteCalls replaces the calls to methods of the testify suite in the
+// body of method.
+func replaceSuiteCalls(method suiteMethod, needsT map[string]bool, migration migration) {
+	names := migration.importNames
+	astutil.Apply(method.decl.Body, func(cursor *astutil.Cursor) bool {
+		callExpr, ok := cursor.Node().(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		// s.Require().Equal(...) and s.Assert().Equal(...)
+		if inner, ok := selector.X.(*ast.CallExpr); ok && len(inner.Args) == 0 {
+			if innerSel, ok := inner.Fun.(*ast.SelectorExpr); ok && isIdent(innerSel.X, method.recv) {
+				if pkg := assertionsPkgName(innerSel.Sel.Name, names); pkg != "" {
+					cursor.Replace(newTestifyPkgCall(migration, pkg, selector.Sel, innerSel.X, callExpr.Args))
+					return true
+				}
+			}
+		}
+
+		recv, ok := selector.X.(*ast.Ident)
+		if !ok || recv.Name != method.recv {
+			return true
+		}
+		name := selector.Sel.Name
+		switch testifyPkgOfSelection(selector, migration) {
+		case pkgTestifySuite, pkgGopkgTestifySuite:
+			switch {
+			case name == "T" && len(callExpr.Args) == 0:
+				cursor.Replace(&ast.Ident{Name: "t", NamePos: recv.NamePos})
+			case assertionsPkgName(name, names) != "" && len(callExpr.Args) == 0:
+				// r := s.Require() is converted by the assert.New migration
+				cursor.Replace(newTestifyPkgCall(migration, assertionsPkgName(name, names),
+					&ast.Ident{Name: "New"}, recv, nil))
+			case name == "Run" && len(callExpr.Args) == 2:
+				replaceSuiteRun(callExpr)
+			default:
+				migration.skip(callExpr, "unsupported suite method %s", name)
+			}
+		case pkgTestifyAssert, pkgGopkgTestifyAssert:
+			cursor.Replace(newTestifyPkgCall(migration, names.testifyAssert, selector.Sel, recv, callExpr.Args))
+		default:
+			if needsT[name] {
+				callExpr.Args = append([]ast.Expr{&ast.Ident{Name: "t"}}, callExpr.Args...)
+			}
+		}
+		return true
+	}, nil)
+}
+
+// assertionsPkgName returns the name of the testify package which has the
+// assertions returned by the suite method name.
+func assertionsPkgName(name string, names importNames) string {
+	switch name {
+	case "Require":
+		return names.testifyRequire
+	case "Assert":
+		return names.testifyAssert
+	}
+	return ""
+}
+
+// newTestifyPkgCall returns a call to the package level testify function
+// pkg.sel, with t as the first argument. The package name is recorded in
+// migration.testifyIdents, so that the import is kept if the call is not
+// migrated by replaceCalls.
+func newTestifyPkgCall(
+	migration migration,
+	pkg string,
+	sel *ast.Ident,
+	pos ast.Node,
+	args []ast.Expr,
+) *ast.CallExpr {
+	ident := &ast.Ident{Name: pkg, NamePos: pos.Pos()}
+	migration.testifyIdents[ident] = testifyImportPath(migration, pkg)
+	return &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   ident,
+			Sel: &ast.Ident{Name: sel.Name},
+		},
+		Args: append([]ast.Expr{&ast.Ident{Name: "t"}}, args...),
+	}
+}
+
+// testifyImportPath returns the import path of the testify assert or require
+// package with the name pkg.
+func testifyImportPath(migration migration, pkg string) string {
+	for _, spec := range migration.file.Imports {
+		pkgPath := strings.Trim(spec.Path.Value, `"`)
+		if isTestifyPkg(pkgPath) && identOrDefault(spec.Name, path.Base(pkgPath)) == pkg {
+			return pkgPath
+		}
+	}
+	if pkg == migration.importNames.testifyRequire {
+		return pkgTestifyRequire
+	}
+	return pkgTestifyAssert
+}
+
+// replaceSuiteRun replaces s.Run(name, func() {...}) with
+// t.Run(name, func(t *testing.T) {...}).
+func replaceSuiteRun(callExpr *ast.CallExpr) {
+	selector := callExpr.Fun.(*ast.SelectorExpr)
+	callExpr.Fun = &ast.SelectorExpr{
+		X:   &ast.Ident{Name: "t", NamePos: selector.X.Pos()},
+		Sel: selector.Sel,
+	}
+	if funcLit, ok := callExpr.Args[1].(*ast.FuncLit); ok {
+		addTestingTParam(funcLit.Type)
+	}
+}
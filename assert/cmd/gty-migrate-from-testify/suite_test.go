@@ -0,0 +1,206 @@
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestMigrateFileConvertsSuite(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StoreSuite struct {
+	suite.Suite
+	items []string
+}
+
+func (s *StoreSuite) SetupTest() {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TestAdd() {
+	s.items = append(s.items, "b")
+	s.Len(s.items, 2)
+	s.Require().Equal("a", s.items[0])
+	s.checkFirst("a")
+}
+
+func (s *StoreSuite) TestSubtests() {
+	r := s.Require()
+	r.NotEmpty(s.items)
+	s.Run("first", func() {
+		s.T().Log("first")
+		s.Assert().Equal("a", s.items[0])
+	})
+}
+
+func (s *StoreSuite) checkFirst(expected string) {
+	s.Equal(expected, s.items[0])
+}
+
+func (s *StoreSuite) reset() {
+	s.items = nil
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
+`
+	migration := newMigrationFromSource(t, source)
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/suite"
+)
+
+type StoreSuite struct {
+	items []string
+}
+
+func (s *StoreSuite) SetupTest(t *testing.T) {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TestAdd(t *testing.T) {
+	s.items = append(s.items, "b")
+	assert.Check(t, cmp.Len(s.items, 2))
+	assert.Equal(t, "a", s.items[0])
+	s.checkFirst(t, "a")
+}
+
+func (s *StoreSuite) TestSubtests(t *testing.T) {
+
+	assert.Assert(t, len(s.items) != 0)
+	t.Run("first", func(t *testing.T) {
+		t.Log("first")
+		assert.Check(t, cmp.Equal("a", s.items[0]))
+	})
+}
+
+func (s *StoreSuite) checkFirst(t *testing.T, expected string) {
+	assert.Check(t, cmp.Equal(expected, s.items[0]))
+}
+
+func (s *StoreSuite) reset() {
+	s.items = nil
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
+
+func TestMigrateFileConvertsSuiteToSubtests(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type StoreSuite struct {
+	suite.Suite
+	items []string
+}
+
+func (s *StoreSuite) SetupSuite() {}
+
+func (s *StoreSuite) SetupTest() {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TearDownTest() {
+	s.items = nil
+}
+
+func (s *StoreSuite) TestFirst() {
+	s.Equal("a", s.items[0])
+}
+
+func (s *StoreSuite) TestAdd() {
+	s.items = append(s.items, "b")
+	s.Len(s.items, 2)
+}
+
+func TestStoreSuite(t *testing.T) {
+	suite.Run(t, new(StoreSuite))
+}
+`
+	migration := newMigrationFromSourceWithOptions(t, source, options{suites: suitesSubtests})
+	migrateFile(migration)
+
+	expected := `package foo
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+type StoreSuite struct {
+	items []string
+}
+
+func (s *StoreSuite) SetupSuite(t *testing.T) {}
+
+func (s *StoreSuite) SetupTest(t *testing.T) {
+	s.items = []string{"a"}
+}
+
+func (s *StoreSuite) TearDownTest(t *testing.T) {
+	s.items = nil
+}
+
+func (s *StoreSuite) TestFirst(t *testing.T) {
+	assert.Check(t, cmp.Equal("a", s.items[0]))
+}
+
+func (s *StoreSuite) TestAdd(t *testing.T) {
+	s.items = append(s.items, "b")
+	assert.Check(t, cmp.Len(s.items, 2))
+}
+
+func TestStoreSuite(t *testing.T) {
+	s := new(StoreSuite)
+	s.SetupSuite(t)
+	t.Run("TestAdd", func(t *testing.T) {
+		s.SetupTest(t)
+		t.Cleanup(func() {
+			s.TearDownTest(t)
+		})
+		s.TestAdd(t)
+	})
+	t.Run("TestFirst", func(t *testing.T) {
+		s.SetupTest(t)
+		t.Cleanup(func() {
+			s.TearDownTest(t)
+		})
+		s.TestFirst(t)
+	})
+}
+`
+	actual, err := formatFile(migration)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Equal(expected, string(actual)))
+}
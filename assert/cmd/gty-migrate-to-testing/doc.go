@@ -0,0 +1,39 @@
+/*
+Command gty-migrate-to-testing migrates packages from gotest.tools/v3/assert
+to the testing package.
+
+Assertions are replaced by an if statement which calls t.Fatalf, or t.Errorf
+for assert.Check, with a failure message which is as close as possible to the
+message printed by the assertion. The command can be used to remove the
+dependency on gotest.tools from a package, or to see exactly what an
+assertion checks.
+
+	assert.Equal(t, got, "expected")
+
+is migrated to
+
+	if got != "expected" {
+		t.Fatalf("assertion failed: %v (got %T) != %v (\"expected\" %T)", got, got, "expected", "expected")
+	}
+
+Assert and Check of a bool, an error, and the comparisons cmp.Equal,
+cmp.DeepEqual, cmp.Nil, cmp.Len, cmp.Contains, cmp.Regexp, cmp.Error,
+cmp.ErrorContains, and cmp.ErrorIs are migrated, as are NilError, Equal,
+DeepEqual, Error, ErrorContains, and ErrorIs. DeepEqual is migrated to
+reflect.DeepEqual, or to go-cmp when it has options. Other assertions, and
+assertions which are not statements, are reported so they can be migrated by
+hand.
+
+	$ go install gotest.tools/v3/assert/cmd/gty-migrate-to-testing
+
+Usage:
+
+	gty-migrate-to-testing [OPTIONS] PACKAGE [PACKAGE...]
+
+See --help for full usage.
+
+Use --dry-run to review a migration before making it. The files are not
+changed; a unified diff of the changes is printed to stdout, and a summary of
+the calls in each file which could not be migrated is printed to stderr.
+*/
+package main
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+
+	"golang.org/x/tools/imports"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
+)
+
+type options struct {
+	migrate.Options
+}
+
+func main() {
+	name := os.Args[0]
+	flags, opts := setupFlags(name)
+	migrate.HandleExitError(name, flags.Parse(os.Args[1:]))
+	migrate.SetupLogging(opts.Options)
+	opts.Pkgs = flags.Args()
+	migrate.HandleExitError(name, run(*opts, os.Stdout))
+}
+
+func setupFlags(name string) (*flag.FlagSet, *options) {
+	opts := options{}
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	opts.AddFlags(flags)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, `Usage: %s [OPTIONS] PACKAGE [PACKAGE...]
+
+Migrate assertions from gotest.tools/v3/assert and gotest.tools/v3/assert/cmp
+to if statements which call t.Fatalf or t.Errorf.
+
+`, name)
+		flags.PrintDefaults()
+	}
+	return flags, &opts
+}
+
+func run(opts options, out io.Writer) error {
+	imports.LocalPrefix = opts.LocalImportPath
+
+	fset := token.NewFileSet()
+	pkgs, err := migrate.LoadPackages(opts.Options, fset)
+	if err != nil {
+		return fmt.Errorf("failed to load program: %w", err)
+	}
+
+	migrate.Debugf("package count: %d", len(pkgs))
+	for _, pkg := range pkgs {
+		migrate.Debugf("file count for package %v: %d", pkg.PkgPath, len(pkg.Syntax))
+		for _, astFile := range pkg.Syntax {
+			absFilename := fset.File(astFile.Pos()).Name()
+			filename := migrate.RelativePath(absFilename)
+			if !importsAssert(astFile) {
+				migrate.Debugf("skipping file %s, no imports", filename)
+				continue
+			}
+
+			migrate.Debugf("migrating %s", filename)
+			m := &migration{
+				file:    astFile,
+				fileset: fset,
+				pkgInfo: pkg.TypesInfo,
+				report:  &migrate.Report{},
+			}
+			if err := migrateAndWrite(m, absFilename, filename, opts, out); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// migrateAndWrite migrates the file, and writes the result to the file, or
+// when opts.DryRun is set, writes a diff of the changes to out.
+func migrateAndWrite(
+	m *migration,
+	absFilename, filename string,
+	opts options,
+	out io.Writer,
+) error {
+	migrateFile(m)
+	raw, err := formatFile(m)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+	return migrate.WriteFile(opts.Options, m.report, absFilename, filename, raw, out)
+}
+
+func importsAssert(file *ast.File) bool {
+	for _, spec := range file.Imports {
+		switch spec.Path.Value {
+		case `"` + pkgAssert + `"`, `"` + pkgCmp + `"`:
+			return true
+		}
+	}
+	return false
+}
+
+func formatFile(m *migration) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	err := format.Node(buf, m.fileset, m.file)
+	if err != nil {
+		return nil, err
+	}
+	filename := m.fileset.File(m.file.Pos()).Name()
+	return imports.Process(filename, buf.Bytes(), nil)
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/types/typeutil"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
+)
+
+const (
+	pkgAssert = "gotest.tools/v3/assert"
+	pkgCmp    = "gotest.tools/v3/assert/cmp"
+	pkgGocmp  = "github.com/google/go-cmp/cmp"
+)
+
+const failureMessage = "assertion failed: "
+
+type migration struct {
+	file    *ast.File
+	fileset *token.FileSet
+	pkgInfo *types.Info
+	// report records the call sites which could not be migrated. If it is
+	// nil they are logged.
+	report *migrate.Report
+	// reasons are the reasons why calls could not be migrated, which are
+	// used when the calls are reported.
+	reasons map[*ast.CallExpr]string
+	// gocmp is the name of the go-cmp package, which is set when an
+	// assertion is converted to a call to go-cmp.
+	gocmp string
+}
+
+// migrateFile replaces assertions which are statements with if statements
+// which call t.Fatalf or t.Errorf. The calls to the assert and cmp packages
+// which remain in the file are reported.
+func migrateFile(m *migration) {
+	m.reasons = make(map[*ast.CallExpr]string)
+	astutil.Apply(m.file, nil, func(cursor *astutil.Cursor) bool {
+		exprStmt, ok := cursor.Node().(*ast.ExprStmt)
+		if !ok {
+			return true
+		}
+		callExpr, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if stmt := m.convertAssertion(callExpr); stmt != nil {
+			cursor.Replace(stmt)
+		}
+		return true
+	})
+	m.reportRemaining()
+
+	if m.gocmp != "" {
+		astutil.AddNamedImport(m.fileset, m.file, m.gocmp, pkgGocmp)
+	}
+}
+
+// reportRemaining reports the calls to the assert and cmp packages which were
+// not migrated.
+func (m *migration) reportRemaining() {
+	ast.Inspect(m.file, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		fn := m.callee(callExpr)
+		if fn == nil {
+			return true
+		}
+		reason, ok := m.reasons[callExpr]
+		if !ok {
+			reason = "call to " + funcName(fn) + " which is not a statement"
+		}
+		m.skip(callExpr, "%s", reason)
+		return false
+	})
+}
+
+func (m *migration) unsupported(callExpr *ast.CallExpr, reason string) ast.Stmt {
+	m.reasons[callExpr] = reason
+	return nil
+}
+
+// callee returns the function in the assert or cmp package which is called by
+// callExpr, or nil if the function is from a different package.
+func (m *migration) callee(callExpr *ast.CallExpr) *types.Func {
+	fn, ok := typeutil.Callee(m.pkgInfo, callExpr).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return nil
+	}
+	switch fn.Pkg().Path() {
+	case pkgAssert, pkgCmp:
+		return fn
+	}
+	return nil
+}
+
+func funcName(fn *types.Func) string {
+	return fn.Pkg().Name() + "." + fn.Name()
+}
+
+// assertion is a call to a function in the assert package.
+type assertion struct {
+	callExpr *ast.CallExpr
+	t        ast.Expr
+	// fatal is true if the assertion stops the test when it fails.
+	fatal      bool
+	msgAndArgs []ast.Expr
+}
+
+func (m *migration) convertAssertion(callExpr *ast.CallExpr) ast.Stmt {
+	fn := m.callee(callExpr)
+	if fn == nil || fn.Pkg().Path() != pkgAssert {
+		return nil
+	}
+	if len(callExpr.Args) < 2 || callExpr.Ellipsis.IsValid() {
+		return m.unsupported(callExpr, "unsupported arguments to "+funcName(fn))
+	}
+	a := assertion{
+		callExpr: callExpr,
+		t:        callExpr.Args[0],
+		fatal:    fn.Name() != "Check",
+	}
+	if !m.hasMethod(a.t, a.method()+"f") {
+		return m.unsupported(callExpr, "assertion with a t which does not have a "+a.method()+"f method")
+	}
+
+	args := callExpr.Args[1:]
+	msgAndArgs := func(n int) []ast.Expr {
+		if len(args) <= n {
+			return nil
+		}
+		return args[n:]
+	}
+	switch fn.Name() {
+	case "Assert", "Check":
+		a.msgAndArgs = msgAndArgs(1)
+		return m.convertComparison(a, args[0])
+	case "NilError":
+		a.msgAndArgs = msgAndArgs(1)
+		return m.nilError(a, args[0])
+	case "Equal":
+		if len(args) < 2 {
+			break
+		}
+		a.msgAndArgs = msgAndArgs(2)
+		return m.equal(a, args[0], args[1])
+	case "DeepEqual":
+		if len(args) < 2 {
+			break
+		}
+		return m.deepEqual(a, args[0], args[1], args[2:])
+	case "Error", "ErrorContains":
+		if len(args) < 2 {
+			break
+		}
+		a.msgAndArgs = msgAndArgs(2)
+		return m.errorMessage(a, fn.Name(), args[0], args[1])
+	case "ErrorIs":
+		if len(args) < 2 {
+			break
+		}
+		a.msgAndArgs = msgAndArgs(2)
+		return m.errorIs(a, args[0], args[1])
+	}
+	return m.unsupported(callExpr, "unsupported assertion "+funcName(fn))
+}
+
+// method returns the name of the method of t which reports the failure of
+// the assertion, without the f suffix.
+func (a assertion) method() string {
+	if a.fatal {
+		return "Fatal"
+	}
+	return "Error"
+}
+
+func (m *migration) hasMethod(expr ast.Expr, name string) bool {
+	typ := m.pkgInfo.TypeOf(expr)
+	if typ == nil {
+		return false
+	}
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// convertComparison converts an Assert or Check of a bool, an error, or a
+// cmp.Comparison.
+func (m *migration) convertComparison(a assertion, expr ast.Expr) ast.Stmt {
+	typ := m.pkgInfo.TypeOf(expr)
+	if typ == nil {
+		return m.unsupported(a.callExpr, "comparison of unknown type")
+	}
+	if basic, ok := typ.Underlying().(*types.Basic); ok && basic.Info()&types.IsBoolean != 0 {
+		return m.boolean(a, expr)
+	}
+	if isError(typ) {
+		return m.nilError(a, expr)
+	}
+
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return m.unsupported(a.callExpr, "comparison which is not a call to the cmp package")
+	}
+	fn := m.callee(callExpr)
+	if fn == nil || fn.Pkg().Path() != pkgCmp || callExpr.Ellipsis.IsValid() {
+		return m.unsupported(a.callExpr, "comparison which is not a call to the cmp package")
+	}
+	args := callExpr.Args
+	switch {
+	case fn.Name() == "Equal" && len(args) == 2:
+		return m.equal(a, args[0], args[1])
+	case fn.Name() == "DeepEqual" && len(args) >= 2:
+		return m.deepEqual(a, args[0], args[1], args[2:])
+	case fn.Name() == "Nil" && len(args) == 1:
+		return m.isNil(a, args[0])
+	case fn.Name() == "Len" && len(args) == 2:
+		return m.length(a, args[0], args[1])
+	case fn.Name() == "Contains" && len(args) == 2:
+		return m.contains(a, args[0], args[1])
+	case fn.Name() == "Regexp" && len(args) == 2:
+		return m.regexp(a, args[0], args[1])
+	case (fn.Name() == "Error" || fn.Name() == "ErrorContains") && len(args) == 2:
+		return m.errorMessage(a, fn.Name(), args[0], args[1])
+	case fn.Name() == "ErrorIs" && len(args) == 2:
+		return m.errorIs(a, args[0], args[1])
+	}
+	return m.unsupported(a.callExpr, "unsupported comparison "+funcName(fn))
+}
+
+// boolean converts an assertion of a bool. The failure message is the same
+// as the message from the assert package.
+func (m *migration) boolean(a assertion, expr ast.Expr) ast.Stmt {
+	return &ast.IfStmt{
+		Cond: negate(expr),
+		Body: m.fail(a, escape(m.boolFailure(expr))),
+	}
+}
+
+func (m *migration) boolFailure(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.BinaryExpr:
+		x, y := m.source(typed.X), m.source(typed.Y)
+		switch typed.Op {
+		case token.NEQ:
+			return x + " is " + y
+		case token.EQL:
+			return x + " is not " + y
+		case token.GTR:
+			return x + " is <= " + y
+		case token.LSS:
+			return x + " is >= " + y
+		case token.GEQ:
+			return x + " is less than " + y
+		case token.LEQ:
+			return x + " is greater than " + y
+		}
+	case *ast.UnaryExpr:
+		if typed.Op == token.NOT {
+			return m.source(typed.X) + " is true"
+		}
+	case *ast.Ident:
+		return typed.Name + " is false"
+	}
+	return "expression is false: " + m.source(expr)
+}
+
+// negate returns an expression which is true when expr is false.
+func negate(expr ast.Expr) ast.Expr {
+	switch typed := expr.(type) {
+	case *ast.BinaryExpr:
+		switch typed.Op {
+		case token.EQL:
+			return &ast.BinaryExpr{X: typed.X, Op: token.NEQ, Y: typed.Y}
+		case token.NEQ:
+			return &ast.BinaryExpr{X: typed.X, Op: token.EQL, Y: typed.Y}
+		}
+	case *ast.UnaryExpr:
+		if typed.Op == token.NOT {
+			return astutil.Unparen(typed.X)
+		}
+	case *ast.Ident, *ast.CallExpr, *ast.SelectorExpr, *ast.IndexExpr, *ast.ParenExpr:
+		return &ast.UnaryExpr{Op: token.NOT, X: expr}
+	}
+	return &ast.UnaryExpr{Op: token.NOT, X: &ast.ParenExpr{X: expr}}
+}
+
+// nilError converts an assertion that err is nil.
+func (m *migration) nilError(a assertion, err ast.Expr) ast.Stmt {
+	init, refs := m.bind([]string{"err"}, err)
+	return &ast.IfStmt{
+		Init: init,
+		Cond: &ast.BinaryExpr{X: refs[0], Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: m.fail(a, "error is not nil: %v", refs[0]),
+	}
+}
+
+// equal converts an assertion that x == y.
+func (m *migration) equal(a assertion, x, y ast.Expr) ast.Stmt {
+	typeX, typeY := m.pkgInfo.TypeOf(x), m.pkgInfo.TypeOf(y)
+	switch {
+	case typeX == nil || typeY == nil:
+		return m.unsupported(a.callExpr, "Equal of values of unknown type")
+	case !types.AssignableTo(typeX, typeY) && !types.AssignableTo(typeY, typeX):
+		return m.unsupported(a.callExpr, "Equal of values with different types")
+	case !types.Comparable(typeX) || !types.Comparable(typeY):
+		return m.unsupported(a.callExpr, "Equal of values which are not comparable")
+	}
+
+	init, refs := m.bind([]string{"actual", "expected"}, x, y)
+	format := "%v (" + escape(m.source(x)) + " %T) != %v (" + escape(m.source(y)) + " %T)"
+	return &ast.IfStmt{
+		Init: init,
+		Cond: &ast.BinaryExpr{X: refs[0], Op: token.NEQ, Y: refs[1]},
+		Body: m.fail(a, format, refs[0], refs[0], refs[1], refs[1]),
+	}
+}
+
+// deepEqual converts an assertion that x and y are deeply equal. Assertions
+// with go-cmp options are converted to a call to go-cmp, otherwise
+// reflect.DeepEqual is used.
+func (m *migration) deepEqual(a assertion, x, y ast.Expr, opts []ast.Expr) ast.Stmt {
+	header := "\n--- " + escape(m.source(x)) + "\n+++ " + escape(m.source(y)) + "\n"
+	if len(opts) == 0 {
+		init, refs := m.bind([]string{"actual", "expected"}, x, y)
+		return &ast.IfStmt{
+			Init: init,
+			Cond: negate(newCall("reflect", "DeepEqual", refs...)),
+			Body: m.fail(a, header+"-%+v\n+%+v", refs[0], refs[1]),
+		}
+	}
+
+	diff := ast.NewIdent("diff")
+	m.gocmp = "gocmp"
+	return &ast.IfStmt{
+		Init: &ast.AssignStmt{
+			Lhs: []ast.Expr{diff},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{newCall(m.gocmp, "Diff", append([]ast.Expr{x, y}, opts...)...)},
+		},
+		Cond: &ast.BinaryExpr{X: diff, Op: token.NEQ, Y: newString("")},
+		Body: m.fail(a, header+"%s", diff),
+	}
+}
+
+// isNil converts an assertion that a pointer, interface, map, slice, channel
+// or func is nil.
+func (m *migration) isNil(a assertion, expr ast.Expr) ast.Stmt {
+	typ := m.pkgInfo.TypeOf(expr)
+	if typ == nil || !isNillable(typ) {
+		return m.unsupported(a.callExpr, "cmp.Nil of a value which can not be nil")
+	}
+	init, refs := m.bind([]string{"value"}, expr)
+	return &ast.IfStmt{
+		Init: init,
+		Cond: &ast.BinaryExpr{X: refs[0], Op: token.NEQ, Y: ast.NewIdent("nil")},
+		Body: m.fail(a, "%v (type %T) is not nil", refs[0], refs[0]),
+	}
+}
+
+func isNillable(typ types.Type) bool {
+	switch typed := typ.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Map, *types.Slice, *types.Chan, *types.Signature:
+		return true
+	case *types.Basic:
+		return typed.Kind() == types.UnsafePointer
+	}
+	return false
+}
+
+// length converts an assertion that seq has the expected length.
+func (m *migration) length(a assertion, seq, expected ast.Expr) ast.Stmt {
+	init, refs := m.bind([]string{"seq", "expected"}, seq, expected)
+	length := &ast.CallExpr{Fun: ast.NewIdent("len"), Args: []ast.Expr{refs[0]}}
+	return &ast.IfStmt{
+		Init: init,
+		Cond: &ast.BinaryExpr{X: length, Op: token.NEQ, Y: refs[1]},
+		Body: m.fail(a, "expected %s (length %d) to have length %d", refs[0], length, refs[1]),
+	}
+}
+
+// contains converts an assertion that a string contains a substring, or a map
+// contains a key.
+func (m *migration) contains(a assertion, collection, item ast.Expr) ast.Stmt {
+	typ := m.pkgInfo.TypeOf(collection)
+	if typ == nil {
+		return m.unsupported(a.callExpr, "cmp.Contains of a value of unknown type")
+	}
+	switch typed := typ.Underlying().(type) {
+	case *types.Basic:
+		if typed.Info()&types.IsString == 0 {
+			break
+		}
+		init, refs := m.bind([]string{"collection", "item"}, collection, item)
+		return &ast.IfStmt{
+			Init: init,
+			Cond: negate(newCall("strings", "Contains", refs...)),
+			Body: m.fail(a, "string %q does not contain %q", refs...),
+		}
+	case *types.Map:
+		if !m.isSimple(collection) || !m.isSimple(item) {
+			break
+		}
+		ok := ast.NewIdent("ok")
+		return &ast.IfStmt{
+			Init: &ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent("_"), ok},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{&ast.IndexExpr{X: collection, Index: item}},
+			},
+			Cond: negate(ok),
+			Body: m.fail(a, "%v does not contain %v", collection, item),
+		}
+	}
+	return m.unsupported(a.callExpr, "cmp.Contains of a "+typ.String())
+}
+
+// regexp converts an assertion that value matches a regular expression.
+func (m *migration) regexp(a assertion, re, value ast.Expr) ast.Stmt {
+	typ := m.pkgInfo.TypeOf(re)
+	if typ == nil {
+		return m.unsupported(a.callExpr, "cmp.Regexp with a pattern of unknown type")
+	}
+	init, refs := m.bind([]string{"re", "value"}, re, value)
+	var compiled ast.Expr
+	switch {
+	case types.Identical(typ.Underlying(), types.Typ[types.String]) || isUntypedString(typ):
+		compiled = newCall("regexp", "MustCompile", refs[0])
+	case typ.String() == "*regexp.Regexp":
+		compiled = refs[0]
+	default:
+		return m.unsupported(a.callExpr, "cmp.Regexp with a pattern of type "+typ.String())
+	}
+	match := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: compiled, Sel: ast.NewIdent("MatchString")},
+		Args: []ast.Expr{refs[1]},
+	}
+	return &ast.IfStmt{
+		Init: init,
+		Cond: negate(match),
+		Body: m.fail(a, "value %q does not match regexp %q", refs[1], refs[0]),
+	}
+}
+
+func isUntypedString(typ types.Type) bool {
+	basic, ok := typ.(*types.Basic)
+	return ok && basic.Kind() == types.UntypedString
+}
+
+// errorMessage converts an Error or ErrorContains assertion, which checks that
+// err is not nil, and compares the error message.
+func (m *migration) errorMessage(a assertion, name string, err, expected ast.Expr) ast.Stmt {
+	init, refs := m.bind([]string{"err", "expected"}, err, expected)
+	message := &ast.CallExpr{Fun: &ast.SelectorExpr{X: refs[0], Sel: ast.NewIdent("Error")}}
+
+	cond := ast.Expr(&ast.BinaryExpr{X: message, Op: token.NEQ, Y: refs[1]})
+	format := "expected error %q, got %q"
+	if name == "ErrorContains" {
+		cond = negate(newCall("strings", "Contains", message, refs[1]))
+		format = "expected error to contain %q, got %q"
+	}
+	return &ast.IfStmt{
+		Init: init,
+		Cond: &ast.BinaryExpr{X: refs[0], Op: token.EQL, Y: ast.NewIdent("nil")},
+		Body: m.fail(a, "expected an error, got nil"),
+		Else: &ast.IfStmt{
+			Cond: cond,
+			Body: m.fail(a, format, refs[1], refs[0]),
+		},
+	}
+}
+
+// errorIs converts an assertion that errors.Is(err, expected).
+func (m *migration) errorIs(a assertion, err, expected ast.Expr) ast.Stmt {
+	init, refs := m.bind([]string{"err", "expected"}, err, expected)
+	return &ast.IfStmt{
+		Init: init,
+		Cond: negate(newCall("errors", "Is", refs...)),
+		Body: m.fail(a, "error is %v, not %v ("+escape(m.source(expected))+")", refs...),
+	}
+}
+
+// bind returns the expressions to use in place of exprs, so that each
+// expression is evaluated once. Expressions which are not simple are assigned
+// to a variable in the returned statement, using the corresponding name.
+func (m *migration) bind(names []string, exprs ...ast.Expr) (ast.Stmt, []ast.Expr) {
+	refs := make([]ast.Expr, len(exprs))
+	assign := &ast.AssignStmt{Tok: token.DEFINE}
+	for i, expr := range exprs {
+		if m.isSimple(expr) {
+			refs[i] = expr
+			continue
+		}
+		refs[i] = ast.NewIdent(names[i])
+		assign.Lhs = append(assign.Lhs, refs[i])
+		assign.Rhs = append(assign.Rhs, expr)
+	}
+	if len(assign.Lhs) == 0 {
+		return nil, refs
+	}
+	return assign, refs
+}
+
+// isSimple returns true if expr can be evaluated more than once without a
+// side effect.
+func (m *migration) isSimple(expr ast.Expr) bool {
+	if tv, ok := m.pkgInfo.Types[expr]; ok && (tv.Value != nil || tv.IsNil()) {
+		return true
+	}
+	switch typed := expr.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.SelectorExpr:
+		return m.isSimple(typed.X)
+	case *ast.ParenExpr:
+		return m.isSimple(typed.X)
+	}
+	return false
+}
+
+// fail returns the block which reports the failure of the assertion. format
+// and args are the message from the comparison, which are followed by the
+// msgAndArgs of the assertion.
+func (m *migration) fail(a assertion, format string, args ...ast.Expr) *ast.BlockStmt {
+	format = failureMessage + format
+	switch len(a.msgAndArgs) {
+	case 0:
+	case 1:
+		format += ": %v"
+		args = append(args, a.msgAndArgs[0])
+	default:
+		if msg, ok := stringLiteral(a.msgAndArgs[0]); ok {
+			format += ": " + msg
+			args = append(args, a.msgAndArgs[1:]...)
+		} else {
+			format += ": %s"
+			args = append(args, newCall("fmt", "Sprintf", a.msgAndArgs...))
+		}
+	}
+
+	method := a.method() + "f"
+	if len(args) == 0 {
+		method = a.method()
+		format = strings.Replace(format, "%%", "%", -1)
+	}
+	failure := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: a.t, Sel: ast.NewIdent(method)},
+		Args: append([]ast.Expr{newString(format)}, args...),
+	}
+	return &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: failure}}}
+}
+
+func (m *migration) source(expr ast.Expr) string {
+	buf := new(bytes.Buffer)
+	if err := format.Node(buf, m.fileset, expr); err != nil {
+		return "expression"
+	}
+	return buf.String()
+}
+
+// escape s so that it is printed as is when used in a format string.
+func escape(s string) string {
+	return strings.Replace(s, "%", "%%", -1)
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+func newString(value string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(value)}
+}
+
+func newCall(pkg, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(name)},
+		Args: args,
+	}
+}
+
+var errorType = types.Universe.Lookup("error").Type()
+
+func isError(typ types.Type) bool {
+	return types.Identical(typ, errorType)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/packages"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmd/internal/migrate"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+)
+
+func newMigrationFromSource(t *testing.T, source string) *migration {
+	t.Helper()
+	root, err := filepath.Abs("../../..")
+	assert.NilError(t, err)
+	goMod := `module example.com/foo
+
+require gotest.tools/v3 v3.0.0
+
+replace gotest.tools/v3 => ` + root + "\n"
+
+	dir := fs.NewDir(t, t.Name(),
+		fs.WithFile("foo.go", source),
+		fs.WithFile("go.mod", goMod))
+	fileset := token.NewFileSet()
+
+	env.ChangeWorkingDir(t, dir.Path())
+	icmd.RunCommand("go", "mod", "tidy").Assert(t, icmd.Success)
+
+	pkgs, err := migrate.LoadPackages(migrate.Options{Pkgs: []string{"./..."}}, fileset)
+	assert.NilError(t, err)
+	packages.PrintErrors(pkgs)
+
+	pkg := pkgs[0]
+	assert.Assert(t, !pkg.IllTyped)
+	return &migration{
+		file:    pkg.Syntax[0],
+		fileset: fileset,
+		pkgInfo: pkg.TypesInfo,
+	}
+}
+
+func TestMigrateFile(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestSomething(t *testing.T) {
+	count := 2
+	items := []string{"a"}
+	byName := map[string]int{"a": 1}
+	var ptr *int
+	assert.Assert(t, count > 1)
+	assert.Assert(t, count == 2, "count is %d", count)
+	assert.Check(t, !os.IsNotExist(nil))
+	assert.Assert(t, len(items) != 0, "items")
+	assert.Equal(t, count, 2)
+	assert.Equal(t, strconv.Itoa(count), "2%")
+	assert.DeepEqual(t, items, []string{"a"})
+	assert.Check(t, is.Len(items, 1))
+	assert.Assert(t, is.Contains("abc", "b"))
+	assert.Assert(t, is.Contains(byName, "a"))
+	assert.Assert(t, is.Nil(ptr))
+	assert.Assert(t, is.Regexp("^[a-z]+$", items[0]))
+	assert.Assert(t, is.Regexp(regexp.MustCompile("^a"), items[0]))
+
+	_, err := strconv.Atoi("1")
+	assert.NilError(t, err)
+	assert.Check(t, err)
+	assert.NilError(t, os.Setenv("KEY", "value"))
+	assert.Error(t, errNotFound, "not found")
+	assert.ErrorContains(t, errNotFound, "found")
+	assert.ErrorIs(t, errNotFound, errNotFound)
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.Assert(t, is.Contains([]string{"a"}, "a"))
+	if !assert.Check(t, true) {
+		return
+	}
+	assert.ErrorType(t, errNotFound, os.IsNotExist)
+	check(t)
+}
+
+func check(t assert.TestingT) {
+	assert.Assert(t, true)
+}
+`
+	m := newMigrationFromSource(t, source)
+	m.report = &migrate.Report{}
+	migrateFile(m)
+
+	expected := `package foo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestSomething(t *testing.T) {
+	count := 2
+	items := []string{"a"}
+	byName := map[string]int{"a": 1}
+	var ptr *int
+	if !(count > 1) {
+		t.Fatal("assertion failed: count is <= 1")
+	}
+	if count != 2 {
+		t.Fatalf("assertion failed: count is not 2: count is %d", count)
+	}
+	if os.IsNotExist(nil) {
+		t.Error("assertion failed: os.IsNotExist(nil) is true")
+	}
+	if len(items) == 0 {
+		t.Fatalf("assertion failed: len(items) is 0: %v", "items")
+	}
+	if count != 2 {
+		t.Fatalf("assertion failed: %v (count %T) != %v (2 %T)", count, count, 2, 2)
+	}
+	if actual := strconv.Itoa(count); actual != "2%" {
+		t.Fatalf("assertion failed: %v (strconv.Itoa(count) %T) != %v (\"2%%\" %T)", actual, actual, "2%", "2%")
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(items, expected) {
+		t.Fatalf("assertion failed: \n--- items\n+++ []string{\"a\"}\n-%+v\n+%+v", items, expected)
+	}
+	if len(items) != 1 {
+		t.Errorf("assertion failed: expected %s (length %d) to have length %d", items, len(items), 1)
+	}
+	if !strings.Contains("abc", "b") {
+		t.Fatalf("assertion failed: string %q does not contain %q", "abc", "b")
+	}
+	if _, ok := byName["a"]; !ok {
+		t.Fatalf("assertion failed: %v does not contain %v", byName, "a")
+	}
+	if ptr != nil {
+		t.Fatalf("assertion failed: %v (type %T) is not nil", ptr, ptr)
+	}
+	if value := items[0]; !regexp.MustCompile("^[a-z]+$").MatchString(value) {
+		t.Fatalf("assertion failed: value %q does not match regexp %q", value, "^[a-z]+$")
+	}
+	if re, value := regexp.MustCompile("^a"), items[0]; !re.MatchString(value) {
+		t.Fatalf("assertion failed: value %q does not match regexp %q", value, re)
+	}
+
+	_, err := strconv.Atoi("1")
+	if err != nil {
+		t.Fatalf("assertion failed: error is not nil: %v", err)
+	}
+	if err != nil {
+		t.Errorf("assertion failed: error is not nil: %v", err)
+	}
+	if err := os.Setenv("KEY", "value"); err != nil {
+		t.Fatalf("assertion failed: error is not nil: %v", err)
+	}
+	if errNotFound == nil {
+		t.Fatal("assertion failed: expected an error, got nil")
+	} else if errNotFound.Error() != "not found" {
+		t.Fatalf("assertion failed: expected error %q, got %q", "not found", errNotFound)
+	}
+	if errNotFound == nil {
+		t.Fatal("assertion failed: expected an error, got nil")
+	} else if !strings.Contains(errNotFound.Error(), "found") {
+		t.Fatalf("assertion failed: expected error to contain %q, got %q", "found", errNotFound)
+	}
+	if !errors.Is(errNotFound, errNotFound) {
+		t.Fatalf("assertion failed: error is %v, not %v (errNotFound)", errNotFound, errNotFound)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.Assert(t, is.Contains([]string{"a"}, "a"))
+	if !assert.Check(t, true) {
+		return
+	}
+	assert.ErrorType(t, errNotFound, os.IsNotExist)
+	check(t)
+}
+
+func check(t assert.TestingT) {
+	assert.Assert(t, true)
+}
+`
+	actual, err := formatFile(m)
+	assert.NilError(t, err)
+	assert.Equal(t, string(actual), expected)
+
+	assert.DeepEqual(t, m.report.Skipped(), []string{
+		"foo.go:46: skipping cmp.Contains of a []string",
+		"foo.go:47: skipping call to assert.Check which is not a statement",
+		"foo.go:50: skipping unsupported assertion assert.ErrorType",
+		"foo.go:55: skipping assertion with a t which does not have a Fatalf method",
+	})
+}
+
+func TestMigrateFileDeepEqualWithOptions(t *testing.T) {
+	source := `
+package foo
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"gotest.tools/v3/assert"
+)
+
+func TestSomething(t *testing.T) {
+	items := []string{"b", "a"}
+	assert.DeepEqual(t, items, []string{"a", "b"}, cmpopts.SortSlices(func(x, y string) bool { return x < y }))
+}
+`
+	m := newMigrationFromSource(t, source)
+	migrateFile(m)
+
+	expected := `package foo
+
+import (
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestSomething(t *testing.T) {
+	items := []string{"b", "a"}
+	if diff := gocmp.Diff(items, []string{"a", "b"}, cmpopts.SortSlices(func(x, y string) bool { return x < y })); diff != "" {
+		t.Fatalf("assertion failed: \n--- items\n+++ []string{\"a\", \"b\"}\n%s", diff)
+	}
+}
+`
+	actual, err := formatFile(m)
+	assert.NilError(t, err)
+	assert.Equal(t, string(actual), expected)
+}
@@ -0,0 +1,84 @@
+package migrate
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+)
+
+// Options are the options shared by the migration commands.
+type Options struct {
+	Pkgs             []string
+	DryRun           bool
+	Debug            bool
+	ShowLoaderErrors bool
+	BuildFlags       []string
+	LocalImportPath  string
+}
+
+// AddFlags adds the flags which set the fields of o to flags.
+func (o *Options) AddFlags(flags *flag.FlagSet) {
+	flags.BoolVar(&o.DryRun, "dry-run", false,
+		"print a diff of the changes instead of writing them to files")
+	flags.BoolVar(&o.Debug, "debug", false, "enable debug logging")
+	flags.BoolVar(&o.ShowLoaderErrors, "print-loader-errors", false,
+		"print errors from loading source")
+	flags.Var((*StringSliceValue)(&o.BuildFlags), "build-flags",
+		"build flags to pass to Go when loading source files")
+	flags.StringVar(&o.LocalImportPath, "local-import-path", "",
+		"value to pass to 'goimports -local' flag for sorting local imports")
+}
+
+// StringSliceValue is a flag.Value for a comma separated list of values. The
+// flag may be set more than once.
+type StringSliceValue []string
+
+func (s StringSliceValue) String() string {
+	return strings.Join(s, ", ")
+}
+
+// Set appends the values in raw.
+func (s *StringSliceValue) Set(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	v, err := csv.NewReader(strings.NewReader(raw)).Read()
+	if err != nil {
+		return err
+	}
+	*s = append(*s, v...)
+	return nil
+}
+
+// HandleExitError exits the command if err is not nil. The exit code is 0 if
+// help was requested, otherwise err is logged and the exit code is 3.
+func HandleExitError(name string, err error) {
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(0)
+	default:
+		log.Println(name + ": Error: " + err.Error())
+		os.Exit(3)
+	}
+}
+
+// SetupLogging configures the log package for the command, and enables
+// Debugf if opts.Debug is set.
+func SetupLogging(opts Options) {
+	log.SetFlags(0)
+	enableDebug = opts.Debug
+}
+
+var enableDebug = false
+
+// Debugf logs a message if debug logging is enabled.
+func Debugf(msg string, args ...interface{}) {
+	if enableDebug {
+		log.Printf("DEBUG: "+msg, args...)
+	}
+}
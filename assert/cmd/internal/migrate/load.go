@@ -0,0 +1,38 @@
+package migrate
+
+import (
+	"go/token"
+
+	"golang.org/x/tools/go/packages"
+)
+
+var loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedDeps |
+	packages.NeedImports |
+	packages.NeedTypes |
+	packages.NeedTypesInfo |
+	packages.NeedTypesSizes |
+	packages.NeedSyntax
+
+// LoadPackages loads opts.Pkgs, including their tests, with the syntax and
+// type information needed to migrate them.
+func LoadPackages(opts Options, fset *token.FileSet) ([]*packages.Package, error) {
+	conf := &packages.Config{
+		Mode:       loadMode,
+		Fset:       fset,
+		Tests:      true,
+		Logf:       Debugf,
+		BuildFlags: opts.BuildFlags,
+	}
+
+	pkgs, err := packages.Load(conf, opts.Pkgs...)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ShowLoaderErrors {
+		packages.PrintErrors(pkgs)
+	}
+	return pkgs, nil
+}
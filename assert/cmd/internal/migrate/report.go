@@ -0,0 +1,130 @@
+/*
+Package migrate provides the reporting, flags, and package loading shared by
+the gty-migrate-from-testify and gty-migrate-to-testing commands.
+*/
+package migrate // import "gotest.tools/v3/assert/cmd/internal/migrate"
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// Report records the call sites in a file which could not be migrated, so
+// they can be listed after the file is migrated.
+type Report struct {
+	skipped []skippedCall
+}
+
+type skippedCall struct {
+	pos     token.Position
+	message string
+}
+
+func (s skippedCall) String() string {
+	return fmt.Sprintf("%s:%d: skipping %s", RelativePath(s.pos.Filename), s.pos.Line, s.message)
+}
+
+// Skip records that the call site at pos could not be migrated. If r is nil
+// the message is logged immediately.
+func (r *Report) Skip(pos token.Position, msg string, args ...interface{}) {
+	skipped := skippedCall{pos: pos, message: fmt.Sprintf(msg, args...)}
+	if r == nil {
+		log.Print(skipped)
+		return
+	}
+	r.skipped = append(r.skipped, skipped)
+}
+
+// Skipped returns the messages for the call sites which could not be
+// migrated, in the order they were recorded.
+func (r *Report) Skipped() []string {
+	var messages []string
+	for _, skipped := range r.skipped {
+		messages = append(messages, skipped.String())
+	}
+	return messages
+}
+
+// LogSkipped logs the call sites which could not be migrated.
+func (r *Report) LogSkipped() {
+	for _, skipped := range r.skipped {
+		log.Print(skipped)
+	}
+}
+
+// LogSummary logs the number of call sites in the file which could not be
+// migrated, and the position of each one.
+func (r *Report) LogSummary(filename string) {
+	if len(r.skipped) == 0 {
+		return
+	}
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "%s: %d call sites could not be migrated:", filename, len(r.skipped))
+	for _, skipped := range r.skipped {
+		fmt.Fprintf(buf, "\n    %s", skipped)
+	}
+	log.Print(buf.String())
+}
+
+// WriteDiff writes a unified diff of the changes made to the file.
+func WriteDiff(out io.Writer, filename string, original, migrated []byte) error {
+	diff := format.UnifiedDiff(format.DiffConfig{
+		A:    string(original),
+		B:    string(migrated),
+		From: "a/" + filepath.ToSlash(filename),
+		To:   "b/" + filepath.ToSlash(filename),
+	})
+	_, err := io.WriteString(out, diff)
+	return err
+}
+
+// WriteFile writes the migrated source to the file, and logs the call sites
+// in report. When opts.DryRun is set the file is not changed, and a diff of
+// the changes is written to out after a summary of report is logged.
+func WriteFile(
+	opts Options,
+	report *Report,
+	absFilename, filename string,
+	migrated []byte,
+	out io.Writer,
+) error {
+	if opts.DryRun {
+		original, err := ioutil.ReadFile(absFilename)
+		if err != nil {
+			return fmt.Errorf("failed to read file %s: %w", filename, err)
+		}
+		report.LogSummary(filename)
+		if err := WriteDiff(out, filename, original, migrated); err != nil {
+			return fmt.Errorf("failed to write diff for %s: %w", filename, err)
+		}
+		return nil
+	}
+
+	report.LogSkipped()
+	if err := ioutil.WriteFile(absFilename, migrated, 0); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filename, err)
+	}
+	return nil
+}
+
+// RelativePath returns p relative to the working directory, or p if it can
+// not be made relative.
+func RelativePath(p string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(cwd, p)
+	if err != nil {
+		return p
+	}
+	return rel
+}
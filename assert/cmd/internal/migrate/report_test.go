@@ -0,0 +1,59 @@
+package migrate
+
+import (
+	"bytes"
+	"flag"
+	"go/token"
+	"log"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestReport(t *testing.T) {
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(os.Stderr)
+	log.SetFlags(0)
+
+	r := &Report{}
+	r.Skip(token.Position{Filename: "foo_test.go", Line: 3}, "unsupported assertion %s", "Foo")
+	r.Skip(token.Position{Filename: "foo_test.go", Line: 7}, "unsupported assertion %s", "Bar")
+	assert.DeepEqual(t, r.Skipped(), []string{
+		"foo_test.go:3: skipping unsupported assertion Foo",
+		"foo_test.go:7: skipping unsupported assertion Bar",
+	})
+	assert.Equal(t, logs.String(), "")
+
+	r.LogSummary("foo_test.go")
+	assert.Equal(t, logs.String(), `foo_test.go: 2 call sites could not be migrated:
+    foo_test.go:3: skipping unsupported assertion Foo
+    foo_test.go:7: skipping unsupported assertion Bar
+`)
+
+	logs.Reset()
+	var nilReport *Report
+	nilReport.Skip(token.Position{Filename: "foo_test.go", Line: 9}, "unsupported assertion")
+	assert.Equal(t, logs.String(), "foo_test.go:9: skipping unsupported assertion\n")
+}
+
+func TestOptionsAddFlags(t *testing.T) {
+	opts := Options{}
+	flags := flag.NewFlagSet("testing", flag.ContinueOnError)
+	opts.AddFlags(flags)
+
+	err := flags.Parse([]string{
+		"--dry-run",
+		"--build-flags=-tags,integration",
+		"--build-flags=-race",
+		"--local-import-path=example.com",
+	})
+	assert.NilError(t, err)
+	expected := Options{
+		DryRun:          true,
+		BuildFlags:      []string{"-tags", "integration", "-race"},
+		LocalImportPath: "example.com",
+	}
+	assert.DeepEqual(t, opts, expected)
+}
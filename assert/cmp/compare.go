@@ -1,4 +1,9 @@
-/*Package cmp provides Comparisons for Assert and Check*/
+/*
+Package cmp provides Comparisons for Assert and Check.
+
+EqualT and DeepEqualT use type parameters, so they require Go 1.18 or later.
+The other comparisons support Go 1.13 or later.
+*/
 package cmp // import "gotest.tools/v3/assert/cmp"
 
 import (
@@ -6,6 +11,8 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"runtime"
+	"runtime/debug"
 	"strings"
 
 	"github.com/google/go-cmp/cmp"
@@ -35,6 +42,11 @@ func DeepEqual(x, y interface{}, opts ...cmp.Option) Comparison {
 		if diff == "" {
 			return ResultSuccess
 		}
+		opts, err := format.DefaultDiffOptions()
+		diff = format.InlineDiff(diff, opts.Inline)
+		if err != nil {
+			diff += "\n" + err.Error()
+		}
 		return multiLineDiffResult(diff, x, y)
 	}
 }
@@ -101,7 +113,8 @@ func Equal(x, y interface{}) Comparison {
 		switch {
 		case x == y:
 			return ResultSuccess
-		case isMultiLineStringCompare(x, y):
+		case isMultiLineStringCompare(x, y), isLongStringCompare(x, y),
+			isInvisibleStringCompare(x, y):
 			diff := format.UnifiedDiff(format.DiffConfig{A: x.(string), B: y.(string)})
 			return multiLineDiffResult(diff, x, y)
 		}
@@ -129,6 +142,42 @@ func isMultiLineStringCompare(x, y interface{}) bool {
 	return strings.Contains(strX, "\n") || strings.Contains(strY, "\n")
 }
 
+// isInvisibleStringCompare returns true if x and y are strings which only
+// differ by whitespace or invisible characters, so they would look the same
+// in the failure message.
+func isInvisibleStringCompare(x, y interface{}) bool {
+	strX, ok := x.(string)
+	if !ok {
+		return false
+	}
+	strY, ok := y.(string)
+	if !ok {
+		return false
+	}
+	return format.DiffersOnlyByInvisible(strX, strY)
+}
+
+// longStringLength is the length of a single line string which is shown as a
+// diff when inline highlighting is enabled.
+const longStringLength = 80
+
+// isLongStringCompare returns true if x or y is a string longer than
+// longStringLength, and changes within a line are highlighted in diffs.
+func isLongStringCompare(x, y interface{}) bool {
+	strX, ok := x.(string)
+	if !ok {
+		return false
+	}
+	strY, ok := y.(string)
+	if !ok {
+		return false
+	}
+	if opts, _ := format.DefaultDiffOptions(); opts.Inline == format.InlineNone {
+		return false
+	}
+	return len(strX) > longStringLength || len(strY) > longStringLength
+}
+
 func multiLineDiffResult(diff string, x, y interface{}) Result {
 	return ResultFailureTemplate(`
 --- {{ with callArg 0 }}{{ formatNode . }}{{else}}←{{end}}
@@ -214,6 +263,139 @@ func Panics(f func()) Comparison {
 	}
 }
 
+// PanicsWithValue succeeds if f() panics with a value equal to expected. The
+// values are compared using reflect.DeepEqual.
+//
+// If f() panics with a different value the failure message includes the stack
+// of the goroutine at the panic.
+func PanicsWithValue(f func(), expected interface{}) Comparison {
+	return func() Result {
+		value, panicked, stack := recoverPanic(f)
+		switch {
+		case !panicked:
+			return ResultFailure("did not panic")
+		case reflect.DeepEqual(value, expected):
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf("panicked with %#v (%T), expected %#v (%T)\n\n%s",
+			value, value, expected, expected, stack))
+	}
+}
+
+// PanicsMatching succeeds if f() panics with a value whose message matches the
+// regular expression re. The message of an error is the result of its Error
+// method, and the message of other values is formatted with fmt.Sprint.
+//
+// If f() panics with a value which does not match the failure message includes
+// the stack of the goroutine at the panic.
+func PanicsMatching(f func(), re RegexOrPattern) Comparison {
+	return func() Result {
+		var regex *regexp.Regexp
+		switch typed := re.(type) {
+		case *regexp.Regexp:
+			regex = typed
+		case string:
+			var err error
+			if regex, err = regexp.Compile(typed); err != nil {
+				return ResultFailure(err.Error())
+			}
+		default:
+			return ResultFailure(fmt.Sprintf("invalid type %T for regex pattern", re))
+		}
+
+		value, panicked, stack := recoverPanic(f)
+		if !panicked {
+			return ResultFailure("did not panic")
+		}
+		msg := fmt.Sprint(value)
+		if err, ok := value.(error); ok {
+			msg = err.Error()
+		}
+		if regex.MatchString(msg) {
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf("panic message %q does not match regexp %q\n\n%s",
+			msg, regex.String(), stack))
+	}
+}
+
+// recoverPanic calls f and returns the value passed to panic, and the stack
+// from the panic to the call of f. If f calls runtime.Goexit, for example by
+// calling t.FailNow, the goroutine continues to exit and recoverPanic does
+// not return.
+func recoverPanic(f func()) (value interface{}, panicked bool, stack string) {
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		// recover returns nil for a runtime.Goexit, which can not be stopped,
+		// so only a panic returns from recoverPanic.
+		value, panicked = recover(), true
+		stack = panicStack(debug.Stack())
+	}()
+	f()
+	completed = true
+	return nil, false, ""
+}
+
+// panicStack removes the frames of the recover from the start of stack, and
+// the frames of the comparison from the end.
+func panicStack(stack []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	start, end := 0, len(lines)
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "panic(") && start == 0:
+			start = i + 2
+		case strings.HasPrefix(line, "gotest.tools/v3/assert/cmp.recoverPanic("):
+			end = i
+		}
+	}
+	if start >= end {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(append(lines[:1:1], lines[start:end]...), "\n")
+}
+
+// MaxAllocs succeeds if f() makes at most max heap allocations on average.
+// Allocations are counted like testing.AllocsPerRun: f is run 100 times, plus
+// once to warm up, with GOMAXPROCS set to 1.
+//
+// The number of allocations may be higher when the race detector or coverage
+// is enabled, and is only meaningful when no other goroutines are
+// allocating, so MaxAllocs should not be used in parallel tests.
+func MaxAllocs(max int, f func()) Comparison {
+	return func() Result {
+		allocs := allocsPerRun(100, f)
+		if allocs <= uint64(max) {
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf(
+			"expected at most %d allocations per run, got %d", max, allocs))
+	}
+}
+
+// allocsPerRun returns the average number of heap allocations made by each
+// call to f. It is a copy of testing.AllocsPerRun, so that this package does
+// not import testing.
+func allocsPerRun(runs int, f func()) uint64 {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(1))
+
+	// warm up
+	f()
+
+	var memstats runtime.MemStats
+	runtime.ReadMemStats(&memstats)
+	mallocs := 0 - memstats.Mallocs
+	for i := 0; i < runs; i++ {
+		f()
+	}
+	runtime.ReadMemStats(&memstats)
+	mallocs += memstats.Mallocs
+	return mallocs / uint64(runs)
+}
+
 // Error succeeds if err is a non-nil error, and the error message equals the
 // expected message.
 func Error(err error, message string) Comparison {
@@ -401,3 +583,59 @@ func ErrorIs(actual error, expected error) Comparison {
 			map[string]interface{}{"a": actual, "x": expected})
 	}
 }
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrorAs succeeds if errors.As(actual, target) returns true. When it succeeds
+// target is set to the first error in the chain of actual which matches the
+// type of target. target must be a non-nil pointer to a type which implements
+// error, or to an interface type. See https://golang.org/pkg/errors/#As for
+// details.
+//
+// The failure message includes every error in the chain of actual, with its
+// type, and the type of the error that was expected.
+func ErrorAs(actual error, target interface{}) Comparison {
+	return func() Result {
+		if target == nil {
+			return ResultFailure("invalid target: target must be a non-nil pointer, not nil")
+		}
+		value := reflect.ValueOf(target)
+		if value.Kind() != reflect.Ptr || value.IsNil() {
+			return ResultFailure(fmt.Sprintf(
+				"invalid target: target must be a non-nil pointer, not %T", target))
+		}
+		expected := value.Type().Elem()
+		if expected.Kind() != reflect.Interface && !expected.Implements(errorType) {
+			return ResultFailure(fmt.Sprintf(
+				"invalid target: %s does not implement error", expected))
+		}
+
+		switch {
+		case actual == nil:
+			return ResultFailure(fmt.Sprintf("error is nil, not %s", expected))
+		case errors.As(actual, target):
+			return ResultSuccess
+		}
+		msg := fmt.Sprintf("error chain of %q does not contain %s:\n", actual, expected)
+		return ResultFailure(msg + formatErrorChain(actual, "  "))
+	}
+}
+
+// formatErrorChain returns a line with the type and message of err, and of
+// each error wrapped by err.
+func formatErrorChain(err error, indent string) string {
+	out := fmt.Sprintf("%s%T: %v", indent, err, err)
+	switch wrapped := err.(type) {
+	case interface{ Unwrap() error }:
+		if next := wrapped.Unwrap(); next != nil {
+			out += "\n" + formatErrorChain(next, indent)
+		}
+	case interface{ Unwrap() []error }:
+		for _, next := range wrapped.Unwrap() {
+			if next != nil {
+				out += "\n" + formatErrorChain(next, indent+"  ")
+			}
+		}
+	}
+	return out
+}
@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"io"
 	"os"
 	"reflect"
 	"regexp"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -165,6 +167,112 @@ func TestPanics(t *testing.T) {
 	assertFailure(t, result, "did not panic")
 }
 
+func panicWithValue(value interface{}) {
+	panic(value)
+}
+
+func TestPanicsWithValue(t *testing.T) {
+	result := PanicsWithValue(func() { panicWithValue("the value") }, "the value")()
+	assertSuccess(t, result)
+
+	result = PanicsWithValue(func() { panicWithValue([]int{1, 2}) }, []int{1, 2})()
+	assertSuccess(t, result)
+
+	result = PanicsWithValue(func() {}, "the value")()
+	assertFailure(t, result, "did not panic")
+
+	result = PanicsWithValue(func() { panicWithValue("other") }, "the value")()
+	assertFailureHasPrefix(t, result, `panicked with "other" (string), expected "the value" (string)
+
+goroutine `)
+	assertPanicStack(t, result)
+}
+
+func TestPanicsMatching(t *testing.T) {
+	result := PanicsMatching(func() { panicWithValue("index 3 out of range") }, `index \d+`)()
+	assertSuccess(t, result)
+
+	result = PanicsMatching(func() { panicWithValue(errors.New("boom")) }, regexp.MustCompile("^boom$"))()
+	assertSuccess(t, result)
+
+	result = PanicsMatching(func() {}, "boom")()
+	assertFailure(t, result, "did not panic")
+
+	result = PanicsMatching(func() { panicWithValue(3) }, "boom")()
+	assertFailureHasPrefix(t, result, `panic message "3" does not match regexp "boom"
+
+goroutine `)
+	assertPanicStack(t, result)
+
+	result = PanicsMatching(func() {}, "[")()
+	assertFailureHasPrefix(t, result, "error parsing regexp")
+}
+
+func TestPanicsWithValueGoexit(t *testing.T) {
+	for name, comparison := range map[string]Comparison{
+		"PanicsWithValue": PanicsWithValue(runtime.Goexit, nil),
+		"PanicsMatching":  PanicsMatching(runtime.Goexit, ".*"),
+	} {
+		returned := make(chan Result, 1)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			returned <- comparison()
+		}()
+		<-done
+		select {
+		case result := <-returned:
+			t.Errorf("%s: expected Goexit to stop the goroutine, got %#v", name, result)
+		default:
+		}
+	}
+}
+
+// assertPanicStack checks that the stack in the failure message starts at the
+// function which panicked, and does not include the frames of the comparison.
+func assertPanicStack(t *testing.T, result Result) {
+	t.Helper()
+	stack := result.(StringResult).FailureMessage()
+	stack = stack[strings.Index(stack, "goroutine "):]
+	lines := strings.Split(stack, "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[1], "gotest.tools/v3/assert/cmp.panicWithValue(") {
+		t.Fatalf("expected stack to start at the panic, got:\n%s", stack)
+	}
+	if strings.Contains(stack, "recoverPanic") || strings.Contains(stack, "runtime/debug") {
+		t.Fatalf("expected stack to exclude the comparison, got:\n%s", stack)
+	}
+}
+
+var allocSink []byte
+
+func TestMaxAllocs(t *testing.T) {
+	result := MaxAllocs(0, func() {})()
+	assertSuccess(t, result)
+
+	allocate := func() {
+		allocSink = make([]byte, 1024)
+	}
+	result = MaxAllocs(1, allocate)()
+	assertSuccess(t, result)
+
+	result = MaxAllocs(0, allocate)()
+	assertFailure(t, result, "expected at most 0 allocations per run, got 1")
+}
+
+// Importing testing registers the test flags, which breaks programs that use
+// this package outside of tests.
+func TestPackageDoesNotImportTesting(t *testing.T) {
+	pkg, err := build.ImportDir(".", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range pkg.Imports {
+		if path == "testing" {
+			t.Fatal("package imports testing")
+		}
+	}
+}
+
 type innerstub struct {
 	num int
 }
@@ -305,6 +413,57 @@ bbbb`
 	assertFailureTemplate(t, res, args, expected)
 }
 
+func TestEqualLongLineWithInlineDiff(t *testing.T) {
+	defer setenv(t, "GOTESTTOOLS_DIFF_INLINE", "word")()
+
+	result := "the quick brown fox jumps over the lazy dog, and the lazy dog does not move at all"
+	exp := "the quick brown fox jumps over the lazy cat, and the lazy cat does not move at all"
+
+	expected := `
+--- result
++++ exp
+@@ -1 +1 @@
+-the quick brown fox jumps over the lazy [-dog-], and the lazy [-dog-] does not move at all
++the quick brown fox jumps over the lazy {+cat+}, and the lazy {+cat+} does not move at all
+`
+
+	args := []ast.Expr{&ast.Ident{Name: "result"}, &ast.Ident{Name: "exp"}}
+	res := Equal(result, exp)()
+	assertFailureTemplate(t, res, args, expected)
+}
+
+func TestEqualInvisibleDifference(t *testing.T) {
+	result := "foo\u00a0bar"
+	exp := "foo bar"
+
+	expected := `
+--- result
++++ exp
+@@ -1 +1 @@
+-foo⍽bar
++foo·bar
+`
+
+	args := []ast.Expr{&ast.Ident{Name: "result"}, &ast.Ident{Name: "exp"}}
+	res := Equal(result, exp)()
+	assertFailureTemplate(t, res, args, expected)
+}
+
+func setenv(t *testing.T, key, value string) func() {
+	t.Helper()
+	orig, ok := os.LookupEnv(key)
+	if err := os.Setenv(key, value); err != nil {
+		t.Fatal(err)
+	}
+	return func() {
+		if ok {
+			os.Setenv(key, orig)
+			return
+		}
+		os.Unsetenv(key)
+	}
+}
+
 func TestEqual_PointersNotEqual(t *testing.T) {
 	x := 123
 	y := 123
@@ -678,3 +837,55 @@ func TestErrorIs(t *testing.T) {
 		assertFailureTemplate(t, result, args, expected)
 	})
 }
+
+type wrappingError struct {
+	err error
+}
+
+func (e wrappingError) Error() string {
+	return "wrapping: " + e.err.Error()
+}
+
+func (e wrappingError) Unwrap() error {
+	return e.err
+}
+
+func TestErrorAs(t *testing.T) {
+	t.Run("matching type", func(t *testing.T) {
+		var target stubError
+		result := ErrorAs(wrappingError{err: stubError{}}, &target)()
+		assertSuccess(t, result)
+	})
+	t.Run("matching interface", func(t *testing.T) {
+		var target interface{ Unwrap() error }
+		result := ErrorAs(fmt.Errorf("wrapped: %w", stubError{}), &target)()
+		assertSuccess(t, result)
+		if target == nil {
+			t.Fatal("expected target to be set")
+		}
+	})
+	t.Run("actual is nil", func(t *testing.T) {
+		var target stubError
+		result := ErrorAs(nil, &target)()
+		assertFailure(t, result, "error is nil, not cmp.stubError")
+	})
+	t.Run("no match in chain", func(t *testing.T) {
+		var target *os.PathError
+		err := fmt.Errorf("read config: %w", wrappingError{err: notStubError{}})
+		result := ErrorAs(err, &target)()
+		expected := `error chain of "read config: wrapping: not stub error" does not contain *fs.PathError:
+  *fmt.wrapError: read config: wrapping: not stub error
+  cmp.wrappingError: wrapping: not stub error
+  cmp.notStubError: not stub error`
+		assertFailure(t, result, expected)
+	})
+	t.Run("target is not a pointer", func(t *testing.T) {
+		result := ErrorAs(stubError{}, stubError{})()
+		assertFailure(t, result, "invalid target: target must be a non-nil pointer, not cmp.stubError")
+	})
+	t.Run("target does not implement error", func(t *testing.T) {
+		var target string
+		result := ErrorAs(stubError{}, &target)()
+		assertFailure(t, result, "invalid target: string does not implement error")
+	})
+}
@@ -0,0 +1,89 @@
+package cmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ContextDone succeeds if ctx is done, because it was cancelled or its
+// deadline was exceeded.
+//
+// ContextDone does not wait for ctx. Cancellation is propagated to child
+// contexts before cancel returns, so ContextDone can be used immediately after
+// cancelling a parent context.
+func ContextDone(ctx context.Context) Comparison {
+	return func() Result {
+		select {
+		case <-ctx.Done():
+			return ResultSuccess
+		default:
+		}
+		msg := "context is not done"
+		if deadline, ok := ctx.Deadline(); ok {
+			msg += fmt.Sprintf(", deadline in %v", time.Until(deadline).Round(time.Millisecond))
+		}
+		return ResultFailure(msg)
+	}
+}
+
+// ContextNotDone succeeds if ctx is not done, and is still not done after
+// waiting for the within duration. Use a within of 0 to check ctx without
+// waiting.
+func ContextNotDone(ctx context.Context, within time.Duration) Comparison {
+	return func() Result {
+		select {
+		case <-ctx.Done():
+			return ResultFailure(fmt.Sprintf("context is done: %v", contextErr(ctx)))
+		default:
+		}
+		if within <= 0 {
+			return ResultSuccess
+		}
+
+		start := time.Now()
+		timer := time.NewTimer(within)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ResultFailure(fmt.Sprintf("context was done after %v: %v",
+				time.Since(start).Round(time.Millisecond), contextErr(ctx)))
+		case <-timer.C:
+			return ResultSuccess
+		}
+	}
+}
+
+// ContextErr succeeds if ctx is done, and errors.Is(ctx.Err(), expected)
+// returns true. expected is usually context.Canceled or
+// context.DeadlineExceeded.
+func ContextErr(ctx context.Context, expected error) Comparison {
+	return func() Result {
+		err := ctx.Err()
+		switch {
+		case err == nil:
+			return ResultFailure(fmt.Sprintf("context is not done, expected error %q", expected))
+		case errors.Is(err, expected):
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf("context error is %q, not %q", err, expected))
+	}
+}
+
+// ContextDeadline succeeds if ctx has a deadline, and the time remaining until
+// the deadline is between min and max, inclusive.
+func ContextDeadline(ctx context.Context, min, max time.Duration) Comparison {
+	return func() Result {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			return ResultFailure("context has no deadline")
+		}
+		remaining := time.Until(deadline)
+		if remaining >= min && remaining <= max {
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf("context deadline is in %v, expected between %v and %v",
+			remaining.Round(time.Millisecond), min, max))
+	}
+}
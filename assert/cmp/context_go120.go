@@ -0,0 +1,32 @@
+//go:build go1.20
+// +build go1.20
+
+package cmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ContextCause succeeds if ctx is done, and errors.Is(context.Cause(ctx),
+// expected) returns true. Use ContextCause to check the error passed to the
+// cancel function returned by context.WithCancelCause.
+//
+// ContextCause requires Go 1.20+.
+func ContextCause(ctx context.Context, expected error) Comparison {
+	return func() Result {
+		cause := context.Cause(ctx)
+		switch {
+		case cause == nil:
+			return ResultFailure(fmt.Sprintf("context is not done, expected cause %q", expected))
+		case errors.Is(cause, expected):
+			return ResultSuccess
+		}
+		return ResultFailure(fmt.Sprintf("context cause is %q, not %q", cause, expected))
+	}
+}
+
+func contextErr(ctx context.Context) error {
+	return context.Cause(ctx)
+}
@@ -0,0 +1,25 @@
+//go:build go1.20
+// +build go1.20
+
+package cmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestContextCause(t *testing.T) {
+	errShutdown := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	assertFailure(t, ContextCause(ctx, errShutdown)(),
+		`context is not done, expected cause "shutting down"`)
+
+	cancel(fmt.Errorf("server: %w", errShutdown))
+	assertSuccess(t, ContextCause(ctx, errShutdown)())
+	assertSuccess(t, ContextErr(ctx, context.Canceled)())
+	assertFailure(t, ContextCause(ctx, context.DeadlineExceeded)(),
+		`context cause is "server: shutting down", not "context deadline exceeded"`)
+	assertFailure(t, ContextNotDone(ctx, 0)(), "context is done: server: shutting down")
+}
@@ -0,0 +1,60 @@
+package cmp
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assertFailure(t, ContextDone(ctx)(), "context is not done")
+
+	child, childCancel := context.WithCancel(ctx)
+	defer childCancel()
+	cancel()
+	assertSuccess(t, ContextDone(ctx)())
+	assertSuccess(t, ContextDone(child)())
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	assertFailureHasPrefix(t, ContextDone(ctx)(), "context is not done, deadline in ")
+}
+
+func TestContextNotDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assertSuccess(t, ContextNotDone(ctx, 0)())
+	assertSuccess(t, ContextNotDone(ctx, 10*time.Millisecond)())
+
+	cancel()
+	assertFailure(t, ContextNotDone(ctx, 0)(), "context is done: context canceled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assertFailureHasPrefix(t, ContextNotDone(ctx, time.Minute)(), "context was done after ")
+}
+
+func TestContextErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assertFailure(t, ContextErr(ctx, context.Canceled)(),
+		`context is not done, expected error "context canceled"`)
+
+	cancel()
+	assertSuccess(t, ContextErr(ctx, context.Canceled)())
+	assertFailure(t, ContextErr(ctx, context.DeadlineExceeded)(),
+		`context error is "context canceled", not "context deadline exceeded"`)
+
+	ctx, cancel = context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	assertSuccess(t, ContextErr(ctx, context.DeadlineExceeded)())
+}
+
+func TestContextDeadline(t *testing.T) {
+	assertFailure(t, ContextDeadline(context.Background(), 0, time.Second)(),
+		"context has no deadline")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	assertSuccess(t, ContextDeadline(ctx, 50*time.Second, time.Minute)())
+	assertFailureHasPrefix(t, ContextDeadline(ctx, 0, time.Second)(), "context deadline is in ")
+}
@@ -0,0 +1,204 @@
+package cmp
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ElementsMatch succeeds if the slices or arrays x and y contain the same
+// elements, in any order. Each element of x must match a different element of
+// y, so an element which is repeated must be repeated the same number of
+// times in both. Elements are compared using google/go-cmp
+// (https://godoc.org/github.com/google/go-cmp/cmp), so the comparison can be
+// customized with opts. The comparison does not have to be transitive, like
+// cmpopts.EquateApprox: the elements are paired so that as many elements as
+// possible are matched.
+//
+// The failure message lists the elements which are only in x, and the elements
+// which are only in y.
+func ElementsMatch(x, y interface{}, opts ...cmp.Option) Comparison {
+	return func() (result Result) {
+		defer func() {
+			if panicmsg, handled := handleCmpPanic(recover()); handled {
+				result = ResultFailure(panicmsg)
+			}
+		}()
+		xs, err := elements(x)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+		ys, err := elements(y)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+
+		pairs := matchElements(xs, ys, opts)
+		var onlyX []interface{}
+		for i, item := range xs {
+			if pairs.y[i] < 0 {
+				onlyX = append(onlyX, item)
+			}
+		}
+		var onlyY []interface{}
+		for j, item := range ys {
+			if pairs.x[j] < 0 {
+				onlyY = append(onlyY, item)
+			}
+		}
+		if len(onlyX) == 0 && len(onlyY) == 0 {
+			return ResultSuccess
+		}
+		return ResultFailureTemplate(
+			`elements of {{ template "x" $ }} and {{ template "y" $ }} do not match`+`
+{{- with .Data.onlyX }}
+in {{ template "x" $ }} but not {{ template "y" $ }}: {{ printf "%v" . }}
+{{- end }}
+{{- with .Data.onlyY }}
+in {{ template "y" $ }} but not {{ template "x" $ }}: {{ printf "%v" . }}
+{{- end }}`+argNameTemplates,
+			map[string]interface{}{"x": x, "y": y, "onlyX": onlyX, "onlyY": onlyY})
+	}
+}
+
+// SubsetOf succeeds if every element of the slice or array subset is also an
+// element of the slice or array superset. Elements are compared using
+// google/go-cmp (https://godoc.org/github.com/google/go-cmp/cmp), so the
+// comparison can be customized with opts.
+//
+// The failure message lists the elements of subset which are missing from
+// superset.
+func SubsetOf(subset, superset interface{}, opts ...cmp.Option) Comparison {
+	return func() (result Result) {
+		defer func() {
+			if panicmsg, handled := handleCmpPanic(recover()); handled {
+				result = ResultFailure(panicmsg)
+			}
+		}()
+		xs, err := elements(subset)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+		ys, err := elements(superset)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+
+		var missing []interface{}
+		for _, item := range xs {
+			if indexOf(ys, item, opts) < 0 {
+				missing = append(missing, item)
+			}
+		}
+		if len(missing) == 0 {
+			return ResultSuccess
+		}
+		return ResultFailureTemplate(
+			`elements of {{ template "x" $ }} are missing from {{ template "y" $ }}: `+
+				`{{ printf "%v" .Data.missing }}`+argNameTemplates,
+			map[string]interface{}{"x": subset, "y": superset, "missing": missing})
+	}
+}
+
+// argNameTemplates define templates for the source of the first two args of
+// the comparison, or their values when the source is not available.
+const argNameTemplates = `
+{{- define "x" }}{{ with callArg 0 }}{{ formatNode . }}{{ else }}{{ printf "%v" .Data.x }}{{ end }}{{ end }}
+{{- define "y" }}{{ with callArg 1 }}{{ formatNode . }}{{ else }}{{ printf "%v" .Data.y }}{{ end }}{{ end }}`
+
+// elements returns the elements of the slice or array v.
+func elements(v interface{}) ([]interface{}, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return nil, nil
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+	default:
+		return nil, fmt.Errorf("type %T is not a slice or array", v)
+	}
+	items := make([]interface{}, value.Len())
+	for i := range items {
+		items[i] = value.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// indexOf returns the index of the first element of items which is equal to
+// item, or -1 if there is no such element.
+func indexOf(items []interface{}, item interface{}, opts []cmp.Option) int {
+	for i, candidate := range items {
+		if cmp.Equal(item, candidate, opts...) {
+			return i
+		}
+	}
+	return -1
+}
+
+// elementPairs is a matching of the elements of xs and ys. y[i] is the index
+// of the element of ys paired with xs[i], and x[j] is the index of the element
+// of xs paired with ys[j], or -1 if the element is not paired.
+type elementPairs struct {
+	xs, ys []interface{}
+	opts   []cmp.Option
+	x, y   []int
+	// equal caches the comparison of xs[i] and ys[j].
+	equal map[[2]int]bool
+}
+
+// matchElements pairs each element of xs with a different element of ys which
+// is equal to it. The pairs are a maximum bipartite matching, found with
+// augmenting paths, so the greatest number of elements are paired even when
+// the comparison is not transitive.
+func matchElements(xs, ys []interface{}, opts []cmp.Option) *elementPairs {
+	p := &elementPairs{
+		xs:    xs,
+		ys:    ys,
+		opts:  opts,
+		x:     make([]int, len(ys)),
+		y:     make([]int, len(xs)),
+		equal: make(map[[2]int]bool),
+	}
+	for j := range p.x {
+		p.x[j] = -1
+	}
+	for i := range xs {
+		p.y[i] = -1
+		p.augment(i, make([]bool, len(ys)))
+	}
+	return p
+}
+
+// augment pairs xs[i] with an element of ys, either one which is not paired,
+// or one whose pair can be paired with another element. It returns false if
+// there is no such element. visited marks the elements of ys already tried.
+func (p *elementPairs) augment(i int, visited []bool) bool {
+	for j := range p.ys {
+		if p.x[j] < 0 && p.isEqual(i, j) {
+			p.x[j], p.y[i] = i, j
+			return true
+		}
+	}
+	for j := range p.ys {
+		if visited[j] || p.x[j] < 0 || !p.isEqual(i, j) {
+			continue
+		}
+		visited[j] = true
+		if p.augment(p.x[j], visited) {
+			p.x[j], p.y[i] = i, j
+			return true
+		}
+	}
+	return false
+}
+
+func (p *elementPairs) isEqual(i, j int) bool {
+	key := [2]int{i, j}
+	equal, ok := p.equal[key]
+	if !ok {
+		equal = cmp.Equal(p.xs[i], p.ys[j], p.opts...)
+		p.equal[key] = equal
+	}
+	return equal
+}
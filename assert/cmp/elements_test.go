@@ -0,0 +1,81 @@
+package cmp
+
+import (
+	"go/ast"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestElementsMatch(t *testing.T) {
+	t.Run("same elements in a different order", func(t *testing.T) {
+		assertSuccess(t, ElementsMatch([]int{1, 2, 3}, []int{3, 1, 2})())
+	})
+	t.Run("array and slice", func(t *testing.T) {
+		assertSuccess(t, ElementsMatch([2]string{"a", "b"}, []string{"b", "a"})())
+	})
+	t.Run("empty and nil", func(t *testing.T) {
+		assertSuccess(t, ElementsMatch([]int{}, nil)())
+	})
+	t.Run("different elements", func(t *testing.T) {
+		actual, expected := []int{1, 2, 4}, []int{3, 2, 1}
+		args := []ast.Expr{&ast.Ident{Name: "actual"}, &ast.Ident{Name: "expected"}}
+		result := ElementsMatch(actual, expected)()
+		assertFailureTemplate(t, result, args, `elements of actual and expected do not match
+in actual but not expected: [4]
+in expected but not actual: [3]`)
+	})
+	t.Run("repeated elements", func(t *testing.T) {
+		result := ElementsMatch([]int{1, 1, 2}, []int{1, 2})()
+		assertFailureTemplate(t, result, nil, `elements of [1 1 2] and [1 2] do not match
+in [1 1 2] but not [1 2]: [1]`)
+	})
+	t.Run("with options", func(t *testing.T) {
+		ignoreCase := cmp.Comparer(strings.EqualFold)
+		assertSuccess(t, ElementsMatch([]string{"A", "b"}, []string{"B", "a"}, ignoreCase)())
+	})
+	t.Run("comparison which is not transitive", func(t *testing.T) {
+		// greedy pairing would pair 2 with 1, and leave 1 without a pair
+		approx := cmp.Comparer(func(a, b int) bool { return a-b <= 1 && b-a <= 1 })
+		assertSuccess(t, ElementsMatch([]int{2, 1}, []int{1, 3}, approx)())
+
+		result := ElementsMatch([]int{2, 1, 9}, []int{1, 3, 5}, approx)()
+		assertFailureTemplate(t, result, nil, `elements of [2 1 9] and [1 3 5] do not match
+in [2 1 9] but not [1 3 5]: [9]
+in [1 3 5] but not [2 1 9]: [5]`)
+	})
+	t.Run("struct elements", func(t *testing.T) {
+		type item struct{ Name string }
+		assertSuccess(t, ElementsMatch([]item{{"a"}, {"b"}}, []item{{"b"}, {"a"}})())
+	})
+	t.Run("not a slice", func(t *testing.T) {
+		assertFailure(t, ElementsMatch("abc", []int{1})(), "type string is not a slice or array")
+	})
+	t.Run("unexported fields", func(t *testing.T) {
+		result := ElementsMatch([]Stub{{unx: 1}}, []Stub{{unx: 1}})()
+		assertFailureHasPrefix(t, result, `cannot handle unexported field at {cmp.Stub}.unx:`)
+	})
+}
+
+func TestSubsetOf(t *testing.T) {
+	t.Run("subset", func(t *testing.T) {
+		assertSuccess(t, SubsetOf([]int{3, 1}, []int{1, 2, 3})())
+	})
+	t.Run("empty subset", func(t *testing.T) {
+		assertSuccess(t, SubsetOf(nil, []int{1, 2, 3})())
+	})
+	t.Run("missing elements", func(t *testing.T) {
+		sub, all := []int{1, 4, 5}, []int{1, 2, 3}
+		args := []ast.Expr{&ast.Ident{Name: "sub"}, &ast.Ident{Name: "all"}}
+		result := SubsetOf(sub, all)()
+		assertFailureTemplate(t, result, args, `elements of sub are missing from all: [4 5]`)
+	})
+	t.Run("with options", func(t *testing.T) {
+		ignoreCase := cmp.Comparer(strings.EqualFold)
+		assertSuccess(t, SubsetOf([]string{"A"}, []string{"b", "a"}, ignoreCase)())
+	})
+	t.Run("not a slice", func(t *testing.T) {
+		assertFailure(t, SubsetOf([]int{1}, map[int]int{})(), "type map[int]int is not a slice or array")
+	})
+}
@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package cmp
+
+import "github.com/google/go-cmp/cmp"
+
+// EqualT succeeds if x == y. Unlike Equal, x and y must have the same type, so
+// comparing values of different types is a compile error. See assert.EqualT
+// for full documentation.
+func EqualT[T comparable](x, y T) Comparison {
+	return Equal(x, y)
+}
+
+// DeepEqualT compares two values of the same type using google/go-cmp, and
+// succeeds if the values are equal. See DeepEqual for full documentation.
+func DeepEqualT[T any](x, y T, opts ...cmp.Option) Comparison {
+	return DeepEqual(x, y, opts...)
+}
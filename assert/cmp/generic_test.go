@@ -0,0 +1,68 @@
+//go:build go1.18
+// +build go1.18
+
+package cmp
+
+import (
+	"go/ast"
+	"reflect"
+	"testing"
+)
+
+func TestEqualT(t *testing.T) {
+	assertSuccess(t, EqualT(1, 1)())
+
+	actual, expected := "foo", "bar"
+	args := []ast.Expr{&ast.Ident{Name: "actual"}, &ast.Ident{Name: "expected"}}
+	result := EqualT(actual, expected)()
+	assertFailureTemplate(t, result, args, "foo (actual string) != bar (expected string)")
+}
+
+func TestDeepEqualT(t *testing.T) {
+	assertSuccess(t, DeepEqualT([]int{1, 2}, []int{1, 2})())
+
+	result := DeepEqualT([]int{1, 2}, []int{1, 3})()
+	if result.Success() {
+		t.Fatal("expected failure")
+	}
+}
+
+// TestGenericSignatures checks that the generic comparisons stay in sync with
+// the comparisons used with Go versions before 1.18.
+func TestGenericSignatures(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		generic  interface{}
+		fallback interface{}
+	}{
+		{name: "EqualT", generic: EqualT[int], fallback: Equal},
+		{name: "DeepEqualT", generic: DeepEqualT[int], fallback: DeepEqual},
+	}
+	for _, tc := range testcases {
+		actual, expected := genericSignature(tc.generic), reflect.TypeOf(tc.fallback)
+		if actual != expected {
+			t.Errorf("%s has signature %s, expected %s", tc.name, actual, expected)
+		}
+	}
+}
+
+// genericSignature returns the type of the function fn, which is a generic
+// function instantiated with int, with every int parameter replaced by
+// interface{}. It is the signature of the non-generic function which fn is
+// like.
+func genericSignature(fn interface{}) reflect.Type {
+	typeParam := reflect.TypeOf(0)
+	anyType := reflect.TypeOf((*interface{})(nil)).Elem()
+	fnType := reflect.TypeOf(fn)
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		if in[i] = fnType.In(i); in[i] == typeParam {
+			in[i] = anyType
+		}
+	}
+	out := make([]reflect.Type, fnType.NumOut())
+	for i := range out {
+		out[i] = fnType.Out(i)
+	}
+	return reflect.FuncOf(in, out, fnType.IsVariadic())
+}
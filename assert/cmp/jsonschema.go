@@ -0,0 +1,49 @@
+package cmp
+
+import (
+	"fmt"
+	"strings"
+
+	"gotest.tools/v3/internal/jsonschema"
+)
+
+// JSONSchema succeeds if doc is valid according to the JSON Schema. Both
+// schema and doc may be a string or []byte of JSON, or any other value which
+// is converted to JSON with json.Marshal.
+//
+// The assertion keywords of JSON Schema draft 7 and 2020-12 are supported,
+// along with $ref to a location in the same schema, such as
+// "#/$defs/address". References to other documents are not supported, and
+// "format" is not validated.
+//
+// The failure message lists every constraint which is violated, and the JSON
+// pointer to the value which violates it.
+func JSONSchema(schema, doc interface{}) Comparison {
+	return func() Result {
+		decodedSchema, err := jsonschema.Decode(schema)
+		if err != nil {
+			return ResultFailure(fmt.Sprintf("invalid JSON schema: %s", err))
+		}
+		decodedDoc, err := jsonschema.Decode(doc)
+		if err != nil {
+			return ResultFailure(fmt.Sprintf("invalid JSON document: %s", err))
+		}
+		violations, err := jsonschema.Validate(decodedSchema, decodedDoc)
+		if err != nil {
+			return ResultFailure(fmt.Sprintf("invalid JSON schema: %s", err))
+		}
+		if len(violations) == 0 {
+			return ResultSuccess
+		}
+		lines := make([]string, 0, len(violations))
+		for _, violation := range violations {
+			lines = append(lines, "  "+violation.String())
+		}
+		noun := "violations"
+		if len(violations) == 1 {
+			noun = "violation"
+		}
+		return ResultFailure(fmt.Sprintf("document does not match the JSON schema, %d %s:\n%s",
+			len(violations), noun, strings.Join(lines, "\n")))
+	}
+}
@@ -0,0 +1,53 @@
+package cmp
+
+import "testing"
+
+const userSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "email"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"email": {"type": "string", "pattern": "^[^@]+@[^@]+$"},
+		"age": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true}
+	},
+	"additionalProperties": false
+}`
+
+func TestJSONSchema(t *testing.T) {
+	doc := map[string]interface{}{
+		"name":  "Ada",
+		"email": "ada@example.com",
+		"age":   36,
+		"tags":  []string{"admin"},
+	}
+	assertSuccess(t, JSONSchema(userSchema, doc)())
+	assertSuccess(t, JSONSchema([]byte(userSchema), `{"name": "Ada", "email": "a@b"}`)())
+}
+
+func TestJSONSchema_Failure(t *testing.T) {
+	doc := `{"name": "", "age": -1.5, "tags": ["a", 2, "a"], "admin": true}`
+	result := JSONSchema(userSchema, doc)()
+	expected := `document does not match the JSON schema, 7 violations:
+  (root): missing required property "email"
+  /admin: property "admin" is not allowed
+  /age: expected integer, got number
+  /age: -1.5 is less than the minimum 0
+  /name: expected at least 1 characters, got 0
+  /tags: items 0 and 2 are equal
+  /tags/1: expected string, got integer`
+	assertFailure(t, result, expected)
+}
+
+func TestJSONSchema_OneViolation(t *testing.T) {
+	result := JSONSchema(`{"type": "string"}`, `1`)()
+	assertFailure(t, result, "document does not match the JSON schema, 1 violation:\n  (root): expected string, got integer")
+}
+
+func TestJSONSchema_InvalidInput(t *testing.T) {
+	assertFailureHasPrefix(t, JSONSchema(`{`, `{}`)(), "invalid JSON schema: ")
+	assertFailureHasPrefix(t, JSONSchema(`{}`, `{`)(), "invalid JSON document: ")
+	assertFailure(t, JSONSchema(`{"$ref": "#/$defs/missing"}`, `{}`)(),
+		`invalid JSON schema: unresolved $ref "#/$defs/missing"`)
+}
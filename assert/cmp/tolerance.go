@@ -0,0 +1,81 @@
+package cmp
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Approximately succeeds if the numbers x and y differ by no more than
+// epsilon. x and y may be any integer or floating point type, and may have
+// different types. NaN is not approximately equal to any value.
+//
+// The failure message includes the difference between x and y.
+func Approximately(x, y interface{}, epsilon float64) Comparison {
+	return func() Result {
+		fx, err := toFloat(x)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+		fy, err := toFloat(y)
+		if err != nil {
+			return ResultFailure(err.Error())
+		}
+		delta := math.Abs(fx - fy)
+		if delta <= epsilon {
+			return ResultSuccess
+		}
+		return ResultFailureTemplate(`
+			{{- printf "%v" .Data.x}} (
+				{{- with callArg 0 }}{{ formatNode . }} {{end -}}
+				{{- printf "%T" .Data.x -}}
+			) is not within {{ .Data.epsilon }} of {{ printf "%v" .Data.y}} (
+				{{- with callArg 1 }}{{ formatNode . }} {{end -}}
+				{{- printf "%T" .Data.y -}}
+			), the difference is {{ .Data.delta }}`,
+			map[string]interface{}{"x": x, "y": y, "epsilon": epsilon, "delta": delta})
+	}
+}
+
+func toFloat(v interface{}) (float64, error) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return float64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	default:
+		return 0, fmt.Errorf("type %T is not a number", v)
+	}
+}
+
+// WithinDuration succeeds if the times x and y differ by no more than delta.
+//
+// The failure message includes the difference between x and y.
+func WithinDuration(x, y time.Time, delta time.Duration) Comparison {
+	return func() Result {
+		diff := x.Sub(y)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= delta {
+			return ResultSuccess
+		}
+		return ResultFailureTemplate(`
+			{{- .Data.formattedX }} {{ with callArg 0 }}({{ formatNode . }}) {{end -}}
+			and {{ .Data.formattedY }} {{ with callArg 1 }}({{ formatNode . }}) {{end -}}
+			differ by {{ .Data.diff }}, which is more than {{ .Data.delta }}`,
+			map[string]interface{}{
+				"x":          x,
+				"y":          y,
+				"formattedX": x.Format(time.RFC3339Nano),
+				"formattedY": y.Format(time.RFC3339Nano),
+				"diff":       diff,
+				"delta":      delta,
+			})
+	}
+}
@@ -0,0 +1,54 @@
+package cmp
+
+import (
+	"go/ast"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestApproximately(t *testing.T) {
+	t.Run("within epsilon", func(t *testing.T) {
+		assertSuccess(t, Approximately(1.0, 1.05, 0.1)())
+		assertSuccess(t, Approximately(10, 10.5, 0.5)())
+		assertSuccess(t, Approximately(uint8(3), int64(3), 0)())
+		assertSuccess(t, Approximately(float32(0.1), 0.1, 1e-6)())
+	})
+
+	t.Run("not within epsilon", func(t *testing.T) {
+		actual, expected := 1.5, 1.25
+		args := []ast.Expr{&ast.Ident{Name: "actual"}, &ast.Ident{Name: "expected"}}
+		result := Approximately(actual, expected, 0.1)()
+		assertFailureTemplate(t, result, args,
+			"1.5 (actual float64) is not within 0.1 of 1.25 (expected float64), the difference is 0.25")
+	})
+
+	t.Run("NaN", func(t *testing.T) {
+		result := Approximately(math.NaN(), 1, 0.1)()
+		assertFailureTemplate(t, result, nil,
+			"NaN (float64) is not within 0.1 of 1 (int), the difference is NaN")
+	})
+
+	t.Run("not a number", func(t *testing.T) {
+		assertFailure(t, Approximately("1", 1, 0.1)(), "type string is not a number")
+		assertFailure(t, Approximately(1, nil, 0.1)(), "type <nil> is not a number")
+	})
+}
+
+func TestWithinDuration(t *testing.T) {
+	start := time.Date(2021, 3, 4, 10, 20, 30, 0, time.UTC)
+
+	t.Run("within delta", func(t *testing.T) {
+		assertSuccess(t, WithinDuration(start, start.Add(time.Second), time.Second)())
+		assertSuccess(t, WithinDuration(start.Add(time.Second), start, time.Second)())
+	})
+
+	t.Run("not within delta", func(t *testing.T) {
+		actual := start.Add(-1500 * time.Millisecond)
+		args := []ast.Expr{&ast.Ident{Name: "actual"}, &ast.Ident{Name: "start"}}
+		result := WithinDuration(actual, start, time.Second)()
+		assertFailureTemplate(t, result, args,
+			"2021-03-04T10:20:28.5Z (actual) and 2021-03-04T10:20:30Z (start) "+
+				"differ by 1.5s, which is more than 1s")
+	})
+}
@@ -0,0 +1,120 @@
+package assert
+
+import (
+	"runtime"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/assert"
+	"gotest.tools/v3/internal/overflow"
+)
+
+// Collector runs many checks in a test, and reports all of their failures
+// together in a single summary, instead of logging each failure when the check
+// fails. Failures are grouped by the line of the check, so a check which fails
+// many times in a loop is reported once, with each of its failure messages.
+//
+// Use NewCollector to create a Collector. The methods of a Collector may be
+// called from any goroutine.
+type Collector struct {
+	t      TestingT
+	buffer *assert.Buffer
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// NewCollector returns a Collector which reports its failures to t. If t
+// implements Cleanup, like testing.T, any failures which were not reported
+// by Report when the test ends are logged, and the test is marked as failed.
+//
+//	c := assert.NewCollector(t)
+//	for _, item := range items {
+//		c.Check(item.Valid(), "item %v", item.Name)
+//		c.Equal(item.Owner, "admin")
+//	}
+//	c.Report()
+func NewCollector(t TestingT) *Collector {
+	c := &Collector{t: t, buffer: new(assert.Buffer)}
+	if ct, ok := t.(cleanupT); ok {
+		ct.Cleanup(func() {
+			if c.report() {
+				t.Fail()
+			}
+		})
+	}
+	return c
+}
+
+// Check performs a comparison like Check, and records the failure message if
+// the comparison fails. Check returns true if the comparison is successful.
+func (c *Collector) Check(comparison BoolOrComparison, msgAndArgs ...interface{}) bool {
+	record := new(assert.Record)
+	if assert.Eval(record, assert.ArgsAtZeroIndex, comparison, msgAndArgs...) {
+		return true
+	}
+	c.add(record)
+	return false
+}
+
+// NilError records a failure if err is not nil, like NilError.
+func (c *Collector) NilError(err error, msgAndArgs ...interface{}) bool {
+	record := new(assert.Record)
+	if assert.Eval(record, assert.ArgsAll, err, msgAndArgs...) {
+		return true
+	}
+	c.add(record)
+	return false
+}
+
+// Equal records a failure if x != y, like Equal.
+func (c *Collector) Equal(x, y interface{}, msgAndArgs ...interface{}) bool {
+	record := new(assert.Record)
+	if assert.Eval(record, assert.ArgsAll, cmp.Equal(x, y), msgAndArgs...) {
+		return true
+	}
+	c.add(record)
+	return false
+}
+
+// DeepEqual records a failure if x and y are not equal, like DeepEqual.
+func (c *Collector) DeepEqual(x, y interface{}, opts ...gocmp.Option) bool {
+	record := new(assert.Record)
+	if assert.Eval(record, assert.ArgsAll, cmp.DeepEqual(x, y, opts...)) {
+		return true
+	}
+	c.add(record)
+	return false
+}
+
+func (c *Collector) add(record *assert.Record) {
+	const stackIndex = 2 // Check/Equal/..., add
+	_, file, line, _ := runtime.Caller(stackIndex)
+	c.buffer.Add(file, line, record)
+}
+
+// Report logs a summary of the failures recorded since the last call to
+// Report, and fails the test immediately with t.FailNow if there were any
+// failures. Like t.FailNow, Report must be called from the goroutine running
+// the test function.
+func (c *Collector) Report() {
+	if ht, ok := c.t.(helperT); ok {
+		ht.Helper()
+	}
+	if c.report() {
+		c.t.FailNow()
+	}
+}
+
+func (c *Collector) report() bool {
+	if ht, ok := c.t.(helperT); ok {
+		ht.Helper()
+	}
+	summary, count := c.buffer.Flush()
+	if count == 0 {
+		return false
+	}
+	c.t.Log(overflow.Limit(c.t, summary))
+	return true
+}
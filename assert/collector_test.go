@@ -0,0 +1,98 @@
+package assert
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert/cmp"
+)
+
+type fakeCleanupT struct {
+	fakeTestingT
+	cleanups []func()
+}
+
+func (f *fakeCleanupT) Cleanup(fn func()) {
+	f.cleanups = append(f.cleanups, fn)
+}
+
+func TestCollector(t *testing.T) {
+	fakeT := &fakeTestingT{}
+	c := NewCollector(fakeT)
+
+	ok := true
+	count := 3
+	passed := c.Check(ok)
+	Assert(t, passed)
+	passed = c.Check(!ok)
+	Assert(t, !passed)
+	passed = c.Check(cmp.Len("a", count), "with %v", "message")
+	Assert(t, !passed)
+	passed = c.NilError(errors.New("oops"))
+	Assert(t, !passed)
+	passed = c.Equal(count, 3)
+	Assert(t, passed)
+	passed = c.DeepEqual([]int{1}, []int{2})
+	Assert(t, !passed)
+	Assert(t, !fakeT.failed && !fakeT.failNowed)
+	Assert(t, len(fakeT.msgs) == 0, "failures should not be logged before Report")
+
+	c.Report()
+	Assert(t, fakeT.failNowed)
+	Assert(t, len(fakeT.msgs) == 1)
+	msg := fakeT.msgs[0]
+	Assert(t, cmp.Contains(msg, "4 assertions failed at 4 locations:\n"))
+	Assert(t, cmp.Contains(msg, "collector_test.go:"))
+	Assert(t, cmp.Contains(msg, "    assertion failed: ok is true\n"))
+	Assert(t, cmp.Contains(msg,
+		"    assertion failed: expected a (length 1) to have length 3: with message\n"))
+	Assert(t, cmp.Contains(msg, "    assertion failed: error is not nil: oops\n"))
+	Assert(t, cmp.Contains(msg, "    assertion failed: \n    --- ←\n    +++ →\n"))
+}
+
+func TestCollectorGroupsFailuresByLine(t *testing.T) {
+	fakeT := &fakeTestingT{}
+	c := NewCollector(fakeT)
+
+	for i := 0; i < 3; i++ {
+		c.Equal(i, 5)
+	}
+	c.Report()
+	Assert(t, fakeT.failNowed)
+	lines := strings.Split(fakeT.msgs[0], "\n")
+	Equal(t, len(lines), 5)
+	Equal(t, lines[0], "3 assertions failed:")
+	Assert(t, cmp.Regexp(`^collector_test.go:\d+ \(3 failures\):$`, lines[1]))
+	Equal(t, lines[2], "    assertion failed: 0 (i int) != 5 (int)")
+	Equal(t, lines[4], "    assertion failed: 2 (i int) != 5 (int)")
+}
+
+func TestCollectorReportWithoutFailures(t *testing.T) {
+	fakeT := &fakeTestingT{}
+	c := NewCollector(fakeT)
+	c.Check(true)
+	c.Report()
+	Assert(t, !fakeT.failNowed)
+	Assert(t, len(fakeT.msgs) == 0)
+}
+
+func TestCollectorReportsFailuresOnCleanup(t *testing.T) {
+	fakeT := &fakeCleanupT{}
+	c := NewCollector(fakeT)
+	Equal(t, len(fakeT.cleanups), 1)
+
+	c.Equal(1, 2)
+	c.Report()
+	Assert(t, fakeT.failNowed)
+	Equal(t, len(fakeT.msgs), 1)
+
+	fakeT.cleanups[0]()
+	Assert(t, !fakeT.failed, "failures were already reported")
+
+	c.Equal(1, 3)
+	fakeT.cleanups[0]()
+	Assert(t, fakeT.failed)
+	Equal(t, len(fakeT.msgs), 2)
+	Assert(t, cmp.Contains(fakeT.msgs[1], "1 assertion failed:\n"))
+}
@@ -24,3 +24,14 @@ func ExampleAssert_customComparison() {
 	}
 	assert.Assert(t, regexPattern("12345.34", `\d+.\d\d`))
 }
+
+func ExampleNewCollector() {
+	items := map[string]int{"one": 1, "two": 2}
+
+	c := assert.NewCollector(t)
+	for name, value := range items {
+		c.Check(value > 0, "item %v", name)
+		c.Equal(len(name), 3)
+	}
+	c.Report()
+}
@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+package assert
+
+import (
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/assert"
+)
+
+// EqualT is like Equal, but x and y must have the same type, so that
+// comparing values of different types is a compile error instead of a test
+// failure.
+//
+//	assert.EqualT(t, count, 3)
+//	assert.EqualT(t, count, "3") // does not compile
+//
+// EqualT uses t.FailNow to fail the test. Like t.FailNow, EqualT must be
+// called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.EqualT from other
+// goroutines.
+func EqualT[T comparable](t TestingT, x, y T, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.EqualT(x, y), msgAndArgs...) {
+		t.FailNow()
+	}
+}
+
+// DeepEqualT is like DeepEqual, but x and y must have the same type.
+//
+// DeepEqualT uses t.FailNow to fail the test. Like t.FailNow, DeepEqualT must
+// be called from the goroutine running the test function, not from other
+// goroutines created during the test. Use Check with cmp.DeepEqualT from other
+// goroutines.
+func DeepEqualT[T any](t TestingT, x, y T, opts ...gocmp.Option) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !assert.Eval(t, assert.ArgsAfterT, cmp.DeepEqualT(x, y, opts...)) {
+		t.FailNow()
+	}
+}
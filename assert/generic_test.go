@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+package assert
+
+import (
+	"reflect"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+func TestEqualTSuccess(t *testing.T) {
+	fakeT := &fakeTestingT{}
+
+	EqualT(fakeT, 1, 1)
+	expectSuccess(t, fakeT)
+
+	EqualT(fakeT, "abcd", "abcd")
+	expectSuccess(t, fakeT)
+}
+
+func TestEqualTFailure(t *testing.T) {
+	fakeT := &fakeTestingT{}
+
+	actual, expected := 1, 3
+	EqualT(fakeT, actual, expected)
+	expectFailNowed(t, fakeT, "assertion failed: 1 (actual int) != 3 (expected int)")
+}
+
+func TestEqualTFailureWithTypeArgument(t *testing.T) {
+	fakeT := &fakeTestingT{}
+
+	actual := uint8(1)
+	EqualT[uint8](fakeT, actual, 3)
+	expectFailNowed(t, fakeT, "assertion failed: 1 (actual uint8) != 3 (uint8)")
+}
+
+func TestDeepEqualTSuccess(t *testing.T) {
+	actual := stub{"ok", 1}
+	expected := stub{"ok", 1}
+
+	fakeT := &fakeTestingT{}
+	DeepEqualT(fakeT, actual, expected, gocmp.AllowUnexported(stub{}))
+	expectSuccess(t, fakeT)
+}
+
+func TestDeepEqualTFailure(t *testing.T) {
+	actual := []string{"a", "b"}
+	expected := []string{"a", "c"}
+
+	fakeT := &fakeTestingT{}
+	DeepEqualT(fakeT, actual, expected)
+	if !fakeT.failNowed {
+		t.Fatal("should have failNowed")
+	}
+}
+
+// TestGenericSignatures checks that the generic assertions stay in sync with
+// the assertions used with Go versions before 1.18.
+func TestGenericSignatures(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		generic  interface{}
+		fallback interface{}
+	}{
+		{name: "EqualT", generic: EqualT[int], fallback: Equal},
+		{name: "DeepEqualT", generic: DeepEqualT[int], fallback: DeepEqual},
+	}
+	for _, tc := range testcases {
+		actual, expected := genericSignature(tc.generic), reflect.TypeOf(tc.fallback)
+		if actual != expected {
+			t.Errorf("%s has signature %s, expected %s", tc.name, actual, expected)
+		}
+	}
+}
+
+// genericSignature returns the type of the function fn, which is a generic
+// function instantiated with int, with every int parameter replaced by
+// interface{}. It is the signature of the non-generic function which fn is
+// like.
+func genericSignature(fn interface{}) reflect.Type {
+	typeParam := reflect.TypeOf(0)
+	anyType := reflect.TypeOf((*interface{})(nil)).Elem()
+	fnType := reflect.TypeOf(fn)
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		if in[i] = fnType.In(i); in[i] == typeParam {
+			in[i] = anyType
+		}
+	}
+	out := make([]reflect.Type, fnType.NumOut())
+	for i := range out {
+		out[i] = fnType.Out(i)
+	}
+	return reflect.FuncOf(in, out, fnType.IsVariadic())
+}
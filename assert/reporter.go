@@ -0,0 +1,105 @@
+package assert
+
+import (
+	"gotest.tools/v3/internal/assert"
+)
+
+// LogT is the subset of testing.T used by a Reporter.
+type LogT interface {
+	Log(args ...interface{})
+}
+
+// Failure is a failed assertion, passed to a Reporter.
+type Failure struct {
+	// Message describes why the comparison failed, for example with a diff
+	// of the values. It does not include the "assertion failed: " prefix.
+	Message string
+	// Annotation is the message passed to the assertion with msgAndArgs, or
+	// an empty string.
+	Annotation string
+	// File and Line are the position of the first caller outside of the
+	// gotest.tools packages, which is usually the assertion in the test. File
+	// is empty if the position is not available.
+	File string
+	Line int
+}
+
+// String returns the failure message in the format logged by TextReporter.
+func (f Failure) String() string {
+	return assert.Failure(f).Text()
+}
+
+// Reporter logs the failure message of an assertion. Use SetReporter to
+// change how the failures of every assertion are logged, for example to emit
+// failures in a format read by CI tools.
+//
+// The failures of the golden, fs, icmd, and poll packages are also logged by
+// the Reporter.
+type Reporter interface {
+	Report(t LogT, failure Failure)
+}
+
+// ReporterFunc is a function which implements Reporter.
+type ReporterFunc func(t LogT, failure Failure)
+
+// Report calls f(t, failure).
+func (f ReporterFunc) Report(t LogT, failure Failure) {
+	f(t, failure)
+}
+
+var (
+	// TextReporter logs the failure message with t.Log. It is the default
+	// Reporter. Messages larger than GOTESTTOOLS_MAX_FAILURE_SIZE are
+	// truncated, see Large failure messages.
+	TextReporter Reporter = ReporterFunc(func(t LogT, failure Failure) {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		assert.ReportText(t, assert.Failure(failure))
+	})
+	// ColorReporter is like TextReporter, but highlights the lines of diffs
+	// in the message with ANSI escape codes, for reading the output in a
+	// terminal.
+	ColorReporter Reporter = ReporterFunc(func(t LogT, failure Failure) {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		assert.ReportColor(t, assert.Failure(failure))
+	})
+	// JSONReporter logs each failure with t.Log as a JSON object on a single
+	// line, with the fields message, annotation, file, and line.
+	JSONReporter Reporter = ReporterFunc(func(t LogT, failure Failure) {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		assert.ReportJSON(t, assert.Failure(failure))
+	})
+)
+
+// SetReporter sets the Reporter used to log the failures of all assertions,
+// and returns a function which restores the previous Reporter. A nil reporter
+// restores the default, which is selected by the GOTESTTOOLS_FAILURE_FORMAT
+// environment variable.
+//
+// The Reporter is shared by every test in the test binary, so it is usually
+// set in TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		assert.SetReporter(assert.JSONReporter)
+//		os.Exit(m.Run())
+//	}
+func SetReporter(reporter Reporter) func() {
+	var report assert.ReportFunc
+	if reporter != nil {
+		report = func(t assert.LogT, failure assert.Failure) {
+			if ht, ok := t.(helperT); ok {
+				ht.Helper()
+			}
+			reporter.Report(t, Failure(failure))
+		}
+	}
+	previous := assert.SetReporter(report)
+	return func() {
+		assert.SetReporter(previous)
+	}
+}
@@ -0,0 +1,140 @@
+package assert
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/internal/assert"
+)
+
+type recordReporter struct {
+	failures []Failure
+}
+
+func (r *recordReporter) Report(_ LogT, failure Failure) {
+	r.failures = append(r.failures, failure)
+}
+
+func TestSetReporter(t *testing.T) {
+	reporter := &recordReporter{}
+	restore := SetReporter(reporter)
+
+	fakeT := &fakeTestingT{}
+	Equal(fakeT, 1, 2, "with %s", "context")
+	restore()
+
+	if !fakeT.failNowed {
+		t.Fatal("should have failNowed")
+	}
+	if len(fakeT.msgs) != 0 {
+		t.Fatalf("expected no log messages, got %v", fakeT.msgs)
+	}
+	if len(reporter.failures) != 1 {
+		t.Fatalf("expected 1 failure, got %v", reporter.failures)
+	}
+	failure := reporter.failures[0]
+	expected := Failure{
+		Message:    "1 (int) != 2 (int)",
+		Annotation: "with context",
+		File:       failure.File,
+		Line:       failure.Line,
+	}
+	if failure != expected {
+		t.Fatalf("expected %#v, got %#v", expected, failure)
+	}
+	if filepath.Base(failure.File) != "reporter_test.go" || failure.Line == 0 {
+		t.Fatalf("expected the position of the assertion, got %s:%d", failure.File, failure.Line)
+	}
+	if actual := failure.String(); actual != "assertion failed: 1 (int) != 2 (int): with context" {
+		t.Fatalf("unexpected string: %s", actual)
+	}
+
+	fakeT = &fakeTestingT{}
+	Equal(fakeT, 1, 2)
+	expectFailNowed(t, fakeT, "assertion failed: 1 (int) != 2 (int)")
+}
+
+func TestSetReporterWithCollector(t *testing.T) {
+	reporter := &recordReporter{}
+	defer SetReporter(reporter)()
+
+	fakeT := &fakeTestingT{}
+	c := NewCollector(fakeT)
+	c.Equal(1, 2)
+	c.Report()
+
+	if len(reporter.failures) != 0 {
+		t.Fatalf("expected the reporter to be skipped, got %v", reporter.failures)
+	}
+	Equal(t, len(fakeT.msgs), 1)
+	Assert(t, strings.HasSuffix(fakeT.msgs[0], "\n    assertion failed: 1 (int) != 2 (int)"))
+}
+
+func TestJSONReporter(t *testing.T) {
+	defer SetReporter(JSONReporter)()
+
+	fakeT := &fakeTestingT{}
+	Check(fakeT, false, "extra")
+	if len(fakeT.msgs) != 1 {
+		t.Fatalf("expected 1 log message, got %v", fakeT.msgs)
+	}
+
+	var failure map[string]interface{}
+	if err := json.Unmarshal([]byte(fakeT.msgs[0]), &failure); err != nil {
+		t.Fatalf("failed to decode %s: %s", fakeT.msgs[0], err)
+	}
+	if failure["message"] != "false is false" || failure["annotation"] != "extra" {
+		t.Fatalf("unexpected failure: %v", failure)
+	}
+	if file, _ := failure["file"].(string); filepath.Base(file) != "reporter_test.go" {
+		t.Fatalf("unexpected file: %v", failure["file"])
+	}
+}
+
+func TestColorReporter(t *testing.T) {
+	defer SetReporter(ColorReporter)()
+
+	fakeT := &fakeTestingT{}
+	Equal(fakeT, "one\ntwo\n", "one\nthree\n")
+	expected := "assertion failed: \n" +
+		"\x1b[1m--- ←\x1b[0m\n" +
+		"\x1b[1m+++ →\x1b[0m\n" +
+		"\x1b[36m@@ -1,3 +1,3 @@\x1b[0m\n" +
+		" one\n" +
+		"\x1b[31m-two\x1b[0m\n" +
+		"\x1b[32m+three\x1b[0m\n" +
+		" \n"
+	expectFailNowed(t, fakeT, expected)
+}
+
+func TestFailureFormatEnvVar(t *testing.T) {
+	orig, exists := os.LookupEnv(assert.FormatEnvVar)
+	defer func() {
+		if exists {
+			os.Setenv(assert.FormatEnvVar, orig)
+			return
+		}
+		os.Unsetenv(assert.FormatEnvVar)
+	}()
+
+	os.Setenv(assert.FormatEnvVar, "json")
+	fakeT := &fakeTestingT{}
+	Check(fakeT, false)
+	if len(fakeT.msgs) != 1 || !strings.HasPrefix(fakeT.msgs[0], `{"message":"false is false"`) {
+		t.Fatalf("expected a JSON failure, got %v", fakeT.msgs)
+	}
+
+	os.Setenv(assert.FormatEnvVar, "yaml")
+	fakeT = &fakeTestingT{}
+	Check(fakeT, false)
+	expected := []string{
+		"assertion failed: false is false",
+		`invalid GOTESTTOOLS_FAILURE_FORMAT: "yaml", expected text, color, or json`,
+	}
+	if strings.Join(fakeT.msgs, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected messages: %q", fakeT.msgs)
+	}
+}
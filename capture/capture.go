@@ -0,0 +1,197 @@
+/*
+Package capture provides helpers to capture the output written to os.Stdout,
+os.Stderr, the standard log package, and log/slog during a test.
+
+Each helper replaces the global writer and returns a Capture. The original
+writer is restored when Capture.Stop is called, or when the test ends.
+
+Since the writers are global, only one Capture of each stream may be active at
+a time. A helper called while another Capture of the same stream is active
+blocks until that Capture is stopped, which makes the helpers safe to use from
+parallel tests. Calling the same helper twice from one test without stopping
+the first Capture blocks forever.
+*/
+package capture // import "gotest.tools/v3/capture"
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/assert"
+)
+
+var (
+	stdoutMu sync.Mutex
+	stderrMu sync.Mutex
+	// logMu guards both the standard log package and log/slog, because
+	// setting the default slog.Logger also redirects the log package.
+	logMu sync.Mutex
+)
+
+type helperT interface {
+	Helper()
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// implemented by gotest.tools/x/subtest.TestContext
+type addCleanupT interface {
+	AddCleanup(f func())
+}
+
+// Capture is the output captured from a stream.
+type Capture struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	once    sync.Once
+	restore func()
+}
+
+// Write appends p to the captured output.
+func (c *Capture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+// Stop restores the original writer and returns all of the captured output.
+// Stop may be called more than once, and is called automatically when the
+// test ends.
+func (c *Capture) Stop() string {
+	c.once.Do(c.restore)
+	return c.String()
+}
+
+// String returns the output captured so far. Output written to os.Stdout or
+// os.Stderr may not be visible until Stop is called.
+func (c *Capture) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.String()
+}
+
+// Lines returns the output captured so far, split into lines.
+func (c *Capture) Lines() []string {
+	return splitLines(c.String())
+}
+
+func newCapture(t assert.TestingT, mu *sync.Mutex, start func(c *Capture) (func(), error)) *Capture {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	mu.Lock()
+	c := &Capture{}
+	restore, err := start(c)
+	if err != nil {
+		mu.Unlock()
+		assert.NilError(t, err)
+	}
+	c.restore = func() {
+		defer mu.Unlock()
+		restore()
+	}
+	registerCleanup(t, func() {
+		c.Stop()
+	})
+	return c
+}
+
+func registerCleanup(t assert.TestingT, f func()) {
+	switch typed := t.(type) {
+	case cleanupT:
+		typed.Cleanup(f)
+	case addCleanupT:
+		typed.AddCleanup(f)
+	}
+}
+
+// Stdout replaces os.Stdout with a pipe, and captures everything written to
+// it until the Capture is stopped.
+//
+// Only writes which use the os.Stdout variable are captured. Output from
+// child processes, or from code which writes directly to file descriptor 1,
+// is not captured.
+func Stdout(t assert.TestingT) *Capture {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return newCapture(t, &stdoutMu, func(c *Capture) (func(), error) {
+		return capturePipe(&os.Stdout, c)
+	})
+}
+
+// Stderr replaces os.Stderr with a pipe, and captures everything written to
+// it until the Capture is stopped. See Stdout for the limitations.
+func Stderr(t assert.TestingT) *Capture {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return newCapture(t, &stderrMu, func(c *Capture) (func(), error) {
+		return capturePipe(&os.Stderr, c)
+	})
+}
+
+func capturePipe(file **os.File, c *Capture) (func(), error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = io.Copy(c, r)
+	}()
+
+	orig := *file
+	*file = w
+	return func() {
+		*file = orig
+		_ = w.Close()
+		<-done
+		_ = r.Close()
+	}, nil
+}
+
+// Log captures everything written by the standard log package until the
+// Capture is stopped. The flags of the log package are set to 0 while the
+// output is captured, so that the captured lines do not include a timestamp.
+func Log(t assert.TestingT) *Capture {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return newCapture(t, &logMu, func(c *Capture) (func(), error) {
+		return captureLog(c), nil
+	})
+}
+
+func captureLog(w io.Writer) func() {
+	restore := saveLog()
+	log.SetOutput(w)
+	log.SetFlags(0)
+	return restore
+}
+
+func saveLog() func() {
+	output, flags := log.Writer(), log.Flags()
+	return func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}
+}
+
+func splitLines(output string) []string {
+	if output == "" {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
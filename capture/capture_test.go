@@ -0,0 +1,102 @@
+package capture
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestStdout(t *testing.T) {
+	orig := os.Stdout
+	c := Stdout(t)
+	assert.Assert(t, os.Stdout != orig)
+
+	fmt.Println("first line")
+	fmt.Fprintln(os.Stdout, "second line")
+
+	out := c.Stop()
+	assert.Equal(t, os.Stdout, orig)
+	assert.Equal(t, out, "first line\nsecond line\n")
+	assert.DeepEqual(t, c.Lines(), []string{"first line", "second line"})
+
+	// Stop can be called again
+	assert.Equal(t, c.Stop(), out)
+}
+
+func TestStderr(t *testing.T) {
+	orig := os.Stderr
+	c := Stderr(t)
+	fmt.Fprint(os.Stderr, "an error")
+	assert.Equal(t, c.Stop(), "an error")
+	assert.Equal(t, os.Stderr, orig)
+}
+
+func TestStdout_RestoredAtCleanup(t *testing.T) {
+	orig := os.Stdout
+	t.Run("capture", func(t *testing.T) {
+		Stdout(t)
+		fmt.Println("not visible")
+	})
+	assert.Equal(t, os.Stdout, orig)
+}
+
+func TestStdout_Parallel(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		i := i
+		t.Run(fmt.Sprintf("case-%d", i), func(t *testing.T) {
+			t.Parallel()
+			c := Stdout(t)
+			fmt.Printf("case %d\n", i)
+			assert.Equal(t, c.Stop(), fmt.Sprintf("case %d\n", i))
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	origOutput, origFlags := log.Writer(), log.Flags()
+	c := Log(t)
+	log.Print("one")
+	log.Printf("two %d", 2)
+
+	assert.Equal(t, c.String(), "one\ntwo 2\n")
+	c.Stop()
+	assert.Equal(t, log.Writer(), origOutput)
+	assert.Equal(t, log.Flags(), origFlags)
+}
+
+func TestContainsLines(t *testing.T) {
+	output := "starting\nlistening on :8080\nstopping\n"
+
+	assert.Assert(t, ContainsLines(output, "stopping", "listening"))
+	assert.Assert(t, ContainsLines(output))
+
+	result := ContainsLines(output, "starting", "starting", "missing")()
+	assert.Assert(t, !result.Success())
+	expected := `missing lines: ["starting" "missing"]
+
+output:
+starting
+listening on :8080
+stopping
+`
+	assert.Equal(t, failureMessage(result), expected)
+}
+
+func TestLinesInOrder(t *testing.T) {
+	output := "starting\nlistening on :8080\nstopping\n"
+
+	assert.Assert(t, LinesInOrder(output, "starting", "stopping"))
+
+	result := LinesInOrder(output, "stopping", "listening")()
+	assert.Assert(t, !result.Success())
+	assert.Assert(t, is.Contains(failureMessage(result),
+		`line "listening" not found in order`))
+}
+
+func failureMessage(result is.Result) string {
+	return result.(interface{ FailureMessage() string }).FailureMessage()
+}
@@ -0,0 +1,58 @@
+package capture
+
+import (
+	"fmt"
+	"strings"
+
+	"gotest.tools/v3/assert/cmp"
+)
+
+// ContainsLines succeeds if every one of lines is contained in a line of
+// output. The lines may appear in any order, and each line of output may
+// match only one of lines.
+func ContainsLines(output string, lines ...string) cmp.Comparison {
+	return func() cmp.Result {
+		remaining := splitLines(output)
+		var missing []string
+		for _, line := range lines {
+			index := indexOfLineContaining(remaining, line)
+			if index == -1 {
+				missing = append(missing, line)
+				continue
+			}
+			remaining = append(remaining[:index:index], remaining[index+1:]...)
+		}
+		if len(missing) == 0 {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf(
+			"missing lines: %q\n\noutput:\n%s", missing, output))
+	}
+}
+
+// LinesInOrder succeeds if every one of lines is contained in a line of
+// output, and the lines appear in the same order as lines. Other lines of
+// output may appear between the matched lines.
+func LinesInOrder(output string, lines ...string) cmp.Comparison {
+	return func() cmp.Result {
+		remaining := splitLines(output)
+		for _, line := range lines {
+			index := indexOfLineContaining(remaining, line)
+			if index == -1 {
+				return cmp.ResultFailure(fmt.Sprintf(
+					"line %q not found in order\n\noutput:\n%s", line, output))
+			}
+			remaining = remaining[index+1:]
+		}
+		return cmp.ResultSuccess
+	}
+}
+
+func indexOfLineContaining(lines []string, substr string) int {
+	for i, line := range lines {
+		if strings.Contains(line, substr) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,44 @@
+//go:build go1.21
+// +build go1.21
+
+package capture
+
+import (
+	"log/slog"
+
+	"gotest.tools/v3/assert"
+)
+
+// Slog replaces the default slog.Logger with a logger which writes to the
+// Capture, and captures all records until the Capture is stopped.
+//
+// Records are formatted with slog.TextHandler, and include records at
+// slog.LevelDebug and above. The time attribute is omitted so that the output is deterministic.
+// Since slog.SetDefault also redirects the standard log package, output from
+// the log package is captured as well.
+//
+// Slog requires Go 1.21+.
+func Slog(t assert.TestingT) *Capture {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return newCapture(t, &logMu, func(c *Capture) (func(), error) {
+		// slog.SetDefault changes the output and flags of the log package,
+		// and does not reset them when the original logger is restored.
+		restoreLog := saveLog()
+		orig := slog.Default()
+		slog.SetDefault(slog.New(slog.NewTextHandler(c, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+			ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+				if len(groups) == 0 && attr.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return attr
+			},
+		})))
+		return func() {
+			slog.SetDefault(orig)
+			restoreLog()
+		}, nil
+	})
+}
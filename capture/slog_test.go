@@ -0,0 +1,37 @@
+//go:build go1.21
+// +build go1.21
+
+package capture
+
+import (
+	"log"
+	"log/slog"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSlog(t *testing.T) {
+	origLogger := slog.Default()
+	origOutput, origFlags := log.Writer(), log.Flags()
+
+	c := Slog(t)
+	slog.Info("started", "port", 8080)
+	slog.Debug("details")
+	slog.Warn("slow request")
+
+	out := c.Stop()
+	assert.Assert(t, LinesInOrder(out,
+		"level=INFO msg=started port=8080",
+		"level=DEBUG msg=details",
+		"level=WARN msg=\"slow request\""))
+	assert.Assert(t, slog.Default() == origLogger)
+	assert.Equal(t, log.Writer(), origOutput)
+	assert.Equal(t, log.Flags(), origFlags)
+}
+
+func TestSlog_CapturesLogPackage(t *testing.T) {
+	c := Slog(t)
+	log.Print("from the log package")
+	assert.Assert(t, ContainsLines(c.Stop(), `level=INFO msg="from the log package"`))
+}
@@ -0,0 +1,115 @@
+//go:build go1.18
+// +build go1.18
+
+package chantest
+
+import (
+	"fmt"
+	"time"
+)
+
+// TestingT is the subset of testing.T used by the functions in this package.
+type TestingT interface {
+	Fatalf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Receive returns the next value received from ch. The test fails if no value
+// is received within timeout, or if ch is closed.
+func Receive[T any](t TestingT, ch <-chan T, timeout time.Duration) T {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			t.Fatalf("channel (%T) was closed, expected a value", ch)
+		}
+		return v
+	case <-timer.C:
+		t.Fatalf("no value received from channel (%T) within %v", ch, timeout)
+	}
+	var zero T
+	return zero
+}
+
+// NoReceive fails the test if a value is received from ch, or ch is closed,
+// before within has passed.
+func NoReceive[T any](t TestingT, ch <-chan T, within time.Duration) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	timer := time.NewTimer(within)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			t.Fatalf("channel (%T) was closed, expected no value within %v", ch, within)
+			return
+		}
+		t.Fatalf("received %s from channel (%T), expected no value within %v",
+			formatValue(v), ch, within)
+	case <-timer.C:
+	}
+}
+
+// Collect receives n values from ch and returns them in the order they were
+// received. The test fails if the n values are not received within timeout,
+// or if ch is closed before n values are received. The failure message
+// includes the values which were received.
+func Collect[T any](t TestingT, ch <-chan T, n int, timeout time.Duration) []T {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	values := make([]T, 0, n)
+	for len(values) < n {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel (%T) was closed after %d of %d values: %v",
+					ch, len(values), n, values)
+				return values
+			}
+			values = append(values, v)
+		case <-timer.C:
+			t.Fatalf("received %d of %d values from channel (%T) within %v: %v",
+				len(values), n, ch, timeout, values)
+			return values
+		}
+	}
+	return values
+}
+
+// Closed fails the test if ch is not closed within timeout, or if a value is
+// received from ch before it is closed. Use Collect to receive any remaining
+// values before calling Closed.
+func Closed[T any](t TestingT, ch <-chan T, timeout time.Duration) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if ok {
+			t.Fatalf("received %s from channel (%T), expected it to be closed",
+				formatValue(v), ch)
+		}
+	case <-timer.C:
+		t.Fatalf("channel (%T) was not closed within %v", ch, timeout)
+	}
+}
+
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,103 @@
+//go:build go1.18
+// +build go1.18
+
+package chantest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+type fakeT struct {
+	failed bool
+	msg    string
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+	t.msg = fmt.Sprintf(format, args...)
+}
+
+func TestReceive(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 3
+	assert.Equal(t, Receive(t, ch, time.Second), 3)
+
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		ch <- 4
+	}()
+	assert.Equal(t, Receive(t, ch, time.Second), 4)
+
+	ft := &fakeT{}
+	Receive(ft, ch, time.Millisecond)
+	assert.Equal(t, ft.msg, "no value received from channel (<-chan int) within 1ms")
+
+	close(ch)
+	ft = &fakeT{}
+	Receive(ft, ch, time.Second)
+	assert.Equal(t, ft.msg, "channel (<-chan int) was closed, expected a value")
+}
+
+func TestNoReceive(t *testing.T) {
+	ch := make(chan string, 1)
+	ft := &fakeT{}
+	NoReceive(ft, ch, time.Millisecond)
+	assert.Assert(t, !ft.failed)
+
+	ch <- "hello"
+	NoReceive(ft, ch, time.Second)
+	assert.Equal(t, ft.msg,
+		`received "hello" from channel (<-chan string), expected no value within 1s`)
+
+	close(ch)
+	ft = &fakeT{}
+	NoReceive(ft, ch, time.Second)
+	assert.Equal(t, ft.msg, "channel (<-chan string) was closed, expected no value within 1s")
+}
+
+func TestCollect(t *testing.T) {
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < 3; i++ {
+			ch <- i
+		}
+	}()
+	assert.DeepEqual(t, Collect(t, ch, 3, time.Second), []int{0, 1, 2})
+
+	buffered := make(chan int, 2)
+	buffered <- 7
+	ft := &fakeT{}
+	values := Collect(ft, buffered, 2, 5*time.Millisecond)
+	assert.DeepEqual(t, values, []int{7})
+	assert.Equal(t, ft.msg, "received 1 of 2 values from channel (<-chan int) within 5ms: [7]")
+
+	buffered <- 8
+	close(buffered)
+	ft = &fakeT{}
+	Collect(ft, buffered, 2, time.Second)
+	assert.Equal(t, ft.msg, "channel (<-chan int) was closed after 1 of 2 values: [8]")
+}
+
+func TestClosed(t *testing.T) {
+	ch := make(chan struct{})
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(ch)
+	}()
+	Closed(t, ch, time.Second)
+
+	ft := &fakeT{}
+	Closed(ft, make(chan error), time.Millisecond)
+	assert.Equal(t, ft.msg, "channel (<-chan error) was not closed within 1ms")
+
+	values := make(chan error, 1)
+	values <- fmt.Errorf("boom")
+	ft = &fakeT{}
+	Closed(ft, values, time.Second)
+	assert.Assert(t, strings.HasPrefix(ft.msg, "received boom from channel"), ft.msg)
+}
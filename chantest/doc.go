@@ -0,0 +1,21 @@
+/*
+Package chantest provides helpers for receiving from channels in tests, with
+a timeout, and with a failure message which says what was expected from the
+channel.
+
+The helpers replace select statements like:
+
+	select {
+	case v := <-ch:
+		// use v
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+	}
+
+with:
+
+	v := chantest.Receive(t, ch, time.Second)
+
+The helpers use generics, and require Go 1.18+.
+*/
+package chantest // import "gotest.tools/v3/chantest"
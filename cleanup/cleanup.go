@@ -0,0 +1,143 @@
+/*
+Package cleanup provides functions to register cleanup functions which depend on
+the result of a test, or which must run in a fixed order.
+*/
+package cleanup // import "gotest.tools/v3/cleanup"
+
+import (
+	"fmt"
+	"sync"
+
+	"gotest.tools/v3/internal/cleanup"
+)
+
+// TestingT is the subset of testing.T used by the functions in this package.
+type TestingT interface {
+	Log(args ...interface{})
+	Failed() bool
+}
+
+type helperT interface {
+	Helper()
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// implemented by gotest.tools/x/subtest.TestContext
+type addCleanupT interface {
+	AddCleanup(f func())
+}
+
+// OnSuccess registers fn to be called when the test ends, only if the test
+// passed. Use it to remove resources which are useful to debug a failure,
+// like the temporary files created by the test.
+//
+// OnSuccess requires Go 1.14+, and fn is not called if the TEST_NOCLEANUP env
+// var is set to true.
+func OnSuccess(t TestingT, fn func()) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	cleanup.Cleanup(t, func() {
+		if !t.Failed() {
+			fn()
+		}
+	})
+}
+
+// OnFailure registers fn to be called when the test ends, only if the test
+// failed. Use it to log extra details about a failure, like the logs of a
+// server used by the test.
+//
+// OnFailure requires Go 1.14+. Unlike OnSuccess fn is called even if the
+// TEST_NOCLEANUP env var is set to true.
+func OnFailure(t TestingT, fn func()) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	register(t, func() {
+		if t.Failed() {
+			fn()
+		}
+	})
+}
+
+func register(t TestingT, f func()) {
+	switch typed := t.(type) {
+	case cleanupT:
+		typed.Cleanup(f)
+	case addCleanupT:
+		typed.AddCleanup(f)
+	}
+}
+
+// Groups is a set of named groups of cleanup functions. When the test ends
+// the groups run in the order they were named in NewGroups, and the functions
+// in each group run in the reverse of the order they were added, like the
+// functions passed to t.Cleanup.
+type Groups struct {
+	mu     sync.Mutex
+	names  []string
+	groups map[string][]func()
+	done   bool
+}
+
+// NewGroups returns Groups with the names, which run when the test ends. For
+// example, to stop containers before removing the network they use:
+//
+//	groups := cleanup.NewGroups(t, "containers", "networks")
+//	groups.Add("networks", removeNetwork)
+//	groups.Add("containers", stopContainer)
+//
+// When used with Go 1.14+ the groups run automatically when the test ends,
+// unless the TEST_NOCLEANUP env var is set to true. Otherwise call Run.
+func NewGroups(t TestingT, names ...string) *Groups {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	g := &Groups{names: names, groups: make(map[string][]func(), len(names))}
+	for _, name := range names {
+		g.groups[name] = nil
+	}
+	cleanup.Cleanup(t, g.Run)
+	return g
+}
+
+// Add fn to the group with name. Add panics if there is no group with name.
+func (g *Groups) Add(name string, fn func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fns, ok := g.groups[name]
+	if !ok {
+		panic(fmt.Sprintf("cleanup group %q does not exist", name))
+	}
+	g.groups[name] = append(fns, fn)
+}
+
+// Run the cleanup functions of every group. Every function is called even if
+// one of them panics or calls t.FailNow. Run only calls the functions once,
+// and later calls do nothing.
+func (g *Groups) Run() {
+	g.mu.Lock()
+	if g.done {
+		g.mu.Unlock()
+		return
+	}
+	g.done = true
+	var ordered []func()
+	for _, name := range g.names {
+		fns := g.groups[name]
+		for i := len(fns) - 1; i >= 0; i-- {
+			ordered = append(ordered, fns[i])
+		}
+	}
+	g.mu.Unlock()
+
+	// Defer all cleanup functions so they all run even if one calls
+	// t.FailNow() or panics.
+	for i := len(ordered) - 1; i >= 0; i-- {
+		defer ordered[i]()
+	}
+}
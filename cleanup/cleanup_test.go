@@ -0,0 +1,92 @@
+package cleanup
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type fakeT struct {
+	failed   bool
+	cleanups []func()
+}
+
+func (t *fakeT) Log(...interface{}) {}
+
+func (t *fakeT) Failed() bool {
+	return t.failed
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestOnSuccess(t *testing.T) {
+	for _, failed := range []bool{false, true} {
+		ft := &fakeT{}
+		called := false
+		OnSuccess(ft, func() { called = true })
+		ft.failed = failed
+		ft.runCleanups()
+		assert.Equal(t, called, !failed)
+	}
+}
+
+func TestOnFailure(t *testing.T) {
+	for _, failed := range []bool{false, true} {
+		ft := &fakeT{}
+		called := false
+		OnFailure(ft, func() { called = true })
+		ft.failed = failed
+		ft.runCleanups()
+		assert.Equal(t, called, failed)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	ft := &fakeT{}
+	var events []string
+	record := func(event string) func() {
+		return func() { events = append(events, event) }
+	}
+
+	groups := NewGroups(ft, "first", "second")
+	groups.Add("second", record("second 1"))
+	groups.Add("first", record("first 1"))
+	groups.Add("second", record("second 2"))
+	groups.Add("first", record("first 2"))
+
+	ft.runCleanups()
+	groups.Run()
+	assert.DeepEqual(t, events, []string{"first 2", "first 1", "second 2", "second 1"})
+}
+
+func TestGroupsRunAllAfterPanic(t *testing.T) {
+	var events []string
+	groups := NewGroups(&fakeT{}, "first", "second")
+	groups.Add("first", func() { panic("boom") })
+	groups.Add("second", func() { events = append(events, "second") })
+
+	func() {
+		defer func() {
+			assert.Equal(t, recover(), "boom")
+		}()
+		groups.Run()
+	}()
+	assert.DeepEqual(t, events, []string{"second"})
+}
+
+func TestGroupsAddUnknownGroup(t *testing.T) {
+	groups := NewGroups(&fakeT{}, "first")
+	assert.Assert(t, func() (panicked bool) {
+		defer func() { panicked = recover() != nil }()
+		groups.Add("other", func() {})
+		return false
+	}())
+}
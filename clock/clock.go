@@ -0,0 +1,90 @@
+/*
+Package clock provides a Clock interface for code which depends on time, and a
+Fake implementation which only moves when the test advances it.
+
+Code which uses a Clock, instead of the functions in the time package, can be
+tested without real sleeps. The Clock is compatible with poll.Clock, so a Fake
+can be passed to poll.WithClock, and with icmd.WithClock.
+*/
+package clock // import "gotest.tools/v3/clock"
+
+import "time"
+
+// Clock provides the current time, and timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After returns a channel which receives the current time after d.
+	After(d time.Duration) <-chan time.Time
+	// NewTimer returns a Timer which fires after d.
+	NewTimer(d time.Duration) Timer
+	// NewTicker returns a Ticker which fires every d.
+	NewTicker(d time.Duration) Ticker
+	// Sleep blocks for d.
+	Sleep(d time.Duration)
+}
+
+// Timer is a single event, like time.Timer.
+type Timer interface {
+	// C returns the channel which receives the time when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. It returns false if the timer
+	// already fired or was stopped.
+	Stop() bool
+	// Reset changes the timer to fire after d. It returns true if the timer
+	// was active.
+	Reset(d time.Duration) bool
+}
+
+// Ticker delivers ticks at intervals, like time.Ticker.
+type Ticker interface {
+	// C returns the channel which receives the ticks.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+	// Reset stops the ticker, and resets its period to d.
+	Reset(d time.Duration)
+}
+
+// Real returns a Clock which uses the functions of the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
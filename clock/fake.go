@@ -0,0 +1,220 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake is a Clock which only moves when it is advanced. The zero value is not
+// usable, use NewFake to create a Fake.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+// waiter is a timer or ticker which has not yet fired.
+type waiter struct {
+	deadline time.Time
+	// period is the interval of a ticker, or 0 for a timer
+	period time.Duration
+	ch     chan time.Time
+}
+
+var _ Clock = &Fake{}
+
+// NewFake returns a Fake set to start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{now: start, changed: make(chan struct{})}
+}
+
+// Now returns the current time of the clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After returns a channel which receives the time once the clock has been
+// advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer returns a Timer which fires once the clock has been advanced by at
+// least d.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTimer{clock: f, w: &waiter{ch: make(chan time.Time, 1)}}
+	f.schedule(t.w, d)
+	return t
+}
+
+// NewTicker returns a Ticker which fires every time the clock is advanced past
+// a multiple of d. Like time.Ticker, ticks are dropped if the receiver does not
+// keep up. NewTicker panics if d is not positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t := &fakeTicker{clock: f, w: &waiter{period: d, ch: make(chan time.Time, 1)}}
+	f.schedule(t.w, d)
+	return t
+}
+
+// Sleep blocks until the clock has been advanced by at least d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// schedule adds w to fire after d. f.mu must be held.
+func (f *Fake) schedule(w *waiter, d time.Duration) {
+	w.deadline = f.now.Add(d)
+	if d <= 0 && w.period == 0 {
+		select {
+		case w.ch <- f.now:
+		default:
+		}
+		return
+	}
+	f.waiters = append(f.waiters, w)
+	f.notify()
+}
+
+// remove w from the waiters, and returns true if it was waiting. f.mu must be
+// held.
+func (f *Fake) remove(w *waiter) bool {
+	for i, other := range f.waiters {
+		if other == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, and fires every timer and ticker with
+// a deadline at or before the new time, in deadline order.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	target := f.now.Add(d)
+	for {
+		next := f.next(target)
+		if next == nil {
+			break
+		}
+		f.now = next.deadline
+		select {
+		case next.ch <- f.now:
+		default:
+			// the receiver did not keep up with a ticker
+		}
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			f.remove(next)
+		}
+	}
+	f.now = target
+	f.notify()
+}
+
+// next returns the waiter with the earliest deadline at or before target. f.mu
+// must be held.
+func (f *Fake) next(target time.Time) *waiter {
+	var next *waiter
+	for _, w := range f.waiters {
+		if w.deadline.After(target) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+// Waiters returns the number of timers and tickers which are waiting for the
+// clock to be advanced.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until there are at least n timers and tickers waiting for
+// the clock to be advanced. Use it before Advance to make sure the code under
+// test is waiting.
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= n {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// notify wakes any callers of BlockUntil. f.mu must be held.
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+type fakeTimer struct {
+	clock *Fake
+	w     *waiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t.w)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.remove(t.w)
+	t.clock.schedule(t.w, d)
+	return active
+}
+
+type fakeTicker struct {
+	clock *Fake
+	w     *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.w.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.w)
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.remove(t.w)
+	t.w.period = d
+	t.clock.schedule(t.w, d)
+}
@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeAfter(t *testing.T) {
+	clock := NewFake(epoch)
+	short := clock.After(time.Second)
+	long := clock.After(time.Minute)
+	assert.Equal(t, clock.Waiters(), 2)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, <-short, epoch.Add(time.Second))
+	assert.Equal(t, clock.Waiters(), 1)
+	assertNotFired(t, long)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, <-long, epoch.Add(time.Minute))
+	assert.Equal(t, clock.Now(), epoch.Add(time.Minute))
+	assert.Equal(t, <-clock.After(0), epoch.Add(time.Minute))
+}
+
+func assertNotFired(t *testing.T, ch <-chan time.Time) {
+	t.Helper()
+	select {
+	case <-ch:
+		t.Fatal("fired before its deadline")
+	default:
+	}
+}
+
+func TestFakeTimerStopAndReset(t *testing.T) {
+	clock := NewFake(epoch)
+	timer := clock.NewTimer(time.Second)
+	assert.Assert(t, timer.Stop())
+	assert.Assert(t, !timer.Stop())
+	clock.Advance(time.Second)
+	assertNotFired(t, timer.C())
+
+	assert.Assert(t, !timer.Reset(time.Second))
+	assert.Assert(t, timer.Reset(2*time.Second))
+	clock.Advance(time.Second)
+	assertNotFired(t, timer.C())
+	clock.Advance(time.Second)
+	assert.Equal(t, <-timer.C(), epoch.Add(3*time.Second))
+	assert.Equal(t, clock.Waiters(), 0)
+}
+
+func TestFakeTicker(t *testing.T) {
+	clock := NewFake(epoch)
+	ticker := clock.NewTicker(10 * time.Second)
+
+	clock.Advance(15 * time.Second)
+	assert.Equal(t, <-ticker.C(), epoch.Add(10*time.Second))
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, <-ticker.C(), epoch.Add(20*time.Second))
+
+	// ticks are dropped when the receiver does not keep up
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, <-ticker.C(), epoch.Add(30*time.Second))
+	assertNotFired(t, ticker.C())
+
+	ticker.Reset(time.Minute)
+	clock.Advance(59 * time.Second)
+	assertNotFired(t, ticker.C())
+	clock.Advance(time.Second)
+	assert.Equal(t, <-ticker.C(), epoch.Add(110*time.Second))
+
+	ticker.Stop()
+	assert.Equal(t, clock.Waiters(), 0)
+	assert.Assert(t, cmp.Panics(func() { clock.NewTicker(0) }))
+}
+
+func TestFakeSleep(t *testing.T) {
+	clock := NewFake(epoch)
+	done := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Hour)
+		close(done)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-done
+}
+
+func TestReal(t *testing.T) {
+	clock := Real()
+	start := clock.Now()
+	clock.Sleep(time.Millisecond)
+	<-clock.After(time.Millisecond)
+	timer := clock.NewTimer(time.Millisecond)
+	<-timer.C()
+	ticker := clock.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+	assert.Assert(t, clock.Now().Sub(start) >= 3*time.Millisecond)
+}
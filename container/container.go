@@ -0,0 +1,309 @@
+/*
+Package container starts Docker or Podman containers for the duration of a
+test.
+
+The containers are managed with the docker or podman command line tool, so
+the package does not depend on a client library. Run skips the test when no
+container runtime is available, waits for the container to be ready, and
+removes the container when the test ends. The logs of the container are
+written to the artifacts directory of the test, see artifacts.Dir.
+*/
+package container // import "gotest.tools/v3/container"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotest.tools/v3/artifacts"
+	"gotest.tools/v3/icmd"
+	"gotest.tools/v3/poll"
+)
+
+// RuntimeEnvVar is the name of an environment variable which sets the
+// container runtime binary. When it is not set docker is used if it is found
+// in PATH, otherwise podman.
+const RuntimeEnvVar = "GOTESTTOOLS_CONTAINER_RUNTIME"
+
+// RemoveTimeout is the time allowed for the runtime to remove a container when
+// the test ends.
+var RemoveTimeout = 30 * time.Second
+
+// TestingT is the subset of testing.T used by Run.
+type TestingT interface {
+	Name() string
+	Log(args ...interface{})
+	Logf(format string, args ...interface{})
+	Fail()
+	FailNow()
+	Fatalf(format string, args ...interface{})
+	Skip(args ...interface{})
+	Cleanup(f func())
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Config describes the container started by Run.
+type Config struct {
+	// Image is the name of the image. It is pulled by the runtime if it is
+	// not available locally.
+	Image string
+	// Command overrides the default command of the image.
+	Command []string
+	// Env sets environment variables in the container.
+	Env map[string]string
+	// Ports are the container ports which are published on a random port of
+	// the loopback interface, in the form "port" or "port/protocol". The
+	// protocol defaults to tcp. See Container.Address.
+	Ports []string
+	// Mounts are bind mounts of host paths into the container.
+	Mounts []Mount
+	// Args are extra flags passed to the run command of the runtime, before
+	// the image name.
+	Args []string
+	// Ready is used to wait until the container is ready. Defaults to waiting
+	// until the container is running.
+	Ready Readiness
+	// PollOps configure the wait for Ready, see poll.WaitOn.
+	PollOps []poll.SettingOp
+}
+
+// Mount is a bind mount of a host path into a container.
+type Mount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+func (m Mount) String() string {
+	if m.ReadOnly {
+		return m.Source + ":" + m.Target + ":ro"
+	}
+	return m.Source + ":" + m.Target
+}
+
+// Container is a running container started by Run.
+type Container struct {
+	// ID of the container.
+	ID string
+	// Runtime is the binary used to manage the container.
+	Runtime string
+
+	ports      map[string]string
+	logs       *icmd.Result
+	removeOnce sync.Once
+}
+
+// Run starts a container, waits until it is ready, and returns it. The test
+// is skipped if no container runtime is available, and fails if the container
+// can not be started, exits, or is not ready before the poll timeout.
+//
+// When the test ends the container is removed, along with its anonymous
+// volumes. Removal happens even if the TEST_NOCLEANUP env var is set.
+func Run(t TestingT, config Config) *Container {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	runtime, err := detectRuntime()
+	if err != nil {
+		t.Skip("container runtime is not available: " + err.Error())
+	}
+
+	result := icmd.RunCmd(icmd.Command(runtime, runArgs(t, config)...))
+	if result.Error != nil {
+		t.Fatalf("failed to start container %s: %s", config.Image, result)
+	}
+	c := &Container{
+		ID:      strings.TrimSpace(result.Stdout()),
+		Runtime: runtime,
+		ports:   make(map[string]string, len(config.Ports)),
+	}
+
+	n := atomic.AddInt32(&containerCount, 1)
+	logFile := artifacts.Create(t, fmt.Sprintf("container-%s-%d.log", imageName(config.Image), n))
+	c.logs = icmd.StartCmd(icmd.Cmd{
+		Command: []string{runtime, "logs", "--follow", c.ID},
+		Stdout:  logFile,
+		Stderr:  logFile,
+	})
+	t.Cleanup(c.Remove)
+
+	for _, port := range config.Ports {
+		address, err := c.lookupPort(port)
+		if err != nil {
+			t.Fatalf("failed to find the address of port %s of container %s: %s", port, c.ID, err)
+		}
+		c.ports[normalizePort(port)] = address
+	}
+
+	ready := config.Ready
+	if ready == nil {
+		ready = ReadyWhenRunning()
+	}
+	check := ready(c)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if running, err := c.running(); err != nil || !running {
+			return poll.Error(&containerExitedError{container: c, err: err})
+		}
+		return check(t)
+	}, config.PollOps...)
+	return c
+}
+
+// containerCount is used to give the logs of each container a unique name
+var containerCount int32
+
+func runArgs(t TestingT, config Config) []string {
+	args := []string{"run", "--detach", "--label", "gotest.tools.test=" + t.Name()}
+
+	keys := make([]string, 0, len(config.Env))
+	for key := range config.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "--env", key+"="+config.Env[key])
+	}
+	for _, port := range config.Ports {
+		args = append(args, "--publish", "127.0.0.1::"+normalizePort(port))
+	}
+	for _, mount := range config.Mounts {
+		args = append(args, "--volume", mount.String())
+	}
+	args = append(args, config.Args...)
+	args = append(args, config.Image)
+	return append(args, config.Command...)
+}
+
+// imageName returns the name of image without the registry, tag, or digest.
+func imageName(image string) string {
+	name := path.Base(image)
+	if i := strings.IndexAny(name, ":@"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+func normalizePort(port string) string {
+	if strings.Contains(port, "/") {
+		return port
+	}
+	return port + "/tcp"
+}
+
+func (c *Container) lookupPort(port string) (string, error) {
+	result := icmd.RunCommand(c.Runtime, "port", c.ID, normalizePort(port))
+	if result.Error != nil {
+		return "", errors.New(strings.TrimSpace(result.Combined()))
+	}
+	// The runtime may print one address for each interface.
+	lines := strings.Split(strings.TrimSpace(result.Stdout()), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return "", errors.New("port is not published")
+	}
+	return strings.TrimSpace(lines[0]), nil
+}
+
+func (c *Container) running() (bool, error) {
+	result := icmd.RunCommand(c.Runtime, "inspect", "--format", "{{.State.Running}}", c.ID)
+	if result.Error != nil {
+		return false, errors.New(strings.TrimSpace(result.Combined()))
+	}
+	return strings.TrimSpace(result.Stdout()) == "true", nil
+}
+
+type containerExitedError struct {
+	container *Container
+	err       error
+}
+
+func (e *containerExitedError) Error() string {
+	msg := "container exited before it was ready"
+	if e.err != nil {
+		msg = "failed to inspect container: " + e.err.Error()
+	}
+	return fmt.Sprintf("%s\nlogs:\n%s", msg, e.container.Logs())
+}
+
+// Address returns the host address, in the form "127.0.0.1:port", of a port
+// from Config.Ports. It panics if port was not in Config.Ports.
+func (c *Container) Address(port string) string {
+	address, ok := c.ports[normalizePort(port)]
+	if !ok {
+		panic(fmt.Sprintf("port %s is not published by container %s", port, c.ID))
+	}
+	return address
+}
+
+// Logs returns the output of the container so far.
+func (c *Container) Logs() string {
+	return c.logs.Combined()
+}
+
+// Exec runs command in the container, and returns the result.
+func (c *Container) Exec(command ...string) *icmd.Result {
+	return icmd.RunCommand(c.Runtime, append([]string{"exec", c.ID}, command...)...)
+}
+
+// Remove the container, and wait for its logs to be written. Remove may be
+// called more than once. It is called automatically when the test ends.
+func (c *Container) Remove() {
+	c.removeOnce.Do(func() {
+		icmd.RunCmd(icmd.Command(c.Runtime, "rm", "--force", "--volumes", c.ID),
+			icmd.WithTimeout(RemoveTimeout))
+		icmd.WaitOnCmd(RemoveTimeout, c.logs)
+	})
+}
+
+var runtimeProbe struct {
+	sync.Mutex
+	done    bool
+	env     string
+	runtime string
+	err     error
+}
+
+// detectRuntime returns the path of the container runtime binary. The result
+// is cached for the test binary, unless the value of RuntimeEnvVar changes.
+func detectRuntime() (string, error) {
+	runtimeProbe.Lock()
+	defer runtimeProbe.Unlock()
+	env := os.Getenv(RuntimeEnvVar)
+	if !runtimeProbe.done || runtimeProbe.env != env {
+		runtimeProbe.runtime, runtimeProbe.err = probeRuntime(env)
+		runtimeProbe.env = env
+		runtimeProbe.done = true
+	}
+	return runtimeProbe.runtime, runtimeProbe.err
+}
+
+func probeRuntime(env string) (string, error) {
+	candidates := []string{"docker", "podman"}
+	if env != "" {
+		candidates = []string{env}
+	}
+	var failures []string
+	for _, name := range candidates {
+		binary, err := exec.LookPath(name)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		result := icmd.RunCmd(icmd.Command(binary, "version"), icmd.WithTimeout(10*time.Second))
+		if result.Error != nil {
+			failures = append(failures, fmt.Sprintf("%s version: %s", name, strings.TrimSpace(result.Combined())))
+			continue
+		}
+		return binary, nil
+	}
+	return "", errors.New(strings.Join(failures, "; "))
+}
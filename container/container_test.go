@@ -0,0 +1,32 @@
+package container
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+)
+
+func TestRun_SkipsWithoutRuntime(t *testing.T) {
+	defer env.Patch(t, RuntimeEnvVar, "gotest-tools-missing-runtime")()
+
+	var skipped bool
+	t.Run("run", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		Run(t, Config{Image: "alpine"})
+		t.Error("expected the test to be skipped")
+	})
+	assert.Assert(t, skipped)
+}
+
+func TestImageName(t *testing.T) {
+	assert.Equal(t, imageName("postgres"), "postgres")
+	assert.Equal(t, imageName("postgres:16-alpine"), "postgres")
+	assert.Equal(t, imageName("docker.io/library/redis@sha256:abc"), "redis")
+	assert.Equal(t, imageName("localhost:5000/team/app:v1"), "app")
+}
+
+func TestMount_String(t *testing.T) {
+	assert.Equal(t, Mount{Source: "/src", Target: "/dst"}.String(), "/src:/dst")
+	assert.Equal(t, Mount{Source: "/src", Target: "/dst", ReadOnly: true}.String(), "/src:/dst:ro")
+}
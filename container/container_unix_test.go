@@ -0,0 +1,120 @@
+//go:build !windows
+// +build !windows
+
+package container
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+// fakeRuntime emulates the subset of the docker command line used by the
+// package. Every invocation is appended to $FAKE_RUNTIME_STATE/calls.
+const fakeRuntime = `#!/bin/sh
+echo "$@" >> "$FAKE_RUNTIME_STATE/calls"
+case "$1" in
+version) exit 0 ;;
+run) echo "c0ffee" ;;
+port) echo "127.0.0.1:$FAKE_RUNTIME_PORT" ;;
+inspect)
+	if [ -f "$FAKE_RUNTIME_STATE/exited" ]; then echo false; else echo true; fi ;;
+logs)
+	echo "starting"
+	echo "ready to accept connections"
+	while [ ! -f "$FAKE_RUNTIME_STATE/removed" ]; do sleep 0.05; done ;;
+exec)
+	shift 2
+	echo "exec $*" ;;
+rm) touch "$FAKE_RUNTIME_STATE/removed" ;;
+esac
+`
+
+func setupFakeRuntime(t *testing.T) (state string, port int) {
+	dir := fs.NewDir(t, "fake-runtime",
+		fs.WithFile("fake-runtime", fakeRuntime, fs.WithMode(0o755)),
+		fs.WithDir("state"))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() { listener.Close() }) //nolint: errcheck
+	port = listener.Addr().(*net.TCPAddr).Port
+
+	state = dir.Join("state")
+	env.Patch(t, RuntimeEnvVar, dir.Join("fake-runtime"))
+	env.Patch(t, "FAKE_RUNTIME_STATE", state)
+	env.Patch(t, "FAKE_RUNTIME_PORT", strconv.Itoa(port))
+	return state, port
+}
+
+func readCalls(t *testing.T, state string) []string {
+	content, err := ioutil.ReadFile(filepath.Join(state, "calls"))
+	assert.NilError(t, err)
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestRun(t *testing.T) {
+	state, port := setupFakeRuntime(t)
+
+	var c *Container
+	t.Run("run", func(t *testing.T) {
+		c = Run(t, Config{
+			Image:   "postgres:16",
+			Command: []string{"postgres", "-c", "fsync=off"},
+			Env:     map[string]string{"POSTGRES_PASSWORD": "secret", "A": "1"},
+			Ports:   []string{"5432"},
+			Mounts:  []Mount{{Source: "/data", Target: "/var/lib/data", ReadOnly: true}},
+			Ready:   ReadyWhenLog("ready to accept connections"),
+		})
+		assert.Equal(t, c.ID, "c0ffee")
+		assert.Equal(t, c.Address("5432/tcp"), "127.0.0.1:"+strconv.Itoa(port))
+		assert.Assert(t, is.Contains(c.Logs(), "starting"))
+
+		result := c.Exec("pg_isready", "-q")
+		assert.Equal(t, result.Stdout(), "exec pg_isready -q\n")
+	})
+
+	calls := readCalls(t, state)
+	assert.Equal(t, calls[1], "run --detach --label gotest.tools.test=TestRun/run"+
+		" --env A=1 --env POSTGRES_PASSWORD=secret --publish 127.0.0.1::5432/tcp"+
+		" --volume /data:/var/lib/data:ro postgres:16 postgres -c fsync=off")
+	assert.Equal(t, calls[len(calls)-1], "rm --force --volumes c0ffee")
+	_, err := os.Stat(filepath.Join(state, "removed"))
+	assert.NilError(t, err)
+}
+
+func TestRun_ReadyWhenListening(t *testing.T) {
+	setupFakeRuntime(t)
+
+	c := Run(t, Config{
+		Image: "redis",
+		Ports: []string{"6379"},
+		Ready: ReadyWhenListening("6379"),
+	})
+	assert.Assert(t, c.Address("6379") != "")
+	assert.Assert(t, is.Panics(func() { c.Address("80") }))
+}
+
+func TestRun_ContainerExited(t *testing.T) {
+	state, _ := setupFakeRuntime(t)
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(state, "exited"), nil, 0o644))
+
+	fakeT := &fakeT{T: t}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(fakeT, Config{Image: "alpine"})
+	}()
+	<-done
+	assert.Assert(t, fakeT.failed)
+	assert.Assert(t, is.Contains(fakeT.msg, "container exited before it was ready"))
+}
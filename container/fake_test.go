@@ -0,0 +1,19 @@
+package container
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+type fakeT struct {
+	*testing.T
+	failed bool
+	msg    string
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+	t.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
@@ -0,0 +1,61 @@
+package container
+
+import (
+	"strings"
+
+	"gotest.tools/v3/nettest"
+	"gotest.tools/v3/poll"
+)
+
+// Readiness returns a poll.Check which succeeds once the container is ready.
+type Readiness func(c *Container) poll.Check
+
+// ReadyWhenRunning returns a Readiness which is ready as soon as the container
+// is running. It is the default Readiness of Config.
+func ReadyWhenRunning() Readiness {
+	return func(c *Container) poll.Check {
+		return func(t poll.LogT) poll.Result {
+			return poll.Success()
+		}
+	}
+}
+
+// ReadyWhenLog returns a Readiness which is ready once the logs of the
+// container contain substr.
+func ReadyWhenLog(substr string) Readiness {
+	return func(c *Container) poll.Check {
+		return func(t poll.LogT) poll.Result {
+			if strings.Contains(c.Logs(), substr) {
+				return poll.Success()
+			}
+			return poll.Continue("logs do not contain %q", substr)
+		}
+	}
+}
+
+// ReadyWhenListening returns a Readiness which is ready once a connection can
+// be opened to the host address of port, which must be one of Config.Ports.
+//
+// Some runtimes accept connections to a published port before the process in
+// the container is listening. Use ReadyWhenLog or ReadyWhenExec when the
+// runtime uses a userland proxy.
+func ReadyWhenListening(port string) Readiness {
+	return func(c *Container) poll.Check {
+		return nettest.Listening("tcp", c.Address(port))
+	}
+}
+
+// ReadyWhenExec returns a Readiness which is ready once command exits 0 when
+// it is run in the container with Container.Exec.
+func ReadyWhenExec(command ...string) Readiness {
+	return func(c *Container) poll.Check {
+		return func(t poll.LogT) poll.Result {
+			result := c.Exec(command...)
+			if result.Error != nil {
+				return poll.Continue("%s exited %d: %s",
+					strings.Join(command, " "), result.ExitCode, strings.TrimSpace(result.Combined()))
+			}
+			return poll.Success()
+		}
+	}
+}
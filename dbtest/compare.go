@@ -0,0 +1,200 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// Queryer is implemented by *sql.DB, *sql.Tx, and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Table is the result of a query.
+type Table struct {
+	Columns []string
+	Rows    [][]interface{}
+}
+
+// Query runs query and returns the result. The test fails if the query
+// returns an error. Values are converted like the values compared by
+// QueryResult, so []byte values are returned as strings.
+func Query(t assert.TestingT, q Queryer, query string, args ...interface{}) Table {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	table, err := queryTable(q, query, args...)
+	assert.NilError(t, err)
+	return table
+}
+
+func queryTable(q Queryer, query string, args ...interface{}) (Table, error) {
+	rows, err := q.QueryContext(context.Background(), query, args...)
+	if err != nil {
+		return Table{}, err
+	}
+	defer rows.Close() //nolint: errcheck
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return Table{}, err
+	}
+	table := Table{Columns: columns}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return Table{}, err
+		}
+		for i, value := range values {
+			values[i] = normalize(value)
+		}
+		table.Rows = append(table.Rows, values)
+	}
+	return table, rows.Err()
+}
+
+// normalize converts a value to the type returned by most drivers, so that
+// expected values can be written as untyped constants.
+func normalize(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case []byte:
+		return string(typed)
+	case int:
+		return int64(typed)
+	case int8:
+		return int64(typed)
+	case int16:
+		return int64(typed)
+	case int32:
+		return int64(typed)
+	case uint8:
+		return int64(typed)
+	case uint16:
+		return int64(typed)
+	case uint32:
+		return int64(typed)
+	case float32:
+		return float64(typed)
+	default:
+		return value
+	}
+}
+
+// RowCount succeeds if table has expected rows.
+func RowCount(q Queryer, table string, expected int) cmp.Comparison {
+	return func() cmp.Result {
+		var count int
+		rows, err := q.QueryContext(context.Background(), "SELECT COUNT(*) FROM "+table)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		defer rows.Close() //nolint: errcheck
+		if rows.Next() {
+			err = rows.Scan(&count)
+		}
+		if err == nil {
+			err = rows.Err()
+		}
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		if count == expected {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("table %s has %d rows, expected %d", table, count, expected))
+	}
+}
+
+// QueryResult succeeds if the result of query has the same columns and rows,
+// in the same order, as expected. Values are compared with reflect.DeepEqual,
+// after integer and float values are converted to int64 and float64, and
+// []byte values to string.
+//
+// The failure message lists each column of each row which does not match,
+// and the rows which are missing or unexpected.
+func QueryResult(q Queryer, expected Table, query string, args ...interface{}) cmp.Comparison {
+	return func() cmp.Result {
+		actual, err := queryTable(q, query, args...)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		if !reflect.DeepEqual(actual.Columns, expected.Columns) {
+			return cmp.ResultFailure(fmt.Sprintf(
+				"query returned columns %v, expected %v", actual.Columns, expected.Columns))
+		}
+		diff := diffRows(expected.Columns, expected.Rows, actual.Rows)
+		if diff == "" {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure("query result does not match (-expected +actual):\n" + diff)
+	}
+}
+
+func diffRows(columns []string, expected, actual [][]interface{}) string {
+	var out strings.Builder
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			fmt.Fprintf(&out, "row %d: missing\n  - %s\n", i, formatRow(columns, expected[i]))
+		case i >= len(expected):
+			fmt.Fprintf(&out, "row %d: unexpected\n  + %s\n", i, formatRow(columns, actual[i]))
+		default:
+			for j, column := range columns {
+				var exp, act interface{}
+				if j < len(expected[i]) {
+					exp = normalize(expected[i][j])
+				}
+				if j < len(actual[i]) {
+					act = actual[i][j]
+				}
+				if reflect.DeepEqual(exp, act) {
+					continue
+				}
+				expValue, actValue := formatValue(exp), formatValue(act)
+				if expValue == actValue {
+					expValue += fmt.Sprintf(" (%T)", exp)
+					actValue += fmt.Sprintf(" (%T)", act)
+				}
+				fmt.Fprintf(&out, "row %d, column %s:\n  - %s\n  + %s\n",
+					i, column, expValue, actValue)
+			}
+		}
+	}
+	return out.String()
+}
+
+func formatRow(columns []string, row []interface{}) string {
+	fields := make([]string, 0, len(row))
+	for j, value := range row {
+		name := fmt.Sprintf("column%d", j)
+		if j < len(columns) {
+			name = columns[j]
+		}
+		fields = append(fields, name+"="+formatValue(normalize(value)))
+	}
+	return strings.Join(fields, " ")
+}
+
+func formatValue(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", typed)
+	case time.Time:
+		return typed.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%v", typed)
+	}
+}
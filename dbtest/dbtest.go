@@ -0,0 +1,153 @@
+/*
+Package dbtest provides helpers to open a database for a test, load its schema
+and fixtures, isolate the test in a transaction, and compare query results.
+
+The package uses database/sql, and does not import a driver. Import the driver
+for your database in the test, for example:
+
+	import _ "github.com/mattn/go-sqlite3"
+*/
+package dbtest // import "gotest.tools/v3/dbtest"
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/internal/cleanup"
+)
+
+type helperT interface {
+	Helper()
+}
+
+// SQLiteDrivers are the names of database/sql drivers which are used by
+// OpenSQLite, in order of preference. A driver must be registered, usually by
+// importing its package, to be used.
+var SQLiteDrivers = []string{"sqlite3", "sqlite"}
+
+// OpenOp is applied to a database after it is opened by Open or OpenSQLite,
+// usually to create the schema or insert fixtures.
+type OpenOp func(db *sql.DB) error
+
+// Open a database with the driver and dsn, apply the OpenOps, and return the
+// database. The test fails if the database can not be reached, or if any of
+// the ops return an error. The database is closed when the test ends.
+//
+// Open does not create a new database. Use a dsn which is private to the
+// test, or use Tx to isolate the changes made by each test.
+func Open(t assert.TestingT, driverName, dsn string, ops ...OpenOp) *sql.DB {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	db, err := sql.Open(driverName, dsn)
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		db.Close() //nolint: errcheck
+	})
+	assert.NilError(t, db.Ping(), "failed to connect to %s database", driverName)
+
+	for _, op := range ops {
+		assert.NilError(t, op(db))
+	}
+	return db
+}
+
+// OpenSQLite creates a new SQLite database file in a temporary directory, and
+// opens it with Open. The first driver from SQLiteDrivers which is registered
+// is used, the test fails if none of them are registered.
+//
+// The database file is removed when the test ends.
+func OpenSQLite(t assert.TestingT, ops ...OpenOp) *sql.DB {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	driverName := sqliteDriver()
+	assert.Assert(t, driverName != "",
+		"no SQLite driver is registered, import one of the drivers %v", SQLiteDrivers)
+
+	dir := fs.NewDir(t, "dbtest")
+	return Open(t, driverName, dir.Join("test.db"), ops...)
+}
+
+func sqliteDriver() string {
+	registered := make(map[string]bool)
+	for _, name := range sql.Drivers() {
+		registered[name] = true
+	}
+	for _, name := range SQLiteDrivers {
+		if registered[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// WithSQL executes each of the statements in order.
+func WithSQL(statements ...string) OpenOp {
+	return func(db *sql.DB) error {
+		for _, statement := range statements {
+			if _, err := db.Exec(statement); err != nil {
+				return fmt.Errorf("failed to execute %q: %w", statement, err)
+			}
+		}
+		return nil
+	}
+}
+
+// WithSQLFiles executes the content of each of the files in order. The
+// content of a file is executed as a single statement, so the driver must
+// support multiple statements in one Exec to use files with more than one
+// statement.
+func WithSQLFiles(paths ...string) OpenOp {
+	return func(db *sql.DB) error {
+		for _, path := range paths {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if _, err := db.Exec(string(content)); err != nil {
+				return fmt.Errorf("failed to execute %s: %w", path, err)
+			}
+		}
+		return nil
+	}
+}
+
+// WithMigrations executes every file in dir with a .sql extension, sorted by
+// name, like WithSQLFiles. Prefix the names of the files with a number to set
+// the order, for example 001_create_users.sql.
+func WithMigrations(dir string) OpenOp {
+	return func(db *sql.DB) error {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("no migrations found in %s", dir)
+		}
+		sort.Strings(paths)
+		return WithSQLFiles(paths...)(db)
+	}
+}
+
+// Tx begins a transaction which is rolled back when the test ends, so that
+// the changes made by the test are not seen by other tests which use the
+// same database. The driver and database must support transactions, and
+// statements which commit implicitly, like DDL in some databases, are not
+// rolled back.
+func Tx(t assert.TestingT, db *sql.DB) *sql.Tx {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	tx, err := db.Begin()
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		tx.Rollback() //nolint: errcheck
+	})
+	return tx
+}
@@ -0,0 +1,148 @@
+package dbtest
+
+import (
+	"database/sql"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestOpen(t *testing.T) {
+	var db *sql.DB
+	t.Run("open", func(t *testing.T) {
+		db = Open(t, "dbtest-fake", t.Name(),
+			WithMigrations("testdata/migrations"),
+			WithSQLFiles("testdata/seed.sql"),
+			WithSQL("INSERT INTO users VALUES (2, 'bob')"))
+		assert.NilError(t, db.Ping())
+	})
+
+	expected := []string{
+		"CREATE TABLE users (id INTEGER, name TEXT);\n",
+		"CREATE INDEX users_name ON users (name);\n",
+		"INSERT INTO users VALUES (1, 'alice');\n",
+		"INSERT INTO users VALUES (2, 'bob')",
+	}
+	assert.DeepEqual(t, getFakeDB("TestOpen/open").Events(), expected)
+	assert.ErrorContains(t, db.Ping(), "database is closed")
+}
+
+func TestWithSQL_Error(t *testing.T) {
+	db, err := sql.Open("dbtest-fake", t.Name())
+	assert.NilError(t, err)
+	defer db.Close() //nolint: errcheck
+
+	err = WithSQL("FAIL")(db)
+	assert.ErrorContains(t, err, `failed to execute "FAIL": syntax error`)
+}
+
+func TestWithMigrations_NoFiles(t *testing.T) {
+	err := WithMigrations("testdata/missing")(nil)
+	assert.ErrorContains(t, err, "no migrations found in testdata/missing")
+}
+
+func TestOpenSQLite(t *testing.T) {
+	orig := SQLiteDrivers
+	defer func() { SQLiteDrivers = orig }()
+	SQLiteDrivers = []string{"not-registered", "dbtest-fake"}
+
+	db := OpenSQLite(t, WithSQL("CREATE TABLE t (id INTEGER)"))
+	assert.NilError(t, db.Ping())
+}
+
+func TestTx(t *testing.T) {
+	db, err := sql.Open("dbtest-fake", t.Name())
+	assert.NilError(t, err)
+	defer db.Close() //nolint: errcheck
+
+	t.Run("tx", func(t *testing.T) {
+		tx := Tx(t, db)
+		_, err := tx.Exec("DELETE FROM users")
+		assert.NilError(t, err)
+	})
+	assert.DeepEqual(t, getFakeDB(t.Name()).Events(),
+		[]string{"BEGIN", "DELETE FROM users", "ROLLBACK"})
+}
+
+func TestRowCount(t *testing.T) {
+	db, err := sql.Open("dbtest-fake", t.Name())
+	assert.NilError(t, err)
+	defer db.Close() //nolint: errcheck
+	getFakeDB(t.Name()).SetResult("SELECT COUNT(*) FROM users", Table{
+		Columns: []string{"COUNT(*)"},
+		Rows:    [][]interface{}{{int64(2)}},
+	})
+
+	assert.Assert(t, RowCount(db, "users", 2))
+
+	result := RowCount(db, "users", 3)()
+	assert.Equal(t, failureMessage(result), "table users has 2 rows, expected 3")
+
+	result = RowCount(db, "missing", 0)()
+	assert.Equal(t, failureMessage(result), "no such table")
+}
+
+func TestQueryResult(t *testing.T) {
+	db, err := sql.Open("dbtest-fake", t.Name())
+	assert.NilError(t, err)
+	defer db.Close() //nolint: errcheck
+	getFakeDB(t.Name()).SetResult("SELECT id, name FROM users", Table{
+		Columns: []string{"id", "name"},
+		Rows: [][]interface{}{
+			{int64(1), []byte("alice")},
+			{int64(2), nil},
+			{int64(4), "dave"},
+		},
+	})
+
+	query := "SELECT id, name FROM users"
+	assert.Assert(t, QueryResult(db, Table{
+		Columns: []string{"id", "name"},
+		Rows:    [][]interface{}{{1, "alice"}, {2, nil}, {4, "dave"}},
+	}, query))
+
+	assert.DeepEqual(t, Query(t, db, query).Rows[0], []interface{}{int64(1), "alice"})
+
+	t.Run("mismatched rows", func(t *testing.T) {
+		result := QueryResult(db, Table{
+			Columns: []string{"id", "name"},
+			Rows:    [][]interface{}{{1, "alice"}, {2.0, "bob"}},
+		}, query)()
+		expected := `query result does not match (-expected +actual):
+row 1, column id:
+  - 2 (float64)
+  + 2 (int64)
+row 1, column name:
+  - "bob"
+  + NULL
+row 2: unexpected
+  + id=4 name="dave"
+`
+		assert.Equal(t, failureMessage(result), expected)
+	})
+
+	t.Run("missing rows", func(t *testing.T) {
+		result := QueryResult(db, Table{
+			Columns: []string{"id", "name"},
+			Rows: [][]interface{}{
+				{1, "alice"}, {2, nil}, {4, "dave"}, {5, "erin"},
+			},
+		}, query)()
+		expected := `query result does not match (-expected +actual):
+row 3: missing
+  - id=5 name="erin"
+`
+		assert.Equal(t, failureMessage(result), expected)
+	})
+
+	t.Run("mismatched columns", func(t *testing.T) {
+		result := QueryResult(db, Table{Columns: []string{"id"}}, query)()
+		assert.Assert(t, is.Contains(failureMessage(result),
+			"query returned columns [id name], expected [id]"))
+	})
+}
+
+func failureMessage(result is.Result) string {
+	return result.(interface{ FailureMessage() string }).FailureMessage()
+}
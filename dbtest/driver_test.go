@@ -0,0 +1,147 @@
+package dbtest
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+)
+
+// fakeDriver is a database/sql driver which records the statements executed
+// on each database, and returns the canned results of queries.
+type fakeDriver struct{}
+
+func init() {
+	sql.Register("dbtest-fake", fakeDriver{})
+}
+
+var fakeDBs = struct {
+	sync.Mutex
+	byName map[string]*fakeDB
+}{byName: map[string]*fakeDB{}}
+
+type fakeDB struct {
+	mu      sync.Mutex
+	events  []string
+	results map[string]Table
+}
+
+func getFakeDB(name string) *fakeDB {
+	fakeDBs.Lock()
+	defer fakeDBs.Unlock()
+	db, ok := fakeDBs.byName[name]
+	if !ok {
+		db = &fakeDB{results: map[string]Table{}}
+		fakeDBs.byName[name] = db
+	}
+	return db
+}
+
+func (db *fakeDB) record(event string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.events = append(db.events, event)
+}
+
+func (db *fakeDB) Events() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.events...)
+}
+
+func (db *fakeDB) SetResult(query string, table Table) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.results[query] = table
+}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{db: getFakeDB(name)}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	return &fakeTx{db: c.db}, nil
+}
+
+type fakeTx struct {
+	db *fakeDB
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.record("COMMIT")
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.record("ROLLBACK")
+	return nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.query == "FAIL" {
+		return nil, errors.New("syntax error")
+	}
+	s.db.record(s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	table, ok := s.db.results[s.query]
+	s.db.mu.Unlock()
+	if !ok {
+		return nil, errors.New("no such table")
+	}
+	return &fakeRows{table: table}, nil
+}
+
+type fakeRows struct {
+	table Table
+	index int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.table.Columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.table.Rows) {
+		return io.EOF
+	}
+	for i, value := range r.table.Rows[r.index] {
+		dest[i] = value
+	}
+	r.index++
+	return nil
+}
@@ -16,3 +16,10 @@ func ExamplePatchAll() {
 		"TWO": "BAR",
 	})()
 }
+
+// Save the environment, working directory, and umask, and restore them when
+// the test ends
+func ExampleSaveState() {
+	defer SaveState(t)()
+	ChangeWorkingDir(t, "./testdata")
+}
@@ -0,0 +1,111 @@
+package env
+
+import (
+	"os"
+	"runtime"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/internal/cleanup"
+)
+
+// Home is a set of temporary user directories created by PatchHome. Use the
+// fs.Dir handles to assert on the files written by the code under test, for
+// example with fs.Equal.
+type Home struct {
+	// Root contains all the other directories.
+	Root *fs.Dir
+	// Home is the value of HOME, and USERPROFILE on Windows.
+	Home *fs.Dir
+	// Config is the value of XDG_CONFIG_HOME, and APPDATA on Windows.
+	Config *fs.Dir
+	// Cache is the value of XDG_CACHE_HOME, and LOCALAPPDATA on Windows.
+	Cache *fs.Dir
+	// Data is the value of XDG_DATA_HOME.
+	Data *fs.Dir
+	// State is the value of XDG_STATE_HOME.
+	State *fs.Dir
+	// Tmp is the value of TMPDIR, and TMP and TEMP on Windows.
+	Tmp *fs.Dir
+
+	restore []func()
+}
+
+// PatchHome creates a temporary home directory, XDG base directories, and
+// temporary directory, and sets the environment variables which point to them,
+// so that code under test does not read or write the real home directory of
+// the user running the tests.
+//
+// Restore resets the environment variables and removes the directories. When
+// used with Go 1.14+ Restore will be called automatically when the test ends,
+// unless the TEST_NOCLEANUP env var is set to true.
+func PatchHome(t assert.TestingT) *Home {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	private := fs.WithMode(0700)
+	root := fs.NewDir(t, "home",
+		fs.WithDir("home", private),
+		fs.WithDir("config", private),
+		fs.WithDir("cache", private),
+		fs.WithDir("data", private),
+		fs.WithDir("state", private),
+		fs.WithDir("tmp", private))
+	home := &Home{
+		Root:   root,
+		Home:   fs.DirFromPath(t, root.Join("home")),
+		Config: fs.DirFromPath(t, root.Join("config")),
+		Cache:  fs.DirFromPath(t, root.Join("cache")),
+		Data:   fs.DirFromPath(t, root.Join("data")),
+		State:  fs.DirFromPath(t, root.Join("state")),
+		Tmp:    fs.DirFromPath(t, root.Join("tmp")),
+	}
+
+	vars := map[string]*fs.Dir{
+		"HOME":            home.Home,
+		"XDG_CONFIG_HOME": home.Config,
+		"XDG_CACHE_HOME":  home.Cache,
+		"XDG_DATA_HOME":   home.Data,
+		"XDG_STATE_HOME":  home.State,
+		"TMPDIR":          home.Tmp,
+	}
+	if runtime.GOOS == "windows" {
+		vars["USERPROFILE"] = home.Home
+		vars["APPDATA"] = home.Config
+		vars["LOCALAPPDATA"] = home.Cache
+		vars["TMP"] = home.Tmp
+		vars["TEMP"] = home.Tmp
+	}
+	for key, dir := range vars {
+		home.restore = append(home.restore, patchEnv(t, key, dir.Path()))
+	}
+	cleanup.Cleanup(t, home.Restore)
+	return home
+}
+
+// Restore resets the environment variables set by PatchHome, and removes the
+// directories. It is safe to call Restore more than once.
+func (h *Home) Restore() {
+	for _, restore := range h.restore {
+		restore()
+	}
+	h.restore = nil
+	h.Root.Remove()
+}
+
+// patchEnv is Patch without registering a cleanup, so that the environment is
+// restored only once by Home.Restore.
+func patchEnv(t assert.TestingT, key, value string) func() {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	oldValue, envVarExists := os.LookupEnv(key)
+	assert.NilError(t, os.Setenv(key, value))
+	return func() {
+		if !envVarExists {
+			assert.NilError(t, os.Unsetenv(key))
+			return
+		}
+		assert.NilError(t, os.Setenv(key, oldValue))
+	}
+}
@@ -0,0 +1,41 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestPatchHome(t *testing.T) {
+	oldHome := os.Getenv("HOME")
+	oldTmp, tmpExists := os.LookupEnv("TMPDIR")
+
+	home := PatchHome(t)
+	assert.Equal(t, os.Getenv("HOME"), home.Home.Path())
+	assert.Equal(t, os.Getenv("XDG_CONFIG_HOME"), home.Config.Path())
+	assert.Equal(t, os.Getenv("XDG_CACHE_HOME"), home.Cache.Path())
+	assert.Equal(t, os.Getenv("XDG_DATA_HOME"), home.Data.Path())
+	assert.Equal(t, os.Getenv("XDG_STATE_HOME"), home.State.Path())
+	assert.Equal(t, os.TempDir(), home.Tmp.Path())
+
+	// code under test writes a config file
+	configDir := filepath.Join(os.Getenv("XDG_CONFIG_HOME"), "app")
+	assert.NilError(t, os.MkdirAll(configDir, 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("a: 1\n"), 0644))
+
+	expected := fs.Expected(t, fs.WithDir("app", fs.WithFile("config.yaml", "a: 1\n")))
+	assert.Assert(t, fs.Equal(home.Config.Path(), expected))
+
+	home.Restore()
+	home.Restore()
+	assert.Equal(t, os.Getenv("HOME"), oldHome)
+	tmp, ok := os.LookupEnv("TMPDIR")
+	assert.Equal(t, ok, tmpExists)
+	assert.Equal(t, tmp, oldTmp)
+	_, err := os.Stat(home.Root.Path())
+	assert.Assert(t, os.IsNotExist(err))
+}
@@ -0,0 +1,43 @@
+package env
+
+import (
+	"os"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/internal/cleanup"
+)
+
+// SaveState saves the environment variables, the working directory, and on
+// Unix the umask of the process, and returns a function which restores all of
+// them. Use SaveState in a test which changes the state of the process, or
+// runs code which changes it, so that the changes do not leak into other
+// tests. Variables which were set after SaveState are removed by the restore
+// function.
+//
+// The state is shared by every goroutine in the process, so a test which
+// changes it must not run in parallel with other tests.
+//
+// When used with Go 1.14+ the restore function will be called automatically
+// when the test ends, unless the TEST_NOCLEANUP env var is set to true.
+func SaveState(t assert.TestingT) func() {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	oldEnv := os.Environ()
+	cwd, err := os.Getwd()
+	assert.NilError(t, err)
+	restoreUmask := saveUmask()
+	clean := func() {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		os.Clearenv()
+		for key, oldVal := range ToMap(oldEnv) {
+			assert.NilError(t, os.Setenv(key, oldVal), "setenv %s=%s", key, oldVal)
+		}
+		assert.NilError(t, os.Chdir(cwd))
+		restoreUmask()
+	}
+	cleanup.Cleanup(t, clean)
+	return clean
+}
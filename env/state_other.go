@@ -0,0 +1,9 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package env
+
+// saveUmask does nothing, Windows and Plan 9 do not have a umask.
+func saveUmask() func() {
+	return func() {}
+}
@@ -0,0 +1,54 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/internal/source"
+	"gotest.tools/v3/skip"
+)
+
+func TestSaveState(t *testing.T) {
+	tmpDir := fs.NewDir(t, t.Name())
+	defer tmpDir.Remove()
+
+	defer Patch(t, "GOTESTTOOLS_STATE_CHANGED", "before")()
+	defer Patch(t, "GOTESTTOOLS_STATE_REMOVED", "before")()
+	origWorkDir := pwd(t)
+	origEnv := os.Environ()
+
+	restore := SaveState(t)
+	assert.NilError(t, os.Setenv("GOTESTTOOLS_STATE_CHANGED", "after"))
+	assert.NilError(t, os.Unsetenv("GOTESTTOOLS_STATE_REMOVED"))
+	assert.NilError(t, os.Setenv("GOTESTTOOLS_STATE_ADDED", "after"))
+	assert.NilError(t, os.Chdir(tmpDir.Path()))
+
+	restore()
+	assert.Equal(t, pwd(t), origWorkDir)
+	assert.DeepEqual(t, sorted(os.Environ()), sorted(origEnv))
+	_, exists := os.LookupEnv("GOTESTTOOLS_STATE_ADDED")
+	assert.Assert(t, !exists)
+}
+
+func TestSaveState_IntegrationWithCleanup(t *testing.T) {
+	skip.If(t, source.GoVersionLessThan(1, 14))
+
+	tmpDir := fs.NewDir(t, t.Name())
+	defer tmpDir.Remove()
+
+	origWorkDir := pwd(t)
+	origEnv := os.Environ()
+
+	t.Run("cleanup in subtest", func(t *testing.T) {
+		SaveState(t)
+		assert.NilError(t, os.Setenv("GOTESTTOOLS_STATE_ADDED", "value"))
+		assert.NilError(t, os.Chdir(tmpDir.Path()))
+	})
+
+	t.Run("state is restored", func(t *testing.T) {
+		assert.Equal(t, pwd(t), origWorkDir)
+		assert.DeepEqual(t, sorted(os.Environ()), sorted(origEnv))
+	})
+}
@@ -0,0 +1,16 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package env
+
+import "syscall"
+
+// saveUmask returns a function which restores the current umask.
+func saveUmask() func() {
+	// The umask can only be read by changing it.
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return func() {
+		syscall.Umask(mask)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package env
+
+import (
+	"syscall"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSaveState_Umask(t *testing.T) {
+	orig := syscall.Umask(0o022)
+	defer syscall.Umask(orig)
+
+	restore := SaveState(t)
+	syscall.Umask(0o077)
+	restore()
+
+	assert.Equal(t, syscall.Umask(0o022), 0o022)
+}
@@ -0,0 +1,287 @@
+/*
+Package fixture provides composable test fixtures with declared dependencies,
+scopes, memoization, and ordered teardown.
+
+A Fixture declares how to set up and tear down a value used by tests, and the
+other fixtures it requires. Get returns the value of a fixture, setting it up
+the first time it is requested in its scope. Fixtures are torn down in the
+reverse of the order in which they were set up, so a fixture is always torn
+down before the fixtures it requires.
+*/
+package fixture // import "gotest.tools/v3/fixture"
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// TestingT is the subset of testing.T used by the fixture package.
+type TestingT interface {
+	Name() string
+	Cleanup(f func())
+	Fatalf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Scope is the lifetime of the value of a Fixture.
+type Scope int
+
+const (
+	// TestScope fixtures are set up once for each test which requests them, and
+	// are torn down when the test completes.
+	TestScope Scope = iota
+	// SharedScope fixtures are set up once for each test which requests them,
+	// like TestScope, but are reused by the subtests of that test. A subtest
+	// which requests the fixture before its parent test gets its own value.
+	SharedScope
+	// PackageScope fixtures are set up once for the test binary, and are torn
+	// down by TearDownPackage or Run.
+	PackageScope
+)
+
+// Fixture declares a value used by tests, how to set it up and tear it down,
+// and the other fixtures it requires. A Fixture should be declared once, as a
+// package level variable, because values are memoized by the *Fixture.
+type Fixture struct {
+	// Name identifies the fixture in failure messages.
+	Name string
+	// Scope is the lifetime of the value. Defaults to TestScope.
+	Scope Scope
+	// Requires are fixtures which are set up before this fixture, and torn
+	// down after it. A required fixture with a narrower scope is set up in the
+	// same scope as this fixture.
+	Requires []*Fixture
+	// Setup creates the value of the fixture. The values of the fixtures in
+	// Requires are available from deps.
+	//
+	// The t passed to the Setup of a PackageScope fixture is not the test
+	// which requested the fixture, because the value outlives that test.
+	// Functions passed to its Cleanup run when the PackageScope fixtures are
+	// torn down, and Fatalf or Errorf fail the setup.
+	Setup func(t TestingT, deps Deps) (interface{}, error)
+	// Teardown releases the value of the fixture. It is optional.
+	Teardown func(value interface{}) error
+}
+
+func (f *Fixture) String() string {
+	if f.Name == "" {
+		return "unnamed fixture"
+	}
+	return f.Name
+}
+
+// Deps provides the values of the fixtures required by a fixture to its Setup
+// function.
+type Deps struct {
+	values map[*Fixture]interface{}
+}
+
+// Get returns the value of f. It panics if f is not in the Requires of the
+// fixture being set up.
+func (d Deps) Get(f *Fixture) interface{} {
+	value, ok := d.values[f]
+	if !ok {
+		panic(fmt.Sprintf("fixture %s is not a declared requirement", f))
+	}
+	return value
+}
+
+// Get returns the value of f, setting up f and the fixtures it requires if
+// they have not been set up in their scope. TestScope fixtures are shared by
+// every call to Get from the same test. If the setup of any fixture fails the
+// test fails with t.Fatalf.
+func Get(t TestingT, f *Fixture) interface{} {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	value, err := get(t, scopeForTest(t), f, nil)
+	if err != nil {
+		t.Fatalf("%s", err)
+	}
+	return value
+}
+
+type entry struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+type scope struct {
+	mu        sync.Mutex
+	entries   map[*Fixture]*entry
+	teardowns []func() error
+}
+
+func newScope() *scope {
+	return &scope{entries: make(map[*Fixture]*entry)}
+}
+
+func (s *scope) entry(f *Fixture) *entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[f]
+	if !ok {
+		e = &entry{}
+		s.entries[f] = e
+	}
+	return e
+}
+
+func (s *scope) addTeardown(f func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.teardowns = append(s.teardowns, f)
+}
+
+// tearDown runs the teardown functions in the reverse of the order they were
+// added, and resets the scope. It returns every error from the teardowns.
+func (s *scope) tearDown() []error {
+	s.mu.Lock()
+	teardowns := s.teardowns
+	s.teardowns = nil
+	s.entries = make(map[*Fixture]*entry)
+	s.mu.Unlock()
+
+	var errs []error
+	for i := len(teardowns) - 1; i >= 0; i-- {
+		if err := teardowns[i](); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+var packageScope = newScope()
+
+var testScopes = struct {
+	sync.Mutex
+	scopes map[string]*scope
+}{scopes: make(map[string]*scope)}
+
+// scopeForTest returns the scope for the test t, creating it and registering
+// its teardown on the first call for the test.
+func scopeForTest(t TestingT) *scope {
+	testScopes.Lock()
+	defer testScopes.Unlock()
+	name := t.Name()
+	if s, ok := testScopes.scopes[name]; ok {
+		return s
+	}
+	s := newScope()
+	testScopes.scopes[name] = s
+	t.Cleanup(func() {
+		testScopes.Lock()
+		delete(testScopes.scopes, name)
+		testScopes.Unlock()
+		for _, err := range s.tearDown() {
+			t.Errorf("%s", err)
+		}
+	})
+	return s
+}
+
+// sharedScope returns the scope of the closest ancestor of the test t which
+// has set up f, or nil if no ancestor has.
+func sharedScope(t TestingT, f *Fixture) *scope {
+	testScopes.Lock()
+	defer testScopes.Unlock()
+	name := t.Name()
+	for {
+		i := strings.LastIndex(name, "/")
+		if i < 0 {
+			return nil
+		}
+		name = name[:i]
+		s, ok := testScopes.scopes[name]
+		if !ok {
+			continue
+		}
+		s.mu.Lock()
+		_, ok = s.entries[f]
+		s.mu.Unlock()
+		if ok {
+			return s
+		}
+	}
+}
+
+// get returns the value of f from s, setting it up if necessary. path is the
+// chain of fixtures which required f, used to detect cycles.
+func get(t TestingT, s *scope, f *Fixture, path []*Fixture) (interface{}, error) {
+	for _, p := range path {
+		if p == f {
+			return nil, fmt.Errorf("fixture %s requires itself: %s", f, formatPath(append(path, f)))
+		}
+	}
+	switch f.Scope {
+	case PackageScope:
+		s = packageScope
+	case SharedScope:
+		if parent := sharedScope(t, f); parent != nil {
+			s = parent
+		}
+	}
+
+	e := s.entry(f)
+	e.once.Do(func() {
+		ok := false
+		defer func() {
+			// Setup called runtime.Goexit, usually from t.FailNow, or
+			// panicked. The value must not be used by later calls to Get.
+			if !ok {
+				e.value, e.err = nil, fmt.Errorf("setup of fixture %s did not complete", f)
+			}
+		}()
+		e.value, e.err = setUp(t, s, f, append(path, f))
+		ok = true
+	})
+	return e.value, e.err
+}
+
+func setUp(t TestingT, s *scope, f *Fixture, path []*Fixture) (interface{}, error) {
+	deps := Deps{values: make(map[*Fixture]interface{}, len(f.Requires))}
+	for _, req := range f.Requires {
+		value, err := get(t, s, req, path)
+		if err != nil {
+			return nil, err
+		}
+		deps.values[req] = value
+	}
+
+	if f.Setup == nil {
+		return nil, nil
+	}
+	var value interface{}
+	var err error
+	if s == packageScope {
+		value, err = setUpPackage(t, s, f, deps)
+	} else {
+		value, err = f.Setup(t, deps)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up fixture %s: %w", f, err)
+	}
+	if f.Teardown != nil {
+		s.addTeardown(func() error {
+			if err := f.Teardown(value); err != nil {
+				return fmt.Errorf("failed to tear down fixture %s: %w", f, err)
+			}
+			return nil
+		})
+	}
+	return value, nil
+}
+
+func formatPath(path []*Fixture) string {
+	names := make([]string, 0, len(path))
+	for _, f := range path {
+		names = append(names, f.String())
+	}
+	return strings.Join(names, " -> ")
+}
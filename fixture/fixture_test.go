@@ -0,0 +1,283 @@
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// recorder records the setup and teardown of fixtures.
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) fixture(name string, scope Scope, requires ...*Fixture) *Fixture {
+	count := 0
+	return &Fixture{
+		Name:     name,
+		Scope:    scope,
+		Requires: requires,
+		Setup: func(t TestingT, deps Deps) (interface{}, error) {
+			count++
+			value := fmt.Sprintf("%s-%d", name, count)
+			for _, req := range requires {
+				value += "(" + deps.Get(req).(string) + ")"
+			}
+			r.add("setup %s", value)
+			return value, nil
+		},
+		Teardown: func(value interface{}) error {
+			r.add("teardown %s", value)
+			return nil
+		},
+	}
+}
+
+func TestGetTestScope(t *testing.T) {
+	rec := &recorder{}
+	db := rec.fixture("db", TestScope)
+	api := rec.fixture("api", TestScope, db)
+
+	t.Run("first", func(t *testing.T) {
+		assert.Equal(t, Get(t, api), "api-1(db-1)")
+		assert.Equal(t, Get(t, db), "db-1", "expected value to be memoized")
+	})
+	t.Run("second", func(t *testing.T) {
+		assert.Equal(t, Get(t, api), "api-2(db-2)")
+	})
+
+	assert.DeepEqual(t, rec.events, []string{
+		"setup db-1",
+		"setup api-1(db-1)",
+		"teardown api-1(db-1)",
+		"teardown db-1",
+		"setup db-2",
+		"setup api-2(db-2)",
+		"teardown api-2(db-2)",
+		"teardown db-2",
+	})
+}
+
+func TestGetSharedScope(t *testing.T) {
+	rec := &recorder{}
+	server := rec.fixture("server", SharedScope)
+
+	t.Run("parent", func(t *testing.T) {
+		assert.Equal(t, Get(t, server), "server-1")
+		for _, name := range []string{"a", "b"} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				assert.Equal(t, Get(t, server), "server-1")
+			})
+		}
+	})
+
+	assert.DeepEqual(t, rec.events, []string{"setup server-1", "teardown server-1"})
+}
+
+func TestGetPackageScope(t *testing.T) {
+	defer func() { assert.NilError(t, TearDownPackage()) }()
+	rec := &recorder{}
+	cluster := rec.fixture("cluster", PackageScope)
+	config := rec.fixture("config", TestScope)
+	client := rec.fixture("client", PackageScope, cluster, config)
+
+	t.Run("first", func(t *testing.T) {
+		assert.Equal(t, Get(t, client), "client-1(cluster-1)(config-1)")
+	})
+	t.Run("second", func(t *testing.T) {
+		assert.Equal(t, Get(t, client), "client-1(cluster-1)(config-1)")
+		assert.Equal(t, Get(t, config), "config-2", "expected a new test scoped value")
+	})
+
+	assert.NilError(t, TearDownPackage())
+	assert.DeepEqual(t, rec.events, []string{
+		"setup cluster-1",
+		"setup config-1",
+		"setup client-1(cluster-1)(config-1)",
+		"setup config-2",
+		"teardown config-2",
+		"teardown client-1(cluster-1)(config-1)",
+		"teardown config-1",
+		"teardown cluster-1",
+	})
+}
+
+type fakeT struct {
+	name     string
+	failed   string
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string { return t.name }
+
+func (t *fakeT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestGetSetupError(t *testing.T) {
+	broken := &Fixture{
+		Name: "broken",
+		Setup: func(t TestingT, deps Deps) (interface{}, error) {
+			return nil, errors.New("no database")
+		},
+	}
+	uses := &Fixture{Name: "uses", Requires: []*Fixture{broken}}
+
+	ft := &fakeT{name: "TestFakeSetupError"}
+	defer ft.runCleanups()
+	Get(ft, uses)
+	assert.Equal(t, ft.failed, "failed to set up fixture broken: no database")
+}
+
+func TestGetSetupIncomplete(t *testing.T) {
+	f := &Fixture{
+		Name: "fatal",
+		Setup: func(t TestingT, deps Deps) (interface{}, error) {
+			runtime.Goexit() // like t.FailNow
+			return 1, nil
+		},
+	}
+
+	ft := &fakeT{name: "TestFakeSetupIncomplete"}
+	defer ft.runCleanups()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Get(ft, f)
+	}()
+	<-done
+	assert.Equal(t, Get(ft, f), nil)
+	assert.Equal(t, ft.failed, "setup of fixture fatal did not complete")
+}
+
+func TestGetPackageScopeT(t *testing.T) {
+	defer func() { assert.NilError(t, TearDownPackage()) }()
+	rec := &recorder{}
+	f := &Fixture{
+		Name:  "cluster",
+		Scope: PackageScope,
+		Setup: func(t TestingT, deps Deps) (interface{}, error) {
+			rec.add("setup in %s", t.Name())
+			t.Cleanup(func() { rec.add("cleanup") })
+			return 1, nil
+		},
+		Teardown: func(value interface{}) error {
+			rec.add("teardown")
+			return nil
+		},
+	}
+
+	t.Run("first", func(t *testing.T) {
+		assert.Equal(t, Get(t, f), 1)
+	})
+	t.Run("second", func(t *testing.T) {
+		assert.Equal(t, Get(t, f), 1)
+	})
+	assert.DeepEqual(t, rec.events, []string{"setup in TestGetPackageScopeT/first"})
+
+	assert.NilError(t, TearDownPackage())
+	assert.DeepEqual(t, rec.events, []string{
+		"setup in TestGetPackageScopeT/first",
+		"teardown",
+		"cleanup",
+	})
+}
+
+func TestGetPackageScopeFatal(t *testing.T) {
+	defer func() { assert.NilError(t, TearDownPackage()) }()
+	f := &Fixture{
+		Name:  "cluster",
+		Scope: PackageScope,
+		Setup: func(t TestingT, deps Deps) (interface{}, error) {
+			t.Errorf("slow start")
+			t.Fatalf("no cluster")
+			return 1, nil
+		},
+	}
+
+	ft := &fakeT{name: "TestFakePackageScopeFatal"}
+	defer ft.runCleanups()
+	assert.Equal(t, Get(ft, f), nil)
+	assert.Equal(t, ft.failed, "failed to set up fixture cluster: slow start\nno cluster")
+}
+
+func TestGetTeardownError(t *testing.T) {
+	f := &Fixture{
+		Name:  "leaky",
+		Setup: func(t TestingT, deps Deps) (interface{}, error) { return 1, nil },
+		Teardown: func(value interface{}) error {
+			return errors.New("still in use")
+		},
+	}
+
+	ft := &fakeT{name: "TestFakeTeardownError"}
+	Get(ft, f)
+	ft.runCleanups()
+	assert.DeepEqual(t, ft.errors, []string{"failed to tear down fixture leaky: still in use"})
+}
+
+func TestGetCycle(t *testing.T) {
+	a := &Fixture{Name: "a"}
+	b := &Fixture{Name: "b", Requires: []*Fixture{a}}
+	a.Requires = []*Fixture{b}
+
+	ft := &fakeT{name: "TestFakeCycle"}
+	defer ft.runCleanups()
+	Get(ft, a)
+	assert.Equal(t, ft.failed, "fixture a requires itself: a -> b -> a")
+}
+
+func TestDepsGetUndeclared(t *testing.T) {
+	assert.Assert(t, cmp.Panics(func() { Deps{}.Get(&Fixture{Name: "x"}) }))
+}
+
+type fakeM struct {
+	run func()
+}
+
+func (m fakeM) Run() int {
+	m.run()
+	return 0
+}
+
+func TestRun(t *testing.T) {
+	f := &Fixture{
+		Name:     "leaky",
+		Scope:    PackageScope,
+		Setup:    func(t TestingT, deps Deps) (interface{}, error) { return 1, nil },
+		Teardown: func(value interface{}) error { return errors.New("still in use") },
+	}
+
+	ft := &fakeT{name: "TestFakeRun"}
+	defer ft.runCleanups()
+	code := Run(fakeM{run: func() {
+		Get(ft, f)
+	}})
+	assert.Equal(t, code, 1)
+}
@@ -0,0 +1,91 @@
+package fixture
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// TearDownPackage tears down every PackageScope fixture, in the reverse of the
+// order in which they were set up. It should be called from TestMain after
+// the tests have run. See Run.
+func TearDownPackage() error {
+	errs := packageScope.tearDown()
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return errors.New(strings.Join(messages, "\n"))
+}
+
+// Run runs the tests with m.Run, tears down the PackageScope fixtures, and
+// returns the exit code. If a teardown fails the error is printed to stderr
+// and the exit code is non-zero. Use it from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(fixture.Run(m))
+//	}
+func Run(m interface{ Run() int }) int {
+	code := m.Run()
+	if err := TearDownPackage(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
+
+// packageT is the TestingT passed to the Setup of a PackageScope fixture. It
+// lives as long as the package scope, instead of the test which requested the
+// fixture.
+type packageT struct {
+	name  string
+	scope *scope
+	// failures are the messages from Errorf and Fatalf.
+	failures []string
+}
+
+// errPackageSetupFailed is the panic used by packageT.Fatalf to stop Setup.
+var errPackageSetupFailed = errors.New("package fixture setup failed")
+
+// Name returns the name of the test which requested the fixture.
+func (t *packageT) Name() string {
+	return t.name
+}
+
+// Cleanup registers f to run when the package scope is torn down.
+func (t *packageT) Cleanup(f func()) {
+	t.scope.addTeardown(func() error {
+		f()
+		return nil
+	})
+}
+
+func (t *packageT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *packageT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	panic(errPackageSetupFailed)
+}
+
+// setUpPackage calls the Setup of the PackageScope fixture f with a packageT.
+// A failure reported with Errorf or Fatalf is returned as an error.
+func setUpPackage(t TestingT, s *scope, f *Fixture, deps Deps) (value interface{}, err error) {
+	pt := &packageT{name: t.Name(), scope: s}
+	defer func() {
+		if r := recover(); r != nil && r != errPackageSetupFailed {
+			panic(r)
+		}
+		if len(pt.failures) > 0 {
+			value, err = nil, errors.New(strings.Join(pt.failures, "\n"))
+		}
+	}()
+	return f.Setup(pt, deps)
+}
@@ -0,0 +1,211 @@
+/*
+Package flaky retries the body of a test which is known to fail
+intermittently, and records how often it needed to be retried.
+*/
+package flaky // import "gotest.tools/v3/flaky"
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// TestingT is the interface passed to each attempt of the test body. It is
+// satisfied by the subset of testing.T which is safe to use in an attempt
+// that may be retried, and can be used with the assert package.
+type TestingT interface {
+	Name() string
+	Helper()
+	Log(args ...interface{})
+	Logf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	Fail()
+	FailNow()
+	Failed() bool
+	Cleanup(f func())
+}
+
+// T is the subset of testing.T used by Run.
+type T interface {
+	Name() string
+	Helper()
+	Log(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// Run runs body up to maxAttempts times, until an attempt passes. Each attempt
+// runs with its own TestingT, so a failed attempt does not fail t. The test
+// only fails if every attempt fails, and then the output of every attempt is
+// included in the failure. The output of a passing attempt is logged to t.
+//
+// If the environment variable GOTESTTOOLS_FLAKY_REPORT is set, the result is
+// appended to the file it names. See Report.
+func Run(t T, maxAttempts int, body func(t TestingT)) {
+	t.Helper()
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	report := Report{Test: t.Name()}
+	var failures []string
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		at := runAttempt(t.Name(), body)
+		report.Attempts = attempt
+		if !at.Failed() {
+			report.Passed = true
+			if attempt > 1 {
+				t.Log(fmt.Sprintf("passed on attempt %d of %d", attempt, maxAttempts))
+			}
+			for _, line := range at.output() {
+				t.Log(line)
+			}
+			break
+		}
+		report.Failures = append(report.Failures, at.summary())
+		failures = append(failures, fmt.Sprintf("attempt %d of %d:\n%s",
+			attempt, maxAttempts, indent(strings.Join(at.output(), "\n"))))
+	}
+
+	if err := writeReport(report); err != nil {
+		t.Log(fmt.Sprintf("failed to write flaky test report: %s", err))
+	}
+	if !report.Passed {
+		t.Errorf("failed %d of %d attempts:\n%s",
+			maxAttempts, maxAttempts, strings.Join(failures, "\n"))
+	}
+}
+
+func runAttempt(name string, body func(t TestingT)) *attemptT {
+	at := &attemptT{name: name}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer at.runCleanups()
+		defer func() {
+			if r := recover(); r != nil {
+				at.Errorf("panic: %v", r)
+			}
+		}()
+		body(at)
+	}()
+	<-done
+	return at
+}
+
+// attemptT records the output and failures of one attempt.
+type attemptT struct {
+	name     string
+	mu       sync.Mutex
+	failed   bool
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+var _ TestingT = &attemptT{}
+
+func (t *attemptT) Name() string {
+	return t.name
+}
+
+func (t *attemptT) Helper() {}
+
+func (t *attemptT) log(message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.logs = append(t.logs, message)
+}
+
+func (t *attemptT) Log(args ...interface{}) {
+	t.log(strings.TrimSuffix(fmt.Sprintln(args...), "\n"))
+}
+
+func (t *attemptT) Logf(format string, args ...interface{}) {
+	t.log(fmt.Sprintf(format, args...))
+}
+
+func (t *attemptT) Error(args ...interface{}) {
+	t.Log(args...)
+	t.fail(fmt.Sprint(args...))
+}
+
+func (t *attemptT) Errorf(format string, args ...interface{}) {
+	t.Logf(format, args...)
+	t.fail(fmt.Sprintf(format, args...))
+}
+
+func (t *attemptT) Fatal(args ...interface{}) {
+	t.Error(args...)
+	t.FailNow()
+}
+
+func (t *attemptT) Fatalf(format string, args ...interface{}) {
+	t.Errorf(format, args...)
+	t.FailNow()
+}
+
+func (t *attemptT) fail(message string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+	t.errors = append(t.errors, message)
+}
+
+func (t *attemptT) Fail() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+}
+
+// FailNow marks the attempt as failed and stops it. Like testing.T.FailNow it
+// must be called from the goroutine running the attempt.
+func (t *attemptT) FailNow() {
+	t.Fail()
+	runtime.Goexit()
+}
+
+func (t *attemptT) Failed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failed
+}
+
+func (t *attemptT) Cleanup(f func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *attemptT) runCleanups() {
+	t.mu.Lock()
+	cleanups := t.cleanups
+	t.cleanups = nil
+	t.mu.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
+
+func (t *attemptT) output() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.logs...)
+}
+
+// summary returns the first error of the attempt, for the report.
+func (t *attemptT) summary() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.errors) == 0 {
+		return "failed"
+	}
+	return t.errors[0]
+}
+
+func indent(s string) string {
+	return "    " + strings.Replace(s, "\n", "\n    ", -1)
+}
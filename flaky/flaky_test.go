@@ -0,0 +1,112 @@
+package flaky
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+type fakeT struct {
+	logs   []string
+	failed string
+}
+
+func (t *fakeT) Name() string { return "TestFake" }
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func TestRunPassesAfterRetry(t *testing.T) {
+	ft := &fakeT{}
+	attempts := 0
+	cleanups := 0
+	Run(ft, 3, func(t TestingT) {
+		attempts++
+		t.Cleanup(func() { cleanups++ })
+		t.Logf("attempt %d", attempts)
+		assert.Assert(t, attempts == 2, "not yet")
+	})
+
+	assert.Equal(t, attempts, 2)
+	assert.Equal(t, cleanups, 2)
+	assert.Equal(t, ft.failed, "")
+	assert.DeepEqual(t, ft.logs, []string{"passed on attempt 2 of 3", "attempt 2"})
+}
+
+func TestRunFailsAllAttempts(t *testing.T) {
+	ft := &fakeT{}
+	attempts := 0
+	Run(ft, 2, func(t TestingT) {
+		attempts++
+		t.Errorf("broke on %d", attempts)
+		t.FailNow()
+		t.Log("not reached")
+	})
+
+	assert.Equal(t, attempts, 2)
+	expected := `failed 2 of 2 attempts:
+attempt 1 of 2:
+    broke on 1
+attempt 2 of 2:
+    broke on 2`
+	assert.Equal(t, ft.failed, expected)
+}
+
+func TestRunRecoversPanic(t *testing.T) {
+	ft := &fakeT{}
+	Run(ft, 1, func(t TestingT) {
+		panic("boom")
+	})
+	assert.Assert(t, cmp.Contains(ft.failed, "panic: boom"))
+}
+
+func TestRunWritesReport(t *testing.T) {
+	dir := fs.NewDir(t, "test-flaky-report")
+	defer dir.Remove()
+	path := dir.Join("report.jsonl")
+	defer env.Patch(t, ReportEnvVar, path)()
+
+	attempts := 0
+	Run(&fakeT{}, 3, func(t TestingT) {
+		attempts++
+		if attempts == 1 {
+			t.Fatal("first attempt")
+		}
+	})
+	Run(&fakeT{}, 1, func(t TestingT) {
+		t.Fail()
+	})
+
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	var reports []Report
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		var report Report
+		assert.NilError(t, json.Unmarshal([]byte(line), &report))
+		reports = append(reports, report)
+	}
+	assert.DeepEqual(t, reports, []Report{
+		{Test: "TestFake", Attempts: 2, Passed: true, Failures: []string{"first attempt"}},
+		{Test: "TestFake", Attempts: 1, Failures: []string{"failed"}},
+	})
+}
+
+func TestRunWithTestingT(t *testing.T) {
+	attempts := 0
+	Run(t, 3, func(t TestingT) {
+		attempts++
+		assert.Check(t, attempts > 1)
+	})
+}
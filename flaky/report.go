@@ -0,0 +1,49 @@
+package flaky
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// ReportEnvVar is the name of the environment variable which sets the path of
+// the report file written by Run.
+const ReportEnvVar = "GOTESTTOOLS_FLAKY_REPORT"
+
+// Report is the result of one call to Run. Reports are appended to the report
+// file as JSON, one per line.
+type Report struct {
+	// Test is the name of the test.
+	Test string `json:"test"`
+	// Attempts is the number of attempts which were run.
+	Attempts int `json:"attempts"`
+	// Passed is true if one of the attempts passed.
+	Passed bool `json:"passed"`
+	// Failures is the first error from each failed attempt.
+	Failures []string `json:"failures,omitempty"`
+}
+
+var reportMu sync.Mutex
+
+func writeReport(report Report) error {
+	path := os.Getenv(ReportEnvVar)
+	if path == "" {
+		return nil
+	}
+	line, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	reportMu.Lock()
+	defer reportMu.Unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
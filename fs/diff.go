@@ -0,0 +1,81 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DiffKind is the kind of difference between a directory and a Manifest.
+type DiffKind string
+
+const (
+	// DiffAdded is a file, symlink, or directory which is in the directory,
+	// but not in the Manifest.
+	DiffAdded DiffKind = "added"
+	// DiffRemoved is a file, symlink, or directory which is in the Manifest,
+	// but not in the directory.
+	DiffRemoved DiffKind = "removed"
+	// DiffModified is a file, symlink, or directory which is in both, but
+	// has a different attribute.
+	DiffModified DiffKind = "modified"
+)
+
+// DiffEntry is a difference between a directory and a Manifest.
+type DiffEntry struct {
+	// Path is the path of the file, symlink, or directory, relative to the
+	// root of the directory. It starts with a path separator, as in the
+	// failure message of Equal.
+	Path string
+	Kind DiffKind
+	// Attribute is the property which differs when Kind is DiffModified. It
+	// is one of "type", "content", "mode", "uid", "gid", "mtime", "xattrs",
+	// "target", or "hardlink".
+	Attribute string
+	// Message describes the difference, in the format used by Equal.
+	Message string
+}
+
+// Diff compares the directory at path to the expected structure described by
+// a manifest, and returns the differences, sorted by path. Diff returns an
+// error if the directory can not be read. Equal formats the differences
+// returned by Diff into its failure message.
+func Diff(path string, expected Manifest) ([]DiffEntry, error) {
+	failures, err := diffFailures(path, expected)
+	if err != nil {
+		return nil, err
+	}
+	var entries []DiffEntry
+	for _, f := range failures {
+		for _, p := range f.problems {
+			entryPath := f.path
+			if p.name != "" {
+				entryPath = filepath.Join(f.path, p.name)
+			}
+			entries = append(entries, DiffEntry{
+				Path:      entryPath,
+				Kind:      p.kind,
+				Attribute: p.attribute,
+				Message:   p.message,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+func diffFailures(path string, expected Manifest) ([]failure, error) {
+	actual, err := manifestFromDir(path)
+	if err != nil {
+		return nil, err
+	}
+	// The modification time of the root directory changes whenever an entry
+	// is added or removed, so it is never compared.
+	root := *expected.root
+	root.mtime = time.Time{}
+	failures := eqDirectory(string(os.PathSeparator), &root, actual.root)
+	return append(failures, eqHardlinks(&root, actual.root)...), nil
+}
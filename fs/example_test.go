@@ -68,3 +68,9 @@ func ExampleApply() {
 
 	fs.Apply(t, dir, fs.WithFile("file1", "content\n"))
 }
+
+// Compare a directory to a fixture directory, which is updated by -update
+func ExampleMatchesGoldenDir() {
+	path := operationWhichCreatesFiles()
+	assert.Assert(t, fs.MatchesGoldenDir(path, "testdata/expected"))
+}
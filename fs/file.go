@@ -8,11 +8,10 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strings"
 
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/internal/cleanup"
+	"gotest.tools/v3/testname"
 )
 
 // Path objects return their filesystem path. Path may be implemented by a
@@ -39,6 +38,8 @@ type helperT interface {
 
 // NewFile creates a new file in a temporary directory using prefix as part of
 // the filename. The PathOps are applied to the before returning the File.
+// The prefix is converted to a safe filename by testname.Sanitize, so the name
+// of a test, or subtest, can be used as the prefix.
 //
 // When used with Go 1.14+ the file will be automatically removed when the test
 // ends, unless the TEST_NOCLEANUP env var is set to true.
@@ -46,7 +47,7 @@ func NewFile(t assert.TestingT, prefix string, ops ...PathOp) *File {
 	if ht, ok := t.(helperT); ok {
 		ht.Helper()
 	}
-	tempfile, err := ioutil.TempFile("", cleanPrefix(prefix)+"-")
+	tempfile, err := ioutil.TempFile("", testname.Sanitize(prefix)+"-")
 	assert.NilError(t, err)
 
 	file := &File{path: tempfile.Name()}
@@ -57,14 +58,6 @@ func NewFile(t assert.TestingT, prefix string, ops ...PathOp) *File {
 	return file
 }
 
-func cleanPrefix(prefix string) string {
-	// windows requires both / and \ are replaced
-	if runtime.GOOS == "windows" {
-		prefix = strings.Replace(prefix, string(os.PathSeparator), "-", -1)
-	}
-	return strings.Replace(prefix, "/", "-", -1)
-}
-
 // Path returns the full path to the file
 func (f *File) Path() string {
 	return f.path
@@ -82,7 +75,8 @@ type Dir struct {
 }
 
 // NewDir returns a new temporary directory using prefix as part of the directory
-// name. The PathOps are applied before returning the Dir.
+// name. The PathOps are applied before returning the Dir. The prefix is
+// converted to a safe filename by testname.Sanitize.
 //
 // When used with Go 1.14+ the directory will be automatically removed when the test
 // ends, unless the TEST_NOCLEANUP env var is set to true.
@@ -90,7 +84,7 @@ func NewDir(t assert.TestingT, prefix string, ops ...PathOp) *Dir {
 	if ht, ok := t.(helperT); ok {
 		ht.Helper()
 	}
-	path, err := ioutil.TempDir("", cleanPrefix(prefix)+"-")
+	path, err := ioutil.TempDir("", testname.Sanitize(prefix)+"-")
 	assert.NilError(t, err)
 	dir := &Dir{path: path}
 	cleanup.Cleanup(t, dir.Remove)
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -64,6 +65,12 @@ func TestNewFile(t *testing.T) {
 		_, err = os.Stat(tmpFile.Path())
 		assert.ErrorType(t, err, os.IsNotExist)
 	})
+
+	t.Run("with special characters: *?", func(t *testing.T) {
+		tmpFile := fs.NewFile(t, t.Name())
+		base := filepath.Base(tmpFile.Path())
+		assert.Assert(t, strings.HasPrefix(base, "TestNewFile-with_special_characters___-"), base)
+	})
 }
 
 func TestNewFile_IntegrationWithCleanup(t *testing.T) {
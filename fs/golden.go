@@ -0,0 +1,64 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/source"
+)
+
+// MatchesGoldenDir compares the directory at path to the golden directory
+// goldenDir, and returns success if they contain the same files, directories,
+// and symlinks, with the same content. File modes, modification times,
+// extended attributes, and hard links are not compared, because most version
+// control systems do not preserve them, and carriage returns in file content
+// are ignored.
+//
+// Running `go test pkgname -update` replaces goldenDir with a copy of the
+// directory at path, adding and removing files so that goldenDir matches the
+// actual state.
+//
+// MatchesGoldenDir is a cmp.Comparison which can be used with assert.Assert().
+func MatchesGoldenDir(path, goldenDir string) cmp.Comparison {
+	return func() cmp.Result {
+		if source.Update {
+			if err := updateGoldenDir(path, goldenDir); err != nil {
+				return cmp.ResultFromError(err)
+			}
+		}
+		expected, err := manifestFromDir(goldenDir)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		ignoreUnversioned(expected.root)
+		postamble := fmt.Sprintf("\nYou can run 'go test . -update' to automatically update %s to the new expected value.",
+			goldenDir)
+		return equal(path, expected, postamble)
+	}
+}
+
+func updateGoldenDir(path, goldenDir string) error {
+	if err := os.RemoveAll(goldenDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(goldenDir, 0755); err != nil {
+		return err
+	}
+	return copyDirectory(path, goldenDir)
+}
+
+// ignoreUnversioned updates the manifest of a golden directory to ignore the
+// properties which are not stored by version control.
+func ignoreUnversioned(root *directory) {
+	walkResources(root, func(r *resource) {
+		r.mode = anyFileMode
+		r.mtime = time.Time{}
+		r.xattrs = nil
+	})
+	walkFiles(root, "", func(_ string, f *file) {
+		f.ignoreCariageReturn = true
+		f.hardlink = ""
+	})
+}
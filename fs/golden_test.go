@@ -0,0 +1,73 @@
+package fs
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/internal/source"
+)
+
+func TestMatchesGoldenDir(t *testing.T) {
+	goldenDir := NewDir(t, "golden",
+		WithFile("a.txt", "a\n", WithMode(0600)),
+		WithDir("sub", WithFile("b.txt", "b\n")))
+	defer goldenDir.Remove()
+	actual := NewDir(t, "actual",
+		WithFile("a.txt", "a\r\n", WithMode(0644)),
+		WithDir("sub", WithMode(0700), WithFile("b.txt", "b\n")))
+	defer actual.Remove()
+
+	assert.Assert(t, MatchesGoldenDir(actual.Path(), goldenDir.Path()))
+}
+
+func TestMatchesGoldenDirFailure(t *testing.T) {
+	goldenDir := NewDir(t, "golden",
+		WithFile("a.txt", "a\n"),
+		WithFile("removed.txt", ""))
+	defer goldenDir.Remove()
+	actual := NewDir(t, "actual",
+		WithFile("a.txt", "changed\n"),
+		WithFile("added.txt", ""))
+	defer actual.Remove()
+
+	result := MatchesGoldenDir(actual.Path(), goldenDir.Path())()
+	assert.Assert(t, !result.Success())
+	expected := `directory ` + actual.Path() + ` does not match expected:
+/
+  removed.txt: expected file to exist
+  added.txt: unexpected file
+/a.txt
+  content:
+    --- expected
+    +++ actual
+    @@ -1,2 +1,2 @@
+    -a
+    +changed
+     
+
+You can run 'go test . -update' to automatically update ` + goldenDir.Path() + ` to the new expected value.`
+	assert.Equal(t, result.(cmpFailure).FailureMessage(), expected)
+}
+
+func TestMatchesGoldenDir_UpdateGolden(t *testing.T) {
+	goldenDir := NewDir(t, "golden",
+		WithFile("a.txt", "a\n"),
+		WithFile("removed.txt", ""))
+	defer goldenDir.Remove()
+	actual := NewDir(t, "actual",
+		WithFile("a.txt", "changed\n"),
+		WithDir("sub", WithFile("added.txt", "added")))
+	defer actual.Remove()
+
+	orig := source.Update
+	source.Update = true
+	defer func() { source.Update = orig }()
+
+	assert.Assert(t, MatchesGoldenDir(actual.Path(), goldenDir.Path()))
+	source.Update = orig
+	assert.Assert(t, MatchesGoldenDir(actual.Path(), goldenDir.Path()))
+	assert.Assert(t, Equal(goldenDir.Path(), Expected(t,
+		WithFile("a.txt", "changed\n", MatchAnyFileMode),
+		WithDir("sub", MatchAnyFileMode, WithFile("added.txt", "added", MatchAnyFileMode)),
+		MatchAnyFileMode)))
+}
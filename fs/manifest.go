@@ -6,6 +6,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -20,6 +23,11 @@ type resource struct {
 	mode os.FileMode
 	uid  uint32
 	gid  uint32
+	// mtime is the modification time. The zero value matches any time.
+	mtime time.Time
+	// xattrs are the extended attributes in the user namespace. A nil map
+	// matches any attributes.
+	xattrs map[string][]byte
 }
 
 type file struct {
@@ -27,6 +35,18 @@ type file struct {
 	content             io.ReadCloser
 	ignoreCariageReturn bool
 	compareContentFunc  func(b []byte) CompareResult
+	// id identifies the file on the filesystem when it has more than one
+	// hard link. It is only set for files read from a directory.
+	id fileID
+	// hardlink is the slash separated path, relative to the root of the
+	// manifest, of a file which must be the same file as this one.
+	hardlink string
+}
+
+// fileID is the device and inode of a file.
+type fileID struct {
+	dev uint64
+	ino uint64
 }
 
 func (f *file) Type() string {
@@ -57,15 +77,26 @@ type dirEntry interface {
 }
 
 // ManifestFromDir creates a Manifest by reading the directory at path. The
-// manifest stores the structure and properties of files in the directory.
-// ManifestFromDir can be used with Equal to compare two directories.
-func ManifestFromDir(t assert.TestingT, path string) Manifest {
+// manifest stores the structure and properties of files in the directory,
+// including which files are hard links to the same file. ManifestFromDir can
+// be used with Equal to compare two directories.
+//
+// The PathOps are applied to the root directory of the manifest. Modification
+// times and extended attributes are not compared unless CompareModTimes or
+// CompareXattrs is used.
+func ManifestFromDir(t assert.TestingT, path string, ops ...PathOp) Manifest {
 	if ht, ok := t.(helperT); ok {
 		ht.Helper()
 	}
 
 	manifest, err := manifestFromDir(path)
 	assert.NilError(t, err)
+	walkResources(manifest.root, func(r *resource) {
+		r.mtime = time.Time{}
+		r.xattrs = nil
+	})
+	root := &directoryPath{directory: manifest.root, source: path}
+	assert.NilError(t, applyPathOps(root, ops))
 	return manifest
 }
 
@@ -79,7 +110,56 @@ func manifestFromDir(path string) (Manifest, error) {
 	}
 
 	directory, err := newDirectory(path, info)
-	return Manifest{root: directory}, err
+	if err != nil {
+		return Manifest{}, err
+	}
+	linkFiles(directory)
+	return Manifest{root: directory}, nil
+}
+
+// linkFiles sets the hardlink of each file in the tree which is the same file
+// as another file, to the path of the first of those files.
+func linkFiles(root *directory) {
+	paths := make(map[fileID][]string)
+	walkFiles(root, "", func(path string, f *file) {
+		if f.id != (fileID{}) {
+			paths[f.id] = append(paths[f.id], path)
+		}
+	})
+	for _, group := range paths {
+		sort.Strings(group)
+		for _, path := range group[1:] {
+			entry, _ := lookupEntry(root, path)
+			entry.(*file).hardlink = group[0]
+		}
+	}
+}
+
+// walkFiles calls fn with the slash separated path of each file in the tree.
+func walkFiles(dir *directory, prefix string, fn func(path string, f *file)) {
+	for name, entry := range dir.items {
+		switch typed := entry.(type) {
+		case *file:
+			fn(prefix+name, typed)
+		case *directory:
+			walkFiles(typed, prefix+name+"/", fn)
+		}
+	}
+}
+
+// lookupEntry returns the entry at the slash separated path in the tree.
+func lookupEntry(root *directory, path string) (dirEntry, bool) {
+	var entry dirEntry = root
+	for _, name := range strings.Split(path, "/") {
+		dir, ok := entry.(*directory)
+		if !ok {
+			return nil, false
+		}
+		if entry, ok = dir.items[name]; !ok {
+			return nil, false
+		}
+	}
+	return entry, true
 }
 
 func newDirectory(path string, info os.FileInfo) (*directory, error) {
@@ -96,13 +176,27 @@ func newDirectory(path string, info os.FileInfo) (*directory, error) {
 		}
 	}
 
+	res, err := newResourceWithAttributes(path, info)
+	if err != nil {
+		return nil, err
+	}
 	return &directory{
-		resource:      newResourceFromInfo(info),
+		resource:      res,
 		items:         items,
 		filepathGlobs: make(map[string]*filePath),
 	}, nil
 }
 
+// newResourceWithAttributes returns the resource for info, with the
+// modification time and extended attributes of path.
+func newResourceWithAttributes(path string, info os.FileInfo) (resource, error) {
+	res := newResourceFromInfo(info)
+	res.mtime = info.ModTime()
+	xattrs, err := listXattrs(path)
+	res.xattrs = xattrs
+	return res, err
+}
+
 func getTypedResource(path string, info os.FileInfo) (dirEntry, error) {
 	switch {
 	case info.IsDir():
@@ -128,12 +222,17 @@ func newSymlink(path string, info os.FileInfo) (*symlink, error) {
 
 func newFile(path string, info os.FileInfo) (*file, error) {
 	// TODO: defer file opening to reduce number of open FDs?
+	res, err := newResourceWithAttributes(path, info)
+	if err != nil {
+		return nil, err
+	}
 	readCloser, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	return &file{
-		resource: newResourceFromInfo(info),
+		resource: res,
 		content:  readCloser,
+		id:       fileIDFromInfo(info),
 	}, err
 }
@@ -0,0 +1,30 @@
+//go:build js || wasip1 || plan9
+// +build js wasip1 plan9
+
+package fs
+
+import "os"
+
+const defaultRootDirMode = os.ModeDir | 0700
+
+var defaultSymlinkMode = os.ModeSymlink | 0777
+
+// newResourceFromInfo only records the mode, because the owner of the file is
+// not available from os.FileInfo on this platform.
+func newResourceFromInfo(info os.FileInfo) resource {
+	return resource{mode: info.Mode()}
+}
+
+// fileIDFromInfo returns the zero fileID, because the inode of the file is not
+// available from os.FileInfo on this platform, so hard links are not detected.
+func fileIDFromInfo(info os.FileInfo) fileID {
+	return fileID{}
+}
+
+func (p *filePath) SetMode(mode os.FileMode) {
+	p.file.mode = mode
+}
+
+func (p *directoryPath) SetMode(mode os.FileMode) {
+	p.directory.mode = mode | os.ModeDir
+}
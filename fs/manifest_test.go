@@ -87,7 +87,7 @@ func TestSymlinks(t *testing.T) {
 }
 
 var cmpManifest = cmp.Options{
-	cmp.AllowUnexported(Manifest{}, resource{}, file{}, symlink{}, directory{}),
+	cmp.AllowUnexported(Manifest{}, resource{}, file{}, symlink{}, directory{}, fileID{}),
 	cmp.Comparer(func(x, y io.ReadCloser) bool {
 		if x == nil || y == nil {
 			return x == y
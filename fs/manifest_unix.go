@@ -1,5 +1,5 @@
-//go:build !windows
-// +build !windows
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
 
 package fs
 
@@ -29,6 +29,14 @@ func newResourceFromInfo(info os.FileInfo) resource {
 	}
 }
 
+func fileIDFromInfo(info os.FileInfo) fileID {
+	statT := info.Sys().(*syscall.Stat_t)
+	if statT.Nlink < 2 {
+		return fileID{}
+	}
+	return fileID{dev: uint64(statT.Dev), ino: uint64(statT.Ino)}
+}
+
 func (p *filePath) SetMode(mode os.FileMode) {
 	p.file.mode = mode
 }
@@ -11,6 +11,12 @@ func newResourceFromInfo(info os.FileInfo) resource {
 	return resource{mode: info.Mode()}
 }
 
+// fileIDFromInfo returns the zero fileID, because os.FileInfo does not
+// include the file index on windows, so hard links are not detected.
+func fileIDFromInfo(info os.FileInfo) fileID {
+	return fileID{}
+}
+
 func (p *filePath) SetMode(mode os.FileMode) {
 	bits := mode & 0600
 	p.file.mode = bits + bits/010 + bits/0100
@@ -25,6 +25,8 @@ type manifestResource interface {
 	SetMode(mode os.FileMode)
 	SetUID(uid uint32)
 	SetGID(gid uint32)
+	SetModTime(mtime time.Time)
+	SetXattr(name string, value []byte)
 }
 
 type manifestFile interface {
@@ -35,6 +37,7 @@ type manifestFile interface {
 type manifestDirectory interface {
 	manifestResource
 	AddSymlink(path, target string) error
+	AddHardlink(path, target string) error
 	AddFile(path string, ops ...PathOp) error
 	AddDirectory(path string, ops ...PathOp) error
 }
@@ -252,24 +255,47 @@ func WithSymlink(path, target string) PathOp {
 // WithHardlink creates a link in the directory which links to target.
 // Target must be a path relative to the directory.
 //
+// When used with a Manifest, target is a slash separated path relative to the
+// root of the manifest, and the file at path is expected to be the same file
+// as target. The content and mode of path are not compared, because they are
+// the content and mode of target.
+//
 // Note: the argument order is the inverse of os.Link to be consistent with
 // the other functions in this package.
 func WithHardlink(path, target string) PathOp {
 	return func(root Path) error {
-		if _, ok := root.(manifestDirectory); ok {
-			return fmt.Errorf("WithHardlink not implemented for manifests")
+		if v, ok := root.(manifestDirectory); ok {
+			return v.AddHardlink(path, target)
 		}
 		return os.Link(filepath.Join(root.Path(), target), filepath.Join(root.Path(), path))
 	}
 }
 
 // WithTimestamps sets the access and modification times of the file system object
-// at path.
+// at path. When used with a Manifest only the modification time is expected,
+// because the access time changes when the file is read.
 func WithTimestamps(atime, mtime time.Time) PathOp {
 	return func(root Path) error {
-		if _, ok := root.(manifestDirectory); ok {
-			return fmt.Errorf("WithTimestamp not implemented for manifests")
+		if m, ok := root.(manifestResource); ok {
+			m.SetModTime(mtime)
+			return nil
 		}
 		return os.Chtimes(root.Path(), atime, mtime)
 	}
 }
+
+// WithXattr sets the extended attribute name of the file or directory at path
+// to value. Only attributes in the user namespace, such as "user.checksum",
+// are stored in a Manifest. Extended attributes are only supported on linux.
+//
+// When used with a Manifest the file or directory is expected to have exactly
+// the attributes set by WithXattr.
+func WithXattr(name, value string) PathOp {
+	return func(root Path) error {
+		if m, ok := root.(manifestResource); ok {
+			m.SetXattr(name, []byte(value))
+			return nil
+		}
+		return setXattr(root.Path(), name, []byte(value))
+	}
+}
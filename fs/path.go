@@ -5,6 +5,8 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -36,9 +38,20 @@ func (p *filePath) SetGID(gid uint32) {
 	p.file.gid = gid
 }
 
+func (p *filePath) SetModTime(mtime time.Time) {
+	p.file.mtime = mtime
+}
+
+func (p *filePath) SetXattr(name string, value []byte) {
+	p.file.xattrs = setXattrValue(p.file.xattrs, name, value)
+}
+
 type directoryPath struct {
 	resourcePath
 	directory *directory
+	// source is the path of the directory read by ManifestFromDir, or empty
+	// if the manifest was not read from the filesystem.
+	source string
 }
 
 func (p *directoryPath) SetUID(uid uint32) {
@@ -49,6 +62,31 @@ func (p *directoryPath) SetGID(gid uint32) {
 	p.directory.gid = gid
 }
 
+func (p *directoryPath) SetModTime(mtime time.Time) {
+	p.directory.mtime = mtime
+}
+
+func (p *directoryPath) SetXattr(name string, value []byte) {
+	p.directory.xattrs = setXattrValue(p.directory.xattrs, name, value)
+}
+
+func setXattrValue(xattrs map[string][]byte, name string, value []byte) map[string][]byte {
+	if xattrs == nil {
+		xattrs = make(map[string][]byte)
+	}
+	xattrs[name] = value
+	return xattrs
+}
+
+func (p *directoryPath) AddHardlink(path, target string) error {
+	p.directory.items[path] = &file{
+		resource: newResource(anyFileMode),
+		content:  anyFileContent,
+		hardlink: target,
+	}
+	return nil
+}
+
 func (p *directoryPath) AddSymlink(path, target string) error {
 	p.directory.items[path] = &symlink{
 		resource: newResource(defaultSymlinkMode),
@@ -197,3 +235,108 @@ func MatchAnyFileMode(path Path) error {
 	}
 	return nil
 }
+
+// MatchAnyFileModTime is a PathOp that updates a Manifest so that the file or
+// directory at path will match any modification time. When path is a
+// directory, every file and directory in it will also match any modification
+// time.
+func MatchAnyFileModTime(path Path) error {
+	switch m := path.(type) {
+	case *filePath:
+		m.file.mtime = time.Time{}
+	case *directoryPath:
+		walkResources(m.directory, func(r *resource) {
+			r.mtime = time.Time{}
+		})
+	}
+	return nil
+}
+
+// MatchAnyXattrs is a PathOp that updates a Manifest so that the file or
+// directory at path will match any extended attributes. When path is a
+// directory, every file and directory in it will also match any extended
+// attributes.
+func MatchAnyXattrs(path Path) error {
+	switch m := path.(type) {
+	case *filePath:
+		m.file.xattrs = nil
+	case *directoryPath:
+		walkResources(m.directory, func(r *resource) {
+			r.xattrs = nil
+		})
+	}
+	return nil
+}
+
+// CompareModTimes is a PathOp for ManifestFromDir which records the
+// modification time of every file and directory in the manifest, so that Equal
+// compares them. The modification time of the root directory is never
+// compared. CompareModTimes has no effect on other manifests.
+func CompareModTimes(path Path) error {
+	return readAttributes(path, func(filename string, r *resource) error {
+		info, err := os.Lstat(filename)
+		if err != nil {
+			return err
+		}
+		r.mtime = info.ModTime()
+		return nil
+	})
+}
+
+// CompareXattrs is a PathOp for ManifestFromDir which records the extended
+// attributes in the user namespace of every file and directory in the
+// manifest, so that Equal compares them. CompareXattrs has no effect on other
+// manifests.
+func CompareXattrs(path Path) error {
+	return readAttributes(path, func(filename string, r *resource) error {
+		xattrs, err := listXattrs(filename)
+		r.xattrs = xattrs
+		return err
+	})
+}
+
+// readAttributes calls fn with the filename and resource of every file and
+// directory below the root of a manifest read by ManifestFromDir.
+func readAttributes(path Path, fn func(filename string, r *resource) error) error {
+	m, ok := path.(*directoryPath)
+	if !ok || m.source == "" {
+		return nil
+	}
+	return walkEntries(m.directory, m.source, fn)
+}
+
+func walkEntries(dir *directory, path string, fn func(filename string, r *resource) error) error {
+	for name, entry := range dir.items {
+		filename := filepath.Join(path, name)
+		switch typed := entry.(type) {
+		case *file:
+			if err := fn(filename, &typed.resource); err != nil {
+				return err
+			}
+		case *directory:
+			if err := fn(filename, &typed.resource); err != nil {
+				return err
+			}
+			if err := walkEntries(typed, filename, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkResources calls fn with the resource of dir, and of every file,
+// symlink, and directory in it.
+func walkResources(dir *directory, fn func(r *resource)) {
+	fn(&dir.resource)
+	for _, entry := range dir.items {
+		switch typed := entry.(type) {
+		case *file:
+			fn(&typed.resource)
+		case *symlink:
+			fn(&typed.resource)
+		case *directory:
+			walkResources(typed, fn)
+		}
+	}
+}
@@ -19,39 +19,71 @@ import (
 // will contain all the differences between the directory structure and the
 // expected structure defined by the Manifest.
 //
-// Equal is a cmp.Comparison which can be used with assert.Assert().
+// Equal is a cmp.Comparison which can be used with assert.Assert(). Use Diff
+// to get the differences as structured values.
 func Equal(path string, expected Manifest) cmp.Comparison {
 	return func() cmp.Result {
-		actual, err := manifestFromDir(path)
-		if err != nil {
-			return cmp.ResultFromError(err)
-		}
-		failures := eqDirectory(string(os.PathSeparator), expected.root, actual.root)
-		if len(failures) == 0 {
-			return cmp.ResultSuccess
-		}
-		msg := fmt.Sprintf("directory %s does not match expected:\n", path)
-		return cmp.ResultFailure(msg + formatFailures(failures))
+		return equal(path, expected, "")
 	}
 }
 
+func equal(path string, expected Manifest, postamble string) cmp.Result {
+	failures, err := diffFailures(path, expected)
+	if err != nil {
+		return cmp.ResultFromError(err)
+	}
+	if len(failures) == 0 {
+		return cmp.ResultSuccess
+	}
+	msg := fmt.Sprintf("directory %s does not match expected:\n", path)
+	return cmp.ResultFailure(msg + formatFailures(failures) + postamble)
+}
+
 type failure struct {
 	path     string
 	problems []problem
 }
 
-type problem string
+type problem struct {
+	kind DiffKind
+	// name is the name of an entry in the directory, when the problem is
+	// reported on a directory about one of its entries.
+	name      string
+	attribute string
+	message   string
+}
 
 func notEqual(property string, x, y interface{}) problem {
-	return problem(fmt.Sprintf("%s: expected %s got %s", property, x, y))
+	return problem{
+		kind:      DiffModified,
+		attribute: property,
+		message:   fmt.Sprintf("%s: expected %s got %s", property, x, y),
+	}
 }
 
-func errProblem(reason string, err error) problem {
-	return problem(fmt.Sprintf("%s: %s", reason, err))
+func typeProblem(name string, x, y string) problem {
+	return problem{
+		kind:      DiffModified,
+		name:      name,
+		attribute: "type",
+		message:   fmt.Sprintf("%s: expected %s got %s", name, x, y),
+	}
 }
 
-func existenceProblem(filename, reason string, args ...interface{}) problem {
-	return problem(filename + ": " + fmt.Sprintf(reason, args...))
+func errProblem(attribute, reason string, err error) problem {
+	return problem{
+		kind:      DiffModified,
+		attribute: attribute,
+		message:   fmt.Sprintf("%s: %s", reason, err),
+	}
+}
+
+func contentProblem(message string) problem {
+	return problem{kind: DiffModified, attribute: "content", message: "content: " + message}
+}
+
+func existenceProblem(kind DiffKind, name, reason string, args ...interface{}) problem {
+	return problem{kind: kind, name: name, message: name + ": " + fmt.Sprintf(reason, args...)}
 }
 
 func eqResource(x, y resource) []problem {
@@ -65,9 +97,77 @@ func eqResource(x, y resource) []problem {
 	if x.mode != anyFileMode && x.mode != y.mode {
 		p = append(p, notEqual("mode", x.mode, y.mode))
 	}
+	if !x.mtime.IsZero() && !x.mtime.Equal(y.mtime) {
+		p = append(p, notEqual("mtime", x.mtime, y.mtime))
+	}
+	if x.xattrs != nil && !equalXattrs(x.xattrs, y.xattrs) {
+		p = append(p, notEqual("xattrs", formatXattrs(x.xattrs), formatXattrs(y.xattrs)))
+	}
 	return p
 }
 
+func equalXattrs(x, y map[string][]byte) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for name, value := range x {
+		other, ok := y[name]
+		if !ok || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatXattrs(xattrs map[string][]byte) string {
+	names := make([]string, 0, len(xattrs))
+	for name := range xattrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = fmt.Sprintf("%s=%q", name, xattrs[name])
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
+
+// eqHardlinks compares the files in x which must be hard links to another
+// file, to the files at the same paths in y.
+func eqHardlinks(x, y *directory) []failure {
+	var f []failure
+	walkFiles(x, "", func(path string, xFile *file) {
+		if xFile.hardlink == "" {
+			return
+		}
+		yFile, _ := lookupFile(y, path)
+		yTarget, _ := lookupFile(y, xFile.hardlink)
+		if yFile == nil || yTarget == nil {
+			// A missing file is reported by eqDirectory.
+			return
+		}
+		if yFile.id == (fileID{}) || yFile.id != yTarget.id {
+			f = append(f, failure{
+				path: filepath.Join(string(os.PathSeparator), filepath.FromSlash(path)),
+				problems: []problem{{
+					kind:      DiffModified,
+					attribute: "hardlink",
+					message:   "expected a hard link to " + xFile.hardlink,
+				}},
+			})
+		}
+	})
+	return f
+}
+
+func lookupFile(root *directory, path string) (*file, bool) {
+	entry, ok := lookupEntry(root, path)
+	if !ok {
+		return nil, false
+	}
+	f, ok := entry.(*file)
+	return f, ok
+}
+
 func removeCarriageReturn(in []byte) []byte {
 	return bytes.Replace(in, []byte("\r\n"), []byte("\n"), -1)
 }
@@ -77,12 +177,12 @@ func eqFile(x, y *file) []problem {
 
 	switch {
 	case x.content == nil:
-		p = append(p, existenceProblem("content", "expected content is nil"))
+		p = append(p, contentProblem("expected content is nil"))
 		return p
 	case x.content == anyFileContent:
 		return p
 	case y.content == nil:
-		p = append(p, existenceProblem("content", "actual content is nil"))
+		p = append(p, contentProblem("actual content is nil"))
 		return p
 	}
 
@@ -92,10 +192,10 @@ func eqFile(x, y *file) []problem {
 	defer y.content.Close()
 
 	if xErr != nil {
-		p = append(p, errProblem("failed to read expected content", xErr))
+		p = append(p, errProblem("content", "failed to read expected content", xErr))
 	}
 	if yErr != nil {
-		p = append(p, errProblem("failed to read actual content", xErr))
+		p = append(p, errProblem("content", "failed to read actual content", xErr))
 	}
 	if xErr != nil || yErr != nil {
 		return p
@@ -104,7 +204,7 @@ func eqFile(x, y *file) []problem {
 	if x.compareContentFunc != nil {
 		r := x.compareContentFunc(yContent)
 		if !r.Success() {
-			p = append(p, existenceProblem("content", r.FailureMessage()))
+			p = append(p, contentProblem(r.FailureMessage()))
 		}
 		return p
 	}
@@ -130,7 +230,7 @@ func diffContent(x, y []byte) problem {
 	// Remove the trailing newline in the diff. A trailing newline is always
 	// added to a problem by formatFailures.
 	diff = strings.TrimSuffix(diff, "\n")
-	return problem("content:\n" + indent(diff, "    "))
+	return problem{kind: DiffModified, attribute: "content", message: "content:\n" + indent(diff, "    ")}
 }
 
 func indent(s, prefix string) string {
@@ -169,12 +269,12 @@ func eqDirectory(path string, x, y *directory) []failure {
 		xEntry := x.items[name]
 		yEntry, ok := y.items[name]
 		if !ok {
-			p = append(p, existenceProblem(name, "expected %s to exist", xEntry.Type()))
+			p = append(p, existenceProblem(DiffRemoved, name, "expected %s to exist", xEntry.Type()))
 			continue
 		}
 
 		if xEntry.Type() != yEntry.Type() {
-			p = append(p, notEqual(name, xEntry.Type(), yEntry.Type()))
+			p = append(p, typeProblem(name, xEntry.Type(), yEntry.Type()))
 			continue
 		}
 
@@ -194,7 +294,7 @@ func eqDirectory(path string, x, y *directory) []failure {
 	}
 	for _, name := range sortedKeys(y.items) {
 		if !matchedFiles[name] {
-			p = append(p, existenceProblem(name, "unexpected %s", y.items[name].Type()))
+			p = append(p, existenceProblem(DiffAdded, name, "unexpected %s", y.items[name].Type()))
 		}
 	}
 	return maybeAppendFailure(f, path, p)
@@ -247,7 +347,7 @@ func matchGlob(name string, yEntry dirEntry, globs map[string]*filePath) globMat
 	for glob, expectedFile := range globs {
 		ok, err := filepath.Match(glob, name)
 		if err != nil {
-			p := errProblem("failed to match glob pattern", err)
+			p := errProblem("", "failed to match glob pattern", err)
 			f := failure{path: name, problems: []problem{p}}
 			m.failures = append(m.failures, f)
 		}
@@ -269,7 +369,7 @@ func formatFailures(failures []failure) string {
 	for _, failure := range failures {
 		buf.WriteString(failure.path + "\n")
 		for _, problem := range failure.problems {
-			buf.WriteString("  " + string(problem) + "\n")
+			buf.WriteString("  " + problem.message + "\n")
 		}
 	}
 	return buf.String()
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
@@ -163,6 +164,52 @@ func TestEqualManyFailures(t *testing.T) {
 	assert.Equal(t, result.(cmpFailure).FailureMessage(), expected)
 }
 
+func TestDiff(t *testing.T) {
+	dir := NewDir(t, t.Name(),
+		WithFile("file1", "same in both"),
+		WithFile("extra", "some content"),
+		WithDir("typed"),
+		WithFile("mode", "", WithMode(0600)))
+	defer dir.Remove()
+
+	manifest := Expected(t,
+		WithDir("subdir",
+			WithFile("somefile", "")),
+		WithFile("file1", "same in both"),
+		WithFile("typed", ""),
+		WithFile("mode", "", WithMode(0644)))
+
+	entries, err := Diff(dir.Path(), manifest)
+	assert.NilError(t, err)
+
+	type entry struct {
+		Path      string
+		Kind      DiffKind
+		Attribute string
+	}
+	var actual []entry
+	for _, e := range entries {
+		actual = append(actual, entry{Path: e.Path, Kind: e.Kind, Attribute: e.Attribute})
+	}
+	sep := string(filepath.Separator)
+	expected := []entry{
+		{Path: sep + "extra", Kind: DiffAdded},
+		{Path: sep + "mode", Kind: DiffModified, Attribute: "mode"},
+		{Path: sep + "subdir", Kind: DiffRemoved},
+		{Path: sep + "typed", Kind: DiffModified, Attribute: "type"},
+	}
+	if runtime.GOOS == "windows" {
+		expected = append(expected[:1], expected[2:]...)
+	}
+	assert.DeepEqual(t, actual, expected)
+	assert.Equal(t, entries[0].Message, "extra: unexpected file")
+}
+
+func TestDiffMissingRoot(t *testing.T) {
+	_, err := Diff("/bogus/path/does/not/exist", Expected(t))
+	assert.Assert(t, err != nil)
+}
+
 type cmpFailure interface {
 	FailureMessage() string
 }
@@ -271,3 +318,97 @@ t.go
 		assert.Equal(t, result.(cmpFailure).FailureMessage(), expected)
 	})
 }
+
+func TestEqualModTime(t *testing.T) {
+	stamp := time.Date(2011, 11, 11, 5, 55, 55, 0, time.UTC)
+	dir := NewDir(t, t.Name(),
+		WithFile("a", "a", WithTimestamps(stamp, stamp)),
+		WithFile("b", "b"))
+	defer dir.Remove()
+
+	expected := Expected(t,
+		WithFile("a", "a", WithTimestamps(stamp, stamp)),
+		WithFile("b", "b"))
+	assert.Assert(t, Equal(dir.Path(), expected))
+
+	other := stamp.Add(time.Hour)
+	expected = Expected(t,
+		WithFile("a", "a", WithTimestamps(other, other)),
+		WithFile("b", "b"))
+	result := Equal(dir.Path(), expected)()
+	assert.Assert(t, !result.Success())
+	assert.Equal(t, result.(cmpFailure).FailureMessage(), fmt.Sprintf(`directory %s does not match expected:
+/a
+  mtime: expected %s got %s
+`, dir.Path(), other, stamp.Local()))
+}
+
+func TestEqualManifestFromDirModTime(t *testing.T) {
+	stamp := time.Date(2011, 11, 11, 5, 55, 55, 0, time.UTC)
+	src := NewDir(t, t.Name(), WithFile("a", "a", WithTimestamps(stamp, stamp)))
+	defer src.Remove()
+	copied := NewDir(t, t.Name(), FromDir(src.Path()))
+	defer copied.Remove()
+
+	assert.Assert(t, Equal(copied.Path(), ManifestFromDir(t, src.Path())))
+	assert.Assert(t, !Equal(copied.Path(), ManifestFromDir(t, src.Path(), CompareModTimes))().Success())
+
+	same := NewDir(t, t.Name(), WithFile("a", "a", WithTimestamps(stamp, stamp)))
+	defer same.Remove()
+	assert.Assert(t, Equal(same.Path(), ManifestFromDir(t, src.Path(), CompareModTimes)))
+}
+
+func TestEqualHardlinks(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "hard links are not detected on windows")
+	dir := NewDir(t, t.Name(),
+		WithFile("a", "content"),
+		WithDir("sub"),
+		WithHardlink("sub/b", "a"))
+	defer dir.Remove()
+	copied := NewDir(t, t.Name(), FromDir(dir.Path()))
+	defer copied.Remove()
+
+	expected := func() Manifest {
+		return Expected(t,
+			WithFile("a", "content"),
+			WithDir("sub", WithHardlink("b", "a")))
+	}
+	assert.Assert(t, Equal(dir.Path(), expected()))
+
+	result := Equal(copied.Path(), expected())()
+	assert.Assert(t, !result.Success())
+	assert.Equal(t, result.(cmpFailure).FailureMessage(), fmt.Sprintf(`directory %s does not match expected:
+/sub/b
+  expected a hard link to a
+`, copied.Path()))
+
+	manifest := ManifestFromDir(t, dir.Path())
+	assert.Assert(t, !Equal(copied.Path(), manifest)().Success())
+}
+
+func TestEqualXattrs(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux", "extended attributes are only supported on linux")
+	dir := NewDir(t, t.Name(), WithFile("a", "a"))
+	defer dir.Remove()
+	if err := WithXattr("user.checksum", "abc")(&File{path: dir.Join("a")}); err != nil {
+		t.Skipf("filesystem does not support extended attributes: %s", err)
+	}
+
+	expected := Expected(t, WithFile("a", "a", WithXattr("user.checksum", "abc")))
+	assert.Assert(t, Equal(dir.Path(), expected))
+	assert.Assert(t, Equal(dir.Path(), Expected(t, WithFile("a", "a"))))
+
+	expected = Expected(t, WithFile("a", "a", WithXattr("user.checksum", "def")))
+	result := Equal(dir.Path(), expected)()
+	assert.Assert(t, !result.Success())
+	assert.Equal(t, result.(cmpFailure).FailureMessage(), fmt.Sprintf(`directory %s does not match expected:
+/a
+  xattrs: expected {user.checksum="def"} got {user.checksum="abc"}
+`, dir.Path()))
+
+	copied := NewDir(t, t.Name(), FromDir(dir.Path()))
+	defer copied.Remove()
+	manifest := ManifestFromDir(t, dir.Path(), CompareXattrs)
+	assert.Assert(t, !Equal(copied.Path(), manifest)().Success())
+	assert.Assert(t, Equal(copied.Path(), ManifestFromDir(t, dir.Path())))
+}
@@ -0,0 +1,63 @@
+package fs
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// xattrNamespace is the namespace of the extended attributes which are stored
+// in a Manifest. Attributes in other namespaces are managed by the system.
+const xattrNamespace = "user."
+
+// listXattrs returns the extended attributes of path in the user namespace.
+// The map is empty if the filesystem does not support extended attributes.
+func listXattrs(path string) (map[string][]byte, error) {
+	xattrs := make(map[string][]byte)
+	size, err := syscall.Listxattr(path, nil)
+	switch {
+	case err == syscall.ENOTSUP:
+		return xattrs, nil
+	case err != nil:
+		return nil, &os.PathError{Op: "listxattr", Path: path, Err: err}
+	case size == 0:
+		return xattrs, nil
+	}
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil, &os.PathError{Op: "listxattr", Path: path, Err: err}
+	}
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if !strings.HasPrefix(string(name), xattrNamespace) {
+			continue
+		}
+		value, err := getXattr(path, string(name))
+		if err != nil {
+			return nil, err
+		}
+		xattrs[string(name)] = value
+	}
+	return xattrs, nil
+}
+
+func getXattr(path, name string) ([]byte, error) {
+	size, err := syscall.Getxattr(path, name, nil)
+	if err != nil {
+		return nil, &os.PathError{Op: "getxattr " + name, Path: path, Err: err}
+	}
+	value := make([]byte, size)
+	size, err = syscall.Getxattr(path, name, value)
+	if err != nil {
+		return nil, &os.PathError{Op: "getxattr " + name, Path: path, Err: err}
+	}
+	return value[:size], nil
+}
+
+func setXattr(path, name string, value []byte) error {
+	if err := syscall.Setxattr(path, name, value, 0); err != nil {
+		return &os.PathError{Op: "setxattr " + name, Path: path, Err: err}
+	}
+	return nil
+}
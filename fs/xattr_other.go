@@ -0,0 +1,19 @@
+//go:build !linux
+// +build !linux
+
+package fs
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// listXattrs returns an empty map, because extended attributes are only
+// supported on linux.
+func listXattrs(path string) (map[string][]byte, error) {
+	return map[string][]byte{}, nil
+}
+
+func setXattr(path, name string, value []byte) error {
+	return fmt.Errorf("extended attributes are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,223 @@
+/*
+Package gittest creates temporary git repositories for tests of tools which
+operate on repositories.
+
+Repositories are created with the git binary, and tests are skipped if git is
+not found in PATH. The commits are made with a fixed author and dates, so the
+commit hashes are the same every time the test runs, and the user and system
+git configuration is ignored.
+*/
+package gittest // import "gotest.tools/v3/gittest"
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+	"gotest.tools/v3/skip"
+)
+
+// TestingT is the subset of testing.T used by the functions in this package.
+type TestingT interface {
+	assert.TestingT
+	Skip(args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+const (
+	// DefaultBranch is the name of the branch checked out by NewRepo.
+	DefaultBranch = "main"
+	// AuthorName is the name of the author and committer of each commit.
+	AuthorName = "gotest.tools"
+	// AuthorEmail is the email of the author and committer of each commit.
+	AuthorEmail = "gotest.tools@example.com"
+)
+
+// epoch is the date of the first commit in a repository. Each commit is made
+// one minute after the previous commit.
+var epoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Repo is a temporary git repository.
+type Repo struct {
+	*fs.Dir
+	t       TestingT
+	home    *fs.Dir
+	commits int
+}
+
+// RepoOp modifies a Repo created by NewRepo.
+type RepoOp func(r *Repo)
+
+// NewRepo creates a repository with DefaultBranch checked out, and applies
+// the RepoOps in order. The repository is removed when the test ends, see
+// fs.NewDir.
+//
+// Example:
+//
+//	repo := gittest.NewRepo(t,
+//		gittest.WithCommit("initial", fs.WithFile("README.md", "hello")),
+//		gittest.WithTag("v1.0.0"),
+//		gittest.WithBranch("feature"),
+//		gittest.WithCommit("add feature", fs.WithFile("feature.go", "package main")))
+func NewRepo(t TestingT, ops ...RepoOp) *Repo {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	r := newRepo(t)
+	r.Git("init", "--quiet")
+	r.Git("symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch)
+	for _, op := range ops {
+		op(r)
+	}
+	return r
+}
+
+// NewBareRepo creates a bare repository, which is usually used as a remote of
+// another repository. See WithRemote.
+func NewBareRepo(t TestingT) *Repo {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	r := newRepo(t)
+	r.Git("init", "--quiet", "--bare")
+	r.Git("symbolic-ref", "HEAD", "refs/heads/"+DefaultBranch)
+	return r
+}
+
+func newRepo(t TestingT) *Repo {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	skip.IfBinaryMissing(t, "git")
+	return &Repo{
+		Dir:  fs.NewDir(t, "gittest"),
+		t:    t,
+		home: fs.NewDir(t, "gittest-home"),
+	}
+}
+
+// Git runs git with args in the repository, and returns the stdout with
+// leading and trailing whitespace removed. The test fails if git exits with a
+// non-zero status.
+func (r *Repo) Git(args ...string) string {
+	if ht, ok := r.t.(helperT); ok {
+		ht.Helper()
+	}
+	date := epoch.Add(time.Duration(r.commits) * time.Minute).Format(time.RFC3339)
+	result := icmd.RunCmd(icmd.Command("git", args...),
+		icmd.Dir(r.Path()),
+		icmd.WithEnv(append(os.Environ(),
+			"HOME="+r.home.Path(),
+			"XDG_CONFIG_HOME="+r.home.Path(),
+			"GIT_CONFIG_NOSYSTEM=1",
+			"GIT_CONFIG_GLOBAL="+os.DevNull,
+			"GIT_AUTHOR_NAME="+AuthorName,
+			"GIT_AUTHOR_EMAIL="+AuthorEmail,
+			"GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME="+AuthorName,
+			"GIT_COMMITTER_EMAIL="+AuthorEmail,
+			"GIT_COMMITTER_DATE="+date,
+		)...))
+	result.Assert(r.t, icmd.Success)
+	return strings.TrimSpace(result.Stdout())
+}
+
+// Commit applies the PathOps to the working tree, stages every change, and
+// commits it with message. It returns the hash of the commit. The commit is
+// made even if there are no changes.
+func (r *Repo) Commit(message string, ops ...fs.PathOp) string {
+	if ht, ok := r.t.(helperT); ok {
+		ht.Helper()
+	}
+	fs.Apply(r.t, r.Dir, ops...)
+	r.Git("add", "--all")
+	r.commits++
+	r.Git("commit", "--quiet", "--allow-empty", "--no-verify", "--no-gpg-sign", "--message", message)
+	return r.Head()
+}
+
+// Head returns the hash of the commit which is checked out.
+func (r *Repo) Head() string {
+	if ht, ok := r.t.(helperT); ok {
+		ht.Helper()
+	}
+	return r.Git("rev-parse", "HEAD")
+}
+
+// WithCommit commits the changes made by the PathOps. See Repo.Commit.
+func WithCommit(message string, ops ...fs.PathOp) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Commit(message, ops...)
+	}
+}
+
+// WithBranch creates a branch from the current commit, and checks it out.
+func WithBranch(name string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("checkout", "--quiet", "-b", name)
+	}
+}
+
+// WithCheckout checks out ref, which may be a branch, tag, or commit.
+func WithCheckout(ref string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("checkout", "--quiet", ref)
+	}
+}
+
+// WithTag creates a lightweight tag of the current commit.
+func WithTag(name string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("tag", name)
+	}
+}
+
+// WithAnnotatedTag creates an annotated tag of the current commit.
+func WithAnnotatedTag(name, message string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("tag", "--annotate", "--message", message, name)
+	}
+}
+
+// WithRemote adds a remote with the url. Use the Path of a Repo created by
+// NewBareRepo as the url for a remote which can be pushed to.
+func WithRemote(name, url string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("remote", "add", name, url)
+	}
+}
+
+// WithPush pushes the current branch to the remote, and sets it as the
+// upstream of the branch.
+func WithPush(remote string) RepoOp {
+	return func(r *Repo) {
+		if ht, ok := r.t.(helperT); ok {
+			ht.Helper()
+		}
+		r.Git("push", "--quiet", "--set-upstream", remote, "HEAD")
+	}
+}
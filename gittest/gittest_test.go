@@ -0,0 +1,58 @@
+package gittest
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestNewRepo(t *testing.T) {
+	repo := NewRepo(t,
+		WithCommit("initial", fs.WithFile("README.md", "hello\n")),
+		WithTag("v1.0.0"),
+		WithBranch("feature"),
+		WithCommit("add feature", fs.WithDir("cmd", fs.WithFile("main.go", "package main\n"))),
+		WithAnnotatedTag("v1.1.0", "release 1.1.0"),
+		WithCheckout(DefaultBranch))
+
+	assert.Equal(t, repo.Git("rev-parse", "--abbrev-ref", "HEAD"), "main")
+	assert.Equal(t, repo.Git("log", "--format=%s", "feature"), "add feature\ninitial")
+	assert.Equal(t, repo.Git("tag", "--list"), "v1.0.0\nv1.1.0")
+	assert.Equal(t, repo.Git("rev-parse", "v1.0.0"), repo.Head())
+	assert.Equal(t, repo.Git("log", "-1", "--format=%an <%ae> %aI"),
+		"gotest.tools <gotest.tools@example.com> 2020-01-01T00:01:00+00:00")
+	assert.Assert(t, fs.Equal(repo.Path(), fs.Expected(t,
+		fs.WithFile("README.md", "hello\n"),
+		fs.MatchExtraFiles)))
+}
+
+func TestRepo_CommitIsReproducible(t *testing.T) {
+	first := NewRepo(t).Commit("initial", fs.WithFile("a.txt", "a"))
+	second := NewRepo(t).Commit("initial", fs.WithFile("a.txt", "a"))
+	assert.Equal(t, first, second)
+}
+
+func TestRepo_CommitEmpty(t *testing.T) {
+	repo := NewRepo(t)
+	first := repo.Commit("first")
+	second := repo.Commit("second")
+	assert.Assert(t, first != second)
+	assert.Equal(t, repo.Git("rev-list", "--count", "HEAD"), "2")
+}
+
+func TestWithRemote(t *testing.T) {
+	remote := NewBareRepo(t)
+	repo := NewRepo(t,
+		WithCommit("initial", fs.WithFile("a.txt", "a")),
+		WithRemote("origin", remote.Path()),
+		WithPush("origin"))
+
+	assert.Equal(t, remote.Git("rev-parse", DefaultBranch), repo.Head())
+	assert.Equal(t, repo.Git("rev-parse", "--abbrev-ref", "@{upstream}"), "origin/main")
+
+	content, err := ioutil.ReadFile(repo.Join("a.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "a")
+}
@@ -0,0 +1,108 @@
+package golden
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// AssertBenchmark compares result to the baseline in the golden file, and
+// fails the test if ns/op or B/op regressed by more than threshold.
+//
+// This is equivalent to assert.Assert(t, Benchmark(result, filename, threshold))
+func AssertBenchmark(
+	t assert.TestingT,
+	result testing.BenchmarkResult,
+	filename string,
+	threshold float64,
+	msgAndArgs ...interface{},
+) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.Assert(t, Benchmark(result, filename, threshold), msgAndArgs...)
+}
+
+// Benchmark compares result to the baseline in the golden file, and returns
+// success unless ns/op or B/op regressed by more than threshold. threshold is
+// a fraction of the baseline, so 0.1 allows a result to be up to 10% slower
+// than the baseline. Results which are faster than the baseline always
+// succeed.
+//
+// Use testing.Benchmark to run a benchmark from a regular test:
+//
+//	result := testing.Benchmark(BenchmarkParse)
+//	golden.AssertBenchmark(t, result, "parse.bench", 0.2)
+//
+// Running `go test pkgname -update` will write result to the golden file, in
+// the format printed by go test -bench -benchmem.
+func Benchmark(result testing.BenchmarkResult, filename string, threshold float64) cmp.Comparison {
+	return func() cmp.Result {
+		if err := update(filename, []byte(formatBenchmark(result))); err != nil {
+			return cmp.ResultFromError(err)
+		}
+		content, err := ioutil.ReadFile(Path(filename))
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		baseline, err := parseBenchmark(string(content))
+		if err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("invalid benchmark baseline %s: %s", Path(filename), err))
+		}
+
+		var regressions []string
+		check := func(unit string, actual, expected float64) {
+			if !regressed(actual, expected, threshold) {
+				return
+			}
+			change := "new"
+			if expected != 0 {
+				change = fmt.Sprintf("%+.1f%%", (actual-expected)/expected*100)
+			}
+			regressions = append(regressions,
+				fmt.Sprintf("%s: %v (baseline %v, %s)", unit, actual, expected, change))
+		}
+		check("ns/op", float64(result.NsPerOp()), baseline["ns/op"])
+		check("B/op", float64(result.AllocedBytesPerOp()), baseline["B/op"])
+		if len(regressions) == 0 {
+			return cmp.ResultSuccess
+		}
+		msg := fmt.Sprintf("benchmark regressed by more than %v%% compared to %s:\n%s",
+			threshold*100, Path(filename), strings.Join(regressions, "\n"))
+		return cmp.ResultFailure(msg + failurePostamble(filename))
+	}
+}
+
+func regressed(actual, expected, threshold float64) bool {
+	return actual > expected*(1+threshold)
+}
+
+func formatBenchmark(result testing.BenchmarkResult) string {
+	return result.String() + "\t" + result.MemString() + "\n"
+}
+
+// parseBenchmark parses a line in the format printed by go test -bench, and
+// returns the value of each unit.
+func parseBenchmark(line string) (map[string]float64, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || len(fields)%2 != 1 {
+		return nil, fmt.Errorf("expected iterations followed by value and unit pairs, got %q", line)
+	}
+	values := make(map[string]float64, len(fields)/2)
+	for i := 1; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return nil, err
+		}
+		values[fields[i+1]] = value
+	}
+	if _, ok := values["ns/op"]; !ok {
+		return nil, fmt.Errorf("missing ns/op in %q", line)
+	}
+	return values, nil
+}
@@ -0,0 +1,75 @@
+package golden
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func benchmarkResult(nsPerOp, bytesPerOp int) testing.BenchmarkResult {
+	return testing.BenchmarkResult{
+		N:         1000,
+		T:         time.Duration(nsPerOp) * 1000,
+		MemAllocs: 2000,
+		MemBytes:  uint64(bytesPerOp) * 1000,
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "    1000\t      1000 ns/op\t      64 B/op\t       2 allocs/op\n")
+	defer clean()
+
+	assert.Assert(t, Benchmark(benchmarkResult(1000, 64), filename, 0))
+	assert.Assert(t, Benchmark(benchmarkResult(1100, 70), filename, 0.1))
+	assert.Assert(t, Benchmark(benchmarkResult(500, 0), filename, 0))
+
+	result := Benchmark(benchmarkResult(1200, 80), filename, 0.1)()
+	assert.Assert(t, !result.Success())
+	expected := "benchmark regressed by more than 10% compared to " + Path(filename) + ":\n" +
+		"ns/op: 1200 (baseline 1000, +20.0%)\n" +
+		"B/op: 80 (baseline 64, +25.0%)" + failurePostamble(filename)
+	assert.Equal(t, result.(failure).FailureMessage(), expected)
+}
+
+func TestBenchmark_NewAllocations(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "1000\t100 ns/op\t0 B/op\t0 allocs/op\n")
+	defer clean()
+
+	result := Benchmark(benchmarkResult(100, 8), filename, 0.5)()
+	assert.Assert(t, is.Contains(result.(failure).FailureMessage(), "B/op: 8 (baseline 0, new)"))
+}
+
+func TestBenchmark_InvalidBaseline(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "not a benchmark")
+	defer clean()
+
+	result := Benchmark(benchmarkResult(100, 8), filename, 0)()
+	assert.Assert(t, is.Contains(result.(failure).FailureMessage(), "invalid benchmark baseline"))
+}
+
+func TestBenchmark_Update(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "")
+	defer clean()
+	setUpdateFlag(t)
+
+	assert.Assert(t, Benchmark(benchmarkResult(250, 32), filename, 0))
+
+	content, err := ioutil.ReadFile(filepath.Join("testdata", filename))
+	assert.NilError(t, err)
+	values, err := parseBenchmark(string(content))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, map[string]float64{"ns/op": 250, "B/op": 32, "allocs/op": 2})
+}
+
+func TestAssertBenchmark(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "1\t1000 ns/op\t0 B/op\t0 allocs/op\n")
+	defer clean()
+
+	fakeT := new(fakeT)
+	AssertBenchmark(fakeT, benchmarkResult(2000, 0), filename, 0.5)
+	assert.Assert(t, fakeT.Failed)
+}
@@ -20,3 +20,7 @@ func ExampleString() {
 func ExampleAssertBytes() {
 	golden.AssertBytes(t, []byte("foo"), "foo-content.golden")
 }
+
+func ExampleAssertJSON() {
+	golden.AssertJSON(t, []byte(`{"name": "foo"}`), "foo-content.json")
+}
@@ -69,7 +69,30 @@ func Get(t assert.TestingT, filename string) []byte {
 }
 
 // Path returns the full path to a file in ./testdata
-func Path(filename string) string {
+//
+// When variants are given, Path returns the path of the first variant of the
+// file which exists, or the path of filename when none of them exist. Use
+// variants for golden files which differ by platform, for example
+//
+//	golden.Assert(t, actual, golden.Path("output.txt", golden.OSVariant()))
+//
+// compares actual to testdata/output_windows.txt on Windows when that file
+// exists, and to testdata/output.txt otherwise. Running `go test pkgname
+// -update` writes to the same file, so create an empty file for a new variant
+// before updating it. When variants are given the path is absolute, so that
+// it can be passed as the filename to the other functions in this package.
+func Path(filename string, variants ...Variant) string {
+	if len(variants) == 0 {
+		return testdataPath(filename)
+	}
+	path := resolveVariant(filename, variants)
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func testdataPath(filename string) string {
 	if filepath.IsAbs(filename) {
 		return filename
 	}
@@ -149,7 +172,8 @@ func AssertBytes(
 }
 
 // Bytes compares actual to the contents of filename and returns success
-// if the bytes are equal.
+// if the bytes are equal. Bytes is suitable for binary content, the failure
+// message is a side by side hexdump of the regions which differ.
 //
 // Running `go test pkgname -update` will write the value of actual
 // to the golden file.
@@ -159,8 +183,7 @@ func Bytes(actual []byte, filename string) cmp.Comparison {
 		if result != nil {
 			return result
 		}
-		msg := fmt.Sprintf("%v (actual) != %v (expected)", actual, expected)
-		return cmp.ResultFailure(msg + failurePostamble(filename))
+		return cmp.ResultFailure("\n" + hexDiff(expected, actual) + failurePostamble(filename))
 	}
 }
 
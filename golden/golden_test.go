@@ -256,8 +256,11 @@ func TestBytesFailure(t *testing.T) {
 
 	result := Bytes([]byte("5555"), filename)()
 	assert.Assert(t, !result.Success())
-	assert.Equal(t, result.(failure).FailureMessage(),
-		`[53 53 53 53] (actual) != [53 53 53 54] (expected)`+failurePostamble(filename))
+	assert.Equal(t, result.(failure).FailureMessage(), `
+expected 4 bytes, actual 4 bytes, first difference at offset 0x3
+  offset    expected                            actual
+! 00000000  35 35 35 36             |5556    |  35 35 35 35             |5555    |`+
+		failurePostamble(filename))
 }
 
 func TestFlagUpdate(t *testing.T) {
@@ -0,0 +1,127 @@
+package golden
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// hexRowSize is the number of bytes in each row of a hexdump diff.
+	hexRowSize = 8
+	// hexContextRows is the number of equal rows shown around each row
+	// which differs.
+	hexContextRows = 1
+	// hexMaxRows is the maximum number of rows in a hexdump diff.
+	hexMaxRows = 32
+)
+
+// hexDiff returns a side by side hexdump of the regions of expected and
+// actual which differ. Rows which differ are marked with !.
+func hexDiff(expected, actual []byte) string {
+	rows := (maxInt(len(expected), len(actual)) + hexRowSize - 1) / hexRowSize
+	differs := make([]bool, rows)
+	first := -1
+	for row := range differs {
+		differs[row] = !bytes.Equal(hexRow(expected, row), hexRow(actual, row))
+		if differs[row] && first < 0 {
+			first = row
+		}
+	}
+	if first < 0 {
+		return ""
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "expected %d bytes, actual %d bytes, first difference at offset %#x\n",
+		len(expected), len(actual), firstDifference(expected, actual))
+	fmt.Fprintf(buf, "  %-8s  %-*s  %s\n", "offset", hexColumnWidth, "expected", "actual")
+
+	shown, last := 0, -1
+	for row := range differs {
+		if !nearDifference(differs, row) {
+			continue
+		}
+		if shown == hexMaxRows {
+			buf.WriteString("  ... (more differences not shown)\n")
+			break
+		}
+		if last >= 0 && row != last+1 {
+			buf.WriteString("  ...\n")
+		}
+		marker := " "
+		if differs[row] {
+			marker = "!"
+		}
+		fmt.Fprintf(buf, "%s %08x  %s  %s\n", marker, row*hexRowSize,
+			formatHexRow(hexRow(expected, row)), formatHexRow(hexRow(actual, row)))
+		shown, last = shown+1, row
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// hexColumnWidth is the width of a row formatted by formatHexRow.
+const hexColumnWidth = hexRowSize*3 + hexRowSize + 2
+
+// hexRow returns the bytes of data in row, which may be fewer than
+// hexRowSize at the end of data.
+func hexRow(data []byte, row int) []byte {
+	start, end := row*hexRowSize, (row+1)*hexRowSize
+	if start > len(data) {
+		return nil
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+	return data[start:end]
+}
+
+func formatHexRow(data []byte) string {
+	var hex, text strings.Builder
+	for i := 0; i < hexRowSize; i++ {
+		if i >= len(data) {
+			hex.WriteString("   ")
+			text.WriteByte(' ')
+			continue
+		}
+		fmt.Fprintf(&hex, "%02x ", data[i])
+		if data[i] >= 0x20 && data[i] < 0x7f {
+			text.WriteByte(data[i])
+		} else {
+			text.WriteByte('.')
+		}
+	}
+	return hex.String() + "|" + text.String() + "|"
+}
+
+func nearDifference(differs []bool, row int) bool {
+	for i := row - hexContextRows; i <= row+hexContextRows; i++ {
+		if i >= 0 && i < len(differs) && differs[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func firstDifference(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return minInt(len(a), len(b))
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,34 @@
+package golden
+
+import (
+	"bytes"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHexDiff(t *testing.T) {
+	expected := make([]byte, 100)
+	for i := range expected {
+		expected[i] = byte(i)
+	}
+	actual := append([]byte{}, expected...)
+	actual[20] = 'x'
+	actual[80] = 'y'
+	actual = append(actual, "extra"...)
+
+	Assert(t, hexDiff(expected, actual), "hexdiff.golden")
+}
+
+func TestHexDiffLimitsRows(t *testing.T) {
+	expected := bytes.Repeat([]byte{0}, 1024)
+	actual := bytes.Repeat([]byte{1}, 1024)
+
+	diff := hexDiff(expected, actual)
+	assert.Equal(t, bytes.Count([]byte(diff), []byte("\n! ")), hexMaxRows)
+	assert.Assert(t, bytes.HasSuffix([]byte(diff), []byte("... (more differences not shown)")))
+}
+
+func TestHexDiffEqual(t *testing.T) {
+	assert.Equal(t, hexDiff([]byte("abc"), []byte("abc")), "")
+}
@@ -0,0 +1,140 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/format"
+)
+
+// Codec decodes and encodes the documents compared by Structured.
+type Codec struct {
+	// Name of the format, used in failure messages.
+	Name string
+	// Unmarshal decodes a document into v, which is a pointer to an empty
+	// interface.
+	Unmarshal func(data []byte, v interface{}) error
+	// Marshal encodes a decoded document in the canonical format written to
+	// the golden file. It must write map keys in a stable order.
+	Marshal func(v interface{}) ([]byte, error)
+}
+
+// JSONCodec is the Codec used by JSON. Documents are written with sorted
+// keys, indented by two spaces. Numbers are decoded as json.Number, so that
+// integers which can not be represented by a float64 are compared exactly.
+var JSONCodec = Codec{
+	Name:      "JSON",
+	Unmarshal: unmarshalJSON,
+	Marshal: func(v interface{}) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(v)
+		return buf.Bytes(), err
+	},
+}
+
+func unmarshalJSON(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	switch err := dec.Decode(v); err {
+	case nil:
+	case io.EOF, io.ErrUnexpectedEOF:
+		// match the error returned by json.Unmarshal
+		return fmt.Errorf("unexpected end of JSON input")
+	default:
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after top-level value")
+	}
+	return nil
+}
+
+// equalNumbers compares json.Number values by their value, so that 2 and 2.0
+// are equal.
+var equalNumbers = gocmp.Comparer(func(x, y json.Number) bool {
+	a, okA := new(big.Rat).SetString(string(x))
+	b, okB := new(big.Rat).SetString(string(y))
+	if !okA || !okB {
+		return x == y
+	}
+	return a.Cmp(b) == 0
+})
+
+// AssertJSON compares the JSON document actual to the document in the golden
+// file.
+//
+// This is equivalent to assert.Assert(t, JSON(actual, filename))
+func AssertJSON(t assert.TestingT, actual []byte, filename string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.Assert(t, JSON(actual, filename), msgAndArgs...)
+}
+
+// JSON compares the JSON document actual to the document in the golden file,
+// and returns success if they have the same values. Key order, whitespace,
+// and other differences in formatting are ignored.
+//
+// Running `go test pkgname -update` will write actual to the golden file in
+// the format of JSONCodec, so that the golden file does not change when only
+// the formatting of actual changes.
+func JSON(actual []byte, filename string) cmp.Comparison {
+	return Structured(actual, filename, JSONCodec)
+}
+
+// Structured compares the document actual to the document in the golden
+// file, after decoding both with codec, and returns success if the decoded
+// values are equal.
+//
+// Running `go test pkgname -update` will write actual to the golden file,
+// encoded with codec.Marshal.
+func Structured(actual []byte, filename string, codec Codec) cmp.Comparison {
+	return func() cmp.Result {
+		var actualValue interface{}
+		if err := codec.Unmarshal(actual, &actualValue); err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to decode actual %s: %s", codec.Name, err))
+		}
+		canonical, err := codec.Marshal(actualValue)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		if err := update(filename, canonical); err != nil {
+			return cmp.ResultFromError(err)
+		}
+
+		content, err := ioutil.ReadFile(Path(filename))
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		var expectedValue interface{}
+		if err := codec.Unmarshal(content, &expectedValue); err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to decode %s golden file %s: %s",
+				codec.Name, Path(filename), err))
+		}
+		if gocmp.Equal(expectedValue, actualValue, equalNumbers) {
+			return cmp.ResultSuccess
+		}
+
+		expected, err := codec.Marshal(expectedValue)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		diff := format.UnifiedDiff(format.DiffConfig{
+			A:    string(expected),
+			B:    string(canonical),
+			From: "expected",
+			To:   "actual",
+		})
+		return cmp.ResultFailure("\n" + diff + failurePostamble(filename))
+	}
+}
@@ -0,0 +1,91 @@
+package golden
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestJSON(t *testing.T) {
+	filename, clean := setupGoldenFile(t, `{"b": [1, 2], "a": {"c": "<d>"}}`)
+	defer clean()
+
+	fakeT := new(fakeT)
+	AssertJSON(fakeT, []byte(`{"a":{"c":"<d>"},"b":[1,2.0]}`), filename)
+	assert.Assert(t, !fakeT.Failed)
+}
+
+func TestJSONFailure(t *testing.T) {
+	filename, clean := setupGoldenFile(t, `{"b": [1, 2], "a": "x"}`)
+	defer clean()
+
+	result := JSON([]byte(`{"a": "y", "b": [1, 2]}`), filename)()
+	assert.Assert(t, !result.Success())
+	assert.Equal(t, result.(failure).FailureMessage(), `
+--- expected
++++ actual
+@@ -1,4 +1,4 @@
+ {
+-  "a": "x",
++  "a": "y",
+   "b": [
+     1,
+`+failurePostamble(filename))
+}
+
+func TestJSONInvalid(t *testing.T) {
+	filename, clean := setupGoldenFile(t, `{"a": `)
+	defer clean()
+
+	result := JSON([]byte(`{`), filename)()
+	assert.Equal(t, result.(failure).FailureMessage(),
+		"failed to decode actual JSON: unexpected end of JSON input")
+
+	result = JSON([]byte(`{}`), filename)()
+	assert.Equal(t, result.(failure).FailureMessage(),
+		"failed to decode JSON golden file "+Path(filename)+": unexpected end of JSON input")
+}
+
+func TestJSON_UpdateGolden(t *testing.T) {
+	filename, clean := setupGoldenFile(t, "")
+	defer clean()
+	setUpdateFlag(t)
+
+	fakeT := new(fakeT)
+	AssertJSON(fakeT, []byte(`{"b":[1,2],"a":{"c":"<d>"}}`), filename)
+	assert.Assert(t, !fakeT.Failed)
+
+	content, err := ioutil.ReadFile(Path(filename))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `{
+  "a": {
+    "c": "<d>"
+  },
+  "b": [
+    1,
+    2
+  ]
+}
+`)
+}
+
+func TestJSONLargeIntegers(t *testing.T) {
+	filename, clean := setupGoldenFile(t, `{"id": 9007199254740992}`)
+	defer clean()
+
+	result := JSON([]byte(`{"id": 9007199254740993}`), filename)()
+	assert.Assert(t, !result.Success())
+	assert.Equal(t, result.(failure).FailureMessage(), `
+--- expected
++++ actual
+@@ -1,4 +1,4 @@
+ {
+-  "id": 9007199254740992
++  "id": 9007199254740993
+ }
+ 
+`+failurePostamble(filename))
+
+	assert.Assert(t, JSON([]byte(`{"id": 9007199254740992.0}`), filename))
+}
@@ -0,0 +1,49 @@
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Variant is a key which selects a variant of a golden file. The file for a
+// variant has the key appended to its name, before the extension, so the
+// "windows" variant of output.txt is output_windows.txt.
+type Variant string
+
+// OSVariant returns the Variant for the current GOOS.
+func OSVariant() Variant {
+	return Variant(runtime.GOOS)
+}
+
+// ArchVariant returns the Variant for the current GOARCH.
+func ArchVariant() Variant {
+	return Variant(runtime.GOARCH)
+}
+
+// PlatformVariant returns the Variant for the current GOOS and GOARCH, in the
+// form GOOS_GOARCH.
+func PlatformVariant() Variant {
+	return Variant(runtime.GOOS + "_" + runtime.GOARCH)
+}
+
+// resolveVariant returns the path in ./testdata of the first variant of
+// filename which exists, or the path of filename if none of the variants exist.
+func resolveVariant(filename string, variants []Variant) string {
+	for _, variant := range variants {
+		if variant == "" {
+			continue
+		}
+		path := testdataPath(variantFilename(filename, variant))
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return testdataPath(filename)
+}
+
+func variantFilename(filename string, variant Variant) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "_" + string(variant) + ext
+}
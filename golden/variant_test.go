@@ -0,0 +1,57 @@
+package golden
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestPathWithVariants(t *testing.T) {
+	dir := fs.NewDir(t, "golden-variants",
+		fs.WithFile("output.txt", "default"),
+		fs.WithFile("output_"+runtime.GOOS+".txt", "os"),
+		fs.WithFile("output_postgres.txt", "postgres"))
+	defer dir.Remove()
+	filename := dir.Join("output.txt")
+
+	assert.Equal(t, Path(filename, OSVariant()), dir.Join("output_"+runtime.GOOS+".txt"))
+	assert.Equal(t, Path(filename, Variant("postgres"), OSVariant()), dir.Join("output_postgres.txt"))
+	assert.Equal(t, Path(filename, Variant("mysql"), OSVariant()), dir.Join("output_"+runtime.GOOS+".txt"))
+	assert.Equal(t, Path(filename, Variant("mysql"), PlatformVariant()), filename)
+	assert.Equal(t, Path(filename, ArchVariant()), filename)
+
+	Assert(t, "os", Path(filename, OSVariant()))
+}
+
+func TestPathWithVariantsIsAbsolute(t *testing.T) {
+	path := Path("missing.golden", OSVariant())
+	expected, err := filepath.Abs(filepath.Join("testdata", "missing.golden"))
+	assert.NilError(t, err)
+	assert.Equal(t, path, expected)
+}
+
+func TestVariantFilename(t *testing.T) {
+	assert.Equal(t, variantFilename("output.txt", "windows"), "output_windows.txt")
+	assert.Equal(t, variantFilename("dir/output", "arm64"), "dir/output_arm64")
+}
+
+func TestAssertWithVariant_UpdateGolden(t *testing.T) {
+	dir := fs.NewDir(t, "golden-variants",
+		fs.WithFile("output.txt", "default"),
+		fs.WithFile("output_"+runtime.GOOS+".txt", ""))
+	defer dir.Remove()
+	setUpdateFlag(t)
+
+	Assert(t, "updated", Path(dir.Join("output.txt"), OSVariant()))
+
+	content, err := ioutil.ReadFile(dir.Join("output_" + runtime.GOOS + ".txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "updated")
+	content, err = ioutil.ReadFile(dir.Join("output.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "default")
+}
@@ -0,0 +1,12 @@
+module gotest.tools/v3/goldenyaml
+
+go 1.25.0
+
+replace gotest.tools/v3 => ../
+
+require (
+	gopkg.in/yaml.v3 v3.0.1
+	gotest.tools/v3 v3.0.0-00010101000000-000000000000
+)
+
+require github.com/google/go-cmp v0.5.5 // indirect
@@ -0,0 +1,61 @@
+/*
+Package goldenyaml compares YAML documents to golden files, ignoring
+differences in key order and formatting. See gotest.tools/v3/golden for
+details about golden files.
+
+The package is a separate module, so that the YAML dependency is only required
+by the projects which use it.
+*/
+package goldenyaml // import "gotest.tools/v3/goldenyaml"
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/golden"
+)
+
+// Codec is the golden.Codec used by YAML. Documents are written with sorted
+// keys, indented by two spaces.
+var Codec = golden.Codec{
+	Name:      "YAML",
+	Unmarshal: yaml.Unmarshal,
+	Marshal: func(v interface{}) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		err := enc.Close()
+		return buf.Bytes(), err
+	},
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Assert compares the YAML document actual to the document in the golden
+// file.
+//
+// This is equivalent to assert.Assert(t, YAML(actual, filename))
+func Assert(t assert.TestingT, actual []byte, filename string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.Assert(t, YAML(actual, filename), msgAndArgs...)
+}
+
+// YAML compares the YAML document actual to the document in the golden file,
+// and returns success if they have the same values. Key order, comments,
+// and other differences in formatting are ignored.
+//
+// Running `go test pkgname -update` will write actual to the golden file in
+// the format of Codec, so that the golden file does not change when only the
+// formatting of actual changes.
+func YAML(actual []byte, filename string) cmp.Comparison {
+	return golden.Structured(actual, filename, Codec)
+}
@@ -0,0 +1,50 @@
+package goldenyaml
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestYAML(t *testing.T) {
+	actual := []byte(`
+tls: {enabled: true}
+# comments are ignored
+name: "example"
+ports: [80, 443]
+`)
+	Assert(t, actual, "config.golden")
+}
+
+func TestYAMLFailure(t *testing.T) {
+	actual := []byte(`
+name: example
+ports: [80, 8443]
+tls: {enabled: true}
+`)
+	result := YAML(actual, "config.golden")()
+	assert.Assert(t, !result.Success())
+
+	msg := result.(interface{ FailureMessage() string }).FailureMessage()
+	expected := `
+--- expected
++++ actual
+@@ -2,5 +2,5 @@
+ ports:
+   - 80
+-  - 443
++  - 8443
+ tls:
+   enabled: true
+`
+	assert.Assert(t, strings.HasPrefix(msg, expected), msg)
+}
+
+func TestYAMLInvalid(t *testing.T) {
+	result := YAML([]byte("a: [b"), "config.golden")()
+	assert.Assert(t, !result.Success())
+	msg := result.(interface{ FailureMessage() string }).FailureMessage()
+	assert.Assert(t, cmp.Contains(msg, "failed to decode actual YAML: "))
+}
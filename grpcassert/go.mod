@@ -0,0 +1,19 @@
+module gotest.tools/v3/grpcassert
+
+go 1.25.0
+
+replace gotest.tools/v3 => ../
+
+require (
+	github.com/google/go-cmp v0.7.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478
+	google.golang.org/grpc v1.82.1
+	google.golang.org/protobuf v1.36.11
+	gotest.tools/v3 v3.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/net v0.53.0 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+)
@@ -0,0 +1,114 @@
+/*
+Package grpcassert provides comparisons for gRPC errors and protobuf messages,
+and a helper to run a gRPC server in the test process.
+
+The package is a separate module, so that the gRPC and protobuf dependencies
+are only required by the projects which use it.
+*/
+package grpcassert // import "gotest.tools/v3/grpcassert"
+
+import (
+	"fmt"
+	"strings"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// Code succeeds if err is a gRPC status error with the code. If code is
+// codes.OK, Code succeeds only if err is nil.
+func Code(err error, code codes.Code) cmp.Comparison {
+	return func() cmp.Result {
+		st, ok := toStatus(err)
+		if !ok {
+			return cmp.ResultFailure(fmt.Sprintf("error is not a gRPC status: %s", err))
+		}
+		if st.Code() == code {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("expected code %s, got %s", code, describe(st)))
+	}
+}
+
+// Status succeeds if err is a gRPC status error with the code and the message.
+func Status(err error, code codes.Code, message string) cmp.Comparison {
+	return func() cmp.Result {
+		st, ok := toStatus(err)
+		if !ok {
+			return cmp.ResultFailure(fmt.Sprintf("error is not a gRPC status: %s", err))
+		}
+		if st.Code() == code && st.Message() == message {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("expected %s, got %s",
+			describe(status.New(code, message)), describe(st)))
+	}
+}
+
+// StatusContains succeeds if err is a gRPC status error with the code, and a
+// message which contains substring.
+func StatusContains(err error, code codes.Code, substring string) cmp.Comparison {
+	return func() cmp.Result {
+		st, ok := toStatus(err)
+		if !ok {
+			return cmp.ResultFailure(fmt.Sprintf("error is not a gRPC status: %s", err))
+		}
+		if st.Code() == code && strings.Contains(st.Message(), substring) {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("expected code %s with a message containing %q, got %s",
+			code, substring, describe(st)))
+	}
+}
+
+// Details succeeds if err is a gRPC status error, and the details of the status
+// are equal to expected, in the same order. The details are compared with
+// protocmp.Transform.
+func Details(err error, expected ...proto.Message) cmp.Comparison {
+	return func() cmp.Result {
+		st, ok := toStatus(err)
+		if !ok {
+			return cmp.ResultFailure(fmt.Sprintf("error is not a gRPC status: %s", err))
+		}
+		actual := make([]proto.Message, 0, len(st.Details()))
+		for _, detail := range st.Details() {
+			msg, ok := detail.(proto.Message)
+			if !ok {
+				return cmp.ResultFailure(fmt.Sprintf("failed to decode status details: %v", detail))
+			}
+			actual = append(actual, msg)
+		}
+		if expected == nil {
+			expected = []proto.Message{}
+		}
+		if diff := gocmp.Diff(expected, actual, protocmp.Transform()); diff != "" {
+			return cmp.ResultFailure("status details are not equal (-expected +actual):\n" + diff)
+		}
+		return cmp.ResultSuccess
+	}
+}
+
+// DeepEqual compares x and y like cmp.DeepEqual, with protocmp.Transform added
+// to the options, so that protobuf messages are compared by their fields, and
+// not by their internal state.
+func DeepEqual(x, y interface{}, opts ...gocmp.Option) cmp.Comparison {
+	return cmp.DeepEqual(x, y, append([]gocmp.Option{protocmp.Transform()}, opts...)...)
+}
+
+func toStatus(err error) (*status.Status, bool) {
+	if err == nil {
+		return status.New(codes.OK, ""), true
+	}
+	return status.FromError(err)
+}
+
+func describe(st *status.Status) string {
+	if st.Message() == "" {
+		return st.Code().String()
+	}
+	return fmt.Sprintf("%s: %s", st.Code(), st.Message())
+}
@@ -0,0 +1,96 @@
+package grpcassert
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func failureMessage(t *testing.T, comparison cmp.Comparison) string {
+	t.Helper()
+	result := comparison()
+	assert.Assert(t, !result.Success(), "expected failure")
+	return result.(interface{ FailureMessage() string }).FailureMessage()
+}
+
+func TestCode(t *testing.T) {
+	err := status.Error(codes.NotFound, "item 1 not found")
+	assert.Check(t, Code(err, codes.NotFound))
+	assert.Check(t, Code(nil, codes.OK))
+
+	assert.Equal(t, failureMessage(t, Code(err, codes.Internal)),
+		"expected code Internal, got NotFound: item 1 not found")
+	assert.Equal(t, failureMessage(t, Code(nil, codes.Internal)),
+		"expected code Internal, got OK")
+	assert.Equal(t, failureMessage(t, Code(errors.New("plain"), codes.Internal)),
+		"error is not a gRPC status: plain")
+}
+
+func TestStatus(t *testing.T) {
+	err := status.Error(codes.NotFound, "item 1 not found")
+	assert.Check(t, Status(err, codes.NotFound, "item 1 not found"))
+	assert.Check(t, StatusContains(err, codes.NotFound, "not found"))
+
+	assert.Equal(t, failureMessage(t, Status(err, codes.NotFound, "item 2 not found")),
+		"expected NotFound: item 2 not found, got NotFound: item 1 not found")
+	assert.Equal(t, failureMessage(t, StatusContains(err, codes.NotFound, "missing")),
+		`expected code NotFound with a message containing "missing", got NotFound: item 1 not found`)
+}
+
+func TestDetails(t *testing.T) {
+	st, err := status.New(codes.InvalidArgument, "invalid").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "name", Description: "required"},
+		}})
+	assert.NilError(t, err)
+
+	assert.Check(t, Details(st.Err(), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "name", Description: "required"},
+		},
+	}))
+	assert.Check(t, Details(status.Error(codes.Internal, "oops")))
+
+	message := failureMessage(t, Details(st.Err(), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "id", Description: "required"},
+		},
+	}))
+	assert.Assert(t, cmp.Contains(message, "status details are not equal (-expected +actual):"))
+	assert.Assert(t, cmp.Contains(message, `"id"`))
+}
+
+func TestDeepEqual(t *testing.T) {
+	x := &errdetails.ErrorInfo{Reason: "QUOTA", Metadata: map[string]string{"limit": "10"}}
+	y := proto.Clone(x)
+	assert.Check(t, DeepEqual(x, y))
+	assert.Check(t, !DeepEqual(x, &errdetails.ErrorInfo{Reason: "OTHER"})().Success())
+}
+
+func TestNewServer(t *testing.T) {
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("items", healthpb.HealthCheckResponse_NOT_SERVING)
+	conn := NewServer(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, healthServer)
+	})
+
+	client := healthpb.NewHealthClient(conn)
+	resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "items"})
+	assert.NilError(t, err)
+	assert.Check(t, DeepEqual(resp, &healthpb.HealthCheckResponse{
+		Status: healthpb.HealthCheckResponse_NOT_SERVING,
+	}))
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Check(t, Code(err, codes.NotFound))
+}
@@ -0,0 +1,51 @@
+package grpcassert
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"gotest.tools/v3/assert"
+)
+
+// TestingT is the subset of testing.T used by NewServer.
+type TestingT interface {
+	assert.TestingT
+	Cleanup(f func())
+}
+
+type helperT interface {
+	Helper()
+}
+
+// bufferSize is the size of the in-memory connection buffer.
+const bufferSize = 1024 * 1024
+
+// NewServer starts a gRPC server which listens on an in-memory connection, and
+// returns a client connection to the server. The register function is called
+// to register the services on the server, before it starts serving.
+//
+// The server and the client connection are closed when the test ends.
+func NewServer(t TestingT, register func(s *grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	listener := bufconn.Listen(bufferSize)
+	server := grpc.NewServer(opts...)
+	register(server)
+	go server.Serve(listener) //nolint: errcheck
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		conn.Close() //nolint: errcheck
+	})
+	return conn
+}
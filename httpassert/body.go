@@ -0,0 +1,71 @@
+package httpassert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/golden"
+	"gotest.tools/v3/internal/format"
+)
+
+// Body succeeds if the body of the response is equal to expected.
+func Body(resp Response, expected string) cmp.Comparison {
+	return func() cmp.Result {
+		body, err := toResponse(resp).body()
+		if err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to read body: %s", err))
+		}
+		if string(body) == expected {
+			return cmp.ResultSuccess
+		}
+		if !strings.Contains(expected, "\n") && !bytes.Contains(body, []byte("\n")) {
+			return cmp.ResultFailure(fmt.Sprintf("expected body %q, got %q", expected, body))
+		}
+		diff := format.UnifiedDiff(format.DiffConfig{
+			A:    expected,
+			B:    string(body),
+			From: "expected",
+			To:   "actual",
+		})
+		return cmp.ResultFailure("body is not equal:\n" + diff)
+	}
+}
+
+// BodyJSON succeeds if the body of the response is a JSON document which is
+// semantically equal to the expected JSON document. The formatting of the
+// documents, and the order of the keys of objects, is ignored.
+func BodyJSON(resp Response, expected string) cmp.Comparison {
+	return func() cmp.Result {
+		body, err := toResponse(resp).body()
+		if err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to read body: %s", err))
+		}
+		var expectedValue, actualValue interface{}
+		if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("expected value is not valid JSON: %s", err))
+		}
+		if err := json.Unmarshal(body, &actualValue); err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("body is not valid JSON: %s\nbody: %s", err, body))
+		}
+		if diff := gocmp.Diff(expectedValue, actualValue); diff != "" {
+			return cmp.ResultFailure("body is not equal (-expected +actual):\n" + diff)
+		}
+		return cmp.ResultSuccess
+	}
+}
+
+// BodyGolden succeeds if the body of the response is equal to the contents of
+// the golden file. See golden.String.
+func BodyGolden(resp Response, filename string) cmp.Comparison {
+	return func() cmp.Result {
+		body, err := toResponse(resp).body()
+		if err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to read body: %s", err))
+		}
+		return golden.String(string(body), filename)()
+	}
+}
@@ -0,0 +1,97 @@
+package httpassert
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/assert/cmp"
+)
+
+// Cookie succeeds if the response sets a cookie with the name of expected,
+// and the attributes of the cookie match the attributes of expected.
+//
+// Only the attributes of expected which are not the zero value are compared,
+// so a test can check the attributes it cares about, like Secure and HttpOnly,
+// without repeating the value of the cookie. Secure and HttpOnly are always
+// compared when they are true in expected.
+func Cookie(resp Response, expected *http.Cookie) cmp.Comparison {
+	return func() cmp.Result {
+		r := toResponse(resp)
+		var actual *http.Cookie
+		for _, cookie := range r.cookies {
+			if cookie.Name == expected.Name {
+				actual = cookie
+			}
+		}
+		if actual == nil {
+			return cmp.ResultFailure(fmt.Sprintf("missing cookie %s", expected.Name))
+		}
+
+		problems := compareCookie(actual, expected)
+		if len(problems) == 0 {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("cookie %s:\n%s",
+			expected.Name, strings.Join(problems, "\n")))
+	}
+}
+
+func compareCookie(actual, expected *http.Cookie) []string {
+	var problems []string
+	add := func(attribute string, x, y interface{}) {
+		problems = append(problems, fmt.Sprintf("  %s: expected %v, got %v", attribute, x, y))
+	}
+	if expected.Value != "" && actual.Value != expected.Value {
+		add("Value", quote(expected.Value), quote(actual.Value))
+	}
+	if expected.Path != "" && actual.Path != expected.Path {
+		add("Path", quote(expected.Path), quote(actual.Path))
+	}
+	if expected.Domain != "" && actual.Domain != expected.Domain {
+		add("Domain", quote(expected.Domain), quote(actual.Domain))
+	}
+	if !expected.Expires.IsZero() && !actual.Expires.Equal(expected.Expires) {
+		add("Expires", formatTime(expected.Expires), formatTime(actual.Expires))
+	}
+	if expected.MaxAge != 0 && actual.MaxAge != expected.MaxAge {
+		add("MaxAge", expected.MaxAge, actual.MaxAge)
+	}
+	if expected.Secure && !actual.Secure {
+		add("Secure", true, false)
+	}
+	if expected.HttpOnly && !actual.HttpOnly {
+		add("HttpOnly", true, false)
+	}
+	if expected.SameSite != 0 && actual.SameSite != expected.SameSite {
+		add("SameSite", sameSite(expected.SameSite), sameSite(actual.SameSite))
+	}
+	return problems
+}
+
+func quote(s string) string {
+	return fmt.Sprintf("%q", s)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "none"
+	}
+	return t.UTC().Format(http.TimeFormat)
+}
+
+func sameSite(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteDefaultMode:
+		return "Default"
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return "unset"
+	}
+}
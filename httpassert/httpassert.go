@@ -0,0 +1,126 @@
+/*
+Package httpassert provides comparisons for the responses of HTTP handlers and
+servers.
+
+Each function returns a cmp.Comparison, which can be used with assert.Assert
+or assert.Check.
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Check(t, httpassert.Status(rec, http.StatusOK))
+	assert.Check(t, httpassert.Header(rec, "Content-Type", "application/json"))
+	assert.Check(t, httpassert.BodyJSON(rec, `{"id": 1, "name": "first"}`))
+*/
+package httpassert // import "gotest.tools/v3/httpassert"
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// Response is the response being compared. It must be an *http.Response or
+// an *httptest.ResponseRecorder. The comparisons panic for any other type.
+//
+// The body of an *http.Response is read once, and replaced with a copy, so
+// that more than one comparison can read the body, and the body can still be
+// read after the comparisons.
+type Response interface{}
+
+type response struct {
+	status  int
+	header  http.Header
+	cookies []*http.Cookie
+	body    func() ([]byte, error)
+}
+
+func toResponse(resp Response) response {
+	switch typed := resp.(type) {
+	case *httptest.ResponseRecorder:
+		result := typed.Result()
+		return response{
+			status:  typed.Code,
+			header:  result.Header,
+			cookies: result.Cookies(),
+			body:    func() ([]byte, error) { return typed.Body.Bytes(), nil },
+		}
+	case *http.Response:
+		return response{
+			status:  typed.StatusCode,
+			header:  typed.Header,
+			cookies: typed.Cookies(),
+			body:    func() ([]byte, error) { return readBody(typed) },
+		}
+	default:
+		panic(fmt.Sprintf("httpassert: unsupported response type %T", resp))
+	}
+}
+
+func readBody(resp *http.Response) ([]byte, error) {
+	if resp.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// Status succeeds if the status code of the response is code.
+func Status(resp Response, code int) cmp.Comparison {
+	return func() cmp.Result {
+		r := toResponse(resp)
+		if r.status == code {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("expected status %s, got %s",
+			statusText(code), statusText(r.status)))
+	}
+}
+
+func statusText(code int) string {
+	return strings.TrimSpace(fmt.Sprintf("%d %s", code, http.StatusText(code)))
+}
+
+// HasHeader succeeds if the response has a header called key.
+func HasHeader(resp Response, key string) cmp.Comparison {
+	return func() cmp.Result {
+		r := toResponse(resp)
+		if _, ok := r.header[http.CanonicalHeaderKey(key)]; ok {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("missing header %s", key))
+	}
+}
+
+// Header succeeds if the response has a header called key, and the values of
+// the header are equal to values.
+func Header(resp Response, key string, values ...string) cmp.Comparison {
+	return func() cmp.Result {
+		r := toResponse(resp)
+		actual, ok := r.header[http.CanonicalHeaderKey(key)]
+		if !ok {
+			return cmp.ResultFailure(fmt.Sprintf("missing header %s", key))
+		}
+		if gocmp.Equal(actual, values) {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("header %s: expected %s, got %s",
+			key, quoteAll(values), quoteAll(actual)))
+	}
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,138 @@
+package httpassert
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func newRecorder() *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Type", "application/json")
+	rec.Header().Add("Vary", "Accept")
+	rec.Header().Add("Vary", "Origin")
+	http.SetCookie(rec, &http.Cookie{
+		Name:     "session",
+		Value:    "abc",
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	rec.WriteHeader(http.StatusCreated)
+	rec.WriteString(`{"id": 1, "tags": ["a", "b"]}`) //nolint: errcheck
+	return rec
+}
+
+func newResponse(t *testing.T) *http.Response {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("hello\nworld\n")) //nolint: errcheck
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NilError(t, err)
+	return resp
+}
+
+func assertFailure(t *testing.T, comparison cmp.Comparison, expected string) {
+	t.Helper()
+	result := comparison()
+	assert.Assert(t, !result.Success(), "expected failure")
+	assert.Equal(t, result.(interface{ FailureMessage() string }).FailureMessage(), expected)
+}
+
+func TestStatus(t *testing.T) {
+	rec := newRecorder()
+	assert.Check(t, Status(rec, http.StatusCreated))
+	assertFailure(t, Status(rec, http.StatusOK), "expected status 200 OK, got 201 Created")
+
+	resp := newResponse(t)
+	assert.Check(t, Status(resp, http.StatusOK))
+}
+
+func TestHeader(t *testing.T) {
+	rec := newRecorder()
+	assert.Check(t, HasHeader(rec, "content-type"))
+	assert.Check(t, Header(rec, "Content-Type", "application/json"))
+	assert.Check(t, Header(rec, "Vary", "Accept", "Origin"))
+
+	assertFailure(t, HasHeader(rec, "Location"), "missing header Location")
+	assertFailure(t, Header(rec, "Location", "/"), "missing header Location")
+	assertFailure(t, Header(rec, "Vary", "Accept"),
+		`header Vary: expected "Accept", got "Accept", "Origin"`)
+}
+
+func TestCookie(t *testing.T) {
+	rec := newRecorder()
+	assert.Check(t, Cookie(rec, &http.Cookie{Name: "session"}))
+	assert.Check(t, Cookie(rec, &http.Cookie{Name: "session", Value: "abc", HttpOnly: true}))
+
+	assertFailure(t, Cookie(rec, &http.Cookie{Name: "other"}), "missing cookie other")
+	assertFailure(t, Cookie(rec, &http.Cookie{
+		Name:     "session",
+		Value:    "xyz",
+		Path:     "/api",
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+		Expires:  time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+	}), `cookie session:
+  Value: expected "xyz", got "abc"
+  Path: expected "/api", got "/"
+  Expires: expected Wed, 02 Jan 2030 03:04:05 GMT, got none
+  Secure: expected true, got false
+  SameSite: expected Strict, got Lax`)
+}
+
+func TestBody(t *testing.T) {
+	rec := newRecorder()
+	assert.Check(t, Body(rec, `{"id": 1, "tags": ["a", "b"]}`))
+	assertFailure(t, Body(rec, `{}`), `expected body "{}", got "{\"id\": 1, \"tags\": [\"a\", \"b\"]}"`)
+
+	resp := newResponse(t)
+	assert.Check(t, Body(resp, "hello\nworld\n"))
+	assertFailure(t, Body(resp, "hello\nthere\n"), `body is not equal:
+--- expected
++++ actual
+@@ -1,3 +1,3 @@
+ hello
+-there
++world
+ 
+`)
+
+	// the body can still be read after the comparisons
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), "hello\nworld\n")
+}
+
+func TestBodyJSON(t *testing.T) {
+	rec := newRecorder()
+	assert.Check(t, BodyJSON(rec, `{"tags": ["a", "b"], "id": 1}`))
+
+	result := BodyJSON(rec, `{"tags": ["a"], "id": 1}`)()
+	assert.Assert(t, !result.Success())
+	message := result.(interface{ FailureMessage() string }).FailureMessage()
+	assert.Assert(t, strings.HasPrefix(message, "body is not equal (-expected +actual):"), message)
+	assert.Assert(t, cmp.Contains(message, `"b"`))
+
+	assertFailure(t, BodyJSON(rec, `{`),
+		"expected value is not valid JSON: unexpected end of JSON input")
+	assertFailure(t, BodyJSON(newResponse(t), `{}`),
+		"body is not valid JSON: invalid character 'h' looking for beginning of value\nbody: hello\nworld\n")
+}
+
+func TestBodyGolden(t *testing.T) {
+	assert.Check(t, BodyGolden(newResponse(t), "body.golden"))
+}
+
+func TestUnsupportedResponse(t *testing.T) {
+	assert.Assert(t, cmp.Panics(func() { Status("not a response", 200)() }))
+}
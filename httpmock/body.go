@@ -0,0 +1,43 @@
+package httpmock
+
+import (
+	"encoding/json"
+	"fmt"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// BodyEqual returns a BodyMatcher which succeeds if the body is equal to
+// expected.
+func BodyEqual(expected string) BodyMatcher {
+	return func(body []byte) cmp.Comparison {
+		return func() cmp.Result {
+			if string(body) == expected {
+				return cmp.ResultSuccess
+			}
+			return cmp.ResultFailure(fmt.Sprintf("expected %q, got %q", expected, body))
+		}
+	}
+}
+
+// BodyJSON returns a BodyMatcher which succeeds if the body is a JSON document
+// which is semantically equal to the expected JSON document. The formatting of
+// the documents, and the order of the keys of objects, is ignored.
+func BodyJSON(expected string) BodyMatcher {
+	return func(body []byte) cmp.Comparison {
+		return func() cmp.Result {
+			var expectedValue, actualValue interface{}
+			if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+				return cmp.ResultFailure(fmt.Sprintf("expected value is not valid JSON: %s", err))
+			}
+			if err := json.Unmarshal(body, &actualValue); err != nil {
+				return cmp.ResultFailure(fmt.Sprintf("not valid JSON: %s", err))
+			}
+			if diff := gocmp.Diff(expectedValue, actualValue); diff != "" {
+				return cmp.ResultFailure("not equal (-expected +actual):\n" + diff)
+			}
+			return cmp.ResultSuccess
+		}
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Package httpmock provides an HTTP server for tests which responds to expected
+requests with canned responses, and fails the test when it receives a request
+which was not expected, or does not receive a request which was expected.
+
+	server := httpmock.NewServer(t)
+	server.Expect(httpmock.Request{Method: "GET", Path: "/items/1"}).
+		Respond(http.StatusOK, `{"id": 1}`)
+
+	client := NewClient(server.URL)
+	...
+*/
+package httpmock // import "gotest.tools/v3/httpmock"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/assert/cmp"
+)
+
+// TestingT is the subset of testing.T used by NewServer.
+type TestingT interface {
+	Log(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// Request describes an expected request. The zero value of each field matches
+// any request.
+type Request struct {
+	// Method of the request, like "GET".
+	Method string
+	// Path of the request URL, not including the query.
+	Path string
+	// Query contains the query parameters which must be in the request URL.
+	// Other query parameters are ignored.
+	Query map[string]string
+	// Header contains headers which must be in the request, with the same
+	// values. Other headers are ignored.
+	Header http.Header
+	// Body compares the body of the request. See BodyEqual and BodyJSON.
+	Body BodyMatcher
+}
+
+// BodyMatcher compares the body of a request to an expected value.
+type BodyMatcher func(body []byte) cmp.Comparison
+
+// Server is an httptest.Server which responds to the expected requests.
+type Server struct {
+	*httptest.Server
+	t TestingT
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+	closed       bool
+}
+
+// Expectation is an expected request, and the response to send when the
+// request is received.
+type Expectation struct {
+	request  Request
+	status   int
+	header   http.Header
+	body     string
+	times    int
+	received int
+}
+
+// NewServer starts a Server. When used with Go 1.14+ the server is closed, and
+// the expectations are verified, when the test ends. Otherwise call Close.
+func NewServer(t TestingT) *Server {
+	s := &Server{t: t}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	if ct, ok := t.(cleanupT); ok {
+		ct.Cleanup(s.Close)
+	}
+	return s
+}
+
+// Expect adds an expected request. By default the request is expected exactly
+// once, and the response is 200 OK with an empty body. Use the methods of
+// Expectation to change the response and the number of times.
+//
+// When a request matches more than one expectation, the first expectation which
+// has not yet received all of its requests is used.
+func (s *Server) Expect(req Request) *Expectation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exp := &Expectation{request: req, status: http.StatusOK, header: http.Header{}, times: 1}
+	s.expectations = append(s.expectations, exp)
+	return exp
+}
+
+// Respond sets the status code and body of the response.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status = status
+	e.body = body
+	return e
+}
+
+// WithHeader adds a header to the response.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// Times sets the number of times the request is expected. A value of -1
+// allows the request any number of times, including none.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+func (e *Expectation) String() string {
+	return e.request.String()
+}
+
+func (r Request) String() string {
+	method, path := r.Method, r.Path
+	if method == "" {
+		method = "*"
+	}
+	if path == "" {
+		path = "*"
+	}
+	return method + " " + path
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var problems []string
+	for _, exp := range s.expectations {
+		if exp.times >= 0 && exp.received >= exp.times {
+			continue
+		}
+		mismatch := exp.request.match(req, body)
+		if len(mismatch) > 0 {
+			problems = append(problems, fmt.Sprintf("%s:\n  %s", exp, strings.Join(mismatch, "\n  ")))
+			continue
+		}
+		exp.received++
+		for key, values := range exp.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(exp.status)
+		w.Write([]byte(exp.body)) //nolint: errcheck
+		return
+	}
+
+	message := fmt.Sprintf("unexpected request %s %s", req.Method, req.URL.RequestURI())
+	if len(problems) > 0 {
+		message += "\ndoes not match the expected requests:\n" + strings.Join(problems, "\n")
+	}
+	s.unexpected = append(s.unexpected, message)
+	s.t.Errorf("%s", message)
+	http.Error(w, message, http.StatusNotImplemented)
+}
+
+func (r Request) match(req *http.Request, body []byte) []string {
+	var mismatch []string
+	if r.Method != "" && r.Method != req.Method {
+		mismatch = append(mismatch, fmt.Sprintf("method: expected %s, got %s", r.Method, req.Method))
+	}
+	if r.Path != "" && r.Path != req.URL.Path {
+		mismatch = append(mismatch, fmt.Sprintf("path: expected %s, got %s", r.Path, req.URL.Path))
+	}
+	query := req.URL.Query()
+	for _, key := range sortedKeys(r.Query) {
+		expected := r.Query[key]
+		if actual, ok := query[key]; !ok || actual[0] != expected {
+			mismatch = append(mismatch, fmt.Sprintf("query %s: expected %q, got %q", key, expected, query.Get(key)))
+		}
+	}
+	for key, values := range r.Header {
+		actual := req.Header[http.CanonicalHeaderKey(key)]
+		if !equalStrings(actual, values) {
+			mismatch = append(mismatch, fmt.Sprintf("header %s: expected %q, got %q",
+				http.CanonicalHeaderKey(key), values, actual))
+		}
+	}
+	if r.Body != nil {
+		if result := r.Body(body)(); !result.Success() {
+			mismatch = append(mismatch, "body: "+failureMessage(result))
+		}
+	}
+	return mismatch
+}
+
+func failureMessage(result cmp.Result) string {
+	if r, ok := result.(interface{ FailureMessage() string }); ok {
+		return r.FailureMessage()
+	}
+	return "does not match"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Verify fails the test if any of the expected requests were not received the
+// expected number of times. Verify is called by Close.
+func (s *Server) Verify() {
+	if ht, ok := s.t.(helperT); ok {
+		ht.Helper()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var missing []string
+	for _, exp := range s.expectations {
+		if exp.times >= 0 && exp.received != exp.times {
+			missing = append(missing, fmt.Sprintf("%s: received %d of %d times", exp, exp.received, exp.times))
+		}
+	}
+	if len(missing) > 0 {
+		s.t.Errorf("expected requests were not received:\n%s", strings.Join(missing, "\n"))
+	}
+}
+
+// Close shuts down the server, and verifies the expected requests were
+// received. See Verify. Close may be called more than once.
+func (s *Server) Close() {
+	if ht, ok := s.t.(helperT); ok {
+		ht.Helper()
+	}
+	s.mu.Lock()
+	closed := s.closed
+	s.closed = true
+	s.mu.Unlock()
+	if closed {
+		return
+	}
+	s.Server.Close()
+	s.Verify()
+}
@@ -0,0 +1,114 @@
+package httpmock
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+type fakeT struct {
+	errors []string
+}
+
+func (t *fakeT) Log(...interface{}) {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func do(t *testing.T, method, url, body string, header http.Header) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NilError(t, err)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	raw, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	return resp.StatusCode, string(raw)
+}
+
+func TestServerExpectedRequests(t *testing.T) {
+	server := NewServer(t)
+	server.Expect(Request{
+		Method: "POST",
+		Path:   "/items",
+		Query:  map[string]string{"dry-run": "true"},
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   BodyJSON(`{"name": "first", "tags": []}`),
+	}).Respond(http.StatusCreated, `{"id": 1}`).WithHeader("Location", "/items/1")
+	server.Expect(Request{Method: "GET", Path: "/items/1"}).Times(2)
+	server.Expect(Request{Path: "/health"}).Times(-1)
+
+	status, body := do(t, "POST", server.URL+"/items?dry-run=true&other=1",
+		`{"tags": [], "name": "first"}`, http.Header{"Content-Type": {"application/json"}})
+	assert.Equal(t, status, http.StatusCreated)
+	assert.Equal(t, body, `{"id": 1}`)
+
+	for i := 0; i < 2; i++ {
+		status, _ = do(t, "GET", server.URL+"/items/1", "", nil)
+		assert.Equal(t, status, http.StatusOK)
+	}
+}
+
+func TestServerUnexpectedRequest(t *testing.T) {
+	ft := &fakeT{}
+	server := NewServer(ft)
+	server.Expect(Request{Method: "PUT", Path: "/items/1", Body: BodyEqual("new")})
+
+	status, _ := do(t, "PUT", server.URL+"/items/1", "old", nil)
+	assert.Equal(t, status, http.StatusNotImplemented)
+	server.Close()
+
+	assert.DeepEqual(t, ft.errors, []string{
+		`unexpected request PUT /items/1
+does not match the expected requests:
+PUT /items/1:
+  body: expected "new", got "old"`,
+		`expected requests were not received:
+PUT /items/1: received 0 of 1 times`,
+	})
+}
+
+func TestServerTooManyRequests(t *testing.T) {
+	ft := &fakeT{}
+	server := NewServer(ft)
+	server.Expect(Request{Method: "GET", Path: "/items"})
+
+	status, _ := do(t, "GET", server.URL+"/items", "", nil)
+	assert.Equal(t, status, http.StatusOK)
+	status, _ = do(t, "GET", server.URL+"/items", "", nil)
+	assert.Equal(t, status, http.StatusNotImplemented)
+	server.Close()
+	server.Close()
+
+	assert.DeepEqual(t, ft.errors, []string{"unexpected request GET /items"})
+}
+
+func TestRequestMatchMismatches(t *testing.T) {
+	expected := Request{
+		Method: "POST",
+		Path:   "/items",
+		Query:  map[string]string{"page": "2"},
+		Header: http.Header{"X-Token": {"abc"}},
+		Body:   BodyJSON(`{"a": 1}`),
+	}
+	req, err := http.NewRequest("GET", "http://example.com/other?page=1", nil)
+	assert.NilError(t, err)
+
+	mismatch := expected.match(req, []byte(`{"a": 2}`))
+	assert.Equal(t, len(mismatch), 5)
+	assert.Equal(t, mismatch[0], "method: expected POST, got GET")
+	assert.Equal(t, mismatch[1], "path: expected /items, got /other")
+	assert.Equal(t, mismatch[2], `query page: expected "2", got "1"`)
+	assert.Equal(t, mismatch[3], `header X-Token: expected ["abc"], got []`)
+	assert.Assert(t, cmp.Contains(mismatch[4], "body: not equal (-expected +actual):"))
+}
@@ -0,0 +1,361 @@
+/*
+Package httpreplay provides an http.RoundTripper which records HTTP interactions
+to a fixture file, and replays them in later runs of the tests.
+
+Tests which depend on a third-party API can record the real interactions once,
+by running the tests with -update, and then run hermetically by replaying the
+recorded responses.
+
+Fixture files are stored in the ./testdata/ subdirectory of the package under
+test, like golden files.
+*/
+package httpreplay // import "gotest.tools/v3/httpreplay"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/golden"
+)
+
+// TestingT is the subset of testing.T used by Setup, Record, and Replay
+type TestingT interface {
+	assert.TestingT
+	Cleanup(f func())
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Mode sets how a request is matched to a recorded interaction when replaying.
+type Mode int
+
+const (
+	// Strict matches a request to an interaction with the same method, URL,
+	// and body. Each interaction is replayed at most once, in the order they
+	// were recorded.
+	Strict Mode = iota
+	// Lenient matches a request to an interaction with the same method and
+	// URL path, ignoring the query and the body. An interaction may be
+	// replayed any number of times.
+	Lenient
+)
+
+// Scrubber modifies an interaction before it is written to the fixture file.
+// Use it to remove secrets, like tokens and passwords, from the recording.
+type Scrubber func(interaction *Interaction)
+
+// Option changes the behaviour of a Transport.
+type Option func(*Transport)
+
+// WithMode sets the Mode used to match requests to recorded interactions. The
+// default is Strict.
+func WithMode(mode Mode) Option {
+	return func(tr *Transport) {
+		tr.mode = mode
+	}
+}
+
+// WithScrubber adds a Scrubber which is applied to each interaction before it
+// is recorded.
+func WithScrubber(scrubber Scrubber) Option {
+	return func(tr *Transport) {
+		tr.scrubbers = append(tr.scrubbers, scrubber)
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send the real requests when
+// recording. The default is http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(tr *Transport) {
+		tr.real = transport
+	}
+}
+
+// ScrubHeaders returns a Scrubber which replaces the values of the headers
+// with "REDACTED", in both the request and the response.
+func ScrubHeaders(names ...string) Scrubber {
+	return func(interaction *Interaction) {
+		for _, name := range names {
+			redactHeader(interaction.Request.Header, name)
+			redactHeader(interaction.Response.Header, name)
+		}
+	}
+}
+
+func redactHeader(header http.Header, name string) {
+	key := http.CanonicalHeaderKey(name)
+	if values, ok := header[key]; ok {
+		for i := range values {
+			values[i] = "REDACTED"
+		}
+	}
+}
+
+// defaultScrubbers are always applied before the scrubbers from WithScrubber.
+var defaultScrubbers = []Scrubber{
+	ScrubHeaders("Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"),
+}
+
+// Transport is an http.RoundTripper which records or replays interactions.
+type Transport struct {
+	fixture   string
+	recording bool
+	mode      Mode
+	scrubbers []Scrubber
+	real      http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+	misses       []string
+}
+
+// Setup records the interactions when the tests are run with -update, and
+// replays them from fixture otherwise. See Record and Replay.
+func Setup(t TestingT, fixture string, ops ...Option) *Transport {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if golden.FlagUpdate() {
+		return Record(t, fixture, ops...)
+	}
+	return Replay(t, fixture, ops...)
+}
+
+// Record returns a Transport which sends real requests, and records every
+// interaction. When the test ends the interactions are written to the fixture
+// file, replacing any previous recording.
+//
+// The Authorization, Proxy-Authorization, Cookie, and Set-Cookie headers are
+// always redacted from the recording. Use WithScrubber to remove other
+// secrets.
+func Record(t TestingT, fixture string, ops ...Option) *Transport {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	tr := newTransport(fixture, ops)
+	tr.recording = true
+	t.Cleanup(func() {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		assert.NilError(t, tr.writeFixture())
+	})
+	return tr
+}
+
+// Replay returns a Transport which replays the responses recorded in the
+// fixture file. If no interaction matches a request, RoundTrip returns an
+// error, and the test fails when it ends.
+func Replay(t TestingT, fixture string, ops ...Option) *Transport {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	tr := newTransport(fixture, ops)
+	interactions, err := LoadFixture(fixture)
+	assert.NilError(t, err, "run the tests with -update to record the fixture")
+	tr.interactions = interactions
+	tr.used = make([]bool, len(interactions))
+	t.Cleanup(func() {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
+		if len(tr.misses) == 0 {
+			return
+		}
+		t.Log("no recorded interactions matched:\n" + strings.Join(tr.misses, "\n"))
+		t.Fail()
+	})
+	return tr
+}
+
+func newTransport(fixture string, ops []Option) *Transport {
+	tr := &Transport{fixture: fixture, real: http.DefaultTransport}
+	for _, op := range ops {
+		op(tr)
+	}
+	return tr
+}
+
+// Recording returns true if the Transport is recording interactions.
+func (tr *Transport) Recording() bool {
+	return tr.recording
+}
+
+// Client returns an http.Client which uses the Transport.
+func (tr *Transport) Client() *http.Client {
+	return &http.Client{Transport: tr}
+}
+
+// RoundTrip records or replays the request. See http.RoundTripper.
+func (tr *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := readRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if tr.recording {
+		return tr.record(req, recorded)
+	}
+	return tr.replay(req, recorded)
+}
+
+func readRequest(req *http.Request) (Request, error) {
+	recorded := Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return recorded, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close() //nolint: errcheck
+	if err != nil {
+		return recorded, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	recorded.Body = string(body)
+	return recorded, nil
+}
+
+func (tr *Transport) record(req *http.Request, recorded Request) (*http.Response, error) {
+	resp, err := tr.real.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	tr.interactions = append(tr.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(body),
+		},
+	})
+	return resp, nil
+}
+
+func (tr *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for i, interaction := range tr.interactions {
+		if !tr.matches(i, interaction.Request, recorded) {
+			continue
+		}
+		tr.used[i] = true
+		return newResponse(req, interaction.Response), nil
+	}
+	miss := recorded.Method + " " + recorded.URL
+	tr.misses = append(tr.misses, miss)
+	return nil, fmt.Errorf("no recorded interaction matches %s", miss)
+}
+
+func (tr *Transport) matches(index int, recorded, req Request) bool {
+	switch tr.mode {
+	case Lenient:
+		return recorded.Method == req.Method && urlPath(recorded.URL) == urlPath(req.URL)
+	default:
+		return !tr.used[index] &&
+			recorded.Method == req.Method &&
+			recorded.URL == req.URL &&
+			recorded.Body == req.Body
+	}
+}
+
+func urlPath(rawURL string) string {
+	if i := strings.IndexAny(rawURL, "?#"); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
+
+func newResponse(req *http.Request, recorded Response) *http.Response {
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
+
+func (tr *Transport) writeFixture() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	interactions := make([]Interaction, 0, len(tr.interactions))
+	for _, interaction := range tr.interactions {
+		for _, scrub := range defaultScrubbers {
+			scrub(&interaction)
+		}
+		for _, scrub := range tr.scrubbers {
+			scrub(&interaction)
+		}
+		interactions = append(interactions, interaction)
+	}
+	raw, err := json.MarshalIndent(interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := golden.Path(tr.fixture)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(raw, '\n'), 0644)
+}
+
+// LoadFixture reads the interactions recorded in the fixture file.
+func LoadFixture(fixture string) ([]Interaction, error) {
+	raw, err := ioutil.ReadFile(golden.Path(fixture))
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	err = json.Unmarshal(raw, &interactions)
+	return interactions, err
+}
@@ -0,0 +1,157 @@
+package httpreplay
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+type fakeT struct {
+	*testing.T
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Fail() {
+	t.failed = true
+}
+
+func (t *fakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, args[0].(string))
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body))) //nolint: errcheck
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	resp, err := client.Get(url)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	return resp.Status + ": " + string(body)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := newServer(t)
+	dir := fs.NewDir(t, "httpreplay")
+	fixture := filepath.Join(dir.Path(), "api.json")
+
+	t.Run("record", func(t *testing.T) {
+		tr := Record(t, fixture, WithScrubber(ScrubHeaders("X-Request-Id")))
+		assert.Assert(t, tr.Recording())
+
+		req, err := http.NewRequest("POST", server.URL+"/items?page=1", strings.NewReader("data"))
+		assert.NilError(t, err)
+		req.Header.Set("Authorization", "Bearer token")
+		resp, err := tr.Client().Do(req)
+		assert.NilError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, string(body), "POST /items data")
+	})
+
+	interactions, err := LoadFixture(fixture)
+	assert.NilError(t, err)
+	assert.Equal(t, len(interactions), 1)
+	interaction := interactions[0]
+	assert.Equal(t, interaction.Request.Method, "POST")
+	assert.Equal(t, interaction.Request.URL, server.URL+"/items?page=1")
+	assert.Equal(t, interaction.Request.Body, "data")
+	assert.Equal(t, interaction.Request.Header.Get("Authorization"), "REDACTED")
+	assert.Equal(t, interaction.Response.StatusCode, http.StatusAccepted)
+	assert.Equal(t, interaction.Response.Header.Get("Set-Cookie"), "REDACTED")
+	assert.Equal(t, interaction.Response.Header.Get("X-Request-Id"), "REDACTED")
+
+	server.Close()
+	t.Run("replay", func(t *testing.T) {
+		tr := Replay(t, fixture)
+		assert.Assert(t, !tr.Recording())
+
+		resp, err := tr.Client().Post(server.URL+"/items?page=1", "text/plain", strings.NewReader("data"))
+		assert.NilError(t, err)
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.NilError(t, err)
+		assert.Equal(t, resp.StatusCode, http.StatusAccepted)
+		assert.Equal(t, string(body), "POST /items data")
+	})
+}
+
+func writeFixture(t *testing.T, interactions []Interaction) string {
+	raw, err := json.Marshal(interactions)
+	assert.NilError(t, err)
+	return fs.NewFile(t, "fixture", fs.WithBytes(raw)).Path()
+}
+
+var recorded = []Interaction{
+	{
+		Request:  Request{Method: "GET", URL: "http://example.com/items?page=1"},
+		Response: Response{StatusCode: 200, Body: "first"},
+	},
+	{
+		Request:  Request{Method: "GET", URL: "http://example.com/items?page=1"},
+		Response: Response{StatusCode: 200, Body: "second"},
+	},
+}
+
+func TestReplayStrict(t *testing.T) {
+	fixture := writeFixture(t, recorded)
+	ft := &fakeT{T: t}
+	client := Replay(ft, fixture).Client()
+
+	assert.Equal(t, get(t, client, "http://example.com/items?page=1"), "200 OK: first")
+	assert.Equal(t, get(t, client, "http://example.com/items?page=1"), "200 OK: second")
+	_, err := client.Get("http://example.com/items?page=1")
+	assert.Assert(t, cmp.ErrorContains(err, "no recorded interaction matches GET http://example.com/items?page=1"))
+	_, err = client.Get("http://example.com/items?page=2")
+	assert.Assert(t, err != nil)
+
+	ft.runCleanups()
+	assert.Assert(t, ft.failed)
+	assert.DeepEqual(t, ft.logs, []string{"no recorded interactions matched:\n" +
+		"GET http://example.com/items?page=1\n" +
+		"GET http://example.com/items?page=2"})
+}
+
+func TestReplayLenient(t *testing.T) {
+	fixture := writeFixture(t, recorded)
+	ft := &fakeT{T: t}
+	client := Replay(ft, fixture, WithMode(Lenient)).Client()
+
+	assert.Equal(t, get(t, client, "http://example.com/items?page=1"), "200 OK: first")
+	assert.Equal(t, get(t, client, "http://example.com/items?page=2"), "200 OK: first")
+	_, err := client.Get("http://example.com/other")
+	assert.Assert(t, err != nil)
+
+	ft.runCleanups()
+	assert.Assert(t, ft.failed)
+}
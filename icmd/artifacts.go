@@ -0,0 +1,32 @@
+package icmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"gotest.tools/v3/artifacts"
+)
+
+// WithOutputArtifacts writes the stdout and stderr of the command to the files
+// name.stdout and name.stderr in the artifacts directory of the test, so that
+// they are listed in the test output when the test fails. See artifacts.Dir.
+// The output is still captured in the Result.
+func WithOutputArtifacts(t artifacts.TestingT, name string) CmdOp {
+	return func(c *Cmd) {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		c.Stdout = teeWriter(c.Stdout, artifacts.Create(t, name+".stdout"))
+		c.Stderr = teeWriter(c.Stderr, artifacts.Create(t, name+".stderr"))
+	}
+}
+
+// artifactName returns a name for the artifacts of cmd, based on the name of
+// the binary.
+func artifactName(prefix string, cmd Cmd) string {
+	if len(cmd.Command) == 0 {
+		return prefix
+	}
+	base := filepath.Base(cmd.Command[0])
+	return prefix + "-" + strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,30 @@
+package icmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/artifacts"
+	"gotest.tools/v3/assert"
+)
+
+func TestRunCmdWithOutputArtifacts(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-warn"), WithOutputArtifacts(t, "stub"))
+	result.Assert(t, Expected{Out: "this is stdout", Err: "this is stderr"})
+
+	dir := artifacts.Dir(t)
+	stdout, err := ioutil.ReadFile(filepath.Join(dir, "stub.stdout"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(stdout), "this is stdout\n")
+	stderr, err := ioutil.ReadFile(filepath.Join(dir, "stub.stderr"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(stderr), "this is stderr\n")
+}
+
+func TestArtifactName(t *testing.T) {
+	assert.Equal(t, artifactName("daemon", Command("/usr/bin/server.exe", "-v")), "daemon-server")
+	assert.Equal(t, artifactName("daemon", Cmd{}), "daemon")
+}
@@ -0,0 +1,52 @@
+package icmd
+
+import "fmt"
+
+// ringBuffer keeps the last len(data) bytes written to it.
+type ringBuffer struct {
+	data  []byte
+	start int
+	size  int
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{data: make([]byte, size)}
+}
+
+// write p to the buffer, and return the number of bytes which were discarded
+// to make room for p.
+func (r *ringBuffer) write(p []byte) int {
+	n := len(r.data)
+	if len(p) >= n {
+		dropped := r.size + len(p) - n
+		copy(r.data, p[len(p)-n:])
+		r.start, r.size = 0, n
+		return dropped
+	}
+
+	var dropped int
+	if overflow := r.size + len(p) - n; overflow > 0 {
+		r.start = (r.start + overflow) % n
+		r.size -= overflow
+		dropped = overflow
+	}
+	end := (r.start + r.size) % n
+	copied := copy(r.data[end:], p)
+	copy(r.data, p[copied:])
+	r.size += len(p)
+	return dropped
+}
+
+func (r *ringBuffer) String() string {
+	n := len(r.data)
+	if r.start+r.size <= n {
+		return string(r.data[r.start : r.start+r.size])
+	}
+	return string(r.data[r.start:]) + string(r.data[:r.start+r.size-n])
+}
+
+// droppedMarker is placed between the head and tail of output which exceeded
+// the limit set by WithMaxOutput.
+func droppedMarker(dropped int64) string {
+	return fmt.Sprintf("\n[... %d bytes dropped ...]\n", dropped)
+}
@@ -0,0 +1,55 @@
+package icmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/skip"
+)
+
+func TestRingBuffer(t *testing.T) {
+	ring := newRingBuffer(5)
+	assert.Equal(t, ring.write([]byte("abc")), 0)
+	assert.Equal(t, ring.String(), "abc")
+	assert.Equal(t, ring.write([]byte("de")), 0)
+	assert.Equal(t, ring.String(), "abcde")
+	assert.Equal(t, ring.write([]byte("fg")), 2)
+	assert.Equal(t, ring.String(), "cdefg")
+	assert.Equal(t, ring.write([]byte("hijklmn")), 7)
+	assert.Equal(t, ring.String(), "jklmn")
+	assert.Equal(t, ring.write([]byte("o")), 1)
+	assert.Equal(t, ring.String(), "klmno")
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	buf := newLimitedBuffer(6)
+	fmt.Fprint(buf, "ab")
+	assert.Equal(t, buf.String(), "ab")
+	fmt.Fprint(buf, "cdef")
+	assert.Equal(t, buf.String(), "abcdef")
+	assert.Equal(t, buf.droppedBytes(), int64(0))
+
+	fmt.Fprint(buf, "ghijklmnop")
+	assert.Equal(t, buf.String(), "abc\n[... 10 bytes dropped ...]\nnop")
+	assert.Equal(t, buf.droppedBytes(), int64(10))
+}
+
+func TestRunCmdWithMaxOutput(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires cat")
+
+	result := RunCmd(Command("cat"),
+		WithMaxOutput(100),
+		WithStdinFunc(func(w io.Writer) error {
+			_, err := io.WriteString(w, "start"+strings.Repeat(".", 1<<20)+"end")
+			return err
+		}))
+	result.Assert(t, Success)
+	assert.Equal(t, result.StdoutDropped(), int64(len("start")+1<<20+len("end")-100))
+	assert.Assert(t, strings.HasPrefix(result.Stdout(), "start"))
+	assert.Assert(t, strings.HasSuffix(result.Stdout(), "end"))
+	assert.Equal(t, result.StderrDropped(), int64(0))
+}
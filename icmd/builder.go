@@ -0,0 +1,109 @@
+package icmd
+
+import (
+	"io"
+	"os"
+	"time"
+)
+
+// CmdBuilder builds a Cmd using chained method calls. Use New to create a
+// CmdBuilder.
+//
+// Example:
+//
+//	result := icmd.New("git").
+//		Args("status", "--short").
+//		Dir(repo.Path()).
+//		Timeout(10 * time.Second).
+//		Run()
+type CmdBuilder struct {
+	cmd Cmd
+}
+
+// New returns a CmdBuilder for the command.
+func New(command string) *CmdBuilder {
+	return &CmdBuilder{cmd: Command(command)}
+}
+
+// Args appends args to the arguments of the command.
+func (b *CmdBuilder) Args(args ...string) *CmdBuilder {
+	b.cmd.Command = append(b.cmd.Command, args...)
+	return b
+}
+
+// Env appends variables to the environment of the command. Each variable
+// must be in the form of KEY=VALUE. Like WithEnv, once Env is called the
+// command is run with only the variables added by Env.
+func (b *CmdBuilder) Env(env ...string) *CmdBuilder {
+	b.cmd.Env = append(b.cmd.Env, env...)
+	return b
+}
+
+// Dir sets the working directory of the command.
+func (b *CmdBuilder) Dir(path string) *CmdBuilder {
+	b.cmd.Dir = path
+	return b
+}
+
+// Timeout sets the timeout duration of the command.
+func (b *CmdBuilder) Timeout(timeout time.Duration) *CmdBuilder {
+	b.cmd.Timeout = timeout
+	return b
+}
+
+// Stdin sets the standard input of the command.
+func (b *CmdBuilder) Stdin(r io.Reader) *CmdBuilder {
+	b.cmd.Stdin = r
+	return b
+}
+
+// Stdout sets a writer which receives a copy of the standard output of the
+// command.
+func (b *CmdBuilder) Stdout(w io.Writer) *CmdBuilder {
+	b.cmd.Stdout = w
+	return b
+}
+
+// ExtraFile adds a file descriptor to the command.
+func (b *CmdBuilder) ExtraFile(f *os.File) *CmdBuilder {
+	b.cmd.ExtraFiles = append(b.cmd.ExtraFiles, f)
+	return b
+}
+
+// With applies each CmdOp to the command.
+func (b *CmdBuilder) With(ops ...CmdOp) *CmdBuilder {
+	for _, op := range ops {
+		op(&b.cmd)
+	}
+	return b
+}
+
+// If applies each CmdOp to the command when condition is true.
+func (b *CmdBuilder) If(condition bool, ops ...CmdOp) *CmdBuilder {
+	if condition {
+		return b.With(ops...)
+	}
+	return b
+}
+
+// Cmd returns the Cmd built by the CmdBuilder. Changes made to the CmdBuilder
+// after Cmd is called do not change the returned Cmd.
+func (b *CmdBuilder) Cmd() Cmd {
+	cmd := b.cmd
+	cmd.Command = append([]string(nil), b.cmd.Command...)
+	if b.cmd.Env != nil {
+		cmd.Env = append([]string{}, b.cmd.Env...)
+	}
+	cmd.ExtraFiles = append([]*os.File(nil), b.cmd.ExtraFiles...)
+	return cmd
+}
+
+// Run runs the command and returns a Result. See RunCmd.
+func (b *CmdBuilder) Run() *Result {
+	return RunCmd(b.Cmd())
+}
+
+// Start starts the command, but doesn't wait for it to finish. See StartCmd.
+func (b *CmdBuilder) Start() *Result {
+	return StartCmd(b.Cmd())
+}
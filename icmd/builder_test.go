@@ -0,0 +1,53 @@
+package icmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCmdBuilder(t *testing.T) {
+	stdin := strings.NewReader("input")
+	stdout := new(bytes.Buffer)
+
+	builder := New("git").
+		Args("status").
+		Args("--short").
+		Env("A=1").
+		Env("B=2").
+		Dir("/tmp").
+		Timeout(time.Second).
+		Stdin(stdin).
+		Stdout(stdout).
+		If(false, Dir("/other")).
+		With(WithRetries(1, 0))
+
+	cmd := builder.Cmd()
+	assert.DeepEqual(t, cmd.Command, []string{"git", "status", "--short"})
+	assert.DeepEqual(t, cmd.Env, []string{"A=1", "B=2"})
+	assert.Equal(t, cmd.Dir, "/tmp")
+	assert.Equal(t, cmd.Timeout, time.Second)
+	assert.Equal(t, cmd.Stdin, stdin)
+	assert.Equal(t, cmd.Stdout, stdout)
+	assert.Equal(t, cmd.Retry.Retries, 1)
+
+	builder.Args("-v").If(true, Dir("/other"))
+	assert.DeepEqual(t, cmd.Command, []string{"git", "status", "--short"})
+	assert.Equal(t, builder.Cmd().Dir, "/other")
+}
+
+func TestCmdBuilderNoEnv(t *testing.T) {
+	assert.Assert(t, New("true").Cmd().Env == nil)
+}
+
+func TestCmdBuilderRun(t *testing.T) {
+	buildStub(t)
+
+	New(binname).Args("-warn").Run().Assert(t, Expected{Err: "this is stderr"})
+
+	result := New(binname).Args("-sleep=10ms").Start()
+	WaitOnCmd(time.Second, result).Assert(t, Success)
+}
@@ -4,6 +4,7 @@ package icmd // import "gotest.tools/v3/icmd"
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -14,6 +15,7 @@ import (
 
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/clock"
 )
 
 type helperT interface {
@@ -26,29 +28,139 @@ const None = "[NOTHING]"
 type lockedBuffer struct {
 	m   sync.RWMutex
 	buf bytes.Buffer
+	// limit is the maximum number of bytes kept by the buffer. When limit is
+	// non-zero the first half is kept in buf, and the last half in tail.
+	limit   int
+	tail    *ringBuffer
+	dropped int64
+}
+
+func newLimitedBuffer(limit int) *lockedBuffer {
+	if limit <= 0 {
+		return new(lockedBuffer)
+	}
+	return &lockedBuffer{limit: limit, tail: newRingBuffer(limit - limit/2)}
 }
 
 func (buf *lockedBuffer) Write(b []byte) (int, error) {
 	buf.m.Lock()
 	defer buf.m.Unlock()
-	return buf.buf.Write(b)
+	if buf.limit == 0 {
+		return buf.buf.Write(b)
+	}
+	n := len(b)
+	if head := buf.limit/2 - buf.buf.Len(); head > 0 {
+		if head > len(b) {
+			head = len(b)
+		}
+		buf.buf.Write(b[:head])
+		b = b[head:]
+	}
+	buf.dropped += int64(buf.tail.write(b))
+	return n, nil
 }
 
 func (buf *lockedBuffer) String() string {
 	buf.m.RLock()
 	defer buf.m.RUnlock()
-	return buf.buf.String()
+	if buf.limit == 0 {
+		return buf.buf.String()
+	}
+	if buf.dropped == 0 {
+		return buf.buf.String() + buf.tail.String()
+	}
+	return buf.buf.String() + droppedMarker(buf.dropped) + buf.tail.String()
+}
+
+// readFrom returns the output kept by the buffer starting at offset, counted
+// from the first byte written, and the offset of the first byte returned. If
+// the bytes at offset were dropped the output starts after them. truncated is
+// true if bytes were dropped after the output which is returned.
+func (buf *lockedBuffer) readFrom(offset int64) (output string, start int64, truncated bool) {
+	buf.m.RLock()
+	defer buf.m.RUnlock()
+	head := int64(buf.buf.Len())
+	if buf.limit == 0 || buf.dropped == 0 {
+		output = buf.buf.String()
+		if buf.limit != 0 {
+			output += buf.tail.String()
+		}
+		if offset > int64(len(output)) {
+			offset = int64(len(output))
+		}
+		return output[offset:], offset, false
+	}
+	if offset < head {
+		return buf.buf.String()[offset:], offset, true
+	}
+	tail := buf.tail.String()
+	start = head + buf.dropped
+	if offset > start+int64(len(tail)) {
+		offset = start + int64(len(tail))
+	}
+	if offset > start {
+		return tail[offset-start:], offset, false
+	}
+	return tail, start, false
+}
+
+func (buf *lockedBuffer) droppedBytes() int64 {
+	buf.m.RLock()
+	defer buf.m.RUnlock()
+	return buf.dropped
 }
 
 // Result stores the result of running a command
 type Result struct {
 	Cmd      *exec.Cmd
 	ExitCode int
-	Error    error
+	// Command is the command and arguments which were run. Cmd.Args is
+	// different when the command is run by a wrapper, for example to apply
+	// Limits.
+	Command []string
+	// ExitSignal is the signal which terminated the command, or nil if the
+	// command was not terminated by a signal
+	ExitSignal os.Signal
+	Error      error
 	// Timeout is true if the command was killed because it ran for too long
-	Timeout   bool
-	outBuffer *lockedBuffer
-	errBuffer *lockedBuffer
+	Timeout bool
+	// Attempts contains the results of previous runs of the command when it
+	// was run with WithRetries. The last attempt is the Result itself, and is
+	// not included.
+	Attempts []*Result
+	// Duration is the wall-clock time from when the command was started until
+	// it exited, or until it was killed by the timeout
+	Duration time.Duration
+	// UserTime and SystemTime are the CPU time used by the command.
+	UserTime   time.Duration
+	SystemTime time.Duration
+	// MaxRSS is the maximum resident set size of the command in bytes. MaxRSS
+	// is only available on platforms which support getrusage.
+	MaxRSS int64
+	// LimitExceeded is the resource limit which terminated the command, if
+	// any. See WithLimits.
+	LimitExceeded Resource
+	limits        *Limits
+	started       time.Time
+	group         *processGroup
+	flush         []func()
+	secrets       []string
+	// killsGroup is true if the command is started in a new process group
+	killsGroup bool
+	// closeAfterStart are files opened for the command, which are closed
+	// once the command has started
+	closeAfterStart []io.Closer
+	watch           *contextWatch
+	clock           clock.Clock
+	outBuffer       *lockedBuffer
+	errBuffer       *lockedBuffer
+	// outCursor and errCursor are the offsets of the end of the last line
+	// matched by WaitForLine in stdout and stderr, counted from the start of
+	// the output including any bytes dropped by WithMaxOutput.
+	outCursor int64
+	errCursor int64
+	// tty is the pseudo-terminal of a command run WithTTY.
+	tty *ttyConn
 }
 
 // Assert compares the Result against the Expected struct, and fails the test if
@@ -59,7 +171,7 @@ func (r *Result) Assert(t assert.TestingT, exp Expected) *Result {
 	if ht, ok := t.(helperT); ok {
 		ht.Helper()
 	}
-	assert.Assert(t, r.Equal(exp))
+	assert.Assert(t, r.equal(t, exp))
 	return r
 }
 
@@ -67,8 +179,14 @@ func (r *Result) Assert(t assert.TestingT, exp Expected) *Result {
 // returns a formatted failure message with the command, stdout, stderr, exit code,
 // and any failed expectations.
 func (r *Result) Equal(exp Expected) cmp.Comparison {
+	return r.equal(nil, exp)
+}
+
+// equal is like Equal. If t is a testing.T the full output of a long stream is
+// written to the artifacts directory of the test.
+func (r *Result) equal(t interface{}, exp Expected) cmp.Comparison {
 	return func() cmp.Result {
-		return cmp.ResultFromError(r.match(exp))
+		return cmp.ResultFromError(r.matchT(t, exp))
 	}
 }
 
@@ -78,32 +196,83 @@ func (r *Result) Compare(exp Expected) error {
 }
 
 func (r *Result) match(exp Expected) error {
+	return r.matchT(nil, exp)
+}
+
+func (r *Result) matchT(t interface{}, exp Expected) error {
 	errors := []string{}
 	add := func(format string, args ...interface{}) {
 		errors = append(errors, fmt.Sprintf(format, args...))
 	}
 
-	if exp.ExitCode != r.ExitCode {
+	outcome := exp.outcome()
+	r.matchOutcome(exp, add)
+	switch {
+	// Only a command which exited has a meaningful exit code.
+	case outcome != "" && outcome != OutcomeExited:
+	// A command terminated by a signal has no meaningful exit code, so only
+	// compare the signal.
+	case exp.ExitSignal != nil:
+		if exp.ExitSignal != r.ExitSignal {
+			add("ExitSignal was %v expected %v", r.ExitSignal, exp.ExitSignal)
+		}
+	case exp.ExitCode != r.ExitCode:
 		add("ExitCode was %d expected %d", r.ExitCode, exp.ExitCode)
 	}
-	if exp.Timeout != r.Timeout {
+	if outcome == "" && exp.Timeout != r.Timeout {
 		if exp.Timeout {
 			add("Expected command to timeout")
 		} else {
 			add("Expected command to finish, but it hit the timeout")
 		}
 	}
+	if exp.MinDuration != 0 && r.Duration < exp.MinDuration {
+		add("Duration was %s expected at least %s", r.Duration, exp.MinDuration)
+	}
+	if exp.MaxDuration != 0 && r.Duration > exp.MaxDuration {
+		add("Duration was %s expected at most %s", r.Duration, exp.MaxDuration)
+	}
 	if !matchOutput(exp.Out, r.Stdout()) {
-		add("Expected stdout to contain %q", exp.Out)
+		add("Expected stdout to contain %q%s", exp.Out, streamDiff("stdout", exp.Out, r.Stdout()))
 	}
 	if !matchOutput(exp.Err, r.Stderr()) {
-		add("Expected stderr to contain %q", exp.Err)
+		add("Expected stderr to contain %q%s", exp.Err, streamDiff("stderr", exp.Err, r.Stderr()))
+	}
+	if !matchOutput(exp.TTY, r.TTY()) {
+		add("Expected tty to contain %q%s", exp.TTY, streamDiff("tty", exp.TTY, r.TTY()))
+	}
+	if exp.OutMatch != nil {
+		if err := exp.OutMatch(r.Stdout()); err != nil {
+			add("Expected stdout to match: %s", err)
+		}
+	}
+	if exp.ErrMatch != nil {
+		if err := exp.ErrMatch(r.Stderr()); err != nil {
+			add("Expected stderr to match: %s", err)
+		}
+	}
+	if exp.TTYMatch != nil {
+		if err := exp.TTYMatch(r.TTY()); err != nil {
+			add("Expected tty to match: %s", err)
+		}
+	}
+	if exp.OutGolden != "" {
+		if msg, ok := matchGolden(r.Stdout(), exp.OutGolden); !ok {
+			add("Expected stdout to match golden file %s:%s", exp.OutGolden, msg)
+		}
+	}
+	if exp.ErrGolden != "" {
+		if msg, ok := matchGolden(r.Stderr(), exp.ErrGolden); !ok {
+			add("Expected stderr to match golden file %s:%s", exp.ErrGolden, msg)
+		}
 	}
 	switch {
 	// If a non-zero exit code is expected there is going to be an error.
 	// Don't require an error message as well as an exit code because the
 	// error message is going to be "exit status <code> which is not useful
-	case exp.Error == "" && exp.ExitCode != 0:
+	case exp.Error == "" && (exp.ExitCode != 0 || exp.ExitSignal != nil):
+	// The error is already described by the outcome
+	case exp.Error == "" && outcome != "" && outcome != OutcomeExited:
 	case exp.Error == "" && r.Error != nil:
 		add("Expected no error")
 	case exp.Error != "" && r.Error == nil:
@@ -115,7 +284,8 @@ func (r *Result) match(exp Expected) error {
 	if len(errors) == 0 {
 		return nil
 	}
-	return fmt.Errorf("%s\nFailures:\n%s", r, strings.Join(errors, "\n"))
+	msg := fmt.Sprintf("%s\nFailures:\n%s", r.failureString(t), strings.Join(errors, "\n"))
+	return fmt.Errorf("%s", redact(msg, r.secrets))
 }
 
 func matchOutput(expected string, actual string) bool {
@@ -128,37 +298,89 @@ func matchOutput(expected string, actual string) bool {
 }
 
 func (r *Result) String() string {
+	return r.format(r.Stdout(), r.Stderr(), r.TTY())
+}
+
+func (r *Result) format(stdout, stderr, tty string) string {
 	var timeout string
 	if r.Timeout {
 		timeout = " (timeout)"
 	}
+	var attempts string
+	if len(r.Attempts) > 0 {
+		attempts = fmt.Sprintf(" (attempt %d)", len(r.Attempts)+1)
+	}
 	var errString string
 	if r.Error != nil {
 		errString = "\nError:    " + r.Error.Error()
 	}
+	var ttyString string
+	if r.tty != nil {
+		ttyString = fmt.Sprintf("TTY:      %v\n", tty)
+	}
 
-	return fmt.Sprintf(`
+	return redact(fmt.Sprintf(`
 Command:  %s
-ExitCode: %d%s%s
+ExitCode: %d%s%s%s
 Stdout:   %v
 Stderr:   %v
-`,
-		strings.Join(r.Cmd.Args, " "),
+%s`,
+		strings.Join(r.commandArgs(), " "),
 		r.ExitCode,
 		timeout,
+		attempts,
 		errString,
-		r.Stdout(),
-		r.Stderr())
+		stdout,
+		stderr,
+		ttyString), r.secrets)
+}
+
+func (r *Result) commandArgs() []string {
+	if r.Command == nil && r.Cmd != nil {
+		return r.Cmd.Args
+	}
+	return r.Command
 }
 
 // Expected is the expected output from a Command. This struct is compared to a
 // Result struct by Result.Assert().
 type Expected struct {
 	ExitCode int
-	Timeout  bool
-	Error    string
-	Out      string
-	Err      string
+	// ExitSignal is the signal expected to terminate the command. When
+	// ExitSignal is set ExitCode is not compared.
+	ExitSignal os.Signal
+	Timeout    bool
+	// Outcome is the expected Outcome of the command. When Outcome is set and
+	// is not OutcomeExited, ExitCode and Timeout are not compared.
+	Outcome Outcome
+	// StartError is the expected cause of a command failing to start. It is
+	// compared with errors.Is, so it may be exec.ErrNotFound, os.ErrNotExist,
+	// or os.ErrPermission. Setting StartError implies OutcomeStartFailed.
+	StartError error
+	// MinDuration and MaxDuration are bounds for Result.Duration. A zero value
+	// means there is no bound.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	Error       string
+	Out         string
+	Err         string
+	// OutMatch is a Matcher used to check stdout, in addition to Out.
+	OutMatch Matcher
+	// ErrMatch is a Matcher used to check stderr, in addition to Err.
+	ErrMatch Matcher
+	// OutGolden is the name of a golden file which must match stdout exactly.
+	// Running the tests with -update writes stdout to the golden file. See
+	// gotest.tools/v3/golden for details about golden files.
+	OutGolden string
+	// ErrGolden is the name of a golden file which must match stderr exactly.
+	// Running the tests with -update writes stderr to the golden file.
+	ErrGolden string
+	// TTY is the expected output written to the terminal of a command run
+	// WithTTY. Like Out, it must be contained in the output, or be None.
+	TTY string
+	// TTYMatch is a Matcher used to check the output written to the terminal,
+	// in addition to TTY.
+	TTYMatch Matcher
 }
 
 // Success is the default expected result. A Success result is one with a 0
@@ -175,6 +397,18 @@ func (r *Result) Stderr() string {
 	return r.errBuffer.String()
 }
 
+// StdoutDropped returns the number of bytes of stdout which were not captured
+// because the output exceeded the limit set by WithMaxOutput.
+func (r *Result) StdoutDropped() int64 {
+	return r.outBuffer.droppedBytes()
+}
+
+// StderrDropped returns the number of bytes of stderr which were not captured
+// because the output exceeded the limit set by WithMaxOutput.
+func (r *Result) StderrDropped() int64 {
+	return r.errBuffer.droppedBytes()
+}
+
 // Combined returns the stdout and stderr combined into a single string
 func (r *Result) Combined() string {
 	return r.outBuffer.String() + r.errBuffer.String()
@@ -186,6 +420,55 @@ func (r *Result) setExitError(err error) {
 	}
 	r.Error = err
 	r.ExitCode = processExitCode(err)
+	r.ExitSignal = processExitSignal(err)
+	if r.limits != nil {
+		r.LimitExceeded = r.limits.exceeded(r)
+	}
+}
+
+// killGroup kills the command, and any processes started by the command when
+// it was started in a new process group.
+func (r *Result) killGroup() error {
+	if r.group == nil {
+		return r.Cmd.Process.Kill()
+	}
+	return r.group.kill()
+}
+
+func (r *Result) releaseGroup() {
+	if r.group != nil {
+		r.group.release()
+	}
+}
+
+func (r *Result) flushOutput() {
+	for _, flush := range r.flush {
+		flush()
+	}
+}
+
+func (r *Result) setUsage() {
+	r.Duration = time.Since(r.started)
+	state := r.Cmd.ProcessState
+	if state == nil {
+		return
+	}
+	r.UserTime = state.UserTime()
+	r.SystemTime = state.SystemTime()
+	r.MaxRSS = maxRSS(state)
+}
+
+// Signal sends a signal to a command started with StartCmd. Use WaitOnCmd to
+// wait for the command to exit, and Expected.ExitSignal to assert that it was
+// terminated by the signal.
+//
+// Not all signals are supported on all platforms. On Windows only os.Kill can
+// be sent.
+func (r *Result) Signal(sig os.Signal) error {
+	if r.Cmd.Process == nil {
+		return errors.New("command has not been started")
+	}
+	return r.Cmd.Process.Signal(sig)
 }
 
 // Cmd contains the arguments and options for a process to run as part of a test
@@ -198,6 +481,39 @@ type Cmd struct {
 	Dir        string
 	Env        []string
 	ExtraFiles []*os.File
+	// MaxOutput is the maximum number of bytes of stdout, and of stderr,
+	// captured in the Result. See WithMaxOutput.
+	MaxOutput int
+	// StdinFile is the path to a file used as the standard input of the
+	// command. See WithStdinFile.
+	StdinFile string
+	// Stderr receives a copy of stderr, in addition to the copy kept in the
+	// Result.
+	Stderr io.Writer
+	// MergeStderr redirects stderr to stdout, like 2>&1 in a shell.
+	MergeStderr bool
+	// Limits are resource limits applied to the command. See WithLimits.
+	Limits *Limits
+	// Retry is used to re-run the command when it does not succeed. See
+	// WithRetries.
+	Retry *RetryPolicy
+	// Secrets are sensitive values which are redacted from failure messages.
+	// See WithSecrets.
+	Secrets []string
+	// SecretEnv are the names of environment variables which contain
+	// sensitive values. See WithSecretEnv.
+	SecretEnv []string
+	// Clock is used to wait for the Timeout. The default is the real clock.
+	// See WithClock.
+	Clock clock.Clock
+	// TTY runs the command with a pseudo-terminal. See WithTTY.
+	TTY bool
+	// ProcessGroup starts the command in a new process group, so that any
+	// processes started by the command are killed with it when the command
+	// times out. It is also used when Timeout is set. See WithProcessGroup.
+	ProcessGroup bool
+	// flush is called when the command exits to flush any buffered writers.
+	flush []func()
 }
 
 // Command create a simple Cmd with the specified command and arguments
@@ -210,6 +526,10 @@ func RunCmd(cmd Cmd, cmdOperators ...CmdOp) *Result {
 	for _, op := range cmdOperators {
 		op(&cmd)
 	}
+	return runWithRetries(cmd, runCmd)
+}
+
+func runCmd(cmd Cmd) *Result {
 	result := StartCmd(cmd)
 	if result.Error != nil {
 		return result
@@ -228,23 +548,59 @@ func StartCmd(cmd Cmd) *Result {
 	if result.Error != nil {
 		return result
 	}
-	result.setExitError(result.Cmd.Start())
+	result.start()
 	return result
 }
 
-// TODO: support exec.CommandContext
+func (r *Result) start() {
+	r.started = time.Now()
+	r.setExitError(r.Cmd.Start())
+	if r.Error == nil && r.killsGroup {
+		r.group = newProcessGroup(r.Cmd.Process)
+	}
+	if r.tty != nil {
+		if r.Error == nil {
+			go r.tty.read()
+		} else {
+			r.tty.master.Close() //nolint: errcheck
+		}
+	}
+	// The command has its own copy of these files once it has started
+	for _, f := range r.closeAfterStart {
+		f.Close() //nolint: errcheck
+	}
+}
+
 func buildCmd(cmd Cmd) *Result {
+	command := cmd.Command
+	var limitErr error
+	if cmd.Limits != nil {
+		if limited, err := limitCommand(command, cmd.Limits); err != nil {
+			limitErr = err
+		} else {
+			command = limited
+		}
+	}
+
 	var execCmd *exec.Cmd
-	switch len(cmd.Command) {
+	switch len(command) {
 	case 1:
-		execCmd = exec.Command(cmd.Command[0])
+		execCmd = exec.Command(command[0])
 	default:
-		execCmd = exec.Command(cmd.Command[0], cmd.Command[1:]...)
+		execCmd = exec.Command(command[0], command[1:]...)
 	}
-	outBuffer := new(lockedBuffer)
-	errBuffer := new(lockedBuffer)
+	outBuffer := newLimitedBuffer(cmd.MaxOutput)
+	errBuffer := newLimitedBuffer(cmd.MaxOutput)
 
 	execCmd.Stdin = cmd.Stdin
+	var stdinFile *os.File
+	var stdinErr error
+	if limitErr == nil {
+		stdinFile, stdinErr = openStdinFile(cmd)
+	}
+	if stdinFile != nil {
+		execCmd.Stdin = stdinFile
+	}
 	execCmd.Dir = cmd.Dir
 	execCmd.Env = cmd.Env
 	if cmd.Stdout != nil {
@@ -252,21 +608,67 @@ func buildCmd(cmd Cmd) *Result {
 	} else {
 		execCmd.Stdout = outBuffer
 	}
-	execCmd.Stderr = errBuffer
+	if cmd.Stderr != nil {
+		execCmd.Stderr = io.MultiWriter(errBuffer, cmd.Stderr)
+	} else {
+		execCmd.Stderr = errBuffer
+	}
+	if cmd.MergeStderr {
+		execCmd.Stderr = execCmd.Stdout
+	}
 	execCmd.ExtraFiles = cmd.ExtraFiles
+	killsGroup := cmd.ProcessGroup || cmd.Timeout > 0
+	if killsGroup {
+		setProcessGroup(execCmd)
+	}
 
-	return &Result{
-		Cmd:       execCmd,
-		outBuffer: outBuffer,
-		errBuffer: errBuffer,
+	var tty *ttyConn
+	var ttyErr error
+	if cmd.TTY && limitErr == nil && stdinErr == nil {
+		tty, ttyErr = newTTYConn(cmd)
+	}
+	if tty != nil {
+		tty.attach(execCmd)
 	}
+
+	result := &Result{
+		Cmd:        execCmd,
+		Command:    cmd.Command,
+		limits:     cmd.Limits,
+		flush:      cmd.flush,
+		secrets:    cmd.secrets(),
+		clock:      cmd.Clock,
+		outBuffer:  outBuffer,
+		errBuffer:  errBuffer,
+		tty:        tty,
+		killsGroup: killsGroup,
+	}
+	if stdinFile != nil {
+		result.closeAfterStart = append(result.closeAfterStart, stdinFile)
+	}
+	if tty != nil {
+		result.closeAfterStart = append(result.closeAfterStart, tty.slave)
+	}
+	switch {
+	case limitErr != nil:
+		result.setExitError(limitErr)
+	case stdinErr != nil:
+		result.setExitError(stdinErr)
+	case ttyErr != nil:
+		result.setExitError(ttyErr)
+	}
+	return result
 }
 
 // WaitOnCmd waits for a command to complete. If timeout is non-nil then
-// only wait until the timeout.
+// only wait until the timeout. When the timeout is reached the command is
+// killed. Any processes started by the command are also killed if the command
+// was started with WithProcessGroup, or with a Timeout.
 func WaitOnCmd(timeout time.Duration, result *Result) *Result {
 	if timeout == time.Duration(0) {
 		result.setExitError(result.Cmd.Wait())
+		result.setUsage()
+		result.finish()
 		return result
 	}
 
@@ -277,14 +679,38 @@ func WaitOnCmd(timeout time.Duration, result *Result) *Result {
 	}()
 
 	select {
-	case <-time.After(timeout):
-		killErr := result.Cmd.Process.Kill()
+	case <-result.timeoutClock().After(timeout):
+		killErr := result.killGroup()
 		if killErr != nil {
 			fmt.Printf("failed to kill (pid=%d): %v\n", result.Cmd.Process.Pid, killErr)
 		}
+		// Wait for Cmd.Wait to return, so the output is no longer being
+		// copied when finish flushes the output and closes the tty. The error
+		// is the kill, which is reported by Timeout.
+		<-done
 		result.Timeout = true
+		result.setUsage()
 	case err := <-done:
 		result.setExitError(err)
+		result.setUsage()
 	}
+	result.finish()
 	return result
 }
+
+func (r *Result) timeoutClock() clock.Clock {
+	if r.clock == nil {
+		return clock.Real()
+	}
+	return r.clock
+}
+
+// finish releases the resources used to run the command once it has exited.
+func (r *Result) finish() {
+	r.stopWatchingContext()
+	r.releaseGroup()
+	if r.tty != nil {
+		r.tty.close()
+	}
+	r.flushOutput()
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/clock"
 	"gotest.tools/v3/fs"
 	"gotest.tools/v3/internal/maint"
 )
@@ -78,6 +79,21 @@ func TestRunCommandWithTimeoutKilled(t *testing.T) {
 	result.Assert(t, Expected{Timeout: true, Out: None, Err: None})
 }
 
+func TestRunCommandWithTimeoutFakeClock(t *testing.T) {
+	buildStub(t)
+
+	fake := clock.NewFake(time.Now())
+	done := make(chan *Result)
+	go func() {
+		done <- RunCmd(Command(binname, "-sleep=10s"), WithTimeout(time.Minute), WithClock(fake))
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+
+	result := <-done
+	result.Assert(t, Expected{Timeout: true, Out: None, Err: None})
+}
+
 func TestRunCommandWithErrors(t *testing.T) {
 	buildStub(t)
 
@@ -133,6 +149,11 @@ Failures:
 ExitCode was 99 expected 101
 Expected command to finish, but it hit the timeout
 Expected stdout to contain "Something else"
+--- expected stdout
++++ actual stdout
+@@ -1 +1 @@
+-Something else
++the output
 Expected stderr to contain "[NOTHING]"`
 
 func newLockedBuffer(s string) *lockedBuffer {
@@ -164,6 +185,11 @@ Stderr:   the stderr
 Failures:
 ExitCode was 0 expected 101
 Expected stdout to contain "Something else"
+--- expected stdout
++++ actual stdout
+@@ -1 +1 @@
+-Something else
++the output
 Expected stderr to contain "[NOTHING]"`
 
 func TestResult_Match_Match(t *testing.T) {
@@ -179,3 +205,20 @@ func TestResult_Match_Match(t *testing.T) {
 	err := result.match(exp)
 	assert.NilError(t, err)
 }
+
+func TestRunCmdWithStdoutAndStderrWriters(t *testing.T) {
+	buildStub(t)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	result := RunCmd(Command(binname, "-warn"), WithStdout(stdout), WithStderr(stderr))
+	result.Assert(t, Expected{Out: "this is stdout", Err: "this is stderr"})
+	assert.Equal(t, stdout.String(), "this is stdout\n")
+	assert.Equal(t, stderr.String(), "this is stderr\n")
+}
+
+func TestRunCmdWithMergedStderr(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-warn"), WithMergedStderr())
+	result.Assert(t, Expected{Out: "this is stdout\nthis is stderr\n", Err: None})
+}
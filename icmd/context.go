@@ -0,0 +1,91 @@
+package icmd
+
+import (
+	"context"
+	"sync"
+)
+
+// RunCmdContext runs a command and returns a Result. If ctx is done before
+// the command exits, the command and any processes it started are killed, and
+// Result.Error is set to ctx.Err().
+//
+// Cmd.Timeout still applies. Use context.WithTimeout, or a context which is
+// cancelled when the test ends, to coordinate the lifetime of commands with
+// other parts of a test.
+func RunCmdContext(ctx context.Context, cmd Cmd, cmdOperators ...CmdOp) *Result {
+	for _, op := range cmdOperators {
+		op(&cmd)
+	}
+	return runWithRetries(cmd, func(cmd Cmd) *Result {
+		result := StartCmdContext(ctx, cmd)
+		if result.Error != nil {
+			return result
+		}
+		return WaitOnCmd(cmd.Timeout, result)
+	})
+}
+
+// StartCmdContext starts a command, but doesn't wait for it to finish. If ctx
+// is done before the command exits, the command and any processes it started
+// are killed. Use WaitOnCmd to wait for the command to exit.
+//
+// If ctx is already done the command is not started, and Result.Error is set
+// to ctx.Err().
+func StartCmdContext(ctx context.Context, cmd Cmd) *Result {
+	cmd.ProcessGroup = true
+	result := buildCmd(cmd)
+	if result.Error != nil {
+		return result
+	}
+	if err := ctx.Err(); err != nil {
+		result.setExitError(err)
+		return result
+	}
+	result.start()
+	if result.Error == nil {
+		result.watchContext(ctx)
+	}
+	return result
+}
+
+// contextWatch kills a command when a context is done.
+type contextWatch struct {
+	ctx    context.Context
+	exited chan struct{}
+	mu     sync.Mutex
+	killed bool
+	done   bool
+}
+
+func (r *Result) watchContext(ctx context.Context) {
+	watch := &contextWatch{ctx: ctx, exited: make(chan struct{})}
+	r.watch = watch
+	go func() {
+		select {
+		case <-ctx.Done():
+			watch.mu.Lock()
+			defer watch.mu.Unlock()
+			if !watch.done {
+				watch.killed = true
+				r.killGroup() //nolint: errcheck
+			}
+		case <-watch.exited:
+		}
+	}()
+}
+
+// stopWatchingContext stops the goroutine which watches the context, and sets
+// Result.Error if the command was killed because the context was done.
+func (r *Result) stopWatchingContext() {
+	if r.watch == nil {
+		return
+	}
+	r.watch.mu.Lock()
+	r.watch.done = true
+	if r.watch.killed {
+		r.Error = r.watch.ctx.Err()
+	}
+	r.watch.mu.Unlock()
+	close(r.watch.exited)
+	r.watch = nil
+}
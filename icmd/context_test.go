@@ -0,0 +1,55 @@
+package icmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunCmdContextCancelled(t *testing.T) {
+	buildStub(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := RunCmdContext(ctx, Command(binname, "-sleep=10s"))
+	assert.Assert(t, errors.Is(result.Error, context.DeadlineExceeded), result.Error)
+	assert.Assert(t, result.Duration < 5*time.Second)
+	assert.Equal(t, result.Outcome(), OutcomeSignaled)
+}
+
+func TestRunCmdContextFinished(t *testing.T) {
+	buildStub(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := RunCmdContext(ctx, Command(binname, "-warn"), WithTimeout(5*time.Second))
+	cancel()
+	result.Assert(t, Expected{Out: "this is stdout", Err: "this is stderr"})
+}
+
+func TestStartCmdContextAlreadyDone(t *testing.T) {
+	buildStub(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := StartCmdContext(ctx, Command(binname))
+	assert.Assert(t, errors.Is(result.Error, context.Canceled))
+	assert.Equal(t, result.Outcome(), OutcomeStartFailed)
+}
+
+func TestStartCmdContextWait(t *testing.T) {
+	buildStub(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := StartCmdContext(ctx, Command(binname, "-sleep=10s"))
+	assert.NilError(t, result.Error)
+	cancel()
+
+	WaitOnCmd(5*time.Second, result)
+	assert.Assert(t, errors.Is(result.Error, context.Canceled))
+	assert.Assert(t, !result.Timeout)
+}
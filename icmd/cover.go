@@ -0,0 +1,60 @@
+package icmd
+
+import (
+	"flag"
+	"os"
+
+	"gotest.tools/v3/assert"
+)
+
+// CoverDir returns the directory where coverage data for the current test run
+// is written, or an empty string if coverage is not enabled. The directory is
+// read from the -test.gocoverdir flag set by 'go test -cover', or from the
+// GOCOVERDIR environment variable.
+func CoverDir() string {
+	if f := flag.Lookup("test.gocoverdir"); f != nil && f.Value.String() != "" {
+		return f.Value.String()
+	}
+	return os.Getenv("GOCOVERDIR")
+}
+
+// WithCoverDir sets GOCOVERDIR in the environment of the command so that a
+// binary built with 'go build -cover' writes its coverage data to dir. The
+// variable is added to the environment of the command, or to the environment
+// of the current process if no environment was set. WithEnv replaces the
+// environment, so WithCoverDir must be applied after WithEnv.
+//
+// If dir is empty the command is not modified.
+func WithCoverDir(dir string) CmdOp {
+	return func(c *Cmd) {
+		if dir == "" {
+			return
+		}
+		if c.Env == nil {
+			c.Env = os.Environ()
+		}
+		c.Env = append(c.Env, "GOCOVERDIR="+dir)
+	}
+}
+
+// WithCoverage is WithCoverDir(CoverDir()). Commands run with WithCoverage
+// contribute to the coverage profile of the test run when the test is run
+// with 'go test -cover'.
+func WithCoverage() CmdOp {
+	return WithCoverDir(CoverDir())
+}
+
+// BuildGoBinary builds the Go package pkg into the binary at output. When cover
+// is true the binary is built with -cover, so that running it with
+// WithCoverage writes coverage data. BuildGoBinary fails the test if the build
+// fails.
+func BuildGoBinary(t assert.TestingT, pkg, output string, cover bool) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	args := []string{"build", "-o", output}
+	if cover {
+		args = append(args, "-cover")
+	}
+	RunCommand("go", append(args, pkg)...).Assert(t, Success)
+}
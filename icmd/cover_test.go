@@ -0,0 +1,49 @@
+package icmd
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+func TestWithCoverDir(t *testing.T) {
+	cmd := Command("true")
+	WithCoverDir("/coverage")(&cmd)
+	assert.Assert(t, cmp.Contains(cmd.Env, "GOCOVERDIR=/coverage"))
+	assert.Assert(t, len(cmd.Env) > 1, "expected the process environment")
+
+	cmd = Command("true")
+	WithEnv("A=b")(&cmd)
+	WithCoverDir("/coverage")(&cmd)
+	assert.DeepEqual(t, cmd.Env, []string{"A=b", "GOCOVERDIR=/coverage"})
+
+	cmd = Command("true")
+	WithCoverDir("")(&cmd)
+	assert.Assert(t, cmd.Env == nil)
+}
+
+func TestBuildGoBinaryWithCover(t *testing.T) {
+	dir := fs.NewDir(t, "cover")
+	coverdir := fs.NewDir(t, "coverdata")
+	bin := dir.Join("stub") + pathext()
+
+	BuildGoBinary(t, stubpath, bin, true)
+	RunCmd(Command(bin), WithCoverDir(coverdir.Path())).Assert(t, Success)
+
+	files, err := ioutil.ReadDir(coverdir.Path())
+	assert.NilError(t, err)
+	assert.Assert(t, len(files) > 0, "expected coverage data in %s", coverdir.Path())
+}
+
+func TestCoverDirFromEnv(t *testing.T) {
+	if f := flag.Lookup("test.gocoverdir"); f != nil && f.Value.String() != "" {
+		t.Skip("coverage is enabled by -test.gocoverdir")
+	}
+	env.Patch(t, "GOCOVERDIR", "/coverage")
+	assert.Equal(t, CoverDir(), "/coverage")
+}
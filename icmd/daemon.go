@@ -0,0 +1,187 @@
+package icmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotest.tools/v3/artifacts"
+	"gotest.tools/v3/internal/cleanup"
+	"gotest.tools/v3/internal/overflow"
+	"gotest.tools/v3/nettest"
+	"gotest.tools/v3/poll"
+)
+
+// DaemonT is the subset of testing.T used by StartDaemon
+type DaemonT interface {
+	poll.TestingT
+}
+
+type failedT interface {
+	Failed() bool
+}
+
+// DefaultStopTimeout is the time Daemon.Stop waits for the daemon to exit
+// after it is interrupted, before it is killed.
+var DefaultStopTimeout = 5 * time.Second
+
+// Daemon is a long running command started by StartDaemon.
+type Daemon struct {
+	// Result of the command. Stdout and Stderr may be read while the daemon is
+	// running. The other fields are set once the daemon has exited.
+	Result *Result
+	// StopTimeout is the time Stop waits for the daemon to exit after it is
+	// interrupted, before it is killed. Defaults to DefaultStopTimeout.
+	StopTimeout time.Duration
+
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// Readiness returns a poll.Check which succeeds once the daemon is ready.
+type Readiness func(d *Daemon) poll.Check
+
+// ReadyWhenOutput returns a Readiness which is ready once the stdout or
+// stderr of the daemon contains substr.
+func ReadyWhenOutput(substr string) Readiness {
+	return func(d *Daemon) poll.Check {
+		return func(t poll.LogT) poll.Result {
+			if strings.Contains(d.Result.Combined(), substr) {
+				return poll.Success()
+			}
+			return poll.Continue("output does not contain %q", substr)
+		}
+	}
+}
+
+// ReadyWhenListening returns a Readiness which is ready once a connection can
+// be opened to address. See net.Dial for a description of network and address.
+func ReadyWhenListening(network, address string) Readiness {
+	return func(d *Daemon) poll.Check {
+		return nettest.Listening(network, address)
+	}
+}
+
+// ReadyWhenHTTP returns a Readiness which is ready once a GET request to url
+// returns 200 OK.
+func ReadyWhenHTTP(url string) Readiness {
+	client := &http.Client{Timeout: time.Second}
+	return func(d *Daemon) poll.Check {
+		return func(t poll.LogT) poll.Result {
+			resp, err := client.Get(url)
+			if err != nil {
+				return poll.Continue("GET %s failed: %s", url, err)
+			}
+			resp.Body.Close() //nolint: errcheck
+			if resp.StatusCode != http.StatusOK {
+				return poll.Continue("GET %s returned %s", url, resp.Status)
+			}
+			return poll.Success()
+		}
+	}
+}
+
+// StartDaemon starts a long running command, and waits until ready succeeds.
+// If the command exits, or is not ready before the poll timeout, the test
+// fails. The pollOps configure the wait, see poll.WaitOn.
+//
+// When the test ends the daemon is stopped with Daemon.Stop. If the test
+// failed the output of the daemon is logged. If t provides a Name, the output
+// is also written to the artifacts directory of the test, see
+// WithOutputArtifacts.
+//
+// Cmd.Timeout is ignored. The daemon runs until it is stopped.
+func StartDaemon(t DaemonT, cmd Cmd, ready Readiness, pollOps ...poll.SettingOp) *Daemon {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if at, ok := t.(artifacts.TestingT); ok {
+		n := atomic.AddInt32(&daemonCount, 1)
+		name := fmt.Sprintf("%s-%d", artifactName("daemon", cmd), n)
+		WithOutputArtifacts(at, name)(&cmd)
+	}
+	cmd.ProcessGroup = true
+	d := &Daemon{
+		Result:      StartCmd(cmd),
+		StopTimeout: DefaultStopTimeout,
+		done:        make(chan struct{}),
+	}
+	if d.Result.Error != nil {
+		t.Fatalf("failed to start daemon: %s", d.Result)
+	}
+	go func() {
+		WaitOnCmd(0, d.Result)
+		close(d.done)
+	}()
+
+	cleanup.Cleanup(t, func() {
+		d.Stop()
+		if ft, ok := t.(failedT); ok && ft.Failed() {
+			t.Log(overflow.Limit(t, "daemon output:"+d.Result.String()))
+		}
+	})
+
+	check := ready(d)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		select {
+		case <-d.done:
+			return poll.Error(&daemonExitedError{result: d.Result})
+		default:
+		}
+		return check(t)
+	}, pollOps...)
+	return d
+}
+
+// daemonCount is used to give the artifacts of each daemon a unique name
+var daemonCount int32
+
+type daemonExitedError struct {
+	result *Result
+}
+
+func (e *daemonExitedError) Error() string {
+	return "daemon exited before it was ready:" + e.result.String()
+}
+
+// Running returns true if the daemon has not exited.
+func (d *Daemon) Running() bool {
+	select {
+	case <-d.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Stop the daemon, and return its Result. Stop interrupts the daemon so that
+// it can shut down gracefully. If the daemon does not exit before
+// StopTimeout it is killed, along with any processes it started. On Windows
+// the daemon is always killed, because interrupts are not supported.
+//
+// Stop may be called more than once. It is called automatically when the test
+// ends.
+func (d *Daemon) Stop() *Result {
+	d.stopOnce.Do(func() {
+		if !d.Running() {
+			return
+		}
+		if runtime.GOOS != "windows" {
+			d.Result.Signal(os.Interrupt) //nolint: errcheck
+			select {
+			case <-d.done:
+				return
+			case <-time.After(d.StopTimeout):
+			}
+		}
+		d.Result.killGroup() //nolint: errcheck
+		<-d.done
+	})
+	<-d.done
+	return d.Result
+}
@@ -0,0 +1,101 @@
+package icmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
+)
+
+type daemonFakeT struct {
+	failed string
+}
+
+func (t *daemonFakeT) Log(args ...interface{}) {}
+
+func (t *daemonFakeT) Logf(format string, args ...interface{}) {}
+
+func (t *daemonFakeT) Fatalf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+	panic("exit start daemon")
+}
+
+func TestStartDaemonGracefulStop(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt is not supported on windows")
+	buildStub(t)
+
+	d := StartDaemon(t, Command(binname, "-trap"), ReadyWhenOutput("waiting for signal"),
+		poll.WithDelay(10*time.Millisecond))
+	assert.Assert(t, d.Running())
+
+	result := d.Stop()
+	assert.Assert(t, !d.Running())
+	result.Assert(t, Expected{Out: "got signal interrupt"})
+	assert.Equal(t, d.Stop(), result)
+}
+
+func TestStartDaemonKilledAfterStopTimeout(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+
+	d := StartDaemon(t, Command("sh", "-c", `trap "" INT; echo started; sleep 10`),
+		ReadyWhenOutput("started"), poll.WithDelay(10*time.Millisecond))
+	d.StopTimeout = 50 * time.Millisecond
+
+	result := d.Stop()
+	assert.Equal(t, result.ExitSignal, syscall.SIGKILL)
+}
+
+func TestStartDaemonExitsBeforeReady(t *testing.T) {
+	buildStub(t)
+	fakeT := &daemonFakeT{}
+
+	assert.Assert(t, cmp.Panics(func() {
+		StartDaemon(fakeT, Command(binname, "-fail=4"), ReadyWhenOutput("never"),
+			poll.WithDelay(10*time.Millisecond))
+	}))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "daemon exited before it was ready"))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "ExitCode: 4"))
+}
+
+func TestStartDaemonStartFailure(t *testing.T) {
+	fakeT := &daemonFakeT{}
+
+	assert.Assert(t, cmp.Panics(func() {
+		StartDaemon(fakeT, Command("doesnotexists"), ReadyWhenOutput("never"))
+	}))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "failed to start daemon"))
+}
+
+func TestReadyWhenHTTP(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	check := ReadyWhenHTTP(server.URL)(nil)
+	result := check(t)
+	assert.Assert(t, !result.Done())
+	assert.Equal(t, result.Message(), "GET "+server.URL+" returned 503 Service Unavailable")
+
+	status = http.StatusOK
+	assert.Assert(t, check(t).Done())
+}
+
+func TestReadyWhenListening(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer listener.Close()
+
+	check := ReadyWhenListening("tcp", listener.Addr().String())(nil)
+	assert.Assert(t, check(t).Done())
+}
@@ -2,6 +2,7 @@ package icmd_test
 
 import (
 	"testing"
+	"time"
 
 	"gotest.tools/v3/icmd"
 )
@@ -20,3 +21,14 @@ func ExampleRunCmd() {
 		Err:      "cat: /does/not/exist: No such file or directory",
 	})
 }
+
+func ExampleWithTTY() {
+	cmd := icmd.Command("mycli", "init")
+	icmd.WithTTY()(&cmd)
+	result := icmd.StartCmd(cmd)
+	result.WaitForTTYLine(t, "Project name", 5*time.Second)
+	_ = result.WriteTTY("example\n")
+	icmd.WaitOnCmd(10*time.Second, result).Assert(t, icmd.Expected{
+		TTY: "Created project example",
+	})
+}
@@ -0,0 +1,30 @@
+//go:build !plan9
+// +build !plan9
+
+package icmd
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// signaledStatus is implemented by syscall.WaitStatus on platforms which
+// support signals.
+type signaledStatus interface {
+	Signaled() bool
+	Signal() syscall.Signal
+}
+
+func processExitSignal(err error) os.Signal {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ProcessState == nil {
+		return nil
+	}
+	status, ok := exitErr.ProcessState.Sys().(signaledStatus)
+	if !ok || !status.Signaled() {
+		return nil
+	}
+	return status.Signal()
+}
@@ -0,0 +1,9 @@
+package icmd
+
+import "os"
+
+// processExitSignal returns nil, because the exit status of a process on plan9
+// does not include a signal.
+func processExitSignal(err error) os.Signal {
+	return nil
+}
@@ -0,0 +1,33 @@
+package icmd
+
+import (
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+// WithDir sets the working directory of the command to the path of dir. dir
+// is usually a *fs.Dir created by fs.NewDir.
+func WithDir(dir fs.Path) CmdOp {
+	return Dir(dir.Path())
+}
+
+// RunCmdInDir creates a new temporary directory with fs.NewDir, applies the
+// PathOps to it, and runs the command with the directory as the working
+// directory. The directory is returned so that the test can compare the
+// resulting tree with fs.Equal.
+//
+// The directory is removed when the test ends, see fs.NewDir.
+//
+// Example:
+//
+//	result, dir := icmd.RunCmdInDir(t, icmd.Command("mytool", "init"),
+//		fs.WithFile("config.yaml", "name: example"))
+//	result.Assert(t, icmd.Success)
+//	assert.Assert(t, fs.Equal(dir.Path(), expected))
+func RunCmdInDir(t assert.TestingT, cmd Cmd, ops ...fs.PathOp) (*Result, *fs.Dir) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	dir := fs.NewDir(t, "icmd-fixture", ops...)
+	return RunCmd(cmd, WithDir(dir)), dir
+}
@@ -0,0 +1,32 @@
+package icmd
+
+import (
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/skip"
+)
+
+func TestWithDir(t *testing.T) {
+	dir := fs.NewDir(t, "icmd-with-dir")
+	cmd := Command("true")
+	WithDir(dir)(&cmd)
+	assert.Equal(t, cmd.Dir, dir.Path())
+}
+
+func TestRunCmdInDir(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+
+	result, dir := RunCmdInDir(t,
+		Command("sh", "-c", "cat input > output && rm input"),
+		fs.WithFile("input", "the content"),
+		fs.WithDir("sub"))
+	result.Assert(t, Success)
+
+	expected := fs.Expected(t,
+		fs.WithFile("output", "the content"),
+		fs.WithDir("sub"))
+	assert.Assert(t, fs.Equal(dir.Path(), expected))
+}
@@ -0,0 +1,23 @@
+package icmd
+
+import (
+	"gotest.tools/v3/golden"
+)
+
+type failureMessage interface {
+	FailureMessage() string
+}
+
+// matchGolden compares actual to the golden file filename and returns the
+// failure message if they are not equal. The golden file is updated when
+// tests are run with -update.
+func matchGolden(actual, filename string) (string, bool) {
+	result := golden.String(actual, filename)()
+	if result.Success() {
+		return "", true
+	}
+	if msg, ok := result.(failureMessage); ok {
+		return msg.FailureMessage(), false
+	}
+	return "", false
+}
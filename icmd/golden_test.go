@@ -0,0 +1,30 @@
+package icmd
+
+import (
+	"os/exec"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunCommandWithGoldenOutput(t *testing.T) {
+	buildStub(t)
+
+	result := RunCommand(binname, "-warn")
+	result.Assert(t, Expected{
+		OutGolden: "stub-stdout.golden",
+		ErrGolden: "stub-stderr.golden",
+	})
+}
+
+func TestResult_Match_GoldenNotMatched(t *testing.T) {
+	result := &Result{
+		Cmd:       exec.Command("binary", "arg1"),
+		outBuffer: newLockedBuffer("other output\n"),
+		errBuffer: newLockedBuffer(""),
+	}
+	err := result.match(Expected{OutGolden: "stub-stdout.golden"})
+	assert.ErrorContains(t, err, "Expected stdout to match golden file stub-stdout.golden:")
+	assert.ErrorContains(t, err, "+other output")
+	assert.ErrorContains(t, err, "-this is stdout")
+}
@@ -0,0 +1,117 @@
+package icmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// GroupResult stores the results of running a group of commands with RunGroup
+type GroupResult struct {
+	// Results contains the Result of each command, in the same order as the
+	// commands passed to RunGroup.
+	Results []*Result
+}
+
+// RunGroup starts all the commands concurrently, and waits for all of them to
+// finish. If timeout is non-zero it is a deadline shared by all the commands.
+// Any command still running when the deadline is reached is killed, and its
+// Result is marked as a timeout. A Cmd.Timeout shorter than the shared deadline
+// still applies to that command.
+//
+// Every command has exited when RunGroup returns, so no cleanup is required.
+func RunGroup(timeout time.Duration, cmds ...Cmd) *GroupResult {
+	group := &GroupResult{Results: make([]*Result, len(cmds))}
+	for i, cmd := range cmds {
+		group.Results[i] = StartCmd(cmd)
+	}
+
+	waitAll(timeout, cmds, group.Results)
+	return group
+}
+
+// waitAll waits for all of the started commands to exit, using timeout as a
+// deadline shared by all of the commands.
+func waitAll(timeout time.Duration, cmds []Cmd, results []*Result) {
+	var deadline time.Time
+	if timeout != 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	var wg sync.WaitGroup
+	for i, result := range results {
+		if result.Error != nil || result.Cmd.Process == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(cmd Cmd, result *Result) {
+			defer wg.Done()
+			WaitOnCmd(groupTimeout(cmd.Timeout, deadline), result)
+		}(cmds[i], result)
+	}
+	wg.Wait()
+}
+
+func groupTimeout(timeout time.Duration, deadline time.Time) time.Duration {
+	if deadline.IsZero() {
+		return timeout
+	}
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		// WaitOnCmd treats a zero timeout as no timeout
+		remaining = time.Nanosecond
+	}
+	if timeout != 0 && timeout < remaining {
+		return timeout
+	}
+	return remaining
+}
+
+// Assert compares the Result of every command in the group against the
+// Expected struct, and fails the test if any of the expectations are not met.
+//
+// This function is equivalent to assert.Assert(t, group.Equal(exp)).
+func (g *GroupResult) Assert(t assert.TestingT, exp Expected) *GroupResult {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.Assert(t, g.equal(t, exp))
+	return g
+}
+
+// Equal compares the Result of every command in the group to Expected. If any
+// of the results do not match expected returns a failure message which
+// includes the failure message of each command that did not match.
+func (g *GroupResult) Equal(exp Expected) cmp.Comparison {
+	return g.equal(nil, exp)
+}
+
+func (g *GroupResult) equal(t interface{}, exp Expected) cmp.Comparison {
+	return func() cmp.Result {
+		return cmp.ResultFromError(g.compare(t, exp))
+	}
+}
+
+// Compare the Result of every command in the group to Expected and return an
+// error if any of them do not match.
+func (g *GroupResult) Compare(exp Expected) error {
+	return g.compare(nil, exp)
+}
+
+func (g *GroupResult) compare(t interface{}, exp Expected) error {
+	var failures []string
+	for i, result := range g.Results {
+		if err := result.matchT(t, exp); err != nil {
+			failures = append(failures, fmt.Sprintf("command %d of %d:%s", i+1, len(g.Results), err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d of %d commands did not match\n%s",
+		len(failures), len(g.Results), strings.Join(failures, "\n"))
+}
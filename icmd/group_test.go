@@ -0,0 +1,42 @@
+package icmd
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunGroupSuccess(t *testing.T) {
+	buildStub(t)
+
+	group := RunGroup(2*time.Second,
+		Command(binname, "-sleep=10ms"),
+		Command(binname, "-warn"))
+	group.Assert(t, Expected{Out: "this is stdout"})
+	assert.Equal(t, len(group.Results), 2)
+	assert.Equal(t, group.Results[0].Stderr(), "")
+	assert.Equal(t, group.Results[1].Stderr(), "this is stderr\n")
+}
+
+func TestRunGroupSharedDeadline(t *testing.T) {
+	buildStub(t)
+
+	group := RunGroup(50*time.Millisecond,
+		Command(binname),
+		Command(binname, "-sleep=2s"))
+	group.Results[0].Assert(t, Success)
+	group.Results[1].Assert(t, Expected{Timeout: true, Out: None})
+
+	err := group.Compare(Success)
+	assert.ErrorContains(t, err, "1 of 2 commands did not match")
+	assert.ErrorContains(t, err, "command 2 of 2:")
+}
+
+func TestRunGroupStartFailure(t *testing.T) {
+	buildStub(t)
+
+	group := RunGroup(0, Command("doesnotexists"), Command(binname))
+	group.Results[0].Assert(t, Expected{ExitCode: 127, Error: "executable file not found"})
+	group.Results[1].Assert(t, Success)
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"os/signal"
+	"strconv"
 	"time"
 )
 
@@ -11,8 +14,33 @@ func main() {
 	sleep := flag.Duration("sleep", 0, "Sleep")
 	warn := flag.Bool("warn", false, "Warn")
 	fail := flag.Int("fail", 0, "Fail with code")
+	counter := flag.String("counter", "", "File used to count the number of runs")
+	failRuns := flag.Int("fail-runs", 0, "Fail the first n runs counted in -counter")
+	trap := flag.Bool("trap", false, "Wait for an interrupt and exit gracefully")
+	openFiles := flag.Int("open-files", 0, "Open n files before exiting")
+	stderr := flag.String("stderr", "", "Print the value to stderr")
 	flag.Parse()
 
+	if *stderr != "" {
+		fmt.Fprintln(os.Stderr, *stderr)
+	}
+
+	for i := 0; i < *openFiles; i++ {
+		if _, err := os.Open(os.DevNull); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	if *trap {
+		waitForInterrupt()
+	}
+
+	if *counter != "" && countRun(*counter) <= *failRuns {
+		fmt.Println("this is a failed run")
+		os.Exit(3)
+	}
+
 	if *sleep != 0 {
 		time.Sleep(*sleep)
 	}
@@ -24,3 +52,22 @@ func main() {
 
 	os.Exit(*fail)
 }
+
+// countRun increments the count stored in path and returns the new value.
+func countRun(path string) int {
+	raw, _ := ioutil.ReadFile(path)
+	count, _ := strconv.Atoi(string(raw))
+	count++
+	if err := ioutil.WriteFile(path, []byte(strconv.Itoa(count)), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return count
+}
+
+func waitForInterrupt() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	fmt.Println("waiting for signal")
+	fmt.Printf("got signal %v\n", <-signals)
+}
@@ -0,0 +1,101 @@
+package icmd
+
+import (
+	"strconv"
+	"time"
+)
+
+// Limits are resource limits applied to a command. A zero value for any field
+// means the resource is not limited.
+//
+// Limits are only supported on platforms which provide setrlimit, and are
+// applied by running the command with the ulimit builtin of /bin/sh, so
+// Result.Cmd.Args is the shell. Result.Command is the command which was run.
+type Limits struct {
+	// Memory is the maximum size of the virtual memory of the process, in bytes
+	Memory uint64
+	// OpenFiles is the maximum number of file descriptors the process may
+	// have open
+	OpenFiles uint64
+	// CPUTime is the maximum amount of CPU time the process may use. It is
+	// rounded up to the nearest second.
+	CPUTime time.Duration
+}
+
+// Resource identifies a resource which can be limited with Limits
+type Resource string
+
+// ResourceCPUTime is recorded as Result.LimitExceeded when the command was
+// terminated because it used more CPU time than Limits.CPUTime.
+const ResourceCPUTime Resource = "cpu time"
+
+// ulimits returns the ulimit shell commands used to apply the limits
+func (l *Limits) ulimits() []string {
+	var cmds []string
+	if l.Memory != 0 {
+		cmds = append(cmds, "ulimit -v "+uitoa((l.Memory+1023)/1024))
+	}
+	if l.OpenFiles != 0 {
+		cmds = append(cmds, "ulimit -n "+uitoa(l.OpenFiles))
+	}
+	if l.CPUTime != 0 {
+		// The soft limit sends SIGXCPU, which is used to detect the limit. The
+		// hard limit sends SIGKILL, in case the command handles SIGXCPU.
+		seconds := uint64((l.CPUTime + time.Second - 1) / time.Second)
+		cmds = append(cmds, "ulimit -S -t "+uitoa(seconds), "ulimit -H -t "+uitoa(seconds+1))
+	}
+	return cmds
+}
+
+// exceeded returns the limit which terminated the command, if any. Only the
+// CPU time limit terminates the command, with SIGXCPU. When the other limits
+// are reached the system calls of the command fail, and the command reports
+// the error in its own way, so those limits are not detected.
+func (l *Limits) exceeded(r *Result) Resource {
+	if l.CPUTime != 0 && isCPULimitSignal(r.ExitSignal) {
+		return ResourceCPUTime
+	}
+	return ""
+}
+
+// WithLimits sets resource limits for the command. See Limits for details.
+//
+// Result.LimitExceeded records if the command was terminated by the CPU time
+// limit.
+func WithLimits(limits Limits) CmdOp {
+	return func(c *Cmd) {
+		c.Limits = &limits
+	}
+}
+
+// WithMaxMemory limits the virtual memory of the command to bytes.
+func WithMaxMemory(bytes uint64) CmdOp {
+	return func(c *Cmd) {
+		c.limits().Memory = bytes
+	}
+}
+
+// WithMaxOpenFiles limits the number of open file descriptors of the command.
+func WithMaxOpenFiles(n uint64) CmdOp {
+	return func(c *Cmd) {
+		c.limits().OpenFiles = n
+	}
+}
+
+// WithMaxCPUTime limits the CPU time used by the command.
+func WithMaxCPUTime(d time.Duration) CmdOp {
+	return func(c *Cmd) {
+		c.limits().CPUTime = d
+	}
+}
+
+func (c *Cmd) limits() *Limits {
+	if c.Limits == nil {
+		c.Limits = &Limits{}
+	}
+	return c.Limits
+}
+
+func uitoa(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,18 @@
+//go:build windows || js || wasip1 || plan9
+// +build windows js wasip1 plan9
+
+package icmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+func limitCommand(command []string, limits *Limits) ([]string, error) {
+	return nil, fmt.Errorf("resource limits are not supported on %s", runtime.GOOS)
+}
+
+func isCPULimitSignal(sig os.Signal) bool {
+	return false
+}
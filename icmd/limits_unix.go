@@ -0,0 +1,21 @@
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
+
+package icmd
+
+import (
+	"os"
+	"strings"
+	"syscall"
+)
+
+// limitCommand wraps command with a shell which applies the limits before
+// replacing itself with command.
+func limitCommand(command []string, limits *Limits) ([]string, error) {
+	script := strings.Join(append(limits.ulimits(), `exec "$0" "$@"`), " && ")
+	return append([]string{"/bin/sh", "-c", script}, command...), nil
+}
+
+func isCPULimitSignal(sig os.Signal) bool {
+	return sig == syscall.SIGXCPU
+}
@@ -0,0 +1,40 @@
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
+
+package icmd
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestRunCmdWithMaxOpenFilesExceeded(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-open-files=64"), WithMaxOpenFiles(32))
+	result.Assert(t, Expected{ExitCode: 1, Err: "too many open files"})
+	// Only limits which terminate the command are detected
+	assert.Equal(t, result.LimitExceeded, Resource(""))
+	assert.DeepEqual(t, result.Command, []string{binname, "-open-files=64"})
+	assert.Assert(t, cmp.Contains(result.String(), "Command:  "+binname+" -open-files=64\n"))
+}
+
+func TestRunCmdWithMaxCPUTimeExceeded(t *testing.T) {
+	result := RunCmd(Command("sh", "-c", "while :; do :; done"),
+		WithMaxCPUTime(time.Second), WithTimeout(20*time.Second))
+	assert.Assert(t, !result.Timeout, "command should be terminated by the CPU time limit")
+	assert.Equal(t, result.ExitSignal, syscall.SIGXCPU)
+	assert.Equal(t, result.LimitExceeded, ResourceCPUTime)
+}
+
+func TestRunCmdWithLimitsNotExceeded(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-open-files=4"), WithLimits(Limits{OpenFiles: 64}))
+	result.Assert(t, Success)
+	assert.Equal(t, result.LimitExceeded, Resource(""))
+}
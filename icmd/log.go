@@ -0,0 +1,75 @@
+package icmd
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// LogT is the subset of testing.T used by WithOutputLog
+type LogT interface {
+	Log(args ...interface{})
+}
+
+// WithOutputLog streams the stdout and stderr of the command to t.Log while
+// the command is running. Each line is logged as soon as it is complete, with
+// a prefix of "stdout: " or "stderr: ". The output is still captured in the
+// Result.
+//
+// Use WithOutputLog for long running commands, so that the output is visible
+// when a test hangs and is killed by the test timeout.
+func WithOutputLog(t LogT) CmdOp {
+	return func(c *Cmd) {
+		stdout := &lineLogWriter{t: t, prefix: "stdout: "}
+		stderr := &lineLogWriter{t: t, prefix: "stderr: "}
+		c.Stdout = teeWriter(c.Stdout, stdout)
+		c.Stderr = teeWriter(c.Stderr, stderr)
+		c.flush = append(c.flush, stdout.Flush, stderr.Flush)
+	}
+}
+
+func teeWriter(existing io.Writer, w io.Writer) io.Writer {
+	if existing == nil {
+		return w
+	}
+	return io.MultiWriter(existing, w)
+}
+
+// lineLogWriter logs each complete line written to it. Flush logs any
+// remaining partial line.
+type lineLogWriter struct {
+	t      LogT
+	prefix string
+	mu     sync.Mutex
+	buf    bytes.Buffer
+}
+
+func (w *lineLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf.Write(p)
+	for {
+		index := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if index == -1 {
+			return len(p), nil
+		}
+		line := w.buf.Next(index + 1)
+		w.log(string(line[:index]))
+	}
+}
+
+func (w *lineLogWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.buf.Len() > 0 {
+		w.log(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *lineLogWriter) log(line string) {
+	if ht, ok := w.t.(helperT); ok {
+		ht.Helper()
+	}
+	w.t.Log(w.prefix + line)
+}
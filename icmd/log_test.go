@@ -0,0 +1,64 @@
+package icmd
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/skip"
+)
+
+type fakeLogT struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (t *fakeLogT) Log(args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestLineLogWriter(t *testing.T) {
+	fakeT := &fakeLogT{}
+	w := &lineLogWriter{t: fakeT, prefix: "out: "}
+
+	fmt.Fprint(w, "one\ntw")
+	fmt.Fprint(w, "o\nthree\n\nfour")
+	assert.DeepEqual(t, fakeT.logs, []string{"out: one", "out: two", "out: three", "out: "})
+	w.Flush()
+	assert.DeepEqual(t, fakeT.logs[4:], []string{"out: four"})
+	w.Flush()
+	assert.Equal(t, len(fakeT.logs), 5)
+}
+
+func TestRunCmdWithOutputLog(t *testing.T) {
+	buildStub(t)
+	fakeT := &fakeLogT{}
+
+	result := RunCmd(Command(binname, "-warn"), WithOutputLog(fakeT))
+	result.Assert(t, Expected{Out: "this is stdout", Err: "this is stderr"})
+	sort.Strings(fakeT.logs)
+	assert.DeepEqual(t, fakeT.logs, []string{"stderr: this is stderr", "stdout: this is stdout"})
+}
+
+func TestRunCmdWithOutputLogTimeout(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+	skip.IfBinaryNotInPath(t, "setsid")
+	fakeT := &fakeLogT{}
+
+	// The process started by setsid is not in the process group which is
+	// killed, so it writes to stdout after the timeout.
+	result := RunCmd(Command("sh", "-c", `setsid sh -c "sleep 0.2; echo late" & sleep 10`),
+		WithTimeout(50*time.Millisecond),
+		WithOutputLog(fakeT))
+	result.Assert(t, Expected{Timeout: true, Out: "late"})
+
+	fakeT.mu.Lock()
+	defer fakeT.mu.Unlock()
+	assert.DeepEqual(t, fakeT.logs, []string{"stdout: late"})
+}
@@ -0,0 +1,160 @@
+package icmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"reflect"
+	"strings"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// Matcher checks the output of a command. Matcher returns an error which
+// describes the mismatch when the output does not match.
+//
+// Matchers are used by Expected.OutMatch, Expected.ErrMatch, and
+// Expected.TTYMatch.
+type Matcher func(output string) error
+
+// MatchRegexp returns a Matcher which succeeds if the output matches the
+// regular expression. re may be a *regexp.Regexp or a string that is a valid
+// regexp pattern.
+func MatchRegexp(re cmp.RegexOrPattern) Matcher {
+	return MatchComparison(func(output string) cmp.Comparison {
+		return cmp.Regexp(re, output)
+	})
+}
+
+// MatchComparison returns a Matcher which succeeds if the cmp.Comparison
+// returned by compare succeeds.
+//
+// Example:
+//
+//	icmd.MatchComparison(func(out string) cmp.Comparison {
+//		return cmp.Contains(out, "ready")
+//	})
+func MatchComparison(compare func(output string) cmp.Comparison) Matcher {
+	return func(output string) error {
+		result := compare(output)()
+		if result.Success() {
+			return nil
+		}
+		switch msg := result.(type) {
+		case failureMessage:
+			return errors.New(msg.FailureMessage())
+		case templatedFailureMessage:
+			return errors.New(msg.FailureMessage(nil))
+		}
+		return errors.New("comparison failed")
+	}
+}
+
+// templatedFailureMessage is implemented by the result of comparisons which
+// format the failure message using the source of the arguments.
+type templatedFailureMessage interface {
+	FailureMessage(args []ast.Expr) string
+}
+
+// MatchJSON returns a Matcher which succeeds if the output is a JSON document
+// equal to expected. The output is decoded into a new value of the same type
+// as expected, so expected may be a struct, to only compare some of the
+// fields, or a map[string]interface{}, to compare every field. The values
+// are compared with google/go-cmp (https://godoc.org/github.com/google/go-cmp/cmp),
+// so the comparison can be customized with opts.
+//
+// The failure message includes a diff of the values.
+//
+// Example:
+//
+//	result.Assert(t, icmd.Expected{
+//		OutMatch: icmd.MatchJSON(map[string]interface{}{"status": "ok"}),
+//	})
+func MatchJSON(expected interface{}, opts ...gocmp.Option) Matcher {
+	return func(output string) error {
+		if expected == nil {
+			return errors.New("expected value of MatchJSON must not be nil")
+		}
+		actual := reflect.New(reflect.TypeOf(expected))
+		if err := json.Unmarshal([]byte(output), actual.Interface()); err != nil {
+			return fmt.Errorf("output is not a valid JSON document: %s", err)
+		}
+		return MatchComparison(func(string) cmp.Comparison {
+			return cmp.DeepEqual(actual.Elem().Interface(), expected, opts...)
+		})(output)
+	}
+}
+
+// ContainsLines returns a Matcher which succeeds if every one of lines is
+// contained in a line of the output. The lines may appear in any order, and
+// each line of output may match only one of lines.
+func ContainsLines(lines ...string) Matcher {
+	return func(output string) error {
+		remaining := splitLines(output)
+		var missing []string
+		for _, line := range lines {
+			index := indexOfLineContaining(remaining, line)
+			if index == -1 {
+				missing = append(missing, line)
+				continue
+			}
+			remaining = append(remaining[:index:index], remaining[index+1:]...)
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		return fmt.Errorf("missing lines: %q", missing)
+	}
+}
+
+// LinesInOrder returns a Matcher which succeeds if every one of lines is
+// contained in a line of the output, and the lines appear in the same order
+// as lines. Other lines of output may appear between the matched lines.
+func LinesInOrder(lines ...string) Matcher {
+	return func(output string) error {
+		remaining := splitLines(output)
+		for _, line := range lines {
+			index := indexOfLineContaining(remaining, line)
+			if index == -1 {
+				return fmt.Errorf("line %q not found in order", line)
+			}
+			remaining = remaining[index+1:]
+		}
+		return nil
+	}
+}
+
+// AllOf returns a Matcher which succeeds if all of the matchers succeed.
+func AllOf(matchers ...Matcher) Matcher {
+	return func(output string) error {
+		var failures []string
+		for _, matcher := range matchers {
+			if err := matcher(output); err != nil {
+				failures = append(failures, err.Error())
+			}
+		}
+		if len(failures) == 0 {
+			return nil
+		}
+		return errors.New(strings.Join(failures, "\n"))
+	}
+}
+
+func splitLines(output string) []string {
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func indexOfLineContaining(lines []string, substr string) int {
+	for i, line := range lines {
+		if strings.Contains(line, substr) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+package icmd
+
+import (
+	"os/exec"
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+const matcherOutput = `2021-01-02 10:00:00 starting
+2021-01-02 10:00:01 listening on :8080
+2021-01-02 10:00:02 ready
+`
+
+func TestMatchRegexp(t *testing.T) {
+	assert.NilError(t, MatchRegexp(`listening on :\d+`)(matcherOutput))
+	assert.NilError(t, MatchRegexp(regexp.MustCompile(`ready\n$`))(matcherOutput))
+	assert.ErrorContains(t, MatchRegexp(`^ready`)("not ready"),
+		`value "not ready" does not match regexp "^ready"`)
+}
+
+func TestMatchComparison(t *testing.T) {
+	matcher := MatchComparison(func(out string) cmp.Comparison {
+		return cmp.Contains(out, "ready")
+	})
+	assert.NilError(t, matcher(matcherOutput))
+	assert.ErrorContains(t, matcher("stopped"), `string "stopped" does not contain "ready"`)
+}
+
+func TestContainsLines(t *testing.T) {
+	assert.NilError(t, ContainsLines("ready", "starting")(matcherOutput))
+	assert.Error(t, ContainsLines("ready", "ready", "stopping")(matcherOutput),
+		`missing lines: ["ready" "stopping"]`)
+}
+
+func TestLinesInOrder(t *testing.T) {
+	assert.NilError(t, LinesInOrder("starting", "ready")(matcherOutput))
+	assert.Error(t, LinesInOrder("ready", "starting")(matcherOutput),
+		`line "starting" not found in order`)
+}
+
+func TestAllOf(t *testing.T) {
+	matcher := AllOf(ContainsLines("ready"), MatchRegexp("^stopped"), LinesInOrder("nope"))
+	assert.Error(t, matcher("ready\n"), `value "ready\n" does not match regexp "^stopped"
+line "nope" not found in order`)
+}
+
+func TestResult_Match_Matchers(t *testing.T) {
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer(matcherOutput),
+		errBuffer: newLockedBuffer("warning: deprecated\n"),
+	}
+	assert.NilError(t, result.match(Expected{
+		OutMatch: LinesInOrder("starting", "ready"),
+		ErrMatch: MatchRegexp("^warning"),
+	}))
+
+	err := result.match(Expected{ErrMatch: ContainsLines("error")})
+	assert.ErrorContains(t, err, `Expected stderr to match: missing lines: ["error"]`)
+}
+
+func TestMatchComparisonTemplatedResult(t *testing.T) {
+	matcher := MatchComparison(func(out string) cmp.Comparison {
+		return cmp.DeepEqual(out, "ready\n")
+	})
+	assert.NilError(t, matcher("ready\n"))
+	err := matcher("stopped\n")
+	assert.ErrorContains(t, err, `"stopped\n"`)
+	assert.ErrorContains(t, err, `"ready\n"`)
+}
+
+func TestMatchJSON(t *testing.T) {
+	output := `{"status": "ok", "count": 2, "items": ["a", "b"]}` + "\n"
+
+	assert.NilError(t, MatchJSON(map[string]interface{}{
+		"status": "ok",
+		"count":  2.0,
+		"items":  []interface{}{"a", "b"},
+	})(output))
+
+	type status struct {
+		Status string
+		Count  int
+	}
+	assert.NilError(t, MatchJSON(status{Status: "ok", Count: 2})(output))
+
+	err := MatchJSON(status{Status: "failed", Count: 2})(output)
+	assert.ErrorContains(t, err, `Status: "ok"`)
+	assert.ErrorContains(t, err, `Status: "failed"`)
+
+	assert.ErrorContains(t, MatchJSON(status{})("not json"),
+		"output is not a valid JSON document: invalid character 'o' in literal null")
+	assert.ErrorContains(t, MatchJSON(nil)(output), "must not be nil")
+}
+
+func TestResult_Match_MatchJSON(t *testing.T) {
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer(`{"status": "ok"}`),
+		errBuffer: newLockedBuffer(""),
+	}
+	err := result.match(Expected{OutMatch: MatchJSON(map[string]interface{}{"status": "ready"})})
+	assert.ErrorContains(t, err, `Stdout:   {"status": "ok"}`)
+	assert.ErrorContains(t, err, `Expected stdout to match: 
+--- ←
++++ →`)
+}
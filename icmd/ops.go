@@ -4,6 +4,8 @@ import (
 	"io"
 	"os"
 	"time"
+
+	"gotest.tools/v3/clock"
 )
 
 // CmdOp is an operation which modified a Cmd structure used to execute commands
@@ -16,6 +18,28 @@ func WithTimeout(timeout time.Duration) CmdOp {
 	}
 }
 
+// WithProcessGroup starts the command in a new process group, so that the
+// command and any processes it starts are killed together when the timeout
+// passed to WaitOnCmd is reached. On Windows the command is assigned to a job
+// object instead.
+//
+// A command in its own process group does not receive the signals sent to the
+// process group of the test, like the interrupt from Ctrl+C, so it is only used
+// when it is requested, or when Cmd.Timeout is set.
+func WithProcessGroup() CmdOp {
+	return func(c *Cmd) {
+		c.ProcessGroup = true
+	}
+}
+
+// WithClock sets the clock used to wait for the timeout of the command. Use a
+// clock.Fake to test the handling of a timeout without waiting for it.
+func WithClock(c clock.Clock) CmdOp {
+	return func(cmd *Cmd) {
+		cmd.Clock = c
+	}
+}
+
 // WithEnv sets the environment variable of the command.
 // Each arguments are in the form of KEY=VALUE
 func WithEnv(env ...string) CmdOp {
@@ -44,3 +68,42 @@ func WithExtraFile(f *os.File) CmdOp {
 		c.ExtraFiles = append(c.ExtraFiles, f)
 	}
 }
+
+// WithStdout sets a writer which receives a copy of the standard output of the
+// command, like > in a shell. The output is also available from
+// Result.Stdout.
+func WithStdout(w io.Writer) CmdOp {
+	return func(c *Cmd) {
+		c.Stdout = w
+	}
+}
+
+// WithStderr sets a writer which receives a copy of the standard error of the
+// command, like 2> in a shell. The output is also available from
+// Result.Stderr.
+func WithStderr(w io.Writer) CmdOp {
+	return func(c *Cmd) {
+		c.Stderr = w
+	}
+}
+
+// WithMergedStderr redirects the standard error of the command to its
+// standard output, like 2>&1 in a shell.
+func WithMergedStderr() CmdOp {
+	return func(c *Cmd) {
+		c.MergeStderr = true
+	}
+}
+
+// WithMaxOutput limits the number of bytes of stdout, and of stderr, which
+// are captured in the Result to limit. When the output of the command exceeds
+// the limit the first and last limit/2 bytes are kept, and a marker with the
+// number of dropped bytes is placed between them. Result.StdoutDropped and
+// Result.StderrDropped return the number of bytes which were dropped.
+//
+// Writers set with WithStdout and WithStderr still receive all of the output.
+func WithMaxOutput(limit int) CmdOp {
+	return func(c *Cmd) {
+		c.MaxOutput = limit
+	}
+}
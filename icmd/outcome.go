@@ -0,0 +1,83 @@
+package icmd
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Outcome describes how a command finished
+type Outcome string
+
+// Outcomes returned by Result.Outcome
+const (
+	// OutcomeExited is the outcome of a command which exited on its own,
+	// with any exit code
+	OutcomeExited Outcome = "exited"
+	// OutcomeSignaled is the outcome of a command which was terminated by a
+	// signal
+	OutcomeSignaled Outcome = "signaled"
+	// OutcomeTimeout is the outcome of a command which was killed because it
+	// hit the timeout
+	OutcomeTimeout Outcome = "timeout"
+	// OutcomeStartFailed is the outcome of a command which could not be
+	// started, for example because the binary does not exist or is not
+	// executable
+	OutcomeStartFailed Outcome = "start failed"
+)
+
+// Outcome returns how the command finished.
+func (r *Result) Outcome() Outcome {
+	switch {
+	case r.Timeout:
+		return OutcomeTimeout
+	case r.Cmd.Process == nil && r.Error != nil:
+		return OutcomeStartFailed
+	case r.ExitSignal != nil:
+		return OutcomeSignaled
+	default:
+		return OutcomeExited
+	}
+}
+
+func (r *Result) describeOutcome() string {
+	switch r.Outcome() {
+	case OutcomeTimeout:
+		return "hit the timeout"
+	case OutcomeStartFailed:
+		return fmt.Sprintf("failed to start: %s", r.Error)
+	case OutcomeSignaled:
+		return fmt.Sprintf("was killed by signal %v", r.ExitSignal)
+	default:
+		return fmt.Sprintf("exited with code %d", r.ExitCode)
+	}
+}
+
+var outcomeExpectations = map[Outcome]string{
+	OutcomeExited:      "exit",
+	OutcomeSignaled:    "be killed by a signal",
+	OutcomeTimeout:     "hit the timeout",
+	OutcomeStartFailed: "fail to start",
+}
+
+// outcome returns the Outcome expected by exp, or an empty string if exp does
+// not set an outcome.
+func (exp Expected) outcome() Outcome {
+	if exp.Outcome == "" && exp.StartError != nil {
+		return OutcomeStartFailed
+	}
+	return exp.Outcome
+}
+
+func (r *Result) matchOutcome(exp Expected, add func(format string, args ...interface{})) {
+	expected := exp.outcome()
+	if expected == "" {
+		return
+	}
+	if actual := r.Outcome(); actual != expected {
+		add("Expected command to %s, but it %s", outcomeExpectations[expected], r.describeOutcome())
+		return
+	}
+	if exp.StartError != nil && !errors.Is(r.Error, exp.StartError) {
+		add("Expected command to fail to start with %q, but it failed with %q", exp.StartError, r.Error)
+	}
+}
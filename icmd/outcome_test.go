@@ -0,0 +1,66 @@
+package icmd
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/skip"
+)
+
+func TestResultOutcome(t *testing.T) {
+	buildStub(t)
+
+	result := RunCommand(binname, "-fail=3")
+	assert.Equal(t, result.Outcome(), OutcomeExited)
+	result.Assert(t, Expected{Outcome: OutcomeExited, ExitCode: 3})
+
+	result = RunCmd(Command(binname, "-sleep=2s"), WithTimeout(20*time.Millisecond))
+	assert.Equal(t, result.Outcome(), OutcomeTimeout)
+	result.Assert(t, Expected{Outcome: OutcomeTimeout})
+
+	result = RunCommand("doesnotexists")
+	assert.Equal(t, result.Outcome(), OutcomeStartFailed)
+	result.Assert(t, Expected{StartError: exec.ErrNotFound})
+}
+
+func TestResultOutcomeSignaled(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt is not supported on windows")
+	buildStub(t)
+
+	result := StartCmd(Command(binname, "-sleep=10s"))
+	assert.NilError(t, result.Signal(os.Interrupt))
+	WaitOnCmd(5*time.Second, result)
+	assert.Equal(t, result.Outcome(), OutcomeSignaled)
+	result.Assert(t, Expected{Outcome: OutcomeSignaled})
+}
+
+func TestResultOutcomeStartFailedPermission(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires unix permissions")
+	file := fs.NewFile(t, "not-executable", fs.WithMode(0644))
+
+	result := RunCommand(file.Path())
+	result.Assert(t, Expected{StartError: os.ErrPermission})
+
+	err := result.Compare(Expected{StartError: exec.ErrNotFound})
+	assert.ErrorContains(t, err, `Expected command to fail to start with "executable file not found in $PATH", but it failed with`)
+}
+
+func TestResult_Match_Outcome(t *testing.T) {
+	buildStub(t)
+
+	result := RunCommand(binname, "-fail=2")
+	err := result.Compare(Expected{Outcome: OutcomeTimeout})
+	assert.ErrorContains(t, err, "Expected command to hit the timeout, but it exited with code 2")
+
+	err = result.Compare(Expected{StartError: exec.ErrNotFound})
+	assert.ErrorContains(t, err, "Expected command to fail to start, but it exited with code 2")
+
+	result = RunCommand("doesnotexists")
+	err = result.Compare(Expected{Outcome: OutcomeExited})
+	assert.ErrorContains(t, err, `Expected command to exit, but it failed to start: exec: "doesnotexists"`)
+}
@@ -0,0 +1,131 @@
+package icmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// PipelineResult stores the results of running a pipeline with RunPipeline
+type PipelineResult struct {
+	// Stages contains the Result of each command in the pipeline, in the same
+	// order as the commands passed to RunPipeline. The stdout of every stage
+	// except the last is connected to the next stage, so it is not captured in
+	// the Result of the stage.
+	Stages []*Result
+}
+
+// RunPipeline runs the commands as a pipeline, like cmd1 | cmd2 in a shell,
+// without using a shell. The stdout of each command is connected to the stdin
+// of the next command with an os.Pipe, so each command sees a pipe, not a
+// terminal or a file.
+//
+// Stdin of the first command and Stdout of the last command may be set to
+// redirect input and output. WithMergedStderr may be used to also send stderr
+// of a command to the next command, like cmd1 2>&1 | cmd2.
+//
+// If timeout is non-zero it is a deadline shared by all the commands. See
+// RunGroup.
+func RunPipeline(timeout time.Duration, cmds ...Cmd) *PipelineResult {
+	pipeline := &PipelineResult{Stages: make([]*Result, len(cmds))}
+
+	var parentFiles []*os.File
+	var stdin *os.File
+	for i, cmd := range cmds {
+		result := buildCmd(cmd)
+		pipeline.Stages[i] = result
+		if stdin != nil {
+			result.Cmd.Stdin = stdin
+		}
+		stdin = nil
+		if i == len(cmds)-1 {
+			break
+		}
+
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			result.setExitError(err)
+			continue
+		}
+		parentFiles = append(parentFiles, reader, writer)
+		result.Cmd.Stdout = writer
+		if cmd.MergeStderr {
+			result.Cmd.Stderr = writer
+		}
+		stdin = reader
+	}
+
+	for _, result := range pipeline.Stages {
+		if result.Error == nil {
+			result.start()
+		}
+	}
+	// The parent must close its copy of the pipes so that each command sees
+	// EOF when the previous command exits.
+	for _, f := range parentFiles {
+		f.Close() //nolint: errcheck
+	}
+	waitAll(timeout, cmds, pipeline.Stages)
+	return pipeline
+}
+
+// Last returns the Result of the last command in the pipeline.
+func (p *PipelineResult) Last() *Result {
+	return p.Stages[len(p.Stages)-1]
+}
+
+// Assert compares the Result of the last command in the pipeline against the
+// Expected struct, and fails the test if any of the expectations are not met,
+// or if any of the other commands did not succeed. This is equivalent to
+// running a pipeline in a shell with 'set -o pipefail'.
+//
+// To assert on the Result of other stages use PipelineResult.Stages.
+//
+// This function is equivalent to assert.Assert(t, pipeline.Equal(exp)).
+func (p *PipelineResult) Assert(t assert.TestingT, exp Expected) *PipelineResult {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.Assert(t, p.equal(t, exp))
+	return p
+}
+
+// Equal compares the last command in the pipeline to Expected, and checks that
+// all other commands succeeded. See PipelineResult.Assert.
+func (p *PipelineResult) Equal(exp Expected) cmp.Comparison {
+	return p.equal(nil, exp)
+}
+
+func (p *PipelineResult) equal(t interface{}, exp Expected) cmp.Comparison {
+	return func() cmp.Result {
+		return cmp.ResultFromError(p.compare(t, exp))
+	}
+}
+
+// Compare the last command in the pipeline to Expected, and check that all
+// other commands succeeded. Returns an error if any of them do not match.
+func (p *PipelineResult) Compare(exp Expected) error {
+	return p.compare(nil, exp)
+}
+
+func (p *PipelineResult) compare(t interface{}, exp Expected) error {
+	var failures []string
+	last := len(p.Stages) - 1
+	for i, result := range p.Stages {
+		expected := Success
+		if i == last {
+			expected = exp
+		}
+		if err := result.matchT(t, expected); err != nil {
+			failures = append(failures, fmt.Sprintf("stage %d of %d:%s", i+1, len(p.Stages), err))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("pipeline did not match\n%s", strings.Join(failures, "\n"))
+}
@@ -0,0 +1,66 @@
+package icmd
+
+import (
+	"bytes"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/skip"
+)
+
+func TestRunPipeline(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sort and tr")
+	buildStub(t)
+
+	pipeline := RunPipeline(5*time.Second,
+		Command(binname, "-warn"),
+		Command("tr", "a-z", "A-Z"),
+		Command("sort", "-r"))
+	pipeline.Assert(t, Expected{Out: "THIS IS STDOUT\n", Err: None})
+	assert.Equal(t, len(pipeline.Stages), 3)
+	assert.Equal(t, pipeline.Stages[0].Stdout(), "")
+	assert.Equal(t, pipeline.Stages[0].Stderr(), "this is stderr\n")
+}
+
+func TestRunPipelineWithRedirections(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sort")
+	buildStub(t)
+
+	output := new(bytes.Buffer)
+	pipeline := RunPipeline(5*time.Second,
+		Cmd{Command: []string{binname, "-warn"}, MergeStderr: true},
+		Cmd{Command: []string{"sort"}, Stdout: output})
+	pipeline.Assert(t, Expected{Out: "this is stderr\nthis is stdout\n"})
+	assert.Equal(t, output.String(), "this is stderr\nthis is stdout\n")
+
+	pipeline = RunPipeline(5*time.Second,
+		Cmd{Command: []string{"sort"}, Stdin: strings.NewReader("b\na\n")},
+		Command("sort", "-r"))
+	pipeline.Assert(t, Expected{Out: "b\na\n"})
+}
+
+func TestRunPipelineFailedStage(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires cat")
+	buildStub(t)
+
+	pipeline := RunPipeline(5*time.Second,
+		Command(binname, "-fail=2"),
+		Command("cat"))
+	pipeline.Last().Assert(t, Expected{Out: "this is stdout"})
+	pipeline.Stages[0].Assert(t, Expected{ExitCode: 2})
+
+	err := pipeline.Compare(Expected{Out: "this is stdout"})
+	assert.ErrorContains(t, err, "stage 1 of 2:")
+	assert.ErrorContains(t, err, "ExitCode was 2 expected 0")
+}
+
+func TestRunPipelineStartFailure(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires cat")
+
+	pipeline := RunPipeline(5*time.Second, Command("doesnotexists"), Command("cat"))
+	pipeline.Stages[0].Assert(t, Expected{ExitCode: 127, Error: "executable file not found"})
+	pipeline.Last().Assert(t, Expected{Out: None})
+}
@@ -0,0 +1,96 @@
+package icmd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/nettest"
+)
+
+// Ports maps names to free network ports allocated by FreeTCPPorts or
+// FreeUDPPorts.
+//
+// Ports are substituted into strings which contain a placeholder in the form
+// of {{.name}}. See WithPorts and Ports.Expand.
+type Ports map[string]int
+
+// FreeTCPPorts returns a free TCP port on the loopback interface for each
+// of names, allocated with nettest.GetFreePort. A port is never returned more
+// than once by the same process, so tests which run in parallel do not
+// receive the same port.
+//
+// The ports are free when FreeTCPPorts returns, but nothing prevents another
+// process from using them before the command under test binds to them.
+func FreeTCPPorts(t assert.TestingT, names ...string) Ports {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return freePorts(t, "tcp", names)
+}
+
+// FreeUDPPorts returns a free UDP port on the loopback interface for each
+// of names. See FreeTCPPorts.
+func FreeUDPPorts(t assert.TestingT, names ...string) Ports {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return freePorts(t, "udp", names)
+}
+
+func freePorts(t assert.TestingT, network string, names []string) Ports {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	ports := Ports{}
+	for _, name := range names {
+		if network == "udp" {
+			ports[name] = nettest.GetFreeUDPPort(t)
+		} else {
+			ports[name] = nettest.GetFreePort(t)
+		}
+	}
+	return ports
+}
+
+// Addr returns the loopback address of the named port, in the form
+// 127.0.0.1:port.
+func (p Ports) Addr(name string) string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(p[name]))
+}
+
+// Expand replaces every {{.name}} placeholder in s with the named port.
+// Expand may be used to build expected values for assertions.
+func (p Ports) Expand(s string) string {
+	var oldnew []string
+	for name, port := range p {
+		oldnew = append(oldnew, "{{."+name+"}}", strconv.Itoa(port))
+	}
+	return strings.NewReplacer(oldnew...).Replace(s)
+}
+
+// WithPorts replaces every {{.name}} placeholder in the arguments and
+// environment of the command with the named port.
+//
+// Example:
+//
+//	ports := icmd.FreeTCPPorts(t, "http")
+//	icmd.RunCmd(icmd.Command("server", "--listen=127.0.0.1:{{.http}}"), icmd.WithPorts(ports))
+func WithPorts(ports Ports) CmdOp {
+	return func(c *Cmd) {
+		c.Command = expandAll(ports, c.Command)
+		c.Env = expandAll(ports, c.Env)
+	}
+}
+
+func expandAll(ports Ports, values []string) []string {
+	if values == nil {
+		return nil
+	}
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		expanded[i] = ports.Expand(value)
+	}
+	return expanded
+}
@@ -0,0 +1,45 @@
+package icmd
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestFreeTCPPorts(t *testing.T) {
+	ports := FreeTCPPorts(t, "http", "grpc")
+	assert.Equal(t, len(ports), 2)
+	assert.Assert(t, ports["http"] != ports["grpc"])
+
+	listener, err := net.Listen("tcp", ports.Addr("http"))
+	assert.NilError(t, err)
+	assert.NilError(t, listener.Close())
+}
+
+func TestFreeUDPPorts(t *testing.T) {
+	ports := FreeUDPPorts(t, "dns")
+
+	conn, err := net.ListenPacket("udp", ports.Addr("dns"))
+	assert.NilError(t, err)
+	assert.NilError(t, conn.Close())
+}
+
+func TestWithPorts(t *testing.T) {
+	ports := Ports{"http": 8080, "admin": 9090}
+	cmd := Command("server", "--listen=:{{.http}}", "--admin={{.admin}}", "{{.other}}")
+	WithEnv("ADDR=127.0.0.1:{{.http}}")(&cmd)
+	WithPorts(ports)(&cmd)
+
+	assert.DeepEqual(t, cmd.Command, []string{"server", "--listen=:8080", "--admin=9090", "{{.other}}"})
+	assert.DeepEqual(t, cmd.Env, []string{"ADDR=127.0.0.1:8080"})
+	assert.Equal(t, ports.Expand("listening on {{.http}}"), "listening on 8080")
+	assert.Equal(t, ports.Addr("admin"), "127.0.0.1:"+strconv.Itoa(9090))
+}
+
+func TestWithPortsNoEnv(t *testing.T) {
+	cmd := Command("server")
+	WithPorts(Ports{"http": 1})(&cmd)
+	assert.Assert(t, cmd.Env == nil)
+}
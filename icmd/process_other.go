@@ -0,0 +1,28 @@
+//go:build js || wasip1 || plan9
+// +build js wasip1 plan9
+
+package icmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup does nothing, because process groups are not supported.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// processGroup only contains the process of the command, because process
+// groups are not supported.
+type processGroup struct {
+	process *os.Process
+}
+
+func newProcessGroup(process *os.Process) *processGroup {
+	return &processGroup{process: process}
+}
+
+func (g *processGroup) kill() error {
+	return g.process.Kill()
+}
+
+func (g *processGroup) release() {}
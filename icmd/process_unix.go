@@ -0,0 +1,39 @@
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
+
+package icmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group so that the
+// command and any processes it starts can be killed together.
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// processGroup is the process group of a command started with setProcessGroup.
+type processGroup struct {
+	process *os.Process
+}
+
+func newProcessGroup(process *os.Process) *processGroup {
+	return &processGroup{process: process}
+}
+
+// kill every process in the process group.
+func (g *processGroup) kill() error {
+	// A negative pid sends the signal to every process in the group
+	if err := syscall.Kill(-g.process.Pid, syscall.SIGKILL); err != nil {
+		return g.process.Kill()
+	}
+	return nil
+}
+
+func (g *processGroup) release() {}
@@ -0,0 +1,68 @@
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
+
+package icmd
+
+import (
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+)
+
+func TestWaitOnCmdTimeoutKillsProcessGroup(t *testing.T) {
+	cmd := Command("sh", "-c", "sleep 10 & echo $!; wait")
+	WithProcessGroup()(&cmd)
+	result := StartCmd(cmd)
+	assert.NilError(t, result.Error)
+
+	var child int
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		out := strings.TrimSpace(result.Stdout())
+		if out == "" {
+			return poll.Continue("child pid not printed")
+		}
+		var err error
+		child, err = strconv.Atoi(out)
+		if err != nil {
+			return poll.Error(err)
+		}
+		return poll.Success()
+	}, poll.WithDelay(10*time.Millisecond))
+
+	WaitOnCmd(50*time.Millisecond, result)
+	assert.Assert(t, result.Timeout)
+
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if err := syscall.Kill(child, 0); err == syscall.ESRCH {
+			return poll.Success()
+		}
+		return poll.Continue("child process %d is still running", child)
+	}, poll.WithDelay(10*time.Millisecond), poll.WithTimeout(5*time.Second))
+}
+
+func TestStartCmdProcessGroup(t *testing.T) {
+	result := StartCmd(Command("sleep", "10"))
+	assert.NilError(t, result.Error)
+	defer WaitOnCmd(0, result)
+	defer result.Cmd.Process.Kill() //nolint: errcheck
+
+	pgid, err := syscall.Getpgid(result.Cmd.Process.Pid)
+	assert.NilError(t, err)
+	assert.Equal(t, pgid, syscall.Getpgrp(), "command should be in the process group of the test")
+
+	cmd := Command("sleep", "10")
+	WithProcessGroup()(&cmd)
+	grouped := StartCmd(cmd)
+	assert.NilError(t, grouped.Error)
+	defer WaitOnCmd(0, grouped)
+	defer grouped.Cmd.Process.Kill() //nolint: errcheck
+
+	pgid, err = syscall.Getpgid(grouped.Cmd.Process.Pid)
+	assert.NilError(t, err)
+	assert.Equal(t, pgid, grouped.Cmd.Process.Pid, "command should be in a new process group")
+}
@@ -0,0 +1,75 @@
+package icmd
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+const (
+	processSetQuota  = 0x0100
+	processTerminate = 0x0001
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// processGroup is a job object which contains the process of a command, and
+// every process started by the command after it was assigned to the job.
+type processGroup struct {
+	process *os.Process
+	job     syscall.Handle
+}
+
+// newProcessGroup assigns process to a new job object. If the job object can
+// not be created, kill falls back to killing the process tree with taskkill.
+func newProcessGroup(process *os.Process) *processGroup {
+	group := &processGroup{process: process}
+	job, _, _ := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return group
+	}
+	handle, err := syscall.OpenProcess(processSetQuota|processTerminate, false, uint32(process.Pid))
+	if err != nil {
+		syscall.CloseHandle(syscall.Handle(job)) //nolint: errcheck
+		return group
+	}
+	defer syscall.CloseHandle(handle) //nolint: errcheck
+
+	if ok, _, _ := procAssignProcessToJobObject.Call(job, uintptr(handle)); ok == 0 {
+		syscall.CloseHandle(syscall.Handle(job)) //nolint: errcheck
+		return group
+	}
+	group.job = syscall.Handle(job)
+	return group
+}
+
+// kill every process in the job object.
+func (g *processGroup) kill() error {
+	if g.job != 0 {
+		if ok, _, _ := procTerminateJobObject.Call(uintptr(g.job), 1); ok != 0 {
+			return nil
+		}
+	}
+	taskkill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(g.process.Pid))
+	if err := taskkill.Run(); err != nil {
+		return g.process.Kill()
+	}
+	return nil
+}
+
+// release closes the handle to the job object. Processes in the job are not
+// affected.
+func (g *processGroup) release() {
+	if g.job != 0 {
+		syscall.CloseHandle(g.job) //nolint: errcheck
+		g.job = 0
+	}
+}
@@ -0,0 +1,289 @@
+/*
+Package replay records invocations of external commands to fixture files, and
+replays them by substituting a stub for the command on PATH.
+
+Tests which depend on external tools (git, docker, kubectl) can record the real
+invocations once, by running the tests with -update, and then run
+hermetically by replaying the recorded output.
+
+Fixture files are stored in the ./testdata/ subdirectory of the package under
+test, like golden files.
+
+The stub is a copy of the test binary. When the copy is run as a stub the init
+function of this package handles the invocation and exits before any tests
+are run.
+*/
+package replay // import "gotest.tools/v3/icmd/replay"
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/golden"
+)
+
+// TestingT is the subset of testing.T used by Setup, Record, and Replay
+type TestingT interface {
+	assert.TestingT
+	Cleanup(f func())
+}
+
+type helperT interface {
+	Helper()
+}
+
+// Invocation is a single recorded run of a command.
+type Invocation struct {
+	// Command is the name of the command
+	Command string `json:"command"`
+	// Args are the arguments to the command, not including the command name
+	Args []string `json:"args"`
+	// Env contains the environment variables which differed from the
+	// environment of the test when the command was run.
+	Env      []string `json:"env,omitempty"`
+	Stdin    string   `json:"stdin,omitempty"`
+	Stdout   string   `json:"stdout"`
+	Stderr   string   `json:"stderr"`
+	ExitCode int      `json:"exitCode"`
+}
+
+// Session is a set of commands which are being recorded or replayed.
+type Session struct {
+	dir    string
+	config config
+}
+
+// Dir returns the directory which contains the stubs for the commands. The
+// directory is added to the start of PATH in the environment of the test.
+func (s *Session) Dir() string {
+	return s.dir
+}
+
+// Path returns the value of PATH used by the session. Use it to set PATH when
+// a command is run with an explicit environment.
+func (s *Session) Path() string {
+	return s.dir + string(os.PathListSeparator) + s.config.OriginalPath
+}
+
+// Recording returns true if the session is recording invocations.
+func (s *Session) Recording() bool {
+	return s.config.Record
+}
+
+// Setup records the commands when the tests are run with -update, and replays
+// them from fixture otherwise. See Record and Replay.
+func Setup(t TestingT, fixture string, commands ...string) *Session {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if golden.FlagUpdate() {
+		return Record(t, fixture, commands...)
+	}
+	return Replay(t, fixture, commands...)
+}
+
+// Record runs the real commands, and records every invocation. When the test
+// ends the invocations are written to the fixture file, replacing any previous
+// recording.
+//
+// The environment of the test is changed so that the commands are found in a
+// directory of stubs. The stubs run the real command found on PATH when Record
+// is called.
+func Record(t TestingT, fixture string, commands ...string) *Session {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	cfg := config{Record: true, Commands: map[string]string{}}
+	for _, name := range commands {
+		real, err := exec.LookPath(name)
+		assert.NilError(t, err, "command %s must be installed to record it", name)
+		cfg.Commands[name] = real
+	}
+	session := newSession(t, fixture, cfg)
+	t.Cleanup(func() {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		assert.NilError(t, session.writeFixture())
+	})
+	return session
+}
+
+// Replay substitutes a stub for each of the commands. When a stub is run
+// it looks for an invocation in the fixture file with the same command,
+// arguments, and stdin, and replays the stdout, stderr, and exit code of the
+// invocation. Each recorded invocation is replayed at most once, in the order
+// they were recorded.
+//
+// If no invocation matches, the stub exits with code 127 and the test fails
+// when it ends.
+func Replay(t TestingT, fixture string, commands ...string) *Session {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	_, err := os.Stat(golden.Path(fixture))
+	assert.NilError(t, err, "run the tests with -update to record the fixture")
+
+	cfg := config{Commands: map[string]string{}}
+	for _, name := range commands {
+		cfg.Commands[name] = ""
+	}
+	session := newSession(t, fixture, cfg)
+	t.Cleanup(func() {
+		misses, err := ioutil.ReadFile(session.config.MissesFile)
+		if err != nil || len(misses) == 0 {
+			return
+		}
+		t.Log("no recorded invocations matched:\n" + string(misses))
+		t.Fail()
+	})
+	return session
+}
+
+func newSession(t TestingT, fixture string, cfg config) *Session {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	dir, err := ioutil.TempDir("", "icmd-replay-")
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir) //nolint: errcheck
+	})
+
+	fixturePath, err := filepath.Abs(golden.Path(fixture))
+	assert.NilError(t, err)
+
+	cfg.Fixture = fixturePath
+	cfg.LogFile = filepath.Join(dir, "invocations.log")
+	cfg.UsedFile = filepath.Join(dir, "used.log")
+	cfg.MissesFile = filepath.Join(dir, "misses.log")
+	cfg.OriginalPath = os.Getenv("PATH")
+
+	executable, err := os.Executable()
+	assert.NilError(t, err)
+	for name := range cfg.Commands {
+		assert.NilError(t, copyFile(executable, filepath.Join(dir, name+exeSuffix())))
+	}
+
+	configFile := filepath.Join(dir, "config.json")
+	env.Patch(t, "PATH", dir+string(os.PathListSeparator)+cfg.OriginalPath)
+	env.Patch(t, envConfig, configFile)
+	cfg.Environ = os.Environ()
+	raw, err := json.Marshal(cfg)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(configFile, raw, 0644))
+
+	return &Session{dir: dir, config: cfg}
+}
+
+func (s *Session) writeFixture() error {
+	invocations, err := readInvocationLog(s.config.LogFile)
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(invocations, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.config.Fixture), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.config.Fixture, append(raw, '\n'), 0644)
+}
+
+func readInvocationLog(path string) ([]Invocation, error) {
+	invocations := []Invocation{}
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		return invocations, nil
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close() //nolint: errcheck
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var inv Invocation
+			if err := json.Unmarshal(line, &inv); err != nil {
+				return nil, err
+			}
+			invocations = append(invocations, inv)
+		}
+		switch {
+		case err == io.EOF:
+			return invocations, nil
+		case err != nil:
+			return nil, err
+		}
+	}
+}
+
+// LoadFixture reads the invocations recorded in the fixture file.
+func LoadFixture(fixture string) ([]Invocation, error) {
+	raw, err := ioutil.ReadFile(golden.Path(fixture))
+	if err != nil {
+		return nil, err
+	}
+	var invocations []Invocation
+	err = json.Unmarshal(raw, &invocations)
+	return invocations, err
+}
+
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint: errcheck
+
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() //nolint: errcheck
+		return err
+	}
+	return out.Close()
+}
+
+func exeSuffix() string {
+	if runtime.GOOS == "windows" {
+		return ".exe"
+	}
+	return ""
+}
+
+// envDiff returns the variables in environ which are not in baseline.
+func envDiff(baseline, environ []string) []string {
+	known := map[string]bool{}
+	for _, kv := range baseline {
+		known[kv] = true
+	}
+	var diff []string
+	for _, kv := range environ {
+		if !known[kv] && !strings.HasPrefix(kv, envConfig+"=") {
+			diff = append(diff, kv)
+		}
+	}
+	sort.Strings(diff)
+	return diff
+}
+
+func (inv Invocation) String() string {
+	return fmt.Sprintf("%s %s", inv.Command, strings.Join(inv.Args, " "))
+}
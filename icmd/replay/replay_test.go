@@ -0,0 +1,87 @@
+package replay
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/icmd"
+	"gotest.tools/v3/skip"
+)
+
+type fakeT struct {
+	*testing.T
+	failed   bool
+	cleanups []func()
+}
+
+func (t *fakeT) Fail() {
+	t.failed = true
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires echo")
+	dir := fs.NewDir(t, "replay")
+	fixture := filepath.Join(dir.Path(), "echo.json")
+
+	t.Run("record", func(t *testing.T) {
+		session := Record(t, fixture, "echo")
+		assert.Assert(t, session.Recording())
+		icmd.RunCommand("echo", "hello").Assert(t, icmd.Expected{Out: "hello\n"})
+	})
+
+	invocations, err := LoadFixture(fixture)
+	assert.NilError(t, err)
+	expected := []Invocation{{Command: "echo", Args: []string{"hello"}, Stdout: "hello\n"}}
+	assert.DeepEqual(t, invocations, expected)
+
+	invocations = append(invocations, Invocation{
+		Command:  "echo",
+		Args:     []string{"hello"},
+		Stdout:   "replayed\n",
+		ExitCode: 3,
+	})
+	raw, err := json.Marshal(invocations)
+	assert.NilError(t, err)
+	assert.NilError(t, ioutil.WriteFile(fixture, raw, 0644))
+
+	t.Run("replay", func(t *testing.T) {
+		session := Replay(t, fixture, "echo")
+		assert.Assert(t, !session.Recording())
+		icmd.RunCommand("echo", "hello").Assert(t, icmd.Expected{Out: "hello\n"})
+		icmd.RunCommand("echo", "hello").Assert(t, icmd.Expected{
+			Out:      "replayed\n",
+			ExitCode: 3,
+		})
+	})
+}
+
+func TestReplayNoMatch(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires echo")
+	fixture := fs.NewFile(t, "fixture", fs.WithContent("[]"))
+	fakeT := &fakeT{T: t}
+
+	Replay(fakeT, fixture.Path(), "echo")
+	result := icmd.RunCommand("echo", "hello")
+	result.Assert(t, icmd.Expected{
+		ExitCode: 127,
+		Err:      "replay: no recorded invocation for: echo hello",
+	})
+
+	fakeT.runCleanups()
+	assert.Assert(t, fakeT.failed)
+}
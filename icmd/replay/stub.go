@@ -0,0 +1,183 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// envConfig is the name of the environment variable which contains the path
+// to the config file of the session. When it is set, and the executable is
+// one of the commands in the config, the process runs as a stub.
+const envConfig = "GOTESTTOOLS_REPLAY_CONFIG"
+
+type config struct {
+	Record bool
+	// Commands maps the name of each command to the path of the real command.
+	// The path is only set when recording.
+	Commands     map[string]string
+	Fixture      string
+	LogFile      string
+	UsedFile     string
+	MissesFile   string
+	OriginalPath string
+	Environ      []string
+}
+
+func init() {
+	path := os.Getenv(envConfig)
+	if path == "" {
+		return
+	}
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), exeSuffix())
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var cfg config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return
+	}
+	if _, ok := cfg.Commands[name]; !ok {
+		return
+	}
+	os.Exit(runStub(cfg, name))
+}
+
+func runStub(cfg config, name string) int {
+	stdin, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: failed to read stdin: %s\n", err)
+		return 127
+	}
+	inv := Invocation{
+		Command: name,
+		Args:    os.Args[1:],
+		Env:     envDiff(cfg.Environ, os.Environ()),
+		Stdin:   string(stdin),
+	}
+	if cfg.Record {
+		return record(cfg, inv)
+	}
+	return replay(cfg, inv)
+}
+
+func record(cfg config, inv Invocation) int {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd := exec.Command(cfg.Commands[inv.Command], inv.Args...)
+	cmd.Stdin = strings.NewReader(inv.Stdin)
+	cmd.Stdout = io.MultiWriter(os.Stdout, stdout)
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderr)
+	// Run the real command with the original PATH so that the real command
+	// does not run the stubs.
+	cmd.Env = append(os.Environ(), "PATH="+cfg.OriginalPath)
+
+	err := cmd.Run()
+	inv.Stdout = stdout.String()
+	inv.Stderr = stderr.String()
+	inv.ExitCode = exitCode(err)
+
+	raw, err := json.Marshal(inv)
+	if err == nil {
+		err = appendLine(cfg.LogFile, string(raw))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: failed to record invocation: %s\n", err)
+		return 127
+	}
+	return inv.ExitCode
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() != -1 {
+		return exitErr.ExitCode()
+	}
+	fmt.Fprintf(os.Stderr, "replay: failed to run command: %s\n", err)
+	return 127
+}
+
+func replay(cfg config, inv Invocation) int {
+	invocations, err := LoadFixture(cfg.Fixture)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: failed to load fixture: %s\n", err)
+		return 127
+	}
+	used, err := readUsed(cfg.UsedFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %s\n", err)
+		return 127
+	}
+
+	for i, recorded := range invocations {
+		if used[i] || !matches(recorded, inv) {
+			continue
+		}
+		if err := appendLine(cfg.UsedFile, strconv.Itoa(i)); err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %s\n", err)
+			return 127
+		}
+		fmt.Fprint(os.Stdout, recorded.Stdout)
+		fmt.Fprint(os.Stderr, recorded.Stderr)
+		return recorded.ExitCode
+	}
+
+	appendLine(cfg.MissesFile, inv.String()) //nolint: errcheck
+	fmt.Fprintf(os.Stderr, "replay: no recorded invocation for: %s\n", inv)
+	return 127
+}
+
+func matches(recorded, inv Invocation) bool {
+	if recorded.Command != inv.Command || recorded.Stdin != inv.Stdin {
+		return false
+	}
+	if len(recorded.Args) != len(inv.Args) {
+		return false
+	}
+	for i := range recorded.Args {
+		if recorded.Args[i] != inv.Args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func readUsed(path string) (map[int]bool, error) {
+	used := map[int]bool{}
+	raw, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return used, nil
+	case err != nil:
+		return nil, err
+	}
+	for _, line := range strings.Fields(string(raw)) {
+		index, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, err
+		}
+		used[index] = true
+	}
+	return used, nil
+}
+
+func appendLine(path string, line string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		f.Close() //nolint: errcheck
+		return err
+	}
+	return f.Close()
+}
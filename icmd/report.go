@@ -0,0 +1,73 @@
+package icmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gotest.tools/v3/internal/format"
+	"gotest.tools/v3/internal/overflow"
+)
+
+// MaxFailureOutput is the maximum number of bytes of stdout, and of stderr,
+// included in the failure message of Result.Assert and Result.Compare. Longer
+// output is truncated, keeping the start and the end of the output, and the
+// full output is written to a file. Result.Assert writes the file to the
+// artifacts directory of the test, otherwise it is written to the temporary
+// directory. The path to the file is included in the failure message.
+//
+// The GOTESTTOOLS_MAX_FAILURE_SIZE environment variable, used to limit the
+// size of assertion failures, replaces this value when it is set.
+//
+// A value of 0 disables truncation.
+var MaxFailureOutput = 16 * 1024
+
+// failureString is like String, but truncates long output.
+func (r *Result) failureString(t interface{}) string {
+	limit, err := overflow.MaxSize(MaxFailureOutput)
+	out := r.format(
+		truncateOutput(t, limit, "stdout", r.Stdout(), r.secrets),
+		truncateOutput(t, limit, "stderr", r.Stderr(), r.secrets),
+		truncateOutput(t, limit, "tty", r.TTY(), r.secrets))
+	if err != nil {
+		out += err.Error()
+	}
+	return out
+}
+
+func truncateOutput(t interface{}, limit int, stream string, output string, secrets []string) string {
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+	dump := "failed to write full output: "
+	if path, err := overflow.Write(t, "icmd-"+stream, redact(output, secrets)); err != nil {
+		dump += err.Error()
+	} else {
+		dump = "full " + stream + " written to " + path
+	}
+	half := limit / 2
+	omitted := len(output) - 2*half
+	return fmt.Sprintf("%s\n... %d bytes omitted, %s ...\n%s",
+		output[:half], omitted, dump, output[len(output)-half:])
+}
+
+// streamDiff returns a unified diff of the expected and actual output of a
+// stream. The diff is omitted when it would not help to explain the failure.
+func streamDiff(stream string, expected, actual string) string {
+	limit, _ := overflow.MaxSize(MaxFailureOutput)
+	switch {
+	case expected == "" || expected == None || actual == "":
+		return ""
+	case limit > 0 && len(actual) > limit:
+		return ""
+	}
+	diff := format.UnifiedDiff(format.DiffConfig{
+		A:    strings.TrimSuffix(expected, "\n"),
+		B:    strings.TrimSuffix(actual, "\n"),
+		From: "expected " + stream,
+		To:   "actual " + stream,
+	})
+	if diff == "" {
+		return ""
+	}
+	return "\n" + strings.TrimSuffix(diff, "\n")
+}
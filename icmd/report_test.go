@@ -0,0 +1,99 @@
+package icmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/internal/overflow"
+)
+
+func TestResult_Match_TruncatesLongOutput(t *testing.T) {
+	defer func(orig int) { MaxFailureOutput = orig }(MaxFailureOutput)
+	MaxFailureOutput = 20
+
+	stdout := "start-" + strings.Repeat("x", 100) + "-end"
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer(stdout),
+		errBuffer: newLockedBuffer("short"),
+	}
+	err := result.match(Expected{Out: "missing"})
+	assert.ErrorContains(t, err, "Stdout:   start-xxxx\n... 90 bytes omitted, full stdout written to ")
+	assert.ErrorContains(t, err, "xxxxxx-end\nStderr:   short\n")
+	assert.Assert(t, !strings.Contains(err.Error(), "--- expected stdout"))
+
+	path := regexp.MustCompile(`full stdout written to (\S+) \.\.\.`).FindStringSubmatch(err.Error())
+	assert.Assert(t, len(path) == 2, err.Error())
+	defer os.Remove(path[1])
+	full, readErr := ioutil.ReadFile(path[1])
+	assert.NilError(t, readErr)
+	assert.Equal(t, string(full), stdout)
+
+	assert.Equal(t, result.String(), "\nCommand:  binary\nExitCode: 0\nStdout:   "+stdout+"\nStderr:   short\n")
+}
+
+func TestResult_Assert_WritesLongOutputToArtifacts(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "20")()
+
+	stdout := "start-" + strings.Repeat("x", 100) + "-end"
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer(stdout),
+		errBuffer: newLockedBuffer("short"),
+	}
+	err := result.matchT(t, Expected{Out: "missing"})
+	assert.ErrorContains(t, err, "... 90 bytes omitted, full stdout written to ")
+
+	path := regexp.MustCompile(`full stdout written to (\S+) \.\.\.`).FindStringSubmatch(err.Error())
+	assert.Assert(t, len(path) == 2, err.Error())
+	assert.Equal(t, filepath.Base(path[1]), "icmd-stdout-001.txt")
+	assert.Assert(t, cmp.Contains(filepath.Base(filepath.Dir(path[1])), "artifacts-"))
+	full, readErr := ioutil.ReadFile(path[1])
+	assert.NilError(t, readErr)
+	assert.Equal(t, string(full), stdout)
+}
+
+func TestResult_Match_InvalidMaxFailureSize(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "lots")()
+
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer("output"),
+		errBuffer: newLockedBuffer("short"),
+	}
+	err := result.match(Expected{Out: "missing"})
+	assert.ErrorContains(t, err, `Stdout:   output
+Stderr:   short
+invalid GOTESTTOOLS_MAX_FAILURE_SIZE: "lots", expected a number of bytes
+Failures:`)
+}
+
+func TestResult_Match_StreamDiff(t *testing.T) {
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		outBuffer: newLockedBuffer("line one\nline two\n"),
+		errBuffer: newLockedBuffer("warning\n"),
+	}
+	err := result.match(Expected{Out: "line one\nline 2\n", Err: "error"})
+	assert.Assert(t, cmp.Contains(err.Error(), `Expected stdout to contain "line one\nline 2\n"
+--- expected stdout
++++ actual stdout
+@@ -1,2 +1,2 @@
+ line one
+-line 2
++line two
+Expected stderr to contain "error"
+--- expected stderr
++++ actual stderr
+@@ -1 +1 @@
+-error
++warning`))
+}
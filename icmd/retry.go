@@ -0,0 +1,102 @@
+package icmd
+
+import (
+	"regexp"
+	"time"
+)
+
+// RetryPolicy configures how RunCmd re-runs a command which did not succeed.
+type RetryPolicy struct {
+	// Retries is the maximum number of times the command is run again after
+	// the first attempt.
+	Retries int
+	// Backoff is the time to wait before the first retry. The wait is doubled
+	// after each subsequent retry.
+	Backoff time.Duration
+	// Conditions are used to decide if a Result should be retried. If any of
+	// the conditions returns true the command is run again. When no conditions
+	// are set the command is retried when it exits with a non-zero exit code,
+	// fails to start, or hits the timeout.
+	Conditions []RetryCondition
+}
+
+// RetryCondition returns true if the command which produced the Result should
+// be run again.
+type RetryCondition func(result *Result) bool
+
+// RetryOnExitCode returns a RetryCondition which retries the command when it
+// exits with any of the exit codes.
+func RetryOnExitCode(codes ...int) RetryCondition {
+	return func(result *Result) bool {
+		for _, code := range codes {
+			if result.ExitCode == code {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// RetryOnOutput returns a RetryCondition which retries the command when
+// the combined stdout and stderr of the command matches the regular expression
+// pattern. RetryOnOutput panics if pattern is not a valid regular expression.
+func RetryOnOutput(pattern string) RetryCondition {
+	re := regexp.MustCompile(pattern)
+	return func(result *Result) bool {
+		return re.MatchString(result.Combined())
+	}
+}
+
+func retryOnFailure(result *Result) bool {
+	return result.ExitCode != 0 || result.Error != nil || result.Timeout
+}
+
+func (p *RetryPolicy) shouldRetry(result *Result) bool {
+	if len(p.Conditions) == 0 {
+		return retryOnFailure(result)
+	}
+	for _, condition := range p.Conditions {
+		if condition(result) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRetries runs the command up to retries more times when the result of
+// the previous attempt matches any of the conditions. The backoff is the
+// time to wait before the first retry, and is doubled after each retry.
+// If no conditions are given the command is retried when it does not succeed.
+//
+// The Result returned by RunCmd is the Result of the last attempt. Previous
+// attempts are available from Result.Attempts.
+//
+// Cmd.Stdin is read by the first attempt. Commands which read stdin should
+// not be retried unless the reader can be read more than once.
+func WithRetries(retries int, backoff time.Duration, conditions ...RetryCondition) CmdOp {
+	return func(c *Cmd) {
+		c.Retry = &RetryPolicy{
+			Retries:    retries,
+			Backoff:    backoff,
+			Conditions: conditions,
+		}
+	}
+}
+
+func runWithRetries(cmd Cmd, run func(Cmd) *Result) *Result {
+	result := run(cmd)
+	if cmd.Retry == nil {
+		return result
+	}
+
+	var attempts []*Result
+	backoff := cmd.Retry.Backoff
+	for i := 0; i < cmd.Retry.Retries && cmd.Retry.shouldRetry(result); i++ {
+		attempts = append(attempts, result)
+		time.Sleep(backoff)
+		backoff *= 2
+		result = run(cmd)
+	}
+	result.Attempts = attempts
+	return result
+}
@@ -0,0 +1,51 @@
+package icmd
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+func TestRunCmdWithRetriesSucceedsAfterFailures(t *testing.T) {
+	buildStub(t)
+	counter := fs.NewFile(t, "counter")
+
+	result := RunCmd(
+		Command(binname, "-counter="+counter.Path(), "-fail-runs=2"),
+		WithRetries(3, time.Millisecond))
+	result.Assert(t, Expected{Out: "this is stdout"})
+	assert.Equal(t, len(result.Attempts), 2)
+	for _, attempt := range result.Attempts {
+		attempt.Assert(t, Expected{ExitCode: 3, Out: "this is a failed run"})
+	}
+}
+
+func TestRunCmdWithRetriesExhausted(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-fail=5"), WithRetries(2, 0))
+	result.Assert(t, Expected{ExitCode: 5})
+	assert.Equal(t, len(result.Attempts), 2)
+	assert.ErrorContains(t, result.Compare(Success), "ExitCode: 5 (attempt 3)")
+}
+
+func TestRunCmdWithRetriesConditionNotMatched(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-fail=5"), WithRetries(2, 0, RetryOnExitCode(3)))
+	result.Assert(t, Expected{ExitCode: 5})
+	assert.Equal(t, len(result.Attempts), 0)
+}
+
+func TestRunCmdWithRetriesOnOutput(t *testing.T) {
+	buildStub(t)
+	counter := fs.NewFile(t, "counter")
+
+	result := RunCmd(
+		Command(binname, "-counter="+counter.Path(), "-fail-runs=1"),
+		WithRetries(2, 0, RetryOnOutput("failed run")))
+	result.Assert(t, Success)
+	assert.Equal(t, len(result.Attempts), 1)
+}
@@ -0,0 +1,14 @@
+package icmd
+
+import (
+	"os"
+	"syscall"
+)
+
+func maxRSS(state *os.ProcessState) int64 {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		// Maxrss is in bytes
+		return rusage.Maxrss
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build windows || js || wasip1 || plan9
+// +build windows js wasip1 plan9
+
+package icmd
+
+import "os"
+
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
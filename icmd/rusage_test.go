@@ -0,0 +1,42 @@
+package icmd
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunCmdRecordsUsage(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-sleep=50ms"), WithTimeout(10*time.Second))
+	result.Assert(t, Expected{MinDuration: 50 * time.Millisecond, MaxDuration: 10 * time.Second})
+	assert.Assert(t, result.UserTime+result.SystemTime > 0)
+	if runtime.GOOS != "windows" {
+		assert.Assert(t, result.MaxRSS > 0)
+	}
+}
+
+func TestRunCmdRecordsDurationOnTimeout(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-sleep=2s"), WithTimeout(30*time.Millisecond))
+	result.Assert(t, Expected{Timeout: true, MinDuration: 30 * time.Millisecond})
+}
+
+func TestResult_Match_Duration(t *testing.T) {
+	result := &Result{
+		Cmd:       exec.Command("binary"),
+		Duration:  2 * time.Second,
+		outBuffer: newLockedBuffer(""),
+		errBuffer: newLockedBuffer(""),
+	}
+	err := result.match(Expected{MaxDuration: time.Second})
+	assert.ErrorContains(t, err, "Duration was 2s expected at most 1s")
+
+	err = result.match(Expected{MinDuration: 3 * time.Second})
+	assert.ErrorContains(t, err, "Duration was 2s expected at least 3s")
+}
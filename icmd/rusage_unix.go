@@ -0,0 +1,17 @@
+//go:build !windows && !darwin && !js && !wasip1 && !plan9
+// +build !windows,!darwin,!js,!wasip1,!plan9
+
+package icmd
+
+import (
+	"os"
+	"syscall"
+)
+
+func maxRSS(state *os.ProcessState) int64 {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		// Maxrss is in kilobytes
+		return int64(rusage.Maxrss) * 1024
+	}
+	return 0
+}
@@ -0,0 +1,72 @@
+package icmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the value of secrets in failure messages
+const Redacted = "[REDACTED]"
+
+// WithSecrets registers values which are sensitive, such as tokens passed to
+// the command as flags. The values are replaced with Redacted in the command
+// line, output, and errors included in the failure messages of
+// Result.Assert, Result.Compare, and Result.String.
+//
+// The values are not removed from Result.Stdout, Result.Stderr, or
+// Result.Cmd.
+func WithSecrets(values ...string) CmdOp {
+	return func(c *Cmd) {
+		c.Secrets = append(c.Secrets, values...)
+	}
+}
+
+// WithSecretEnv registers the names of environment variables which contain
+// sensitive values. The values of the variables, from Cmd.Env or the
+// environment of the current process, are treated as secrets. See WithSecrets.
+func WithSecretEnv(names ...string) CmdOp {
+	return func(c *Cmd) {
+		c.SecretEnv = append(c.SecretEnv, names...)
+	}
+}
+
+// secrets returns the secret values of cmd, longest first so that a secret
+// which contains another secret is fully redacted.
+func (c Cmd) secrets() []string {
+	var secrets []string
+	for _, value := range c.Secrets {
+		if value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	for _, name := range c.SecretEnv {
+		if value := c.lookupEnv(name); value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	return secrets
+}
+
+func (c Cmd) lookupEnv(name string) string {
+	if c.Env == nil {
+		return os.Getenv(name)
+	}
+	var value string
+	for _, kv := range c.Env {
+		if strings.HasPrefix(kv, name+"=") {
+			value = kv[len(name)+1:]
+		}
+	}
+	return value
+}
+
+func redact(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.Replace(s, secret, Redacted, -1)
+	}
+	return s
+}
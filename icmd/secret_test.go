@@ -0,0 +1,30 @@
+package icmd
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestRunCmdWithSecrets(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-fail=2", "-stderr=token s3cr3t-value"),
+		WithSecrets("s3cr3t", "s3cr3t-value", ""))
+	err := result.Compare(Success)
+	assert.ErrorContains(t, err, "-stderr=token [REDACTED]")
+	assert.Assert(t, !strings.Contains(err.Error(), "s3cr3t"), err.Error())
+	assert.Assert(t, cmp.Contains(result.Stderr(), "s3cr3t-value"))
+}
+
+func TestRunCmdWithSecretEnv(t *testing.T) {
+	buildStub(t)
+
+	result := RunCmd(Command(binname, "-stderr=env-token"),
+		WithEnv("TOKEN=env-token", "OTHER=value"),
+		WithSecretEnv("TOKEN", "MISSING"))
+	assert.Assert(t, cmp.Contains(result.String(), "-stderr=[REDACTED]"))
+	assert.Assert(t, !strings.Contains(result.String(), "env-token"))
+}
@@ -0,0 +1,52 @@
+package icmd
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
+)
+
+func TestResultSignalTerminatesCommand(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt is not supported on windows")
+	buildStub(t)
+
+	result := StartCmd(Command(binname, "-sleep=10s"))
+	assert.NilError(t, result.Error)
+	assert.NilError(t, result.Signal(os.Interrupt))
+
+	WaitOnCmd(5*time.Second, result)
+	result.Assert(t, Expected{ExitSignal: os.Interrupt, Out: None})
+	assert.ErrorContains(t, result.Compare(Expected{ExitSignal: os.Kill}),
+		"ExitSignal was interrupt expected killed")
+}
+
+func TestResultSignalGracefulShutdown(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt is not supported on windows")
+	buildStub(t)
+
+	result := StartCmd(Command(binname, "-trap"))
+	assert.NilError(t, result.Error)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if strings.Contains(result.Stdout(), "waiting for signal") {
+			return poll.Success()
+		}
+		return poll.Continue("command is not ready")
+	}, poll.WithDelay(10*time.Millisecond))
+	assert.NilError(t, result.Signal(os.Interrupt))
+
+	WaitOnCmd(5*time.Second, result)
+	result.Assert(t, Expected{Out: "got signal interrupt"})
+	assert.Assert(t, result.ExitSignal == nil)
+}
+
+func TestResultSignalNotStarted(t *testing.T) {
+	result := &Result{Cmd: exec.Command("true")}
+	assert.Error(t, result.Signal(os.Kill), "command has not been started")
+}
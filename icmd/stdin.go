@@ -0,0 +1,65 @@
+package icmd
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"gotest.tools/v3/golden"
+)
+
+// WithStdinFile sets the standard input of the command to the file at path.
+// The file is passed directly to the command, so large files are not read
+// into memory. The file is opened every time the command is run, so commands
+// run WithRetries read the whole file on every attempt.
+//
+// If the file can not be opened the command is not started, and the error is
+// set on the Result.
+func WithStdinFile(path string) CmdOp {
+	return func(c *Cmd) {
+		c.StdinFile = path
+	}
+}
+
+// WithStdinGolden sets the standard input of the command to the golden file
+// in ./testdata. See WithStdinFile and gotest.tools/v3/golden.
+func WithStdinGolden(filename string) CmdOp {
+	return WithStdinFile(golden.Path(filename))
+}
+
+// WithStdinFunc sets the standard input of the command to the data written by
+// write. write is called in a new goroutine when the command first reads from
+// stdin, and the data is streamed to the command through a pipe, so large or
+// generated input does not need to be held in memory. Stdin is closed when
+// write returns.
+func WithStdinFunc(write func(w io.Writer) error) CmdOp {
+	return func(c *Cmd) {
+		c.Stdin = &generatorReader{write: write}
+	}
+}
+
+// generatorReader starts a goroutine which writes to a pipe on the first call
+// to Read.
+type generatorReader struct {
+	write  func(w io.Writer) error
+	once   sync.Once
+	reader *io.PipeReader
+}
+
+func (g *generatorReader) Read(p []byte) (int, error) {
+	g.once.Do(func() {
+		reader, writer := io.Pipe()
+		g.reader = reader
+		go func() {
+			writer.CloseWithError(g.write(writer)) //nolint: errcheck
+		}()
+	})
+	return g.reader.Read(p)
+}
+
+func openStdinFile(cmd Cmd) (*os.File, error) {
+	if cmd.StdinFile == "" {
+		return nil, nil
+	}
+	return os.Open(cmd.StdinFile)
+}
@@ -0,0 +1,60 @@
+package icmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/fs"
+	"gotest.tools/v3/skip"
+)
+
+func TestRunCmdWithStdinFile(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sort")
+	file := fs.NewFile(t, "stdin", fs.WithContent("b\na\n"))
+
+	result := RunCmd(Command("sort"), WithStdinFile(file.Path()))
+	result.Assert(t, Expected{Out: "a\nb\n"})
+}
+
+func TestRunCmdWithStdinFileMissing(t *testing.T) {
+	result := RunCmd(Command("sort"), WithStdinFile("/does/not/exist"))
+	result.Assert(t, Expected{ExitCode: 127, Error: "open /does/not/exist"})
+}
+
+func TestRunCmdWithStdinGolden(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sort")
+
+	result := RunCmd(Command("sort"), WithStdinGolden("stdin.golden"))
+	result.Assert(t, Expected{Out: "a\nb\nc\n"})
+}
+
+func TestRunCmdWithStdinFunc(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires wc")
+
+	result := RunCmd(Command("wc", "-l"), WithStdinFunc(func(w io.Writer) error {
+		for i := 0; i < 100000; i++ {
+			if _, err := fmt.Fprintf(w, "line %d\n", i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	result.Assert(t, Success)
+	if strings.TrimSpace(result.Stdout()) != "100000" {
+		t.Fatalf("unexpected line count: %q", result.Stdout())
+	}
+}
+
+func TestRunCmdWithStdinFuncError(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires cat")
+
+	result := RunCmd(Command("cat"), WithStdinFunc(func(w io.Writer) error {
+		fmt.Fprint(w, "partial") //nolint: errcheck
+		return errors.New("generator failed")
+	}))
+	result.Assert(t, Expected{ExitCode: 127, Out: "partial", Error: "generator failed"})
+}
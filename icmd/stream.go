@@ -0,0 +1,189 @@
+package icmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// streamPollInterval is the time between checks of the output of a running
+// command by WaitForLine.
+const streamPollInterval = 10 * time.Millisecond
+
+// outputStream is the output of a command written to stdout or stderr, and the
+// offset of the end of the last line matched by WaitForLine.
+type outputStream struct {
+	name   string
+	buffer *lockedBuffer
+	cursor *int64
+}
+
+// WaitForLine waits until a line written to stdout or stderr by the running
+// command matches re, and returns the line without the trailing newline. re
+// may be a *regexp.Regexp or a string that is a valid regexp pattern. Only
+// complete lines, terminated by a newline, are matched.
+//
+// Each call only matches lines written after the line matched by the previous
+// call for the same stream, so a sequence of calls can wait for the output of
+// each step of an interaction with a command:
+//
+//	result := icmd.StartCmd(icmd.Command("server"))
+//	result.WaitForLine(t, "listening on", 5*time.Second)
+//	// ... connect to the server ...
+//	result.WaitForLine(t, "client connected", 5*time.Second)
+//	assert.NilError(t, result.Signal(os.Interrupt))
+//
+// If no line matches before timeout, the test fails with the output of the
+// command. WaitForLine may be used with StartCmd, StartDaemon, or while
+// another goroutine waits for the command with WaitOnCmd.
+func (r *Result) WaitForLine(t assert.TestingT, re cmp.RegexOrPattern, timeout time.Duration) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return r.waitForLine(t, re, timeout, r.stdoutStream(), r.stderrStream())
+}
+
+// WaitForStdoutLine is like WaitForLine, but only matches lines written to
+// stdout.
+func (r *Result) WaitForStdoutLine(t assert.TestingT, re cmp.RegexOrPattern, timeout time.Duration) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return r.waitForLine(t, re, timeout, r.stdoutStream())
+}
+
+// WaitForStderrLine is like WaitForLine, but only matches lines written to
+// stderr.
+func (r *Result) WaitForStderrLine(t assert.TestingT, re cmp.RegexOrPattern, timeout time.Duration) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return r.waitForLine(t, re, timeout, r.stderrStream())
+}
+
+// WaitForTTYLine is like WaitForLine, but matches lines written to the
+// terminal of a command started WithTTY. The terminal echoes the input
+// written by WriteTTY, so a line of input may also match re.
+func (r *Result) WaitForTTYLine(t assert.TestingT, re cmp.RegexOrPattern, timeout time.Duration) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if r.tty == nil {
+		assert.Assert(t, func() cmp.Result {
+			return cmp.ResultFailure("command was not run WithTTY")
+		})
+		return ""
+	}
+	return r.waitForLine(t, re, timeout, outputStream{name: "tty", buffer: r.tty.buffer, cursor: &r.tty.cursor})
+}
+
+func (r *Result) stdoutStream() outputStream {
+	return outputStream{name: "stdout", buffer: r.outBuffer, cursor: &r.outCursor}
+}
+
+func (r *Result) stderrStream() outputStream {
+	return outputStream{name: "stderr", buffer: r.errBuffer, cursor: &r.errCursor}
+}
+
+func (r *Result) waitForLine(
+	t assert.TestingT,
+	re cmp.RegexOrPattern,
+	timeout time.Duration,
+	streams ...outputStream,
+) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	line, err := r.matchLine(re, timeout, streams)
+	assert.Assert(t, func() cmp.Result {
+		return cmp.ResultFromError(err)
+	})
+	return line
+}
+
+func (r *Result) matchLine(re cmp.RegexOrPattern, timeout time.Duration, streams []outputStream) (string, error) {
+	regex, err := compileRegexp(re)
+	if err != nil {
+		return "", err
+	}
+	if r.outBuffer == nil {
+		return "", fmt.Errorf("command was not started: %s", r.Error)
+	}
+
+	deadline := r.timeoutClock().After(timeout)
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, stream := range streams {
+			if line, ok := stream.next(regex); ok {
+				return line, nil
+			}
+		}
+		select {
+		case <-deadline:
+			names := make([]string, 0, len(streams))
+			for _, stream := range streams {
+				names = append(names, stream.name)
+			}
+			// Only the output is included, the other fields of the Result may
+			// be set concurrently by WaitOnCmd.
+			output := fmt.Sprintf("\nStdout:   %v\nStderr:   %v\n", r.Stdout(), r.Stderr())
+			if r.tty != nil {
+				output += fmt.Sprintf("TTY:      %v\n", r.TTY())
+			}
+			output = redact(output, r.secrets)
+			return "", fmt.Errorf("timeout after %s waiting for a line of %s matching %q%s",
+				timeout, strings.Join(names, " or "), regex.String(), output)
+		case <-ticker.C:
+		}
+	}
+}
+
+// next returns the first complete line after the cursor which matches re,
+// and moves the cursor to the end of that line. Lines which were partly
+// dropped by WithMaxOutput are skipped.
+func (s outputStream) next(re *regexp.Regexp) (string, bool) {
+	for {
+		output, start, truncated := s.buffer.readFrom(*s.cursor)
+		// The start of the first line was dropped if the output does not
+		// start at the cursor.
+		partial := start > *s.cursor
+		*s.cursor = start
+		for {
+			end := strings.IndexByte(output, '\n')
+			if end < 0 {
+				break
+			}
+			line := strings.TrimSuffix(output[:end], "\r")
+			output = output[end+1:]
+			*s.cursor += int64(end + 1)
+			if partial {
+				partial = false
+				continue
+			}
+			if re.MatchString(line) {
+				return line, true
+			}
+		}
+		if !truncated {
+			return "", false
+		}
+		// The end of the line was dropped, continue after the dropped bytes.
+		*s.cursor += int64(len(output))
+	}
+}
+
+func compileRegexp(re cmp.RegexOrPattern) (*regexp.Regexp, error) {
+	switch typed := re.(type) {
+	case *regexp.Regexp:
+		return typed, nil
+	case string:
+		return regexp.Compile(typed)
+	default:
+		return nil, fmt.Errorf("invalid type %T for regex pattern", re)
+	}
+}
@@ -0,0 +1,131 @@
+package icmd
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+type streamFakeT struct {
+	logs   []string
+	failed bool
+}
+
+func (t *streamFakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *streamFakeT) Fail() {
+	t.failed = true
+}
+
+func (t *streamFakeT) FailNow() {
+	t.failed = true
+}
+
+func TestWaitForLine(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+
+	stdin, w := io.Pipe()
+	cmd := Command("sh", "-c",
+		`echo step 1; echo warning: slow >&2; read line; echo "got $line"; echo step 2; sleep 10`)
+	WithStdin(stdin)(&cmd)
+	result := StartCmd(cmd)
+	assert.NilError(t, result.Error)
+	done := make(chan struct{})
+	go func() {
+		WaitOnCmd(0, result)
+		close(done)
+	}()
+
+	assert.Equal(t, result.WaitForStdoutLine(t, "^step", time.Second), "step 1")
+	assert.Equal(t, result.WaitForStderrLine(t, regexp.MustCompile("warning"), time.Second),
+		"warning: slow")
+
+	_, err := io.WriteString(w, "hello\n")
+	assert.NilError(t, err)
+	assert.Equal(t, result.WaitForLine(t, "^got", time.Second), "got hello")
+	assert.Equal(t, result.WaitForLine(t, "^step", time.Second), "step 2")
+
+	assert.NilError(t, w.Close())
+	assert.NilError(t, result.killGroup())
+	<-done
+}
+
+func TestWaitForLineTimeout(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+
+	result := StartCmd(Command("sh", "-c", `echo ready; echo partial`))
+	WaitOnCmd(time.Second, result)
+
+	fakeT := &streamFakeT{}
+	assert.Equal(t, result.WaitForLine(fakeT, "ready", time.Second), "ready")
+	assert.Equal(t, result.WaitForLine(fakeT, "ready", 20*time.Millisecond), "")
+	assert.Assert(t, fakeT.failed)
+	assert.Equal(t, len(fakeT.logs), 1)
+	assert.Assert(t, cmp.Contains(fakeT.logs[0],
+		`timeout after 20ms waiting for a line of stdout or stderr matching "ready"
+Stdout:   ready
+partial
+`))
+}
+
+func TestWaitForLineInvalidPattern(t *testing.T) {
+	fakeT := &streamFakeT{}
+	result := &Result{outBuffer: newLimitedBuffer(0), errBuffer: newLimitedBuffer(0)}
+	result.WaitForLine(fakeT, "[", time.Millisecond)
+	assert.Assert(t, fakeT.failed)
+	assert.Assert(t, cmp.Contains(fakeT.logs[0], "missing closing ]"))
+}
+
+func TestWaitForLineWithMaxOutput(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires sh")
+
+	result := RunCmd(Command("sh", "-c", `i=1; while [ $i -le 100 ]; do echo line $i; i=$((i+1)); done`),
+		WithMaxOutput(64))
+	result.Assert(t, Success)
+
+	// The head ends with the start of line 5, and the tail starts with the
+	// end of line 97, so those lines are skipped.
+	for _, expected := range []string{"line 1", "line 2", "line 3", "line 4", "line 98", "line 99", "line 100"} {
+		assert.Equal(t, result.WaitForStdoutLine(t, "^l", time.Second), expected)
+	}
+	fakeT := &streamFakeT{}
+	result.WaitForStdoutLine(fakeT, "^l", 20*time.Millisecond)
+	assert.Assert(t, fakeT.failed)
+}
+
+func TestOutputStreamNextAfterDroppedOutput(t *testing.T) {
+	var cursor int64
+	stream := outputStream{name: "stdout", buffer: newLimitedBuffer(20), cursor: &cursor}
+	re := regexp.MustCompile("^line")
+
+	fmt.Fprint(stream.buffer, "line 1\nline 2\n")
+	line, ok := stream.next(re)
+	assert.Assert(t, ok)
+	assert.Equal(t, line, "line 1")
+	line, ok = stream.next(re)
+	assert.Assert(t, ok)
+	assert.Equal(t, line, "line 2")
+
+	fmt.Fprint(stream.buffer, "line 3\nline 4\nline 5\n")
+	line, ok = stream.next(re)
+	assert.Assert(t, ok)
+	assert.Equal(t, line, "line 5")
+	_, ok = stream.next(re)
+	assert.Assert(t, !ok)
+
+	fmt.Fprint(stream.buffer, "line 6\n")
+	line, ok = stream.next(re)
+	assert.Assert(t, ok)
+	assert.Equal(t, line, "line 6")
+	_, ok = stream.next(re)
+	assert.Assert(t, !ok)
+}
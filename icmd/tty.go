@@ -0,0 +1,115 @@
+package icmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// ttyDrainTimeout is the maximum time to wait for the output written to the
+// terminal to be read once the command has exited. A process started by the
+// command may keep the terminal open after the command exits.
+const ttyDrainTimeout = time.Second
+
+// WithTTY runs the command with a pseudo-terminal as its standard input,
+// output, and error, so that a command which checks if it is connected to a
+// terminal behaves as it does when run interactively. Use it to test prompts,
+// colored output, or progress bars.
+//
+// The output written to the terminal is available from Result.TTY, and can be
+// checked with Expected.TTY and Expected.TTYMatch. Stdout and Stderr are
+// empty. Use Result.WriteTTY to write to the terminal, as if typed by a user,
+// and Result.WaitForTTYLine to wait for a line of output from a command
+// started with StartCmd.
+//
+// The terminal translates newlines in the output to "\r\n", and echoes the
+// input written by WriteTTY back to the output, unless the command disables
+// echo. The terminal has 24 rows and 80 columns.
+//
+// Cmd.Stdin, Cmd.StdinFile, Cmd.Stderr, and Cmd.MergeStderr are ignored. The
+// output written to the terminal is copied to Cmd.Stdout.
+//
+// WithTTY is only supported on Linux. On other platforms the command is not
+// started, and the error is set on the Result.
+func WithTTY() CmdOp {
+	return func(c *Cmd) {
+		c.TTY = true
+	}
+}
+
+// ttyConn is the pseudo-terminal of a command run WithTTY.
+type ttyConn struct {
+	master *os.File
+	slave  *os.File
+	buffer *lockedBuffer
+	// output receives everything read from the terminal.
+	output io.Writer
+	done   chan struct{}
+	// cursor is the offset of the end of the last line matched by
+	// WaitForTTYLine.
+	cursor int64
+}
+
+func newTTYConn(cmd Cmd) (*ttyConn, error) {
+	master, slave, err := openTTY()
+	if err != nil {
+		return nil, err
+	}
+	buffer := newLimitedBuffer(cmd.MaxOutput)
+	tty := &ttyConn{
+		master: master,
+		slave:  slave,
+		buffer: buffer,
+		output: buffer,
+		done:   make(chan struct{}),
+	}
+	if cmd.Stdout != nil {
+		tty.output = io.MultiWriter(buffer, cmd.Stdout)
+	}
+	return tty, nil
+}
+
+// attach connects the standard streams of execCmd to the terminal, and makes
+// it the controlling terminal of the command.
+func (tty *ttyConn) attach(execCmd *exec.Cmd) {
+	execCmd.Stdin = tty.slave
+	execCmd.Stdout = tty.slave
+	execCmd.Stderr = tty.slave
+	setControllingTerminal(execCmd)
+}
+
+// read copies the output written to the terminal until every process has
+// closed the terminal, or the terminal is closed.
+func (tty *ttyConn) read() {
+	defer close(tty.done)
+	io.Copy(tty.output, tty.master) //nolint: errcheck
+}
+
+// close waits for the output to be read, and closes the terminal.
+func (tty *ttyConn) close() {
+	select {
+	case <-tty.done:
+	case <-time.After(ttyDrainTimeout):
+	}
+	tty.master.Close() //nolint: errcheck
+}
+
+// TTY returns the output written to the terminal by a command run WithTTY.
+func (r *Result) TTY() string {
+	if r.tty == nil {
+		return ""
+	}
+	return r.tty.buffer.String()
+}
+
+// WriteTTY writes input to the terminal of a command started WithTTY, as if
+// it was typed by a user. End input with "\r" or "\n" to enter a line.
+func (r *Result) WriteTTY(input string) error {
+	if r.tty == nil {
+		return errors.New("command was not run WithTTY")
+	}
+	_, err := io.WriteString(r.tty.master, input)
+	return err
+}
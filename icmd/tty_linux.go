@@ -0,0 +1,76 @@
+package icmd
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// openTTY opens a new pseudo-terminal, and returns the master and slave ends.
+func openTTY() (*os.File, *os.File, error) {
+	fd, err := syscall.Open("/dev/ptmx", syscall.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "open", Path: "/dev/ptmx", Err: err}
+	}
+	name, err := setupTTY(fd)
+	if err != nil {
+		syscall.Close(fd) //nolint: errcheck
+		return nil, nil, err
+	}
+	// A non-blocking master is read with the runtime poller, so that closing
+	// the master stops a pending read.
+	if err := syscall.SetNonblock(fd, true); err != nil {
+		syscall.Close(fd) //nolint: errcheck
+		return nil, nil, err
+	}
+	master := os.NewFile(uintptr(fd), "/dev/ptmx")
+
+	slave, err := os.OpenFile(name, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close() //nolint: errcheck
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// setupTTY unlocks the slave end of the pseudo-terminal, sets the size of the
+// terminal, and returns the path to the slave.
+func setupTTY(fd int) (string, error) {
+	var unlock int32
+	if err := ioctl(fd, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		return "", err
+	}
+	var n uint32
+	if err := ioctl(fd, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		return "", err
+	}
+	size := struct{ rows, cols, x, y uint16 }{rows: 24, cols: 80}
+	if err := ioctl(fd, syscall.TIOCSWINSZ, unsafe.Pointer(&size)); err != nil {
+		return "", err
+	}
+	return "/dev/pts/" + strconv.FormatUint(uint64(n), 10), nil
+}
+
+func ioctl(fd int, req uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(arg))
+	if errno != 0 {
+		return os.NewSyscallError("ioctl", errno)
+	}
+	return nil
+}
+
+// setControllingTerminal starts the command in a new session, with stdin as
+// the controlling terminal. The command is the leader of a new process group,
+// so it can still be killed with all the processes it starts.
+func setControllingTerminal(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// A session leader can not change its process group.
+	cmd.SysProcAttr.Setpgid = false
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package icmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+func openTTY() (*os.File, *os.File, error) {
+	return nil, nil, fmt.Errorf("WithTTY is not supported on %s", runtime.GOOS)
+}
+
+func setControllingTerminal(*exec.Cmd) {}
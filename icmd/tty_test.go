@@ -0,0 +1,73 @@
+package icmd
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/skip"
+)
+
+func TestWithTTY(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux", "WithTTY requires linux")
+
+	result := RunCmd(Command("sh", "-c", `test -t 0 && test -t 1 && test -t 2 && echo terminal`),
+		WithTTY(), WithTimeout(10*time.Second))
+	result.Assert(t, Expected{TTY: "terminal\r\n", Out: None, Err: None})
+	assert.Equal(t, result.TTY(), "terminal\r\n")
+}
+
+func TestWithTTYSize(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux", "WithTTY requires linux")
+
+	result := RunCmd(Command("stty", "size"), WithTTY(), WithTimeout(10*time.Second))
+	result.Assert(t, Expected{TTY: "24 80\r\n"})
+}
+
+func TestWithTTYInteractive(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux", "WithTTY requires linux")
+
+	cmd := Command("sh", "-c", `echo "name?"; read name; echo "hello $name"`)
+	WithTTY()(&cmd)
+	result := StartCmd(cmd)
+	assert.NilError(t, result.Error)
+
+	assert.Equal(t, result.WaitForTTYLine(t, "name", 5*time.Second), "name?")
+	assert.NilError(t, result.WriteTTY("gopher\n"))
+	assert.Equal(t, result.WaitForTTYLine(t, "^hello", 5*time.Second), "hello gopher")
+
+	WaitOnCmd(10*time.Second, result).Assert(t, Expected{
+		TTY:      "name?\r\ngopher\r\nhello gopher\r\n",
+		TTYMatch: MatchRegexp(`(?m)^hello gopher\r$`),
+	})
+}
+
+func TestWithTTYFailureMessage(t *testing.T) {
+	skip.If(t, runtime.GOOS != "linux", "WithTTY requires linux")
+
+	result := RunCmd(Command("sh", "-c", `echo prompt`), WithTTY(), WithTimeout(10*time.Second))
+	err := result.Compare(Expected{TTY: "missing"})
+	assert.Assert(t, cmp.ErrorContains(err, "TTY:      prompt\r\n"))
+	assert.Assert(t, cmp.ErrorContains(err, `Expected tty to contain "missing"`))
+}
+
+func TestWithTTYNotSupported(t *testing.T) {
+	skip.If(t, runtime.GOOS == "linux", "WithTTY is supported on linux")
+
+	result := RunCmd(Command("echo"), WithTTY())
+	assert.ErrorContains(t, result.Error, "WithTTY is not supported on "+runtime.GOOS)
+}
+
+func TestResultWriteTTYWithoutTTY(t *testing.T) {
+	result := &Result{}
+	assert.ErrorContains(t, result.WriteTTY("input"), "command was not run WithTTY")
+	assert.Equal(t, result.TTY(), "")
+
+	fakeT := &streamFakeT{}
+	result.WaitForTTYLine(fakeT, "line", time.Second)
+	assert.Assert(t, fakeT.failed)
+	assert.Assert(t, strings.Contains(strings.Join(fakeT.logs, "\n"), "command was not run WithTTY"))
+}
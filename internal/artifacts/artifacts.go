@@ -0,0 +1,183 @@
+/*
+Package artifacts manages the artifacts directory of each test. It is used by
+the gotest.tools/v3/artifacts package, and by packages which can not import it
+because gotest.tools/v3/artifacts imports gotest.tools/v3/assert.
+*/
+package artifacts // import "gotest.tools/v3/internal/artifacts"
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/internal/cleanup"
+	"gotest.tools/v3/internal/testname"
+)
+
+// RootEnvVar is the name of the environment variable which sets the directory
+// where the artifacts of failed tests are copied.
+const RootEnvVar = "GOTESTTOOLS_ARTIFACTS_ROOT"
+
+// TestingT is the subset of testing.T used by Dir.
+type TestingT interface {
+	Log(args ...interface{})
+	Name() string
+}
+
+type helperT interface {
+	Helper()
+}
+
+type failedT interface {
+	Failed() bool
+}
+
+var (
+	mu   sync.Mutex
+	dirs = map[TestingT]string{}
+)
+
+// Dir returns the artifacts directory of the test. See the
+// gotest.tools/v3/artifacts package for details.
+func Dir(t TestingT) (string, error) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if dir, ok := dirs[t]; ok {
+		return dir, nil
+	}
+
+	dir, err := ioutil.TempDir("", "artifacts-"+testname.ID(t)+"-")
+	if err != nil {
+		return "", err
+	}
+	dirs[t] = dir
+	RegisterCleanup(t, func() {
+		mu.Lock()
+		delete(dirs, t)
+		mu.Unlock()
+		finish(t, dir)
+	})
+	return dir, nil
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// implemented by gotest.tools/x/subtest.TestContext
+type addCleanupT interface {
+	AddCleanup(f func())
+}
+
+// RegisterCleanup is like cleanup.Cleanup, but ignores TEST_NOCLEANUP, because
+// the failures must still be reported.
+func RegisterCleanup(t TestingT, f func()) {
+	switch typed := t.(type) {
+	case cleanupT:
+		typed.Cleanup(f)
+	case addCleanupT:
+		typed.AddCleanup(f)
+	}
+}
+
+func finish(t TestingT, dir string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	files, err := listFiles(dir)
+	if err != nil {
+		t.Log(fmt.Sprintf("failed to list artifacts in %s: %s", dir, err))
+		return
+	}
+	if ft, ok := t.(failedT); !ok || !ft.Failed() || len(files) == 0 {
+		cleanup.Cleanup(t, func() {
+			os.RemoveAll(dir) //nolint: errcheck
+		})
+		return
+	}
+
+	location := dir
+	if root := os.Getenv(RootEnvVar); root != "" {
+		target := filepath.Join(root, testname.ID(t))
+		if err := copyDir(dir, target, files); err != nil {
+			t.Log(fmt.Sprintf("failed to copy artifacts to %s: %s", target, err))
+		} else {
+			location = target
+			os.RemoveAll(dir) //nolint: errcheck
+		}
+	}
+	t.Log(formatIndex(location, files))
+}
+
+type file struct {
+	path string
+	size int64
+}
+
+func listFiles(dir string) ([]file, error) {
+	var files []file
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path: rel, size: info.Size()})
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	return files, err
+}
+
+func formatIndex(dir string, files []file) string {
+	lines := make([]string, 0, len(files))
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("  %s (%d bytes)", f.path, f.size))
+	}
+	return fmt.Sprintf("artifacts in %s:\n%s", dir, strings.Join(lines, "\n"))
+}
+
+func copyDir(source, target string, files []file) error {
+	for _, f := range files {
+		if err := copyFile(filepath.Join(source, f.path), filepath.Join(target, f.path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint: errcheck
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close() //nolint: errcheck
+		return err
+	}
+	return out.Close()
+}
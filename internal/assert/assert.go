@@ -7,7 +7,6 @@ import (
 	"reflect"
 
 	"gotest.tools/v3/assert/cmp"
-	"gotest.tools/v3/internal/format"
 	"gotest.tools/v3/internal/source"
 )
 
@@ -20,6 +19,12 @@ type helperT interface {
 	Helper()
 }
 
+// argIndexT is implemented by a LogT passed to Eval by assertions which do not
+// accept the comparison as their second argument.
+type argIndexT interface {
+	comparisonArgIndex() int
+}
+
 const failureMessage = "assertion failed: "
 
 // Eval the comparison and print a failure messages if the comparison has failed.
@@ -49,7 +54,7 @@ func Eval(
 
 	case error:
 		msg := failureMsgFromError(check)
-		t.Log(format.WithCustomMessage(failureMessage+msg, msgAndArgs...))
+		logFailure(t, msg, msgAndArgs...)
 
 	case cmp.Comparison:
 		success = RunComparison(t, argSelector, check, msgAndArgs...)
@@ -60,9 +65,27 @@ func Eval(
 	default:
 		t.Log(fmt.Sprintf("invalid Comparison: %v (%T)", check, check))
 	}
+	if !success {
+		logSnippet(t)
+	}
 	return success
 }
 
+// logSnippet logs the source around the failed assertion when it is enabled
+// by the GOTESTTOOLS_SOURCE_SNIPPET environment variable.
+func logSnippet(t LogT) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	snippet, err := source.CallerSnippet()
+	switch {
+	case err != nil:
+		t.Log(err.Error())
+	case snippet != "":
+		t.Log(snippet)
+	}
+}
+
 func runCompareFunc(
 	t LogT,
 	f func() (success bool, message string),
@@ -72,7 +95,7 @@ func runCompareFunc(
 		ht.Helper()
 	}
 	if success, message := f(); !success {
-		t.Log(format.WithCustomMessage(failureMessage+message, msgAndArgs...))
+		logFailure(t, message, msgAndArgs...)
 		return false
 	}
 	return true
@@ -89,7 +112,10 @@ func logFailureFromBool(t LogT, msgAndArgs ...interface{}) {
 		return
 	}
 
-	const comparisonArgIndex = 1 // Assert(t, comparison)
+	comparisonArgIndex := 1 // Assert(t, comparison)
+	if at, ok := t.(argIndexT); ok {
+		comparisonArgIndex = at.comparisonArgIndex()
+	}
 	if len(args) <= comparisonArgIndex {
 		t.Log(failureMessage + "but assert failed to find the expression to print")
 		return
@@ -101,7 +127,7 @@ func logFailureFromBool(t LogT, msgAndArgs ...interface{}) {
 		msg = "expression is false"
 	}
 
-	t.Log(format.WithCustomMessage(failureMessage+msg, msgAndArgs...))
+	logFailure(t, msg, msgAndArgs...)
 }
 
 func failureMsgFromError(err error) string {
@@ -0,0 +1,100 @@
+package assert
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Record is a LogT which records the messages logged by a single assertion,
+// so that they can be added to a Buffer instead of being logged immediately.
+//
+// Record is used by assertions which accept the comparison as their first
+// argument, like Collector.Check.
+type Record struct {
+	messages []string
+}
+
+// Log records the message.
+func (r *Record) Log(args ...interface{}) {
+	r.messages = append(r.messages, fmt.Sprint(args...))
+}
+
+func (r *Record) comparisonArgIndex() int {
+	return 0
+}
+
+// Buffer collects the failures of assertions, grouped by the location of the
+// assertion which failed. Buffer is safe to use from multiple goroutines.
+type Buffer struct {
+	mu     sync.Mutex
+	groups []*failureGroup
+	count  int
+}
+
+type failureGroup struct {
+	file     string
+	line     int
+	messages []string
+}
+
+// Add the messages recorded by a failed assertion, called from file and line.
+func (b *Buffer) Add(file string, line int, record *Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.count++
+	message := strings.Join(record.messages, "\n")
+	for _, group := range b.groups {
+		if group.file == file && group.line == line {
+			group.messages = append(group.messages, message)
+			return
+		}
+	}
+	b.groups = append(b.groups, &failureGroup{
+		file:     file,
+		line:     line,
+		messages: []string{message},
+	})
+}
+
+// Flush returns a summary of the failures added since the last call to Flush,
+// and the number of failures. The summary is empty if there were no failures.
+func (b *Buffer) Flush() (string, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	count, groups := b.count, b.groups
+	b.count, b.groups = 0, nil
+	if count == 0 {
+		return "", 0
+	}
+
+	out := new(strings.Builder)
+	fmt.Fprintf(out, "%s failed", plural(count, "assertion"))
+	if len(groups) > 1 {
+		fmt.Fprintf(out, " at %s", plural(len(groups), "location"))
+	}
+	out.WriteString(":")
+	for _, group := range groups {
+		fmt.Fprintf(out, "\n%s:%d", filepath.Base(group.file), group.line)
+		if len(group.messages) > 1 {
+			fmt.Fprintf(out, " (%s)", plural(len(group.messages), "failure"))
+		}
+		out.WriteString(":")
+		for _, message := range group.messages {
+			out.WriteString("\n" + indent(message, "    "))
+		}
+	}
+	return out.String(), count
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,154 @@
+package assert
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/internal/format"
+	"gotest.tools/v3/internal/overflow"
+	"gotest.tools/v3/internal/source"
+)
+
+// FormatEnvVar is the name of the environment variable which selects the
+// format of failure messages when no reporter is set with SetReporter.
+const FormatEnvVar = "GOTESTTOOLS_FAILURE_FORMAT"
+
+// Failure is a failed assertion.
+type Failure struct {
+	Message    string
+	Annotation string
+	File       string
+	Line       int
+}
+
+// Text returns the failure message in the format logged by ReportText.
+func (f Failure) Text() string {
+	return format.WithCustomMessage(failureMessage+f.Message, f.Annotation)
+}
+
+// ReportFunc logs a failed assertion.
+type ReportFunc func(t LogT, failure Failure)
+
+var reporter struct {
+	sync.RWMutex
+	report ReportFunc
+}
+
+// SetReporter sets the function used to log failures, and returns the
+// previous function. A nil report restores the default, which is selected by
+// GOTESTTOOLS_FAILURE_FORMAT.
+func SetReporter(report ReportFunc) ReportFunc {
+	reporter.Lock()
+	defer reporter.Unlock()
+	previous := reporter.report
+	reporter.report = report
+	return previous
+}
+
+func currentReporter() ReportFunc {
+	reporter.RLock()
+	defer reporter.RUnlock()
+	return reporter.report
+}
+
+// logFailure builds the Failure for a failed assertion and logs it with the
+// reporter.
+func logFailure(t LogT, message string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	failure := Failure{Message: message, Annotation: format.Message(msgAndArgs...)}
+	failure.File, failure.Line, _ = source.Caller()
+
+	// A Record buffers the failure for a Collector, which reports it later.
+	if record, ok := t.(*Record); ok {
+		record.Log(failure.Text())
+		return
+	}
+	if report := currentReporter(); report != nil {
+		report(t, failure)
+		return
+	}
+	switch value := os.Getenv(FormatEnvVar); value {
+	case "", "text":
+		ReportText(t, failure)
+	case "color":
+		ReportColor(t, failure)
+	case "json":
+		ReportJSON(t, failure)
+	default:
+		ReportText(t, failure)
+		t.Log(fmt.Sprintf("invalid %s: %q, expected text, color, or json", FormatEnvVar, value))
+	}
+}
+
+// ReportText logs the failure message, or the start of the message and the
+// path to a file with the full message when the message is larger than the
+// limit set by the GOTESTTOOLS_MAX_FAILURE_SIZE environment variable.
+func ReportText(t LogT, failure Failure) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	t.Log(overflow.Limit(t, failure.Text()))
+}
+
+// ReportColor is like ReportText, but highlights the lines of diffs in the
+// message with ANSI escape codes.
+func ReportColor(t LogT, failure Failure) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	t.Log(colorize(overflow.Limit(t, failure.Text())))
+}
+
+const (
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiReset = "\x1b[0m"
+)
+
+func colorize(msg string) string {
+	lines := strings.Split(msg, "\n")
+	for i, line := range lines {
+		var color string
+		switch {
+		case strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			color = ansiBold
+		case strings.HasPrefix(line, "@@"):
+			color = ansiCyan
+		case strings.HasPrefix(line, "-"):
+			color = ansiRed
+		case strings.HasPrefix(line, "+"):
+			color = ansiGreen
+		default:
+			continue
+		}
+		lines[i] = color + line + ansiReset
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ReportJSON logs the failure as a JSON object on a single line, with the
+// fields message, annotation, file, and line.
+func ReportJSON(t LogT, failure Failure) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	failure.Message = overflow.Limit(t, failure.Message)
+	out, err := json.Marshal(struct {
+		Message    string `json:"message"`
+		Annotation string `json:"annotation,omitempty"`
+		File       string `json:"file,omitempty"`
+		Line       int    `json:"line,omitempty"`
+	}(failure))
+	if err != nil {
+		ReportText(t, failure)
+		return
+	}
+	t.Log(string(out))
+}
@@ -6,7 +6,6 @@ import (
 	"go/ast"
 
 	"gotest.tools/v3/assert/cmp"
-	"gotest.tools/v3/internal/format"
 	"gotest.tools/v3/internal/source"
 )
 
@@ -57,7 +56,7 @@ func RunComparison(
 		message = fmt.Sprintf("comparison returned invalid Result type: %T", result)
 	}
 
-	t.Log(format.WithCustomMessage(failureMessage+message, msgAndArgs...))
+	logFailure(t, message, msgAndArgs...)
 	return false
 }
 
@@ -133,6 +132,33 @@ func ArgsFromComparisonCall(args []ast.Expr) []ast.Expr {
 	return nil
 }
 
+// ArgsAll selects all the args. Used when the caller does not accept a
+// testing.T, and the args to select are the args of the caller.
+func ArgsAll(args []ast.Expr) []ast.Expr {
+	return args
+}
+
+// ArgsFromReturnedComparison selects args from the CallExpression returned by
+// the function literal at position 1. Used when the caller has a testing.T as
+// the first argument, followed by a function which returns a cmp.Comparison.
+func ArgsFromReturnedComparison(args []ast.Expr) []ast.Expr {
+	if len(args) <= 1 {
+		return nil
+	}
+	funcLit, ok := args[1].(*ast.FuncLit)
+	if !ok || len(funcLit.Body.List) == 0 {
+		return nil
+	}
+	ret, ok := funcLit.Body.List[len(funcLit.Body.List)-1].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return nil
+	}
+	if callExpr, ok := ret.Results[0].(*ast.CallExpr); ok {
+		return callExpr.Args
+	}
+	return nil
+}
+
 // ArgsAtZeroIndex selects args from the CallExpression at position 1.
 // Used when the caller accepts a single cmp.Comparison argument.
 func ArgsAtZeroIndex(args []ast.Expr) []ast.Expr {
@@ -82,6 +82,15 @@ func NewMatcher(a, b []string) *SequenceMatcher {
 	return &m
 }
 
+// NewMatcherWithoutAutoJunk returns a new SequenceMatcher which does not treat
+// popular elements of b as junk. It is used for sequences with a small
+// alphabet, like the characters of a line, where most elements are popular.
+func NewMatcherWithoutAutoJunk(a, b []string) *SequenceMatcher {
+	m := SequenceMatcher{}
+	m.SetSeqs(a, b)
+	return &m
+}
+
 // SetSeqs sets two sequences to be compared.
 func (m *SequenceMatcher) SetSeqs(a, b []string) {
 	m.SetSeq1(a)
@@ -294,7 +303,13 @@ func (m *SequenceMatcher) GetMatchingBlocks() []Match {
 		return matched
 	}
 	matched := matchBlocks(0, len(m.a), 0, len(m.b), nil)
+	m.matchingBlocks = collapseMatches(matched, len(m.a), len(m.b))
+	return m.matchingBlocks
+}
 
+// collapseMatches joins adjacent equal blocks in matched, and terminates the
+// list with the dummy match (la, lb, 0).
+func collapseMatches(matched []Match, la, lb int) []Match {
 	// It's possible that we have adjacent equal blocks in the
 	// matching_blocks list now.
 	nonAdjacent := []Match{}
@@ -321,9 +336,7 @@ func (m *SequenceMatcher) GetMatchingBlocks() []Match {
 		nonAdjacent = append(nonAdjacent, Match{i1, j1, k1})
 	}
 
-	nonAdjacent = append(nonAdjacent, Match{len(m.a), len(m.b), 0})
-	m.matchingBlocks = nonAdjacent
-	return m.matchingBlocks
+	return append(nonAdjacent, Match{la, lb, 0})
 }
 
 // GetOpCodes returns a list of 5-tuples describing how to turn a into b.
@@ -345,8 +358,14 @@ func (m *SequenceMatcher) GetOpCodes() []OpCode {
 	if m.opCodes != nil {
 		return m.opCodes
 	}
+	m.opCodes = OpCodes(m.GetMatchingBlocks())
+	return m.opCodes
+}
+
+// OpCodes returns the list of OpCode which turn a into b from the list of
+// matching blocks, in the format returned by GetMatchingBlocks.
+func OpCodes(matching []Match) []OpCode {
 	i, j := 0, 0
-	matching := m.GetMatchingBlocks()
 	opCodes := make([]OpCode, 0, len(matching))
 	for _, m := range matching {
 		//  invariant:  we've pumped out correct diffs to change
@@ -373,8 +392,7 @@ func (m *SequenceMatcher) GetOpCodes() []OpCode {
 			opCodes = append(opCodes, OpCode{'e', ai, i, bj, j})
 		}
 	}
-	m.opCodes = opCodes
-	return m.opCodes
+	return opCodes
 }
 
 // GetGroupedOpCodes isolates change clusters by eliminating ranges with no changes.
@@ -382,10 +400,16 @@ func (m *SequenceMatcher) GetOpCodes() []OpCode {
 // Return a generator of groups with up to n lines of context.
 // Each group is in the same format as returned by GetOpCodes().
 func (m *SequenceMatcher) GetGroupedOpCodes(n int) [][]OpCode {
+	return GroupOpCodes(m.GetOpCodes(), n)
+}
+
+// GroupOpCodes isolates change clusters in codes by eliminating ranges with no
+// changes. See GetGroupedOpCodes.
+func GroupOpCodes(codes []OpCode, n int) [][]OpCode {
 	if n < 0 {
 		n = 3
 	}
-	codes := m.GetOpCodes()
+	codes = append([]OpCode(nil), codes...)
 	if len(codes) == 0 {
 		codes = []OpCode{{'e', 0, 1, 0, 1}}
 	}
@@ -0,0 +1,183 @@
+package difflib
+
+import "sort"
+
+// maxChainLength is the number of times a line may occur in a before the
+// histogram algorithm stops using it to match lines.
+const maxChainLength = 64
+
+// PatienceMatchingBlocks returns the matching blocks of a and b, in the format
+// returned by SequenceMatcher.GetMatchingBlocks, using the patience diff
+// algorithm. Lines which occur exactly once in both a and b are matched first,
+// and the ranges between those lines are matched recursively.
+func PatienceMatchingBlocks(a, b []string) []Match {
+	matched := patience(a, b, 0, len(a), 0, len(b), nil)
+	return collapseMatches(matched, len(a), len(b))
+}
+
+func patience(a, b []string, alo, ahi, blo, bhi int, matched []Match) []Match {
+	for alo < ahi && blo < bhi && a[alo] == b[blo] {
+		matched = append(matched, Match{alo, blo, 1})
+		alo, blo = alo+1, blo+1
+	}
+	var suffix []Match
+	for alo < ahi && blo < bhi && a[ahi-1] == b[bhi-1] {
+		ahi, bhi = ahi-1, bhi-1
+		suffix = append(suffix, Match{ahi, bhi, 1})
+	}
+
+	anchors := uniqueCommonLines(a, b, alo, ahi, blo, bhi)
+	if len(anchors) == 0 {
+		matched = fallbackMatches(a, b, alo, ahi, blo, bhi, matched)
+	} else {
+		i, j := alo, blo
+		for _, anchor := range anchors {
+			matched = patience(a, b, i, anchor.A, j, anchor.B, matched)
+			matched = append(matched, anchor)
+			i, j = anchor.A+1, anchor.B+1
+		}
+		matched = patience(a, b, i, ahi, j, bhi, matched)
+	}
+
+	for k := len(suffix) - 1; k >= 0; k-- {
+		matched = append(matched, suffix[k])
+	}
+	return matched
+}
+
+// uniqueCommonLines returns the longest sequence of lines which occur exactly
+// once in a[alo:ahi] and b[blo:bhi], and are in the same order in both.
+func uniqueCommonLines(a, b []string, alo, ahi, blo, bhi int) []Match {
+	type occurrence struct {
+		countA, countB int
+		indexA         int
+	}
+	lines := make(map[string]*occurrence)
+	for i := alo; i < ahi; i++ {
+		o, ok := lines[a[i]]
+		if !ok {
+			o = &occurrence{}
+			lines[a[i]] = o
+		}
+		o.countA++
+		o.indexA = i
+	}
+	for j := blo; j < bhi; j++ {
+		if o, ok := lines[b[j]]; ok {
+			o.countB++
+		}
+	}
+
+	var candidates []Match
+	for j := blo; j < bhi; j++ {
+		if o, ok := lines[b[j]]; ok && o.countA == 1 && o.countB == 1 {
+			candidates = append(candidates, Match{o.indexA, j, 1})
+		}
+	}
+
+	// Patience sorting finds the longest increasing subsequence of the
+	// positions in a, ordered by the position in b.
+	var tops []int
+	prev := make([]int, len(candidates))
+	for k, candidate := range candidates {
+		n := sort.Search(len(tops), func(p int) bool {
+			return candidates[tops[p]].A > candidate.A
+		})
+		prev[k] = -1
+		if n > 0 {
+			prev[k] = tops[n-1]
+		}
+		if n == len(tops) {
+			tops = append(tops, k)
+		} else {
+			tops[n] = k
+		}
+	}
+	if len(tops) == 0 {
+		return nil
+	}
+	result := make([]Match, len(tops))
+	for k, n := tops[len(tops)-1], len(tops)-1; n >= 0; k, n = prev[k], n-1 {
+		result[n] = candidates[k]
+	}
+	return result
+}
+
+// HistogramMatchingBlocks returns the matching blocks of a and b, in the
+// format returned by SequenceMatcher.GetMatchingBlocks, using the histogram
+// diff algorithm. Like the patience algorithm it matches rare lines first, but
+// it also matches lines which are not unique, preferring the blocks whose
+// lines occur the least often in a.
+func HistogramMatchingBlocks(a, b []string) []Match {
+	matched := histogram(a, b, 0, len(a), 0, len(b), nil)
+	return collapseMatches(matched, len(a), len(b))
+}
+
+func histogram(a, b []string, alo, ahi, blo, bhi int, matched []Match) []Match {
+	if alo >= ahi || blo >= bhi {
+		return matched
+	}
+	match, ok := lowOccurrenceMatch(a, b, alo, ahi, blo, bhi)
+	if !ok {
+		return fallbackMatches(a, b, alo, ahi, blo, bhi, matched)
+	}
+	matched = histogram(a, b, alo, match.A, blo, match.B, matched)
+	matched = append(matched, match)
+	return histogram(a, b, match.A+match.Size, ahi, match.B+match.Size, bhi, matched)
+}
+
+// lowOccurrenceMatch returns the longest block of matching lines with the
+// lowest number of occurrences in a[alo:ahi]. It returns false if every line
+// in b[blo:bhi] is missing from a, or occurs more than maxChainLength times.
+func lowOccurrenceMatch(a, b []string, alo, ahi, blo, bhi int) (Match, bool) {
+	positions := make(map[string][]int)
+	for i := alo; i < ahi; i++ {
+		positions[a[i]] = append(positions[a[i]], i)
+	}
+
+	best, bestCount := Match{}, maxChainLength+1
+	for j := blo; j < bhi; {
+		next := j + 1
+		occurrences := positions[b[j]]
+		if len(occurrences) == 0 || len(occurrences) > bestCount {
+			j = next
+			continue
+		}
+		for _, i := range occurrences {
+			si, sj := i, j
+			for si > alo && sj > blo && a[si-1] == b[sj-1] {
+				si, sj = si-1, sj-1
+			}
+			ei, ej := i+1, j+1
+			for ei < ahi && ej < bhi && a[ei] == b[ej] {
+				ei, ej = ei+1, ej+1
+			}
+
+			count := len(occurrences)
+			for k := si; k < ei; k++ {
+				count = min(count, len(positions[a[k]]))
+			}
+			size := ei - si
+			if count < bestCount || (count == bestCount && size > best.Size) {
+				best, bestCount = Match{si, sj, size}, count
+			}
+			next = max(next, ej)
+		}
+		j = next
+	}
+	return best, best.Size > 0
+}
+
+// fallbackMatches appends the matching blocks of a[alo:ahi] and b[blo:bhi]
+// found by a SequenceMatcher. It is used when there are no lines the patience
+// or histogram algorithms can use to split the ranges.
+func fallbackMatches(a, b []string, alo, ahi, blo, bhi int, matched []Match) []Match {
+	if alo >= ahi || blo >= bhi {
+		return matched
+	}
+	blocks := NewMatcher(a[alo:ahi], b[blo:bhi]).GetMatchingBlocks()
+	for _, block := range blocks[:len(blocks)-1] {
+		matched = append(matched, Match{block.A + alo, block.B + blo, block.Size})
+	}
+	return matched
+}
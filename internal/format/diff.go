@@ -2,9 +2,11 @@ package format
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
-	"unicode"
 
 	"gotest.tools/v3/internal/difflib"
 )
@@ -13,20 +15,116 @@ const (
 	contextLines = 2
 )
 
+// Environment variables which set the DiffOptions used by UnifiedDiff when
+// DiffConfig.Options is nil.
+const (
+	DiffAlgorithmEnvVar = "GOTESTTOOLS_DIFF_ALGORITHM"
+	DiffContextEnvVar   = "GOTESTTOOLS_DIFF_CONTEXT"
+	DiffInlineEnvVar    = "GOTESTTOOLS_DIFF_INLINE"
+)
+
+// DiffAlgorithm is the algorithm used to match the lines of a diff.
+type DiffAlgorithm string
+
+const (
+	// DiffAlgorithmDifflib matches the longest blocks of lines first. It is
+	// the default.
+	DiffAlgorithmDifflib DiffAlgorithm = "difflib"
+	// DiffAlgorithmPatience matches the lines which are unique in both values
+	// first, which keeps changes aligned with distinctive lines.
+	DiffAlgorithmPatience DiffAlgorithm = "patience"
+	// DiffAlgorithmHistogram is like DiffAlgorithmPatience, but also matches
+	// lines which are not unique, preferring the rarest lines.
+	DiffAlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
+// InlineMode is how the changes within a changed line are highlighted.
+type InlineMode string
+
+const (
+	// InlineNone does not highlight changes within a line. It is the default.
+	InlineNone InlineMode = "none"
+	// InlineWord highlights the words which changed.
+	InlineWord InlineMode = "word"
+	// InlineChar highlights the characters which changed.
+	InlineChar InlineMode = "char"
+)
+
+// DiffOptions change how UnifiedDiff matches and renders a diff.
+type DiffOptions struct {
+	Algorithm DiffAlgorithm
+	// ContextLines is the number of unchanged lines shown around each change.
+	ContextLines int
+	Inline       InlineMode
+}
+
+// DefaultDiffOptions returns the DiffOptions set by the environment variables
+// GOTESTTOOLS_DIFF_ALGORITHM, GOTESTTOOLS_DIFF_CONTEXT, and
+// GOTESTTOOLS_DIFF_INLINE. If a variable has an invalid value the default is
+// used for that option, and the returned error describes the invalid value.
+func DefaultDiffOptions() (DiffOptions, error) {
+	opts := DiffOptions{
+		Algorithm:    DiffAlgorithmDifflib,
+		ContextLines: contextLines,
+		Inline:       InlineNone,
+	}
+	var invalid []string
+	switch value := DiffAlgorithm(os.Getenv(DiffAlgorithmEnvVar)); value {
+	case "":
+	case DiffAlgorithmDifflib, DiffAlgorithmPatience, DiffAlgorithmHistogram:
+		opts.Algorithm = value
+	default:
+		invalid = append(invalid, fmt.Sprintf(
+			"invalid %s: %q, expected one of difflib, patience, histogram",
+			DiffAlgorithmEnvVar, value))
+	}
+	if value := os.Getenv(DiffContextEnvVar); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			invalid = append(invalid, fmt.Sprintf(
+				"invalid %s: %q, expected a number of lines", DiffContextEnvVar, value))
+		} else {
+			opts.ContextLines = n
+		}
+	}
+	switch value := InlineMode(os.Getenv(DiffInlineEnvVar)); value {
+	case "":
+	case InlineNone, InlineWord, InlineChar:
+		opts.Inline = value
+	default:
+		invalid = append(invalid, fmt.Sprintf(
+			"invalid %s: %q, expected one of none, word, char", DiffInlineEnvVar, value))
+	}
+	if len(invalid) > 0 {
+		return opts, errors.New(strings.Join(invalid, "\n"))
+	}
+	return opts, nil
+}
+
 // DiffConfig for a unified diff
 type DiffConfig struct {
 	A    string
 	B    string
 	From string
 	To   string
+	// Options used to create the diff. If Options is nil the options from
+	// DefaultDiffOptions are used, and any invalid environment variable is
+	// reported after the diff.
+	Options *DiffOptions
 }
 
 // UnifiedDiff is a modified version of difflib.WriteUnifiedDiff with better
 // support for showing the whitespace differences.
 func UnifiedDiff(conf DiffConfig) string {
+	opts := conf.Options
+	var optsErr error
+	if opts == nil {
+		defaults, err := DefaultDiffOptions()
+		opts, optsErr = &defaults, err
+	}
 	a := strings.SplitAfter(conf.A, "\n")
 	b := strings.SplitAfter(conf.B, "\n")
-	groups := difflib.NewMatcher(a, b).GetGroupedOpCodes(contextLines)
+	groups := difflib.GroupOpCodes(opCodes(opts.Algorithm, a, b), opts.ContextLines)
 	if len(groups) == 0 {
 		return ""
 	}
@@ -38,18 +136,24 @@ func UnifiedDiff(conf DiffConfig) string {
 	writeLine := func(prefix string, s string) {
 		buf.WriteString(prefix + s)
 	}
-	if hasWhitespaceDiffLines(groups, a, b) {
+	if hasInvisibleDiffLines(groups, a, b) {
 		writeLine = visibleWhitespaceLine(writeLine)
 	}
 	formatHeader(writeFormat, conf)
 	for _, group := range groups {
 		formatRangeLine(writeFormat, group)
 		for _, opCode := range group {
+			// With ContextLines 0 a group starts and ends with an empty range
+			// of equal lines.
+			if opCode.Tag == 'e' && opCode.I1 == opCode.I2 {
+				continue
+			}
 			in, out := a[opCode.I1:opCode.I2], b[opCode.J1:opCode.J2]
 			switch opCode.Tag {
 			case 'e':
 				formatLines(writeLine, " ", in)
 			case 'r':
+				in, out = highlightLines(in, out, opts.Inline)
 				formatLines(writeLine, "-", in)
 				formatLines(writeLine, "+", out)
 			case 'd':
@@ -59,65 +163,20 @@ func UnifiedDiff(conf DiffConfig) string {
 			}
 		}
 	}
-	return buf.String()
-}
-
-// hasWhitespaceDiffLines returns true if any diff groups is only different
-// because of whitespace characters.
-func hasWhitespaceDiffLines(groups [][]difflib.OpCode, a, b []string) bool {
-	for _, group := range groups {
-		in, out := new(bytes.Buffer), new(bytes.Buffer)
-		for _, opCode := range group {
-			if opCode.Tag == 'e' {
-				continue
-			}
-			for _, line := range a[opCode.I1:opCode.I2] {
-				in.WriteString(line)
-			}
-			for _, line := range b[opCode.J1:opCode.J2] {
-				out.WriteString(line)
-			}
-		}
-		if removeWhitespace(in.String()) == removeWhitespace(out.String()) {
-			return true
-		}
-	}
-	return false
-}
-
-func removeWhitespace(s string) string {
-	var result []rune
-	for _, r := range s {
-		if !unicode.IsSpace(r) {
-			result = append(result, r)
-		}
+	if optsErr != nil {
+		buf.WriteString(optsErr.Error() + "\n")
 	}
-	return string(result)
+	return buf.String()
 }
 
-func visibleWhitespaceLine(ws func(string, string)) func(string, string) {
-	mapToVisibleSpace := func(r rune) rune {
-		switch r {
-		case '\n':
-		case ' ':
-			return '·'
-		case '\t':
-			return '▷'
-		case '\v':
-			return '▽'
-		case '\r':
-			return '↵'
-		case '\f':
-			return '↓'
-		default:
-			if unicode.IsSpace(r) {
-				return '�'
-			}
-		}
-		return r
-	}
-	return func(prefix, s string) {
-		ws(prefix, strings.Map(mapToVisibleSpace, s))
+func opCodes(algorithm DiffAlgorithm, a, b []string) []difflib.OpCode {
+	switch algorithm {
+	case DiffAlgorithmPatience:
+		return difflib.OpCodes(difflib.PatienceMatchingBlocks(a, b))
+	case DiffAlgorithmHistogram:
+		return difflib.OpCodes(difflib.HistogramMatchingBlocks(a, b))
+	default:
+		return difflib.NewMatcher(a, b).GetOpCodes()
 	}
 }
 
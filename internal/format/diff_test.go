@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
 	"gotest.tools/v3/golden"
 	"gotest.tools/v3/internal/format"
 )
@@ -50,6 +51,18 @@ func TestUnifiedDiff(t *testing.T) {
 			b:        "  something\n\tsomething\n  \n",
 			expected: "whitespace-diff.golden",
 		},
+		{
+			name:     "invisible characters diff",
+			a:        "name: foo bar\nid: 1\nprefix\n",
+			b:        "name: foo\u00a0bar\nid: \ufeff1\npre\u200bfix\n",
+			expected: "invisible-diff.golden",
+		},
+		{
+			name:     "mixed tabs and spaces",
+			a:        "func() {\n\treturn 1\n}\n",
+			b:        "func() {\n  \treturn 2\n}\n",
+			expected: "mixed-indent-diff.golden",
+		},
 	}
 
 	for _, testcase := range testcases {
@@ -69,3 +82,152 @@ func TestUnifiedDiff(t *testing.T) {
 		})
 	}
 }
+
+func TestUnifiedDiffWithOptions(t *testing.T) {
+	moved := struct{ a, b string }{
+		a: "func a() {\n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n",
+		b: "func a() {\n\treturn 1\n}\n\nfunc c() {\n\treturn 3\n}\n\nfunc b() {\n\treturn 2\n}\n",
+	}
+	changed := struct{ a, b string }{
+		a: "first\nthe quick brown fox jumps over\nthe lazy dog\nlast\n",
+		b: "first\nthe quick red fox jumped over\nthe lazy dog\nlast\n",
+	}
+	var testcases = []struct {
+		name     string
+		a        string
+		b        string
+		opts     format.DiffOptions
+		expected string
+	}{
+		{
+			name:     "difflib",
+			a:        moved.a,
+			b:        moved.b,
+			opts:     format.DiffOptions{Algorithm: format.DiffAlgorithmDifflib, ContextLines: 1},
+			expected: "algorithm-difflib.golden",
+		},
+		{
+			name:     "patience",
+			a:        moved.a,
+			b:        moved.b,
+			opts:     format.DiffOptions{Algorithm: format.DiffAlgorithmPatience, ContextLines: 1},
+			expected: "algorithm-patience.golden",
+		},
+		{
+			name:     "histogram",
+			a:        moved.a,
+			b:        moved.b,
+			opts:     format.DiffOptions{Algorithm: format.DiffAlgorithmHistogram, ContextLines: 1},
+			expected: "algorithm-histogram.golden",
+		},
+		{
+			name:     "no context lines",
+			a:        changed.a,
+			b:        changed.b,
+			opts:     format.DiffOptions{Inline: format.InlineNone},
+			expected: "no-context-lines.golden",
+		},
+		{
+			name:     "inline words",
+			a:        changed.a,
+			b:        changed.b,
+			opts:     format.DiffOptions{Inline: format.InlineWord, ContextLines: 1},
+			expected: "inline-word.golden",
+		},
+		{
+			name:     "inline characters",
+			a:        changed.a,
+			b:        changed.b,
+			opts:     format.DiffOptions{Inline: format.InlineChar, ContextLines: 1},
+			expected: "inline-char.golden",
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			opts := testcase.opts
+			diff := format.UnifiedDiff(format.DiffConfig{
+				A:       testcase.a,
+				B:       testcase.b,
+				Options: &opts,
+			})
+			assert.Assert(t, golden.String(diff, testcase.expected))
+		})
+	}
+}
+
+func TestDefaultDiffOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		defer env.Patch(t, format.DiffAlgorithmEnvVar, "")()
+		defer env.Patch(t, format.DiffContextEnvVar, "")()
+		defer env.Patch(t, format.DiffInlineEnvVar, "")()
+
+		expected := format.DiffOptions{
+			Algorithm:    format.DiffAlgorithmDifflib,
+			ContextLines: 2,
+			Inline:       format.InlineNone,
+		}
+		opts, err := format.DefaultDiffOptions()
+		assert.NilError(t, err)
+		assert.Equal(t, opts, expected)
+	})
+
+	t.Run("from environment", func(t *testing.T) {
+		defer env.Patch(t, format.DiffAlgorithmEnvVar, "histogram")()
+		defer env.Patch(t, format.DiffContextEnvVar, "5")()
+		defer env.Patch(t, format.DiffInlineEnvVar, "word")()
+
+		expected := format.DiffOptions{
+			Algorithm:    format.DiffAlgorithmHistogram,
+			ContextLines: 5,
+			Inline:       format.InlineWord,
+		}
+		opts, err := format.DefaultDiffOptions()
+		assert.NilError(t, err)
+		assert.Equal(t, opts, expected)
+	})
+
+	t.Run("invalid values use the defaults", func(t *testing.T) {
+		defer env.Patch(t, format.DiffAlgorithmEnvVar, "myers")()
+		defer env.Patch(t, format.DiffContextEnvVar, "-1")()
+		defer env.Patch(t, format.DiffInlineEnvVar, "word")()
+
+		expected := format.DiffOptions{
+			Algorithm:    format.DiffAlgorithmDifflib,
+			ContextLines: 2,
+			Inline:       format.InlineWord,
+		}
+		opts, err := format.DefaultDiffOptions()
+		assert.Equal(t, opts, expected)
+		assert.Error(t, err, `invalid GOTESTTOOLS_DIFF_ALGORITHM: "myers", expected one of difflib, patience, histogram
+invalid GOTESTTOOLS_DIFF_CONTEXT: "-1", expected a number of lines`)
+	})
+
+	t.Run("invalid value is reported after the diff", func(t *testing.T) {
+		defer env.Patch(t, format.DiffContextEnvVar, "lots")()
+
+		diff := format.UnifiedDiff(format.DiffConfig{A: "a", B: "b"})
+		expected := "@@ -1 +1 @@\n-a\n+b\n" +
+			`invalid GOTESTTOOLS_DIFF_CONTEXT: "lots", expected a number of lines` + "\n"
+		assert.Equal(t, diff, expected)
+	})
+}
+
+func TestInlineDiff(t *testing.T) {
+	diff := `  struct{
+- 	Name: "the first name",
++ 	Name: "the last name",
+- 	ID:   1,
+  	Tags: nil,
+  }
+`
+	expected := `  struct{
+- 	Name: "the [-first-] name",
++ 	Name: "the {+last+} name",
+- 	ID:   1,
+  	Tags: nil,
+  }
+`
+	assert.Equal(t, format.InlineDiff(diff, format.InlineWord), expected)
+	assert.Equal(t, format.InlineDiff(diff, format.InlineNone), diff)
+}
@@ -0,0 +1,169 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gotest.tools/v3/internal/difflib"
+)
+
+// Markers which surround the removed and added parts of a changed line.
+const (
+	removedStart = "[-"
+	removedEnd   = "-]"
+	addedStart   = "{+"
+	addedEnd     = "+}"
+)
+
+// InlineDiff highlights the changes within lines of diff, a diff where
+// removed lines start with '-' and added lines start with '+', like the diffs
+// from go-cmp. Each line in a run of removed lines is compared to the
+// matching line in the run of added lines which follows it.
+func InlineDiff(diff string, mode InlineMode) string {
+	if mode == InlineNone || mode == "" {
+		return diff
+	}
+	lines := strings.SplitAfter(diff, "\n")
+	for i := 0; i < len(lines); {
+		removed := prefixedRun(lines[i:], "-")
+		added := prefixedRun(lines[i+removed:], "+")
+		switch {
+		case removed == 0 && added == 0:
+			i++
+			continue
+		case removed == 0 || added == 0:
+			i += removed + added
+			continue
+		}
+		in, out := lines[i:i+removed], lines[i+removed:i+removed+added]
+		hIn, hOut := highlightLines(trimPrefix(in), trimPrefix(out), mode)
+		for k := range hIn {
+			in[k] = in[k][:1] + hIn[k]
+		}
+		for k := range hOut {
+			out[k] = out[k][:1] + hOut[k]
+		}
+		i += removed + added
+	}
+	return strings.Join(lines, "")
+}
+
+func prefixedRun(lines []string, prefix string) int {
+	for i, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+func trimPrefix(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = line[1:]
+	}
+	return result
+}
+
+// highlightLines marks the changes between each line in in and the line at the
+// same index in out. Lines are only compared when in and out have the same
+// number of lines.
+func highlightLines(in, out []string, mode InlineMode) ([]string, []string) {
+	if mode == InlineNone || mode == "" || len(in) != len(out) {
+		return in, out
+	}
+	hIn, hOut := make([]string, len(in)), make([]string, len(out))
+	for i := range in {
+		hIn[i], hOut[i] = highlightLine(in[i], out[i], mode)
+	}
+	return hIn, hOut
+}
+
+// highlightLine surrounds the parts of a which were removed with [- and -],
+// and the parts of b which were added with {+ and +}. The lines are returned
+// unchanged when they have nothing in common.
+func highlightLine(a, b string, mode InlineMode) (string, string) {
+	a, aEnd := splitLineEnd(a)
+	b, bEnd := splitLineEnd(b)
+	ta, tb := tokenize(a, mode), tokenize(b, mode)
+	codes := difflib.NewMatcherWithoutAutoJunk(ta, tb).GetOpCodes()
+	if !hasCommonText(codes, ta) {
+		return a + aEnd, b + bEnd
+	}
+
+	outA, outB := new(strings.Builder), new(strings.Builder)
+	for _, code := range codes {
+		in := strings.Join(ta[code.I1:code.I2], "")
+		out := strings.Join(tb[code.J1:code.J2], "")
+		switch code.Tag {
+		case 'e':
+			outA.WriteString(in)
+			outB.WriteString(out)
+		case 'r':
+			outA.WriteString(removedStart + in + removedEnd)
+			outB.WriteString(addedStart + out + addedEnd)
+		case 'd':
+			outA.WriteString(removedStart + in + removedEnd)
+		case 'i':
+			outB.WriteString(addedStart + out + addedEnd)
+		}
+	}
+	return outA.String() + aEnd, outB.String() + bEnd
+}
+
+func splitLineEnd(line string) (string, string) {
+	if strings.HasSuffix(line, "\n") {
+		return line[:len(line)-1], "\n"
+	}
+	return line, ""
+}
+
+// hasCommonText returns true if the equal parts of the lines contain
+// something other than whitespace.
+func hasCommonText(codes []difflib.OpCode, tokens []string) bool {
+	for _, code := range codes {
+		if code.Tag != 'e' {
+			continue
+		}
+		for _, token := range tokens[code.I1:code.I2] {
+			if strings.TrimSpace(token) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tokenize splits s into characters, or into words, runs of whitespace, and
+// single punctuation characters.
+func tokenize(s string, mode InlineMode) []string {
+	var tokens []string
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if mode == InlineWord {
+			switch {
+			case isWordRune(r):
+				size = runLength(s, isWordRune)
+			case unicode.IsSpace(r):
+				size = runLength(s, unicode.IsSpace)
+			}
+		}
+		tokens = append(tokens, s[:size])
+		s = s[size:]
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func runLength(s string, in func(rune) bool) int {
+	for i, r := range s {
+		if !in(r) {
+			return i
+		}
+	}
+	return len(s)
+}
@@ -0,0 +1,124 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gotest.tools/v3/internal/difflib"
+)
+
+// invisibleNames are the markers used for invisible characters which do not
+// have a single character marker.
+var invisibleNames = map[rune]string{
+	'\u200b': "<ZWSP>",
+	'\u200c': "<ZWNJ>",
+	'\u200d': "<ZWJ>",
+	'\u2060': "<WJ>",
+	'\ufeff': "<BOM>",
+}
+
+// hasInvisibleDiffLines returns true if any diff group is only different
+// because of whitespace or invisible characters, or if any changed line
+// contains an invisible character which is easily mistaken for another, or
+// is indented with a mix of tabs and spaces.
+func hasInvisibleDiffLines(groups [][]difflib.OpCode, a, b []string) bool {
+	for _, group := range groups {
+		in, out := new(bytes.Buffer), new(bytes.Buffer)
+		for _, opCode := range group {
+			if opCode.Tag == 'e' {
+				continue
+			}
+			for _, line := range a[opCode.I1:opCode.I2] {
+				in.WriteString(line)
+			}
+			for _, line := range b[opCode.J1:opCode.J2] {
+				out.WriteString(line)
+			}
+		}
+		if removeInvisible(in.String()) == removeInvisible(out.String()) {
+			return true
+		}
+		if hasConfusingInvisible(in.String()) || hasConfusingInvisible(out.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffersOnlyByInvisible returns true if a and b are different, but are
+// equal once whitespace and invisible characters are removed.
+func DiffersOnlyByInvisible(a, b string) bool {
+	return a != b && removeInvisible(a) == removeInvisible(b)
+}
+
+func isInvisible(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.Cf, r)
+}
+
+func removeInvisible(s string) string {
+	var result []rune
+	for _, r := range s {
+		if !isInvisible(r) {
+			result = append(result, r)
+		}
+	}
+	return string(result)
+}
+
+// hasConfusingInvisible returns true if s contains a non-ASCII invisible
+// character, like a non-breaking space or a zero width space, or has a line
+// indented with both tabs and spaces.
+func hasConfusingInvisible(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII && isInvisible(r) {
+			return true
+		}
+	}
+	for _, line := range strings.Split(s, "\n") {
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if strings.Contains(indent, " ") && strings.Contains(indent, "\t") {
+			return true
+		}
+	}
+	return false
+}
+
+func visibleWhitespaceLine(ws func(string, string)) func(string, string) {
+	return func(prefix, s string) {
+		ws(prefix, visibleWhitespace(s))
+	}
+}
+
+func visibleWhitespace(s string) string {
+	buf := new(strings.Builder)
+	for _, r := range s {
+		switch r {
+		case '\n':
+			buf.WriteRune(r)
+		case ' ':
+			buf.WriteRune('·')
+		case '\t':
+			buf.WriteRune('▷')
+		case '\v':
+			buf.WriteRune('▽')
+		case '\r':
+			buf.WriteRune('↵')
+		case '\f':
+			buf.WriteRune('↓')
+		case '\u00a0':
+			buf.WriteRune('⍽')
+		default:
+			switch name, ok := invisibleNames[r]; {
+			case ok:
+				buf.WriteString(name)
+			case isInvisible(r):
+				fmt.Fprintf(buf, "<U+%04X>", r)
+			default:
+				buf.WriteRune(r)
+			}
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,586 @@
+/*
+Package jsonschema validates JSON documents against a JSON Schema.
+
+The validator supports the assertion keywords of JSON Schema draft 7 and
+2020-12, and references to locations in the same schema. It does not resolve
+references to other documents, and treats "format" as an annotation.
+*/
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a constraint of the schema which the document does not
+// satisfy.
+type Violation struct {
+	// Pointer is the JSON pointer to the value in the document.
+	Pointer string
+	// Keyword is the schema keyword which failed.
+	Keyword string
+	Message string
+}
+
+func (v Violation) String() string {
+	pointer := v.Pointer
+	if pointer == "" {
+		pointer = "(root)"
+	}
+	return pointer + ": " + v.Message
+}
+
+// Decode converts v into a generic JSON value. A string or []byte is parsed
+// as JSON, any other value is converted with json.Marshal.
+func Decode(v interface{}) (interface{}, error) {
+	var data []byte
+	switch typed := v.(type) {
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	case json.RawMessage:
+		data = typed
+	default:
+		var err error
+		data, err = json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Validate returns the violations of schema by doc. Both values must be
+// decoded JSON, see Decode. An error is returned if the schema is invalid.
+func Validate(schema, doc interface{}) ([]Violation, error) {
+	v := &validator{root: schema, patterns: map[string]*regexp.Regexp{}}
+	if err := v.validate(schema, doc, "", 0); err != nil {
+		return nil, err
+	}
+	return v.violations, nil
+}
+
+// maxDepth limits the nesting of references, to stop recursive schemas which
+// never consume the document.
+const maxDepth = 100
+
+type validator struct {
+	root       interface{}
+	patterns   map[string]*regexp.Regexp
+	violations []Violation
+}
+
+func (v *validator) fail(pointer, keyword, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Pointer: pointer,
+		Keyword: keyword,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// valid returns true if doc satisfies schema, without recording violations.
+func (v *validator) valid(schema, doc interface{}, pointer string, depth int) (bool, error) {
+	sub := &validator{root: v.root, patterns: v.patterns}
+	if err := sub.validate(schema, doc, pointer, depth); err != nil {
+		return false, err
+	}
+	return len(sub.violations) == 0, nil
+}
+
+func (v *validator) validate(schema, doc interface{}, pointer string, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("schema references are nested more than %d levels", maxDepth)
+	}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(pointer, "false", "no value is allowed")
+		}
+		return nil
+	case map[string]interface{}:
+		return v.validateObjectSchema(s, doc, pointer, depth)
+	default:
+		return fmt.Errorf("invalid schema at %s: expected an object or boolean, got %s", pointer, typeOf(schema))
+	}
+}
+
+func (v *validator) validateObjectSchema(s map[string]interface{}, doc interface{}, pointer string, depth int) error {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			return err
+		}
+		if err := v.validate(target, doc, pointer, depth+1); err != nil {
+			return err
+		}
+	}
+
+	checks := []func(map[string]interface{}, interface{}, string, int) error{
+		v.checkGeneric,
+		v.checkNumber,
+		v.checkString,
+		v.checkArray,
+		v.checkObject,
+		v.checkCombinators,
+	}
+	for _, check := range checks {
+		if err := check(s, doc, pointer, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the schema are supported", ref)
+	}
+	current := v.root
+	fragment := strings.TrimPrefix(ref, "#")
+	if fragment == "" {
+		return current, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only JSON pointers are supported", ref)
+	}
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			next, ok := typed[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			current = typed[index]
+		default:
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return current, nil
+}
+
+func (v *validator) checkGeneric(s map[string]interface{}, doc interface{}, pointer string, _ int) error {
+	if types, ok := s["type"]; ok {
+		allowed, err := stringList(types)
+		if err != nil {
+			return fmt.Errorf("invalid type at %s: %s", pointer, err)
+		}
+		if !hasType(doc, allowed) {
+			v.fail(pointer, "type", "expected %s, got %s", strings.Join(allowed, " or "), typeOf(doc))
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, value := range enum {
+			if equal(value, doc) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(pointer, "enum", "%s is not one of %s", formatValue(doc), formatValue(enum))
+		}
+	}
+	if value, ok := s["const"]; ok && !equal(value, doc) {
+		v.fail(pointer, "const", "expected %s, got %s", formatValue(value), formatValue(doc))
+	}
+	return nil
+}
+
+func (v *validator) checkNumber(s map[string]interface{}, doc interface{}, pointer string, _ int) error {
+	n, ok := doc.(float64)
+	if !ok {
+		return nil
+	}
+	if min, ok := s["minimum"].(float64); ok && n < min {
+		v.fail(pointer, "minimum", "%v is less than the minimum %v", n, min)
+	}
+	if max, ok := s["maximum"].(float64); ok && n > max {
+		v.fail(pointer, "maximum", "%v is greater than the maximum %v", n, max)
+	}
+	if min, ok := s["exclusiveMinimum"].(float64); ok && n <= min {
+		v.fail(pointer, "exclusiveMinimum", "%v is not greater than %v", n, min)
+	}
+	if max, ok := s["exclusiveMaximum"].(float64); ok && n >= max {
+		v.fail(pointer, "exclusiveMaximum", "%v is not less than %v", n, max)
+	}
+	if m, ok := s["multipleOf"].(float64); ok && m > 0 {
+		q := n / m
+		if math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(pointer, "multipleOf", "%v is not a multiple of %v", n, m)
+		}
+	}
+	return nil
+}
+
+func (v *validator) checkString(s map[string]interface{}, doc interface{}, pointer string, _ int) error {
+	str, ok := doc.(string)
+	if !ok {
+		return nil
+	}
+	length := utf8.RuneCountInString(str)
+	if min, ok := s["minLength"].(float64); ok && float64(length) < min {
+		v.fail(pointer, "minLength", "expected at least %v characters, got %d", min, length)
+	}
+	if max, ok := s["maxLength"].(float64); ok && float64(length) > max {
+		v.fail(pointer, "maxLength", "expected at most %v characters, got %d", max, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := v.compile(pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(str) {
+			v.fail(pointer, "pattern", "%q does not match the pattern %q", str, pattern)
+		}
+	}
+	return nil
+}
+
+func (v *validator) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %s", pattern, err)
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+func (v *validator) checkArray(s map[string]interface{}, doc interface{}, pointer string, depth int) error {
+	items, ok := doc.([]interface{})
+	if !ok {
+		return nil
+	}
+	if min, ok := s["minItems"].(float64); ok && float64(len(items)) < min {
+		v.fail(pointer, "minItems", "expected at least %v items, got %d", min, len(items))
+	}
+	if max, ok := s["maxItems"].(float64); ok && float64(len(items)) > max {
+		v.fail(pointer, "maxItems", "expected at most %v items, got %d", max, len(items))
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+	outer:
+		for i := range items {
+			for j := 0; j < i; j++ {
+				if equal(items[i], items[j]) {
+					v.fail(pointer, "uniqueItems", "items %d and %d are equal", j, i)
+					break outer
+				}
+			}
+		}
+	}
+
+	// prefixItems (2020-12) or an array of items (draft 7) apply to the items
+	// at the same index, and items (2020-12) or additionalItems (draft 7)
+	// apply to the rest.
+	prefix, rest := s["prefixItems"], s["items"]
+	if tuple, ok := rest.([]interface{}); ok {
+		prefix, rest = tuple, s["additionalItems"]
+	}
+	prefixSchemas, _ := prefix.([]interface{})
+	for i, item := range items {
+		schema := rest
+		if i < len(prefixSchemas) {
+			schema = prefixSchemas[i]
+		}
+		if schema == nil {
+			continue
+		}
+		if err := v.validate(schema, item, pointer+"/"+strconv.Itoa(i), depth); err != nil {
+			return err
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		count := 0
+		for i, item := range items {
+			ok, err := v.valid(contains, item, pointer+"/"+strconv.Itoa(i), depth)
+			if err != nil {
+				return err
+			}
+			if ok {
+				count++
+			}
+		}
+		min := 1.0
+		if value, ok := s["minContains"].(float64); ok {
+			min = value
+		}
+		if float64(count) < min {
+			v.fail(pointer, "contains", "expected at least %v items to match contains, got %d", min, count)
+		}
+		if max, ok := s["maxContains"].(float64); ok && float64(count) > max {
+			v.fail(pointer, "maxContains", "expected at most %v items to match contains, got %d", max, count)
+		}
+	}
+	return nil
+}
+
+func (v *validator) checkObject(s map[string]interface{}, doc interface{}, pointer string, depth int) error {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if min, ok := s["minProperties"].(float64); ok && float64(len(obj)) < min {
+		v.fail(pointer, "minProperties", "expected at least %v properties, got %d", min, len(obj))
+	}
+	if max, ok := s["maxProperties"].(float64); ok && float64(len(obj)) > max {
+		v.fail(pointer, "maxProperties", "expected at most %v properties, got %d", max, len(obj))
+	}
+	if required, ok := s["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := obj[name]; !ok {
+					v.fail(pointer, "required", "missing required property %q", name)
+				}
+			}
+		}
+	}
+	if err := v.checkDependencies(s, obj, pointer, depth); err != nil {
+		return err
+	}
+
+	keys := sortedKeys(obj)
+	properties, _ := s["properties"].(map[string]interface{})
+	patternProperties, _ := s["patternProperties"].(map[string]interface{})
+	patterns := sortedKeys(patternProperties)
+	for _, key := range keys {
+		child := pointer + "/" + escapePointer(key)
+		matched := false
+		if schema, ok := properties[key]; ok {
+			matched = true
+			if err := v.validate(schema, obj[key], child, depth); err != nil {
+				return err
+			}
+		}
+		for _, pattern := range patterns {
+			re, err := v.compile(pattern)
+			if err != nil {
+				return err
+			}
+			if !re.MatchString(key) {
+				continue
+			}
+			matched = true
+			if err := v.validate(patternProperties[pattern], obj[key], child, depth); err != nil {
+				return err
+			}
+		}
+		if additional, ok := s["additionalProperties"]; ok && !matched {
+			if additional == false {
+				v.fail(child, "additionalProperties", "property %q is not allowed", key)
+			} else if err := v.validate(additional, obj[key], child, depth); err != nil {
+				return err
+			}
+		}
+		if names, ok := s["propertyNames"]; ok {
+			valid, err := v.valid(names, key, child, depth)
+			if err != nil {
+				return err
+			}
+			if !valid {
+				v.fail(child, "propertyNames", "property name %q does not match propertyNames", key)
+			}
+		}
+	}
+	return nil
+}
+
+// checkDependencies checks dependentRequired and dependentSchemas (2020-12)
+// and dependencies (draft 7).
+func (v *validator) checkDependencies(s map[string]interface{}, obj map[string]interface{}, pointer string, depth int) error {
+	dependencies := map[string]interface{}{}
+	for _, keyword := range []string{"dependencies", "dependentRequired", "dependentSchemas"} {
+		if deps, ok := s[keyword].(map[string]interface{}); ok {
+			for key, value := range deps {
+				dependencies[key] = value
+			}
+		}
+	}
+	for _, key := range sortedKeys(dependencies) {
+		if _, ok := obj[key]; !ok {
+			continue
+		}
+		if names, ok := dependencies[key].([]interface{}); ok {
+			for _, name := range names {
+				if name, ok := name.(string); ok {
+					if _, ok := obj[name]; !ok {
+						v.fail(pointer, "dependentRequired", "property %q is required by property %q", name, key)
+					}
+				}
+			}
+			continue
+		}
+		if err := v.validate(dependencies[key], obj, pointer, depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *validator) checkCombinators(s map[string]interface{}, doc interface{}, pointer string, depth int) error {
+	if all, ok := s["allOf"].([]interface{}); ok {
+		for _, schema := range all {
+			if err := v.validate(schema, doc, pointer, depth); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		count, err := v.countValid(anyOf, doc, pointer, depth)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			v.fail(pointer, "anyOf", "does not match any of the %d schemas in anyOf", len(anyOf))
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		count, err := v.countValid(oneOf, doc, pointer, depth)
+		if err != nil {
+			return err
+		}
+		if count != 1 {
+			v.fail(pointer, "oneOf", "matches %d of the %d schemas in oneOf, expected exactly 1", count, len(oneOf))
+		}
+	}
+	if not, ok := s["not"]; ok {
+		valid, err := v.valid(not, doc, pointer, depth)
+		if err != nil {
+			return err
+		}
+		if valid {
+			v.fail(pointer, "not", "matches the schema in not")
+		}
+	}
+	if cond, ok := s["if"]; ok {
+		valid, err := v.valid(cond, doc, pointer, depth)
+		if err != nil {
+			return err
+		}
+		branch := "else"
+		if valid {
+			branch = "then"
+		}
+		if schema, ok := s[branch]; ok {
+			if err := v.validate(schema, doc, pointer, depth); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *validator) countValid(schemas []interface{}, doc interface{}, pointer string, depth int) (int, error) {
+	count := 0
+	for _, schema := range schemas {
+		valid, err := v.valid(schema, doc, pointer, depth)
+		if err != nil {
+			return 0, err
+		}
+		if valid {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func stringList(value interface{}) ([]string, error) {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}, nil
+	case []interface{}:
+		result := make([]string, 0, len(typed))
+		for _, item := range typed {
+			str, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected a string, got %s", typeOf(item))
+			}
+			result = append(result, str)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("expected a string or array, got %s", typeOf(value))
+	}
+}
+
+func hasType(doc interface{}, types []string) bool {
+	actual := typeOf(doc)
+	for _, expected := range types {
+		switch {
+		case expected == actual:
+			return true
+		case expected == "number" && actual == "integer":
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf returns the JSON Schema type of a decoded JSON value.
+func typeOf(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) && !math.IsInf(typed, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func equal(x, y interface{}) bool {
+	return reflect.DeepEqual(x, y)
+}
+
+func formatValue(value interface{}) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(out)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
@@ -0,0 +1,192 @@
+package jsonschema_test
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/internal/jsonschema"
+)
+
+func validate(t *testing.T, schema, doc string) []string {
+	t.Helper()
+	decodedSchema, err := jsonschema.Decode(schema)
+	assert.NilError(t, err)
+	decodedDoc, err := jsonschema.Decode(doc)
+	assert.NilError(t, err)
+	violations, err := jsonschema.Validate(decodedSchema, decodedDoc)
+	assert.NilError(t, err)
+	var result []string
+	for _, v := range violations {
+		result = append(result, v.Keyword+" "+v.String())
+	}
+	return result
+}
+
+func TestValidate(t *testing.T) {
+	testcases := []struct {
+		name     string
+		schema   string
+		doc      string
+		expected []string
+	}{
+		{name: "true schema", schema: `true`, doc: `1`},
+		{
+			name: "false schema", schema: `false`, doc: `1`,
+			expected: []string{"false (root): no value is allowed"},
+		},
+		{name: "type list", schema: `{"type": ["string", "null"]}`, doc: `null`},
+		{name: "integer is a number", schema: `{"type": "number"}`, doc: `3`},
+		{
+			name: "enum", schema: `{"enum": ["a", 1]}`, doc: `"b"`,
+			expected: []string{`enum (root): "b" is not one of ["a",1]`},
+		},
+		{
+			name: "const", schema: `{"const": {"a": 1}}`, doc: `{"a": 2}`,
+			expected: []string{`const (root): expected {"a":1}, got {"a":2}`},
+		},
+		{
+			name: "numbers", doc: `10`,
+			schema: `{"exclusiveMaximum": 10, "maximum": 5, "exclusiveMinimum": 10, "multipleOf": 3}`,
+			expected: []string{
+				"maximum (root): 10 is greater than the maximum 5",
+				"exclusiveMinimum (root): 10 is not greater than 10",
+				"exclusiveMaximum (root): 10 is not less than 10",
+				"multipleOf (root): 10 is not a multiple of 3",
+			},
+		},
+		{name: "multipleOf decimal", schema: `{"multipleOf": 0.01}`, doc: `1.23`},
+		{
+			name: "strings", schema: `{"maxLength": 2, "pattern": "^[a-z]+$"}`, doc: `"héllo"`,
+			expected: []string{
+				"maxLength (root): expected at most 2 characters, got 5",
+				`pattern (root): "héllo" does not match the pattern "^[a-z]+$"`,
+			},
+		},
+		{
+			name: "prefixItems", doc: `[1, "a", true]`,
+			schema:   `{"prefixItems": [{"type": "integer"}, {"type": "string"}], "items": false}`,
+			expected: []string{"false /2: no value is allowed"},
+		},
+		{
+			name: "draft 7 tuple items", doc: `[1, 2]`,
+			schema:   `{"items": [{"type": "integer"}], "additionalItems": {"type": "string"}}`,
+			expected: []string{"type /1: expected string, got integer"},
+		},
+		{
+			name: "contains", doc: `[1, 2, 3]`,
+			schema: `{"contains": {"minimum": 2}, "maxContains": 1, "minItems": 4}`,
+			expected: []string{
+				"minItems (root): expected at least 4 items, got 3",
+				"maxContains (root): expected at most 1 items to match contains, got 2",
+			},
+		},
+		{
+			name: "contains none", schema: `{"contains": {"type": "string"}}`, doc: `[1]`,
+			expected: []string{"contains (root): expected at least 1 items to match contains, got 0"},
+		},
+		{
+			name: "patternProperties", doc: `{"x-a": 1, "b": 2}`,
+			schema: `{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "string"}}`,
+			expected: []string{
+				"type /b: expected string, got integer",
+				"type /x-a: expected string, got integer",
+			},
+		},
+		{
+			name: "propertyNames", schema: `{"propertyNames": {"maxLength": 3}}`, doc: `{"abcd": 1}`,
+			expected: []string{`propertyNames /abcd: property name "abcd" does not match propertyNames`},
+		},
+		{
+			name: "pointer escaping", schema: `{"properties": {"a/b~c": {"type": "string"}}}`, doc: `{"a/b~c": 1}`,
+			expected: []string{"type /a~1b~0c: expected string, got integer"},
+		},
+		{
+			name: "dependentRequired", doc: `{"card": 1}`,
+			schema:   `{"dependentRequired": {"card": ["billing"]}, "minProperties": 1, "maxProperties": 3}`,
+			expected: []string{`dependentRequired (root): property "billing" is required by property "card"`},
+		},
+		{
+			name: "draft 7 dependencies schema", doc: `{"card": 1}`,
+			schema:   `{"dependencies": {"card": {"required": ["billing"]}}}`,
+			expected: []string{`required (root): missing required property "billing"`},
+		},
+		{
+			name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"minimum": 5}]}`, doc: `1`,
+			expected: []string{"anyOf (root): does not match any of the 2 schemas in anyOf"},
+		},
+		{
+			name: "oneOf", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`, doc: `1`,
+			expected: []string{"oneOf (root): matches 2 of the 2 schemas in oneOf, expected exactly 1"},
+		},
+		{
+			name: "allOf and not", schema: `{"allOf": [{"type": "integer"}], "not": {"const": 1}}`, doc: `1`,
+			expected: []string{"not (root): matches the schema in not"},
+		},
+		{
+			name: "if then else", doc: `{"country": "CA", "postal": "123"}`,
+			schema: `{
+				"if": {"properties": {"country": {"const": "US"}}},
+				"then": {"properties": {"postal": {"pattern": "^[0-9]{5}$"}}},
+				"else": {"properties": {"postal": {"minLength": 6}}}
+			}`,
+			expected: []string{"minLength /postal: expected at least 6 characters, got 3"},
+		},
+		{
+			name: "ref", doc: `{"billing": {"zip": 1}, "shipping": {}}`,
+			schema: `{
+				"$defs": {"address": {"type": "object", "required": ["zip"], "properties": {"zip": {"type": "string"}}}},
+				"properties": {"billing": {"$ref": "#/$defs/address"}, "shipping": {"$ref": "#/$defs/address"}}
+			}`,
+			expected: []string{
+				"type /billing/zip: expected string, got integer",
+				`required /shipping: missing required property "zip"`,
+			},
+		},
+		{
+			name: "recursive ref", doc: `{"children": [{"children": [{"name": 1}]}]}`,
+			schema: `{
+				"properties": {"name": {"type": "string"}, "children": {"type": "array", "items": {"$ref": "#"}}}
+			}`,
+			expected: []string{"type /children/0/children/0/name: expected string, got integer"},
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.DeepEqual(t, validate(t, tc.schema, tc.doc), tc.expected)
+		})
+	}
+}
+
+func TestValidate_InvalidSchema(t *testing.T) {
+	testcases := []struct {
+		schema   string
+		expected string
+	}{
+		{schema: `1`, expected: "invalid schema at : expected an object or boolean, got integer"},
+		{schema: `{"type": 1}`, expected: "invalid type at : expected a string or array, got integer"},
+		{schema: `{"pattern": "("}`, expected: `invalid pattern "("`},
+		{schema: `{"$ref": "other.json"}`, expected: `unsupported $ref "other.json"`},
+		{schema: `{"$ref": "#/definitions/x"}`, expected: `unresolved $ref "#/definitions/x"`},
+		{schema: `{"$ref": "#"}`, expected: "nested more than 100 levels"},
+	}
+	for _, tc := range testcases {
+		schema, err := jsonschema.Decode(tc.schema)
+		assert.NilError(t, err)
+		_, err = jsonschema.Validate(schema, "value")
+		assert.ErrorContains(t, err, tc.expected, tc.schema)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	value, err := jsonschema.Decode(struct {
+		Name string `json:"name"`
+	}{Name: "a"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, value, map[string]interface{}{"name": "a"})
+
+	_, err = jsonschema.Decode("{")
+	assert.Assert(t, err != nil)
+	assert.Assert(t, strings.Contains(err.Error(), "unexpected end"))
+}
@@ -0,0 +1,132 @@
+/*
+Package overflow limits the size of failure messages written to the test log.
+A message which is larger than the limit is written to a file in the artifacts
+directory of the test, and the log only includes the start of the message and
+the path to the file.
+*/
+package overflow // import "gotest.tools/v3/internal/overflow"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"unicode/utf8"
+
+	"gotest.tools/v3/internal/artifacts"
+	"gotest.tools/v3/internal/testname"
+)
+
+// MaxSizeEnvVar is the name of the environment variable which sets the
+// maximum size of a failure message, in bytes. A value of 0 disables the
+// limit.
+const MaxSizeEnvVar = "GOTESTTOOLS_MAX_FAILURE_SIZE"
+
+// DefaultMaxSize is the maximum size of a failure message, in bytes, when
+// MaxSizeEnvVar is not set.
+const DefaultMaxSize = 64 * 1024
+
+// maxFiles is the number of overflow files which may be written by one test.
+const maxFiles = 1000
+
+// Limit returns msg if it is smaller than the maximum size. Otherwise the full
+// message is written to a file, and Limit returns the start of the message
+// followed by the path to the file. The file is written to the artifacts
+// directory of the test if t has a Name method, otherwise it is written to a
+// temporary file.
+func Limit(t interface{}, msg string) string {
+	maxSize, err := maxSize()
+	switch {
+	case err != nil:
+		return msg + "\n" + err.Error()
+	case maxSize == 0 || len(msg) <= maxSize:
+		return msg
+	}
+
+	path, err := Write(t, "failure", msg)
+	truncated := truncate(msg, maxSize)
+	if err != nil {
+		return fmt.Sprintf("%s\n... (truncated %d of %d bytes, failed to write the full message: %s)",
+			truncated, len(msg)-len(truncated), len(msg), err)
+	}
+	return fmt.Sprintf("%s\n... (truncated %d of %d bytes, the full message is in %s)",
+		truncated, len(msg)-len(truncated), len(msg), path)
+}
+
+func maxSize() (int, error) {
+	return MaxSize(DefaultMaxSize)
+}
+
+// MaxSize returns the maximum size set by MaxSizeEnvVar, or fallback if the
+// variable is not set. If the value is invalid MaxSize returns fallback and an
+// error which describes the value.
+func MaxSize(fallback int) (int, error) {
+	value := os.Getenv(MaxSizeEnvVar)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fallback, fmt.Errorf("invalid %s: %q, expected a number of bytes", MaxSizeEnvVar, value)
+	}
+	return n, nil
+}
+
+// truncate returns the start of msg which is at most size bytes, without
+// splitting a UTF-8 encoded rune.
+func truncate(msg string, size int) string {
+	for size > 0 && !utf8.RuneStart(msg[size]) {
+		size--
+	}
+	return msg[:size]
+}
+
+// Write writes msg to a new file named after name, and returns the path of the
+// file. Like Limit, the file is written to the artifacts directory of the test
+// if t has a Name method, otherwise it is written to a temporary file.
+func Write(t interface{}, name string, msg string) (string, error) {
+	at, ok := t.(artifacts.TestingT)
+	if !ok {
+		f, err := ioutil.TempFile("", "gotesttools-"+name+"-*.txt")
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(msg); err != nil {
+			f.Close() //nolint: errcheck
+			return "", err
+		}
+		return f.Name(), f.Close()
+	}
+
+	dir, err := artifacts.Dir(at)
+	if err != nil {
+		return "", err
+	}
+	for i := 1; i <= maxFiles; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%s-%03d.txt", name, i))
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		switch {
+		case os.IsExist(err):
+			continue
+		case err != nil:
+			return "", err
+		}
+		if _, err := f.WriteString(msg); err != nil {
+			f.Close() //nolint: errcheck
+			return "", err
+		}
+		return reportedPath(at, path), f.Close()
+	}
+	return "", fmt.Errorf("too many %s files in %s", name, dir)
+}
+
+// reportedPath returns the path where the file will be after the artifacts of
+// the failed test are copied to the directory set by artifacts.RootEnvVar.
+func reportedPath(t artifacts.TestingT, path string) string {
+	root := os.Getenv(artifacts.RootEnvVar)
+	if root == "" {
+		return path
+	}
+	return filepath.Join(root, testname.ID(t), filepath.Base(path))
+}
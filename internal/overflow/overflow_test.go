@@ -0,0 +1,94 @@
+package overflow_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/internal/overflow"
+)
+
+type fakeT struct {
+	name     string
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string { return t.name }
+
+func (t *fakeT) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+
+func (t *fakeT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeT) runCleanups() {
+	for len(t.cleanups) > 0 {
+		f := t.cleanups[len(t.cleanups)-1]
+		t.cleanups = t.cleanups[:len(t.cleanups)-1]
+		f()
+	}
+}
+
+var pathPattern = regexp.MustCompile(`the full message is in (.+)\)$`)
+
+func TestLimitSmallMessage(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "20")()
+
+	assert.Equal(t, overflow.Limit(&fakeT{name: "TestSmall"}, "short message"), "short message")
+}
+
+func TestLimitWritesArtifact(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "10")()
+	ft := &fakeT{name: "TestLarge"}
+	defer ft.runCleanups()
+
+	msg := strings.Repeat("0123456789", 5)
+	actual := overflow.Limit(ft, msg)
+	assert.Assert(t, cmp.Regexp(
+		`^0123456789\n\.\.\. \(truncated 40 of 50 bytes, the full message is in .+failure-001.txt\)$`,
+		actual))
+
+	path := pathPattern.FindStringSubmatch(actual)[1]
+	assert.Assert(t, strings.Contains(filepath.Base(filepath.Dir(path)), "artifacts-TestLarge"))
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), msg)
+
+	second := pathPattern.FindStringSubmatch(overflow.Limit(ft, msg))[1]
+	assert.Equal(t, filepath.Base(second), "failure-002.txt")
+}
+
+func TestLimitWithoutName(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "10")()
+
+	msg := strings.Repeat("0123456789", 5)
+	actual := overflow.Limit(t.Log, msg)
+	path := pathPattern.FindStringSubmatch(actual)[1]
+	defer os.Remove(path)
+
+	content, err := ioutil.ReadFile(path)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), msg)
+}
+
+func TestLimitDisabled(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "0")()
+
+	msg := strings.Repeat("0123456789", 10000)
+	assert.Equal(t, overflow.Limit(&fakeT{name: "TestDisabled"}, msg), msg)
+}
+
+func TestLimitTruncatesAtRuneStart(t *testing.T) {
+	defer env.Patch(t, overflow.MaxSizeEnvVar, "3")()
+	ft := &fakeT{name: "TestRuneStart"}
+	defer ft.runCleanups()
+
+	actual := overflow.Limit(ft, "ab☃c")
+	assert.Assert(t, strings.HasPrefix(actual, "ab\n... (truncated 4 of 6 bytes"), actual)
+}
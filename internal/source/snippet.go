@@ -0,0 +1,141 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SnippetEnvVar is the name of the environment variable which enables source
+// snippets in failure messages. The value is the number of lines of context
+// to show before and after the line of the failed assertion.
+const SnippetEnvVar = "GOTESTTOOLS_SOURCE_SNIPPET"
+
+// CallerSnippet returns the source of the first caller outside of the
+// gotest.tools packages, with the lines around it, and a caret under the call
+// expression. It returns an empty string if snippets are not enabled by
+// GOTESTTOOLS_SOURCE_SNIPPET, or the source is not available.
+func CallerSnippet() (string, error) {
+	value := os.Getenv(SnippetEnvVar)
+	if value == "" {
+		return "", nil
+	}
+	contextLines, err := strconv.Atoi(value)
+	if err != nil || contextLines < 0 {
+		return "", fmt.Errorf("invalid %s: %q, expected a number of lines", SnippetEnvVar, value)
+	}
+
+	filename, line, ok := callerOutsideModule()
+	if !ok {
+		return "", nil
+	}
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		debug("failed to read source for snippet: %s", err)
+		return "", nil
+	}
+	return snippet(filename, content, line, contextLines), nil
+}
+
+// Caller returns the position of the first caller outside of the gotest.tools
+// packages, which is usually the position of the assertion in a test.
+func Caller() (string, int, bool) {
+	return callerOutsideModule()
+}
+
+// callerOutsideModule returns the position of the first frame in the call
+// stack which is not in a non-test file of a gotest.tools package.
+func callerOutsideModule() (string, int, bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		inModule := strings.HasPrefix(frame.Function, "gotest.tools/v3/") &&
+			!strings.HasSuffix(frame.File, "_test.go")
+		if !inModule && frame.File != "" {
+			return frame.File, frame.Line, true
+		}
+		if !more {
+			return "", 0, false
+		}
+	}
+}
+
+func snippet(filename string, content []byte, line, contextLines int) string {
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-contextLines, line+contextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "%s:%d:\n", filepath.Base(filename), line)
+	for n := first; n <= last; n++ {
+		marker := " "
+		if n == line {
+			marker = ">"
+		}
+		fmt.Fprintf(buf, "%s %*d | %s\n", marker, width, n, lines[n-1])
+		if n != line {
+			continue
+		}
+		if underline := callUnderline(filename, content, lines[n-1], line); underline != "" {
+			fmt.Fprintf(buf, "  %*s | %s\n", width, "", underline)
+		}
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// callUnderline returns a caret under the start of the call expression at
+// line, followed by ~ under the rest of the call on that line.
+func callUnderline(filename string, content []byte, text string, line int) string {
+	fileset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fileset, filename, content, parser.AllErrors)
+	if err != nil {
+		debug("failed to parse source for snippet: %s", err)
+		return ""
+	}
+	expr, err := getCallExpr(fileset, astFile, line)
+	if err != nil {
+		debug("failed to find call for snippet: %s", err)
+		return ""
+	}
+
+	start, end := fileset.Position(expr.Pos()), fileset.Position(expr.End())
+	if start.Line != line {
+		return ""
+	}
+	startCol, endCol := start.Column-1, end.Column-1
+	if end.Line != line || endCol > len(text) {
+		endCol = len(text)
+	}
+	if startCol >= endCol {
+		return ""
+	}
+
+	// Keep tabs from the indentation so that the caret lines up with the call.
+	indent := strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, text[:startCol])
+	length := utf8.RuneCountInString(text[startCol:endCol])
+	return indent + "^" + strings.Repeat("~", length-1)
+}
@@ -0,0 +1,65 @@
+package source
+
+import (
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	content := `package example
+
+func TestExample(t *testing.T) {
+	actual := compute()
+	assert.Equal(t, actual, 3)
+	assert.Assert(t, actual > 2,
+		"message")
+}
+`
+	var testcases = []struct {
+		name         string
+		line         int
+		contextLines int
+		expected     string
+	}{
+		{
+			name:         "with context",
+			line:         5,
+			contextLines: 1,
+			expected: `example_test.go:5:
+  4 | 	actual := compute()
+> 5 | 	assert.Equal(t, actual, 3)
+    | 	^~~~~~~~~~~~~~~~~~~~~~~~~~
+  6 | 	assert.Assert(t, actual > 2,`,
+		},
+		{
+			name: "no context",
+			line: 5,
+			expected: `example_test.go:5:
+> 5 | 	assert.Equal(t, actual, 3)
+    | 	^~~~~~~~~~~~~~~~~~~~~~~~~~`,
+		},
+		{
+			name: "multi-line call",
+			line: 6,
+			expected: `example_test.go:6:
+> 6 | 	assert.Assert(t, actual > 2,
+    | 	^~~~~~~~~~~~~~~~~~~~~~~~~~~~`,
+		},
+		{
+			name:         "context at start of file",
+			line:         1,
+			contextLines: 2,
+			expected: `example_test.go:1:
+> 1 | package example
+  2 | 
+  3 | func TestExample(t *testing.T) {`,
+		},
+	}
+	for _, testcase := range testcases {
+		t.Run(testcase.name, func(t *testing.T) {
+			actual := snippet("example_test.go", []byte(content), testcase.line, testcase.contextLines)
+			if actual != testcase.expected {
+				t.Fatalf("expected:\n%s\n\ngot:\n%s", testcase.expected, actual)
+			}
+		})
+	}
+}
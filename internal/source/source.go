@@ -76,6 +76,14 @@ func scanToLine(fileset *token.FileSet, node ast.Node, lineNum int) ast.Node {
 }
 
 func getCallExprArgs(fileset *token.FileSet, astFile ast.Node, line int) ([]ast.Expr, error) {
+	expr, err := getCallExpr(fileset, astFile, line)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Args, nil
+}
+
+func getCallExpr(fileset *token.FileSet, astFile ast.Node, line int) (*ast.CallExpr, error) {
 	node, err := getNodeAtLine(fileset, astFile, line)
 	switch {
 	case err != nil:
@@ -92,7 +100,7 @@ func getCallExprArgs(fileset *token.FileSet, astFile ast.Node, line int) ([]ast.
 		return nil, errors.New("failed to find call expression")
 	}
 	debug("callExpr: %s", debugFormatNode{visitor.expr})
-	return visitor.expr.Args, nil
+	return visitor.expr, nil
 }
 
 type callExprVisitor struct {
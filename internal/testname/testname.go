@@ -0,0 +1,60 @@
+/*
+Package testname implements gotest.tools/v3/testname. It is used by packages
+which can not import gotest.tools/v3/testname, because its tests import
+gotest.tools/v3/assert.
+*/
+package testname // import "gotest.tools/v3/internal/testname"
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxLength is the maximum length of the identifier returned by Sanitize.
+const MaxLength = 64
+
+// hashLength is the number of hex characters of the hash appended to names
+// which are truncated.
+const hashLength = 8
+
+// TestingT is the subset of testing.T used by ID and Unique.
+type TestingT interface {
+	Name() string
+}
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Sanitize returns an identifier for name which is safe to use as part of a
+// filename. See gotest.tools/v3/testname.Sanitize.
+func Sanitize(name string) string {
+	id := strings.NewReplacer("/", "-", `\`, "-").Replace(name)
+	id = unsafeChars.ReplaceAllString(id, "_")
+	if len(id) <= MaxLength {
+		return id
+	}
+	sum := sha256.Sum256([]byte(name))
+	return id[:MaxLength-hashLength-1] + "-" + hex.EncodeToString(sum[:])[:hashLength]
+}
+
+// ID returns the identifier for the name of the test.
+func ID(t TestingT) string {
+	return Sanitize(t.Name())
+}
+
+// Unique returns the identifier for the name of the test, followed by a short
+// random suffix.
+func Unique(t TestingT) string {
+	return ID(t) + "-" + randomSuffix()
+}
+
+func randomSuffix() string {
+	buf := make([]byte, 3)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %s", err))
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,108 @@
+package leak
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/internal/cleanup"
+)
+
+// IgnoreFile ignores open files whose description matches pattern, using the
+// syntax of path.Match. The description is the path of the file where the OS
+// allows it, for example "/tmp/data.db" or "socket:[1234] tcp
+// 127.0.0.1:8080->0.0.0.0:0" on Linux.
+func IgnoreFile(pattern string) Option {
+	return func(o *options) {
+		o.ignoreFiles = append(o.ignoreFiles, pattern)
+	}
+}
+
+// CheckFiles records the file descriptors (handles on Windows) which are open
+// when it is called, and returns a function which reports an error on t for
+// any other files that are still open after the timeout. On Linux the leaked
+// files are identified by their path, or by the addresses of a socket. On
+// other unix systems only the file descriptor is reported, and on Windows only
+// the number of leaked handles is reported.
+//
+// When used with Go 1.14+ the returned function is called automatically when
+// the test ends, unless the TEST_NOCLEANUP env var is set to true. Calling it
+// more than once only checks once.
+func CheckFiles(t TestingT, ops ...Option) func() {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	opts := options{timeout: 2 * time.Second}
+	for _, op := range ops {
+		op(&opts)
+	}
+	baseline, err := openFiles()
+	if err != nil {
+		t.Log("failed to list open files, skipping leak check: " + err.Error())
+		return func() {}
+	}
+
+	var once sync.Once
+	verify := func() {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		once.Do(func() {
+			var leaked []openFile
+			retry(opts.timeout, func() bool {
+				current, err := openFiles()
+				if err != nil {
+					leaked = []openFile{{name: "failed to list open files: " + err.Error()}}
+					return true
+				}
+				leaked = ignoreFiles(baseline.leakedSince(current), opts)
+				return len(leaked) == 0
+			})
+			if len(leaked) == 0 {
+				return
+			}
+			lines := make([]string, 0, len(leaked))
+			for _, file := range leaked {
+				lines = append(lines, file.String())
+			}
+			t.Errorf("found %d unexpected open files:\n  %s",
+				len(leaked), strings.Join(lines, "\n  "))
+		})
+	}
+	cleanup.Cleanup(t, verify)
+	return verify
+}
+
+// openFile is a file descriptor or handle, and a description of the file if
+// the OS provides one.
+type openFile struct {
+	name        string
+	description string
+}
+
+func (f openFile) String() string {
+	if f.description == "" {
+		return f.name
+	}
+	return f.name + ": " + f.description
+}
+
+func ignoreFiles(leaked []openFile, opts options) []openFile {
+	var result []openFile
+	for _, file := range leaked {
+		if !matchesAny(file.description, opts.ignoreFiles) {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+func matchesAny(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package leak
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func fdDir() string {
+	return "/proc/self/fd"
+}
+
+func describeFile(dir, name string, sockets map[string]string) (string, bool) {
+	target, err := os.Readlink(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+	if target == fmt.Sprintf("/proc/%d/fd", os.Getpid()) {
+		return "", false
+	}
+	if socket, ok := sockets[target]; ok {
+		return target + " " + socket, true
+	}
+	return target, true
+}
+
+// isRuntimeFile returns true for files the Go runtime opens lazily, such as the
+// network poller.
+func isRuntimeFile(description string) bool {
+	switch description {
+	case "anon_inode:[eventpoll]", "anon_inode:[eventfd]", "anon_inode:[pidfd]":
+		return true
+	}
+	return false
+}
+
+// socketDescriptions returns the addresses of the sockets of the process,
+// keyed by the link target of their file descriptors, such as "socket:[1234]".
+func socketDescriptions() map[string]string {
+	sockets := map[string]string{}
+	for _, network := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		readProcNet(network, func(fields []string) {
+			if len(fields) < 10 {
+				return
+			}
+			sockets["socket:["+fields[9]+"]"] = fmt.Sprintf("%s %s->%s",
+				network, procNetAddress(fields[1]), procNetAddress(fields[2]))
+		})
+	}
+	readProcNet("unix", func(fields []string) {
+		if len(fields) < 8 {
+			return
+		}
+		sockets["socket:["+fields[6]+"]"] = "unix " + fields[7]
+	})
+	return sockets
+}
+
+func readProcNet(network string, fn func(fields []string)) {
+	f, err := os.Open("/proc/self/net/" + network)
+	if err != nil {
+		return
+	}
+	defer f.Close() // nolint: errcheck
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // skip the header
+	for scanner.Scan() {
+		fn(strings.Fields(scanner.Text()))
+	}
+}
+
+// procNetAddress converts an address from /proc/net/tcp, like 0100007F:1F90,
+// to the usual format, like 127.0.0.1:8080.
+func procNetAddress(raw string) string {
+	parts := strings.SplitN(raw, ":", 2)
+	if len(parts) != 2 {
+		return raw
+	}
+	ip, err := hex.DecodeString(parts[0])
+	if err != nil || (len(ip) != net.IPv4len && len(ip) != net.IPv6len) {
+		return raw
+	}
+	// the address is stored as 32 bit words in host byte order
+	for i := 0; i < len(ip); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = ip[i+3], ip[i+2], ip[i+1], ip[i]
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return raw
+	}
+	return net.JoinHostPort(net.IP(ip).String(), strconv.FormatUint(port, 10))
+}
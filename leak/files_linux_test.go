@@ -0,0 +1,13 @@
+package leak
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProcNetAddress(t *testing.T) {
+	assert.Equal(t, procNetAddress("0100007F:1F90"), "127.0.0.1:8080")
+	assert.Equal(t, procNetAddress("00000000000000000000000001000000:0050"), "[::1]:80")
+	assert.Equal(t, procNetAddress("bogus"), "bogus")
+}
@@ -0,0 +1,29 @@
+//go:build !linux && !windows
+// +build !linux,!windows
+
+package leak
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func fdDir() string {
+	return "/dev/fd"
+}
+
+func describeFile(dir, name string, _ map[string]string) (string, bool) {
+	info, err := os.Lstat(filepath.Join(dir, name))
+	if err != nil {
+		return "", false
+	}
+	return info.Mode().String(), true
+}
+
+func isRuntimeFile(string) bool {
+	return false
+}
+
+func socketDescriptions() map[string]string {
+	return nil
+}
@@ -0,0 +1,77 @@
+package leak
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestCheckFilesReportsOpenFile(t *testing.T) {
+	ft := &fakeT{}
+	verify := CheckFiles(ft, WithTimeout(20*time.Millisecond))
+
+	f, err := ioutil.TempFile("", "leak-check")
+	assert.NilError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	verify()
+	assert.Assert(t, cmp.Contains(ft.failed, "found 1 unexpected open files:"))
+	if runtime.GOOS == "linux" {
+		assert.Assert(t, cmp.Contains(ft.failed, f.Name()))
+	}
+}
+
+func TestCheckFilesWaitsForFilesToClose(t *testing.T) {
+	ft := &fakeT{}
+	verify := CheckFiles(ft)
+
+	f, err := ioutil.TempFile("", "leak-check")
+	assert.NilError(t, err)
+	defer os.Remove(f.Name())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		f.Close()
+	}()
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestCheckFilesIgnoreFile(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("files are only identified by path on linux")
+	}
+	ft := &fakeT{}
+	verify := CheckFiles(ft, WithTimeout(20*time.Millisecond), IgnoreFile(filepath.Join(os.TempDir(), "leak-check*")))
+
+	f, err := ioutil.TempFile("", "leak-check")
+	assert.NilError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestCheckFilesIdentifiesSockets(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sockets are only identified on linux")
+	}
+	ft := &fakeT{}
+	verify := CheckFiles(ft, WithTimeout(20*time.Millisecond))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer listener.Close()
+
+	verify()
+	assert.Assert(t, cmp.Contains(ft.failed, "tcp "+listener.Addr().String()+"->"))
+}
@@ -0,0 +1,63 @@
+//go:build !windows
+// +build !windows
+
+package leak
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// fileSet maps open file descriptors to a description of the file.
+type fileSet map[int]string
+
+func (s fileSet) leakedSince(current fileSet) []openFile {
+	fds := make([]int, 0, len(current))
+	for fd, description := range current {
+		if previous, ok := s[fd]; ok && previous == description {
+			continue
+		}
+		if isRuntimeFile(description) {
+			continue
+		}
+		fds = append(fds, fd)
+	}
+	sort.Ints(fds)
+
+	leaked := make([]openFile, 0, len(fds))
+	for _, fd := range fds {
+		leaked = append(leaked, openFile{name: fmt.Sprintf("fd %d", fd), description: current[fd]})
+	}
+	return leaked
+}
+
+func openFiles() (fileSet, error) {
+	dir := fdDir()
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	_ = f.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	sockets := socketDescriptions()
+	files := fileSet{}
+	for _, name := range names {
+		fd, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		description, ok := describeFile(dir, name, sockets)
+		if !ok {
+			// the file was closed, or it was the directory being listed
+			continue
+		}
+		files[fd] = description
+	}
+	return files, nil
+}
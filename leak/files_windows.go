@@ -0,0 +1,37 @@
+package leak
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var procGetProcessHandleCount = syscall.NewLazyDLL("kernel32.dll").NewProc("GetProcessHandleCount")
+
+// fileSet is the number of handles open in the process. Windows does not
+// provide a way to list the handles without undocumented APIs.
+type fileSet uint32
+
+func (s fileSet) leakedSince(current fileSet) []openFile {
+	if current <= s {
+		return nil
+	}
+	leaked := make([]openFile, 0, current-s)
+	for i := s; i < current; i++ {
+		leaked = append(leaked, openFile{name: fmt.Sprintf("handle %d of %d", i-s+1, current-s)})
+	}
+	return leaked
+}
+
+func openFiles() (fileSet, error) {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return 0, err
+	}
+	var count uint32
+	r, _, err := procGetProcessHandleCount.Call(uintptr(process), uintptr(unsafe.Pointer(&count)))
+	if r == 0 {
+		return 0, err
+	}
+	return fileSet(count), nil
+}
@@ -0,0 +1,171 @@
+/*
+Package leak checks that a test does not leave goroutines running, or files
+open, after it ends.
+*/
+package leak // import "gotest.tools/v3/leak"
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/internal/cleanup"
+)
+
+// TestingT is the subset of testing.T used by Check.
+type TestingT interface {
+	Log(args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+type options struct {
+	timeout     time.Duration
+	ignore      []func(goroutine) bool
+	ignoreFiles []string
+}
+
+// Option changes the behaviour of Check and CheckFiles.
+type Option func(*options)
+
+// WithTimeout sets how long Check waits for goroutines to exit before
+// reporting them as leaked, or CheckFiles waits for files to be closed. The
+// default is 2 seconds.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// IgnoreTopFunction ignores goroutines which are currently running the
+// function name, for example "internal/poll.runtime_pollWait". The name is the
+// package path followed by the function name, as it appears in a stack trace,
+// without any arguments.
+func IgnoreTopFunction(name string) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore, func(g goroutine) bool {
+			return g.topFunction() == name
+		})
+	}
+}
+
+// IgnoreFunction ignores goroutines which have the function name anywhere in
+// their stack. This is useful for known background workers, which may be
+// blocked in a different function every time.
+func IgnoreFunction(name string) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore, func(g goroutine) bool {
+			return g.hasFunction(name)
+		})
+	}
+}
+
+// Check records the goroutines which are running when it is called, and
+// returns a function which reports an error on t for any other goroutines that
+// are still running after the timeout. Goroutines which are part of the
+// testing package, such as other tests running in parallel, are ignored.
+//
+// When used with Go 1.14+ the returned function is called automatically when
+// the test ends, unless the TEST_NOCLEANUP env var is set to true. Calling it
+// more than once only checks once.
+func Check(t TestingT, ops ...Option) func() {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	opts := options{timeout: 2 * time.Second}
+	for _, op := range ops {
+		op(&opts)
+	}
+	baseline := map[int]bool{}
+	for _, g := range goroutines() {
+		baseline[g.id] = true
+	}
+
+	var once sync.Once
+	verify := func() {
+		if ht, ok := t.(helperT); ok {
+			ht.Helper()
+		}
+		once.Do(func() {
+			var leaked []goroutine
+			retry(opts.timeout, func() bool {
+				leaked = findLeaks(baseline, opts)
+				return len(leaked) == 0
+			})
+			if len(leaked) == 0 {
+				return
+			}
+			stacks := make([]string, 0, len(leaked))
+			for _, g := range leaked {
+				stacks = append(stacks, g.stack)
+			}
+			t.Errorf("found %d unexpected goroutines:\n\n%s",
+				len(leaked), strings.Join(stacks, "\n\n"))
+		})
+	}
+	cleanup.Cleanup(t, verify)
+	return verify
+}
+
+// retry calls check until it returns true, or until the timeout expires.
+func retry(timeout time.Duration, check func() bool) {
+	deadline := time.Now().Add(timeout)
+	delay := time.Millisecond
+	for !check() && time.Now().Before(deadline) {
+		time.Sleep(delay)
+		if delay < 100*time.Millisecond {
+			delay *= 2
+		}
+	}
+}
+
+func findLeaks(baseline map[int]bool, opts options) []goroutine {
+	all := goroutines()
+	var leaked []goroutine
+	// the first goroutine is always the current one
+	for _, g := range all[1:] {
+		if baseline[g.id] || isTestingGoroutine(g) || ignored(g, opts) {
+			continue
+		}
+		leaked = append(leaked, g)
+	}
+	return leaked
+}
+
+func ignored(g goroutine, opts options) bool {
+	for _, ignore := range opts.ignore {
+		if ignore(g) {
+			return true
+		}
+	}
+	return false
+}
+
+var testingFunctions = []string{
+	"testing.tRunner",
+	"testing.(*T).Run",
+	"testing.(*M).Run",
+	"testing.runTests",
+	"testing.runFuzzing",
+	"testing.runFuzzTests",
+	"os/signal.signal_recv",
+	"os/signal.loop",
+}
+
+func isTestingGoroutine(g goroutine) bool {
+	for _, name := range testingFunctions {
+		if g.hasFunction(name) {
+			return true
+		}
+	}
+	// goroutines which only run runtime functions belong to the runtime
+	for _, function := range g.functions {
+		if !strings.HasPrefix(function, "runtime.") {
+			return false
+		}
+	}
+	return true
+}
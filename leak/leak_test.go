@@ -0,0 +1,115 @@
+package leak
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+type fakeT struct {
+	failed string
+}
+
+func (t *fakeT) Log(...interface{}) {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func blockedWorker(stop chan struct{}) {
+	<-stop
+}
+
+func TestCheckReportsLeakedGoroutine(t *testing.T) {
+	ft := &fakeT{}
+	verify := Check(ft, WithTimeout(20*time.Millisecond))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go blockedWorker(stop)
+
+	verify()
+	assert.Assert(t, cmp.Contains(ft.failed, "found 1 unexpected goroutines:"))
+	assert.Assert(t, cmp.Contains(ft.failed, "gotest.tools/v3/leak.blockedWorker"))
+}
+
+func TestCheckWaitsForGoroutinesToExit(t *testing.T) {
+	ft := &fakeT{}
+	verify := Check(ft)
+
+	stop := make(chan struct{})
+	go blockedWorker(stop)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(stop)
+	}()
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestCheckIgnoresGoroutines(t *testing.T) {
+	ft := &fakeT{}
+	verify := Check(ft,
+		WithTimeout(20*time.Millisecond),
+		IgnoreTopFunction("gotest.tools/v3/leak.blockedWorker"))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go blockedWorker(stop)
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestCheckIgnoresGoroutinesWithFunction(t *testing.T) {
+	ft := &fakeT{}
+	verify := Check(ft,
+		WithTimeout(20*time.Millisecond),
+		IgnoreFunction("gotest.tools/v3/leak.startWorker.func1"))
+
+	stop := make(chan struct{})
+	defer close(stop)
+	startWorker(stop)
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func startWorker(stop chan struct{}) {
+	go func() {
+		blockedWorker(stop)
+	}()
+}
+
+func TestCheckIgnoresParallelTests(t *testing.T) {
+	ft := &fakeT{}
+	verify := Check(ft, WithTimeout(20*time.Millisecond))
+
+	t.Run("parallel", func(t *testing.T) {
+		t.Parallel()
+		time.Sleep(50 * time.Millisecond)
+	})
+
+	verify()
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestParseStack(t *testing.T) {
+	stack := `goroutine 7 [chan receive]:
+example.com/pkg.worker(0xc000012345)
+	/path/to/pkg/worker.go:12 +0x25
+example.com/pkg.(*Pool).run(...)
+	/path/to/pkg/pool.go:30
+created by example.com/pkg.Start
+	/path/to/pkg/worker.go:8 +0x3f`
+
+	g, ok := parseStack(stack)
+	assert.Assert(t, ok)
+	assert.Equal(t, g.id, 7)
+	assert.DeepEqual(t, g.functions, []string{"example.com/pkg.worker", "example.com/pkg.(*Pool).run"})
+	assert.Equal(t, g.topFunction(), "example.com/pkg.worker")
+}
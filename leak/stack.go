@@ -0,0 +1,82 @@
+package leak
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// goroutine is one goroutine parsed from the output of runtime.Stack.
+type goroutine struct {
+	id        int
+	stack     string
+	functions []string
+}
+
+func (g goroutine) topFunction() string {
+	if len(g.functions) == 0 {
+		return ""
+	}
+	return g.functions[0]
+}
+
+func (g goroutine) hasFunction(name string) bool {
+	for _, function := range g.functions {
+		if function == name {
+			return true
+		}
+	}
+	return false
+}
+
+// goroutines returns all running goroutines, starting with the current one.
+func goroutines() []goroutine {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return parseStacks(string(buf[:n]))
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+func parseStacks(stacks string) []goroutine {
+	var result []goroutine
+	for _, stack := range strings.Split(strings.TrimSpace(stacks), "\n\n") {
+		if g, ok := parseStack(stack); ok {
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// parseStack parses one goroutine from a stack dump, which looks like:
+//
+//	goroutine 7 [chan receive]:
+//	example.com/pkg.worker(0xc000012345)
+//		/path/to/pkg/worker.go:12 +0x25
+//	created by example.com/pkg.Start
+//		/path/to/pkg/worker.go:8 +0x3f
+func parseStack(stack string) (goroutine, bool) {
+	lines := strings.Split(stack, "\n")
+	g := goroutine{stack: stack}
+	if _, err := fmt.Sscanf(lines[0], "goroutine %d ", &g.id); err != nil {
+		return g, false
+	}
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "created by ") {
+			continue
+		}
+		g.functions = append(g.functions, functionName(line))
+	}
+	return g, true
+}
+
+// functionName removes the arguments from a function line in a stack trace.
+func functionName(line string) string {
+	if i := strings.LastIndex(line, "("); i > 0 {
+		return line[:i]
+	}
+	return line
+}
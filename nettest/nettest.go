@@ -0,0 +1,224 @@
+/*
+Package nettest provides helpers for tests which use the network: free ports,
+loopback listeners, and waiting for a server to listen on an address.
+*/
+package nettest // import "gotest.tools/v3/nettest"
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/internal/cleanup"
+	"gotest.tools/v3/poll"
+)
+
+type helperT interface {
+	Helper()
+}
+
+var allocated = struct {
+	sync.Mutex
+	ports map[string]bool
+}{ports: map[string]bool{}}
+
+// GetFreePort returns a free TCP port on the loopback interface. A port is
+// never returned more than once by the same process, so tests which run in
+// parallel do not receive the same port.
+//
+// The port is free when GetFreePort returns, but nothing prevents another
+// process from using it before the code under test binds to it. Use
+// ReservePort to hold the port until it is used.
+func GetFreePort(t assert.TestingT) int {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	port, err := freePort("tcp")
+	assert.NilError(t, err, "failed to allocate a free tcp port")
+	return port
+}
+
+// GetFreeUDPPort returns a free UDP port on the loopback interface. See
+// GetFreePort.
+func GetFreeUDPPort(t assert.TestingT) int {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	port, err := freePort("udp")
+	assert.NilError(t, err, "failed to allocate a free udp port")
+	return port
+}
+
+func freePort(network string) (int, error) {
+	allocated.Lock()
+	defer allocated.Unlock()
+
+	for {
+		port, err := listenOnFreePort(network)
+		if err != nil {
+			return 0, err
+		}
+		key := network + "/" + strconv.Itoa(port)
+		if !allocated.ports[key] {
+			allocated.ports[key] = true
+			return port, nil
+		}
+	}
+}
+
+func listenOnFreePort(network string) (int, error) {
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return 0, err
+		}
+		defer conn.Close() //nolint: errcheck
+		return conn.LocalAddr().(*net.UDPAddr).Port, nil
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close() //nolint: errcheck
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Reservation holds a TCP port open until it is released, so that no other
+// process can bind to it.
+type Reservation struct {
+	listener net.Listener
+	port     int
+	once     sync.Once
+}
+
+// ReservePort listens on a free TCP port on the loopback interface, and
+// keeps listening until the Reservation is released. Release the port just
+// before the code under test binds to it, or pass Reservation.Listener to code
+// which accepts a net.Listener, to avoid a race with other processes.
+//
+// The port is released when the test ends.
+func ReservePort(t assert.TestingT) *Reservation {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	allocated.Lock()
+	defer allocated.Unlock()
+
+	for {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NilError(t, err, "failed to reserve a free tcp port")
+		port := listener.Addr().(*net.TCPAddr).Port
+		key := "tcp/" + strconv.Itoa(port)
+		if allocated.ports[key] {
+			listener.Close() //nolint: errcheck
+			continue
+		}
+		allocated.ports[key] = true
+		r := &Reservation{listener: listener, port: port}
+		cleanup.Cleanup(t, r.Release)
+		return r
+	}
+}
+
+// Port returns the reserved port.
+func (r *Reservation) Port() int {
+	return r.port
+}
+
+// Addr returns the reserved address, in the form 127.0.0.1:port.
+func (r *Reservation) Addr() string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(r.port))
+}
+
+// Listener returns the listener which holds the port. The caller may accept
+// connections from the listener, and may close it.
+func (r *Reservation) Listener() net.Listener {
+	return r.listener
+}
+
+// Release closes the listener so that the port can be used. Release may be
+// called more than once.
+func (r *Reservation) Release() {
+	r.once.Do(func() {
+		r.listener.Close() //nolint: errcheck
+	})
+}
+
+// NewTCPListener returns a listener on a free TCP port on the loopback
+// interface. The listener is closed when the test ends.
+func NewTCPListener(t assert.TestingT) net.Listener {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		listener.Close() //nolint: errcheck
+	})
+	return listener
+}
+
+// NewUDPConn returns a packet connection on a free UDP port on the loopback
+// interface. The connection is closed when the test ends.
+func NewUDPConn(t assert.TestingT) net.PacketConn {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		conn.Close() //nolint: errcheck
+	})
+	return conn
+}
+
+// NewUnixListener returns a listener on a unix socket in a new temporary
+// directory. The listener is closed, and the directory removed, when the test
+// ends.
+func NewUnixListener(t assert.TestingT) net.Listener {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	// A short directory name is used because the length of the path of a
+	// unix socket is limited to about 100 bytes.
+	dir, err := ioutil.TempDir("", "nettest")
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		os.RemoveAll(dir) //nolint: errcheck
+	})
+
+	listener, err := net.Listen("unix", filepath.Join(dir, "sock"))
+	assert.NilError(t, err)
+	cleanup.Cleanup(t, func() {
+		listener.Close() //nolint: errcheck
+	})
+	return listener
+}
+
+// Listening returns a poll.Check which succeeds once a connection can be
+// opened to address on the named network. See net.Dial for a description of
+// network and address. Unlike poll.Connection the connection is closed.
+func Listening(network, address string) poll.Check {
+	return func(t poll.LogT) poll.Result {
+		conn, err := net.DialTimeout(network, address, time.Second)
+		if err != nil {
+			return poll.Continue("%s://%s is not listening: %s", network, address, err)
+		}
+		conn.Close() //nolint: errcheck
+		return poll.Success()
+	}
+}
+
+// WaitForListener waits until a TCP connection can be opened to addr. The
+// test fails if addr is not listening before the timeout.
+func WaitForListener(t poll.TestingT, addr string, timeout time.Duration) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	poll.WaitOn(t, Listening("tcp", addr), poll.WithTimeout(timeout))
+}
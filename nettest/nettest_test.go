@@ -0,0 +1,93 @@
+package nettest
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestGetFreePort(t *testing.T) {
+	seen := map[int]bool{}
+	for i := 0; i < 10; i++ {
+		port := GetFreePort(t)
+		assert.Assert(t, !seen[port], "port %d returned twice", port)
+		seen[port] = true
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(GetFreePort(t))))
+	assert.NilError(t, err)
+	assert.NilError(t, listener.Close())
+}
+
+func TestGetFreeUDPPort(t *testing.T) {
+	port := GetFreeUDPPort(t)
+	conn, err := net.ListenPacket("udp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	assert.NilError(t, err)
+	assert.NilError(t, conn.Close())
+}
+
+func TestReservePort(t *testing.T) {
+	r := ReservePort(t)
+	assert.Equal(t, r.Addr(), "127.0.0.1:"+strconv.Itoa(r.Port()))
+
+	_, err := net.Listen("tcp", r.Addr())
+	assert.Assert(t, err != nil, "expected the port to be in use")
+
+	r.Release()
+	r.Release()
+	listener, err := net.Listen("tcp", r.Addr())
+	assert.NilError(t, err)
+	assert.NilError(t, listener.Close())
+}
+
+func TestReservePort_ReleasedAtCleanup(t *testing.T) {
+	var addr string
+	t.Run("reserve", func(t *testing.T) {
+		addr = ReservePort(t).Addr()
+	})
+	listener, err := net.Listen("tcp", addr)
+	assert.NilError(t, err)
+	assert.NilError(t, listener.Close())
+}
+
+func TestNewTCPListener(t *testing.T) {
+	var listener net.Listener
+	t.Run("listen", func(t *testing.T) {
+		listener = NewTCPListener(t)
+		WaitForListener(t, listener.Addr().String(), time.Second)
+	})
+	_, err := listener.Accept()
+	assert.Assert(t, err != nil, "expected the listener to be closed")
+}
+
+func TestNewUDPConn(t *testing.T) {
+	conn := NewUDPConn(t)
+	assert.Equal(t, conn.LocalAddr().(*net.UDPAddr).IP.String(), "127.0.0.1")
+}
+
+func TestNewUnixListener(t *testing.T) {
+	listener := NewUnixListener(t)
+	conn, err := net.Dial("unix", listener.Addr().String())
+	assert.NilError(t, err)
+	assert.NilError(t, conn.Close())
+}
+
+func TestListening(t *testing.T) {
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(GetFreePort(t)))
+
+	result := Listening("tcp", addr)(t)
+	assert.Assert(t, !result.Done())
+	assert.Assert(t, is.Contains(result.Message(), "tcp://"+addr+" is not listening"))
+
+	listener, err := net.Listen("tcp", addr)
+	assert.NilError(t, err)
+	defer listener.Close() //nolint: errcheck
+
+	result = Listening("tcp", addr)(t)
+	assert.Assert(t, result.Done())
+	assert.NilError(t, result.Error())
+}
@@ -0,0 +1,62 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestContinueWrapsError(t *testing.T) {
+	r := Continue("not ready: %w", os.ErrNotExist)
+	assert.Equal(t, r.Message(), "not ready: file does not exist")
+	assert.Assert(t, errors.Is(causeOf(r), os.ErrNotExist))
+
+	r = Continue("no cause %d", 1)
+	assert.Equal(t, r.Message(), "no cause 1")
+	assert.NilError(t, causeOf(r))
+}
+
+func TestWaitTimeoutPreservesCause(t *testing.T) {
+	check := func(t LogT) Result {
+		return Continue("config missing: %w", &os.PathError{Op: "open", Path: "config", Err: os.ErrNotExist})
+	}
+
+	err := Wait(t, check, WithTimeout(time.Millisecond))
+	assert.Assert(t, errors.Is(err, ErrTimeout))
+	assert.Assert(t, errors.Is(err, os.ErrNotExist))
+	var pathErr *os.PathError
+	assert.Assert(t, errors.As(err, &pathErr))
+	assert.Equal(t, pathErr.Path, "config")
+	assert.Assert(t, cmp.Contains(err.Error(), "timeout hit after 1ms: config missing: open config:"))
+}
+
+func TestWaitContextPreservesCause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	check := func(t LogT) Result {
+		return Continue("%w", os.ErrPermission)
+	}
+
+	err := Wait(t, check, WithContext(ctx))
+	assert.Assert(t, errors.Is(err, context.Canceled))
+	assert.Assert(t, !errors.Is(err, ErrTimeout))
+}
+
+func TestWaitCheckError(t *testing.T) {
+	check := func(t LogT) Result {
+		return Error(os.ErrClosed)
+	}
+
+	err := Wait(t, check)
+	assert.Assert(t, errors.Is(err, os.ErrClosed))
+	assert.Error(t, err, "polling check failed: file already closed")
+}
+
+func TestWaitSuccess(t *testing.T) {
+	assert.NilError(t, Wait(t, func(t LogT) Result { return Success() }))
+}
@@ -30,18 +30,20 @@ func FileExists(path string) Check {
 
 // Connection try to open a connection to the address on the
 // named network. See net.Dial for a description of the network and
-// address parameters.
+// address parameters. The error from the most recent dial is included in
+// the failure message.
 func Connection(network, address string) Check {
 	return func(t LogT) Result {
 		if h, ok := t.(helperT); ok {
 			h.Helper()
 		}
 
-		_, err := net.Dial(network, address)
+		conn, err := net.Dial(network, address)
 		if err != nil {
 			t.Logf("waiting on socket %s://%s to be available...", network, address)
-			return Continue("socket %s://%s not available", network, address)
+			return Continue("socket %s://%s not available: %w", network, address, err)
 		}
+		_ = conn.Close()
 		return Success()
 	}
 }
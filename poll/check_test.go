@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
 )
 
 func TestWaitOnFile(t *testing.T) {
@@ -32,7 +33,7 @@ func TestWaitOnSocketWithTimeout(t *testing.T) {
 		check := Connection("tcp", "foo.bar:55555")
 		r := check(t)
 		assert.Assert(t, !r.Done())
-		assert.Equal(t, r.Message(), "socket tcp://foo.bar:55555 not available")
+		assert.Assert(t, cmp.Contains(r.Message(), "socket tcp://foo.bar:55555 not available: dial tcp"))
 	})
 
 	t.Run("connection to ", func(t *testing.T) {
@@ -0,0 +1,107 @@
+/*
+Package checks provides poll.Check functions for the conditions most often
+waited on by integration tests.
+*/
+package checks // import "gotest.tools/v3/poll/checks"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"gotest.tools/v3/poll"
+)
+
+// DialTimeout is the timeout used by TCP for each connection attempt.
+var DialTimeout = time.Second
+
+// RequestTimeout is the timeout used by HTTP for each request.
+var RequestTimeout = 5 * time.Second
+
+// TCP returns a Check which succeeds once a TCP connection can be opened to
+// address.
+func TCP(address string) poll.Check {
+	return func(t poll.LogT) poll.Result {
+		conn, err := net.DialTimeout("tcp", address, DialTimeout)
+		if err != nil {
+			return poll.Continue("tcp address %s not accepting connections: %s", address, err)
+		}
+		_ = conn.Close()
+		return poll.Success()
+	}
+}
+
+// HTTP returns a Check which succeeds once a GET request to url responds with
+// status.
+func HTTP(url string, status int) poll.Check {
+	client := &http.Client{Timeout: RequestTimeout}
+	return func(t poll.LogT) poll.Result {
+		resp, err := client.Get(url)
+		if err != nil {
+			return poll.Continue("GET %s failed: %w", url, err)
+		}
+		_, _ = ioutil.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != status {
+			return poll.Continue("GET %s returned status %d, not %d", url, resp.StatusCode, status)
+		}
+		return poll.Success()
+	}
+}
+
+// FileExists returns a Check which succeeds once path exists. It is the same
+// as poll.FileExists.
+func FileExists(path string) poll.Check {
+	return poll.FileExists(path)
+}
+
+// FileMatches returns a Check which succeeds once path exists and its content
+// matches re.
+func FileMatches(path string, re *regexp.Regexp) poll.Check {
+	return func(t poll.LogT) poll.Result {
+		content, err := ioutil.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			return poll.Continue("file %s does not exist", path)
+		case err != nil:
+			return poll.Error(err)
+		case !re.Match(content):
+			return poll.Continue("file %s does not match %s:\n%s", path, re, truncate(content))
+		}
+		return poll.Success()
+	}
+}
+
+const maxContent = 1024
+
+func truncate(content []byte) string {
+	if len(content) <= maxContent {
+		return string(content)
+	}
+	return fmt.Sprintf("%s\n[... %d bytes omitted ...]",
+		content[:maxContent], len(content)-maxContent)
+}
+
+// ProcessExited returns a Check which succeeds once the process with pid has
+// exited.
+//
+// On unix an exited child process of the test which has not been waited on
+// still exists, so ProcessExited will not succeed until the process is
+// reaped. Use it for processes which are not children of the test, or wait on
+// the child process from another goroutine.
+func ProcessExited(pid int) poll.Check {
+	return func(t poll.LogT) poll.Result {
+		running, err := processRunning(pid)
+		switch {
+		case err != nil:
+			return poll.Error(fmt.Errorf("failed to check process %d: %w", pid, err))
+		case running:
+			return poll.Continue("process %d is still running", pid)
+		}
+		return poll.Success()
+	}
+}
@@ -0,0 +1,91 @@
+package checks
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+func TestTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	addr := l.Addr().String()
+
+	assert.Assert(t, TCP(addr)(t).Done())
+
+	assert.NilError(t, l.Close())
+	r := TCP(addr)(t)
+	assert.Assert(t, !r.Done())
+	assert.Assert(t, cmp.Contains(r.Message(), "tcp address "+addr+" not accepting connections"))
+}
+
+func TestHTTP(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	r := HTTP(srv.URL, http.StatusOK)(t)
+	assert.Assert(t, !r.Done())
+	assert.Equal(t, r.Message(), "GET "+srv.URL+" returned status 503, not 200")
+
+	status = http.StatusOK
+	assert.Assert(t, HTTP(srv.URL, http.StatusOK)(t).Done())
+
+	srv.Close()
+	r = HTTP(srv.URL, http.StatusOK)(t)
+	assert.Assert(t, !r.Done())
+	assert.Assert(t, cmp.Contains(r.Message(), "GET "+srv.URL+" failed: "))
+}
+
+func TestFileExists(t *testing.T) {
+	dir := fs.NewDir(t, "test-file-exists")
+	defer dir.Remove()
+	path := filepath.Join(dir.Path(), "file")
+
+	r := FileExists(path)(t)
+	assert.Assert(t, !r.Done())
+	assert.Equal(t, r.Message(), "file "+path+" does not exist")
+
+	assert.NilError(t, ioutil.WriteFile(path, nil, 0600))
+	assert.Assert(t, FileExists(path)(t).Done())
+}
+
+func TestFileMatches(t *testing.T) {
+	dir := fs.NewDir(t, "test-file-matches", fs.WithFile("log", "starting\n"))
+	defer dir.Remove()
+	path := dir.Join("log")
+	check := FileMatches(path, regexp.MustCompile(`(?m)^ready$`))
+
+	r := check(t)
+	assert.Assert(t, !r.Done())
+	assert.Equal(t, r.Message(), "file "+path+" does not match (?m)^ready$:\nstarting\n")
+
+	assert.NilError(t, ioutil.WriteFile(path, []byte("starting\nready\n"), 0600))
+	assert.Assert(t, check(t).Done())
+
+	r = FileMatches(dir.Join("missing"), regexp.MustCompile("x"))(t)
+	assert.Assert(t, !r.Done())
+}
+
+func TestProcessExited(t *testing.T) {
+	r := ProcessExited(os.Getpid())(t)
+	assert.Assert(t, !r.Done())
+	assert.NilError(t, r.Error())
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	assert.NilError(t, cmd.Run())
+	r = ProcessExited(cmd.Process.Pid)(t)
+	assert.NilError(t, r.Error())
+	assert.Assert(t, r.Done())
+}
@@ -0,0 +1,18 @@
+package checks
+
+import (
+	"os"
+	"strconv"
+)
+
+func processRunning(pid int) (bool, error) {
+	_, err := os.Stat("/proc/" + strconv.Itoa(pid))
+	switch {
+	case err == nil:
+		return true, nil
+	case os.IsNotExist(err):
+		return false, nil
+	default:
+		return false, err
+	}
+}
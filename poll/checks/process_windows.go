@@ -0,0 +1,31 @@
+package checks
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	processQueryLimitedInformation = 0x1000
+	stillActive                    = 259
+	errorInvalidParameter          = syscall.Errno(87)
+)
+
+func processRunning(pid int) (bool, error) {
+	h, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		// OpenProcess fails with ERROR_INVALID_PARAMETER when there is no
+		// process with the pid.
+		if errors.Is(err, errorInvalidParameter) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer syscall.CloseHandle(h) // nolint: errcheck
+
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false, err
+	}
+	return code == stillActive, nil
+}
@@ -0,0 +1,68 @@
+package poll
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TimeoutMultiplierEnvVar is the name of the environment variable used to
+// scale the timeout of every wait. The value must be a positive number, for
+// example GOTEST_POLL_TIMEOUT_MULTIPLIER=2.5 on a slow CI machine. The
+// multiplier is applied after all settings, so it also scales timeouts set
+// with WithTimeout.
+const TimeoutMultiplierEnvVar = "GOTEST_POLL_TIMEOUT_MULTIPLIER"
+
+var (
+	defaultsMu sync.Mutex
+	defaultOps []SettingOp
+)
+
+// SetDefaults sets the settings used by every wait in the test binary, before
+// the settings passed to WaitOn are applied. Each call replaces the defaults
+// from the previous call. It is intended to be called once, from TestMain or
+// an init function.
+func SetDefaults(pollOps ...SettingOp) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaultOps = append([]SettingOp(nil), pollOps...)
+}
+
+// newConfig returns the Settings for a wait, from the package defaults,
+// pollOps, and the timeout multiplier.
+func newConfig(t LogT, pollOps []SettingOp) *Settings {
+	config := defaultConfig()
+	defaultsMu.Lock()
+	for _, pollOp := range defaultOps {
+		pollOp(config)
+	}
+	defaultsMu.Unlock()
+	for _, pollOp := range pollOps {
+		pollOp(config)
+	}
+
+	multiplier, err := timeoutMultiplier()
+	if err != nil {
+		t.Logf("ignoring %s: %s", TimeoutMultiplierEnvVar, err)
+		return config
+	}
+	config.Timeout = time.Duration(float64(config.Timeout) * multiplier)
+	return config
+}
+
+func timeoutMultiplier() (float64, error) {
+	value, ok := os.LookupEnv(TimeoutMultiplierEnvVar)
+	if !ok || value == "" {
+		return 1, nil
+	}
+	multiplier, err := strconv.ParseFloat(value, 64)
+	switch {
+	case err != nil:
+		return 1, err
+	case multiplier <= 0:
+		return 1, fmt.Errorf("multiplier must be a positive number, got %q", value)
+	}
+	return multiplier, nil
+}
@@ -0,0 +1,39 @@
+package poll
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+)
+
+func TestNewConfigWithDefaults(t *testing.T) {
+	SetDefaults(WithTimeout(time.Minute), WithDelay(time.Second))
+	defer SetDefaults()
+
+	config := newConfig(t, []SettingOp{WithDelay(time.Millisecond)})
+	assert.Equal(t, config.Timeout, time.Minute)
+	assert.Equal(t, config.Delay, time.Millisecond)
+}
+
+func TestNewConfigTimeoutMultiplier(t *testing.T) {
+	defer env.Patch(t, TimeoutMultiplierEnvVar, "2.5")()
+
+	config := newConfig(t, []SettingOp{WithTimeout(4 * time.Second)})
+	assert.Equal(t, config.Timeout, 10*time.Second)
+	assert.Equal(t, config.Delay, 100*time.Millisecond)
+}
+
+func TestNewConfigInvalidTimeoutMultiplier(t *testing.T) {
+	for _, value := range []string{"fast", "0", "-1"} {
+		t.Run(value, func(t *testing.T) {
+			defer env.Patch(t, TimeoutMultiplierEnvVar, value)()
+			lt := &logT{}
+
+			config := newConfig(lt, nil)
+			assert.Equal(t, config.Timeout, 10*time.Second)
+			assert.Equal(t, len(lt.logs), 1)
+		})
+	}
+}
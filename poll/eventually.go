@@ -0,0 +1,84 @@
+package poll
+
+import (
+	"strings"
+	"sync"
+
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/assert"
+)
+
+// Eventually polls comparison until the cmp.Comparison it returns succeeds.
+// comparison is called on every poll so that the values being compared are
+// read again. Eventually accepts the same settings as WaitOn.
+//
+//	poll.Eventually(t, func() cmp.Comparison {
+//		return cmp.Equal(server.Status(), "ready")
+//	}, poll.WithTimeout(time.Second))
+//
+// If polling stops before the comparison succeeds, the failure message of the
+// last comparison is logged in the format used by assert.Assert, including any
+// diff of the values, and the test fails with the reason polling stopped.
+func Eventually(t TestingT, comparison func() cmp.Comparison, pollOps ...SettingOp) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	last := &lastResult{}
+	check := func(t LogT) Result {
+		result := comparison()()
+		if result.Success() {
+			return Success()
+		}
+		last.set(result)
+		return Continue("%s", firstLine(Compare(func() cmp.Result { return result }).Message()))
+	}
+
+	config := newConfig(t, pollOps)
+	err := wait(t, check, config)
+	if err == nil {
+		return
+	}
+	if result := last.get(); result != nil {
+		logComparisonFailure(t, result)
+	}
+	t.Fatalf("%s", err)
+}
+
+// logComparisonFailure logs the failure message of result. It must be called
+// directly by Eventually, so that the source of the comparison can be found
+// in the call stack.
+func logComparisonFailure(t TestingT, result cmp.Result) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	assert.RunComparison(t, assert.ArgsFromReturnedComparison, func() cmp.Result {
+		return result
+	})
+}
+
+// lastResult is the most recent failed result of a comparison. It is set by
+// the check, which may still be running when the wait stops.
+type lastResult struct {
+	mu     sync.Mutex
+	result cmp.Result
+}
+
+func (l *lastResult) set(result cmp.Result) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.result = result
+}
+
+func (l *lastResult) get() cmp.Result {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.result
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
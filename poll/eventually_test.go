@@ -0,0 +1,52 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+type logFakeT struct {
+	fakeT
+	logs []string
+}
+
+func (t *logFakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func TestEventually(t *testing.T) {
+	counter := 0
+	Eventually(t, func() cmp.Comparison {
+		counter++
+		return cmp.Equal(counter, 3)
+	}, WithDelay(0))
+	assert.Equal(t, counter, 3)
+}
+
+func TestEventuallyTimeout(t *testing.T) {
+	fakeT := &logFakeT{}
+	status := "starting\nup"
+
+	assert.Assert(t, cmp.Panics(func() {
+		Eventually(fakeT, func() cmp.Comparison {
+			return cmp.Equal(status, "ready\nup")
+		}, WithDelay(0), WithTimeout(10*time.Millisecond))
+	}))
+
+	assert.Assert(t, cmp.Contains(fakeT.failed, "timeout hit after 10ms: assertion failed: "))
+	assert.Equal(t, len(fakeT.logs), 1)
+	expected := `assertion failed: 
+--- status
++++ →
+@@ -1,2 +1,2 @@
+-starting
++ready
+ up
+`
+	assert.Assert(t, strings.HasSuffix(fakeT.logs[0], expected), fakeT.logs[0])
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"gotest.tools/v3/assert/cmp"
 	"gotest.tools/v3/poll"
 )
 
@@ -43,3 +44,9 @@ func ExampleSettingOp() {
 	}
 	poll.WaitOn(t, check, poll.WithTimeout(30*time.Second), poll.WithDelay(15*time.Millisecond))
 }
+
+func ExampleEventually() {
+	poll.Eventually(t, func() cmp.Comparison {
+		return cmp.Equal(getState(), "ready")
+	}, poll.WithTimeout(time.Second))
+}
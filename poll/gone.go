@@ -0,0 +1,31 @@
+package poll
+
+// WaitUntilGone waits until check stops succeeding. It is the inverse of
+// WaitOn, for waiting until a condition which held no longer does, such as a
+// resource being deleted, a port being closed, or a process exiting. check is
+// written the same way as for WaitOn, so a check like FileExists or
+// Connection can be used to wait until the file or socket is gone.
+//
+// Polling stops when check returns a Result which is not done. If check
+// returns an error the test fails and polling stops. WaitUntilGone accepts
+// the same settings as WaitOn.
+func WaitUntilGone(t TestingT, check Check, pollOps ...SettingOp) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	WaitOn(t, gone(check), pollOps...)
+}
+
+func gone(check Check) Check {
+	return func(t LogT) Result {
+		result := check(t)
+		switch {
+		case result.Error() != nil:
+			return result
+		case result.Done():
+			return Continue("condition still holds")
+		default:
+			return Success()
+		}
+	}
+}
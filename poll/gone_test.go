@@ -0,0 +1,49 @@
+package poll
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestWaitUntilGone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "test-wait-until-gone")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lock")
+	assert.NilError(t, ioutil.WriteFile(path, nil, 0600))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		os.Remove(path)
+	}()
+	WaitUntilGone(t, FileExists(path), WithDelay(time.Millisecond))
+}
+
+func TestWaitUntilGoneTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	check := func(t LogT) Result {
+		return Success()
+	}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitUntilGone(fakeT, check, WithTimeout(time.Millisecond))
+	}))
+	assert.Equal(t, withoutStats(fakeT.failed), "timeout hit after 1ms: condition still holds")
+}
+
+func TestWaitUntilGoneError(t *testing.T) {
+	fakeT := &fakeT{}
+	check := func(t LogT) Result {
+		return Error(errors.New("broke"))
+	}
+
+	assert.Assert(t, cmp.Panics(func() { WaitUntilGone(fakeT, check) }))
+	assert.Equal(t, fakeT.failed, "polling check failed: broke")
+}
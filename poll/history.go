@@ -0,0 +1,64 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// history records the most recent Continue messages from a wait, so that the
+// failure message can show whether the condition was progressing, oscillating,
+// or stuck.
+type history struct {
+	size    int
+	entries []historyEntry
+}
+
+type historyEntry struct {
+	first   time.Duration
+	last    time.Duration
+	count   int
+	message string
+}
+
+func (h *history) add(elapsed time.Duration, message string) {
+	if h.size <= 0 {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1].message == message {
+		h.entries[n-1].last = elapsed
+		h.entries[n-1].count++
+		return
+	}
+	h.entries = append(h.entries, historyEntry{
+		first:   elapsed,
+		last:    elapsed,
+		count:   1,
+		message: message,
+	})
+	if len(h.entries) > h.size {
+		h.entries = h.entries[1:]
+	}
+}
+
+// String returns the history formatted for a failure message, or an empty
+// string if there is not more than one entry.
+func (h *history) String() string {
+	if len(h.entries) < 2 {
+		return ""
+	}
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "\nlast %d results:", len(h.entries))
+	for _, entry := range h.entries {
+		fmt.Fprintf(b, "\n  %s", formatElapsed(entry.first))
+		if entry.count > 1 {
+			fmt.Fprintf(b, "-%s (x%d)", formatElapsed(entry.last), entry.count)
+		}
+		fmt.Fprintf(b, ": %s", entry.message)
+	}
+	return b.String()
+}
+
+func formatElapsed(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
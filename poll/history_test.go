@@ -0,0 +1,72 @@
+package poll
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/clock"
+)
+
+func TestHistory(t *testing.T) {
+	h := &history{size: 3}
+	h.add(0, "one")
+	assert.Equal(t, h.String(), "")
+
+	h.add(100*time.Millisecond, "two")
+	h.add(200*time.Millisecond, "two")
+	h.add(300*time.Millisecond, "three")
+	h.add(400*time.Millisecond, "four")
+	expected := `
+last 3 results:
+  100ms-200ms (x2): two
+  300ms: three
+  400ms: four`
+	assert.Equal(t, h.String(), expected)
+}
+
+func TestHistoryDisabled(t *testing.T) {
+	h := &history{}
+	h.add(0, "one")
+	h.add(time.Second, "two")
+	assert.Equal(t, h.String(), "")
+}
+
+func TestWaitOnTimeoutWithHistory(t *testing.T) {
+	fakeT := &fakeT{}
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	counter := 0
+	check := func(t LogT) Result {
+		counter++
+		return Continue("replicas ready: %d", counter%2)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Check(t, cmp.Panics(func() {
+			WaitOn(fakeT, check,
+				WithClock(fake),
+				WithTimeout(3500*time.Millisecond),
+				WithDelay(time.Second),
+				WithHistory(2))
+		}))
+	}()
+
+	for i := 0; i < 3; i++ {
+		fake.BlockUntil(2)
+		fake.Advance(time.Second)
+	}
+	// only the timeout fires, the next delay ends at 4s
+	fake.BlockUntil(2)
+	fake.Advance(500 * time.Millisecond)
+	<-done
+
+	expected := `timeout hit after 3.5s: replicas ready: 0
+last 2 results:
+  2s: replicas ready: 1
+  3s: replicas ready: 0
+4 attempts in 3.5s, checks took 0s on average and 0s at most`
+	assert.Equal(t, fakeT.failed, expected)
+}
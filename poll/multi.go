@@ -0,0 +1,108 @@
+package poll
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// WaitOnAll waits until every check in checks has succeeded. The checks are
+// run concurrently on each poll, and a check is not run again once it has
+// succeeded. If the timeout is reached the failure message lists each
+// condition which was still unmet. WaitOnAll accepts the same settings as
+// WaitOn.
+func WaitOnAll(t TestingT, checks []Check, pollOps ...SettingOp) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	WaitOn(t, allOf(checks), pollOps...)
+}
+
+// WaitOnAny waits until at least one check in checks has succeeded. The checks
+// are run concurrently on each poll. If the timeout is reached the failure
+// message lists the state of every condition. WaitOnAny accepts the same
+// settings as WaitOn.
+func WaitOnAny(t TestingT, checks []Check, pollOps ...SettingOp) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	WaitOn(t, anyOf(checks), pollOps...)
+}
+
+func allOf(checks []Check) Check {
+	done := make([]bool, len(checks))
+	return func(t LogT) Result {
+		results := runChecks(t, checks, done)
+		if err := firstError(results); err != nil {
+			return Error(err)
+		}
+		var pending []int
+		for i, result := range results {
+			if result != nil && result.Done() {
+				done[i] = true
+			}
+			if !done[i] {
+				pending = append(pending, i)
+			}
+		}
+		if len(pending) == 0 {
+			return Success()
+		}
+		return Continue("%d of %d conditions not met:\n%s",
+			len(pending), len(checks), describeConditions(results, pending))
+	}
+}
+
+func anyOf(checks []Check) Check {
+	return func(t LogT) Result {
+		results := runChecks(t, checks, nil)
+		if err := firstError(results); err != nil {
+			return Error(err)
+		}
+		pending := make([]int, 0, len(results))
+		for i, result := range results {
+			if result.Done() {
+				return Success()
+			}
+			pending = append(pending, i)
+		}
+		return Continue("none of %d conditions met:\n%s",
+			len(checks), describeConditions(results, pending))
+	}
+}
+
+// runChecks runs each check which is not yet done concurrently, and returns
+// their results. The result for a check which was skipped is nil.
+func runChecks(t LogT, checks []Check, done []bool) []Result {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		if done != nil && done[i] {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = check(t)
+		}(i, check)
+	}
+	wg.Wait()
+	return results
+}
+
+func firstError(results []Result) error {
+	for i, result := range results {
+		if result != nil && result.Error() != nil {
+			return fmt.Errorf("condition %d of %d: %w", i+1, len(results), result.Error())
+		}
+	}
+	return nil
+}
+
+func describeConditions(results []Result, indexes []int) string {
+	lines := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		lines = append(lines, fmt.Sprintf("condition %d: %s", i+1, results[i].Message()))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,77 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func countdown(n int) Check {
+	return func(t LogT) Result {
+		if n == 0 {
+			return Success()
+		}
+		n--
+		return Continue("%d left", n+1)
+	}
+}
+
+func never(message string) Check {
+	return func(t LogT) Result {
+		return Continue(message)
+	}
+}
+
+func TestWaitOnAll(t *testing.T) {
+	calls := 0
+	once := func(t LogT) Result {
+		calls++
+		return Success()
+	}
+
+	WaitOnAll(t, []Check{countdown(1), once, countdown(3)}, WithDelay(0))
+	assert.Equal(t, calls, 1, "succeeded check should not be run again")
+}
+
+func TestWaitOnAllTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	checks := []Check{never("db not ready"), countdown(0), never("cache not ready")}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOnAll(fakeT, checks, WithDelay(0), WithTimeout(10*time.Millisecond))
+	}))
+	expected := `timeout hit after 10ms: 2 of 3 conditions not met:
+condition 1: db not ready
+condition 3: cache not ready`
+	assert.Equal(t, withoutStats(fakeT.failed), expected)
+}
+
+func TestWaitOnAllError(t *testing.T) {
+	fakeT := &fakeT{}
+	checks := []Check{never("waiting"), func(t LogT) Result {
+		return Error(errors.New("broke"))
+	}}
+
+	assert.Assert(t, cmp.Panics(func() { WaitOnAll(fakeT, checks) }))
+	assert.Equal(t, fakeT.failed, "polling check failed: condition 2 of 2: broke")
+}
+
+func TestWaitOnAny(t *testing.T) {
+	WaitOnAny(t, []Check{never("never"), countdown(2)}, WithDelay(0))
+}
+
+func TestWaitOnAnyTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	checks := []Check{never("primary down"), never("replica down")}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOnAny(fakeT, checks, WithDelay(0), WithTimeout(10*time.Millisecond))
+	}))
+	expected := `timeout hit after 10ms: none of 2 conditions met:
+condition 1: primary down
+condition 2: replica down`
+	assert.Equal(t, withoutStats(fakeT.failed), expected)
+}
@@ -3,7 +3,10 @@
 package poll // import "gotest.tools/v3/poll"
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/rand"
 	"strings"
 	"time"
 
@@ -32,12 +35,77 @@ type Settings struct {
 	// Timeout is the maximum time to wait for the condition. Defaults to 10s.
 	Timeout time.Duration
 	// Delay is the time to sleep between checking the condition. Defaults to
-	// 100ms.
+	// 100ms. When Backoff is set, Delay is the delay after the first check.
 	Delay time.Duration
+	// Backoff is the factor used to increase the delay after each check. A
+	// value of 0 or 1 uses the same Delay between every check.
+	Backoff float64
+	// MaxDelay is the upper bound on the delay when Backoff or Strategy is
+	// used. A value of 0 means the delay is not bounded.
+	MaxDelay time.Duration
+	// Jitter randomly reduces each delay by up to this fraction of the delay.
+	// Must be between 0 and 1. Defaults to 0, which disables jitter.
+	Jitter float64
+	// Strategy, when not nil, computes the delay after each check instead of
+	// Delay and Backoff. The delay is still bounded by MaxDelay, and reduced
+	// by Jitter.
+	Strategy Backoff
+	// Clock is used to measure the timeout and delays. Defaults to the system
+	// clock.
+	Clock Clock
+	// Context stops polling when it is done, even if Timeout has not been
+	// reached. Defaults to context.Background().
+	Context context.Context
+	// OnProgress is called with the latest state of the wait at most once
+	// every ProgressInterval while the check has not succeeded. Defaults to
+	// nil, which disables progress reports.
+	OnProgress func(t LogT, progress Progress)
+	// ProgressInterval is the minimum time between calls to OnProgress.
+	ProgressInterval time.Duration
+	// DeadlineMargin is the time left before the test deadline, as reported by
+	// t.Deadline(), at which polling stops. When the test deadline is earlier
+	// than Timeout, the timeout is reduced so the wait fails with a useful
+	// message before the test binary is killed. Defaults to 1s. A negative
+	// value ignores the test deadline.
+	DeadlineMargin time.Duration
+	// History is the number of recent Continue messages included in the
+	// failure message when polling stops before the check succeeds.
+	// Consecutive duplicate messages are counted as one. The history is only
+	// included when it has more than one entry. Defaults to 5.
+	History int
+	// Stats, when not nil, is set to the attempt and timing metrics of the
+	// wait when it stops.
+	Stats *Stats
 }
 
 func defaultConfig() *Settings {
-	return &Settings{Timeout: 10 * time.Second, Delay: 100 * time.Millisecond}
+	return &Settings{
+		Timeout: 10 * time.Second,
+		Delay:   100 * time.Millisecond,
+		Context: context.Background(),
+		Clock:   systemClock{},
+
+		DeadlineMargin: time.Second,
+		History:        5,
+	}
+}
+
+// Clock provides the current time and timers to WaitOn. It may be replaced
+// using WithClock so that code which waits using poll can be tested without
+// real sleeps. A clock.Fake from gotest.tools/v3/clock implements Clock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
 }
 
 // SettingOp is a function which accepts and modifies Settings
@@ -57,6 +125,123 @@ func WithTimeout(timeout time.Duration) SettingOp {
 	}
 }
 
+// WithBackoff increases the delay between checks exponentially. After each
+// check the delay is multiplied by factor, up to a maximum of max. Use
+// WithDelay to set the initial delay.
+func WithBackoff(factor float64, max time.Duration) SettingOp {
+	return func(config *Settings) {
+		config.Backoff = factor
+		config.MaxDelay = max
+	}
+}
+
+// WithJitter randomly reduces each delay by up to fraction of the delay, so
+// that concurrent waiters do not poll in lockstep. fraction is clamped to the
+// range 0 to 1.
+func WithJitter(fraction float64) SettingOp {
+	return func(config *Settings) {
+		switch {
+		case fraction < 0:
+			fraction = 0
+		case fraction > 1:
+			fraction = 1
+		}
+		config.Jitter = fraction
+	}
+}
+
+// Backoff computes the delay between checks. Use WithBackoffStrategy to
+// replace the fixed delay and exponential backoff of Settings.
+type Backoff interface {
+	// Delay returns the delay to use after the check numbered attempt, where
+	// the first check is attempt 0.
+	Delay(attempt int) time.Duration
+}
+
+// BackoffFunc is an adapter to allow the use of a function as a Backoff.
+type BackoffFunc func(attempt int) time.Duration
+
+// Delay returns f(attempt).
+func (f BackoffFunc) Delay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// WithBackoffStrategy sets the Backoff used to compute the delay after each
+// check. Delay and Backoff are ignored when a strategy is set, but MaxDelay
+// and Jitter are still applied to the delay.
+func WithBackoffStrategy(strategy Backoff) SettingOp {
+	return func(config *Settings) {
+		config.Strategy = strategy
+	}
+}
+
+// nextDelay returns the delay to use after the check numbered attempt, where
+// the first check is attempt 0.
+func (s *Settings) nextDelay(attempt int) time.Duration {
+	delay := s.Delay
+	switch {
+	case s.Strategy != nil:
+		delay = s.Strategy.Delay(attempt)
+	case s.Backoff > 1:
+		d := float64(delay)
+		for i := 0; i < attempt; i++ {
+			d *= s.Backoff
+			if s.MaxDelay > 0 && d >= float64(s.MaxDelay) {
+				d = float64(s.MaxDelay)
+				break
+			}
+		}
+		delay = time.Duration(d)
+	}
+	if s.MaxDelay > 0 && delay > s.MaxDelay {
+		delay = s.MaxDelay
+	}
+	if s.Jitter > 0 {
+		delay -= time.Duration(s.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// WithDeadlineMargin sets how long before the test deadline polling stops. A
+// negative margin ignores the test deadline.
+func WithDeadlineMargin(margin time.Duration) SettingOp {
+	return func(config *Settings) {
+		config.DeadlineMargin = margin
+	}
+}
+
+// WithHistory sets the number of recent Continue messages included in the
+// failure message. A size of 0 disables the history.
+func WithHistory(size int) SettingOp {
+	return func(config *Settings) {
+		config.History = size
+	}
+}
+
+// WithStats sets stats to the attempt and timing metrics of the wait when it
+// stops, whether or not the check succeeded.
+func WithStats(stats *Stats) SettingOp {
+	return func(config *Settings) {
+		config.Stats = stats
+	}
+}
+
+// WithClock sets the clock used to measure the timeout and delays.
+func WithClock(clock Clock) SettingOp {
+	return func(config *Settings) {
+		config.Clock = clock
+	}
+}
+
+// WithContext sets a context which stops polling when it is done. When the
+// context ends the wait, the test fails with a message that includes the
+// context error instead of the timeout.
+func WithContext(ctx context.Context) SettingOp {
+	return func(config *Settings) {
+		config.Context = ctx
+	}
+}
+
 // Result of a check performed by WaitOn
 type Result interface {
 	// Error indicates that the check failed and polling should stop, and the
@@ -72,6 +257,7 @@ type result struct {
 	done    bool
 	message string
 	err     error
+	cause   error
 }
 
 func (r result) Done() bool {
@@ -86,11 +272,22 @@ func (r result) Error() error {
 	return r.err
 }
 
+// Unwrap returns the error wrapped by a Continue result.
+func (r result) Unwrap() error {
+	return r.cause
+}
+
 // Continue returns a Result that indicates to WaitOn that it should continue
 // polling. The message text will be used as the failure message if the timeout
 // is reached.
+//
+// The message may wrap an error using the %w verb, as in fmt.Errorf. If the
+// timeout is reached the wrapped error from the most recent Continue is
+// preserved in the error returned by Wait and WaitFor, so that it can be
+// inspected with errors.Is and errors.As.
 func Continue(message string, args ...interface{}) Result {
-	return result{message: fmt.Sprintf(message, args...)}
+	err := fmt.Errorf(message, args...)
+	return result{message: err.Error(), cause: errors.Unwrap(err)}
 }
 
 // Success returns a Result where Done() returns true, which indicates to WaitOn
@@ -112,37 +309,183 @@ func WaitOn(t TestingT, check Check, pollOps ...SettingOp) {
 	if ht, ok := t.(helperT); ok {
 		ht.Helper()
 	}
-	config := defaultConfig()
-	for _, pollOp := range pollOps {
-		pollOp(config)
+	config := newConfig(t, pollOps)
+	if err := wait(t, check, config); err != nil {
+		t.Fatalf("%s", err)
+	}
+}
+
+// Wait is like WaitOn, but instead of failing the test it returns an error
+// which describes why polling stopped. The error wraps ErrTimeout, or the
+// context error, or the error from an Error result, as well as the error
+// wrapped by the most recent Continue result.
+func Wait(t LogT, check Check, pollOps ...SettingOp) error {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	config := newConfig(t, pollOps)
+	return wait(t, check, config)
+}
+
+// WaitOnContext is WaitOn with a context. Polling stops when ctx is done, or
+// when the timeout is reached, whichever happens first. The failure message
+// reports which of the two ended the wait.
+func WaitOnContext(ctx context.Context, t TestingT, check Check, pollOps ...SettingOp) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
 	}
+	WaitOn(t, check, append(pollOps, WithContext(ctx))...)
+}
 
+// wait polls check until it is done, returning an error which describes why
+// polling stopped if the check did not succeed.
+func wait(t LogT, check Check, config *Settings) error {
 	var lastMessage string
-	after := time.After(config.Timeout)
+	var lastCause error
+	hist := &history{size: config.History}
+	clock := config.Clock
+	start := clock.Now()
+	timeout, capped := config.timeout(t, start)
+	after := clock.After(timeout)
+	lastReport := start
+
+	stats := &Stats{}
+	defer func() {
+		if config.Stats != nil {
+			*config.Stats = *stats
+		}
+	}()
+	// withStats adds the stats to the error for a wait which did not finish.
+	withStats := func(err error) error {
+		stats.Elapsed = clock.Now().Sub(start)
+		return fmt.Errorf("%w\n%s", err, stats)
+	}
+
 	chResult := make(chan Result)
-	for {
+	for attempt := 0; ; attempt++ {
+		checkStart := clock.Now()
 		go func() {
 			chResult <- check(t)
 		}()
 		select {
 		case <-after:
-			if lastMessage == "" {
-				lastMessage = "first check never completed"
-			}
-			t.Fatalf("timeout hit after %s: %s", config.Timeout, lastMessage)
+			return withStats(timeoutError(timeout, capped, describeLast(lastMessage)+hist.String(), lastCause))
+		case <-config.Context.Done():
+			return withStats(contextError(config.Context, clock.Now().Sub(start), lastMessage, hist, lastCause))
 		case result := <-chResult:
+			now := clock.Now()
+			stats.Attempts++
+			stats.CheckDurations = append(stats.CheckDurations, now.Sub(checkStart))
+			stats.Elapsed = now.Sub(start)
 			switch {
 			case result.Error() != nil:
-				t.Fatalf("polling check failed: %s", result.Error())
+				return fmt.Errorf("polling check failed: %w", result.Error())
 			case result.Done():
-				return
+				return nil
 			}
-			time.Sleep(config.Delay)
 			lastMessage = result.Message()
+			lastCause = causeOf(result)
+			hist.add(now.Sub(start), lastMessage)
+		}
+
+		if now := clock.Now(); config.OnProgress != nil && now.Sub(lastReport) >= config.ProgressInterval {
+			lastReport = now
+			config.OnProgress(t, Progress{
+				Attempts: attempt + 1,
+				Elapsed:  now.Sub(start),
+				Message:  lastMessage,
+			})
+		}
+
+		select {
+		case <-after:
+			return withStats(timeoutError(timeout, capped, lastMessage+hist.String(), lastCause))
+		case <-config.Context.Done():
+			return withStats(contextError(config.Context, clock.Now().Sub(start), lastMessage, hist, lastCause))
+		case <-clock.After(config.nextDelay(attempt)):
 		}
 	}
 }
 
+type deadlineT interface {
+	Deadline() (time.Time, bool)
+}
+
+// timeout returns the timeout for a wait which starts at now, and whether it
+// was reduced to stop before the test deadline.
+func (s *Settings) timeout(t LogT, now time.Time) (time.Duration, bool) {
+	dt, ok := t.(deadlineT)
+	if !ok || s.DeadlineMargin < 0 {
+		return s.Timeout, false
+	}
+	deadline, ok := dt.Deadline()
+	if !ok {
+		return s.Timeout, false
+	}
+	remaining := deadline.Sub(now) - s.DeadlineMargin
+	if remaining >= s.Timeout {
+		return s.Timeout, false
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// ErrTimeout is wrapped by the error returned from Wait and WaitFor when the
+// timeout is reached.
+var ErrTimeout = errors.New("timeout hit")
+
+// waitError is returned when polling stops before the check succeeds. It
+// matches reason with errors.Is, and unwraps to the error wrapped by the last
+// Continue result.
+type waitError struct {
+	message string
+	reason  error
+	cause   error
+}
+
+func (e *waitError) Error() string {
+	return e.message
+}
+
+func (e *waitError) Is(target error) bool {
+	return target == e.reason
+}
+
+func (e *waitError) Unwrap() error {
+	return e.cause
+}
+
+func causeOf(result Result) error {
+	if u, ok := result.(interface{ Unwrap() error }); ok {
+		return u.Unwrap()
+	}
+	return nil
+}
+
+func timeoutError(timeout time.Duration, capped bool, lastMessage string, cause error) error {
+	message := fmt.Sprintf("timeout hit after %s: %s", timeout, lastMessage)
+	if capped {
+		message = fmt.Sprintf("timeout hit after %s, before the test deadline: %s",
+			timeout.Round(time.Millisecond), lastMessage)
+	}
+	return &waitError{message: message, reason: ErrTimeout, cause: cause}
+}
+
+func contextError(ctx context.Context, elapsed time.Duration, lastMessage string, hist *history, cause error) error {
+	message := fmt.Sprintf("polling stopped after %s by context (%s): %s%s",
+		elapsed.Round(time.Millisecond), ctx.Err(), describeLast(lastMessage), hist)
+	return &waitError{message: message, reason: ctx.Err(), cause: cause}
+}
+
+func describeLast(lastMessage string) string {
+	if lastMessage == "" {
+		return "first check never completed"
+	}
+	return lastMessage
+}
+
 // Compare values using the cmp.Comparison. If the comparison fails return a
 // result which indicates to WaitOn that it should continue waiting.
 // If the comparison is successful then WaitOn stops polling.
@@ -154,6 +497,24 @@ func Compare(compare cmp.Comparison) Result {
 	return Continue(buf.String())
 }
 
+// CompareCheck returns a Check which calls comparison on every poll and
+// compares the result using Compare. comparison is called each time so that
+// the values being compared are read again, for example:
+//
+//	poll.WaitOn(t, poll.CompareCheck(func() cmp.Comparison {
+//		return cmp.Equal(server.Status(), "ready")
+//	}))
+//
+// The failure message of the comparison is used as the Continue message, so
+// it is included in the failure message if the timeout is reached. Use
+// Eventually to also log the full failure message of the last comparison,
+// including any diff of the values.
+func CompareCheck(comparison func() cmp.Comparison) Check {
+	return func(t LogT) Result {
+		return Compare(comparison())
+	}
+}
+
 type logBuffer struct {
 	log [][]interface{}
 }
@@ -1,12 +1,14 @@
 package poll
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/clock"
 )
 
 type fakeT struct {
@@ -47,7 +49,7 @@ func TestWaitOnWithTimeout(t *testing.T) {
 	assert.Assert(t, cmp.Panics(func() {
 		WaitOn(fakeT, check, WithTimeout(time.Millisecond))
 	}))
-	assert.Equal(t, "timeout hit after 1ms: not done", fakeT.failed)
+	assert.Equal(t, "timeout hit after 1ms: not done", withoutStats(fakeT.failed))
 }
 
 func TestWaitOnWithCheckTimeout(t *testing.T) {
@@ -59,7 +61,7 @@ func TestWaitOnWithCheckTimeout(t *testing.T) {
 	}
 
 	assert.Assert(t, cmp.Panics(func() { WaitOn(fakeT, check, WithTimeout(time.Millisecond)) }))
-	assert.Equal(t, "timeout hit after 1ms: first check never completed", fakeT.failed)
+	assert.Equal(t, "timeout hit after 1ms: first check never completed", withoutStats(fakeT.failed))
 }
 
 func TestWaitOnWithCheckError(t *testing.T) {
@@ -85,3 +87,229 @@ func TestWaitOn_WithCompare(t *testing.T) {
 	}))
 	assert.Assert(t, cmp.Contains(fakeT.failed, "assertion failed: 3 (int) != 4 (int)"))
 }
+
+func TestWaitOnContextCancelled(t *testing.T) {
+	fakeT := &fakeT{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOnContext(ctx, fakeT, check, WithDelay(0))
+	}))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "by context (context canceled)"))
+}
+
+func TestWaitOnContextDeadlineBeforeTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	start := time.Now()
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOn(fakeT, check, WithContext(ctx), WithDelay(5*time.Millisecond))
+	}))
+	assert.Assert(t, time.Since(start) < 5*time.Second)
+	assert.Assert(t, cmp.Contains(fakeT.failed, "by context (context deadline exceeded): not done"))
+}
+
+func TestWaitOnContextTimeoutBeforeContext(t *testing.T) {
+	fakeT := &fakeT{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOnContext(ctx, fakeT, check, WithTimeout(time.Millisecond))
+	}))
+	assert.Equal(t, "timeout hit after 1ms: not done", withoutStats(fakeT.failed))
+}
+
+func TestSettingsNextDelay(t *testing.T) {
+	t.Run("fixed delay", func(t *testing.T) {
+		config := &Settings{Delay: 10 * time.Millisecond}
+		for _, attempt := range []int{0, 1, 5} {
+			assert.Equal(t, config.nextDelay(attempt), 10*time.Millisecond)
+		}
+	})
+
+	t.Run("exponential backoff", func(t *testing.T) {
+		config := &Settings{Delay: 10 * time.Millisecond, Backoff: 2}
+		assert.Equal(t, config.nextDelay(0), 10*time.Millisecond)
+		assert.Equal(t, config.nextDelay(1), 20*time.Millisecond)
+		assert.Equal(t, config.nextDelay(3), 80*time.Millisecond)
+	})
+
+	t.Run("backoff with max delay", func(t *testing.T) {
+		config := &Settings{Delay: 10 * time.Millisecond}
+		WithBackoff(3, 50*time.Millisecond)(config)
+		assert.Equal(t, config.nextDelay(1), 30*time.Millisecond)
+		assert.Equal(t, config.nextDelay(2), 50*time.Millisecond)
+		assert.Equal(t, config.nextDelay(1000), 50*time.Millisecond)
+	})
+
+	t.Run("backoff strategy", func(t *testing.T) {
+		config := &Settings{Delay: 10 * time.Millisecond, Backoff: 2}
+		WithBackoffStrategy(BackoffFunc(func(attempt int) time.Duration {
+			return time.Duration(attempt+1) * 5 * time.Millisecond
+		}))(config)
+		assert.Equal(t, config.nextDelay(0), 5*time.Millisecond)
+		assert.Equal(t, config.nextDelay(3), 20*time.Millisecond)
+
+		config.MaxDelay = 12 * time.Millisecond
+		assert.Equal(t, config.nextDelay(1), 10*time.Millisecond)
+		assert.Equal(t, config.nextDelay(3), 12*time.Millisecond)
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		config := &Settings{Delay: 100 * time.Millisecond}
+		WithJitter(0.5)(config)
+		for i := 0; i < 50; i++ {
+			delay := config.nextDelay(0)
+			assert.Assert(t, delay > 50*time.Millisecond && delay <= 100*time.Millisecond, delay)
+		}
+	})
+
+	t.Run("jitter is clamped", func(t *testing.T) {
+		config := &Settings{}
+		WithJitter(4)(config)
+		assert.Equal(t, config.Jitter, 1.0)
+		WithJitter(-1)(config)
+		assert.Equal(t, config.Jitter, 0.0)
+	})
+}
+
+func TestWaitOnWithBackoff(t *testing.T) {
+	counter := 0
+	check := func(t LogT) Result {
+		if counter == 4 {
+			return Success()
+		}
+		counter++
+		return Continue("counter is at %d", counter)
+	}
+
+	WaitOn(t, check, WithDelay(time.Millisecond), WithBackoff(2, 4*time.Millisecond), WithJitter(0.5))
+	assert.Equal(t, counter, 4)
+}
+
+var _ Clock = (*clock.Fake)(nil)
+
+func TestWaitOnWithFakeClock(t *testing.T) {
+	fakeT := &fakeT{}
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Check(t, cmp.Panics(func() {
+			WaitOn(fakeT, check, WithClock(fake), WithTimeout(time.Hour), WithDelay(time.Minute))
+		}))
+	}()
+
+	// one timer for the timeout, and one for the delay after the first check
+	fake.BlockUntil(2)
+	fake.Advance(time.Minute)
+	fake.BlockUntil(2)
+	fake.Advance(time.Hour)
+	<-done
+	expected := `timeout hit after 1h0m0s: not done
+2 attempts in 1h1m0s, checks took 0s on average and 0s at most`
+	assert.Equal(t, fakeT.failed, expected)
+}
+
+type deadlineFakeT struct {
+	fakeT
+	deadline time.Time
+}
+
+func (t *deadlineFakeT) Deadline() (time.Time, bool) {
+	return t.deadline, !t.deadline.IsZero()
+}
+
+func TestSettingsTimeoutFromTestDeadline(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := defaultConfig()
+
+	t.Run("no deadline", func(t *testing.T) {
+		timeout, capped := config.timeout(&deadlineFakeT{}, now)
+		assert.Equal(t, timeout, 10*time.Second)
+		assert.Assert(t, !capped)
+	})
+
+	t.Run("deadline after timeout", func(t *testing.T) {
+		ft := &deadlineFakeT{deadline: now.Add(time.Minute)}
+		timeout, capped := config.timeout(ft, now)
+		assert.Equal(t, timeout, 10*time.Second)
+		assert.Assert(t, !capped)
+	})
+
+	t.Run("deadline before timeout", func(t *testing.T) {
+		ft := &deadlineFakeT{deadline: now.Add(5 * time.Second)}
+		timeout, capped := config.timeout(ft, now)
+		assert.Equal(t, timeout, 4*time.Second)
+		assert.Assert(t, capped)
+	})
+
+	t.Run("deadline within margin", func(t *testing.T) {
+		ft := &deadlineFakeT{deadline: now.Add(500 * time.Millisecond)}
+		timeout, capped := config.timeout(ft, now)
+		assert.Equal(t, timeout, time.Duration(0))
+		assert.Assert(t, capped)
+	})
+
+	t.Run("deadline ignored", func(t *testing.T) {
+		config := defaultConfig()
+		WithDeadlineMargin(-1)(config)
+		ft := &deadlineFakeT{deadline: now.Add(5 * time.Second)}
+		timeout, capped := config.timeout(ft, now)
+		assert.Equal(t, timeout, 10*time.Second)
+		assert.Assert(t, !capped)
+	})
+}
+
+func TestWaitOnCappedByTestDeadline(t *testing.T) {
+	ft := &deadlineFakeT{deadline: time.Now().Add(time.Second + 20*time.Millisecond)}
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	assert.Assert(t, cmp.Panics(func() { WaitOn(ft, check, WithDelay(time.Millisecond)) }))
+	assert.Assert(t, cmp.Contains(ft.failed, "before the test deadline: not done"))
+}
+
+func TestWaitOnCompareCheck(t *testing.T) {
+	counter := 0
+	WaitOn(t, CompareCheck(func() cmp.Comparison {
+		counter++
+		return cmp.Equal(counter, 3)
+	}), WithDelay(0))
+	assert.Equal(t, counter, 3)
+}
+
+func TestWaitOnCompareCheckTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	check := CompareCheck(func() cmp.Comparison {
+		return cmp.DeepEqual([]string{"a"}, []string{"b"})
+	})
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOn(fakeT, check, WithDelay(0), WithTimeout(10*time.Millisecond))
+	}))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "timeout hit after 10ms: assertion failed:"))
+	assert.Assert(t, cmp.Contains(fakeT.failed, `"a"`))
+	assert.Assert(t, cmp.Contains(fakeT.failed, `"b"`))
+}
@@ -0,0 +1,34 @@
+package poll
+
+import "time"
+
+// Progress describes the state of a wait which has not yet completed. It is
+// passed to the function set by WithProgress.
+type Progress struct {
+	// Attempts is the number of times the check has been run.
+	Attempts int
+	// Elapsed is the time since the wait started.
+	Elapsed time.Duration
+	// Message is the message from the most recent Continue result.
+	Message string
+}
+
+// WithProgress calls fn with the latest Continue message at most once every
+// interval while waiting, so that long waits can report that they are still
+// making progress.
+func WithProgress(interval time.Duration, fn func(t LogT, progress Progress)) SettingOp {
+	return func(config *Settings) {
+		config.ProgressInterval = interval
+		config.OnProgress = fn
+	}
+}
+
+// WithProgressLog logs the latest Continue message to the test log at most
+// once every interval while waiting. Use it to show liveness in CI for waits
+// which would otherwise be silent until they time out.
+func WithProgressLog(interval time.Duration) SettingOp {
+	return WithProgress(interval, func(t LogT, progress Progress) {
+		t.Logf("still waiting after %s (%d attempts): %s",
+			progress.Elapsed.Round(time.Millisecond), progress.Attempts, progress.Message)
+	})
+}
@@ -0,0 +1,73 @@
+package poll
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/clock"
+)
+
+type logT struct {
+	fakeT
+	logs []string
+}
+
+func (t *logT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func TestWaitOnWithProgress(t *testing.T) {
+	var reports []Progress
+	counter := 0
+	check := func(t LogT) Result {
+		counter++
+		if counter == 5 {
+			return Success()
+		}
+		return Continue("counter is %d", counter)
+	}
+
+	WaitOn(t, check, WithDelay(0), WithProgress(0, func(t LogT, progress Progress) {
+		reports = append(reports, progress)
+	}))
+	assert.Equal(t, len(reports), 4)
+	assert.Equal(t, reports[3].Attempts, 4)
+	assert.Equal(t, reports[3].Message, "counter is 4")
+}
+
+func TestWaitOnWithProgressLogInterval(t *testing.T) {
+	lt := &logT{}
+	fake := clock.NewFake(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	check := func(t LogT) Result {
+		return Continue("not ready")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.Check(t, cmp.Panics(func() {
+			WaitOn(lt, check,
+				WithClock(fake),
+				WithTimeout(25500*time.Millisecond),
+				WithDelay(time.Second),
+				WithProgressLog(10*time.Second))
+		}))
+	}()
+
+	for i := 0; i < 25; i++ {
+		fake.BlockUntil(2)
+		fake.Advance(time.Second)
+	}
+	// only the timeout fires, the next delay ends at 26s
+	fake.BlockUntil(2)
+	fake.Advance(500 * time.Millisecond)
+	<-done
+
+	assert.DeepEqual(t, lt.logs, []string{
+		"still waiting after 10s (11 attempts): not ready",
+		"still waiting after 20s (21 attempts): not ready",
+	})
+}
@@ -0,0 +1,45 @@
+package poll
+
+import (
+	"fmt"
+
+	gocmp "github.com/google/go-cmp/cmp"
+)
+
+// Stable returns a Check which succeeds once the value returned by get has
+// remained unchanged for count consecutive checks after the first. Values are
+// compared using go-cmp with opts. Use it to wait for something to converge or
+// settle, such as a controller reconciling state or a cache being filled.
+//
+// If get returns an error the check fails and polling stops.
+func Stable(get func() (interface{}, error), count int, opts ...gocmp.Option) Check {
+	var (
+		last   interface{}
+		seen   bool
+		stable int
+		change string
+	)
+	return func(t LogT) Result {
+		value, err := get()
+		if err != nil {
+			return Error(err)
+		}
+
+		switch {
+		case !seen:
+			seen = true
+			change = fmt.Sprintf("first value: %v", value)
+		case gocmp.Equal(last, value, opts...):
+			stable++
+		default:
+			stable = 0
+			change = "last change (-previous +current):\n" + gocmp.Diff(last, value, opts...)
+		}
+		last = value
+
+		if stable >= count {
+			return Success()
+		}
+		return Continue("value unchanged for %d of %d checks, %s", stable, count, change)
+	}
+}
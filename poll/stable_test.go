@@ -0,0 +1,60 @@
+package poll
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestWaitOnStable(t *testing.T) {
+	values := []int{1, 2, 2, 3, 3, 3, 3}
+	calls := 0
+	get := func() (interface{}, error) {
+		value := values[calls]
+		calls++
+		return value, nil
+	}
+
+	WaitOn(t, Stable(get, 3), WithDelay(0))
+	assert.Equal(t, calls, 7)
+}
+
+func TestWaitOnStableTimeout(t *testing.T) {
+	fakeT := &fakeT{}
+	counter := 0
+	get := func() (interface{}, error) {
+		counter++
+		return map[string]int{"count": counter / 2}, nil
+	}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOn(fakeT, Stable(get, 5), WithDelay(0), WithTimeout(20*time.Millisecond))
+	}))
+	assert.Assert(t, cmp.Contains(fakeT.failed, "of 5 checks, last change (-previous +current):"))
+}
+
+func TestStableMessages(t *testing.T) {
+	values := []string{"a", "a", "b"}
+	calls := 0
+	check := Stable(func() (interface{}, error) {
+		value := values[calls]
+		calls++
+		return value, nil
+	}, 2)
+
+	assert.Equal(t, check(t).Message(), "value unchanged for 0 of 2 checks, first value: a")
+	assert.Equal(t, check(t).Message(), "value unchanged for 1 of 2 checks, first value: a")
+	r := check(t)
+	assert.Assert(t, cmp.Contains(r.Message(), "value unchanged for 0 of 2 checks, last change"))
+	assert.Assert(t, cmp.Contains(r.Message(), `"b"`))
+}
+
+func TestStableError(t *testing.T) {
+	check := Stable(func() (interface{}, error) {
+		return nil, errors.New("broke")
+	}, 2)
+	assert.Error(t, check(t).Error(), "broke")
+}
@@ -0,0 +1,63 @@
+package poll
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats are the attempt and timing metrics of a wait. Use WithStats to
+// receive them from WaitOn. They are also included in the failure message
+// when polling stops before the check succeeds.
+type Stats struct {
+	// Attempts is the number of checks which completed.
+	Attempts int
+	// Elapsed is the time from the start of the wait until it stopped.
+	Elapsed time.Duration
+	// CheckDurations is the time taken by each completed check, in order.
+	CheckDurations []time.Duration
+}
+
+// MaxCheckDuration returns the duration of the slowest completed check.
+func (s Stats) MaxCheckDuration() time.Duration {
+	var max time.Duration
+	for _, d := range s.CheckDurations {
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// AverageCheckDuration returns the mean duration of the completed checks.
+func (s Stats) AverageCheckDuration() time.Duration {
+	if len(s.CheckDurations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range s.CheckDurations {
+		total += d
+	}
+	return total / time.Duration(len(s.CheckDurations))
+}
+
+func (s Stats) String() string {
+	attempts := "attempts"
+	if s.Attempts == 1 {
+		attempts = "attempt"
+	}
+	return fmt.Sprintf("%d %s in %s, checks took %s on average and %s at most",
+		s.Attempts, attempts, roundDuration(s.Elapsed),
+		roundDuration(s.AverageCheckDuration()), roundDuration(s.MaxCheckDuration()))
+}
+
+// roundDuration rounds d to a precision which is useful in a failure message.
+func roundDuration(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
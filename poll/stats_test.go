@@ -0,0 +1,62 @@
+package poll
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+// withoutStats removes the line with the Stats from a failure message.
+func withoutStats(message string) string {
+	if i := strings.LastIndex(message, "\n"); i >= 0 {
+		return message[:i]
+	}
+	return message
+}
+
+func TestStats(t *testing.T) {
+	stats := Stats{
+		Attempts:       3,
+		Elapsed:        1234567 * time.Microsecond,
+		CheckDurations: []time.Duration{time.Millisecond, 5 * time.Millisecond, 3 * time.Millisecond},
+	}
+	assert.Equal(t, stats.MaxCheckDuration(), 5*time.Millisecond)
+	assert.Equal(t, stats.AverageCheckDuration(), 3*time.Millisecond)
+	assert.Equal(t, stats.String(), "3 attempts in 1.235s, checks took 3ms on average and 5ms at most")
+
+	assert.Equal(t, Stats{}.AverageCheckDuration(), time.Duration(0))
+}
+
+func TestWaitOnWithStats(t *testing.T) {
+	var stats Stats
+	counter := 0
+	check := func(t LogT) Result {
+		counter++
+		if counter == 3 {
+			return Success()
+		}
+		return Continue("not yet")
+	}
+
+	WaitOn(t, check, WithDelay(0), WithStats(&stats))
+	assert.Equal(t, stats.Attempts, 3)
+	assert.Equal(t, len(stats.CheckDurations), 3)
+	assert.Assert(t, stats.Elapsed >= stats.MaxCheckDuration())
+}
+
+func TestWaitOnTimeoutIncludesStats(t *testing.T) {
+	fakeT := &fakeT{}
+	var stats Stats
+	check := func(t LogT) Result {
+		return Continue("not done")
+	}
+
+	assert.Assert(t, cmp.Panics(func() {
+		WaitOn(fakeT, check, WithTimeout(time.Millisecond), WithStats(&stats))
+	}))
+	assert.Equal(t, stats.Attempts, 1)
+	assert.Assert(t, cmp.Contains(fakeT.failed, "\n1 attempt in "))
+}
@@ -0,0 +1,46 @@
+//go:build go1.18
+// +build go1.18
+
+package poll
+
+// WaitFor polls check until it returns done, and returns the value from that
+// call. It accepts the same settings as WaitOn.
+//
+// check returns a value, an error, and whether polling is done:
+//
+//   - done with a nil error stops polling and WaitFor returns the value.
+//   - done with a non-nil error stops polling and WaitFor returns the error.
+//   - not done continues polling. A non-nil error is used as the failure
+//     message if the timeout is reached.
+//
+// Unlike WaitOn, WaitFor does not fail the test. The returned error describes
+// why polling stopped, so that the caller can decide how to handle it.
+func WaitFor[T any](t LogT, check func(t LogT) (T, error, bool), pollOps ...SettingOp) (T, error) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	config := newConfig(t, pollOps)
+
+	// values is buffered so that a check which completes after the timeout does
+	// not block.
+	values := make(chan T, 1)
+	err := wait(t, func(t LogT) Result {
+		value, err, done := check(t)
+		switch {
+		case done && err != nil:
+			return Error(err)
+		case done:
+			values <- value
+			return Success()
+		case err != nil:
+			return Continue("%w", err)
+		default:
+			return Continue("value is %v", value)
+		}
+	}, config)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return <-values, nil
+}
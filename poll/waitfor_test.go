@@ -0,0 +1,66 @@
+//go:build go1.18
+// +build go1.18
+
+package poll
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestWaitFor(t *testing.T) {
+	counter := 0
+	check := func(t LogT) (int, error, bool) {
+		counter++
+		return counter, nil, counter == 3
+	}
+
+	value, err := WaitFor(t, check, WithDelay(0))
+	assert.NilError(t, err)
+	assert.Equal(t, value, 3)
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	check := func(t LogT) (string, error, bool) {
+		return "", fmt.Errorf("not ready"), false
+	}
+
+	value, err := WaitFor(t, check, WithTimeout(time.Millisecond))
+	assert.Equal(t, withoutStats(err.Error()), "timeout hit after 1ms: not ready")
+	assert.Equal(t, value, "")
+}
+
+func TestWaitForTimeoutWithoutError(t *testing.T) {
+	check := func(t LogT) (int, error, bool) {
+		return 7, nil, false
+	}
+
+	_, err := WaitFor(t, check, WithTimeout(time.Millisecond))
+	assert.Equal(t, withoutStats(err.Error()), "timeout hit after 1ms: value is 7")
+}
+
+func TestWaitForCheckError(t *testing.T) {
+	errBroke := errors.New("broke")
+	check := func(t LogT) (int, error, bool) {
+		return 0, errBroke, true
+	}
+
+	_, err := WaitFor(t, check)
+	assert.Error(t, err, "polling check failed: broke")
+	assert.Assert(t, errors.Is(err, errBroke))
+}
+
+func TestWaitForTimeoutPreservesCause(t *testing.T) {
+	errNotReady := errors.New("not ready")
+	check := func(t LogT) (int, error, bool) {
+		return 0, errNotReady, false
+	}
+
+	_, err := WaitFor(t, check, WithTimeout(time.Millisecond))
+	assert.Assert(t, errors.Is(err, ErrTimeout))
+	assert.Assert(t, errors.Is(err, errNotReady))
+}
@@ -0,0 +1,111 @@
+package promassert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/internal/format"
+)
+
+// HasMetric succeeds if families contains the metric name with the labels.
+// The labels are optional, and a metric may have other labels.
+func HasMetric(families Families, name string, labels Labels) cmp.Comparison {
+	return func() cmp.Result {
+		family, _ := families.lookup(name)
+		if family == nil {
+			return cmp.ResultFailure(fmt.Sprintf("metric %s not found, found metrics: %s",
+				name, strings.Join(families.Names(), ", ")))
+		}
+		if len(matching(family, labels)) > 0 {
+			return cmp.ResultSuccess
+		}
+		return cmp.ResultFailure(fmt.Sprintf("metric %s with labels %s not found, found:\n%s",
+			name, formatLabels(labels), describeMetrics(family)))
+	}
+}
+
+func describeMetrics(family *dto.MetricFamily) string {
+	lines := make([]string, 0, len(family.GetMetric()))
+	for _, metric := range family.GetMetric() {
+		lines = append(lines, "  "+family.GetName()+metricLabels(metric))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Value succeeds if exactly one metric name matches the labels, and its value
+// is within tolerance of expected. Use a tolerance of 0 to require the exact
+// value.
+//
+// The value of a counter, gauge, or untyped metric is compared. For a
+// histogram or summary use the name with a _count or _sum suffix to compare the
+// number of observations or their sum.
+func Value(families Families, name string, labels Labels, expected, tolerance float64) cmp.Comparison {
+	return func() cmp.Result {
+		family, suffix := families.lookup(name)
+		if family == nil {
+			return cmp.ResultFailure(fmt.Sprintf("metric %s not found, found metrics: %s",
+				name, strings.Join(families.Names(), ", ")))
+		}
+		metrics := matching(family, labels)
+		switch len(metrics) {
+		case 0:
+			return cmp.ResultFailure(fmt.Sprintf("metric %s with labels %s not found, found:\n%s",
+				name, formatLabels(labels), describeMetrics(family)))
+		case 1:
+		default:
+			return cmp.ResultFailure(fmt.Sprintf("labels %s match %d metrics %s, expected 1:\n%s",
+				formatLabels(labels), len(metrics), name, describeMetrics(family)))
+		}
+		actual, err := value(family, metrics[0], suffix)
+		if err != nil {
+			return cmp.ResultFromError(err)
+		}
+		if math.Abs(actual-expected) <= tolerance {
+			return cmp.ResultSuccess
+		}
+		msg := fmt.Sprintf("metric %s%s is %v, expected %v", name, metricLabels(metrics[0]), actual, expected)
+		if tolerance > 0 {
+			msg += fmt.Sprintf(" ± %v", tolerance)
+		}
+		return cmp.ResultFailure(msg)
+	}
+}
+
+// Equal succeeds if the metric families with the names are the same in
+// families and in expected, which is in the Prometheus text format. If no
+// names are given, every family in expected is compared. The failure message
+// is a diff of the families in the text format.
+//
+// Example:
+//
+//	assert.Assert(t, promassert.Equal(families, `
+//	# HELP requests_total Total requests.
+//	# TYPE requests_total counter
+//	requests_total{code="200"} 3
+//	`, "requests_total"))
+func Equal(families Families, expected string, names ...string) cmp.Comparison {
+	return func() cmp.Result {
+		expectedFamilies, err := Parse(strings.NewReader(strings.TrimLeft(expected, "\n\t ")))
+		if err != nil {
+			return cmp.ResultFailure(fmt.Sprintf("failed to parse expected metrics: %s", err))
+		}
+		if len(names) == 0 {
+			names = expectedFamilies.Names()
+		}
+		want := formatFamilies(expectedFamilies, names)
+		got := formatFamilies(families, names)
+		if want == got {
+			return cmp.ResultSuccess
+		}
+		diff := format.UnifiedDiff(format.DiffConfig{
+			A:    want,
+			B:    got,
+			From: "expected",
+			To:   "actual",
+		})
+		return cmp.ResultFailure("metrics do not match:\n" + diff)
+	}
+}
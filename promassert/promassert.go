@@ -0,0 +1,198 @@
+/*
+Package promassert provides comparisons for Prometheus metrics, gathered from
+a prometheus.Gatherer or scraped from a /metrics endpoint.
+
+The package is a separate module, so that the Prometheus dependencies are only
+required by the projects which use it.
+*/
+package promassert // import "gotest.tools/v3/promassert"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"gotest.tools/v3/assert"
+)
+
+type helperT interface {
+	Helper()
+}
+
+// Families are metric families by name.
+type Families map[string]*dto.MetricFamily
+
+// Labels are the names and values of labels used to select metrics. A metric
+// matches if it has every one of the labels, other labels are ignored.
+type Labels map[string]string
+
+// Gather collects the metric families from g. The test fails if g returns an
+// error.
+func Gather(t assert.TestingT, g prometheus.Gatherer) Families {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	families, err := g.Gather()
+	assert.NilError(t, err, "failed to gather metrics")
+	return byName(families)
+}
+
+// Scrape fetches the metrics from url, usually the /metrics endpoint of a
+// server, and parses them in the Prometheus text format. The test fails if
+// the request fails, the status is not 200 OK, or the metrics can not be
+// parsed.
+func Scrape(t assert.TestingT, url string) Families {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NilError(t, err)
+	req.Header.Set("Accept", "text/plain;version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	assert.NilError(t, err, "failed to scrape metrics")
+	defer resp.Body.Close() //nolint: errcheck
+	assert.Equal(t, resp.StatusCode, http.StatusOK, "scrape %s returned %s", url, resp.Status)
+
+	families, err := Parse(resp.Body)
+	assert.NilError(t, err, "failed to parse metrics from %s", url)
+	return families
+}
+
+// Parse reads metric families in the Prometheus text format.
+func Parse(r io.Reader) (Families, error) {
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	return Families(families), nil
+}
+
+func byName(families []*dto.MetricFamily) Families {
+	result := make(Families, len(families))
+	for _, family := range families {
+		result[family.GetName()] = family
+	}
+	return result
+}
+
+// Names returns the names of the families, sorted.
+func (f Families) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String returns the families in the Prometheus text format.
+func (f Families) String() string {
+	return formatFamilies(f, f.Names())
+}
+
+func formatFamilies(f Families, names []string) string {
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		family, ok := f[name]
+		if !ok {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(buf, family); err != nil {
+			fmt.Fprintf(buf, "# failed to format %s: %s\n", name, err)
+		}
+	}
+	return buf.String()
+}
+
+// lookup returns the family of the metric name, and the kind of value to read
+// from it. The name of a histogram or summary may have a _count, _sum, or
+// _bucket suffix.
+func (f Families) lookup(name string) (*dto.MetricFamily, string) {
+	if family, ok := f[name]; ok {
+		return family, ""
+	}
+	for _, suffix := range []string{"_count", "_sum"} {
+		if family, ok := f[strings.TrimSuffix(name, suffix)]; ok && strings.HasSuffix(name, suffix) {
+			switch family.GetType() {
+			case dto.MetricType_HISTOGRAM, dto.MetricType_SUMMARY, dto.MetricType_GAUGE_HISTOGRAM:
+				return family, suffix
+			}
+		}
+	}
+	return nil, ""
+}
+
+func matching(family *dto.MetricFamily, labels Labels) []*dto.Metric {
+	var result []*dto.Metric
+	for _, metric := range family.GetMetric() {
+		if hasLabels(metric, labels) {
+			result = append(result, metric)
+		}
+	}
+	return result
+}
+
+func hasLabels(metric *dto.Metric, labels Labels) bool {
+	actual := make(map[string]string, len(metric.GetLabel()))
+	for _, pair := range metric.GetLabel() {
+		actual[pair.GetName()] = pair.GetValue()
+	}
+	for name, value := range labels {
+		if actualValue, ok := actual[name]; !ok || actualValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func formatLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func metricLabels(metric *dto.Metric) string {
+	labels := make(map[string]string, len(metric.GetLabel()))
+	for _, pair := range metric.GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	return formatLabels(labels)
+}
+
+// value returns the value of a counter, gauge, or untyped metric, or the
+// sample count or sum of a histogram or summary.
+func value(family *dto.MetricFamily, metric *dto.Metric, suffix string) (float64, error) {
+	switch {
+	case metric.Counter != nil:
+		return metric.Counter.GetValue(), nil
+	case metric.Gauge != nil:
+		return metric.Gauge.GetValue(), nil
+	case metric.Untyped != nil:
+		return metric.Untyped.GetValue(), nil
+	case metric.Histogram != nil && suffix == "_count":
+		return float64(metric.Histogram.GetSampleCount()), nil
+	case metric.Histogram != nil && suffix == "_sum":
+		return metric.Histogram.GetSampleSum(), nil
+	case metric.Summary != nil && suffix == "_count":
+		return float64(metric.Summary.GetSampleCount()), nil
+	case metric.Summary != nil && suffix == "_sum":
+		return metric.Summary.GetSampleSum(), nil
+	}
+	return 0, fmt.Errorf("metric %s is a %s, use the %s_count or %s_sum name to compare its value",
+		family.GetName(), strings.ToLower(family.GetType().String()), family.GetName(), family.GetName())
+}
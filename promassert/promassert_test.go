@@ -0,0 +1,128 @@
+package promassert
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func failureMessage(t *testing.T, comparison cmp.Comparison) string {
+	t.Helper()
+	result := comparison()
+	assert.Assert(t, !result.Success(), "expected failure")
+	return result.(interface{ FailureMessage() string }).FailureMessage()
+}
+
+func newRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "requests_total",
+		Help: "Total requests.",
+	}, []string{"code", "method"})
+	requests.WithLabelValues("200", "GET").Add(3)
+	requests.WithLabelValues("500", "GET").Inc()
+	requests.WithLabelValues("200", "POST").Add(2)
+
+	temperature := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "temperature",
+		Help: "Current temperature.",
+	})
+	temperature.Set(21.4)
+
+	latency := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "latency_seconds",
+		Help:    "Request latency.",
+		Buckets: []float64{0.1, 1},
+	})
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+
+	reg.MustRegister(requests, temperature, latency)
+	return reg
+}
+
+func TestGather(t *testing.T) {
+	families := Gather(t, newRegistry())
+	assert.DeepEqual(t, families.Names(),
+		[]string{"latency_seconds", "requests_total", "temperature"})
+}
+
+func TestScrape(t *testing.T) {
+	reg := newRegistry()
+	srv := httptest.NewServer(promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	defer srv.Close()
+
+	families := Scrape(t, srv.URL)
+	assert.DeepEqual(t, families.Names(),
+		[]string{"latency_seconds", "requests_total", "temperature"})
+	assert.Check(t, Value(families, "requests_total", Labels{"code": "200", "method": "GET"}, 3, 0))
+}
+
+func TestHasMetric(t *testing.T) {
+	families := Gather(t, newRegistry())
+	assert.Check(t, HasMetric(families, "requests_total", nil))
+	assert.Check(t, HasMetric(families, "requests_total", Labels{"code": "500"}))
+	assert.Check(t, HasMetric(families, "latency_seconds_count", nil))
+
+	assert.Equal(t, failureMessage(t, HasMetric(families, "errors_total", nil)),
+		"metric errors_total not found, found metrics: latency_seconds, requests_total, temperature")
+	assert.Equal(t, failureMessage(t, HasMetric(families, "requests_total", Labels{"code": "404"})),
+		`metric requests_total with labels {code="404"} not found, found:
+  requests_total{code="200", method="GET"}
+  requests_total{code="200", method="POST"}
+  requests_total{code="500", method="GET"}`)
+}
+
+func TestValue(t *testing.T) {
+	families := Gather(t, newRegistry())
+	assert.Check(t, Value(families, "requests_total", Labels{"code": "500"}, 1, 0))
+	assert.Check(t, Value(families, "temperature", nil, 21, 0.5))
+	assert.Check(t, Value(families, "latency_seconds_count", nil, 2, 0))
+	assert.Check(t, Value(families, "latency_seconds_sum", nil, 0.55, 1e-9))
+
+	assert.Equal(t, failureMessage(t, Value(families, "temperature", nil, 20, 0.5)),
+		"metric temperature{} is 21.4, expected 20 ± 0.5")
+	assert.Equal(t, failureMessage(t, Value(families, "requests_total", Labels{"method": "POST"}, 1, 0)),
+		`metric requests_total{code="200", method="POST"} is 2, expected 1`)
+	assert.Equal(t, failureMessage(t, Value(families, "requests_total", Labels{"code": "200"}, 1, 0)),
+		`labels {code="200"} match 2 metrics requests_total, expected 1:
+  requests_total{code="200", method="GET"}
+  requests_total{code="200", method="POST"}
+  requests_total{code="500", method="GET"}`)
+	assert.Equal(t, failureMessage(t, Value(families, "latency_seconds", nil, 1, 0)),
+		"metric latency_seconds is a histogram, use the latency_seconds_count or latency_seconds_sum name to compare its value")
+}
+
+func TestEqual(t *testing.T) {
+	families := Gather(t, newRegistry())
+	assert.Check(t, Equal(families, `
+# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{code="200",method="GET"} 3
+requests_total{code="200",method="POST"} 2
+requests_total{code="500",method="GET"} 1
+`))
+
+	msg := failureMessage(t, Equal(families, `
+# HELP temperature Current temperature.
+# TYPE temperature gauge
+temperature 19
+`, "temperature"))
+	assert.Equal(t, msg, `metrics do not match:
+--- expected
++++ actual
+@@ -1,4 +1,4 @@
+ # HELP temperature Current temperature.
+ # TYPE temperature gauge
+-temperature 19
++temperature 21.4
+ 
+`)
+
+	msg = failureMessage(t, Equal(families, "not metrics"))
+	assert.Assert(t, cmp.Contains(msg, "failed to parse expected metrics"))
+}
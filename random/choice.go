@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package random
+
+import "math/rand"
+
+// Choice returns one of options. It panics if options is empty.
+//
+// Choice requires Go 1.18+.
+func Choice[T any](r *rand.Rand, options ...T) T {
+	if len(options) == 0 {
+		panic("random: no options")
+	}
+	return options[r.Intn(len(options))]
+}
+
+// Shuffled returns a copy of values in a random order.
+//
+// Shuffled requires Go 1.18+.
+func Shuffled[T any](r *rand.Rand, values []T) []T {
+	result := make([]T, len(values))
+	copy(result, values)
+	r.Shuffle(len(result), func(i, j int) {
+		result[i], result[j] = result[j], result[i]
+	})
+	return result
+}
@@ -0,0 +1,31 @@
+//go:build go1.18
+// +build go1.18
+
+package random
+
+import (
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestChoice(t *testing.T) {
+	r := New(t)
+	options := []string{"red", "green", "blue"}
+	for i := 0; i < 20; i++ {
+		assert.Assert(t, is.Contains(options, Choice(r, options...)))
+	}
+	assert.Assert(t, is.Panics(func() { Choice[int](r) }))
+}
+
+func TestShuffled(t *testing.T) {
+	r := New(t)
+	values := []int{1, 2, 3, 4, 5}
+	shuffled := Shuffled(r, values)
+	assert.DeepEqual(t, values, []int{1, 2, 3, 4, 5})
+
+	sort.Ints(shuffled)
+	assert.DeepEqual(t, shuffled, values)
+}
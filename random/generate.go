@@ -0,0 +1,61 @@
+package random
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Alphanumeric is the alphabet used by String.
+const Alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// String returns a string of n characters from Alphanumeric.
+func String(r *rand.Rand, n int) string {
+	return StringFrom(r, n, Alphanumeric)
+}
+
+// StringFrom returns a string of n characters from alphabet. It panics if
+// alphabet is empty.
+func StringFrom(r *rand.Rand, n int, alphabet string) string {
+	runes := []rune(alphabet)
+	result := make([]rune, n)
+	for i := range result {
+		result[i] = runes[r.Intn(len(runes))]
+	}
+	return string(result)
+}
+
+// Bytes returns n random bytes.
+func Bytes(r *rand.Rand, n int) []byte {
+	result := make([]byte, n)
+	r.Read(result) //nolint: errcheck
+	return result
+}
+
+// IntBetween returns an int in the range [min, max]. It panics if max is less
+// than min.
+func IntBetween(r *rand.Rand, min, max int) int {
+	if max < min {
+		panic("random: max is less than min")
+	}
+	return min + int(r.Int63n(int64(max)-int64(min)+1))
+}
+
+// Duration returns a duration in the range [min, max). It returns min if max
+// is not greater than min.
+func Duration(r *rand.Rand, min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(r.Int63n(int64(max-min)))
+}
+
+// Time returns a time in the range [min, max), in the location of min. It
+// returns min if max is not after min.
+func Time(r *rand.Rand, min, max time.Time) time.Time {
+	return min.Add(Duration(r, 0, max.Sub(min)))
+}
+
+// Bool returns true or false.
+func Bool(r *rand.Rand) bool {
+	return r.Intn(2) == 1
+}
@@ -0,0 +1,113 @@
+/*
+Package random provides a source of random values for a test which can be
+reproduced when the test fails, and functions which use it to generate common
+test data.
+
+Each test gets a seed. The seed is read from the GOTESTTOOLS_SEED env var if it
+is set, otherwise a new seed is generated for the test. When the test fails the
+seed is logged, so that the failure can be reproduced by running the test again
+with the env var set to the seed:
+
+	GOTESTTOOLS_SEED=1699999999 go test -run TestName ./...
+*/
+package random // import "gotest.tools/v3/random"
+
+import (
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gotest.tools/v3/cleanup"
+)
+
+// SeedEnvVar is the name of the environment variable which sets the seed of
+// every test.
+const SeedEnvVar = "GOTESTTOOLS_SEED"
+
+// TestingT is the subset of testing.T used by the functions in this package.
+type TestingT interface {
+	Log(args ...interface{})
+	Failed() bool
+	Fatalf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+var seeds = struct {
+	sync.Mutex
+	byTest map[TestingT]int64
+}{byTest: map[TestingT]int64{}}
+
+var seedCount int64
+
+// Seed returns the seed of the test. The first call for a test reads the
+// seed from SeedEnvVar, or generates a new seed, and arranges for the seed to
+// be logged if the test fails. Later calls return the same seed.
+//
+// The seed is only logged on failure with Go 1.14+. With older versions of Go
+// it is logged immediately.
+func Seed(t TestingT) int64 {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	seeds.Lock()
+	defer seeds.Unlock()
+	if seed, ok := seeds.byTest[t]; ok {
+		return seed
+	}
+
+	seed := time.Now().UnixNano() + atomic.AddInt64(&seedCount, 1)
+	if value := os.Getenv(SeedEnvVar); value != "" {
+		var err error
+		seed, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			t.Fatalf("invalid %s=%q: %s", SeedEnvVar, value, err)
+		}
+	}
+	seeds.byTest[t] = seed
+
+	logSeed := func() {
+		t.Log(formatSeed(seed))
+	}
+	// Cleanups run in the reverse of the order they are registered, so the
+	// seed is forgotten after it is logged.
+	switch typed := t.(type) {
+	case interface{ Cleanup(func()) }:
+		typed.Cleanup(func() { forgetSeed(t) })
+		cleanup.OnFailure(t, logSeed)
+	case interface{ AddCleanup(func()) }:
+		typed.AddCleanup(func() { forgetSeed(t) })
+		cleanup.OnFailure(t, logSeed)
+	default:
+		logSeed()
+	}
+	return seed
+}
+
+func forgetSeed(t TestingT) {
+	seeds.Lock()
+	defer seeds.Unlock()
+	delete(seeds.byTest, t)
+}
+
+func formatSeed(seed int64) string {
+	return "random seed: " + strconv.FormatInt(seed, 10) +
+		" (set " + SeedEnvVar + "=" + strconv.FormatInt(seed, 10) + " to reproduce)"
+}
+
+// New returns a source of random values seeded with the Seed of the test.
+// Every call for the same test returns a new source which produces the same
+// sequence of values.
+//
+// The returned source is not safe for concurrent use.
+func New(t TestingT) *rand.Rand {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return rand.New(rand.NewSource(Seed(t)))
+}
@@ -0,0 +1,163 @@
+package random
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+)
+
+type fakeT struct {
+	failed   bool
+	logs     []string
+	cleanups []func()
+}
+
+func (t *fakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *fakeT) Failed() bool {
+	return t.failed
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.failed = true
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestSeed_FromEnv(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "12345")()
+
+	first, second := &fakeT{}, &fakeT{}
+	assert.Equal(t, Seed(first), int64(12345))
+	assert.Equal(t, New(first).Int63(), New(second).Int63())
+}
+
+func TestSeed_Generated(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "")()
+
+	first, second := &fakeT{}, &fakeT{}
+	assert.Assert(t, Seed(first) != Seed(second))
+	assert.Equal(t, Seed(first), Seed(first))
+}
+
+func TestSeed_LoggedOnFailure(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "42")()
+
+	passed := &fakeT{}
+	Seed(passed)
+	passed.runCleanups()
+	assert.Equal(t, len(passed.logs), 0)
+
+	failed := &fakeT{}
+	Seed(failed)
+	failed.failed = true
+	failed.runCleanups()
+	assert.DeepEqual(t, failed.logs,
+		[]string{"random seed: 42 (set GOTESTTOOLS_SEED=42 to reproduce)"})
+}
+
+func TestSeed_ForgottenAfterCleanup(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "42")()
+
+	fakeT := &fakeT{}
+	Seed(fakeT)
+	fakeT.failed = true
+	fakeT.runCleanups()
+	assert.Equal(t, len(fakeT.logs), 1)
+
+	seeds.Lock()
+	defer seeds.Unlock()
+	_, ok := seeds.byTest[fakeT]
+	assert.Assert(t, !ok, "expected the seed to be deleted")
+}
+
+type noCleanupT struct {
+	logs []string
+}
+
+func (t *noCleanupT) Log(args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprint(args...))
+}
+
+func (t *noCleanupT) Failed() bool {
+	return false
+}
+
+func (t *noCleanupT) Fatalf(format string, args ...interface{}) {}
+
+func TestSeed_LoggedWithoutCleanup(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "7")()
+
+	fakeT := &noCleanupT{}
+	Seed(fakeT)
+	assert.DeepEqual(t, fakeT.logs,
+		[]string{"random seed: 7 (set GOTESTTOOLS_SEED=7 to reproduce)"})
+}
+
+func TestSeed_InvalidEnv(t *testing.T) {
+	defer env.Patch(t, SeedEnvVar, "not-a-number")()
+
+	fakeT := &fakeT{}
+	Seed(fakeT)
+	assert.Assert(t, fakeT.failed)
+	assert.Assert(t, is.Contains(fakeT.logs[0], `invalid GOTESTTOOLS_SEED="not-a-number"`))
+}
+
+func TestString(t *testing.T) {
+	r := New(t)
+	s := String(r, 20)
+	assert.Equal(t, len(s), 20)
+	for _, c := range s {
+		assert.Assert(t, strings.ContainsRune(Alphanumeric, c), "unexpected %q", c)
+	}
+
+	assert.Equal(t, StringFrom(r, 5, "é"), "ééééé")
+}
+
+func TestBytes(t *testing.T) {
+	assert.Equal(t, len(Bytes(New(t), 16)), 16)
+}
+
+func TestIntBetween(t *testing.T) {
+	r := New(t)
+	for i := 0; i < 100; i++ {
+		n := IntBetween(r, -2, 2)
+		assert.Assert(t, n >= -2 && n <= 2, "got %d", n)
+	}
+	assert.Equal(t, IntBetween(r, 3, 3), 3)
+	assert.Assert(t, is.Panics(func() { IntBetween(r, 3, 2) }))
+}
+
+func TestTime(t *testing.T) {
+	r := New(t)
+	min := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	max := min.Add(24 * time.Hour)
+	for i := 0; i < 100; i++ {
+		value := Time(r, min, max)
+		assert.Assert(t, !value.Before(min) && value.Before(max), "got %s", value)
+	}
+	assert.Equal(t, Time(r, max, min), max)
+}
+
+func TestDuration(t *testing.T) {
+	r := New(t)
+	value := Duration(r, time.Second, 2*time.Second)
+	assert.Assert(t, value >= time.Second && value < 2*time.Second, "got %s", value)
+	assert.Equal(t, Duration(r, time.Second, 0), time.Second)
+}
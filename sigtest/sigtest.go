@@ -0,0 +1,136 @@
+/*
+Package sigtest sends OS signals to the test process, or to a command started
+by icmd, and checks that the signal handlers ran, to test graceful shutdown
+and other signal handling code.
+
+Signals are delivered to the whole process, so tests which use this package
+must not run in parallel with other tests which send or handle the same
+signals.
+*/
+package sigtest // import "gotest.tools/v3/sigtest"
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"gotest.tools/v3/icmd"
+)
+
+// TestingT is the subset of testing.T used by the functions in this package.
+type TestingT interface {
+	Cleanup(func())
+	Fatalf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+// pollInterval is how often Handled checks if the handler has run.
+const pollInterval = 5 * time.Millisecond
+
+// Notify relays the signals sigs to the returned channel, like signal.Notify,
+// and stops relaying them when the test ends. The channel is buffered, so
+// signals which are not received right away are not dropped.
+func Notify(t TestingT, sigs ...os.Signal) <-chan os.Signal {
+	ch := make(chan os.Signal, 16)
+	signal.Notify(ch, sigs...)
+	t.Cleanup(func() {
+		signal.Stop(ch)
+	})
+	return ch
+}
+
+// Guard prevents the signals sigs from terminating the test process until
+// the test ends. Without a guard, a signal such as os.Interrupt which is sent
+// before the code under test calls signal.Notify, or after it calls
+// signal.Stop, would stop the test binary.
+//
+// The guard does not change how the signals are delivered to the handlers
+// registered by the code under test. A call to signal.Reset or signal.Ignore
+// for one of the signals removes the guard.
+func Guard(t TestingT, sigs ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	t.Cleanup(func() {
+		signal.Stop(ch)
+	})
+}
+
+// Send sends the signal sig to the test process. A Guard is registered for
+// sig first, so that the test process is not terminated if no handler is
+// registered.
+//
+// The signal is delivered asynchronously. Use Handled to wait for the handler
+// to run.
+func Send(t TestingT, sig os.Signal) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	Guard(t, sig)
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find the test process: %v", err)
+		return
+	}
+	if err := process.Signal(sig); err != nil {
+		t.Fatalf("failed to send signal %v to the test process: %v", sig, err)
+	}
+}
+
+// SendCmd sends the signal sig to a command started by icmd.StartCmd. Use
+// icmd.WaitOnCmd to wait for the command to exit, and icmd.Expected to check
+// how it exited.
+func SendCmd(t TestingT, result *icmd.Result, sig os.Signal) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if err := result.Signal(sig); err != nil {
+		t.Fatalf("failed to send signal %v to %v: %v", sig, result.Command, err)
+	}
+}
+
+// Handled sends the signal sig to the test process, and waits for handled to
+// return true. The test fails if handled does not return true within
+// timeout. handled is usually a check that the shutdown of the code under
+// test has completed.
+//
+// Example:
+//
+//	srv := startServer(t) // calls signal.Notify(ch, os.Interrupt)
+//	sigtest.Handled(t, os.Interrupt, srv.Stopped, time.Second)
+func Handled(t TestingT, sig os.Signal, handled func() bool, timeout time.Duration) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	Send(t, sig)
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for !handled() {
+		if time.Now().After(deadline) {
+			t.Fatalf("signal %v was not handled within %v", sig, timeout)
+			return
+		}
+		<-ticker.C
+	}
+}
+
+// HandledCmd sends the signal sig to a command started by icmd.StartCmd, and
+// waits for the command to exit. The test fails if the command does not exit
+// within timeout, in which case the command is killed. The result is returned
+// so that the output and exit code can be checked with Result.Assert.
+func HandledCmd(t TestingT, result *icmd.Result, sig os.Signal, timeout time.Duration) *icmd.Result {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	SendCmd(t, result, sig)
+	icmd.WaitOnCmd(timeout, result)
+	if result.Timeout {
+		t.Fatalf("command %v did not exit within %v after signal %v\n%s",
+			result.Command, timeout, sig, result.Combined())
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package sigtest
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+	"gotest.tools/v3/poll"
+	"gotest.tools/v3/skip"
+)
+
+type fakeT struct {
+	cleanups []func()
+	msg      string
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.msg = fmt.Sprintf(format, args...)
+}
+
+func (t *fakeT) runCleanups() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestNotify(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt can not be sent on windows")
+	ch := Notify(t, os.Interrupt)
+	Send(t, os.Interrupt)
+
+	select {
+	case sig := <-ch:
+		assert.Equal(t, sig, os.Interrupt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal was not received")
+	}
+}
+
+func TestHandled(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt can not be sent on windows")
+	var stopped int32
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt)
+	defer signal.Stop(ch)
+	go func() {
+		<-ch
+		atomic.StoreInt32(&stopped, 1)
+	}()
+
+	Handled(t, os.Interrupt, func() bool {
+		return atomic.LoadInt32(&stopped) == 1
+	}, 5*time.Second)
+}
+
+func TestHandledTimeout(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "os.Interrupt can not be sent on windows")
+	ft := &fakeT{}
+	defer ft.runCleanups()
+
+	Handled(ft, os.Interrupt, func() bool { return false }, 20*time.Millisecond)
+	assert.Equal(t, ft.msg, "signal interrupt was not handled within 20ms")
+}
+
+func TestHandledCmd(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires a unix shell")
+	script := `trap 'echo stopping; exit 0' TERM; echo ready; while true; do sleep 0.01; done`
+	result := icmd.StartCmd(icmd.Command("sh", "-c", script))
+	assert.NilError(t, result.Error)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if strings.Contains(result.Stdout(), "ready") {
+			return poll.Success()
+		}
+		return poll.Continue("command is not ready")
+	}, poll.WithDelay(10*time.Millisecond))
+
+	HandledCmd(t, result, syscall.SIGTERM, 5*time.Second).
+		Assert(t, icmd.Expected{Out: "stopping"})
+}
+
+func TestHandledCmdTimeout(t *testing.T) {
+	skip.If(t, runtime.GOOS == "windows", "requires a unix shell")
+	script := `trap '' TERM; echo ready; while true; do sleep 0.01; done`
+	result := icmd.StartCmd(icmd.Command("sh", "-c", script))
+	assert.NilError(t, result.Error)
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if strings.Contains(result.Stdout(), "ready") {
+			return poll.Success()
+		}
+		return poll.Continue("command is not ready")
+	}, poll.WithDelay(10*time.Millisecond))
+
+	ft := &fakeT{}
+	HandledCmd(ft, result, syscall.SIGTERM, 50*time.Millisecond)
+	assert.Assert(t, strings.HasPrefix(ft.msg,
+		"command [sh -c "+script+"] did not exit within 50ms after signal terminated"), ft.msg)
+}
@@ -0,0 +1,118 @@
+package skip
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfBinaryMissing skips the test if any of binaries can not be found in PATH.
+// The skip message lists every binary which is missing. A binary which is found
+// is cached for each value of PATH, a missing binary is looked up again by the
+// next call.
+func IfBinaryMissing(t skipT, binaries ...string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	var missing []string
+	for _, binary := range binaries {
+		if err := lookPath(binary); err != nil {
+			missing = append(missing, binary)
+		}
+	}
+	switch len(missing) {
+	case 0:
+	case 1:
+		skipTest(t, fmt.Sprintf("binary %s not found in PATH", missing[0]))
+	default:
+		skipTest(t, fmt.Sprintf("binaries %s not found in PATH", strings.Join(missing, ", ")))
+	}
+}
+
+// IfBinaryNotInPath skips the test if binary can not be found in PATH. Like
+// IfBinaryMissing, a binary which is found is cached for each value of PATH.
+func IfBinaryNotInPath(t skipT, binary string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if err := lookPath(binary); err != nil {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("binary %s not found in PATH", binary), msgAndArgs...))
+	}
+}
+
+var binaryLookups = &probeCache{
+	entries:     make(map[string]*probeEntry),
+	retryErrors: true,
+}
+
+func lookPath(binary string) error {
+	return binaryLookups.probe(binary+"\x00"+os.Getenv("PATH"), func() error {
+		_, err := exec.LookPath(binary)
+		return err
+	})
+}
+
+// IfBinaryVersionBelow skips the test if binary can not be found in PATH, or
+// if the version printed by "binary --version" is lower than minVersion. The
+// version is the first dotted number in the output, such as 2.34.1 in
+// "git version 2.34.1". Versions are compared by each numeric component.
+func IfBinaryVersionBelow(t skipT, binary, minVersion string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("binary %s not found in PATH", binary), msgAndArgs...))
+		return
+	}
+
+	out, err := exec.Command(path, "--version").CombinedOutput()
+	if err != nil {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("failed to get version of %s: %s", binary, err), msgAndArgs...))
+		return
+	}
+	version := versionPattern.FindString(string(out))
+	if version == "" {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("failed to find version of %s in output: %q", binary, out), msgAndArgs...))
+		return
+	}
+	if compareVersions(version, minVersion) < 0 {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("%s version %s is lower than %s", binary, version, minVersion), msgAndArgs...))
+	}
+}
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// compareVersions returns -1, 0, or 1 if a is lower, equal, or greater than b.
+// Missing components are treated as 0.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := versionComponent(as, i), versionComponent(bs, i)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionComponent(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
@@ -0,0 +1,118 @@
+package skip
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+func TestIfBinaryMissing(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfBinaryMissing(skipT, "go")
+	assert.Equal(t, skipT.reason, "")
+
+	IfBinaryMissing(skipT, "go", "gotesttools-missing-1")
+	assert.Equal(t, skipT.reason, "binary gotesttools-missing-1 not found in PATH")
+
+	IfBinaryMissing(skipT, "gotesttools-missing-1", "gotesttools-missing-2")
+	assert.Equal(t, skipT.reason,
+		"binaries gotesttools-missing-1, gotesttools-missing-2 not found in PATH")
+}
+
+func TestIfBinaryNotInPath(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfBinaryNotInPath(skipT, "go")
+	assert.Equal(t, skipT.reason, "")
+
+	IfBinaryNotInPath(skipT, "gotesttools-missing-1", "needed to %s", "build")
+	assert.Equal(t, skipT.reason, "binary gotesttools-missing-1 not found in PATH: needed to build")
+}
+
+func TestIfBinaryVersionBelow(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the binary")
+	}
+	dir := fs.NewDir(t, "test-binary-version",
+		fs.WithFile("tool", "#!/bin/sh\necho 'tool version 2.10.3 (build abc)'\n", fs.WithMode(0755)),
+		fs.WithFile("noversion", "#!/bin/sh\necho 'no version here'\n", fs.WithMode(0755)))
+	defer dir.Remove()
+	tool := filepath.Join(dir.Path(), "tool")
+
+	skipT := &fakeSkipT{}
+	IfBinaryVersionBelow(skipT, tool, "2.9")
+	assert.Equal(t, skipT.reason, "")
+	IfBinaryVersionBelow(skipT, tool, "2.10.3")
+	assert.Equal(t, skipT.reason, "")
+
+	IfBinaryVersionBelow(skipT, tool, "2.10.4", "needs --frob")
+	assert.Equal(t, skipT.reason, tool+" version 2.10.3 is lower than 2.10.4: needs --frob")
+
+	IfBinaryVersionBelow(skipT, filepath.Join(dir.Path(), "noversion"), "1.0")
+	assert.Assert(t, cmp.Contains(skipT.reason, `failed to find version`))
+
+	IfBinaryVersionBelow(skipT, "gotesttools-missing-1", "1.0")
+	assert.Equal(t, skipT.reason, "binary gotesttools-missing-1 not found in PATH")
+}
+
+func TestCompareVersions(t *testing.T) {
+	var testcases = []struct {
+		a, b     string
+		expected int
+	}{
+		{a: "1.2.3", b: "1.2.3", expected: 0},
+		{a: "1.2", b: "1.2.0", expected: 0},
+		{a: "1.10", b: "1.9", expected: 1},
+		{a: "1.9.9", b: "1.10", expected: -1},
+		{a: "2", b: "1.99", expected: 1},
+	}
+	for _, tc := range testcases {
+		assert.Equal(t, compareVersions(tc.a, tc.b), tc.expected, "%s vs %s", tc.a, tc.b)
+	}
+}
+
+func TestIfBinaryMissingCachesLookup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the binary")
+	}
+	dir := fs.NewDir(t, "test-binary-cache",
+		fs.WithFile("gotesttools-cached", "#!/bin/sh\n", fs.WithMode(0755)))
+	defer dir.Remove()
+	defer env.Patch(t, "PATH", dir.Path())()
+
+	skipT := &fakeSkipT{}
+	IfBinaryMissing(skipT, "gotesttools-cached")
+	assert.Equal(t, skipT.reason, "")
+
+	assert.NilError(t, os.Remove(dir.Join("gotesttools-cached")))
+	IfBinaryMissing(skipT, "gotesttools-cached")
+	assert.Equal(t, skipT.reason, "", "expected cached result")
+
+	defer env.Patch(t, "PATH", dir.Path()+string(os.PathListSeparator))()
+	IfBinaryMissing(skipT, "gotesttools-cached")
+	assert.Equal(t, skipT.reason, "binary gotesttools-cached not found in PATH")
+}
+
+func TestIfBinaryMissingDoesNotCacheMissingBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the binary")
+	}
+	dir := fs.NewDir(t, "test-binary-retry")
+	defer dir.Remove()
+	defer env.Patch(t, "PATH", dir.Path())()
+
+	skipT := &fakeSkipT{}
+	IfBinaryMissing(skipT, "gotesttools-later")
+	assert.Equal(t, skipT.reason, "binary gotesttools-later not found in PATH")
+
+	assert.NilError(t, ioutil.WriteFile(dir.Join("gotesttools-later"), []byte("#!/bin/sh\n"), 0755))
+	skipT = &fakeSkipT{}
+	IfBinaryMissing(skipT, "gotesttools-later")
+	assert.Equal(t, skipT.reason, "")
+}
@@ -0,0 +1,58 @@
+package skip
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfRaceDetector skips the test if the test binary was built with -race.
+func IfRaceDetector(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if raceEnabled {
+		skipTest(t, format.WithCustomMessage("race detector is enabled", msgAndArgs...))
+	}
+}
+
+// ShortBudgetEnvVar is the name of the environment variable used to override
+// ShortBudget. The value is parsed with time.ParseDuration.
+const ShortBudgetEnvVar = "GOTESTTOOLS_SHORT_BUDGET"
+
+// ShortBudget is the longest expected duration of a test which is run when
+// tests are run with -short. See IfOverShortBudget.
+var ShortBudget = time.Second
+
+// isShort is replaced in tests.
+var isShort = testing.Short
+
+// IfOverShortBudget skips the test if the tests are run with -short, and
+// expected, the expected duration of the test, is longer than the budget. The
+// budget is ShortBudget, or the duration in the environment variable
+// GOTESTTOOLS_SHORT_BUDGET when it is set. IfOverShortBudget panics if the
+// environment variable is not a valid duration.
+func IfOverShortBudget(t skipT, expected time.Duration, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !isShort() {
+		return
+	}
+	budget := ShortBudget
+	if value := os.Getenv(ShortBudgetEnvVar); value != "" {
+		var err error
+		budget, err = time.ParseDuration(value)
+		if err != nil {
+			panic(fmt.Sprintf("invalid %s: %s", ShortBudgetEnvVar, err))
+		}
+	}
+	if expected > budget {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("expected duration %s is over the -short budget of %s", expected, budget),
+			msgAndArgs...))
+	}
+}
@@ -0,0 +1,65 @@
+package skip
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+)
+
+func TestIfRaceDetector(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfRaceDetector(skipT, "too slow")
+	if raceEnabled {
+		assert.Equal(t, skipT.reason, "race detector is enabled: too slow")
+		return
+	}
+	assert.Equal(t, skipT.reason, "")
+}
+
+func withShort(short bool) func() {
+	orig := isShort
+	isShort = func() bool { return short }
+	return func() { isShort = orig }
+}
+
+func TestIfOverShortBudget(t *testing.T) {
+	defer env.Patch(t, ShortBudgetEnvVar, "")()
+
+	t.Run("not short", func(t *testing.T) {
+		defer withShort(false)()
+		skipT := &fakeSkipT{}
+		IfOverShortBudget(skipT, time.Hour)
+		assert.Equal(t, skipT.reason, "")
+	})
+
+	t.Run("within budget", func(t *testing.T) {
+		defer withShort(true)()
+		skipT := &fakeSkipT{}
+		IfOverShortBudget(skipT, 500*time.Millisecond)
+		assert.Equal(t, skipT.reason, "")
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		defer withShort(true)()
+		skipT := &fakeSkipT{}
+		IfOverShortBudget(skipT, 2*time.Second)
+		assert.Equal(t, skipT.reason, "expected duration 2s is over the -short budget of 1s")
+	})
+
+	t.Run("budget from env", func(t *testing.T) {
+		defer withShort(true)()
+		defer env.Patch(t, ShortBudgetEnvVar, "5s")()
+		skipT := &fakeSkipT{}
+		IfOverShortBudget(skipT, 2*time.Second)
+		assert.Equal(t, skipT.reason, "")
+	})
+
+	t.Run("invalid budget from env", func(t *testing.T) {
+		defer withShort(true)()
+		defer env.Patch(t, ShortBudgetEnvVar, "soon")()
+		assert.Assert(t, cmp.Panics(func() { IfOverShortBudget(&fakeSkipT{}, time.Second) }))
+	})
+}
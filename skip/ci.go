@@ -0,0 +1,64 @@
+package skip
+
+import (
+	"os"
+	"strings"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// ciProviders maps an environment variable to the name of the CI provider
+// which sets it. The list is checked in order, so the more specific variables
+// come before the generic CI variable.
+var ciProviders = []struct {
+	env  string
+	name string
+}{
+	{env: "GITHUB_ACTIONS", name: "GitHub Actions"},
+	{env: "GITLAB_CI", name: "GitLab CI"},
+	{env: "JENKINS_URL", name: "Jenkins"},
+	{env: "CIRCLECI", name: "CircleCI"},
+	{env: "TRAVIS", name: "Travis CI"},
+	{env: "BUILDKITE", name: "Buildkite"},
+	{env: "TF_BUILD", name: "Azure Pipelines"},
+	{env: "TEAMCITY_VERSION", name: "TeamCity"},
+	{env: "APPVEYOR", name: "AppVeyor"},
+	{env: "BITBUCKET_BUILD_NUMBER", name: "Bitbucket Pipelines"},
+	{env: "DRONE", name: "Drone"},
+	{env: "CODEBUILD_BUILD_ID", name: "AWS CodeBuild"},
+	{env: "CI", name: "CI"},
+}
+
+// DetectCI returns the name of the CI provider the test is running on, and
+// true if the test is running on CI. It recognizes the environment variables
+// set by common CI providers, and the CI variable which is set by most others.
+func DetectCI() (string, bool) {
+	for _, provider := range ciProviders {
+		value := os.Getenv(provider.env)
+		if value == "" || strings.EqualFold(value, "false") || value == "0" {
+			continue
+		}
+		return provider.name, true
+	}
+	return "", false
+}
+
+// IfCI skips the test if it is running on CI. See DetectCI.
+func IfCI(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if name, ok := DetectCI(); ok {
+		skipTest(t, format.WithCustomMessage("running on "+name, msgAndArgs...))
+	}
+}
+
+// UnlessCI skips the test unless it is running on CI. See DetectCI.
+func UnlessCI(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if _, ok := DetectCI(); !ok {
+		skipTest(t, format.WithCustomMessage("not running on CI", msgAndArgs...))
+	}
+}
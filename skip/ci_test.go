@@ -0,0 +1,64 @@
+package skip
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+)
+
+// withoutCI unsets every variable used to detect CI.
+func withoutCI(t *testing.T) func() {
+	var restore []func()
+	for _, provider := range ciProviders {
+		restore = append(restore, env.Patch(t, provider.env, ""))
+	}
+	return func() {
+		for _, f := range restore {
+			f()
+		}
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	defer withoutCI(t)()
+
+	_, ok := DetectCI()
+	assert.Assert(t, !ok)
+
+	defer env.Patch(t, "CI", "false")()
+	_, ok = DetectCI()
+	assert.Assert(t, !ok)
+
+	defer env.Patch(t, "CI", "true")()
+	name, ok := DetectCI()
+	assert.Assert(t, ok)
+	assert.Equal(t, name, "CI")
+
+	defer env.Patch(t, "GITLAB_CI", "true")()
+	name, _ = DetectCI()
+	assert.Equal(t, name, "GitLab CI")
+}
+
+func TestIfCI(t *testing.T) {
+	defer withoutCI(t)()
+	skipT := &fakeSkipT{}
+	IfCI(skipT)
+	assert.Equal(t, skipT.reason, "")
+
+	defer env.Patch(t, "GITHUB_ACTIONS", "true")()
+	IfCI(skipT, "needs a display")
+	assert.Equal(t, skipT.reason, "running on GitHub Actions: needs a display")
+}
+
+func TestUnlessCI(t *testing.T) {
+	defer withoutCI(t)()
+	skipT := &fakeSkipT{}
+	UnlessCI(skipT)
+	assert.Equal(t, skipT.reason, "not running on CI")
+
+	skipT = &fakeSkipT{}
+	defer env.Patch(t, "JENKINS_URL", "https://jenkins.example.com")()
+	UnlessCI(skipT)
+	assert.Equal(t, skipT.reason, "")
+}
@@ -0,0 +1,52 @@
+package skip
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// DaemonProbeTimeout is the timeout for the connection made by
+// IfDaemonUnavailable.
+var DaemonProbeTimeout = 2 * time.Second
+
+var daemonProbes = newProbeCache()
+
+// IfDaemonUnavailable skips the test unless a daemon accepts connections at
+// address. address is the path to a unix socket, or a URL with a unix:// or
+// tcp:// scheme, such as tcp://localhost:5432. The result of the probe is
+// cached for each address, so only the first call in a test binary waits for
+// the connection.
+//
+// Use IfDockerUnavailable to check that a container runtime responds to
+// requests.
+func IfDaemonUnavailable(t skipT, address string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	err := daemonProbes.probe(address, func() error {
+		return dialDaemon(address)
+	})
+	if err != nil {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("daemon is not available at %s: %s", address, err), msgAndArgs...))
+	}
+}
+
+func dialDaemon(address string) error {
+	network, addr := "unix", address
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		addr = strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+	}
+	conn, err := net.DialTimeout(network, addr, DaemonProbeTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
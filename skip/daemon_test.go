@@ -0,0 +1,64 @@
+package skip
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/fs"
+)
+
+func TestIfDaemonUnavailable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer l.Close()
+
+	skipT := &fakeSkipT{}
+	IfDaemonUnavailable(skipT, "tcp://"+l.Addr().String())
+	assert.Equal(t, skipT.reason, "")
+}
+
+func TestIfDaemonUnavailableUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix socket")
+	}
+	dir := fs.NewDir(t, "test-daemon")
+	defer dir.Remove()
+	socket := dir.Join("daemon.sock")
+	l, err := net.Listen("unix", socket)
+	assert.NilError(t, err)
+	defer l.Close()
+
+	skipT := &fakeSkipT{}
+	IfDaemonUnavailable(skipT, socket)
+	assert.Equal(t, skipT.reason, "")
+	IfDaemonUnavailable(skipT, "unix://"+socket)
+	assert.Equal(t, skipT.reason, "")
+
+	missing := filepath.Join(dir.Path(), "missing.sock")
+	IfDaemonUnavailable(skipT, missing, "requires the daemon")
+	assert.Assert(t, cmp.Contains(skipT.reason, "daemon is not available at "+missing+": "))
+	assert.Assert(t, cmp.Contains(skipT.reason, ": requires the daemon"))
+}
+
+func TestIfDaemonUnavailableCachesProbe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	address := "tcp://" + l.Addr().String()
+	assert.NilError(t, l.Close())
+
+	skipT := &fakeSkipT{}
+	IfDaemonUnavailable(skipT, address)
+	assert.Assert(t, cmp.Contains(skipT.reason, "daemon is not available at "+address))
+
+	l, err = net.Listen("tcp", l.Addr().String())
+	assert.NilError(t, err)
+	defer l.Close()
+
+	skipT = &fakeSkipT{}
+	IfDaemonUnavailable(skipT, address)
+	assert.Assert(t, skipT.reason != "", "expected cached result")
+}
@@ -0,0 +1,104 @@
+package skip
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// ContainerProbeTimeout is the timeout for each connection made by
+// IfDockerUnavailable.
+var ContainerProbeTimeout = 2 * time.Second
+
+var containerProbe struct {
+	sync.Mutex
+	done bool
+	err  error
+}
+
+// IfDockerUnavailable skips the test unless a docker or podman API is
+// reachable. The address from DOCKER_HOST is used if it is set, otherwise the
+// default docker and podman sockets are tried. A runtime is usable if it
+// responds to a ping. The result of the probe is cached for the test binary.
+func IfDockerUnavailable(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	containerProbe.Lock()
+	if !containerProbe.done {
+		containerProbe.err = probeContainerRuntime()
+		containerProbe.done = true
+	}
+	err := containerProbe.err
+	containerProbe.Unlock()
+
+	if err != nil {
+		skipTest(t, format.WithCustomMessage("container runtime is not available: "+err.Error(), msgAndArgs...))
+	}
+}
+
+func containerRuntimeAddresses() []string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return []string{host}
+	}
+	addresses := []string{"unix:///var/run/docker.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		addresses = append(addresses, "unix://"+filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return append(addresses, "unix:///run/podman/podman.sock")
+}
+
+func probeContainerRuntime() error {
+	var failures []string
+	for _, address := range containerRuntimeAddresses() {
+		err := pingContainerRuntime(address)
+		if err == nil {
+			return nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %s", address, err))
+	}
+	return fmt.Errorf("%s", strings.Join(failures, "; "))
+}
+
+func pingContainerRuntime(address string) error {
+	var network, addr string
+	switch {
+	case strings.HasPrefix(address, "unix://"):
+		network, addr = "unix", strings.TrimPrefix(address, "unix://")
+	case strings.HasPrefix(address, "tcp://"):
+		network, addr = "tcp", strings.TrimPrefix(address, "tcp://")
+	default:
+		return fmt.Errorf("unsupported address")
+	}
+
+	client := &http.Client{
+		Timeout: ContainerProbeTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				dialer := net.Dialer{Timeout: ContainerProbeTimeout}
+				return dialer.DialContext(ctx, network, addr)
+			},
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get("http://container-runtime/_ping")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+	_, _ = ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ping returned status %d", resp.StatusCode)
+	}
+	return nil
+}
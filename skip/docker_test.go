@@ -0,0 +1,66 @@
+package skip
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+)
+
+func resetContainerProbe() {
+	containerProbe.Lock()
+	defer containerProbe.Unlock()
+	containerProbe.done = false
+	containerProbe.err = nil
+}
+
+func TestIfDockerUnavailable(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Check(t, cmp.Equal(r.URL.Path, "/_ping"))
+		w.Write([]byte("OK")) // nolint: errcheck
+	}))
+	defer srv.Close()
+	defer env.Patch(t, "DOCKER_HOST", "tcp://"+srv.Listener.Addr().String())()
+	resetContainerProbe()
+	defer resetContainerProbe()
+
+	skipT := &fakeSkipT{}
+	IfDockerUnavailable(skipT)
+	assert.Equal(t, skipT.reason, "")
+}
+
+func TestIfDockerUnavailableSkips(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	address := "tcp://" + l.Addr().String()
+	assert.NilError(t, l.Close())
+	defer env.Patch(t, "DOCKER_HOST", address)()
+	resetContainerProbe()
+	defer resetContainerProbe()
+
+	skipT := &fakeSkipT{}
+	IfDockerUnavailable(skipT, "needs postgres")
+	assert.Assert(t, strings.HasPrefix(skipT.reason, "container runtime is not available: "+address+": "))
+	assert.Assert(t, strings.HasSuffix(skipT.reason, ": needs postgres"))
+}
+
+func TestIfDockerUnavailableBadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	address := "tcp://" + srv.Listener.Addr().String()
+	defer env.Patch(t, "DOCKER_HOST", address)()
+	resetContainerProbe()
+	defer resetContainerProbe()
+
+	skipT := &fakeSkipT{}
+	IfDockerUnavailable(skipT)
+	assert.Equal(t, skipT.reason,
+		"container runtime is not available: "+address+": ping returned status 500")
+}
@@ -0,0 +1,91 @@
+package skip
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfEnv skips the test if the environment variable name is set to a non-empty
+// value. The skip message includes the name and value of the variable.
+func IfEnv(t skipT, name string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if value := os.Getenv(name); value != "" {
+		skipTest(t, format.WithCustomMessage(fmt.Sprintf("env %s=%q is set", name, value), msgAndArgs...))
+	}
+}
+
+// UnlessEnv skips the test unless the environment variable name is set to a
+// non-empty value.
+func UnlessEnv(t skipT, name string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if os.Getenv(name) == "" {
+		skipTest(t, format.WithCustomMessage(fmt.Sprintf("env %s is not set", name), msgAndArgs...))
+	}
+}
+
+// IfEnvMatch skips the test if the value of the environment variable name
+// matches the glob pattern. See path.Match for the pattern syntax. An unset
+// variable matches as an empty string.
+func IfEnvMatch(t skipT, name, pattern string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	value := os.Getenv(name)
+	matched, err := path.Match(pattern, value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %s", pattern, err))
+	}
+	if matched {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("env %s=%q matches %q", name, value, pattern), msgAndArgs...))
+	}
+}
+
+// UnlessEnvMatch skips the test unless the value of the environment variable
+// name matches the glob pattern. See path.Match for the pattern syntax.
+func UnlessEnvMatch(t skipT, name, pattern string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	value := os.Getenv(name)
+	matched, err := path.Match(pattern, value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid pattern %q: %s", pattern, err))
+	}
+	if !matched {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("env %s=%q does not match %q", name, value, pattern), msgAndArgs...))
+	}
+}
+
+// IfEnvRegexp skips the test if the value of the environment variable name
+// matches re.
+func IfEnvRegexp(t skipT, name string, re *regexp.Regexp, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if value := os.Getenv(name); re.MatchString(value) {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("env %s=%q matches /%s/", name, value, re), msgAndArgs...))
+	}
+}
+
+// UnlessEnvRegexp skips the test unless the value of the environment variable
+// name matches re.
+func UnlessEnvRegexp(t skipT, name string, re *regexp.Regexp, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if value := os.Getenv(name); !re.MatchString(value) {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("env %s=%q does not match /%s/", name, value, re), msgAndArgs...))
+	}
+}
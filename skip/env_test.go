@@ -0,0 +1,79 @@
+package skip
+
+import (
+	"regexp"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+	"gotest.tools/v3/env"
+)
+
+const envName = "GOTESTTOOLS_SKIP_TEST_VAR"
+
+func TestIfEnv(t *testing.T) {
+	defer env.Patch(t, envName, "")()
+	skipT := &fakeSkipT{}
+	IfEnv(skipT, envName)
+	assert.Equal(t, skipT.reason, "")
+
+	defer env.Patch(t, envName, "true")()
+	IfEnv(skipT, envName, "not in %s", "CI")
+	assert.Equal(t, skipT.reason, `env GOTESTTOOLS_SKIP_TEST_VAR="true" is set: not in CI`)
+}
+
+func TestUnlessEnv(t *testing.T) {
+	defer env.Patch(t, envName, "1")()
+	skipT := &fakeSkipT{}
+	UnlessEnv(skipT, envName)
+	assert.Equal(t, skipT.reason, "")
+
+	defer env.Patch(t, envName, "")()
+	UnlessEnv(skipT, envName)
+	assert.Equal(t, skipT.reason, "env GOTESTTOOLS_SKIP_TEST_VAR is not set")
+}
+
+func TestIfEnvMatch(t *testing.T) {
+	defer env.Patch(t, envName, "release-1.2")()
+	skipT := &fakeSkipT{}
+	IfEnvMatch(skipT, envName, "main*")
+	assert.Equal(t, skipT.reason, "")
+
+	IfEnvMatch(skipT, envName, "release-*")
+	assert.Equal(t, skipT.reason, `env GOTESTTOOLS_SKIP_TEST_VAR="release-1.2" matches "release-*"`)
+}
+
+func TestUnlessEnvMatch(t *testing.T) {
+	defer env.Patch(t, envName, "release-1.2")()
+	skipT := &fakeSkipT{}
+	UnlessEnvMatch(skipT, envName, "release-*")
+	assert.Equal(t, skipT.reason, "")
+
+	UnlessEnvMatch(skipT, envName, "main")
+	assert.Equal(t, skipT.reason, `env GOTESTTOOLS_SKIP_TEST_VAR="release-1.2" does not match "main"`)
+}
+
+func TestEnvMatchInvalidPattern(t *testing.T) {
+	skipT := &fakeSkipT{}
+	assert.Assert(t, cmp.Panics(func() { IfEnvMatch(skipT, envName, "[") }))
+}
+
+func TestIfEnvRegexp(t *testing.T) {
+	defer env.Patch(t, envName, "v1.14.2")()
+	skipT := &fakeSkipT{}
+	IfEnvRegexp(skipT, envName, regexp.MustCompile(`^v2\.`))
+	assert.Equal(t, skipT.reason, "")
+
+	IfEnvRegexp(skipT, envName, regexp.MustCompile(`^v1\.1[0-9]\.`))
+	assert.Equal(t, skipT.reason, `env GOTESTTOOLS_SKIP_TEST_VAR="v1.14.2" matches /^v1\.1[0-9]\./`)
+}
+
+func TestUnlessEnvRegexp(t *testing.T) {
+	defer env.Patch(t, envName, "v1.14.2")()
+	skipT := &fakeSkipT{}
+	UnlessEnvRegexp(skipT, envName, regexp.MustCompile(`^v1\.`))
+	assert.Equal(t, skipT.reason, "")
+
+	UnlessEnvRegexp(skipT, envName, regexp.MustCompile(`^v2\.`))
+	assert.Equal(t, skipT.reason, `env GOTESTTOOLS_SKIP_TEST_VAR="v1.14.2" does not match /^v2\./`)
+}
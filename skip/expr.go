@@ -0,0 +1,196 @@
+package skip
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfExpr skips the test if the build-constraint-like expression evaluates to
+// true. The expression uses the syntax of a //go:build line, with the
+// operators &&, ||, ! and parentheses. The terms are:
+//
+//   - the value of runtime.GOOS or runtime.GOARCH, and unix on unix systems
+//   - race, when the test binary was built with -race
+//   - cgo, when cgo is enabled
+//   - short, when the test is run with -short
+//   - go1.N, when the Go version of the test binary is at least 1.N
+//
+// Any other term is false. IfExpr panics if expr is not a valid expression.
+// The skip message contains the expression.
+func IfExpr(t skipT, expr string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	result, err := evalExpr(expr, exprTerm)
+	if err != nil {
+		panic(fmt.Sprintf("invalid skip expression %q: %s", expr, err))
+	}
+	if result {
+		skipTest(t, format.WithCustomMessage(expr, msgAndArgs...))
+	}
+}
+
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true,
+	"freebsd": true, "hurd": true, "illumos": true, "ios": true, "linux": true,
+	"netbsd": true, "openbsd": true, "solaris": true,
+}
+
+func exprTerm(term string) bool {
+	switch term {
+	case runtime.GOOS, runtime.GOARCH:
+		return true
+	case "unix":
+		return unixOS[runtime.GOOS]
+	case "race":
+		return raceEnabled
+	case "cgo":
+		return cgoEnabled
+	case "short":
+		return testing.Short()
+	}
+	if strings.HasPrefix(term, "go1.") {
+		minor, err := strconv.Atoi(strings.TrimPrefix(term, "go1."))
+		return err == nil && goMinorVersion() >= minor
+	}
+	return false
+}
+
+// goMinorVersion returns the minor version of the Go release used to build
+// the test binary, or a large number for a development version.
+func goMinorVersion() int {
+	version := runtime.Version()
+	if !strings.HasPrefix(version, "go1.") {
+		return 1 << 30
+	}
+	version = strings.TrimPrefix(version, "go1.")
+	end := strings.IndexFunc(version, func(r rune) bool { return !unicode.IsDigit(r) })
+	if end >= 0 {
+		version = version[:end]
+	}
+	minor, _ := strconv.Atoi(version)
+	return minor
+}
+
+// evalExpr evaluates a build constraint expression, using term to find the
+// value of each term.
+func evalExpr(expr string, term func(string) bool) (bool, error) {
+	p := &exprParser{tokens: tokenizeExpr(expr), term: term}
+	if len(p.tokens) == 0 {
+		return false, fmt.Errorf("empty expression")
+	}
+	result, err := p.or()
+	if err != nil {
+		return false, err
+	}
+	if p.pos < len(p.tokens) {
+		return false, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return result, nil
+}
+
+func tokenizeExpr(expr string) []string {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '!':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		default:
+			end := i + 1
+			for end < len(expr) && !strings.ContainsRune(" \t()!&|", rune(expr[end])) {
+				end++
+			}
+			tokens = append(tokens, expr[i:end])
+			i = end
+		}
+	}
+	return tokens
+}
+
+type exprParser struct {
+	tokens []string
+	pos    int
+	term   func(string) bool
+}
+
+func (p *exprParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *exprParser) or() (bool, error) {
+	result, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var next bool
+		next, err = p.and()
+		result = result || next
+	}
+	return result, err
+}
+
+func (p *exprParser) and() (bool, error) {
+	result, err := p.not()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var next bool
+		next, err = p.not()
+		result = result && next
+	}
+	return result, err
+}
+
+func (p *exprParser) not() (bool, error) {
+	switch token := p.peek(); token {
+	case "!":
+		p.pos++
+		result, err := p.not()
+		return !result, err
+	case "(":
+		p.pos++
+		result, err := p.or()
+		if err != nil {
+			return false, err
+		}
+		if p.peek() != ")" {
+			return false, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return result, nil
+	case "", ")", "&&", "||":
+		if token == "" {
+			return false, fmt.Errorf("unexpected end of expression")
+		}
+		return false, fmt.Errorf("unexpected %q", token)
+	default:
+		if !isExprTerm(token) {
+			return false, fmt.Errorf("invalid term %q", token)
+		}
+		p.pos++
+		return p.term(token), nil
+	}
+}
+
+func isExprTerm(token string) bool {
+	for _, r := range token {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package skip
+
+import (
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestEvalExpr(t *testing.T) {
+	terms := map[string]bool{"a": true, "b": false, "go1.5": true}
+	term := func(name string) bool { return terms[name] }
+
+	var testcases = []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: "a", expected: true},
+		{expr: "!a", expected: false},
+		{expr: "a && b", expected: false},
+		{expr: "a || b", expected: true},
+		{expr: "!(a && b)", expected: true},
+		{expr: "b || !b && a", expected: true},
+		{expr: "(b || !b) && !a", expected: false},
+		{expr: "go1.5&&!unknown", expected: true},
+	}
+	for _, tc := range testcases {
+		result, err := evalExpr(tc.expr, term)
+		assert.NilError(t, err, tc.expr)
+		assert.Equal(t, result, tc.expected, tc.expr)
+	}
+}
+
+func TestEvalExprInvalid(t *testing.T) {
+	term := func(string) bool { return true }
+	var testcases = []struct {
+		expr     string
+		expected string
+	}{
+		{expr: "", expected: "empty expression"},
+		{expr: "a &&", expected: "unexpected end of expression"},
+		{expr: "(a", expected: "missing )"},
+		{expr: "a b", expected: `unexpected "b"`},
+		{expr: "a & b", expected: `unexpected "&"`},
+		{expr: "a-b", expected: `invalid term "a-b"`},
+		{expr: "|| a", expected: `unexpected "||"`},
+	}
+	for _, tc := range testcases {
+		_, err := evalExpr(tc.expr, term)
+		assert.Error(t, err, tc.expected, tc.expr)
+	}
+}
+
+func TestIfExpr(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfExpr(skipT, "!"+runtime.GOOS)
+	assert.Equal(t, skipT.reason, "")
+
+	expr := runtime.GOOS + " && " + runtime.GOARCH + " && go1.1"
+	IfExpr(skipT, expr, "known issue")
+	assert.Equal(t, skipT.reason, expr+": known issue")
+}
+
+func TestIfExprRace(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfExpr(skipT, "race")
+	assert.Equal(t, skipT.reason != "", raceEnabled)
+}
+
+func TestIfExprInvalid(t *testing.T) {
+	assert.Assert(t, cmp.Panics(func() { IfExpr(&fakeSkipT{}, "linux &&") }))
+}
@@ -0,0 +1,44 @@
+package skip
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// NetworkProbeAddress is the address dialed by IfNoNetwork to find out if
+// outbound network access is available. It must be a host:port which accepts
+// TCP connections. Using a host name also requires DNS to work.
+var NetworkProbeAddress = "proxy.golang.org:443"
+
+// NetworkProbeTimeout is the timeout for the connection made by IfNoNetwork.
+var NetworkProbeTimeout = 3 * time.Second
+
+var networkProbes = newProbeCache()
+
+// IfNoNetwork skips the test if a TCP connection can not be opened to
+// NetworkProbeAddress. The result of the probe is cached for each address, so
+// only the first call in a test binary waits for the connection.
+func IfNoNetwork(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	address := NetworkProbeAddress
+	if err := probeNetwork(address); err != nil {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("network is not available: failed to connect to %s: %s", address, err),
+			msgAndArgs...))
+	}
+}
+
+func probeNetwork(address string) error {
+	return networkProbes.probe(address, func() error {
+		conn, err := net.DialTimeout("tcp", address, NetworkProbeTimeout)
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err
+	})
+}
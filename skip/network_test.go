@@ -0,0 +1,53 @@
+package skip
+
+import (
+	"net"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func withProbeAddress(address string) func() {
+	orig := NetworkProbeAddress
+	NetworkProbeAddress = address
+	return func() { NetworkProbeAddress = orig }
+}
+
+func TestIfNoNetwork(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer l.Close()
+	defer withProbeAddress(l.Addr().String())()
+
+	skipT := &fakeSkipT{}
+	IfNoNetwork(skipT)
+	assert.Equal(t, skipT.reason, "")
+}
+
+func TestIfNoNetworkUnavailable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	address := l.Addr().String()
+	assert.NilError(t, l.Close())
+	defer withProbeAddress(address)()
+
+	skipT := &fakeSkipT{}
+	IfNoNetwork(skipT, "downloads modules")
+	assert.Assert(t, cmp.Contains(skipT.reason,
+		"network is not available: failed to connect to "+address))
+	assert.Assert(t, cmp.Contains(skipT.reason, ": downloads modules"))
+}
+
+func TestIfNoNetworkCachesProbe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	defer withProbeAddress(l.Addr().String())()
+
+	skipT := &fakeSkipT{}
+	IfNoNetwork(skipT)
+	assert.NilError(t, l.Close())
+
+	IfNoNetwork(skipT)
+	assert.Equal(t, skipT.reason, "", "expected cached result")
+}
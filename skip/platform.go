@@ -0,0 +1,47 @@
+package skip
+
+import (
+	"runtime"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfOS skips the test if it is running on one of the operating systems in
+// goos. The values are compared to runtime.GOOS.
+func IfOS(t skipT, goos ...string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	for _, name := range goos {
+		if runtime.GOOS == name {
+			skipTest(t, "running on "+name)
+			return
+		}
+	}
+}
+
+// IfArch skips the test if it is running on one of the architectures in arch.
+// The values are compared to runtime.GOARCH.
+func IfArch(t skipT, arch ...string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	for _, name := range arch {
+		if runtime.GOARCH == name {
+			skipTest(t, "running on "+name)
+			return
+		}
+	}
+}
+
+// IfNotRoot skips the test unless it is running with elevated privileges. On
+// unix that is an effective user id of 0. On Windows that is a process with an
+// elevated token, such as one started with "Run as administrator".
+func IfNotRoot(t skipT, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !isPrivileged() {
+		skipTest(t, format.WithCustomMessage("not running as "+privilegedUser, msgAndArgs...))
+	}
+}
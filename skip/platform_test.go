@@ -0,0 +1,36 @@
+package skip
+
+import (
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIfOS(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfOS(skipT, "plan9-not-real")
+	assert.Equal(t, skipT.reason, "")
+
+	IfOS(skipT, "plan9-not-real", runtime.GOOS)
+	assert.Equal(t, skipT.reason, "running on "+runtime.GOOS)
+}
+
+func TestIfArch(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfArch(skipT)
+	assert.Equal(t, skipT.reason, "")
+
+	IfArch(skipT, runtime.GOARCH)
+	assert.Equal(t, skipT.reason, "running on "+runtime.GOARCH)
+}
+
+func TestIfNotRoot(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfNotRoot(skipT, "needs to bind port %d", 80)
+	if isPrivileged() {
+		assert.Equal(t, skipT.reason, "")
+		return
+	}
+	assert.Equal(t, skipT.reason, "not running as "+privilegedUser+": needs to bind port 80")
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package skip
+
+import "os"
+
+const privilegedUser = "root"
+
+func isPrivileged() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,29 @@
+package skip
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const privilegedUser = "administrator"
+
+// tokenElevation is the TOKEN_INFORMATION_CLASS value for TokenElevation.
+const tokenElevation = 20
+
+func isPrivileged() bool {
+	process, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return false
+	}
+	var token syscall.Token
+	if err := syscall.OpenProcessToken(process, syscall.TOKEN_QUERY, &token); err != nil {
+		return false
+	}
+	defer token.Close() // nolint: errcheck
+
+	var elevated uint32
+	var n uint32
+	err = syscall.GetTokenInformation(token, tokenElevation,
+		(*byte)(unsafe.Pointer(&elevated)), uint32(unsafe.Sizeof(elevated)), &n)
+	return err == nil && elevated != 0
+}
@@ -0,0 +1,47 @@
+package skip
+
+import "sync"
+
+// probeCache stores the results of probes of the capabilities of the
+// environment, so that each probe only runs once in a test binary. Probes of
+// different keys run concurrently, and concurrent probes of the same key wait
+// for a single probe.
+type probeCache struct {
+	mu      sync.Mutex
+	entries map[string]*probeEntry
+	// retryErrors removes failed probes from the cache, so the probe runs
+	// again the next time the key is probed.
+	retryErrors bool
+}
+
+type probeEntry struct {
+	once sync.Once
+	err  error
+}
+
+func newProbeCache() *probeCache {
+	return &probeCache{entries: make(map[string]*probeEntry)}
+}
+
+// probe returns the cached result for key, or runs probe and caches the result.
+func (c *probeCache) probe(key string, probe func() error) error {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &probeEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.err = probe()
+	})
+	if entry.err != nil && c.retryErrors {
+		c.mu.Lock()
+		if c.entries[key] == entry {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+	}
+	return entry.err
+}
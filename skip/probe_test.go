@@ -0,0 +1,54 @@
+package skip
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestProbeCacheRunsProbeOnce(t *testing.T) {
+	cache := newProbeCache()
+	var calls int32
+	release := make(chan struct{})
+	probe := func() error {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return errors.New("unavailable")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Check(t, cache.probe("key", probe) != nil)
+		}()
+	}
+	// a probe of another key does not wait for the slow probe
+	assert.NilError(t, cache.probe("other", func() error { return nil }))
+	close(release)
+	wg.Wait()
+
+	assert.Error(t, cache.probe("key", probe), "unavailable")
+	assert.Equal(t, atomic.LoadInt32(&calls), int32(1))
+}
+
+func TestProbeCacheRetryErrors(t *testing.T) {
+	cache := &probeCache{entries: make(map[string]*probeEntry), retryErrors: true}
+	var calls int
+	probe := func() error {
+		calls++
+		if calls == 1 {
+			return errors.New("unavailable")
+		}
+		return nil
+	}
+
+	assert.Error(t, cache.probe("key", probe), "unavailable")
+	assert.NilError(t, cache.probe("key", probe))
+	assert.NilError(t, cache.probe("key", probe))
+	assert.Equal(t, calls, 2)
+}
@@ -52,13 +52,13 @@ func If(t skipT, condition BoolOrCheckFunc, msgAndArgs ...interface{}) {
 		ifCondition(t, check, msgAndArgs...)
 	case func() bool:
 		if check() {
-			t.Skip(format.WithCustomMessage(getFunctionName(check), msgAndArgs...))
+			skipTest(t, format.WithCustomMessage(getFunctionName(check), msgAndArgs...))
 		}
 	case func() Result:
 		result := check()
 		if result.Skip() {
 			msg := getFunctionName(check) + ": " + result.Message()
-			t.Skip(format.WithCustomMessage(msg, msgAndArgs...))
+			skipTest(t, format.WithCustomMessage(msg, msgAndArgs...))
 		}
 	default:
 		panic(fmt.Sprintf("invalid type for condition arg: %T", check))
@@ -84,7 +84,7 @@ func ifCondition(t skipT, condition bool, msgAndArgs ...interface{}) {
 	source, err := source.FormattedCallExprArg(stackIndex, argPos)
 	if err != nil {
 		t.Log(err.Error())
-		t.Skip(format.Message(msgAndArgs...))
+		skipTest(t, format.Message(msgAndArgs...))
 	}
-	t.Skip(format.WithCustomMessage(source, msgAndArgs...))
+	skipTest(t, format.WithCustomMessage(source, msgAndArgs...))
 }
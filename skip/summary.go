@@ -0,0 +1,132 @@
+package skip
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SummaryFileEnvVar is the name of the environment variable used by
+// RunWithSummary. When it is set, the summary is written to the file it names
+// instead of to stdout.
+const SummaryFileEnvVar = "GOTESTTOOLS_SKIP_SUMMARY_FILE"
+
+// Record is a test which was skipped by a function in this package.
+type Record struct {
+	// Test is the name of the test, or an empty string if the name is not
+	// known.
+	Test string
+	// Reason is the skip message.
+	Reason string
+}
+
+var summary struct {
+	sync.Mutex
+	enabled bool
+	records []Record
+}
+
+// EnableSummary starts recording every skip performed by this package, so that
+// they can be reported with Summary or WriteSummary.
+func EnableSummary() {
+	summary.Lock()
+	defer summary.Unlock()
+	summary.enabled = true
+}
+
+// Summary returns a record of every skip performed by this package since
+// EnableSummary was called.
+func Summary() []Record {
+	summary.Lock()
+	defer summary.Unlock()
+	return append([]Record(nil), summary.records...)
+}
+
+// WriteSummary writes the number of skipped tests, and the tests skipped for
+// each reason, to out.
+func WriteSummary(out io.Writer) error {
+	records := Summary()
+	byReason := make(map[string][]string)
+	for _, record := range records {
+		byReason[record.Reason] = append(byReason[record.Reason], record.Test)
+	}
+	reasons := make([]string, 0, len(byReason))
+	for reason := range byReason {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		a, b := byReason[reasons[i]], byReason[reasons[j]]
+		if len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "skipped %d tests for %d reasons\n", len(records), len(reasons))
+	for _, reason := range reasons {
+		tests := byReason[reason]
+		fmt.Fprintf(b, "%d: %s\n", len(tests), reason)
+		for _, test := range tests {
+			if test != "" {
+				fmt.Fprintf(b, "    %s\n", test)
+			}
+		}
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// RunWithSummary enables the skip summary, runs the tests, and writes the
+// summary to stdout, or to the file named by the environment variable
+// GOTESTTOOLS_SKIP_SUMMARY_FILE. It returns the exit code from m.Run. Use it
+// from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(skip.RunWithSummary(m))
+//	}
+func RunWithSummary(m interface{ Run() int }) int {
+	EnableSummary()
+	code := m.Run()
+
+	if path := os.Getenv(SummaryFileEnvVar); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write skip summary: %s\n", err)
+			return code
+		}
+		defer f.Close() // nolint: errcheck
+		if err := WriteSummary(f); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write skip summary: %s\n", err)
+		}
+		return code
+	}
+	if err := WriteSummary(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write skip summary: %s\n", err)
+	}
+	return code
+}
+
+type namedT interface {
+	Name() string
+}
+
+// skipTest records the skip in the summary, and skips the test.
+func skipTest(t skipT, reason string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	summary.Lock()
+	if summary.enabled {
+		record := Record{Reason: reason}
+		if nt, ok := t.(namedT); ok {
+			record.Test = nt.Name()
+		}
+		summary.records = append(summary.records, record)
+	}
+	summary.Unlock()
+	t.Skip(reason)
+}
@@ -0,0 +1,93 @@
+package skip
+
+import (
+	"bytes"
+	"io/ioutil"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+	"gotest.tools/v3/fs"
+)
+
+type namedSkipT struct {
+	fakeSkipT
+	name string
+}
+
+func (t *namedSkipT) Name() string {
+	return t.name
+}
+
+func resetSummary() {
+	summary.Lock()
+	defer summary.Unlock()
+	summary.enabled = false
+	summary.records = nil
+}
+
+func TestSummary(t *testing.T) {
+	defer resetSummary()
+
+	IfOS(&namedSkipT{name: "TestBefore"}, runtime.GOOS)
+	assert.Equal(t, len(Summary()), 0, "expected skips to be ignored until enabled")
+
+	EnableSummary()
+	IfOS(&namedSkipT{name: "TestOne"}, runtime.GOOS)
+	IfOS(&namedSkipT{name: "TestTwo"}, runtime.GOOS)
+	IfArch(&namedSkipT{name: "TestThree"}, runtime.GOARCH)
+	IfOS(&fakeSkipT{}, runtime.GOOS)
+
+	assert.DeepEqual(t, Summary(), []Record{
+		{Test: "TestOne", Reason: "running on " + runtime.GOOS},
+		{Test: "TestTwo", Reason: "running on " + runtime.GOOS},
+		{Test: "TestThree", Reason: "running on " + runtime.GOARCH},
+		{Reason: "running on " + runtime.GOOS},
+	})
+}
+
+func TestWriteSummary(t *testing.T) {
+	defer resetSummary()
+	EnableSummary()
+	UnlessEnv(&namedSkipT{name: "TestSlowA"}, "GOTESTTOOLS_UNSET_VAR")
+	UnlessEnv(&namedSkipT{name: "TestSlowB"}, "GOTESTTOOLS_UNSET_VAR")
+	IfExpr(&namedSkipT{name: "TestGo"}, "go1.1")
+
+	buf := new(bytes.Buffer)
+	assert.NilError(t, WriteSummary(buf))
+	expected := `skipped 3 tests for 2 reasons
+2: env GOTESTTOOLS_UNSET_VAR is not set
+    TestSlowA
+    TestSlowB
+1: go1.1
+    TestGo
+`
+	assert.Equal(t, buf.String(), expected)
+}
+
+type fakeM struct {
+	run func()
+}
+
+func (m fakeM) Run() int {
+	m.run()
+	return 3
+}
+
+func TestRunWithSummaryToFile(t *testing.T) {
+	defer resetSummary()
+	dir := fs.NewDir(t, "test-skip-summary")
+	defer dir.Remove()
+	defer env.Patch(t, SummaryFileEnvVar, dir.Join("summary.txt"))()
+
+	code := RunWithSummary(fakeM{run: func() {
+		IfOS(&namedSkipT{name: "TestOne"}, runtime.GOOS)
+	}})
+	assert.Equal(t, code, 3)
+
+	content, err := ioutil.ReadFile(dir.Join("summary.txt"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content),
+		"skipped 1 tests for 1 reasons\n1: running on "+runtime.GOOS+"\n    TestOne\n")
+}
@@ -0,0 +1,110 @@
+package skip
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"gotest.tools/v3/internal/format"
+)
+
+// IfGoVersionLessThan skips the test if the Go version used to build the test
+// binary is lower than version, for example "1.22" or "1.21.5". Development
+// versions of Go are never lower than version.
+func IfGoVersionLessThan(t skipT, version string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	current, ok := goVersion(runtime.Version())
+	if ok && compareVersions(current, strings.TrimPrefix(version, "go")) < 0 {
+		skipTest(t, format.WithCustomMessage(
+			fmt.Sprintf("go version %s is lower than %s", current, version), msgAndArgs...))
+	}
+}
+
+// goVersion returns the dotted version number from a version reported by
+// runtime.Version, and false for a development version.
+func goVersion(version string) (string, bool) {
+	if !strings.HasPrefix(version, "go") {
+		return "", false
+	}
+	version = versionPattern.FindString(version)
+	return version, version != ""
+}
+
+// IfGoExperiment skips the test if the test binary was built with the
+// GOEXPERIMENT name enabled.
+func IfGoExperiment(t skipT, name string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if hasGoExperiment(name) {
+		skipTest(t, format.WithCustomMessage("GOEXPERIMENT "+name+" is enabled", msgAndArgs...))
+	}
+}
+
+// UnlessGoExperiment skips the test unless the test binary was built with the
+// GOEXPERIMENT name enabled.
+func UnlessGoExperiment(t skipT, name string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !hasGoExperiment(name) {
+		skipTest(t, format.WithCustomMessage("GOEXPERIMENT "+name+" is not enabled", msgAndArgs...))
+	}
+}
+
+func hasGoExperiment(name string) bool {
+	for _, experiment := range splitList(goExperiment()) {
+		if experiment == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IfGODEBUG skips the test if the GODEBUG environment variable contains
+// setting, which has the form name=value.
+func IfGODEBUG(t skipT, setting string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if hasGODEBUG(setting) {
+		skipTest(t, format.WithCustomMessage("GODEBUG "+setting+" is set", msgAndArgs...))
+	}
+}
+
+// UnlessGODEBUG skips the test unless the GODEBUG environment variable
+// contains setting, which has the form name=value.
+func UnlessGODEBUG(t skipT, setting string, msgAndArgs ...interface{}) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if !hasGODEBUG(setting) {
+		skipTest(t, format.WithCustomMessage("GODEBUG "+setting+" is not set", msgAndArgs...))
+	}
+}
+
+// hasGODEBUG returns true if setting is in GODEBUG. When a name is repeated
+// the last value is used, which matches the behaviour of the runtime.
+func hasGODEBUG(setting string) bool {
+	name := strings.SplitN(setting, "=", 2)[0]
+	var last string
+	for _, item := range splitList(os.Getenv("GODEBUG")) {
+		if strings.SplitN(item, "=", 2)[0] == name {
+			last = item
+		}
+	}
+	return last == setting
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,22 @@
+//go:build go1.18
+// +build go1.18
+
+package skip
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// goExperiment returns the GOEXPERIMENT setting used to build the test binary.
+func goExperiment() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "GOEXPERIMENT" {
+				return setting.Value
+			}
+		}
+		return ""
+	}
+	return os.Getenv("GOEXPERIMENT")
+}
@@ -0,0 +1,12 @@
+//go:build !go1.18
+// +build !go1.18
+
+package skip
+
+import "os"
+
+// goExperiment returns the GOEXPERIMENT setting from the environment, because
+// the build settings of the binary are not available before go1.18.
+func goExperiment() string {
+	return os.Getenv("GOEXPERIMENT")
+}
@@ -0,0 +1,62 @@
+package skip
+
+import (
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/env"
+)
+
+func TestGoVersion(t *testing.T) {
+	version, ok := goVersion("go1.21.5")
+	assert.Assert(t, ok)
+	assert.Equal(t, version, "1.21.5")
+
+	version, ok = goVersion("go1.22rc1")
+	assert.Assert(t, ok)
+	assert.Equal(t, version, "1.22")
+
+	_, ok = goVersion("devel go1.23-abcdef")
+	assert.Assert(t, !ok)
+}
+
+func TestIfGoVersionLessThan(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfGoVersionLessThan(skipT, "1.1")
+	assert.Equal(t, skipT.reason, "")
+
+	current, ok := goVersion(runtime.Version())
+	if !ok {
+		t.Skip("development version of go")
+	}
+	IfGoVersionLessThan(skipT, "go999.0", "needs iterators")
+	assert.Equal(t, skipT.reason, "go version "+current+" is lower than go999.0: needs iterators")
+}
+
+func TestGoExperiment(t *testing.T) {
+	skipT := &fakeSkipT{}
+	IfGoExperiment(skipT, "gotesttoolsnotreal")
+	assert.Equal(t, skipT.reason, "")
+
+	UnlessGoExperiment(skipT, "gotesttoolsnotreal")
+	assert.Equal(t, skipT.reason, "GOEXPERIMENT gotesttoolsnotreal is not enabled")
+}
+
+func TestGODEBUG(t *testing.T) {
+	defer env.Patch(t, "GODEBUG", "http2client=0, gctrace=1,http2client=1")()
+
+	skipT := &fakeSkipT{}
+	IfGODEBUG(skipT, "http2client=0")
+	assert.Equal(t, skipT.reason, "", "expected the last value to win")
+
+	IfGODEBUG(skipT, "gctrace=1")
+	assert.Equal(t, skipT.reason, "GODEBUG gctrace=1 is set")
+
+	skipT = &fakeSkipT{}
+	UnlessGODEBUG(skipT, "http2client=1")
+	assert.Equal(t, skipT.reason, "")
+
+	UnlessGODEBUG(skipT, "madvdontneed=1")
+	assert.Equal(t, skipT.reason, "GODEBUG madvdontneed=1 is not set")
+}
@@ -0,0 +1,174 @@
+/*
+Package stress runs a function from many goroutines at once, to exercise the
+code under test for data races and other concurrency bugs. Run the tests with
+-race to detect data races.
+*/
+package stress // import "gotest.tools/v3/stress"
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TestingT is the subset of testing.T used by Run.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+type helperT interface {
+	Helper()
+}
+
+type options struct {
+	goroutines int
+	iterations int
+	duration   time.Duration
+}
+
+// Option changes the behaviour of Run.
+type Option func(*options)
+
+// WithGoroutines sets the number of goroutines which run the function. The
+// default is twice runtime.GOMAXPROCS.
+func WithGoroutines(n int) Option {
+	return func(o *options) {
+		o.goroutines = n
+	}
+}
+
+// WithIterations sets the number of times each goroutine runs the function.
+// The default is 100.
+func WithIterations(n int) Option {
+	return func(o *options) {
+		o.iterations = n
+		o.duration = 0
+	}
+}
+
+// WithDuration runs the function repeatedly in each goroutine until d has
+// passed, instead of for a fixed number of iterations.
+func WithDuration(d time.Duration) Option {
+	return func(o *options) {
+		o.duration = d
+	}
+}
+
+// Worker identifies the goroutine and iteration of a call to the function
+// passed to Run.
+type Worker struct {
+	// ID is the index of the goroutine, from 0 to the number of goroutines.
+	ID int
+	// Iteration is the number of times the function was called before by the
+	// same goroutine.
+	Iteration int
+}
+
+// Run calls fn from many goroutines, repeatedly, and waits for all the calls
+// to complete. The goroutines wait until all of them have started before the
+// first call, so that the calls overlap as much as possible.
+//
+// A goroutine stops calling fn after fn returns an error or panics. The other
+// goroutines continue. When all the goroutines are done the failure of each
+// goroutine is reported on t, along with the stack trace for panics.
+//
+// fn must not call t.FailNow, or the functions which call it, like
+// assert.Assert, because they must be called from the goroutine running the
+// test. Return an error, or use assert.Check instead.
+func Run(t TestingT, fn func(w Worker) error, ops ...Option) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	opts := options{goroutines: 2 * runtime.GOMAXPROCS(0), iterations: 100}
+	for _, op := range ops {
+		op(&opts)
+	}
+
+	var (
+		ready    sync.WaitGroup
+		done     sync.WaitGroup
+		start    = make(chan struct{})
+		mu       sync.Mutex
+		failures []failure
+		calls    int64
+	)
+	ready.Add(opts.goroutines)
+	done.Add(opts.goroutines)
+	for i := 0; i < opts.goroutines; i++ {
+		go func(id int) {
+			defer done.Done()
+			ready.Done()
+			<-start
+
+			var deadline time.Time
+			if opts.duration > 0 {
+				deadline = time.Now().Add(opts.duration)
+			}
+			for iteration := 0; ; iteration++ {
+				if opts.duration > 0 && !time.Now().Before(deadline) {
+					return
+				}
+				if opts.duration <= 0 && iteration >= opts.iterations {
+					return
+				}
+				atomic.AddInt64(&calls, 1)
+				w := Worker{ID: id, Iteration: iteration}
+				if f := call(fn, w); f != nil {
+					mu.Lock()
+					failures = append(failures, *f)
+					mu.Unlock()
+					return
+				}
+			}
+		}(i)
+	}
+	ready.Wait()
+	close(start)
+	done.Wait()
+
+	if len(failures) == 0 {
+		return
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].worker.ID < failures[j].worker.ID
+	})
+	t.Errorf("%d of %d goroutines failed after %d calls:\n\n%s",
+		len(failures), opts.goroutines, atomic.LoadInt64(&calls), formatFailures(failures))
+}
+
+type failure struct {
+	worker Worker
+	err    error
+	panic  interface{}
+	stack  []byte
+}
+
+func call(fn func(w Worker) error, w Worker) (f *failure) {
+	defer func() {
+		if r := recover(); r != nil {
+			f = &failure{worker: w, panic: r, stack: debug.Stack()}
+		}
+	}()
+	if err := fn(w); err != nil {
+		return &failure{worker: w, err: err}
+	}
+	return nil
+}
+
+func formatFailures(failures []failure) string {
+	messages := make([]string, 0, len(failures))
+	for _, f := range failures {
+		prefix := fmt.Sprintf("goroutine %d, iteration %d: ", f.worker.ID, f.worker.Iteration)
+		if f.err != nil {
+			messages = append(messages, prefix+f.err.Error())
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%spanic: %v\n%s", prefix, f.panic, f.stack))
+	}
+	return strings.Join(messages, "\n\n")
+}
@@ -0,0 +1,67 @@
+package stress
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+type fakeT struct {
+	failures []string
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRun(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[int]int{}
+	Run(t, func(w Worker) error {
+		mu.Lock()
+		defer mu.Unlock()
+		seen[w.ID]++
+		return nil
+	}, WithGoroutines(4), WithIterations(25))
+
+	assert.DeepEqual(t, seen, map[int]int{0: 25, 1: 25, 2: 25, 3: 25})
+}
+
+func TestRun_WithDuration(t *testing.T) {
+	var calls int64
+	start := time.Now()
+	Run(t, func(w Worker) error {
+		atomic.AddInt64(&calls, 1)
+		time.Sleep(time.Millisecond)
+		return nil
+	}, WithGoroutines(2), WithDuration(50*time.Millisecond))
+
+	assert.Assert(t, time.Since(start) >= 50*time.Millisecond)
+	assert.Assert(t, atomic.LoadInt64(&calls) > 2)
+}
+
+func TestRun_Failures(t *testing.T) {
+	fakeT := &fakeT{}
+	Run(fakeT, func(w Worker) error {
+		switch {
+		case w.ID == 1 && w.Iteration == 3:
+			return errors.New("bad state")
+		case w.ID == 2 && w.Iteration == 5:
+			panic("boom")
+		}
+		return nil
+	}, WithGoroutines(3), WithIterations(10))
+
+	assert.Equal(t, len(fakeT.failures), 1)
+	msg := fakeT.failures[0]
+	assert.Assert(t, is.Contains(msg, "2 of 3 goroutines failed after 20 calls:\n\n"+
+		"goroutine 1, iteration 3: bad state\n\n"+
+		"goroutine 2, iteration 5: panic: boom\n"))
+	assert.Assert(t, is.Contains(msg, "stress.TestRun_Failures"))
+}
@@ -0,0 +1,117 @@
+/*
+Package suite runs the test methods of a struct as subtests, with optional
+setup and teardown hooks for the suite and for each test.
+
+A suite is any value with methods which have a name starting with Test and the
+signature func(*testing.T). Hooks are declared by implementing the
+SetupSuite, TearDownSuite, SetupTest, and TearDownTest interfaces.
+*/
+package suite // import "gotest.tools/v3/suite"
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// SetupSuite is implemented by a suite which has setup to run before any of
+// its tests.
+type SetupSuite interface {
+	SetupSuite(t *testing.T)
+}
+
+// TearDownSuite is implemented by a suite which has teardown to run after all
+// of its tests, including parallel tests, have completed.
+type TearDownSuite interface {
+	TearDownSuite(t *testing.T)
+}
+
+// SetupTest is implemented by a suite which has setup to run before each
+// test. It is called with the *testing.T of the test.
+type SetupTest interface {
+	SetupTest(t *testing.T)
+}
+
+// TearDownTest is implemented by a suite which has teardown to run after each
+// test. It is called with the *testing.T of the test, after the test and its
+// subtests have completed.
+type TearDownTest interface {
+	TearDownTest(t *testing.T)
+}
+
+// Run runs each test method of suite as a subtest of t, in the order of the
+// method names.
+//
+// A test method may call t.Parallel. The suite value is shared by every test,
+// so a suite with parallel tests must not store per-test state in its fields.
+// TearDownTest and TearDownSuite are run using t.Cleanup, so they run after
+// parallel tests have completed, and run even if a test fails.
+func Run(t *testing.T, suite interface{}) {
+	t.Helper()
+	tests := testMethods(suite)
+	if len(tests) == 0 {
+		t.Fatalf("suite %T has no test methods", suite)
+	}
+
+	if s, ok := suite.(SetupSuite); ok {
+		s.SetupSuite(t)
+	}
+	if s, ok := suite.(TearDownSuite); ok {
+		defer cleanup(t, func() { s.TearDownSuite(t) })()
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			if s, ok := suite.(TearDownTest); ok {
+				defer cleanup(t, func() { s.TearDownTest(t) })()
+			}
+			if s, ok := suite.(SetupTest); ok {
+				s.SetupTest(t)
+			}
+			test.run(t)
+		})
+	}
+}
+
+type testMethod struct {
+	name string
+	run  func(t *testing.T)
+}
+
+var testingTType = reflect.TypeOf(&testing.T{})
+
+func testMethods(suite interface{}) []testMethod {
+	value := reflect.ValueOf(suite)
+	typ := value.Type()
+
+	var tests []testMethod
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if !strings.HasPrefix(method.Name, "Test") {
+			continue
+		}
+		mtype := method.Type
+		// the receiver is the first argument
+		if mtype.NumIn() != 2 || mtype.In(1) != testingTType || mtype.NumOut() != 0 {
+			continue
+		}
+		fn := value.Method(i).Interface().(func(*testing.T))
+		tests = append(tests, testMethod{name: method.Name, run: fn})
+	}
+	return tests
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// cleanup registers f with t.Cleanup and returns a no-op, or returns f to be
+// deferred when t.Cleanup is not available, before go1.14.
+func cleanup(t interface{}, f func()) func() {
+	if ct, ok := t.(cleanupT); ok {
+		ct.Cleanup(f)
+		return func() {}
+	}
+	return f
+}
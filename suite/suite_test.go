@@ -0,0 +1,106 @@
+package suite
+
+import (
+	"sort"
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type recordingSuite struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (s *recordingSuite) add(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *recordingSuite) SetupSuite(t *testing.T) { s.add("setup suite") }
+
+func (s *recordingSuite) TearDownSuite(t *testing.T) { s.add("teardown suite") }
+
+func (s *recordingSuite) SetupTest(t *testing.T) { s.add("setup " + t.Name()) }
+
+func (s *recordingSuite) TearDownTest(t *testing.T) { s.add("teardown " + t.Name()) }
+
+func (s *recordingSuite) TestOne(t *testing.T) { s.add("run " + t.Name()) }
+
+func (s *recordingSuite) TestTwo(t *testing.T) { s.add("run " + t.Name()) }
+
+// not a test method, because of the signature
+func (s *recordingSuite) TestHelper(x int) {}
+
+func TestRun(t *testing.T) {
+	s := &recordingSuite{}
+	t.Run("suite", func(t *testing.T) {
+		Run(t, s)
+	})
+
+	assert.DeepEqual(t, s.events, []string{
+		"setup suite",
+		"setup TestRun/suite/TestOne",
+		"run TestRun/suite/TestOne",
+		"teardown TestRun/suite/TestOne",
+		"setup TestRun/suite/TestTwo",
+		"run TestRun/suite/TestTwo",
+		"teardown TestRun/suite/TestTwo",
+		"teardown suite",
+	})
+}
+
+type parallelSuite struct {
+	recordingSuite
+}
+
+func (s *parallelSuite) TestA(t *testing.T) {
+	t.Parallel()
+	s.add("run " + t.Name())
+}
+
+func (s *parallelSuite) TestB(t *testing.T) {
+	t.Parallel()
+	s.add("run " + t.Name())
+}
+
+func TestRunParallel(t *testing.T) {
+	s := &parallelSuite{}
+	t.Run("suite", func(t *testing.T) {
+		Run(t, s)
+	})
+
+	assert.Equal(t, len(s.events), 2+3*4)
+	assert.Equal(t, s.events[0], "setup suite")
+	assert.Equal(t, s.events[len(s.events)-1], "teardown suite")
+
+	runs := []string{}
+	for _, event := range s.events {
+		if len(event) > 4 && event[:4] == "run " {
+			runs = append(runs, event)
+		}
+	}
+	sort.Strings(runs)
+	assert.DeepEqual(t, runs, []string{
+		"run TestRunParallel/suite/TestA",
+		"run TestRunParallel/suite/TestB",
+		"run TestRunParallel/suite/TestOne",
+		"run TestRunParallel/suite/TestTwo",
+	})
+}
+
+type plainSuite struct {
+	ran []string
+}
+
+func (s *plainSuite) TestOnly(t *testing.T) {
+	s.ran = append(s.ran, t.Name())
+}
+
+func TestRunWithoutHooks(t *testing.T) {
+	s := &plainSuite{}
+	Run(t, s)
+	assert.DeepEqual(t, s.ran, []string{"TestRunWithoutHooks/TestOnly"})
+}
@@ -0,0 +1,88 @@
+/*
+Package testcontext provides a context.Context which is tied to the lifetime of
+a test.
+
+The context returned by New can be passed to the functions in other packages
+which accept a context, such as icmd.RunCmdContext and poll.WaitOnContext, so
+that they stop when the test ends or is about to reach its deadline.
+*/
+package testcontext // import "gotest.tools/v3/testcontext"
+
+import (
+	"context"
+	"time"
+)
+
+// TestingT is the subset of testing.T used by New.
+type TestingT interface {
+	Name() string
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
+// implemented by gotest.tools/x/subtest.TestContext
+type addCleanupT interface {
+	AddCleanup(f func())
+}
+
+type deadlineT interface {
+	Deadline() (time.Time, bool)
+}
+
+// DeadlineMargin is how long before the test deadline the context returned by
+// New is cancelled, so that the test has time to report a useful failure
+// before the test binary is killed.
+var DeadlineMargin = time.Second
+
+type nameKey struct{}
+
+// New returns a context which is cancelled when the test and all its subtests
+// complete, and which has a deadline of DeadlineMargin before the test
+// deadline from t.Deadline, if there is one. The name of the test is stored in
+// the context, and can be retrieved with Name.
+//
+// If t does not support Cleanup, the context is never cancelled by the end of
+// the test.
+func New(t TestingT) context.Context {
+	return WithParent(context.Background(), t)
+}
+
+// WithParent returns a context like New, derived from parent.
+func WithParent(parent context.Context, t TestingT) context.Context {
+	ctx := context.WithValue(parent, nameKey{}, t.Name())
+
+	var cancel context.CancelFunc
+	deadline, ok := testDeadline(t)
+	if ok {
+		ctx, cancel = context.WithDeadline(ctx, deadline.Add(-DeadlineMargin))
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	registerCleanup(t, cancel)
+	return ctx
+}
+
+func testDeadline(t TestingT) (time.Time, bool) {
+	if dt, ok := t.(deadlineT); ok {
+		return dt.Deadline()
+	}
+	return time.Time{}, false
+}
+
+func registerCleanup(t TestingT, f func()) {
+	switch typed := t.(type) {
+	case cleanupT:
+		typed.Cleanup(f)
+	case addCleanupT:
+		typed.AddCleanup(f)
+	}
+}
+
+// Name returns the name of the test stored in ctx by New, or an empty string
+// if ctx was not created by New.
+func Name(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
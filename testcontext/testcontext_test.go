@@ -0,0 +1,69 @@
+package testcontext
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestNew(t *testing.T) {
+	var ctx context.Context
+	t.Run("subtest", func(t *testing.T) {
+		ctx = New(t)
+		assert.NilError(t, ctx.Err())
+		assert.Equal(t, Name(ctx), "TestNew/subtest")
+	})
+	assert.Equal(t, ctx.Err(), context.Canceled)
+}
+
+type fakeT struct {
+	name     string
+	deadline time.Time
+	cleanups []func()
+}
+
+func (t *fakeT) Name() string {
+	return t.name
+}
+
+func (t *fakeT) Deadline() (time.Time, bool) {
+	return t.deadline, !t.deadline.IsZero()
+}
+
+func (t *fakeT) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func TestNewWithDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	ft := &fakeT{name: "TestFake", deadline: deadline}
+
+	ctx := New(ft)
+	actual, ok := ctx.Deadline()
+	assert.Assert(t, ok)
+	assert.Equal(t, actual, deadline.Add(-DeadlineMargin))
+	assert.Equal(t, len(ft.cleanups), 1)
+
+	ft.cleanups[0]()
+	assert.Equal(t, ctx.Err(), context.Canceled)
+}
+
+func TestNewWithoutDeadline(t *testing.T) {
+	ft := &fakeT{name: "TestFake"}
+	ctx := New(ft)
+	_, ok := ctx.Deadline()
+	assert.Assert(t, !ok)
+}
+
+func TestWithParent(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	ctx := WithParent(parent, &fakeT{name: "TestFake"})
+	cancel()
+	assert.Equal(t, ctx.Err(), context.Canceled)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, Name(context.Background()), "")
+}
@@ -0,0 +1,140 @@
+/*
+Package testmain helps to write a TestMain function which runs global setup and
+teardown steps around the tests of a package.
+
+	var binary string
+
+	func TestMain(m *testing.M) {
+		os.Exit(testmain.Run(m,
+			testmain.Step{
+				Name:     "build binary",
+				Setup:    func() (err error) { binary, err = build(); return err },
+				Teardown: func() error { return os.Remove(binary) },
+			},
+		))
+	}
+*/
+package testmain // import "gotest.tools/v3/testmain"
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// M is the subset of testing.M used by Run.
+type M interface {
+	Run() int
+}
+
+// Step is a global setup step, and the teardown which reverses it.
+type Step struct {
+	// Name is used to identify the step in error messages.
+	Name string
+	// Setup is called before the tests run. It may be nil.
+	Setup func() error
+	// Teardown is called after the tests have run, if Setup returned no error.
+	// It may be nil.
+	Teardown func() error
+}
+
+// stderr is a variable so that tests can capture the output of Run.
+var stderr io.Writer = os.Stderr
+
+// exit is a variable so that tests can stop a signal from exiting.
+var exit = os.Exit
+
+// Run parses the command line flags, runs the Setup of each step in order,
+// runs the tests with m.Run, and then runs the Teardown of each step in the
+// reverse order. It returns the exit code, which should be passed to os.Exit.
+//
+// Flags are parsed before any setup, so that package level flags, such as the
+// -update flag used by the golden package, can be used by the setup steps.
+//
+// If a setup step fails the tests are not run, the steps which were already
+// set up are torn down, and the exit code is 1. If a teardown step fails the
+// remaining steps are still torn down, and the exit code is 1.
+//
+// The teardown steps are run even if a setup step or a test panics, in which
+// case the panic continues after the teardown, and when the test binary
+// receives an interrupt or SIGTERM, in which case the binary exits with code
+// 1 after the teardown. A test which calls os.Exit with a non-zero code, or a
+// test binary which is killed by the -timeout flag, can not be intercepted, so
+// the teardown steps do not run.
+func Run(m M, steps ...Step) int {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	var (
+		mu       sync.Mutex
+		setUp    []Step
+		failed   bool
+		tornDown bool
+	)
+	tearDown := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if tornDown {
+			return
+		}
+		tornDown = true
+		for i := len(setUp) - 1; i >= 0; i-- {
+			step := setUp[i]
+			if step.Teardown == nil {
+				continue
+			}
+			if err := step.Teardown(); err != nil {
+				fmt.Fprintf(stderr, "teardown %s failed: %s\n", step.Name, err)
+				failed = true
+			}
+		}
+	}
+
+	stop := handleSignals(tearDown)
+	defer stop()
+	defer tearDown()
+
+	for _, step := range steps {
+		if step.Setup != nil {
+			if err := step.Setup(); err != nil {
+				fmt.Fprintf(stderr, "setup %s failed: %s\n", step.Name, err)
+				tearDown()
+				return 1
+			}
+		}
+		mu.Lock()
+		setUp = append(setUp, step)
+		mu.Unlock()
+	}
+
+	code := m.Run()
+	tearDown()
+	if failed && code == 0 {
+		code = 1
+	}
+	return code
+}
+
+func handleSignals(tearDown func()) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			fmt.Fprintf(stderr, "received %s, tearing down\n", sig)
+			tearDown()
+			exit(1)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,98 @@
+package testmain
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+type fakeM func() int
+
+func (m fakeM) Run() int {
+	return m()
+}
+
+func recordingStep(name string, events *[]string) Step {
+	return Step{
+		Name: name,
+		Setup: func() error {
+			*events = append(*events, "setup "+name)
+			return nil
+		},
+		Teardown: func() error {
+			*events = append(*events, "teardown "+name)
+			return nil
+		},
+	}
+}
+
+func patchStderr(t *testing.T) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	stderr = buf
+	t.Cleanup(func() { stderr = os.Stderr })
+	return buf
+}
+
+func TestRunOrder(t *testing.T) {
+	var events []string
+	code := Run(fakeM(func() int {
+		events = append(events, "tests")
+		return 0
+	}), recordingStep("one", &events), recordingStep("two", &events))
+
+	assert.Equal(t, code, 0)
+	assert.DeepEqual(t, events, []string{
+		"setup one", "setup two", "tests", "teardown two", "teardown one",
+	})
+}
+
+func TestRunSetupFails(t *testing.T) {
+	buf := patchStderr(t)
+	var events []string
+	failing := Step{
+		Name:     "broken",
+		Setup:    func() error { return errors.New("oops") },
+		Teardown: func() error { events = append(events, "teardown broken"); return nil },
+	}
+	code := Run(fakeM(func() int {
+		events = append(events, "tests")
+		return 0
+	}), recordingStep("one", &events), failing, recordingStep("three", &events))
+
+	assert.Equal(t, code, 1)
+	assert.DeepEqual(t, events, []string{"setup one", "teardown one"})
+	assert.Equal(t, buf.String(), "setup broken failed: oops\n")
+}
+
+func TestRunTeardownFails(t *testing.T) {
+	buf := patchStderr(t)
+	var events []string
+	failing := Step{
+		Name:     "broken",
+		Teardown: func() error { return errors.New("oops") },
+	}
+	code := Run(fakeM(func() int { return 0 }), recordingStep("one", &events), failing)
+
+	assert.Equal(t, code, 1)
+	assert.DeepEqual(t, events, []string{"setup one", "teardown one"})
+	assert.Equal(t, buf.String(), "teardown broken failed: oops\n")
+}
+
+func TestRunKeepsTestExitCode(t *testing.T) {
+	code := Run(fakeM(func() int { return 3 }))
+	assert.Equal(t, code, 3)
+}
+
+func TestRunTearsDownOnPanic(t *testing.T) {
+	var events []string
+	func() {
+		defer func() {
+			assert.Equal(t, recover(), "boom")
+		}()
+		Run(fakeM(func() int { panic("boom") }), recordingStep("one", &events))
+	}()
+	assert.DeepEqual(t, events, []string{"setup one", "teardown one"})
+}
@@ -0,0 +1,36 @@
+//go:build !windows && !js && !wasip1 && !plan9
+// +build !windows,!js,!wasip1,!plan9
+
+package testmain
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestRunTearsDownOnSignal(t *testing.T) {
+	buf := patchStderr(t)
+	exited := make(chan int, 1)
+	exit = func(code int) { exited <- code }
+	defer func() { exit = os.Exit }()
+
+	var events []string
+	Run(fakeM(func() int {
+		assert.NilError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+		select {
+		case code := <-exited:
+			assert.Equal(t, code, 1)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for signal")
+		}
+		events = append(events, "tests")
+		return 0
+	}), recordingStep("one", &events))
+
+	assert.DeepEqual(t, events, []string{"setup one", "teardown one", "tests"})
+	assert.Equal(t, buf.String(), "received terminated, tearing down\n")
+}
@@ -0,0 +1,41 @@
+/*
+Package testname converts the name of a test into an identifier which is safe
+to use as part of a filename.
+
+The identifier is stable, so the same test always gets the same identifier, and
+is bounded in length, so that the names of deeply nested subtests do not
+exceed the limits of the filesystem. Packages like fs and artifacts use it to
+name the files and directories they create for a test.
+*/
+package testname // import "gotest.tools/v3/testname"
+
+import "gotest.tools/v3/internal/testname"
+
+// MaxLength is the maximum length of the identifier returned by Sanitize.
+const MaxLength = testname.MaxLength
+
+// TestingT is the subset of testing.T used by ID and Unique.
+type TestingT = testname.TestingT
+
+// Sanitize returns an identifier for name which is safe to use as part of a
+// filename. Path separators are replaced by "-", and any other characters
+// except letters, digits, ".", "_", and "-" are replaced by "_".
+//
+// Names longer than MaxLength are truncated, and a short hash of the full name
+// is appended, so that different long names still get different identifiers.
+func Sanitize(name string) string {
+	return testname.Sanitize(name)
+}
+
+// ID returns the identifier for the name of the test. See Sanitize.
+func ID(t TestingT) string {
+	return testname.ID(t)
+}
+
+// Unique returns the identifier for the name of the test, followed by a short
+// random suffix. Use Unique instead of ID for names which must not collide when
+// the same test runs more than once at the same time, for example with -count
+// and t.Parallel, or from different test binaries.
+func Unique(t TestingT) string {
+	return testname.Unique(t)
+}
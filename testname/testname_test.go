@@ -0,0 +1,50 @@
+package testname
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestSanitize(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		expected string
+	}{
+		{name: "TestSimple", expected: "TestSimple"},
+		{name: "TestOuter/inner_case", expected: "TestOuter-inner_case"},
+		{name: `Test\windows`, expected: "Test-windows"},
+		{name: "TestSpecial/a b:c*d?", expected: "TestSpecial-a_b_c_d_"},
+		{name: "TestUnicode/héllo wörld", expected: "TestUnicode-h_llo_w_rld"},
+		{name: "v1.2-rc", expected: "v1.2-rc"},
+	}
+	for _, tc := range testcases {
+		assert.Check(t, cmp.Equal(Sanitize(tc.name), tc.expected), tc.name)
+	}
+}
+
+func TestSanitizeLongName(t *testing.T) {
+	long := "TestLong/" + strings.Repeat("x", 100)
+	other := "TestLong/" + strings.Repeat("x", 99) + "y"
+
+	id := Sanitize(long)
+	assert.Equal(t, len(id), MaxLength)
+	assert.Equal(t, id, Sanitize(long))
+	assert.Assert(t, strings.HasPrefix(id, "TestLong-xxx"))
+	assert.Assert(t, id != Sanitize(other))
+}
+
+func TestID(t *testing.T) {
+	t.Run("sub test", func(t *testing.T) {
+		assert.Equal(t, ID(t), "TestID-sub_test")
+	})
+}
+
+func TestUnique(t *testing.T) {
+	first, second := Unique(t), Unique(t)
+	assert.Assert(t, first != second)
+	assert.Assert(t, strings.HasPrefix(first, "TestUnique-"))
+	assert.Equal(t, len(first), len("TestUnique-")+6)
+}
@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+package testtable
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Dimension is a named parameter of a test matrix, and the values it takes.
+type Dimension struct {
+	Name   string
+	Values []interface{}
+}
+
+// Dim returns a Dimension with name and values.
+func Dim(name string, values ...interface{}) Dimension {
+	return Dimension{Name: name, Values: values}
+}
+
+// Combination is one value for each Dimension of a matrix, by dimension name.
+type Combination map[string]interface{}
+
+// Get returns the value of the dimension name. It panics if the matrix has no
+// dimension with that name.
+func (c Combination) Get(name string) interface{} {
+	value, ok := c[name]
+	if !ok {
+		panic(fmt.Sprintf("matrix has no dimension %q", name))
+	}
+	return value
+}
+
+// MatrixOp is an option for RunMatrix.
+type MatrixOp func(*matrixConfig)
+
+type matrixConfig struct {
+	exclude []func(Combination) bool
+}
+
+// Exclude skips every combination for which exclude returns true. Excluded
+// combinations do not create a subtest.
+func Exclude(exclude func(c Combination) bool) MatrixOp {
+	return func(config *matrixConfig) {
+		config.exclude = append(config.exclude, exclude)
+	}
+}
+
+// RunMatrix runs fn for every combination of the values of dims. Each
+// dimension is a level of nested subtests named name=value, so a failure
+// shows which combination failed, for example:
+//
+//	TestStorage/backend=postgres/tls=true/api=v2
+//
+// A subset of the combinations can be run with -run, for example
+// -run 'TestStorage/backend=sqlite'.
+func RunMatrix(t *testing.T, dims []Dimension, fn func(t *testing.T, c Combination), ops ...MatrixOp) {
+	t.Helper()
+	config := &matrixConfig{}
+	for _, op := range ops {
+		op(config)
+	}
+	runMatrix(t, dims, Combination{}, fn, config)
+}
+
+func runMatrix(t *testing.T, dims []Dimension, c Combination, fn func(*testing.T, Combination), config *matrixConfig) {
+	t.Helper()
+	if len(dims) == 0 {
+		fn(t, c)
+		return
+	}
+	dim, rest := dims[0], dims[1:]
+	for _, value := range dim.Values {
+		next := make(Combination, len(c)+1)
+		for k, v := range c {
+			next[k] = v
+		}
+		next[dim.Name] = value
+		if len(rest) == 0 && config.excluded(next) {
+			continue
+		}
+		t.Run(fmt.Sprintf("%s=%v", dim.Name, value), func(t *testing.T) {
+			t.Helper()
+			runMatrix(t, rest, next, fn, config)
+		})
+	}
+}
+
+func (c *matrixConfig) excluded(combination Combination) bool {
+	for _, exclude := range c.exclude {
+		if exclude(combination) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+//go:build go1.18
+// +build go1.18
+
+package testtable
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+func TestRunMatrix(t *testing.T) {
+	dims := []Dimension{
+		Dim("backend", "sqlite", "postgres"),
+		Dim("tls", false, true),
+	}
+
+	r := &runner{}
+	var combinations []Combination
+	t.Run("matrix", func(t *testing.T) {
+		RunMatrix(t, dims, func(t *testing.T, c Combination) {
+			r.record(t)
+			combinations = append(combinations, c)
+		}, Exclude(func(c Combination) bool {
+			return c.Get("backend") == "sqlite" && c.Get("tls") == true
+		}))
+	})
+
+	assert.DeepEqual(t, r.ran, []string{
+		"TestRunMatrix/matrix/backend=sqlite/tls=false",
+		"TestRunMatrix/matrix/backend=postgres/tls=false",
+		"TestRunMatrix/matrix/backend=postgres/tls=true",
+	})
+	assert.DeepEqual(t, combinations[2], Combination{"backend": "postgres", "tls": true})
+}
+
+func TestCombinationGetMissing(t *testing.T) {
+	assert.Assert(t, cmp.Panics(func() { Combination{}.Get("backend") }))
+}
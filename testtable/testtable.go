@@ -0,0 +1,114 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Package testtable runs table-driven tests, with subtest names and per-case
+markers taken from the fields of the test case struct.
+*/
+package testtable // import "gotest.tools/v3/testtable"
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Named is implemented by a test case which provides its own subtest name.
+type Named interface {
+	Name() string
+}
+
+// Run runs fn as a subtest of t for each test case in cases.
+//
+// The name of each subtest is the result of the Name method if the test case
+// implements Named, otherwise the value of a string field called Name or name.
+// A test case without a name is named by its index, for example case-3.
+//
+// A test case struct may have markers which change how it is run:
+//
+//   - a bool field called Focus or focus which is true runs only the focused
+//     test cases, and skips the others. Use it while debugging a case.
+//   - a field called Skip or skip which is a true bool or a non-empty string
+//     skips the test case. A string is used as the reason in the skip message.
+func Run[Case any](t *testing.T, cases []Case, fn func(t *testing.T, tc Case)) {
+	t.Helper()
+	focused := 0
+	for _, tc := range cases {
+		if isFocused(tc) {
+			focused++
+		}
+	}
+	if focused > 0 {
+		t.Logf("running %d focused test cases, skipping %d", focused, len(cases)-focused)
+	}
+
+	for i, tc := range cases {
+		tc := tc
+		t.Run(caseName(tc, i), func(t *testing.T) {
+			t.Helper()
+			if reason, ok := skipReason(tc); ok {
+				t.Skip(reason)
+			}
+			if focused > 0 && !isFocused(tc) {
+				t.Skip("other test cases are focused")
+			}
+			fn(t, tc)
+		})
+	}
+}
+
+func caseName(tc interface{}, index int) string {
+	if named, ok := tc.(Named); ok {
+		if name := named.Name(); name != "" {
+			return name
+		}
+	}
+	if field, ok := caseField(tc, "Name", "name"); ok && field.Kind() == reflect.String {
+		if name := field.String(); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("case-%d", index)
+}
+
+func isFocused(tc interface{}) bool {
+	field, ok := caseField(tc, "Focus", "focus")
+	return ok && field.Kind() == reflect.Bool && field.Bool()
+}
+
+func skipReason(tc interface{}) (string, bool) {
+	field, ok := caseField(tc, "Skip", "skip")
+	if !ok {
+		return "", false
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		return "test case is marked skip", field.Bool()
+	case reflect.String:
+		reason := strings.TrimSpace(field.String())
+		return reason, reason != ""
+	}
+	return "", false
+}
+
+// caseField returns the first field of the struct tc with one of names. tc
+// may be a struct or a pointer to a struct.
+func caseField(tc interface{}, names ...string) (reflect.Value, bool) {
+	value := reflect.ValueOf(tc)
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for _, name := range names {
+		if field := value.FieldByName(name); field.IsValid() {
+			return field, true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,116 @@
+//go:build go1.18
+// +build go1.18
+
+package testtable
+
+import (
+	"sync"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// runner records the subtests which ran.
+type runner struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *runner) record(t *testing.T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ran = append(r.ran, t.Name())
+}
+
+func TestRunNames(t *testing.T) {
+	type testCase struct {
+		name     string
+		input    int
+		expected int
+	}
+	cases := []testCase{
+		{name: "one", input: 1, expected: 2},
+		{input: 2, expected: 4},
+		{name: "three", input: 3, expected: 6},
+	}
+
+	r := &runner{}
+	t.Run("table", func(t *testing.T) {
+		Run(t, cases, func(t *testing.T, tc testCase) {
+			r.record(t)
+			assert.Equal(t, tc.input*2, tc.expected)
+		})
+	})
+	assert.DeepEqual(t, r.ran, []string{
+		"TestRunNames/table/one",
+		"TestRunNames/table/case-1",
+		"TestRunNames/table/three",
+	})
+}
+
+type namedCase struct {
+	a, b int
+}
+
+func (c namedCase) Name() string {
+	return "sum"
+}
+
+func TestRunNamedInterface(t *testing.T) {
+	r := &runner{}
+	t.Run("table", func(t *testing.T) {
+		Run(t, []namedCase{{a: 1, b: 2}}, func(t *testing.T, tc namedCase) {
+			r.record(t)
+		})
+	})
+	assert.DeepEqual(t, r.ran, []string{"TestRunNamedInterface/table/sum"})
+}
+
+func TestRunMarkers(t *testing.T) {
+	type testCase struct {
+		Name  string
+		Focus bool
+		Skip  string
+	}
+
+	t.Run("skip", func(t *testing.T) {
+		r := &runner{}
+		t.Run("table", func(t *testing.T) {
+			Run(t, []testCase{
+				{Name: "a"},
+				{Name: "b", Skip: "flaky, see issue 12"},
+			}, func(t *testing.T, tc testCase) {
+				r.record(t)
+			})
+		})
+		assert.DeepEqual(t, r.ran, []string{"TestRunMarkers/skip/table/a"})
+	})
+
+	t.Run("focus", func(t *testing.T) {
+		r := &runner{}
+		t.Run("table", func(t *testing.T) {
+			Run(t, []testCase{
+				{Name: "a"},
+				{Name: "b", Focus: true},
+				{Name: "c"},
+			}, func(t *testing.T, tc testCase) {
+				r.record(t)
+			})
+		})
+		assert.DeepEqual(t, r.ran, []string{"TestRunMarkers/focus/table/b"})
+	})
+}
+
+func TestRunPointerCases(t *testing.T) {
+	type testCase struct {
+		name string
+		skip bool
+	}
+	r := &runner{}
+	t.Run("table", func(t *testing.T) {
+		Run(t, []*testCase{{name: "a"}, {name: "b", skip: true}}, func(t *testing.T, tc *testCase) {
+			r.record(t)
+		})
+	})
+	assert.DeepEqual(t, r.ran, []string{"TestRunPointerCases/table/a"})
+}
@@ -0,0 +1,94 @@
+/*
+Package timeout fails a test which takes too long, and shows what every
+goroutine was doing at the time.
+
+The -timeout flag of go test stops the whole test binary with a panic which
+often looks unrelated to the test which was running. Run fails only the test
+which took too long, and the other tests continue to run.
+*/
+package timeout // import "gotest.tools/v3/timeout"
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+// Run calls fn with t, and fails the test if fn does not return within d.
+// The failure includes the stack of every goroutine, so that the reason for the
+// delay can be found.
+//
+// If fn calls t.FailNow or t.SkipNow, for example with t.Fatal or t.Skip, Run
+// also stops the test.
+//
+// When the timeout is reached Run stops the test with t.FailNow, and fn is
+// left running in a separate goroutine. If fn uses t after the test has
+// ended the test binary panics, so a fn which may block for a long time should
+// use a context.Context which is cancelled when the test ends, such as the one
+// from testcontext.New.
+func Run(t *testing.T, d time.Duration, fn func(t *testing.T)) {
+	t.Helper()
+	run(t, d, func() { fn(t) })
+}
+
+type testingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	FailNow()
+	SkipNow()
+	Skipped() bool
+}
+
+func run(t testingT, d time.Duration, body func()) {
+	t.Helper()
+	done := make(chan struct{})
+	panicked := make(chan interface{}, 1)
+	completed := false
+	go func() {
+		defer close(done)
+		defer func() {
+			if !completed {
+				if r := recover(); r != nil {
+					panicked <- r
+				}
+			}
+		}()
+		body()
+		completed = true
+	}()
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-done:
+		select {
+		case r := <-panicked:
+			// continue the panic in the test goroutine so it is reported
+			panic(r)
+		default:
+		}
+		// body called t.FailNow or t.SkipNow, which only stopped the goroutine
+		// running body, so stop the test goroutine as well.
+		switch {
+		case completed:
+		case t.Skipped():
+			t.SkipNow()
+		default:
+			t.FailNow()
+		}
+	case <-timer.C:
+		t.Errorf("test did not complete within %s\n\ngoroutine stacks:\n\n%s", d, stacks())
+		t.FailNow()
+	}
+}
+
+func stacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
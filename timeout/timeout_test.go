@@ -0,0 +1,108 @@
+package timeout
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/assert/cmp"
+)
+
+type fakeT struct {
+	failed  string
+	stopped bool
+	skipped bool
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func (t *fakeT) FailNow() {
+	t.stopped = true
+}
+
+func (t *fakeT) SkipNow() {
+	t.skipped = true
+	t.stopped = true
+}
+
+func (t *fakeT) Skipped() bool {
+	return t.skipped
+}
+
+func blockedBody(stop chan struct{}) {
+	<-stop
+}
+
+func TestRunTimeout(t *testing.T) {
+	ft := &fakeT{}
+	stop := make(chan struct{})
+	defer close(stop)
+
+	run(ft, 10*time.Millisecond, func() { blockedBody(stop) })
+
+	assert.Assert(t, ft.stopped)
+	assert.Assert(t, cmp.Contains(ft.failed, "test did not complete within 10ms"))
+	assert.Assert(t, cmp.Contains(ft.failed, "gotest.tools/v3/timeout.blockedBody"))
+}
+
+func TestRunCompletes(t *testing.T) {
+	ft := &fakeT{}
+	called := false
+	run(ft, time.Second, func() { called = true })
+
+	assert.Assert(t, called)
+	assert.Assert(t, !ft.stopped)
+	assert.Equal(t, ft.failed, "")
+}
+
+func TestRunPanic(t *testing.T) {
+	ft := &fakeT{}
+	defer func() {
+		assert.Equal(t, recover(), "boom")
+	}()
+	run(ft, time.Second, func() { panic("boom") })
+}
+
+func TestRunWithTestingT(t *testing.T) {
+	Run(t, time.Second, func(t *testing.T) {
+		assert.Assert(t, t.Name() == "TestRunWithTestingT")
+	})
+}
+
+func TestRunStopsTestAfterSkipNow(t *testing.T) {
+	returned := false
+	t.Run("skipped", func(t *testing.T) {
+		Run(t, time.Second, func(t *testing.T) {
+			t.SkipNow()
+		})
+		returned = true
+	})
+	assert.Assert(t, !returned, "Run should not return after t.SkipNow")
+}
+
+func TestRunStopsTestAfterFatal(t *testing.T) {
+	if os.Getenv("TIMEOUT_TEST_FATAL") != "" {
+		t.Run("fatal", func(t *testing.T) {
+			Run(t, time.Second, func(t *testing.T) {
+				t.Fatal("fatal failure")
+			})
+			fmt.Println("Run returned after t.Fatal")
+		})
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunStopsTestAfterFatal$", "-test.v")
+	cmd.Env = append(os.Environ(), "TIMEOUT_TEST_FATAL=1")
+	out, err := cmd.CombinedOutput()
+	assert.Assert(t, err != nil, "test should fail:\n%s", out)
+	assert.Assert(t, cmp.Contains(string(out), "fatal failure"))
+	assert.Assert(t, !strings.Contains(string(out), "Run returned after t.Fatal"), string(out))
+}
@@ -0,0 +1,320 @@
+/*
+Package tlstest generates certificate authorities, and server and client
+certificates, for tests of TLS code.
+
+The certificates use ECDSA P-256 keys, which are fast to generate. Use
+SharedCA, CA.Server, and CA.Client to reuse certificates across the tests in a
+test binary.
+*/
+package tlstest // import "gotest.tools/v3/tlstest"
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
+)
+
+type helperT interface {
+	Helper()
+}
+
+// CertOp modifies the template of a certificate before it is signed.
+type CertOp func(template *x509.Certificate)
+
+// WithCommonName sets the subject common name of the certificate.
+func WithCommonName(name string) CertOp {
+	return func(template *x509.Certificate) {
+		template.Subject.CommonName = name
+	}
+}
+
+// WithHosts adds subject alternative names to the certificate. Each host is
+// added as an IP address if it can be parsed as one, otherwise as a DNS name.
+func WithHosts(hosts ...string) CertOp {
+	return func(template *x509.Certificate) {
+		for _, host := range hosts {
+			if ip := net.ParseIP(host); ip != nil {
+				template.IPAddresses = append(template.IPAddresses, ip)
+				continue
+			}
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+}
+
+// WithValidity sets the period in which the certificate is valid.
+func WithValidity(notBefore, notAfter time.Time) CertOp {
+	return func(template *x509.Certificate) {
+		template.NotBefore = notBefore
+		template.NotAfter = notAfter
+	}
+}
+
+// WithExpiry sets the certificate to expire d after it is issued. Use a
+// negative d to issue a certificate which has already expired.
+func WithExpiry(d time.Duration) CertOp {
+	return func(template *x509.Certificate) {
+		now := time.Now()
+		template.NotAfter = now.Add(d)
+		if !template.NotBefore.Before(template.NotAfter) {
+			template.NotBefore = template.NotAfter.Add(-time.Hour)
+		}
+	}
+}
+
+// WithClientAuth sets the extended key usage of the certificate to client
+// authentication, instead of server authentication.
+func WithClientAuth() CertOp {
+	return func(template *x509.Certificate) {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+}
+
+// Cert is a certificate and its private key.
+type Cert struct {
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+	// CertPEM is the PEM encoded certificate.
+	CertPEM []byte
+	// KeyPEM is the PEM encoded private key, in PKCS #8 form.
+	KeyPEM []byte
+}
+
+// TLSCertificate returns the certificate for use in tls.Config.Certificates.
+func (c *Cert) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{c.Certificate.Raw},
+		PrivateKey:  c.PrivateKey,
+		Leaf:        c.Certificate,
+	}
+}
+
+// Files writes the certificate and key to cert.pem and key.pem in a new
+// temporary directory, and returns the paths of the files. The directory is
+// removed when the test ends, see fs.NewDir.
+func (c *Cert) Files(t assert.TestingT) (certFile, keyFile string) {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	dir := fs.NewDir(t, "tlstest",
+		fs.WithFile("cert.pem", string(c.CertPEM), fs.WithMode(0o644)),
+		fs.WithFile("key.pem", string(c.KeyPEM), fs.WithMode(0o600)))
+	return dir.Join("cert.pem"), dir.Join("key.pem")
+}
+
+// CA is a certificate authority which issues certificates.
+type CA struct {
+	Cert
+
+	mu     sync.Mutex
+	issued map[string]*Cert
+}
+
+// NewCA creates a new self-signed certificate authority. By default the CA is
+// valid from an hour ago until a day from now.
+func NewCA(t assert.TestingT, ops ...CertOp) *CA {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	ca, err := newCA(ops...)
+	assert.NilError(t, err)
+	return ca
+}
+
+var shared struct {
+	sync.Mutex
+	ca *CA
+}
+
+// SharedCA returns a certificate authority which is created the first time it
+// is called, and reused by every test in the test binary.
+func SharedCA(t assert.TestingT) *CA {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	shared.Lock()
+	defer shared.Unlock()
+	if shared.ca == nil {
+		ca, err := newCA(WithCommonName("gotest.tools shared test CA"))
+		assert.NilError(t, err)
+		shared.ca = ca
+	}
+	return shared.ca
+}
+
+func newCA(ops ...CertOp) (*CA, error) {
+	template := newTemplate()
+	template.Subject.CommonName = "gotest.tools test CA"
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	template.ExtKeyUsage = nil
+	for _, op := range ops {
+		op(template)
+	}
+
+	cert, err := createCert(template, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CA{Cert: *cert, issued: map[string]*Cert{}}, nil
+}
+
+func newTemplate() *x509.Certificate {
+	now := time.Now()
+	return &x509.Certificate{
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(24 * time.Hour),
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+}
+
+// createCert generates a key, and signs template with the key of parent, or
+// with its own key if parent is nil.
+func createCert(template *x509.Certificate, parent *Cert) (*Cert, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	signer, issuer := crypto.Signer(key), template
+	if parent != nil {
+		signer, issuer = parent.PrivateKey, parent.Certificate
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Cert{
+		Certificate: cert,
+		PrivateKey:  key,
+		CertPEM:     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		KeyPEM:      pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}, nil
+}
+
+// Issue creates a new certificate signed by the CA. By default the
+// certificate is for server authentication, has no subject alternative names,
+// and is valid from an hour ago until a day from now.
+func (ca *CA) Issue(t assert.TestingT, ops ...CertOp) *Cert {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	template := newTemplate()
+	for _, op := range ops {
+		op(template)
+	}
+	cert, err := createCert(template, &ca.Cert)
+	assert.NilError(t, err)
+	return cert
+}
+
+// DefaultHosts are the hosts used by CA.Server when no hosts are given.
+var DefaultHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// Server returns a server certificate for hosts, or for DefaultHosts if no
+// hosts are given. The certificate is issued the first time it is requested,
+// and reused for later calls with the same hosts.
+func (ca *CA) Server(t assert.TestingT, hosts ...string) *Cert {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	if len(hosts) == 0 {
+		hosts = DefaultHosts
+	}
+	return ca.cached(t, "server:"+strings.Join(hosts, ","),
+		WithCommonName(hosts[0]), WithHosts(hosts...))
+}
+
+// Client returns a client certificate with the common name. The certificate
+// is issued the first time it is requested, and reused for later calls with
+// the same common name.
+func (ca *CA) Client(t assert.TestingT, commonName string) *Cert {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	return ca.cached(t, "client:"+commonName, WithCommonName(commonName), WithClientAuth())
+}
+
+func (ca *CA) cached(t assert.TestingT, key string, ops ...CertOp) *Cert {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if cert, ok := ca.issued[key]; ok {
+		return cert
+	}
+	cert := ca.Issue(t, ops...)
+	ca.issued[key] = cert
+	return cert
+}
+
+// CertPool returns a pool which contains the certificate of the CA.
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// File writes the certificate of the CA to ca.pem in a new temporary
+// directory, and returns the path of the file. The directory is removed when
+// the test ends, see fs.NewDir.
+func (ca *CA) File(t assert.TestingT) string {
+	if ht, ok := t.(helperT); ok {
+		ht.Helper()
+	}
+	dir := fs.NewDir(t, "tlstest", fs.WithFile("ca.pem", string(ca.CertPEM)))
+	return dir.Join("ca.pem")
+}
+
+// ServerConfig returns a tls.Config for a server which uses the certificate,
+// and verifies client certificates signed by the CA when clients send one.
+// Set ClientAuth to tls.RequireAndVerifyClientCert to require a client
+// certificate.
+func (ca *CA) ServerConfig(server *Cert) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{server.TLSCertificate()},
+		ClientCAs:    ca.CertPool(),
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// ClientConfig returns a tls.Config for a client which trusts the CA, and
+// sends the client certificate if it is not nil.
+func (ca *CA) ClientConfig(client *Cert) *tls.Config {
+	config := &tls.Config{
+		RootCAs:    ca.CertPool(),
+		MinVersion: tls.VersionTLS12,
+	}
+	if client != nil {
+		config.Certificates = []tls.Certificate{client.TLSCertificate()}
+	}
+	return config
+}
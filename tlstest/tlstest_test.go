@@ -0,0 +1,94 @@
+package tlstest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+func TestCA_Server(t *testing.T) {
+	ca := NewCA(t)
+	server := ca.Server(t)
+
+	assert.Assert(t, ca.Certificate.IsCA)
+	assert.DeepEqual(t, server.Certificate.DNSNames, []string{"localhost"})
+	assert.Equal(t, len(server.Certificate.IPAddresses), 2)
+	assert.Assert(t, server.Certificate.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	assert.NilError(t, server.Certificate.CheckSignatureFrom(ca.Certificate))
+
+	assert.Assert(t, ca.Server(t) == server, "expected the certificate to be reused")
+	assert.Assert(t, ca.Server(t, "example.com") != server)
+}
+
+func TestSharedCA(t *testing.T) {
+	assert.Assert(t, SharedCA(t) == SharedCA(t))
+}
+
+func TestCA_Verify(t *testing.T) {
+	ca := NewCA(t)
+	verify := func(cert *Cert, usage x509.ExtKeyUsage) error {
+		_, err := cert.Certificate.Verify(x509.VerifyOptions{
+			Roots:     ca.CertPool(),
+			KeyUsages: []x509.ExtKeyUsage{usage},
+		})
+		return err
+	}
+
+	assert.NilError(t, verify(ca.Client(t, "alice"), x509.ExtKeyUsageClientAuth))
+	assert.Assert(t, verify(ca.Client(t, "alice"), x509.ExtKeyUsageServerAuth) != nil)
+
+	expired := ca.Issue(t, WithHosts("localhost"), WithExpiry(-time.Minute))
+	assert.ErrorContains(t, verify(expired, x509.ExtKeyUsageServerAuth), "expired")
+
+	other := NewCA(t).Server(t)
+	assert.ErrorContains(t, verify(other, x509.ExtKeyUsageServerAuth), "unknown authority")
+}
+
+func TestCA_TLSConfig(t *testing.T) {
+	ca := SharedCA(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName)) //nolint: errcheck
+	}))
+	server.TLS = ca.ServerConfig(ca.Server(t))
+	server.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	server.StartTLS()
+	defer server.Close()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: ca.ClientConfig(ca.Client(t, "bob")),
+	}}
+	resp, err := client.Get(server.URL)
+	assert.NilError(t, err)
+	defer resp.Body.Close() //nolint: errcheck
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), "bob")
+
+	noCert := &http.Client{Transport: &http.Transport{TLSClientConfig: ca.ClientConfig(nil)}}
+	_, err = noCert.Get(server.URL)
+	assert.Assert(t, err != nil)
+}
+
+func TestCert_Files(t *testing.T) {
+	ca := NewCA(t)
+	server := ca.Server(t, "example.com")
+
+	certFile, keyFile := server.Files(t)
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	assert.NilError(t, err)
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, leaf.DNSNames, []string{"example.com"})
+
+	content, err := ioutil.ReadFile(ca.File(t))
+	assert.NilError(t, err)
+	assert.Assert(t, is.Contains(string(content), "BEGIN CERTIFICATE"))
+}
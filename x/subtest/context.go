@@ -9,15 +9,16 @@ This package was inspired by github.com/frankban/quicktest.
 With the addition of T.Cleanup() in go1.14 this package provides very
 little value. A context.Context can be managed by tests that need it with
 little enough boilerplate that it doesn't make sense to wrap testing.T in a
-TestContext.
+TestContext. See gotest.tools/v3/testcontext.
 */
 package subtest // import "gotest.tools/v3/x/subtest"
 
 import (
 	"context"
 	"testing"
+	"time"
 
-	"gotest.tools/v3/internal/cleanup"
+	"gotest.tools/v3/testcontext"
 )
 
 type testcase struct {
@@ -30,13 +31,26 @@ type cleanupFunc func()
 
 func (tc *testcase) Ctx() context.Context {
 	if tc.ctx == nil {
-		var cancel func()
-		tc.ctx, cancel = context.WithCancel(context.Background())
-		cleanup.Cleanup(tc, cancel)
+		tc.ctx = testcontext.New(tc)
 	}
 	return tc.ctx
 }
 
+func (tc *testcase) Deadline() (time.Time, bool) {
+	if dt, ok := tc.TB.(deadlineT); ok {
+		return dt.Deadline()
+	}
+	return time.Time{}, false
+}
+
+type deadlineT interface {
+	Deadline() (time.Time, bool)
+}
+
+type cleanupT interface {
+	Cleanup(f func())
+}
+
 // cleanup runs all cleanup functions. Functions are run in the opposite order
 // in which they were added. Cleanup is called automatically before Run exits.
 func (tc *testcase) cleanup() {
@@ -48,7 +62,14 @@ func (tc *testcase) cleanup() {
 	tc.cleanupFuncs = nil
 }
 
+// AddCleanup registers f with testing.TB.Cleanup when it is available, so
+// that it runs in the correct order with other cleanup functions, and after
+// any parallel subtests have completed. Otherwise f is run when Run returns.
 func (tc *testcase) AddCleanup(f func()) {
+	if ct, ok := tc.TB.(cleanupT); ok {
+		ct.Cleanup(f)
+		return
+	}
 	tc.cleanupFuncs = append(tc.cleanupFuncs, f)
 }
 
@@ -64,8 +85,8 @@ type parallel interface {
 	Parallel()
 }
 
-// Run a subtest. When subtest exits, every cleanup function added with
-// TestContext.AddCleanup will be run.
+// Run a subtest. When the subtest and all of its own subtests complete, every
+// cleanup function added with TestContext.AddCleanup will be run.
 func Run(t *testing.T, name string, subtest func(t TestContext)) bool {
 	return t.Run(name, func(t *testing.T) {
 		tc := &testcase{TB: t}
@@ -74,6 +95,15 @@ func Run(t *testing.T, name string, subtest func(t TestContext)) bool {
 	})
 }
 
+// RunParallel runs a subtest like Run, and calls t.Parallel before subtest, so
+// that it runs in parallel with the other parallel subtests of t.
+func RunParallel(t *testing.T, name string, subtest func(t TestContext)) bool {
+	return Run(t, name, func(t TestContext) {
+		t.Parallel()
+		subtest(t)
+	})
+}
+
 // TestContext provides a testing.TB and a context.Context for a test case.
 type TestContext interface {
 	testing.TB
@@ -83,9 +113,13 @@ type TestContext interface {
 	// should be used instead. AddCleanup will be removed in a future release.
 	AddCleanup(f func())
 	// Ctx returns a context for the test case. Multiple calls from the same subtest
-	// will return the same context. The context is cancelled when Run
-	// returns.
+	// will return the same context. The context is cancelled when the subtest
+	// completes, and has a deadline before the deadline of the test binary.
+	// See testcontext.New.
 	Ctx() context.Context
+	// Deadline returns the time at which the test binary will exceed the
+	// timeout set by the -timeout flag. See testing.T.Deadline.
+	Deadline() (deadline time.Time, ok bool)
 	// Parallel calls t.Parallel on the testing.TB. Panics if testing.TB does
 	// not implement Parallel.
 	Parallel()
@@ -2,7 +2,10 @@ package subtest
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -30,3 +33,43 @@ func TestTestcase_Run_Parallel(t *testing.T) {
 		t.Parallel()
 	})
 }
+
+func TestTestcase_Run_CleanupAfterParallelSubtests(t *testing.T) {
+	var events []string
+	var mu sync.Mutex
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	Run(t, "outer", func(t TestContext) {
+		t.AddCleanup(func() { record("outer cleanup") })
+		ctx := t.Ctx()
+		outer := t.(*testcase).TB.(*testing.T)
+		for _, name := range []string{"a", "b"} {
+			name := name
+			RunParallel(outer, name, func(t TestContext) {
+				time.Sleep(10 * time.Millisecond)
+				assert.NilError(t, ctx.Err())
+				record("parallel " + name)
+			})
+		}
+	})
+
+	sort.Strings(events[:2])
+	assert.DeepEqual(t, events, []string{"parallel a", "parallel b", "outer cleanup"})
+}
+
+func TestTestcase_Deadline(t *testing.T) {
+	Run(t, "deadline", func(tc TestContext) {
+		expected, expectedOK := t.Deadline()
+		deadline, ok := tc.Deadline()
+		assert.Equal(t, ok, expectedOK)
+		assert.Equal(t, deadline, expected)
+		if ok {
+			ctxDeadline, _ := tc.Ctx().Deadline()
+			assert.Assert(t, ctxDeadline.Before(deadline))
+		}
+	})
+}